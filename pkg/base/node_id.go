@@ -83,11 +83,10 @@ func (n *NodeIDContainer) Reset(val roachpb.NodeID) {
 // on process start which is unique across all SQL server processes running
 // on behalf of the tenant, while the SQL server is running.
 //
-// NB: until https://github.com/cockroachdb/cockroach/issues/47899 is addressed,
-// the properties of the SQLInstanceID hold trivially due to the constraint that
-// only one SQL server must be running on behalf of the tenant at any given
-// time. After that, it's likely that we'll allocate these IDs off a counter,
-// so they will be completely unique (per tenant).
+// The SQL servers of a secondary tenant allocate their SQLInstanceID from the
+// tenant's system.sql_instances table on startup, where it is tied to their
+// sqlliveness session. Until then (or if the table is not available), they use
+// a default SQLInstanceID.
 type SQLInstanceID int32
 
 func (s SQLInstanceID) String() string {
@@ -96,8 +95,10 @@ func (s SQLInstanceID) String() string {
 
 // SQLIDContainer wraps a SQLInstanceID and optionally a NodeID.
 type SQLIDContainer struct {
-	w             errorutil.TenantSQLDeprecatedWrapper // NodeID
-	sqlInstanceID SQLInstanceID
+	w errorutil.TenantSQLDeprecatedWrapper // NodeID
+	// sqlInstanceID is accessed atomically; it is updated once the SQL server
+	// of a tenant has registered itself with the sql_instances table.
+	sqlInstanceID int32
 }
 
 // NewSQLIDContainer sets up an SQLIDContainer. It is handed either a positive SQLInstanceID
@@ -108,7 +109,7 @@ type SQLIDContainer struct {
 func NewSQLIDContainer(sqlInstanceID SQLInstanceID, nodeID *NodeIDContainer) *SQLIDContainer {
 	return &SQLIDContainer{
 		w:             errorutil.MakeTenantSQLDeprecatedWrapper(nodeID, nodeID != nil),
-		sqlInstanceID: sqlInstanceID,
+		sqlInstanceID: int32(sqlInstanceID),
 	}
 }
 
@@ -137,7 +138,14 @@ func (c *SQLIDContainer) SQLInstanceID() SQLInstanceID {
 	if n, ok := c.OptionalNodeID(); ok {
 		return SQLInstanceID(n)
 	}
-	return c.sqlInstanceID
+	return SQLInstanceID(atomic.LoadInt32(&c.sqlInstanceID))
+}
+
+// SetSQLInstanceID sets the SQLInstanceID of a SQL server which doesn't expose
+// a NodeID. It is used by the SQL pods of secondary tenants once they have
+// been allocated a unique SQLInstanceID.
+func (c *SQLIDContainer) SetSQLInstanceID(id SQLInstanceID) {
+	atomic.StoreInt32(&c.sqlInstanceID, int32(id))
 }
 
 // TestingIDContainer is an SQLIDContainer with hard-coded SQLInstanceID of 10 and
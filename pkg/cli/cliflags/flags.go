@@ -1123,6 +1123,32 @@ If non-empty, write log files in this directory. If empty, write log files to
 `,
 	}
 
+	Log = FlagInfo{
+		Name: "log",
+		Description: `
+Logging configuration, expressed using YAML syntax. The configuration
+defines additional log sinks (file groups, fluent and HTTP servers, stderr),
+which logging channels they receive, their minimum severity, their output
+format (crdb-v1, crdb-v1-tty, json, json-fluent) and their redaction policy.
+For example:
+<PRE>
+
+  --log='sinks: {fluent-servers: {local: {channels: all, address: "127.0.0.1:5170"}}}'
+
+</PRE>
+The channels routed to a file group stop writing to their default log
+files. When a stderr sink is configured, it supersedes --logtostderr.
+`,
+	}
+
+	LogConfigFile = FlagInfo{
+		Name: "log-config-file",
+		Description: `
+File name to read the logging configuration from. This has the same effect as
+passing the content of the file via the --log flag.
+`,
+	}
+
 	LogDirMaxSize = FlagInfo{
 		Name: "log-group-max-size",
 		Description: `
@@ -1213,7 +1239,20 @@ The default is to not exclude any node.`,
 Redact text that may contain confidential data or PII from retrieved
 log entries. Note that this flag only operates on log entries;
 other items retrieved by the zip command may still consider
-confidential data or PII.
+confidential data or PII. See --redact.
+`,
+	}
+
+	ZipRedact = FlagInfo{
+		Name: "redact",
+		Description: `
+Redact text that may contain confidential data or PII from all the
+retrieved data. This implies --redact-logs. Additionally, constants
+are removed from the SQL statements and expressions, keys are
+truncated to their table and index prefix, the values of
+non-reportable cluster settings are hidden, the raw job payloads
+and descriptors are omitted, and the labels identifying clients are
+removed from the CPU profiles.
 `,
 	}
 
@@ -1234,4 +1273,26 @@ The zip command will block for the duration specified. Zero disables this featur
 		Name:        "all",
 		Description: `Cancel all outstanding requests.`,
 	}
+
+	StmtDiagMinLatency = FlagInfo{
+		Name: "min-latency",
+		Description: `
+If nonzero, only collect the bundle for an execution of the statement that
+takes at least the specified duration. The request stays outstanding until
+such an execution is observed.`,
+	}
+
+	StmtDiagSamplingProbability = FlagInfo{
+		Name: "sampling-probability",
+		Description: `
+If nonzero, trace each execution of the statement with the specified
+probability, between 0 and 1.`,
+	}
+
+	StmtDiagExpiresAfter = FlagInfo{
+		Name: "expires-after",
+		Description: `
+If nonzero, cancel the request if it is not satisfied within the specified
+duration.`,
+	}
 )
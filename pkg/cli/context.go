@@ -254,6 +254,11 @@ var zipCtx struct {
 	// server-side during retrieval.
 	redactLogs bool
 
+	// redact indicates whether all the retrieved data, not only the log
+	// files, should be stripped of confidential data. It implies
+	// redactLogs.
+	redact bool
+
 	// Duration (in seconds) to run CPU profile for.
 	cpuProfDuration time.Duration
 }
@@ -264,6 +269,7 @@ var zipCtx struct {
 func setZipContextDefaults() {
 	zipCtx.nodes = nodeSelection{}
 	zipCtx.redactLogs = false
+	zipCtx.redact = false
 	zipCtx.cpuProfDuration = 5 * time.Second
 }
 
@@ -369,6 +375,10 @@ var startCtx struct {
 	// logging settings specific to file logging.
 	logDir log.DirName
 
+	// logConfigInput is the YAML logging configuration, provided via
+	// --log or --log-config-file.
+	logConfigInput string
+
 	// geoLibsDir is used to specify locations of the GEOS library.
 	geoLibsDir string
 }
@@ -387,6 +397,7 @@ func setStartContextDefaults() {
 	startCtx.listeningURLFile = ""
 	startCtx.pidFile = ""
 	startCtx.inBackground = false
+	startCtx.logConfigInput = ""
 	startCtx.geoLibsDir = "/usr/local/lib/cockroach"
 }
 
@@ -530,11 +541,17 @@ func setDemoContextDefaults() {
 // stmtDiagCtx captures the command-line parameters of the 'statement-diag'
 // command.
 var stmtDiagCtx struct {
-	all bool
+	all                 bool
+	minLatency          time.Duration
+	samplingProbability float64
+	expiresAfter        time.Duration
 }
 
 func setStmtDiagContextDefaults() {
 	stmtDiagCtx.all = false
+	stmtDiagCtx.minLatency = 0
+	stmtDiagCtx.samplingProbability = 0
+	stmtDiagCtx.expiresAfter = 0
 }
 
 // GetServerCfgStores provides direct public access to the StoreSpecList inside
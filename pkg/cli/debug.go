@@ -35,8 +35,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/gc"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverpb"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/rditer"
-	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
@@ -1086,90 +1086,17 @@ func removeDeadReplicas(
 	}
 
 	batch := db.NewBatch()
-	for _, desc := range newDescs {
-		// Write the rewritten descriptor to the range-local descriptor
-		// key. We do not update the meta copies of the descriptor.
-		// Instead, we leave them in a temporarily inconsistent state and
-		// they will be overwritten when the cluster recovers and
-		// up-replicates this range from its single copy to multiple
-		// copies. We rely on the fact that all range descriptor updates
-		// start with a CPut on the range-local copy followed by a blind
-		// Put to the meta copy.
-		//
-		// For example, if we have replicas on s1-s4 but s3 and s4 are
-		// dead, we will rewrite the replica on s2 to have s2 as its only
-		// member only. When the cluster is restarted (and the dead nodes
-		// remain dead), the rewritten replica will be the only one able
-		// to make progress. It will elect itself leader and upreplicate.
-		//
-		// The old replica on s1 is untouched by this process. It will
-		// eventually either be overwritten by a new replica when s2
-		// upreplicates, or it will be destroyed by the replica GC queue
-		// after upreplication has happened and s1 is no longer a member.
-		// (Note that in the latter case, consistency between s1 and s2 no
-		// longer matters; the consistency checker will only run on nodes
-		// that the new leader believes are members of the range).
-		//
-		// Note that this tool does not guarantee fully consistent
-		// results; the most recent writes to the raft log may have been
-		// lost. In the most unfortunate cases, this means that we would
-		// be "winding back" a split or a merge, which is almost certainly
-		// to result in irrecoverable corruption (for example, not only
-		// will individual values stored in the meta ranges diverge, but
-		// there will be keys not represented by any ranges or vice
-		// versa).
-		key := keys.RangeDescriptorKey(desc.StartKey)
-		sl := stateloader.Make(desc.RangeID)
-		ms, err := sl.LoadMVCCStats(ctx, batch)
+	for i := range newDescs {
+		// See loqrecovery.RewriteRangeDescriptor for why rewriting only the
+		// range-local copy of the descriptor is sufficient.
+		abortedTxn, err := loqrecovery.RewriteRangeDescriptor(ctx, batch, clock.Now(), &newDescs[i])
 		if err != nil {
-			return nil, errors.Wrap(err, "loading MVCCStats")
-		}
-		err = storage.MVCCPutProto(ctx, batch, &ms, key, clock.Now(), nil /* txn */, &desc)
-		if wiErr := (*roachpb.WriteIntentError)(nil); errors.As(err, &wiErr) {
-			if len(wiErr.Intents) != 1 {
-				return nil, errors.Errorf("expected 1 intent, found %d: %s", len(wiErr.Intents), wiErr)
-			}
-			intent := wiErr.Intents[0]
-			// We rely on the property that transactions involving the range
-			// descriptor always start on the range-local descriptor's key.
-			// This guarantees that when the transaction commits, the intent
-			// will be resolved synchronously. If we see an intent on this
-			// key, we know that the transaction did not commit and we can
-			// abort it.
-			//
-			// TODO(nvanbenschoten): This need updating for parallel
-			// commits. If the transaction record is in the STAGING state,
-			// we can't just delete it. Simplest solution to this is to
-			// avoid parallel commits for membership change transactions; if
-			// we can't do that I don't think we'll be able to recover them
-			// with an offline tool.
-			fmt.Printf("Conflicting intent found on %s. Aborting txn %s to resolve.\n", key, intent.Txn.ID)
-
-			// A crude form of the intent resolution process: abort the
-			// transaction by deleting its record.
-			txnKey := keys.TransactionKey(intent.Txn.Key, intent.Txn.ID)
-			if err := storage.MVCCDelete(ctx, batch, &ms, txnKey, hlc.Timestamp{}, nil); err != nil {
-				return nil, err
-			}
-			update := roachpb.LockUpdate{
-				Span:   roachpb.Span{Key: intent.Key},
-				Txn:    intent.Txn,
-				Status: roachpb.ABORTED,
-			}
-			if _, err := storage.MVCCResolveWriteIntent(ctx, batch, &ms, update); err != nil {
-				return nil, err
-			}
-			// With the intent resolved, we can try again.
-			if err := storage.MVCCPutProto(ctx, batch, &ms, key, clock.Now(),
-				nil /* txn */, &desc); err != nil {
-				return nil, err
-			}
-		} else if err != nil {
 			batch.Close()
 			return nil, err
 		}
-		if err := sl.SetMVCCStats(ctx, batch, &ms); err != nil {
-			return nil, errors.Wrap(err, "updating MVCCStats")
+		if abortedTxn != nil {
+			fmt.Printf("Conflicting intent found on %s. Aborted txn %s to resolve.\n",
+				keys.RangeDescriptorKey(newDescs[i].StartKey), abortedTxn.ID)
 		}
 	}
 
@@ -1307,6 +1234,9 @@ func init() {
 	debugDoctorCmd.AddCommand(debugDoctorCmds...)
 	DebugCmd.AddCommand(debugDoctorCmd)
 
+	debugRecoverCmd.AddCommand(debugRecoverCmds...)
+	DebugCmd.AddCommand(debugRecoverCmd)
+
 	f := debugSyncBenchCmd.Flags()
 	f.IntVarP(&syncBenchOpts.Concurrency, "concurrency", "c", syncBenchOpts.Concurrency,
 		"number of concurrent writers")
@@ -1319,6 +1249,20 @@ func init() {
 	f.IntSliceVar(&removeDeadReplicasOpts.deadStoreIDs, "dead-store-ids", nil,
 		"list of dead store IDs")
 
+	f = debugRecoverCollectInfoCmd.Flags()
+	f.StringSliceVar(&debugRecoverCollectInfoOpts.storePaths, "store", nil,
+		"path to a store directory of the node to collect replica info from")
+
+	f = debugRecoverPlanCmd.Flags()
+	f.StringVarP(&debugRecoverPlanOpts.outputFile, "plan", "o", "",
+		"filename to write the plan to")
+	f.IntSliceVar(&debugRecoverPlanOpts.deadStoreIDs, "dead-store-ids", nil,
+		"list of dead store IDs, which must match the stores without collected replica info")
+
+	f = debugRecoverApplyPlanCmd.Flags()
+	f.StringSliceVar(&debugRecoverApplyPlanOpts.storePaths, "store", nil,
+		"path to a store directory of the node to apply the plan to")
+
 	f = debugMergeLogsCommand.Flags()
 	f.Var(flagutil.Time(&debugMergeLogsOpts.from), "from",
 		"time before which messages should be filtered")
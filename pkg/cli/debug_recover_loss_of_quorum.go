@@ -0,0 +1,283 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+var debugRecoverCmd = &cobra.Command{
+	Use:   "recover [command]",
+	Short: "commands to recover unavailable ranges after loss of quorum",
+	Long: `Commands to recover ranges that lost quorum because a majority of their
+replicas were permanently lost.
+
+These commands are UNSAFE and should only be used with the supervision of
+a Cockroach Labs engineer. They are a last-resort option to recover data
+after multiple node failures. The recovered data is not guaranteed to be
+consistent.
+
+Recovery is performed with all nodes stopped, in three steps:
+
+1. collect-info is run on every surviving node to gather information about
+   the replicas found on its stores.
+2. make-plan is run on the combined output of collect-info. It computes
+   which ranges lost quorum and chooses the most up-to-date surviving replica
+   of each of them to become its only voter.
+3. apply-plan is run with the resulting plan on every surviving node. It
+   rewrites the descriptors of the chosen replicas on that node's stores.
+
+Afterwards, the surviving nodes can be restarted. The dead nodes must never
+rejoin the cluster; if they did, data may be corrupted. As with
+unsafe-remove-dead-replicas, nodes should not be restarted until at least 10
+seconds have passed since they were stopped.
+`,
+	RunE: usageAndErr,
+}
+
+var debugRecoverCmds = []*cobra.Command{
+	debugRecoverCollectInfoCmd,
+	debugRecoverPlanCmd,
+	debugRecoverApplyPlanCmd,
+}
+
+var debugRecoverCollectInfoCmd = &cobra.Command{
+	Use:   "collect-info --store=<store-dir> [--store=<store-dir>...] [output-file]",
+	Short: "collect replica information from the stores of a stopped node",
+	Long: `
+Collects information about all replicas found on the given stores and writes
+it as JSON to the given file, or to stdout if no file is given. The node
+owning the stores must be stopped.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDebugRecoverCollectInfo,
+}
+
+var debugRecoverCollectInfoOpts struct {
+	storePaths []string
+}
+
+func runDebugRecoverCollectInfo(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+
+	var stores []storage.Engine
+	for _, storePath := range debugRecoverCollectInfoOpts.storePaths {
+		db, err := OpenExistingStore(storePath, stopper, true /* readOnly */)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open store at %s", storePath)
+		}
+		stores = append(stores, db)
+	}
+
+	replicaInfo, err := loqrecovery.CollectReplicaInfo(context.Background(), stores)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(replicaInfo, "", "  ")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		_, err = fmt.Printf("%s\n", out)
+		return err
+	}
+	if err := ioutil.WriteFile(args[0], out, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write replica info to %s", args[0])
+	}
+	fmt.Fprintf(stderr, "Collected info about %d replicas.\n", len(replicaInfo.Replicas))
+	return nil
+}
+
+var debugRecoverPlanCmd = &cobra.Command{
+	Use:   "make-plan [--dead-store-ids=<store ID>,...] [--plan=<plan-file>] <replica-info-file>...",
+	Short: "compute a recovery plan from the replica information of all surviving nodes",
+	Long: `
+Computes a plan to restore quorum to all ranges from the replica information
+collected by collect-info on every surviving node, and writes it to the file
+given by --plan, or to stdout if no file is given.
+
+Stores referenced by range descriptors from which no information was
+collected are considered dead. If --dead-store-ids is set, it must list
+exactly these stores.
+
+The plan is not written if the surviving replicas would not cover the whole
+key space exactly once.
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDebugRecoverMakePlan,
+}
+
+var debugRecoverPlanOpts struct {
+	outputFile   string
+	deadStoreIDs []int
+}
+
+func runDebugRecoverMakePlan(cmd *cobra.Command, args []string) error {
+	var nodes []loqrecovery.NodeReplicaInfo
+	for _, filename := range args {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read replica info file %s", filename)
+		}
+		var nodeInfo loqrecovery.NodeReplicaInfo
+		if err := json.Unmarshal(data, &nodeInfo); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal replica info from file %s", filename)
+		}
+		nodes = append(nodes, nodeInfo)
+	}
+
+	var deadStoreIDs []roachpb.StoreID
+	for _, id := range debugRecoverPlanOpts.deadStoreIDs {
+		deadStoreIDs = append(deadStoreIDs, roachpb.StoreID(id))
+	}
+
+	plan, report, err := loqrecovery.PlanReplicas(context.Background(), nodes, deadStoreIDs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "Total replicas analyzed: %d\n", report.TotalReplicas)
+	fmt.Fprintf(stderr, "Ranges without quorum: %d\n", len(plan.Updates))
+	fmt.Fprintf(stderr, "Discarded live replicas: %d\n", report.DiscardedNonSurvivors)
+	fmt.Fprintf(stderr, "Surviving stores: %v\n", report.PresentStores)
+	fmt.Fprintf(stderr, "Missing stores: %v\n", report.MissingStores)
+	for _, u := range plan.Updates {
+		fmt.Fprintf(stderr, "Recovering r%d %s: s%d becomes the only voter with replica ID %d\n",
+			u.RangeID, u.StartKey, u.NewReplica.StoreID, u.NewReplica.ReplicaID)
+	}
+	if len(report.Problems) > 0 {
+		for _, p := range report.Problems {
+			fmt.Fprintf(stderr, "Problem: %s\n", p)
+		}
+		return errors.Newf("found %d key space problems in the surviving replicas, refusing to create a plan",
+			len(report.Problems))
+	}
+
+	out, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	if debugRecoverPlanOpts.outputFile == "" {
+		_, err = fmt.Printf("%s\n", out)
+		return err
+	}
+	if err := ioutil.WriteFile(debugRecoverPlanOpts.outputFile, out, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write plan to %s", debugRecoverPlanOpts.outputFile)
+	}
+	return nil
+}
+
+var debugRecoverApplyPlanCmd = &cobra.Command{
+	Use:   "apply-plan --store=<store-dir> [--store=<store-dir>...] <plan-file>",
+	Short: "apply a recovery plan to the stores of a stopped node",
+	Long: `
+Applies the parts of a plan computed by make-plan which target the given
+stores. The node owning the stores must be stopped. Applying a plan more than
+once is harmless.
+
+This command will prompt for confirmation before committing its changes.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDebugRecoverApplyPlan,
+}
+
+var debugRecoverApplyPlanOpts struct {
+	storePaths []string
+}
+
+func runDebugRecoverApplyPlan(cmd *cobra.Command, args []string) error {
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	ctx := context.Background()
+
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return errors.Wrapf(err, "failed to read plan file %s", args[0])
+	}
+	var plan loqrecovery.ReplicaUpdatePlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal plan from file %s", args[0])
+	}
+
+	batches := make(map[roachpb.StoreID]storage.Batch)
+	stores := make(map[roachpb.StoreID]storage.ReadWriter)
+	for _, storePath := range debugRecoverApplyPlanOpts.storePaths {
+		db, err := OpenExistingStore(storePath, stopper, false /* readOnly */)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open store at %s", storePath)
+		}
+		storeIdent, err := kvserver.ReadStoreIdent(ctx, db)
+		if err != nil {
+			return err
+		}
+		batch := db.NewBatch()
+		defer batch.Close()
+		batches[storeIdent.StoreID] = batch
+		stores[storeIdent.StoreID] = batch
+	}
+
+	clock := hlc.NewClock(hlc.UnixNano, 0)
+	report, err := loqrecovery.PrepareUpdateReplicas(ctx, plan, clock, stores)
+	if err != nil {
+		return err
+	}
+	for _, r := range report.SkippedReplicas {
+		fmt.Printf("Replica %s was already updated\n", &r)
+	}
+	for _, r := range report.UpdatedReplicas {
+		fmt.Printf("Replica %s -> %s\n", &r.OldDescriptor, &r.NewDescriptor)
+		if r.AbortedTransactionID != uuid.Nil {
+			fmt.Printf("Aborted txn %s to remove its intent from the descriptor of r%d\n",
+				r.AbortedTransactionID, r.NewDescriptor.RangeID)
+		}
+	}
+	if len(report.UpdatedReplicas) == 0 {
+		fmt.Printf("Nothing to do\n")
+		return nil
+	}
+
+	fmt.Printf("Proceed with the above rewrites? [y/N] ")
+
+	reader := bufio.NewReader(os.Stdin)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n")
+	if line[0] == 'y' || line[0] == 'Y' {
+		fmt.Printf("Committing\n")
+		for _, batch := range batches {
+			if err := batch.Commit(true); err != nil {
+				return err
+			}
+		}
+	} else {
+		fmt.Printf("Aborting\n")
+	}
+	return nil
+}
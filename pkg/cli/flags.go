@@ -138,6 +138,14 @@ func durationFlag(f *pflag.FlagSet, valPtr *time.Duration, flagInfo cliflags.Fla
 	registerEnvVarDefault(f, flagInfo)
 }
 
+// float64Flag creates a float64 flag and registers it with the FlagSet.
+// The default value is taken from the variable pointed to by valPtr.
+// See context.go to initialize defaults.
+func float64Flag(f *pflag.FlagSet, valPtr *float64, flagInfo cliflags.FlagInfo) {
+	f.Float64VarP(valPtr, flagInfo.Name, flagInfo.Shorthand, *valPtr, flagInfo.Usage())
+	registerEnvVarDefault(f, flagInfo)
+}
+
 // varFlag creates a custom-variable flag and registers it with the FlagSet.
 // The default value is taken from the value's current value.
 // See context.go to initialize defaults.
@@ -455,6 +463,8 @@ func init() {
 	for _, cmd := range serverCmds {
 		f := cmd.Flags()
 		varFlag(f, &startCtx.logDir, cliflags.LogDir)
+		stringFlag(f, &startCtx.logConfigInput, cliflags.Log)
+		varFlag(f, &fileContentsValue{dst: &startCtx.logConfigInput}, cliflags.LogConfigFile)
 		varFlag(f,
 			pflag.PFlagFromGoFlag(flag.Lookup(logflags.LogFilesCombinedMaxSizeName)).Value,
 			cliflags.LogDirMaxSize)
@@ -633,6 +643,7 @@ func init() {
 		varFlag(f, &zipCtx.nodes.inclusive, cliflags.ZipNodes)
 		varFlag(f, &zipCtx.nodes.exclusive, cliflags.ZipExcludeNodes)
 		boolFlag(f, &zipCtx.redactLogs, cliflags.ZipRedactLogs)
+		boolFlag(f, &zipCtx.redact, cliflags.ZipRedact)
 		durationFlag(f, &zipCtx.cpuProfDuration, cliflags.ZipCPUProfileDuration)
 	}
 
@@ -763,6 +774,11 @@ func init() {
 	{
 		boolFlag(stmtDiagDeleteCmd.Flags(), &stmtDiagCtx.all, cliflags.StmtDiagDeleteAll)
 		boolFlag(stmtDiagCancelCmd.Flags(), &stmtDiagCtx.all, cliflags.StmtDiagCancelAll)
+
+		f := stmtDiagRequestCmd.Flags()
+		durationFlag(f, &stmtDiagCtx.minLatency, cliflags.StmtDiagMinLatency)
+		float64Flag(f, &stmtDiagCtx.samplingProbability, cliflags.StmtDiagSamplingProbability)
+		durationFlag(f, &stmtDiagCtx.expiresAfter, cliflags.StmtDiagExpiresAfter)
 	}
 
 	// sqlfmt command.
@@ -816,6 +832,10 @@ func init() {
 		// NB: this also gets PreRun treatment via extraServerFlagInit to populate BaseCfg.SQLAddr.
 		varFlag(f, addrSetter{&serverSQLAddr, &serverSQLPort}, cliflags.ListenSQLAddr)
 		varFlag(f, addrSetter{&serverHTTPAddr, &serverHTTPPort}, cliflags.ListenHTTPAddr)
+		// NB: if --listen-addr is set, the SQL server accepts the RPCs of the
+		// other SQL servers of the tenant, see runStartSQL.
+		varFlag(f, addrSetter{&startCtx.serverListenAddr, &serverListenPort}, cliflags.ListenAddr)
+		varFlag(f, addrSetter{&serverAdvertiseAddr, &serverAdvertisePort}, cliflags.AdvertiseAddr)
 
 		stringSliceFlag(f, &serverCfg.SQLConfig.TenantKVAddrs, cliflags.KVAddrs)
 		varFlag(f, &startCtx.logDir, cliflags.LogDir)
@@ -1030,3 +1030,49 @@ func TestHttpHostFlagValue(t *testing.T) {
 		}
 	}
 }
+
+func TestLogConfigFlags(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	// Avoid leaking configuration changes after the tests end.
+	defer initCLIDefaults()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	const fileConfig = "sinks: {stderr: {filter: ERROR}}"
+	configFile := filepath.Join(dir, "log.yaml")
+	if err := ioutil.WriteFile(configFile, []byte(fileConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := startCmd.Flags()
+	testData := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"start"}, ""},
+		{[]string{"start", "--log", "sinks: {stderr: {filter: WARNING}}"}, "sinks: {stderr: {filter: WARNING}}"},
+		{[]string{"start", "--log-config-file", configFile}, fileConfig},
+		// The last flag wins.
+		{[]string{"start", "--log-config-file", configFile, "--log", "{}"}, "{}"},
+	}
+
+	for _, td := range testData {
+		t.Run(strings.Join(td.args, " "), func(t *testing.T) {
+			initCLIDefaults()
+			if err := f.Parse(td.args); err != nil {
+				t.Fatalf("Parse(%#v) got unexpected error: %v", td.args, err)
+			}
+			if startCtx.logConfigInput != td.expected {
+				t.Errorf("expected %q, got %q", td.expected, startCtx.logConfigInput)
+			}
+		})
+	}
+
+	initCLIDefaults()
+	err := f.Parse([]string{"start", "--log-config-file", filepath.Join(dir, "nonexistent")})
+	if !testutils.IsError(err, "no such file or directory") {
+		t.Fatalf("expected file not found error, got %v", err)
+	}
+}
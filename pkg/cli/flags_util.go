@@ -13,6 +13,7 @@ package cli
 import (
 	gohex "encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"regexp"
 	"strconv"
@@ -129,6 +130,30 @@ func (s *statementsValue) Set(value string) error {
 	return nil
 }
 
+// fileContentsValue is an implementation of pflag.Value that reads
+// the contents of the named file into a string.
+type fileContentsValue struct {
+	dst      *string
+	fileName string
+}
+
+// Type implements the pflag.Value interface.
+func (f *fileContentsValue) Type() string { return "<file>" }
+
+// String implements the pflag.Value interface.
+func (f *fileContentsValue) String() string { return f.fileName }
+
+// Set implements the pflag.Value interface.
+func (f *fileContentsValue) Set(fileName string) error {
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	f.fileName = fileName
+	*f.dst = string(b)
+	return nil
+}
+
 type dumpMode int
 
 const (
@@ -141,6 +141,37 @@ func newRowIter(rows *sqlRows, showMoreChars bool) *rowIter {
 	}
 }
 
+// rowFilter modifies in place a row of a result set before it is
+// rendered. cols contains the names of the columns of the row.
+type rowFilter func(cols, row []string)
+
+// filteredRowIter is an implementation of the rowStrIter interface
+// which passes the rows of another iterator through a rowFilter.
+type filteredRowIter struct {
+	rowStrIter
+	cols   []string
+	filter rowFilter
+}
+
+func (iter *filteredRowIter) Next() (row []string, err error) {
+	row, err = iter.rowStrIter.Next()
+	if err == nil {
+		iter.filter(iter.cols, row)
+	}
+	return row, err
+}
+
+func (iter *filteredRowIter) ToSlice() ([][]string, error) {
+	allRows, err := iter.rowStrIter.ToSlice()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range allRows {
+		iter.filter(iter.cols, row)
+	}
+	return allRows, nil
+}
+
 // rowReporter is used to render result sets.
 // - describe is called once in any case with the result column set.
 // - beforeFirstRow is called once upon the first row encountered.
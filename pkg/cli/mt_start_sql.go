@@ -16,6 +16,7 @@ import (
 	"os/signal"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -52,6 +53,11 @@ well unless it can be verified using a trusted root certificate store. That is,
   sslmode=verify-ca.
 - ca-server-tenant.crt needs to be present on the SQL server.
 - ca-client-tenant.crt needs to be present on the KV server.
+
+If --listen-addr is specified, the SQL server accepts the RPCs of the other SQL
+servers of the same tenant at that address, which lets them distribute the
+execution of queries across each other. This requires the node.{crt,key} pair,
+and --sql-addr to be set to a different address.
 `,
 	Args: cobra.NoArgs,
 	RunE: MaybeDecorateGRPCError(runStartSQL),
@@ -74,6 +80,16 @@ func runStartSQL(cmd *cobra.Command, args []string) error {
 	}
 	defer stopper.Stop(ctx)
 
+	// The SQL servers of a tenant which accept each other's RPCs distribute
+	// the DistSQL flows across each other.
+	if cmd.Flags().Lookup(cliflags.ListenAddr.Name).Changed {
+		if serverCfg.Addr == serverCfg.SQLAddr {
+			return errors.Newf("--%s must differ from --%s",
+				cliflags.ListenSQLAddr.Name, cliflags.ListenAddr.Name)
+		}
+		serverCfg.SQLConfig.TenantServeSQLInstanceRPCs = true
+	}
+
 	st := serverCfg.BaseConfig.Settings
 
 	// TODO(tbg): this has to be passed in. See the upgrade strategy in:
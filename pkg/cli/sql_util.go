@@ -834,6 +834,15 @@ var tagsWithRowsAffected = map[string]struct{}{
 // runQueryAndFormatResults takes a 'query' with optional 'parameters'.
 // It runs the sql query and writes output to 'w'.
 func runQueryAndFormatResults(conn *sqlConn, w io.Writer, fn queryFunc) (err error) {
+	return runQueryAndFormatFilteredResults(conn, w, fn, nil /* filter */)
+}
+
+// runQueryAndFormatFilteredResults is like runQueryAndFormatResults,
+// but the rows are passed through 'filter', if non-nil, before being
+// formatted.
+func runQueryAndFormatFilteredResults(
+	conn *sqlConn, w io.Writer, fn queryFunc, filter rowFilter,
+) (err error) {
 	startTime := timeutil.Now()
 	rows, isMultiStatementQuery, err := fn(conn)
 	if err != nil {
@@ -921,7 +930,11 @@ func runQueryAndFormatResults(conn *sqlConn, w io.Writer, fn queryFunc) (err err
 			if cleanup != nil {
 				defer cleanup()
 			}
-			return render(reporter, w, cols, newRowIter(rows, true), completedHook, noRowsHook)
+			var iter rowStrIter = newRowIter(rows, true)
+			if filter != nil {
+				iter = &filteredRowIter{rowStrIter: iter, cols: cols, filter: filter}
+			}
+			return render(reporter, w, cols, iter, completedHook, noRowsHook)
 		}(); err != nil {
 			return err
 		}
@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logflags"
 	"github.com/cockroachdb/cockroach/pkg/util/sdnotify"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -1096,6 +1097,24 @@ func setupAndInitializeLoggingAndProfiling(
 		return nil, err
 	}
 
+	// Set up the log sinks requested via --log, if any. This must
+	// happen before the secondary loggers are created, so that the
+	// channels routed to a file group do not open their default file.
+	if startCtx.logConfigInput != "" {
+		logConfig, err := logconfig.Parse(startCtx.logConfigInput)
+		if err != nil {
+			return nil, err
+		}
+		defaultLogDir := logOutputDirectory()
+		if err := logConfig.Validate(&defaultLogDir); err != nil {
+			return nil, errors.Wrap(err, "invalid logging configuration")
+		}
+		if _, err := log.ApplyConfig(logConfig); err != nil {
+			return nil, err
+		}
+		telemetry.Count("server.logging.config.custom")
+	}
+
 	// Record redaction usage for telemetry.
 	if log.RedactableLogsEnabled() {
 		telemetry.Count("server.logging.redactable_logs.enabled")
@@ -17,9 +17,11 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/errors"
 	"github.com/spf13/cobra"
 )
@@ -28,8 +30,8 @@ var stmtDiagCmd = &cobra.Command{
 	Use:   "statement-diag [command]",
 	Short: "commands for managing statement diagnostics bundles",
 	Long: `This set of commands can be used to manage and download statement diagnostic
-bundles, and to cancel outstanding diagnostics activation requests. Statement
-diagnostics can be activated from the UI or using EXPLAIN ANALYZE (DEBUG).`,
+bundles, and to create and cancel diagnostics activation requests. Statement
+diagnostics can also be activated from the UI or using EXPLAIN ANALYZE (DEBUG).`,
 	RunE: usageAndErr,
 }
 
@@ -95,9 +97,10 @@ func runStmtDiagList(cmd *cobra.Command, args []string) error {
 	// -- List outstanding activation requests --
 
 	rows, err = conn.Query(
-		`SELECT id, statement_fingerprint, requested_at
+		`SELECT id, statement_fingerprint, requested_at,
+		        extract(epoch FROM min_execution_latency), sampling_probability, expires_at
 		 FROM system.statement_diagnostics_requests
-		 WHERE NOT completed
+		 WHERE NOT completed AND (expires_at IS NULL OR expires_at > now())
 		 ORDER BY requested_at DESC`,
 		nil, /* args */
 	)
@@ -105,36 +108,117 @@ func runStmtDiagList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	buf.Reset()
-	w = tabwriter.NewWriter(&buf, 4, 0, 2, ' ', 0)
-	fmt.Fprint(w, "  ID\tActivation time\tStatement\n")
-	num = 0
+	type request struct {
+		id         int64
+		stmt       string
+		t          time.Time
+		conditions string
+	}
+	var requests []request
+	hasConditions := false
+	vals = make([]driver.Value, 6)
 	for {
 		if err := rows.Next(vals); err == io.EOF {
 			break
 		} else if err != nil {
 			return err
 		}
-		id := vals[0].(int64)
-		stmt := vals[1].(string)
-		t := vals[2].(time.Time)
-		fmt.Fprintf(w, "  %d\t%s\t%s\n", id, t.UTC().Format(timeFmt), stmt)
-		num++
+		req := request{
+			id:   vals[0].(int64),
+			stmt: vals[1].(string),
+			t:    vals[2].(time.Time),
+		}
+		var conditions []string
+		if minLatency, ok := vals[3].(float64); ok {
+			conditions = append(conditions, fmt.Sprintf("min latency %s",
+				time.Duration(minLatency*float64(time.Second))))
+		}
+		if samplingProbability, ok := vals[4].(float64); ok {
+			conditions = append(conditions, fmt.Sprintf("sampling probability %g", samplingProbability))
+		}
+		if expiresAt, ok := vals[5].(time.Time); ok {
+			conditions = append(conditions, fmt.Sprintf("expires at %s", expiresAt.UTC().Format(timeFmt)))
+		}
+		if len(conditions) > 0 {
+			req.conditions = strings.Join(conditions, ", ")
+			hasConditions = true
+		}
+		requests = append(requests, req)
 	}
 	if err := rows.Close(); err != nil {
 		return err
 	}
-	if num == 0 {
+	if len(requests) == 0 {
 		fmt.Printf("No outstanding activation requests.\n")
+		return nil
+	}
+
+	buf.Reset()
+	w = tabwriter.NewWriter(&buf, 4, 0, 2, ' ', 0)
+	// The conditions are only shown if some request has any, so as not to
+	// clutter the common case.
+	if hasConditions {
+		fmt.Fprint(w, "  ID\tActivation time\tStatement\tConditions\n")
 	} else {
-		fmt.Printf("Outstanding activation requests:\n")
-		_ = w.Flush()
-		fmt.Print(buf.String())
+		fmt.Fprint(w, "  ID\tActivation time\tStatement\n")
+	}
+	for _, req := range requests {
+		fmt.Fprintf(w, "  %d\t%s\t%s", req.id, req.t.UTC().Format(timeFmt), req.stmt)
+		if hasConditions {
+			fmt.Fprintf(w, "\t%s", req.conditions)
+		}
+		fmt.Fprint(w, "\n")
 	}
+	fmt.Printf("Outstanding activation requests:\n")
+	_ = w.Flush()
+	fmt.Print(buf.String())
 
 	return nil
 }
 
+var stmtDiagRequestCmd = &cobra.Command{
+	Use:   "request <statement fingerprint> [options]",
+	Short: "request a statement diagnostics bundle",
+	Long: `Request a statement diagnostics bundle for the statements matching the given
+fingerprint, for example 'SELECT _ FROM _ WHERE _ = _'.
+
+By default, the bundle is collected for the next execution of the statement.
+With --min-latency, only an execution that is at least that slow is collected,
+and the request stays outstanding until such an execution is observed. Use
+--sampling-probability to only trace a fraction of the executions, and
+--expires-after to abandon the request if it isn't satisfied in time.`,
+	Args: cobra.ExactArgs(1),
+	RunE: MaybeDecorateGRPCError(runStmtDiagRequest),
+}
+
+func runStmtDiagRequest(cmd *cobra.Command, args []string) error {
+	if p := stmtDiagCtx.samplingProbability; p < 0 || p > 1 {
+		return errors.Newf("--%s must be in range [0, 1]", cliflags.StmtDiagSamplingProbability.Name)
+	}
+	if stmtDiagCtx.minLatency < 0 {
+		return errors.Newf("--%s must not be negative", cliflags.StmtDiagMinLatency.Name)
+	}
+	if stmtDiagCtx.expiresAfter < 0 {
+		return errors.Newf("--%s must not be negative", cliflags.StmtDiagExpiresAfter.Name)
+	}
+
+	conn, err := makeSQLClient("cockroach statement-diag", useSystemDb)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Exec(
+		"SELECT crdb_internal.request_statement_bundle($1, $2, $3::INTERVAL, $4::INTERVAL)",
+		[]driver.Value{
+			args[0],
+			stmtDiagCtx.samplingProbability,
+			stmtDiagCtx.minLatency.String(),
+			stmtDiagCtx.expiresAfter.String(),
+		},
+	)
+}
+
 var stmtDiagDownloadCmd = &cobra.Command{
 	Use:   "download <bundle id> <file> [options]",
 	Short: "download statement diagnostics bundle into a zip file",
@@ -348,6 +432,7 @@ func runStmtDiagCancel(cmd *cobra.Command, args []string) error {
 
 var stmtDiagCmds = []*cobra.Command{
 	stmtDiagListCmd,
+	stmtDiagRequestCmd,
 	stmtDiagDownloadCmd,
 	stmtDiagDeleteCmd,
 	stmtDiagCancelCmd,
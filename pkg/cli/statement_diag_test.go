@@ -85,6 +85,27 @@ func Example_statement_diag() {
 	c.RunWithArgs([]string{"statement-diag", "cancel", "--all"})
 	c.RunWithArgs([]string{"statement-diag", "list"})
 
+	// Conditional requests. The expired request is not listed.
+	_, err = c.RunWithCaptureArgs([]string{"sql", "-e",
+		`INSERT INTO system.statement_diagnostics_requests
+		   (id, completed, statement_fingerprint, requested_at, min_execution_latency, sampling_probability, expires_at)
+		 VALUES (7, FALSE, 'SELECT _ * _', '2010-01-02 03:04:13', '100ms', 0.5, NULL),
+		        (8, FALSE, 'SELECT _ % _', '2010-01-02 03:04:14', NULL, NULL, '2010-01-02 03:04:15')`,
+	})
+	if err != nil {
+		log.Fatalf(context.Background(), "Couldn't execute sql: %s", err)
+	}
+	c.RunWithArgs([]string{"statement-diag", "list"})
+	c.RunWithArgs([]string{"statement-diag", "request"})
+	c.RunWithArgs([]string{"statement-diag", "request", "SELECT _ - _", "--sampling-probability=2"})
+	c.RunWithArgs([]string{"statement-diag", "request", "SELECT _ - _", "--min-latency=-1s"})
+	c.RunWithArgs([]string{"statement-diag", "request", "SELECT _ - _",
+		"--min-latency=100ms", "--sampling-probability=0.5", "--expires-after=1h"})
+	c.RunWithArgs([]string{"sql", "-e",
+		"SELECT min_execution_latency, sampling_probability, expires_at > now() AS pending " +
+			"FROM system.statement_diagnostics_requests WHERE NOT completed AND statement_fingerprint = 'SELECT _ - _'",
+	})
+
 	// Output:
 	// statement-diag list
 	// Statement diagnostics bundles:
@@ -149,4 +170,19 @@ func Example_statement_diag() {
 	// statement-diag list
 	// No statement diagnostics bundles available.
 	// No outstanding activation requests.
+	// statement-diag list
+	// No statement diagnostics bundles available.
+	// Outstanding activation requests:
+	//   ID  Activation time          Statement     Conditions
+	//   7   2010-01-02 03:04:13 UTC  SELECT _ * _  min latency 100ms, sampling probability 0.5
+	// statement-diag request
+	// ERROR: accepts 1 arg(s), received 0
+	// statement-diag request SELECT _ - _ --sampling-probability=2
+	// ERROR: --sampling-probability must be in range [0, 1]
+	// statement-diag request SELECT _ - _ --min-latency=-1s
+	// ERROR: --min-latency must not be negative
+	// statement-diag request SELECT _ - _ --min-latency=100ms --sampling-probability=0.5 --expires-after=1h
+	// sql -e SELECT min_execution_latency, sampling_probability, expires_at > now() AS pending FROM system.statement_diagnostics_requests WHERE NOT completed AND statement_fingerprint = 'SELECT _ - _'
+	// min_execution_latency	sampling_probability	pending
+	// 00:00:00.1	0.5	true
 }
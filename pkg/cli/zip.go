@@ -60,10 +60,13 @@ requires the cluster to be live.
 
 // Tables containing cluster-wide info that are collected in a debug zip.
 var debugZipTablesPerCluster = []string{
+	"crdb_internal.cluster_contended_tables",
+	"crdb_internal.cluster_contention_events",
 	"crdb_internal.cluster_queries",
 	"crdb_internal.cluster_sessions",
 	"crdb_internal.cluster_settings",
 	"crdb_internal.cluster_transactions",
+	"crdb_internal.index_usage_statistics",
 
 	"crdb_internal.jobs",
 	"system.jobs",       // get the raw, restorable jobs records too.
@@ -220,6 +223,9 @@ func runDebugZip(cmd *cobra.Command, args []string) (retErr error) {
 		settingsName  = base + "/settings"
 	)
 
+	// --redact implies --redact-logs.
+	redactLogs := zipCtx.redactLogs || zipCtx.redact
+
 	baseCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -307,7 +313,11 @@ func runDebugZip(cmd *cobra.Command, args []string) (retErr error) {
 		},
 		{
 			fn: func(ctx context.Context) (interface{}, error) {
-				return admin.RangeLog(ctx, &serverpb.RangeLogRequest{})
+				rangeLog, err := admin.RangeLog(ctx, &serverpb.RangeLogRequest{})
+				if err == nil && zipCtx.redact {
+					redactRangeLog(rangeLog)
+				}
+				return rangeLog, err
 			},
 			pathName: rangelogName,
 		},
@@ -413,7 +423,13 @@ func runDebugZip(cmd *cobra.Command, args []string) (retErr error) {
 						if err != nil {
 							return err
 						}
-						pd = profData{data: resp.Data}
+						data := resp.Data
+						if zipCtx.redact {
+							if data, err = redactCPUProfile(data); err != nil {
+								return errors.Wrap(err, "redacting cpu profile")
+							}
+						}
+						pd = profData{data: data}
 						return nil
 					})
 					if err != nil {
@@ -632,7 +648,7 @@ func runDebugZip(cmd *cobra.Command, args []string) (retErr error) {
 						func(ctx context.Context) error {
 							entries, err = status.LogFile(
 								ctx, &serverpb.LogFileRequest{
-									NodeId: id, File: file.Name, Redact: zipCtx.redactLogs, KeepRedactable: true,
+									NodeId: id, File: file.Name, Redact: redactLogs, KeepRedactable: true,
 								})
 							return err
 						}); err != nil {
@@ -657,7 +673,7 @@ func runDebugZip(cmd *cobra.Command, args []string) (retErr error) {
 						// most conservative way possible. (It's not great that
 						// possibly confidential data flew over the network, but
 						// at least it stops here.)
-						if zipCtx.redactLogs && !e.Redactable {
+						if redactLogs && !e.Redactable {
 							e.Message = "REDACTEDBYZIP"
 							// We're also going to print a warning at the end.
 							warnRedactLeak = true
@@ -691,6 +707,9 @@ func runDebugZip(cmd *cobra.Command, args []string) (retErr error) {
 						ranges.Ranges[j].State.Desc.RangeID
 				})
 				for _, r := range ranges.Ranges {
+					if zipCtx.redact {
+						redactRangeInfo(&r)
+					}
 					name := fmt.Sprintf("%s/ranges/%s", prefix, r.State.Desc.RangeID)
 					if err := z.createJSON(name+".json", r); err != nil {
 						return err
@@ -735,6 +754,9 @@ func runDebugZip(cmd *cobra.Command, args []string) (retErr error) {
 					err := runZipRequestWithTimeout(baseCtx, fmt.Sprintf("requesting table details for %s.%s", dbName, tableName), timeout,
 						func(ctx context.Context) error {
 							table, err = admin.TableDetails(ctx, &serverpb.TableDetailsRequest{Database: dbName, Table: tableName})
+							if err == nil && zipCtx.redact {
+								redactTableDetails(table)
+							}
 							return err
 						})
 					if err := z.createJSONOrError(name+".json", table, err); err != nil {
@@ -821,6 +843,10 @@ func dumpTableDataForZip(
 ) error {
 	query := fmt.Sprintf(`SET statement_timeout = '%s'; SELECT %s FROM %s`, timeout, selectClause, table)
 	baseName := base + "/" + table
+	var filter rowFilter
+	if zipCtx.redact {
+		filter = zipTableRedactors[table]
+	}
 
 	fmt.Printf("retrieving SQL data for %s... ", table)
 	const maxRetries = 5
@@ -833,7 +859,7 @@ func dumpTableDataForZip(
 		}
 		// Pump the SQL rows directly into the zip writer, to avoid
 		// in-RAM buffering.
-		if err := runQueryAndFormatResults(conn, w, makeQuery(query)); err != nil {
+		if err := runQueryAndFormatFilteredResults(conn, w, makeQuery(query), filter); err != nil {
 			if cErr := z.createError(name, err); cErr != nil {
 				return cErr
 			}
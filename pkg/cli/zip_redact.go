@@ -0,0 +1,269 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bytes"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/redact"
+	"github.com/google/pprof/profile"
+)
+
+// This file contains the redaction performed by `debug zip --redact`.
+// The redaction is performed client-side, so that it also applies to
+// the data retrieved from nodes running previous versions.
+//
+// The data is redacted conservatively: a value which cannot be
+// anonymized, for example a SQL statement that fails to parse, is
+// replaced entirely by zipRedactedMarker.
+
+// zipRedactedMarker replaces the redacted values.
+var zipRedactedMarker = string(redact.RedactedMarker())
+
+// zipTableRedactors contains the redaction applied to the rows of the
+// tables collected in a debug zip. The tables not listed here do not
+// contain confidential data.
+var zipTableRedactors = map[string]rowFilter{
+	"crdb_internal.cluster_contention_events": redactColumns(redactValue, "key"),
+	"crdb_internal.cluster_queries":           redactColumns(redactStatements, "query"),
+	"crdb_internal.cluster_sessions":          redactColumns(redactStatements, "active_queries", "last_active_query"),
+	"crdb_internal.cluster_settings":          redactSettingValues,
+	"crdb_internal.cluster_transactions":      redactColumns(redactValue, "txn_string"),
+
+	"crdb_internal.jobs": chainRowFilters(
+		redactColumns(redactStatements, "description", "statement"),
+		redactColumns(redactValue, "error"),
+	),
+	"system.jobs":       redactColumns(redactValue, "payload", "progress", "hex_payload", "hex_progress"),
+	"system.descriptor": redactColumns(redactValue, "descriptor", "hex_descriptor"),
+
+	"crdb_internal.partitions": redactColumns(redactValue, "list_value", "range_value"),
+	"crdb_internal.zones": chainRowFilters(
+		redactColumns(redactStatements, "raw_config_sql", "full_config_sql"),
+		redactColumns(redactValue, "raw_config_yaml", "raw_config_protobuf", "full_config_yaml"),
+	),
+
+	"crdb_internal.node_queries":              redactColumns(redactStatements, "query"),
+	"crdb_internal.node_sessions":             redactColumns(redactStatements, "active_queries", "last_active_query"),
+	"crdb_internal.node_statement_statistics": redactColumns(redactValue, "last_error"),
+	"crdb_internal.node_transactions":         redactColumns(redactValue, "txn_string"),
+}
+
+// redactColumns returns a rowFilter which applies redactFn to the
+// values of the given columns.
+func redactColumns(redactFn func(string) string, names ...string) rowFilter {
+	return func(cols, row []string) {
+		for i, col := range cols {
+			for _, name := range names {
+				if col == name {
+					row[i] = redactFn(row[i])
+				}
+			}
+		}
+	}
+}
+
+// chainRowFilters returns a rowFilter which applies the given filters
+// in sequence.
+func chainRowFilters(filters ...rowFilter) rowFilter {
+	return func(cols, row []string) {
+		for _, f := range filters {
+			f(cols, row)
+		}
+	}
+}
+
+// redactValue redacts a value entirely. NULL and empty values are
+// preserved, as they do not contain anything confidential.
+func redactValue(s string) string {
+	if s == "" || s == "NULL" {
+		return s
+	}
+	return zipRedactedMarker
+}
+
+// redactStatements removes the constants from a list of SQL statements.
+func redactStatements(s string) string {
+	if s == "" || s == "NULL" {
+		return s
+	}
+	stmts, err := parser.Parse(s)
+	if err != nil {
+		return zipRedactedMarker
+	}
+	return stmts.StringWithFlags(tree.FmtHideConstants)
+}
+
+// redactExpr removes the constants from a SQL scalar expression.
+func redactExpr(s string) string {
+	if s == "" {
+		return s
+	}
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		return zipRedactedMarker
+	}
+	return tree.AsStringWithFlags(expr, tree.FmtHideConstants)
+}
+
+// redactSettingValues redacts the values of the cluster settings which
+// are not reportable, as found in crdb_internal.cluster_settings. The
+// values of the settings unknown to this binary are redacted as well.
+func redactSettingValues(cols, row []string) {
+	var name string
+	valueIdx := -1
+	for i, col := range cols {
+		switch col {
+		case "variable":
+			name = row[i]
+		case "value":
+			valueIdx = i
+		}
+	}
+	if valueIdx < 0 {
+		return
+	}
+	setting, ok := settings.Lookup(name, settings.LookupForReporting)
+	if _, masked := setting.(*settings.MaskedSetting); !ok || masked {
+		row[valueIdx] = zipRedactedMarker
+	}
+}
+
+// redactKey truncates a key of the SQL keyspace to the prefix that
+// identifies its table and index, which removes the indexed values.
+// It returns whether the key was truncated. The keys outside of the
+// SQL keyspace are returned unchanged.
+func redactKey(key roachpb.Key) (roachpb.Key, bool) {
+	_, tenID, err := keys.DecodeTenantPrefix(key)
+	if err != nil {
+		return nil, true
+	}
+	codec := keys.MakeSQLCodec(tenID)
+	rem, _, err := codec.DecodeTablePrefix(key)
+	if err != nil {
+		// Not a table key.
+		return key, false
+	}
+	if indexRem, _, _, err := codec.DecodeIndexPrefix(key); err == nil {
+		rem = indexRem
+	}
+	if len(rem) == 0 {
+		return key, false
+	}
+	return key[:len(key)-len(rem)], true
+}
+
+// redactPrettyKey returns the pretty-printed form of the redacted key.
+func redactPrettyKey(key roachpb.Key) string {
+	redacted, truncated := redactKey(key)
+	if truncated {
+		return redacted.String() + "/" + zipRedactedMarker
+	}
+	return redacted.String()
+}
+
+// redactRangeDescriptor returns a copy of the range descriptor with
+// redacted start and end keys.
+func redactRangeDescriptor(desc *roachpb.RangeDescriptor) *roachpb.RangeDescriptor {
+	if desc == nil {
+		return nil
+	}
+	redacted := *desc
+	startKey, _ := redactKey(desc.StartKey.AsRawKey())
+	endKey, _ := redactKey(desc.EndKey.AsRawKey())
+	redacted.StartKey = roachpb.RKey(startKey)
+	redacted.EndKey = roachpb.RKey(endKey)
+	return &redacted
+}
+
+// redactRangeInfo redacts the keys of the range in a response to the
+// Ranges RPC.
+func redactRangeInfo(r *serverpb.RangeInfo) {
+	if desc := r.State.Desc; desc != nil {
+		r.Span = serverpb.PrettySpan{
+			StartKey: redactPrettyKey(desc.StartKey.AsRawKey()),
+			EndKey:   redactPrettyKey(desc.EndKey.AsRawKey()),
+		}
+		r.State.Desc = redactRangeDescriptor(desc)
+	} else {
+		r.Span = serverpb.PrettySpan{StartKey: zipRedactedMarker, EndKey: zipRedactedMarker}
+	}
+}
+
+// redactRangeLog redacts the keys of the range descriptors in a
+// response to the RangeLog RPC.
+func redactRangeLog(rangeLog *serverpb.RangeLogResponse) {
+	for i := range rangeLog.Events {
+		e := &rangeLog.Events[i]
+		if info := e.Event.Info; info != nil {
+			redactedInfo := *info
+			redactedInfo.UpdatedDesc = redactRangeDescriptor(info.UpdatedDesc)
+			redactedInfo.NewDesc = redactRangeDescriptor(info.NewDesc)
+			redactedInfo.RemovedDesc = redactRangeDescriptor(info.RemovedDesc)
+			// The details may contain keys, e.g. for load-based splits.
+			redactedInfo.Details = redactValue(info.Details)
+			e.Event.Info = &redactedInfo
+			if redactedInfo.UpdatedDesc != nil {
+				e.PrettyInfo.UpdatedDesc = redactedInfo.UpdatedDesc.String()
+			}
+			if redactedInfo.NewDesc != nil {
+				e.PrettyInfo.NewDesc = redactedInfo.NewDesc.String()
+			}
+		}
+		e.PrettyInfo.Details = redactValue(e.PrettyInfo.Details)
+	}
+}
+
+// redactTableDetails removes the constants from the schema of a table
+// in a response to the TableDetails RPC. They may contain confidential
+// data, for example in partitioning clauses or default expressions.
+func redactTableDetails(table *serverpb.TableDetailsResponse) {
+	table.CreateTableStatement = redactStatements(table.CreateTableStatement)
+	for i := range table.Columns {
+		col := &table.Columns[i]
+		col.DefaultValue = redactExpr(col.DefaultValue)
+		col.GenerationExpression = redactExpr(col.GenerationExpression)
+	}
+}
+
+// zipRedactedProfileLabels are the labels of the CPU profiles which
+// identify the clients of the cluster.
+var zipRedactedProfileLabels = []string{"appname", "addr"}
+
+// redactCPUProfile redacts the labels of the samples of a CPU profile
+// which identify the clients. The statements in the labels are already
+// anonymized by the server.
+func redactCPUProfile(data []byte) ([]byte, error) {
+	p, err := profile.ParseData(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range p.Sample {
+		for _, label := range zipRedactedProfileLabels {
+			if values, ok := s.Label[label]; ok {
+				for i := range values {
+					values[i] = zipRedactedMarker
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
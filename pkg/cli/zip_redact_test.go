@@ -0,0 +1,147 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// TestZipTableRedactorsAreCollected verifies that the redaction of
+// `debug zip --redact` is only specified for collected tables, so that
+// it doesn't silently stop applying when a table is renamed.
+func TestZipTableRedactorsAreCollected(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	collected := make(map[string]bool)
+	for _, table := range append(debugZipTablesPerCluster, debugZipTablesPerNode...) {
+		collected[table] = true
+	}
+	for table := range zipTableRedactors {
+		require.True(t, collected[table], "%s is not collected in debug zip", table)
+	}
+}
+
+func TestZipRedactRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		table    string
+		cols     []string
+		row      []string
+		expected []string
+	}{
+		{
+			table:    "crdb_internal.node_queries",
+			cols:     []string{"query_id", "query"},
+			row:      []string{"1", "SELECT * FROM t WHERE k = 'secret'"},
+			expected: []string{"1", "SELECT * FROM t WHERE k = _"},
+		},
+		{
+			table:    "crdb_internal.cluster_sessions",
+			cols:     []string{"active_queries", "last_active_query"},
+			row:      []string{"INSERT INTO t VALUES (1, 'a'); SELECT 2", "NULL"},
+			expected: []string{"INSERT INTO t VALUES (_, _); SELECT _", "NULL"},
+		},
+		{
+			// A statement that fails to parse is redacted entirely.
+			table:    "crdb_internal.jobs",
+			cols:     []string{"description", "error"},
+			row:      []string{"GC for DROP TABLE secret", "duplicate key value"},
+			expected: []string{zipRedactedMarker, zipRedactedMarker},
+		},
+		{
+			table:    "crdb_internal.cluster_settings",
+			cols:     []string{"variable", "value", "type"},
+			row:      []string{"cluster.organization", "ACME", "s"},
+			expected: []string{"cluster.organization", zipRedactedMarker, "s"},
+		},
+		{
+			table:    "crdb_internal.cluster_settings",
+			cols:     []string{"variable", "value", "type"},
+			row:      []string{"sql.defaults.distsql", "auto", "e"},
+			expected: []string{"sql.defaults.distsql", "auto", "e"},
+		},
+		{
+			table:    "crdb_internal.cluster_settings",
+			cols:     []string{"variable", "value", "type"},
+			row:      []string{"some.unknown.setting", "1", "i"},
+			expected: []string{"some.unknown.setting", zipRedactedMarker, "i"},
+		},
+		{
+			table:    "crdb_internal.partitions",
+			cols:     []string{"name", "list_value", "range_value"},
+			row:      []string{"p1", "('secret')", "NULL"},
+			expected: []string{"p1", zipRedactedMarker, "NULL"},
+		},
+	} {
+		tc.row = append([]string(nil), tc.row...)
+		zipTableRedactors[tc.table](tc.cols, tc.row)
+		require.Equal(t, tc.expected, tc.row, "%s", tc.table)
+	}
+}
+
+func TestZipRedactKey(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tenantCodec := keys.MakeSQLCodec(roachpb.MakeTenantID(5))
+	for _, tc := range []struct {
+		key      roachpb.Key
+		expected string
+	}{
+		{roachpb.KeyMin, "/Min"},
+		{keys.NodeLivenessPrefix, "/System/NodeLiveness"},
+		{keys.SystemSQLCodec.TablePrefix(53), "/Table/53"},
+		{keys.SystemSQLCodec.IndexPrefix(53, 1), "/Table/53/1"},
+		{
+			encoding.EncodeStringAscending(keys.SystemSQLCodec.IndexPrefix(53, 1), "secret"),
+			"/Table/53/1/" + zipRedactedMarker,
+		},
+		{
+			encoding.EncodeStringAscending(tenantCodec.IndexPrefix(53, 2), "secret"),
+			"/Tenant/5/Table/53/2/" + zipRedactedMarker,
+		},
+	} {
+		require.Equal(t, tc.expected, redactPrettyKey(tc.key))
+	}
+}
+
+func TestZipRedactRangeInfo(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	startKey := encoding.EncodeStringAscending(keys.SystemSQLCodec.IndexPrefix(53, 1), "a")
+	endKey := encoding.EncodeStringAscending(keys.SystemSQLCodec.IndexPrefix(53, 1), "z")
+	desc := &roachpb.RangeDescriptor{
+		RangeID:  5,
+		StartKey: roachpb.RKey(startKey),
+		EndKey:   roachpb.RKey(endKey),
+	}
+	var r serverpb.RangeInfo
+	r.Span = serverpb.PrettySpan{StartKey: `/Table/53/1/"a"`, EndKey: `/Table/53/1/"z"`}
+	r.State.Desc = desc
+	redactRangeInfo(&r)
+
+	require.Equal(t, serverpb.PrettySpan{
+		StartKey: "/Table/53/1/" + zipRedactedMarker,
+		EndKey:   "/Table/53/1/" + zipRedactedMarker,
+	}, r.Span)
+	require.Equal(t, roachpb.RKey(keys.SystemSQLCodec.IndexPrefix(53, 1)), r.State.Desc.StartKey)
+	require.Equal(t, roachpb.RKey(keys.SystemSQLCodec.IndexPrefix(53, 1)), r.State.Desc.EndKey)
+	require.Equal(t, roachpb.RangeID(5), r.State.Desc.RangeID)
+	// The original descriptor is not modified.
+	require.Equal(t, roachpb.RKey(startKey), desc.StartKey)
+}
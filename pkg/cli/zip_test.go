@@ -79,7 +79,9 @@ table_name NOT IN (
 	'predefined_comments',
 	'session_trace',
 	'session_variables',
-	'tables'
+	'statement_statistics',
+	'tables',
+	'transaction_statistics'
 )
 ORDER BY name ASC`)
 	assert.NoError(t, err)
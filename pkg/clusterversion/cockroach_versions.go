@@ -81,6 +81,14 @@ const (
 	VersionUpdateScheduledJobsSchema
 	VersionCreateLoginPrivilege
 	VersionHBAForNonTLS
+	VersionNonVotingReplicas
+	VersionSQLStatsTables
+	VersionAlterSystemStmtDiagReqs
+	VersionRoleAuditPolicies
+	VersionPlanBaselines
+	VersionStatisticsExpressions
+	VersionSQLInstancesTable
+	VersionReplicatedLocks
 
 	// Add new versions here (step one of two).
 )
@@ -606,6 +614,56 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionHBAForNonTLS,
 		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 21},
 	},
+	{
+		// VersionNonVotingReplicas is when range descriptors may contain
+		// replicas of type NON_VOTER, as placed by the allocator according to
+		// the num_voters zone config field.
+		Key:     VersionNonVotingReplicas,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 22},
+	},
+	{
+		// VersionSQLStatsTables is when the system.statement_statistics and
+		// system.transaction_statistics tables are introduced.
+		Key:     VersionSQLStatsTables,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 23},
+	},
+	{
+		// VersionAlterSystemStmtDiagReqs is when the
+		// system.statement_diagnostics_requests table gains the columns that make
+		// conditional diagnostics requests possible.
+		Key:     VersionAlterSystemStmtDiagReqs,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 24},
+	},
+	{
+		// VersionRoleAuditPolicies is when the system.role_audit_policies table
+		// is introduced.
+		Key:     VersionRoleAuditPolicies,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 25},
+	},
+	{
+		// VersionPlanBaselines is when the system.plan_baselines table is
+		// introduced.
+		Key:     VersionPlanBaselines,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 26},
+	},
+	{
+		// VersionStatisticsExpressions is when the expression column is added to
+		// system.table_statistics, allowing statistics on expressions.
+		Key:     VersionStatisticsExpressions,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 27},
+	},
+	{
+		// VersionSQLInstancesTable is when the system.sql_instances table, in
+		// which the SQL pods of a tenant register themselves, is introduced.
+		Key:     VersionSQLInstancesTable,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 28},
+	},
+	{
+		// VersionReplicatedLocks is when locking reads can acquire replicated
+		// locks, which are stored in the LocalRangeLockTablePrefix keyspace.
+		Key:     VersionReplicatedLocks,
+		Version: roachpb.Version{Major: 20, Minor: 1, Unstable: 29},
+	},
 
 	// Add new versions here (step two of two).
 })
@@ -243,6 +243,30 @@ func (z *ZoneConfig) IsComplete() bool {
 		(!z.InheritedConstraints) && (!z.InheritedLeasePreferences))
 }
 
+// GetNumVoters returns the desired number of voting replicas for the zone. If
+// NumVoters is unset, every replica is a voter and NumReplicas is returned.
+func (z *ZoneConfig) GetNumVoters() int32 {
+	if z.NumVoters != nil {
+		return *z.NumVoters
+	}
+	if z.NumReplicas != nil {
+		return *z.NumReplicas
+	}
+	return 0
+}
+
+// GetNumNonVoters returns the desired number of non-voting replicas for the
+// zone.
+func (z *ZoneConfig) GetNumNonVoters() int32 {
+	if z.NumReplicas == nil {
+		return 0
+	}
+	if n := *z.NumReplicas - z.GetNumVoters(); n > 0 {
+		return n
+	}
+	return 0
+}
+
 // ValidateTandemFields returns an error if the ZoneConfig to be written
 // specifies a configuration that could cause problems with the introduction
 // of cascading zone configs.
@@ -255,6 +279,9 @@ func (z *ZoneConfig) ValidateTandemFields() error {
 	if numConstrainedRepls > 0 && z.NumReplicas == nil {
 		return fmt.Errorf("when per-replica constraints are set, num_replicas must be set as well")
 	}
+	if z.NumVoters != nil && z.NumReplicas == nil {
+		return fmt.Errorf("when num_voters is set, num_replicas must be set as well")
+	}
 	if (z.RangeMinBytes != nil || z.RangeMaxBytes != nil) &&
 		(z.RangeMinBytes == nil || z.RangeMaxBytes == nil) {
 		return fmt.Errorf("range_min_bytes and range_max_bytes must be set together")
@@ -290,6 +317,18 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	if z.NumVoters != nil {
+		switch {
+		case *z.NumVoters <= 0:
+			return fmt.Errorf("at least one voting replica is required")
+		case *z.NumVoters == 2:
+			return fmt.Errorf("at least 3 voting replicas are required for multi-replica configurations")
+		case z.NumReplicas != nil && *z.NumVoters > *z.NumReplicas:
+			return fmt.Errorf("num_voters (%d) cannot be greater than num_replicas (%d)",
+				*z.NumVoters, *z.NumReplicas)
+		}
+	}
+
 	if z.RangeMaxBytes != nil && *z.RangeMaxBytes < base.MinRangeMaxBytes {
 		return fmt.Errorf("RangeMaxBytes %d less than minimum allowed %d",
 			*z.RangeMaxBytes, base.MinRangeMaxBytes)
@@ -367,6 +406,11 @@ func (z *ZoneConfig) InheritFromParent(parent *ZoneConfig) {
 			z.NumReplicas = proto.Int32(*parent.NumReplicas)
 		}
 	}
+	if z.NumVoters == nil {
+		if parent.NumVoters != nil {
+			z.NumVoters = proto.Int32(*parent.NumVoters)
+		}
+	}
 	if z.RangeMinBytes == nil {
 		if parent.RangeMinBytes != nil {
 			z.RangeMinBytes = proto.Int64(*parent.RangeMinBytes)
@@ -406,6 +450,12 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 				z.NumReplicas = proto.Int32(*other.NumReplicas)
 			}
 		}
+		if fieldName == "num_voters" {
+			z.NumVoters = nil
+			if other.NumVoters != nil {
+				z.NumVoters = proto.Int32(*other.NumVoters)
+			}
+		}
 		if fieldName == "range_min_bytes" {
 			z.RangeMinBytes = nil
 			if other.RangeMinBytes != nil {
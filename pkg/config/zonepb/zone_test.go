@@ -51,6 +51,35 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"at least 3 replicas are required for multi-replica configurations",
 		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(0),
+			},
+			"at least one voting replica is required",
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(2),
+			},
+			"at least 3 voting replicas are required for multi-replica configurations",
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(3),
+				NumVoters:   proto.Int32(5),
+			},
+			"num_voters \\(5\\) cannot be greater than num_replicas \\(3\\)",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(5),
+				NumVoters:     proto.Int32(3),
+				RangeMaxBytes: proto.Int64(1 << 20),
+			},
+			"",
+		},
 		{
 			ZoneConfig{
 				NumReplicas:   proto.Int32(1),
@@ -274,6 +303,12 @@ func TestZoneConfigValidateTandemFields(t *testing.T) {
 			},
 			"when per-replica constraints are set, num_replicas must be set as well",
 		},
+		{
+			ZoneConfig{
+				NumVoters: proto.Int32(3),
+			},
+			"when num_voters is set, num_replicas must be set as well",
+		},
 		{
 			ZoneConfig{
 				InheritedConstraints:      true,
@@ -78,11 +78,11 @@ var _ yaml.Unmarshaler = &ConstraintsList{}
 //
 // We use two different formats here, dependent on whether per-replica
 // constraints are being used in ConstraintsList:
-// 1. A legacy format when there are 0 or 1 Constraints and NumReplicas is
-//    zero:
-//    [c1, c2, c3]
-// 2. A per-replica format when NumReplicas is non-zero:
-//    {"c1,c2,c3": numReplicas1, "c4,c5": numReplicas2}
+//  1. A legacy format when there are 0 or 1 Constraints and NumReplicas is
+//     zero:
+//     [c1, c2, c3]
+//  2. A per-replica format when NumReplicas is non-zero:
+//     {"c1,c2,c3": numReplicas1, "c4,c5": numReplicas2}
 func (c ConstraintsList) MarshalYAML() (interface{}, error) {
 	// If per-replica Constraints aren't in use, marshal everything into a list
 	// for compatibility with pre-2.0-style configs.
@@ -203,6 +203,7 @@ type marshalableZoneConfig struct {
 	RangeMaxBytes                *int64            `json:"range_max_bytes" yaml:"range_max_bytes"`
 	GC                           *GCPolicy         `json:"gc"`
 	NumReplicas                  *int32            `json:"num_replicas" yaml:"num_replicas"`
+	NumVoters                    *int32            `json:"num_voters,omitempty" yaml:"num_voters,omitempty"`
 	Constraints                  ConstraintsList   `json:"constraints" yaml:"constraints,flow"`
 	LeasePreferences             []LeasePreference `json:"lease_preferences" yaml:"lease_preferences,flow"`
 	ExperimentalLeasePreferences []LeasePreference `json:"experimental_lease_preferences" yaml:"experimental_lease_preferences,flow,omitempty"`
@@ -225,6 +226,9 @@ func zoneConfigToMarshalable(c ZoneConfig) marshalableZoneConfig {
 	if c.NumReplicas != nil && *c.NumReplicas != 0 {
 		m.NumReplicas = proto.Int32(*c.NumReplicas)
 	}
+	if c.NumVoters != nil {
+		m.NumVoters = proto.Int32(*c.NumVoters)
+	}
 	m.Constraints = ConstraintsList{c.Constraints, c.InheritedConstraints}
 	if !c.InheritedLeasePreferences {
 		m.LeasePreferences = c.LeasePreferences
@@ -253,6 +257,9 @@ func zoneConfigFromMarshalable(m marshalableZoneConfig, c ZoneConfig) ZoneConfig
 	if m.NumReplicas != nil {
 		c.NumReplicas = proto.Int32(*m.NumReplicas)
 	}
+	if m.NumVoters != nil {
+		c.NumVoters = proto.Int32(*m.NumVoters)
+	}
 	c.Constraints = m.Constraints.Constraints
 	c.InheritedConstraints = m.Constraints.Inherited
 	if m.LeasePreferences != nil {
@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -137,6 +138,10 @@ type Registry struct {
 	// improving this API.
 	sessionBoundInternalExecutorFactory sqlutil.SessionBoundInternalExecutorFactory
 
+	// eventLogFn, if set, records a structured event in the event log as
+	// part of the provided transaction.
+	eventLogFn EventLogFn
+
 	// if non-empty, indicates path to file that prevents any job adoptions.
 	preventAdoptionFile string
 
@@ -237,6 +242,17 @@ func (r *Registry) SetSessionBoundInternalExecutorFactory(
 	r.sessionBoundInternalExecutorFactory = factory
 }
 
+// EventLogFn records a structured event in the event log as part of the
+// provided transaction.
+type EventLogFn func(ctx context.Context, txn *kv.Txn, targetID int32, event eventpb.EventPayload) error
+
+// SetEventLogFn sets the function used by the registry to record job
+// status changes in the event log. We expose this separately from the
+// constructor to avoid a circular dependency.
+func (r *Registry) SetEventLogFn(fn EventLogFn) {
+	r.eventLogFn = fn
+}
+
 // MetricsStruct returns the metrics for production monitoring of each job type.
 // They're all stored as the `metric.Struct` interface because of dependency
 // cycles.
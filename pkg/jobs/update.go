@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
@@ -197,6 +198,10 @@ func (j *Job) Update(ctx context.Context, updateFn UpdateFn) error {
 				"job %d: expected exactly one row affected, but %d rows affected by job update", *j.id, n,
 			)
 		}
+
+		if ju.md.Status != "" && ju.md.Status != status {
+			return j.registry.logStatusChange(ctx, txn, *j.id, payload, status, ju.md.Status)
+		}
 		return nil
 	}); err != nil {
 		return err
@@ -213,3 +218,38 @@ func (j *Job) Update(ctx context.Context, updateFn UpdateFn) error {
 	}
 	return nil
 }
+
+// logStatusChange records the transition of a job from one status to
+// another in the event log, as part of the transaction that updated the
+// job.
+func (r *Registry) logStatusChange(
+	ctx context.Context,
+	txn *kv.Txn,
+	jobID int64,
+	payload *jobspb.Payload,
+	prevStatus, newStatus Status,
+) error {
+	if r.eventLogFn == nil {
+		return nil
+	}
+	event := &eventpb.JobStateChange{
+		CommonJobEventDetails: eventpb.CommonJobEventDetails{
+			JobID:       jobID,
+			JobType:     payload.Type().String(),
+			Description: payload.Description,
+			User:        payload.Username,
+			Status:      string(newStatus),
+		},
+		PreviousStatus: string(prevStatus),
+		Error:          payload.Error,
+	}
+	for _, id := range payload.DescriptorIDs {
+		event.DescriptorIDs = append(event.DescriptorIDs, uint32(id))
+	}
+	// The event targets the first descriptor affected by the job, if any.
+	var targetID int32
+	if len(payload.DescriptorIDs) > 0 {
+		targetID = int32(payload.DescriptorIDs[0])
+	}
+	return r.eventLogFn(ctx, txn, targetID, event)
+}
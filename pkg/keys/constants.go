@@ -52,8 +52,9 @@ var (
 	// key suffixes.
 	localSuffixLength = 4
 
-	// There are four types of local key data enumerated below: replicated
-	// range-ID, unreplicated range-ID, range local, and store-local keys.
+	// There are five types of local key data enumerated below: replicated
+	// range-ID, unreplicated range-ID, range local, store-local, and lock
+	// table keys.
 
 	// 1. Replicated Range-ID keys
 	//
@@ -191,6 +192,14 @@ var (
 	// possible suggested compaction keys for a store.
 	LocalStoreSuggestedCompactionsMax = LocalStoreSuggestedCompactionsMin.PrefixEnd()
 
+	// 5. Lock table keys
+	//
+	// LocalRangeLockTablePrefix specifies the key prefix for the replicated
+	// lock table. It is immediately followed by the locked key, encoded using
+	// EncodeBytes, so that the replicated locks on a range's keys sort
+	// together and are addressable to the range containing those keys.
+	LocalRangeLockTablePrefix = roachpb.Key(makeKey(localPrefix, roachpb.RKey("z")))
+
 	// The global keyspace includes the meta{1,2}, system, system tenant SQL
 	// keys, and non-system tenant SQL keys.
 
@@ -380,6 +389,11 @@ const (
 	ScheduledJobsTableID                = 37
 	TenantsRangesID                     = 38 // pseudo
 	SqllivenessID                       = 39
+	StatementStatisticsTableID          = 40
+	TransactionStatisticsTableID        = 41
+	RoleAuditPoliciesTableID            = 42
+	PlanBaselinesTableID                = 43
+	SQLInstancesTableID                 = 44
 
 	// CommentType is type for system.comments
 	DatabaseCommentType = 0
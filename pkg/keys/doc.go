@@ -155,11 +155,12 @@ package keys
 var _ = [...]interface{}{
 	MinKey,
 
-	// There are four types of local key data enumerated below: replicated
-	// range-ID, unreplicated range-ID, range local, and store-local keys.
-	// Local keys are constructed using a prefix, an optional infix, and a
-	// suffix. The prefix and infix are used to disambiguate between the four
-	// types of local keys listed above, and determines inter-group ordering.
+	// There are five types of local key data enumerated below: replicated
+	// range-ID, unreplicated range-ID, range local, store-local, and lock
+	// table keys. Local keys are constructed using a prefix, an optional
+	// infix, and a suffix. The prefix and infix are used to disambiguate
+	// between the five types of local keys listed above, and determines
+	// inter-group ordering.
 	// The string comment next to each symbol below is the suffix pertaining to
 	// the corresponding key (and determines intra-group ordering).
 	// 	  - RangeID replicated keys all share `LocalRangeIDPrefix` and
@@ -168,11 +169,12 @@ var _ = [...]interface{}{
 	// 		`localRangeIDUnreplicatedInfix`.
 	// 	  - Range local keys all share `LocalRangePrefix`.
 	//	  - Store keys all share `localStorePrefix`.
+	//	  - Lock table keys all share `LocalRangeLockTablePrefix`.
 	//
-	// `LocalRangeIDPrefix`, `localRangePrefix` and `localStorePrefix` all in
-	// turn share `localPrefix`. `localPrefix` was chosen arbitrarily. Local
-	// keys would work just as well with a different prefix, like 0xff, or even
-	// with a suffix.
+	// `LocalRangeIDPrefix`, `localRangePrefix`, `localStorePrefix` and
+	// `LocalRangeLockTablePrefix` all in turn share `localPrefix`.
+	// `localPrefix` was chosen arbitrarily. Local keys would work just as well
+	// with a different prefix, like 0xff, or even with a suffix.
 
 	//   1. Replicated range-ID local keys: These store metadata pertaining to a
 	//   range as a whole. Though they are replicated, they are unaddressable.
@@ -216,6 +218,12 @@ var _ = [...]interface{}{
 	StoreIdentKey,               // "iden"
 	StoreLastUpKey,              // "uptm"
 
+	//   5. Lock table keys: These store the replicated locks held on keys by
+	//   transactions. They are replicated and addressable, and are keyed by
+	//   the locked key rather than by a suffix. They all share
+	//   `LocalRangeLockTablePrefix`.
+	LockTableSingleKey,
+
 	// The global keyspace includes the meta{1,2}, system, system tenant SQL
 	// keys, and non-system tenant SQL keys.
 	//
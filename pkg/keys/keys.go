@@ -397,6 +397,34 @@ func QueueLastProcessedKey(key roachpb.RKey, queue string) roachpb.Key {
 	return MakeRangeKey(key, LocalQueueLastProcessedSuffix, roachpb.RKey(queue))
 }
 
+// LockTableSingleKey returns a key under which the replicated lock on the
+// specified key is stored in the lock table.
+func LockTableSingleKey(key roachpb.Key) roachpb.Key {
+	// The +3 accounts for the bytesMarker and terminator.
+	buf := make(roachpb.Key, 0, len(LocalRangeLockTablePrefix)+len(key)+3)
+	buf = append(buf, LocalRangeLockTablePrefix...)
+	buf = encoding.EncodeBytesAscending(buf, key)
+	return buf
+}
+
+// DecodeLockTableSingleKey decodes the lock table key to return the key that
+// is locked.
+func DecodeLockTableSingleKey(key roachpb.Key) (lockedKey roachpb.Key, err error) {
+	if !bytes.HasPrefix(key, LocalRangeLockTablePrefix) {
+		return nil, errors.Errorf("key %q does not have %q prefix",
+			key, LocalRangeLockTablePrefix)
+	}
+	b := key[len(LocalRangeLockTablePrefix):]
+	b, lockedKey, err = encoding.DecodeBytesAscending(b, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 0 {
+		return nil, errors.Errorf("key %q has %d left-over bytes after decoding", key, len(b))
+	}
+	return lockedKey, nil
+}
+
 // IsLocal performs a cheap check that returns true iff a range-local key is
 // passed, that is, a key for which `Addr` would return a non-identical RKey
 // (or a decoding error).
@@ -441,6 +469,17 @@ func Addr(k roachpb.Key) (roachpb.RKey, error) {
 		if bytes.HasPrefix(k, LocalRangeIDPrefix) {
 			return nil, errors.Errorf("local range ID key %q is not addressable", k)
 		}
+		if bytes.HasPrefix(k, LocalRangeLockTablePrefix) {
+			// Lock table keys are addressed to the key that they lock.
+			var err error
+			if k, err = DecodeLockTableSingleKey(k); err != nil {
+				return nil, err
+			}
+			if !IsLocal(k) {
+				break
+			}
+			continue
+		}
 		if !bytes.HasPrefix(k, LocalRangePrefix) {
 			return nil, errors.Errorf("local key %q malformed; should contain prefix %q",
 				k, LocalRangePrefix)
@@ -112,6 +112,27 @@ func TestAbortSpanEncodeDecode(t *testing.T) {
 	}
 }
 
+func TestLockTableKeyEncodeDecode(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	for _, testKey := range []roachpb.Key{
+		roachpb.Key("a"),
+		roachpb.Key("a\x00b"),
+		RangeDescriptorKey(roachpb.RKey("b")),
+	} {
+		key := LockTableSingleKey(testKey)
+		lockedKey, err := DecodeLockTableSingleKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !lockedKey.Equal(testKey) {
+			t.Fatalf("expected locked key %q, got %q", testKey, lockedKey)
+		}
+	}
+	if _, err := DecodeLockTableSingleKey(RangeDescriptorKey(roachpb.RKey("c"))); err == nil {
+		t.Fatal("expected error decoding non-lock table key")
+	}
+}
+
 func TestKeyAddress(t *testing.T) {
 	testCases := []struct {
 		key        roachpb.Key
@@ -123,6 +144,8 @@ func TestKeyAddress(t *testing.T) {
 		{TransactionKey(roachpb.Key("baz"), uuid.MakeV4()), roachpb.RKey("baz")},
 		{TransactionKey(roachpb.KeyMax, uuid.MakeV4()), roachpb.RKeyMax},
 		{RangeDescriptorKey(roachpb.RKey(TransactionKey(roachpb.Key("doubleBaz"), uuid.MakeV4()))), roachpb.RKey("doubleBaz")},
+		{LockTableSingleKey(roachpb.Key("qux")), roachpb.RKey("qux")},
+		{LockTableSingleKey(RangeDescriptorKey(roachpb.RKey("doubleQux"))), roachpb.RKey("doubleQux")},
 		{nil, nil},
 	}
 	for i, test := range testCases {
@@ -153,7 +176,7 @@ func TestKeyAddressError(t *testing.T) {
 			RangeLastReplicaGCTimestampKey(0),
 		},
 		"local key .* malformed": {
-			makeKey(localPrefix, roachpb.Key("z")),
+			makeKey(localPrefix, roachpb.Key("y")),
 		},
 	}
 	for regexp, keyList := range testCases {
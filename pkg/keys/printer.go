@@ -76,6 +76,8 @@ var (
 				ppFunc: localRangeIDKeyPrint, PSFunc: localRangeIDKeyParse},
 			{Name: "/Range", prefix: LocalRangePrefix, ppFunc: localRangeKeyPrint,
 				PSFunc: parseUnsupported},
+			{Name: "/LockTable", prefix: LocalRangeLockTablePrefix, ppFunc: localLockTableKeyPrint,
+				PSFunc: parseUnsupported},
 		}},
 		{Name: "/Meta1", start: Meta1Prefix, end: Meta1KeyMax, Entries: []DictEntry{
 			{Name: "", prefix: Meta1Prefix, ppFunc: print,
@@ -503,6 +505,14 @@ func localRangeKeyPrint(valDirs []encoding.Direction, key roachpb.Key) string {
 	return buf.String()
 }
 
+func localLockTableKeyPrint(valDirs []encoding.Direction, key roachpb.Key) string {
+	_, lockedKey, err := encoding.DecodeBytesAscending(key, nil)
+	if err != nil {
+		return decodeKeyPrint(valDirs, key)
+	}
+	return roachpb.Key(lockedKey).String()
+}
+
 // ErrUglifyUnsupported is returned when UglyPrint doesn't know how to process a
 // key.
 type ErrUglifyUnsupported struct {
@@ -43,10 +43,10 @@ type ReplicaSlice []ReplicaInfo
 // descriptor and using gossip to lookup node descriptors. Replicas on nodes
 // that are not gossiped are omitted from the result.
 //
-// Generally, only voting replicas are returned. However, if a non-nil
-// leaseholder is passed in, it will be included in the result even if the
-// descriptor has it as a learner (we assert that the leaseholder is part of the
-// descriptor). The idea is that the descriptor might be stale and list the
+// Generally, only voting and non-voting replicas are returned. However, if a
+// non-nil leaseholder is passed in, it will be included in the result even if
+// the descriptor has it as a learner (we assert that the leaseholder is part of
+// the descriptor). The idea is that the descriptor might be stale and list the
 // leaseholder as a learner erroneously, and lease info is a strong signal in
 // that direction. Note that the returned ReplicaSlice might still not include
 // the leaseholder if info for the respective node is missing from the
@@ -67,9 +67,13 @@ func NewReplicaSlice(
 	}
 
 	// Learner replicas won't serve reads/writes, so we'll send only to the
-	// `Voters` replicas. This is just an optimization to save a network hop,
-	// everything would still work if we had `All` here.
+	// `Voters` and `NonVoters` replicas. This is just an optimization to save a
+	// network hop, everything would still work if we had `All` here. Non-voters
+	// are included because they can serve follower reads.
 	voters := desc.Replicas().Voters()
+	if nonVoters := desc.Replicas().NonVoters(); len(nonVoters) > 0 {
+		voters = append(voters[:len(voters):len(voters)], nonVoters...)
+	}
 	// If we know a leaseholder, though, let's make sure we include it.
 	if leaseholder != nil && len(voters) < len(desc.Replicas().All()) {
 		found := false
@@ -15,7 +15,9 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -245,6 +247,10 @@ func (tp *txnPipeliner) SendLocked(
 		return nil, pErr
 	}
 
+	// Upgrade any exclusive locking reads to acquire replicated locks, if
+	// configured to do so.
+	ba = tp.maybeReplicateLockingReads(ctx, ba)
+
 	// Adjust the batch so that it doesn't miss any in-flight writes.
 	ba = tp.chainToInFlightWrites(ba)
 
@@ -329,6 +335,54 @@ func (tp *txnPipeliner) attachLocksToEndTxn(
 	return ba, nil
 }
 
+// maybeReplicateLockingReads upgrades all Exclusive locking Scan and
+// ReverseScan requests in the batch to acquire replicated locks if the
+// kv.transaction.replicated_locking_reads_enabled setting is enabled and all
+// nodes in the cluster understand replicated locks.
+// Replicated locks are persisted in the range's lock table keyspace and are
+// resolved alongside the transaction's intents, so they are not lost on lease
+// transfers or node restarts like unreplicated locks are.
+//
+// The request slice and any updated requests are copied before being mutated,
+// so the caller's batch is left untouched.
+func (tp *txnPipeliner) maybeReplicateLockingReads(
+	ctx context.Context, ba roachpb.BatchRequest,
+) roachpb.BatchRequest {
+	if !kvserverbase.ReplicatedLockingReadsEnabled.Get(&tp.st.SV) ||
+		!tp.st.Version.IsActive(ctx, clusterversion.VersionReplicatedLocks) {
+		return ba
+	}
+	forked := false
+	for i, ru := range ba.Requests {
+		var replicated roachpb.Request
+		switch t := ru.GetInner().(type) {
+		case *roachpb.ScanRequest:
+			if t.KeyLocking == lock.Exclusive && !t.KeyLockingReplicated {
+				scanCopy := *t
+				scanCopy.KeyLockingReplicated = true
+				replicated = &scanCopy
+			}
+		case *roachpb.ReverseScanRequest:
+			if t.KeyLocking == lock.Exclusive && !t.KeyLockingReplicated {
+				scanCopy := *t
+				scanCopy.KeyLockingReplicated = true
+				replicated = &scanCopy
+			}
+		}
+		if replicated == nil {
+			continue
+		}
+		if !forked {
+			oldReqs := ba.Requests
+			ba.Requests = make([]roachpb.RequestUnion, len(oldReqs))
+			copy(ba.Requests, oldReqs)
+			forked = true
+		}
+		ba.Requests[i].MustSetInner(replicated)
+	}
+	return ba
+}
+
 // chainToInFlightWrites ensures that we "chain" on to any in-flight writes that
 // overlap the keys we're trying to read/write. We do this by prepending
 // QueryIntent requests with the ErrorIfMissing option before each request that
@@ -17,7 +17,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
@@ -409,6 +411,96 @@ func TestTxnPipelinerReads(t *testing.T) {
 	require.Equal(t, 0, tp.ifWrites.len())
 }
 
+// TestTxnPipelinerReplicatedLockingReads tests that txnPipeliner upgrades
+// exclusive locking reads to acquire replicated locks when the corresponding
+// cluster setting is enabled, without mutating the caller's requests.
+func TestTxnPipelinerReplicatedLockingReads(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	tp, mockSender := makeMockTxnPipeliner()
+
+	txn := makeTxnProto()
+	keyA, keyB, keyC, keyD := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c"), roachpb.Key("d")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	scanArgs := &roachpb.ScanRequest{
+		RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyB},
+		KeyLocking:    lock.Exclusive,
+	}
+	revScanArgs := &roachpb.ReverseScanRequest{
+		RequestHeader: roachpb.RequestHeader{Key: keyC, EndKey: keyD},
+		KeyLocking:    lock.Exclusive,
+	}
+	nonLockingScanArgs := &roachpb.ScanRequest{
+		RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyD},
+	}
+	ba.Add(scanArgs, revScanArgs, nonLockingScanArgs)
+
+	// Replicated locking reads disabled. Locking reads should be untouched.
+	kvserverbase.ReplicatedLockingReadsEnabled.Override(&tp.st.SV, false)
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 3)
+		require.False(t, ba.Requests[0].GetScan().KeyLockingReplicated)
+		require.False(t, ba.Requests[1].GetReverseScan().KeyLockingReplicated)
+		require.False(t, ba.Requests[2].GetScan().KeyLockingReplicated)
+		require.True(t, ba.IsReadOnly())
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		return br, nil
+	})
+
+	br, pErr := tp.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+
+	// Replicated locking reads enabled, but replicated locks are not supported
+	// by all nodes in the cluster yet. Locking reads should be untouched.
+	st := tp.st
+	tp.st = cluster.MakeTestingClusterSettingsWithVersions(
+		clusterversion.VersionByKey(clusterversion.VersionReplicatedLocks-1),
+		clusterversion.TestingBinaryMinSupportedVersion,
+		true /* initializeVersion */)
+	kvserverbase.ReplicatedLockingReadsEnabled.Override(&tp.st.SV, true)
+
+	br, pErr = tp.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	tp.st = st
+
+	// Replicated locking reads enabled. Locking reads should be upgraded.
+	kvserverbase.ReplicatedLockingReadsEnabled.Override(&tp.st.SV, true)
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 3)
+		require.True(t, ba.Requests[0].GetScan().KeyLockingReplicated)
+		require.True(t, ba.Requests[1].GetReverseScan().KeyLockingReplicated)
+		require.False(t, ba.Requests[2].GetScan().KeyLockingReplicated)
+		require.False(t, ba.IsReadOnly())
+		require.False(t, ba.AsyncConsensus)
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		return br, nil
+	})
+
+	br, pErr = tp.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+
+	// The caller's requests should not have been mutated.
+	require.False(t, scanArgs.KeyLockingReplicated)
+	require.False(t, revScanArgs.KeyLockingReplicated)
+
+	// The lock spans of both locking reads should be tracked, once per batch.
+	require.Equal(t, 0, tp.ifWrites.len())
+	tp.lockFootprint.mergeAndSort()
+	require.Equal(t, []roachpb.Span{
+		{Key: keyA, EndKey: keyB}, {Key: keyC, EndKey: keyD},
+	}, tp.lockFootprint.asSlice())
+}
+
 // TestTxnPipelinerRangedWrites tests that txnPipeliner will never perform
 // ranged write operations using async consensus. It also tests that ranged
 // writes will correctly chain on to existing in-flight writes.
@@ -57,6 +57,10 @@ const (
 	removeDeadReplicaPriority               float64 = 1000
 	removeDecommissioningReplicaPriority    float64 = 200
 	removeExtraReplicaPriority              float64 = 100
+	addMissingNonVoterPriority              float64 = 60
+	removeDeadNonVoterPriority              float64 = 50
+	removeDecommissioningNonVoterPriority   float64 = 40
+	removeExtraNonVoterPriority             float64 = 20
 )
 
 // MinLeaseTransferStatsDuration configures the minimum amount of time a
@@ -108,6 +112,10 @@ const (
 	AllocatorConsiderRebalance
 	AllocatorRangeUnavailable
 	AllocatorFinalizeAtomicReplicationChange
+	AllocatorAddNonVoter
+	AllocatorRemoveNonVoter
+	AllocatorRemoveDeadNonVoter
+	AllocatorRemoveDecommissioningNonVoter
 )
 
 var allocatorActionNames = map[AllocatorAction]string{
@@ -122,6 +130,10 @@ var allocatorActionNames = map[AllocatorAction]string{
 	AllocatorConsiderRebalance:               "consider rebalance",
 	AllocatorRangeUnavailable:                "range unavailable",
 	AllocatorFinalizeAtomicReplicationChange: "finalize conf change",
+	AllocatorAddNonVoter:                     "add non-voter",
+	AllocatorRemoveNonVoter:                  "remove non-voter",
+	AllocatorRemoveDeadNonVoter:              "remove dead non-voter",
+	AllocatorRemoveDecommissioningNonVoter:   "remove decommissioning non-voter",
 }
 
 func (a AllocatorAction) String() string {
@@ -296,6 +308,22 @@ func GetNeededReplicas(zoneConfigReplicaCount int32, clusterNodes int) int {
 	return need
 }
 
+// GetNeededNonVoters calculates the number of non-voting replicas a range
+// should have given its number of voters, the number of non-voters requested
+// by its zone config, and the number of nodes in the cluster. Since a node can
+// hold at most one replica of a range, non-voters are limited to the nodes that
+// don't already hold a voter.
+func GetNeededNonVoters(numVoters, zoneConfigNonVoterCount, clusterNodes int) int {
+	need := zoneConfigNonVoterCount
+	if clusterNodes-numVoters < need {
+		need = clusterNodes - numVoters
+	}
+	if need < 0 {
+		need = 0
+	}
+	return need
+}
+
 // ComputeAction determines the exact operation needed to repair the
 // supplied range, as governed by the supplied zone configuration. It
 // returns the required action that should be taken and a priority.
@@ -355,7 +383,14 @@ func (a *Allocator) ComputeAction(
 		return AllocatorRemoveLearner, removeLearnerReplicaPriority
 	}
 	// computeAction expects to operate only on voters.
-	return a.computeAction(ctx, zone, desc.Replicas().Voters())
+	voterReplicas := desc.Replicas().Voters()
+	action, priority := a.computeAction(ctx, zone, voterReplicas)
+	if action != AllocatorConsiderRebalance {
+		// Repairs to the set of voters take precedence over any changes to the
+		// non-voters.
+		return action, priority
+	}
+	return a.computeNonVoterAction(ctx, zone, voterReplicas, desc.Replicas().NonVoters())
 }
 
 func (a *Allocator) computeAction(
@@ -365,7 +400,7 @@ func (a *Allocator) computeAction(
 	have := len(voterReplicas)
 	decommissioningReplicas := a.storePool.decommissioningReplicas(voterReplicas)
 	clusterNodes := a.storePool.ClusterNodeCount()
-	need := GetNeededReplicas(*zone.NumReplicas, clusterNodes)
+	need := GetNeededReplicas(zone.GetNumVoters(), clusterNodes)
 	desiredQuorum := computeQuorum(need)
 	quorum := computeQuorum(have)
 
@@ -453,6 +488,54 @@ func (a *Allocator) computeAction(
 	return AllocatorConsiderRebalance, 0
 }
 
+// computeNonVoterAction determines the action, if any, that should be taken on
+// the non-voting replicas of a range whose voters are in good standing. Since
+// non-voters don't participate in quorum, dead and decommissioning non-voters
+// are removed outright and replaced later, rather than replaced before being
+// removed.
+func (a *Allocator) computeNonVoterAction(
+	ctx context.Context,
+	zone *zonepb.ZoneConfig,
+	voterReplicas, nonVoterReplicas []roachpb.ReplicaDescriptor,
+) (AllocatorAction, float64) {
+	have := len(nonVoterReplicas)
+	need := GetNeededNonVoters(len(voterReplicas), int(zone.GetNumNonVoters()), a.storePool.ClusterNodeCount())
+
+	if have < need {
+		priority := addMissingNonVoterPriority
+		action := AllocatorAddNonVoter
+		log.VEventf(ctx, 3, "%s - missing non-voter need=%d, have=%d, priority=%.2f",
+			action, need, have, priority)
+		return action, priority
+	}
+
+	_, deadNonVoters := a.storePool.liveAndDeadReplicas(nonVoterReplicas)
+	if len(deadNonVoters) > 0 {
+		priority := removeDeadNonVoterPriority
+		action := AllocatorRemoveDeadNonVoter
+		log.VEventf(ctx, 3, "%s - dead=%d, priority=%.2f", action, len(deadNonVoters), priority)
+		return action, priority
+	}
+
+	if decommissioning := a.storePool.decommissioningReplicas(nonVoterReplicas); len(decommissioning) > 0 {
+		priority := removeDecommissioningNonVoterPriority
+		action := AllocatorRemoveDecommissioningNonVoter
+		log.VEventf(ctx, 3, "%s - num_decommissioning=%d, priority=%.2f",
+			action, len(decommissioning), priority)
+		return action, priority
+	}
+
+	if have > need {
+		priority := removeExtraNonVoterPriority
+		action := AllocatorRemoveNonVoter
+		log.VEventf(ctx, 3, "%s - need=%d, have=%d, priority=%.2f", action, need, have, priority)
+		return action, priority
+	}
+
+	// Nothing needs to be done, but we may want to rebalance.
+	return AllocatorConsiderRebalance, 0
+}
+
 type decisionDetails struct {
 	Target   string
 	Existing string `json:",omitempty"`
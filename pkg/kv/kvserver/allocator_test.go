@@ -4676,6 +4676,93 @@ func TestAllocatorComputeActionRemoveDead(t *testing.T) {
 	}
 }
 
+func TestAllocatorComputeActionNonVoter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	zone := zonepb.ZoneConfig{
+		NumReplicas: proto.Int32(5),
+		NumVoters:   proto.Int32(3),
+	}
+	makeDesc := func(voters, nonVoters []roachpb.StoreID) roachpb.RangeDescriptor {
+		desc := makeDescriptor(voters)
+		for _, storeID := range nonVoters {
+			desc.InternalReplicas = append(desc.InternalReplicas, roachpb.ReplicaDescriptor{
+				StoreID:   storeID,
+				NodeID:    roachpb.NodeID(storeID),
+				ReplicaID: roachpb.ReplicaID(storeID),
+				Type:      roachpb.ReplicaTypeNonVoter(),
+			})
+		}
+		return desc
+	}
+
+	testCases := []struct {
+		desc            roachpb.RangeDescriptor
+		live            []roachpb.StoreID
+		dead            []roachpb.StoreID
+		decommissioning []roachpb.StoreID
+		expectedAction  AllocatorAction
+	}{
+		// Missing a voter; voter repairs take precedence.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2}, []roachpb.StoreID{4, 5}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorAdd,
+		},
+		// Missing both non-voters.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, nil),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorAddNonVoter,
+		},
+		// Missing one non-voter.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorAddNonVoter,
+		},
+		// Fully replicated.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorConsiderRebalance,
+		},
+		// One non-voter too many.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5, 6}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5, 6},
+			expectedAction: AllocatorRemoveNonVoter,
+		},
+		// A dead non-voter.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 6},
+			dead:           []roachpb.StoreID{5},
+			expectedAction: AllocatorRemoveDeadNonVoter,
+		},
+		// A decommissioning non-voter.
+		{
+			desc:            makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:            []roachpb.StoreID{1, 2, 3, 4, 6},
+			decommissioning: []roachpb.StoreID{5},
+			expectedAction:  AllocatorRemoveDecommissioningNonVoter,
+		},
+	}
+
+	stopper, _, sp, a, _ := createTestAllocator(10, false /* deterministic */)
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+
+	for i, tcase := range testCases {
+		mockStorePool(sp, tcase.live, nil, tcase.dead, tcase.decommissioning, nil)
+		action, _ := a.ComputeAction(ctx, &zone, &tcase.desc)
+		if tcase.expectedAction != action {
+			t.Errorf("Test case %d expected action %s, got action %s", i, tcase.expectedAction, action)
+		}
+	}
+}
+
 func TestAllocatorComputeActionDecommission(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -5198,6 +5285,31 @@ func TestAllocatorGetNeededReplicas(t *testing.T) {
 	}
 }
 
+func TestAllocatorGetNeededNonVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testCases := []struct {
+		numVoters, zoneNonVoters, availNodes int
+		expected                             int
+	}{
+		{3, 0, 5, 0},
+		{3, 2, 5, 2},
+		{3, 2, 4, 1},
+		{3, 2, 3, 0},
+		{3, 2, 2, 0},
+		{1, 4, 10, 4},
+	}
+
+	for _, tc := range testCases {
+		if e, a := tc.expected, GetNeededNonVoters(tc.numVoters, tc.zoneNonVoters, tc.availNodes); e != a {
+			t.Errorf(
+				"GetNeededNonVoters(numVoters=%d, zoneNonVoters=%d, availNodes=%d) got %d; want %d",
+				tc.numVoters, tc.zoneNonVoters, tc.availNodes, a, e)
+		}
+	}
+}
+
 func makeDescriptor(storeList []roachpb.StoreID) roachpb.RangeDescriptor {
 	desc := roachpb.RangeDescriptor{
 		EndKey: roachpb.RKey(keys.SystemPrefix),
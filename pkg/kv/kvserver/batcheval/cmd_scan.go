@@ -18,10 +18,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/errors"
 )
 
 func init() {
-	RegisterReadOnlyCommand(roachpb.Scan, DefaultDeclareIsolatedKeys, Scan)
+	RegisterReadWriteCommand(roachpb.Scan, DefaultDeclareIsolatedKeys, Scan)
 }
 
 // Scan scans the key range specified by start key through end key
@@ -29,7 +30,7 @@ func init() {
 // stores the number of scan results remaining for this batch
 // (MaxInt64 for no limit).
 func Scan(
-	ctx context.Context, reader storage.Reader, cArgs CommandArgs, resp roachpb.Response,
+	ctx context.Context, readWriter storage.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
 ) (result.Result, error) {
 	args := cArgs.Args.(*roachpb.ScanRequest)
 	h := cArgs.Header
@@ -48,17 +49,25 @@ func Scan(
 		Reverse:          false,
 	}
 
+	// Locking scans must not skip over replicated locks held by other
+	// transactions, which are not visible to the MVCC scan itself.
+	if args.KeyLocking != lock.None && h.Txn != nil {
+		if err := checkForConflictingLocks(ctx, readWriter, cArgs.EvalCtx, args.Span(), h.Txn); err != nil {
+			return result.Result{}, err
+		}
+	}
+
 	switch args.ScanFormat {
 	case roachpb.BATCH_RESPONSE:
 		scanRes, err = storage.MVCCScanToBytes(
-			ctx, reader, args.Key, args.EndKey, h.Timestamp, opts)
+			ctx, readWriter, args.Key, args.EndKey, h.Timestamp, opts)
 		if err != nil {
 			return result.Result{}, err
 		}
 		reply.BatchResponses = scanRes.KVData
 	case roachpb.KEY_VALUES:
 		scanRes, err = storage.MVCCScan(
-			ctx, reader, args.Key, args.EndKey, h.Timestamp, opts)
+			ctx, readWriter, args.Key, args.EndKey, h.Timestamp, opts)
 		if err != nil {
 			return result.Result{}, err
 		}
@@ -80,14 +89,20 @@ func Scan(
 		// one in CollectIntentRows either so that we're guaranteed to use the
 		// same cached iterator and observe a consistent snapshot of the engine.
 		const usePrefixIter = false
-		reply.IntentRows, err = CollectIntentRows(ctx, reader, usePrefixIter, scanRes.Intents)
+		reply.IntentRows, err = CollectIntentRows(ctx, readWriter, usePrefixIter, scanRes.Intents)
 		if err != nil {
 			return result.Result{}, err
 		}
 	}
 
 	if args.KeyLocking != lock.None && h.Txn != nil {
-		err = acquireUnreplicatedLocksOnKeys(&res, h.Txn, args.ScanFormat, &scanRes)
+		dur := args.KeyLockingDurability()
+		if dur == lock.Replicated && args.KeyLocking != lock.Exclusive {
+			return result.Result{}, errors.AssertionFailedf(
+				"replicated locks must use Exclusive strength, found %s", args.KeyLocking)
+		}
+		err = acquireLocksOnKeys(ctx, readWriter, cArgs.Stats, &res, h.Txn,
+			args.KeyLocking, dur, args.ScanFormat, &scanRes)
 		if err != nil {
 			return result.Result{}, err
 		}
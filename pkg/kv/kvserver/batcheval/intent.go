@@ -13,10 +13,14 @@ package batcheval
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 )
@@ -103,29 +107,105 @@ func readProvisionalVal(
 
 }
 
-// acquireUnreplicatedLocksOnKeys adds an unreplicated lock acquisition by the
-// transaction to the provided result.Result for each key in the scan result.
-func acquireUnreplicatedLocksOnKeys(
+// acquireLocksOnKeys acquires locks with the provided strength and durability
+// by the transaction on each key in the scan result. A lock acquisition is
+// added to the provided result.Result for each key. Replicated locks are
+// additionally persisted to the range's lock table keyspace using the provided
+// ReadWriter.
+func acquireLocksOnKeys(
+	ctx context.Context,
+	readWriter storage.ReadWriter,
+	ms *enginepb.MVCCStats,
 	res *result.Result,
 	txn *roachpb.Transaction,
+	str lock.Strength,
+	dur lock.Durability,
 	scanFmt roachpb.ScanFormat,
 	scanRes *storage.MVCCScanResult,
 ) error {
 	res.Local.AcquiredLocks = make([]roachpb.LockAcquisition, scanRes.NumKeys)
+	acquireLock := func(i int, key roachpb.Key) error {
+		if dur == lock.Replicated {
+			if err := storage.MVCCAcquireLock(ctx, readWriter, txn, ms, key); err != nil {
+				return err
+			}
+		}
+		res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, key, str, dur)
+		return nil
+	}
 	switch scanFmt {
 	case roachpb.BATCH_RESPONSE:
 		var i int
 		return storage.MVCCScanDecodeKeyValues(scanRes.KVData, func(key storage.MVCCKey, _ []byte) error {
-			res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, key.Key, lock.Unreplicated)
+			// The key is only valid for the duration of the callback, so it
+			// must be copied before being retained in the lock acquisition.
+			if err := acquireLock(i, append(roachpb.Key(nil), key.Key...)); err != nil {
+				return err
+			}
 			i++
 			return nil
 		})
 	case roachpb.KEY_VALUES:
 		for i, row := range scanRes.KVs {
-			res.Local.AcquiredLocks[i] = roachpb.MakeLockAcquisition(txn, row.Key, lock.Unreplicated)
+			if err := acquireLock(i, row.Key); err != nil {
+				return err
+			}
 		}
 		return nil
 	default:
 		panic("unexpected scanFormat")
 	}
 }
+
+// checkForConflictingLocks verifies that a locking scan over the provided span
+// does not conflict with replicated locks held by other transactions. Such
+// locks are not visible to the MVCC scan, so they are checked for separately.
+func checkForConflictingLocks(
+	ctx context.Context,
+	reader storage.Reader,
+	rec EvalContext,
+	span roachpb.Span,
+	txn *roachpb.Transaction,
+) error {
+	if !replicatedLocksMayExist(ctx, rec) {
+		return nil
+	}
+	return storage.MVCCCheckForConflictingLocks(ctx, reader, span.Key, span.EndKey, txn)
+}
+
+// CheckIntentWriteForConflictingLocks verifies that an intent write does not
+// conflict with replicated locks held by other transactions on its keys. Such
+// locks are not stored in the MVCC history of the keys, so writers don't
+// observe them and must check for them separately before being evaluated.
+func CheckIntentWriteForConflictingLocks(
+	ctx context.Context, reader storage.Reader, cArgs CommandArgs,
+) error {
+	args := cArgs.Args
+	if !roachpb.IsIntentWrite(args) || !replicatedLocksMayExist(ctx, cArgs.EvalCtx) {
+		return nil
+	}
+	if put, ok := args.(*roachpb.PutRequest); ok && (put.Blind || put.Inline) {
+		// The callers of blind puts guarantee that no other transaction can
+		// have accessed the key, and inline values are not transactional.
+		return nil
+	}
+	span := args.Header().Span()
+	if keys.IsLocal(span.Key) {
+		// Replicated locks are only acquired on global keys.
+		return nil
+	}
+	if len(span.EndKey) == 0 {
+		return storage.MVCCCheckForConflictingLock(ctx, reader, span.Key, cArgs.Header.Txn)
+	}
+	return storage.MVCCCheckForConflictingLocks(ctx, reader, span.Key, span.EndKey, cArgs.Header.Txn)
+}
+
+// replicatedLocksMayExist returns whether the range may hold replicated locks.
+// They are only acquired while kv.transaction.replicated_locking_reads_enabled
+// is set and all the nodes in the cluster understand them, so there is no need
+// to look for them otherwise.
+func replicatedLocksMayExist(ctx context.Context, rec EvalContext) bool {
+	st := rec.ClusterSettings()
+	return kvserverbase.ReplicatedLockingReadsEnabled.Get(&st.SV) &&
+		st.Version.IsActive(ctx, clusterversion.VersionReplicatedLocks)
+}
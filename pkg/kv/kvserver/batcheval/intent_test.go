@@ -14,12 +14,16 @@ import (
 	"context"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -163,3 +167,79 @@ func TestCollectIntentsUsesSameIterator(t *testing.T) {
 		})
 	}
 }
+
+// TestCheckIntentWriteForConflictingLocks tests that intent writes conflict
+// with the replicated locks held by other transactions on their keys, and that
+// they don't look for such locks when replicated locks can't exist.
+func TestCheckIntentWriteForConflictingLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	keyA, keyB, keyC := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+	ts := hlc.Timestamp{WallTime: 123}
+	txn1 := roachpb.MakeTransaction("txn1", keyA, roachpb.NormalUserPriority, ts, 0)
+	txn2 := roachpb.MakeTransaction("txn2", keyA, roachpb.NormalUserPriority, ts, 0)
+	val := roachpb.MakeValueFromString("val")
+
+	db := &instrumentedEngine{Engine: storage.NewDefaultInMem()}
+	defer db.Close()
+	require.NoError(t, storage.MVCCAcquireLock(ctx, db, &txn1, nil, keyB))
+
+	var iters int
+	db.onNewIterator = func(storage.IterOptions) { iters++ }
+	check := func(st *cluster.Settings, txn *roachpb.Transaction, req roachpb.Request) error {
+		evalCtx := (&MockEvalCtx{ClusterSettings: st}).EvalContext()
+		cArgs := CommandArgs{EvalCtx: evalCtx, Header: roachpb.Header{Txn: txn}, Args: req}
+		return CheckIntentWriteForConflictingLocks(ctx, db, cArgs)
+	}
+	put := func(key roachpb.Key) roachpb.Request {
+		return &roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: key}, Value: val}
+	}
+	delRange := &roachpb.DeleteRangeRequest{
+		RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyC},
+	}
+	blindPut := &roachpb.PutRequest{
+		RequestHeader: roachpb.RequestHeader{Key: keyB}, Value: val, Blind: true,
+	}
+	get := &roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: keyB}}
+
+	// Before replicated locks can exist, the lock table is not read.
+	oldSt := cluster.MakeTestingClusterSettingsWithVersions(
+		clusterversion.VersionByKey(clusterversion.VersionReplicatedLocks-1),
+		clusterversion.TestingBinaryMinSupportedVersion,
+		true /* initializeVersion */)
+	kvserverbase.ReplicatedLockingReadsEnabled.Override(&oldSt.SV, true)
+	require.NoError(t, check(oldSt, &txn2, put(keyB)))
+	require.NoError(t, check(oldSt, &txn2, delRange))
+	require.Equal(t, 0, iters)
+
+	// Nor is it read while replicated locking reads are disabled.
+	st := cluster.MakeTestingClusterSettings()
+	require.NoError(t, check(st, &txn2, put(keyB)))
+	require.NoError(t, check(st, &txn2, delRange))
+	require.Equal(t, 0, iters)
+
+	kvserverbase.ReplicatedLockingReadsEnabled.Override(&st.SV, true)
+	var wiErr *roachpb.WriteIntentError
+	for _, req := range []roachpb.Request{put(keyB), delRange} {
+		err := check(st, &txn2, req)
+		require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+		require.Len(t, wiErr.Intents, 1)
+		require.Equal(t, keyB, wiErr.Intents[0].Key)
+		require.Equal(t, txn1.ID, wiErr.Intents[0].Txn.ID)
+
+		// Non-transactional writes conflict too, but not the lock holder.
+		err = check(st, nil /* txn */, req)
+		require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+		require.NoError(t, check(st, &txn1, req))
+	}
+
+	// Other keys, blind puts and non-writes don't conflict.
+	iters = 0
+	require.NoError(t, check(st, &txn2, put(keyA)))
+	require.Equal(t, 1, iters)
+	require.NoError(t, check(st, &txn2, blindPut))
+	require.NoError(t, check(st, &txn2, get))
+	require.Equal(t, 1, iters)
+}
@@ -503,11 +503,12 @@ type lockTable interface {
 	// the lockTable initially. It must only be called in the evaluation phase
 	// before calling Dequeue, which means all the latches needed by the request
 	// are held. The key must be in the request's SpanSet with the appropriate
-	// SpanAccess: currently the strength is always Exclusive, so the span
+	// SpanAccess: the strength is either Shared or Exclusive, so the span
 	// containing this key must be SpanReadWrite. This contract ensures that the
 	// lock is not held in a conflicting manner by a different transaction.
 	// Acquiring a lock that is already held by this transaction upgrades the
-	// lock's timestamp and strength, if necessary.
+	// lock's timestamp and strength, if necessary. Shared locks must be
+	// acquired with Unreplicated durability.
 	//
 	// For replicated locks, this must be called after the corresponding write
 	// intent has been applied to the replicated state machine.
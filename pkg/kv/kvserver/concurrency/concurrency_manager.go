@@ -56,6 +56,8 @@ type Config struct {
 	// Metrics.
 	TxnWaitMetrics *txnwait.Metrics
 	SlowLatchGauge *metric.Gauge
+	// Callbacks.
+	OnContentionEvent func(*roachpb.ContentionEvent)
 	// Configs + Knobs.
 	MaxLockTableSize  int64
 	DisableTxnPushing bool
@@ -91,6 +93,7 @@ func NewManager(cfg Config) Manager {
 			ir:                cfg.IntentResolver,
 			lm:                m,
 			disableTxnPushing: cfg.DisableTxnPushing,
+			onContentionEvent: cfg.OnContentionEvent,
 		},
 		// TODO(nvanbenschoten): move pkg/storage/txnwait to a new
 		// pkg/storage/concurrency/txnwait package.
@@ -306,7 +309,7 @@ func (m *managerImpl) HandleTransactionPushError(
 
 // OnLockAcquired implements the LockManager interface.
 func (m *managerImpl) OnLockAcquired(ctx context.Context, acq *roachpb.LockAcquisition) {
-	if err := m.lt.AcquireLock(&acq.Txn, acq.Key, lock.Exclusive, acq.Durability); err != nil {
+	if err := m.lt.AcquireLock(&acq.Txn, acq.Key, acq.Strength, acq.Durability); err != nil {
 		log.Fatalf(ctx, "%v", err)
 	}
 }
@@ -435,6 +438,32 @@ func (r *Request) writeConflictTimestamp() hlc.Timestamp {
 	return ts
 }
 
+// writeLockStrength returns the strength of the locks that the request
+// acquires on the keys that it declares with SpanReadWrite lock spans. Requests
+// that only perform Shared locking reads acquire Shared locks, which are
+// compatible with each other. All other requests acquire Exclusive locks.
+func (r *Request) writeLockStrength() lock.Strength {
+	str := lock.Exclusive
+	for _, ru := range r.Requests {
+		switch req := ru.GetInner().(type) {
+		case *roachpb.ScanRequest:
+			if req.KeyLocking == lock.Shared {
+				str = lock.Shared
+				continue
+			}
+		case *roachpb.ReverseScanRequest:
+			if req.KeyLocking == lock.Shared {
+				str = lock.Shared
+				continue
+			}
+		}
+		if req := ru.GetInner(); !roachpb.IsReadOnly(req) || roachpb.IsLocking(req) {
+			return lock.Exclusive
+		}
+	}
+	return str
+}
+
 func (r *Request) isSingle(m roachpb.Method) bool {
 	if len(r.Requests) != 1 {
 		return false
@@ -61,7 +61,7 @@ import (
 // handle-write-intent-error  req=<req-name> txn=<txn-name> key=<key> lease-seq=<seq>
 // handle-txn-push-error      req=<req-name> txn=<txn-name> key=<key>  TODO(nvanbenschoten): implement this
 //
-// on-lock-acquired  req=<req-name> key=<key> [seq=<seq>] [dur=r|u]
+// on-lock-acquired  req=<req-name> key=<key> [seq=<seq>] [dur=r|u] [str=shared|exclusive]
 // on-lock-updated   req=<req-name> txn=<txn-name> key=<key> status=[committed|aborted|pending] [ts=<int>[,<int>]]
 // on-txn-updated    txn=<txn-name> status=[committed|aborted|pending] [ts=<int>[,<int>]]
 //
@@ -304,6 +304,11 @@ func TestConcurrencyManagerBasic(t *testing.T) {
 					dur = scanLockDurability(t, d)
 				}
 
+				str := lock.Exclusive
+				if d.HasArg("str") {
+					str = scanLockStrength(t, d)
+				}
+
 				// Confirm that the request has a corresponding write request.
 				found := false
 				for _, ru := range guard.Req.Requests {
@@ -325,7 +330,7 @@ func TestConcurrencyManagerBasic(t *testing.T) {
 
 				mon.runSync("acquire lock", func(ctx context.Context) {
 					log.Eventf(ctx, "txn %s @ %s", txn.ID.Short(), key)
-					acq := roachpb.MakeLockAcquisition(txnAcquire, roachpb.Key(key), dur)
+					acq := roachpb.MakeLockAcquisition(txnAcquire, roachpb.Key(key), str, dur)
 					m.OnLockAcquired(ctx, &acq)
 				})
 				return c.waitAndCollect(t, mon)
@@ -914,10 +919,15 @@ func (m *monitor) collectRecordings() string {
 						prev--
 						continue
 					}
+					g.prevEvents++
+					if field.Key == tracing.ContentionEventField {
+						// Contention events include the time spent waiting,
+						// which is not deterministic.
+						continue
+					}
 					logs = append(logs, logRecord{
 						g: g, value: field.Value,
 					})
-					g.prevEvents++
 				}
 			}
 		}
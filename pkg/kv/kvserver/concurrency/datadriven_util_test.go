@@ -73,6 +73,24 @@ func scanLockDurability(t *testing.T, d *datadriven.TestData) lock.Durability {
 	}
 }
 
+func scanLockStrength(t *testing.T, d *datadriven.TestData) lock.Strength {
+	var strS string
+	d.ScanArgs(t, "str", &strS)
+	return parseLockStrength(t, d, strS)
+}
+
+func parseLockStrength(t *testing.T, d *datadriven.TestData, s string) lock.Strength {
+	switch s {
+	case "shared":
+		return lock.Shared
+	case "exclusive":
+		return lock.Exclusive
+	default:
+		d.Fatalf(t, "unknown lock strength: %s", s)
+		return 0
+	}
+}
+
 func scanWaitPolicy(t *testing.T, d *datadriven.TestData, required bool) lock.WaitPolicy {
 	const key = "wait-policy"
 	if !required && !d.HasArg(key) {
@@ -141,6 +159,9 @@ func scanSingleRequest(
 		if v, ok := fields["endkey"]; ok {
 			r.EndKey = roachpb.Key(v)
 		}
+		if v, ok := fields["str"]; ok {
+			r.KeyLocking = parseLockStrength(t, d, v)
+		}
 		return &r
 
 	case "put":
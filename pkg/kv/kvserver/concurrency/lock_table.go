@@ -262,6 +262,9 @@ type lockTableGuardImpl struct {
 	spans   *spanset.SpanSet
 	readTS  hlc.Timestamp
 	writeTS hlc.Timestamp
+	// The strength of the locks that the request intends to acquire on the
+	// keys in its SpanReadWrite spans. Either Shared or Exclusive.
+	str lock.Strength
 
 	// Snapshots of the trees for which this request has some spans. Note that
 	// the lockStates in these snapshots may have been removed from
@@ -538,10 +541,14 @@ type lockState struct {
 
 	// Invariant summary (see detailed comments below):
 	// - both holder.locked and waitQ.reservation != nil cannot be true.
+	// - both holder.locked and len(holder.shared) > 0 cannot be true.
+	// - both len(holder.shared) > 0 and waitQ.reservation != nil cannot be
+	//   true.
 	// - if holder.locked and multiple holderInfos have txn != nil: all the
 	//   txns must have the same txn.ID.
 	// - !holder.locked => waitingReaders.Len() == 0. That is, readers wait
-	//   only if the lock is held. They do not wait for a reservation.
+	//   only if the lock is held with Exclusive strength. They do not wait for
+	//   a reservation or for Shared lock holders.
 	// - If reservation != nil, that request is not in queuedWriters.
 
 	// Information about whether the lock is held and the holder. We track
@@ -550,8 +557,14 @@ type lockState struct {
 	// replicated and unreplicated mode at different stages.
 	holder struct {
 		locked bool
-		// LockStrength is always Exclusive
+		// The Exclusive lock holder, if locked is true.
 		holder [lock.MaxDurability + 1]lockHolderInfo
+		// The Shared lock holders, in the order in which they acquired the lock.
+		// Shared locks are compatible with each other, so multiple transactions
+		// can hold the lock with Shared strength at the same time. Shared locks
+		// are only held with Unreplicated durability, so a single
+		// lockHolderInfo per transaction is sufficient.
+		shared []lockHolderInfo
 	}
 
 	// Information about the requests waiting on the lock.
@@ -588,8 +601,7 @@ type lockWaitQueue struct {
 	// seqnums but at another key req2 wants to read and req1 wants to write and
 	// since req2 does not wait in the queue it acquires a read reservation
 	// before req1. See the discussion at the end of this comment section on how
-	// the behavior will extend when we start supporting Shared and Upgrade
-	// locks.
+	// the behavior extends to Shared locks.
 	//
 	// Non-transactional requests can do both reads and writes but cannot be
 	// depended on since they don't have a transaction that can be pushed.
@@ -651,44 +663,53 @@ type lockWaitQueue struct {
 	//   This is a deadlock caused by the lock table unless req2 partially
 	//   breaks the reservation at A.
 	//
-	// Extension for Shared and Upgrade locks:
-	// There are 3 aspects to consider: holders; reservers; the dependencies
-	// that need to be captured when waiting.
+	// Shared locks:
 	//
-	// - Holders: only shared locks are compatible with themselves, so there can
-	//   be one of (a) no holder (b) multiple shared lock holders, (c) one
-	//   exclusive holder, (d) one upgrade holder. Non-locking reads will
-	//   wait in waitingReaders for only an incompatible exclusive holder.
+	// Requests that only perform Shared locking reads (SELECT ... FOR SHARE)
+	// declare their keys as SpanReadWrite, like other locking requests, but
+	// intend to acquire the lock with Shared strength (see
+	// lockTableGuardImpl.str). Shared locks are compatible with each other but
+	// not with Exclusive locks, so there can be one of (a) no holder, (b) one
+	// or more Shared lock holders, (c) one Exclusive holder. Non-locking reads
+	// only wait in waitingReaders for an Exclusive holder, so they are never
+	// blocked by Shared locks.
 	//
-	// - Reservers: This follows the same pattern as holders. Non-locking reads
-	//   do not wait on reservers.
+	// - Reservations: requests that intend to acquire a Shared lock never make
+	//   reservations. Joint reservations by a sequence of Shared lockers could
+	//   be partially broken, which introduces the same complexity as discussed
+	//   above. Instead, such requests are treated like non-transactional
+	//   writes: they ignore reservations held by requests with a higher seqNum
+	//   and break them if they end up acquiring the lock, and they leave the
+	//   queue without the reservation when they reach its front while the lock
+	//   is not held.
 	//
-	// - Queueing and dependencies: All potential lockers and non-transactional
-	//   writers will wait in the same queue. A sequence of consecutive requests
-	//   that have the potential to acquire a shared lock will jointly reserve
-	//   that shared lock. Such requests cannot jump ahead of requests with a
-	//   lower seqnum just because there is currently a shared lock reservation
-	//   (this can cause lockTable induced deadlocks). Such joint reservations
-	//   can be partially broken by a waiter desiring an exclusive or upgrade
-	//   lock. Like the current code, non-transactional writes will wait for
-	//   reservations that have a lower sequence num, but not make their own
-	//   reservation. Additionally, they can partially break joint reservations.
+	// - Queueing and fairness: all requests that want to acquire a lock wait in
+	//   queuedWriters, ordered by seqNum. A request that intends to acquire an
+	//   Exclusive lock conflicts with all Shared lock holders other than its
+	//   own transaction. A request that intends to acquire a Shared lock is
+	//   compatible with the other Shared lock holders, but it does not jump
+	//   ahead of transactional requests with a lower seqNum that are waiting to
+	//   acquire an Exclusive lock, since that could starve writers. In that
+	//   case it depends on the first such waiter, which is what it will push
+	//   for deadlock detection. This is also true for requests with an Error
+	//   wait policy, which raise an error instead of waiting. Letting them
+	//   acquire the lock because they never wait would allow a stream of such
+	//   requests to keep the lock held with Shared strength indefinitely.
 	//
-	//   Reservations that are (partially or fully) broken cause requests to
-	//   reenter the queue as inactive waiters. This is no different than the
-	//   current behavior. Each request can specify the same key in spans for
-	//   ReadOnly, ReadShared, ReadUpgrade, ReadWrite. The spans will be
-	//   iterated over in decreasing order of strength, to only wait at a lock
-	//   at the highest strength (this is similar to the current behavior using
-	//   accessDecreasingStrength).
+	//   The set of Shared lock holders can change many times while a writer
+	//   waits on one of them, and each change informs the active waiters of
+	//   their (possibly unchanged) state. The lockTableWaiter does not treat
+	//   such an update as progress by the transaction it is waiting on, so it
+	//   does not postpone its liveness and deadlock detection pushes (see
+	//   lockTableWaiterImpl.WaitOn).
 	//
-	//   For dependencies, a waiter desiring an exclusive or upgrade lock always
-	//   conflicts with the holder(s) or reserver(s) so that is the dependency
-	//   that will be captured. A waiter desiring a shared lock may encounter a
-	//   situation where it does not conflict with the holder(s) or reserver(s)
-	//   since those are also shared lockers. In that case it will depend on the
-	//   first waiter since that waiter must be desiring a lock that is
-	//   incompatible with a shared lock.
+	//   When the set of Shared lock holders or the set of waiters changes,
+	//   waiters that have become compatible with the lock, e.g. because their
+	//   transaction has become its sole holder and they want to upgrade to an
+	//   Exclusive lock, are removed from the queue (see
+	//   releaseCompatibleWaiters).
+	//
+	// Upgrade locks are not supported.
 
 	reservation *lockTableGuardImpl
 
@@ -717,7 +738,8 @@ type lockWaitQueue struct {
 	queuedWriters list.List
 
 	// List of *lockTableGuardImpl. All of these are actively waiting. If
-	// non-empty, the lock must be held. By definition these cannot be in
+	// non-empty, the lock must be held with Exclusive strength. By definition
+	// these cannot be in
 	// waitSelf state since that state is only used when there is a reservation.
 	waitingReaders list.List
 
@@ -781,12 +803,24 @@ func (l *lockState) Format(buf *strings.Builder) {
 		}
 		fmt.Fprintln(b, "")
 	}
+	writeSharedHolderInfo := func(b *strings.Builder, h *lockHolderInfo) {
+		fmt.Fprintf(b, "  shared holder: txn: %v, ts: %v, info: unrepl epoch: %d, seqs: [%d",
+			h.txn.ID, h.ts, h.txn.Epoch, h.seqs[0])
+		for j := 1; j < len(h.seqs); j++ {
+			fmt.Fprintf(b, ", %d", h.seqs[j])
+		}
+		fmt.Fprintln(b, "]")
+	}
 	txn, ts := l.getLockHolder()
-	if txn == nil {
+	if txn != nil {
+		writeHolderInfo(buf, txn, ts)
+	} else if len(l.holder.shared) > 0 {
+		for i := range l.holder.shared {
+			writeSharedHolderInfo(buf, &l.holder.shared[i])
+		}
+	} else {
 		fmt.Fprintf(buf, "  res: req: %d, ", l.reservation.seqNum)
 		writeResInfo(buf, l.reservation.txn, l.reservation.writeTS)
-	} else {
-		writeHolderInfo(buf, txn, ts)
 	}
 	// TODO(sumeer): Add an optional `description string` field to Request and
 	// lockTableGuardImpl that tests can set to avoid relying on the seqNum to
@@ -840,20 +874,26 @@ func (l *lockState) tryBreakReservation(seqNum uint64) bool {
 
 // Informs active waiters about reservation or lock holder. The reservation
 // may have changed so this needs to fix any inconsistencies wrt waitSelf and
-// waitForDistinguished states.
+// waitForDistinguished states. If the lock is held with Shared strength, all
+// the active waiters must conflict with it (see releaseCompatibleWaiters).
 // REQUIRES: l.mu is locked.
 func (l *lockState) informActiveWaiters() {
 	waitForState := waitingState{kind: waitFor, key: l.key}
 	findDistinguished := l.distinguishedWaiter == nil
+	sharedLocked := false
 	if lockHolderTxn, _ := l.getLockHolder(); lockHolderTxn != nil {
 		waitForState.txn = lockHolderTxn
 		waitForState.held = true
-	} else {
+	} else if l.reservation != nil {
 		waitForState.txn = l.reservation.txn
 		if !findDistinguished && l.distinguishedWaiter.isSameTxnAsReservation(waitForState) {
 			findDistinguished = true
 			l.distinguishedWaiter = nil
 		}
+	} else {
+		// The lock is held with Shared strength. Each waiter may be waiting on
+		// a different transaction, see sharedLockConflict.
+		sharedLocked = true
 	}
 
 	for e := l.waitingReaders.Front(); e != nil; e = e.Next() {
@@ -881,11 +921,16 @@ func (l *lockState) informActiveWaiters() {
 			continue
 		}
 		g := qg.guard
-		var state waitingState
-		if g.isSameTxnAsReservation(waitForState) {
+		state := waitForState
+		if sharedLocked {
+			state.txn, state.held = l.sharedLockConflict(g)
+			if state.txn == nil {
+				panic("lockTable bug - active waiter does not conflict with shared lock")
+			}
+		}
+		if g.isSameTxnAsReservation(state) {
 			state = waitingState{kind: waitSelf}
 		} else {
-			state = waitForState
 			state.guardAccess = spanset.SpanReadWrite
 			if findDistinguished {
 				l.distinguishedWaiter = g
@@ -927,6 +972,84 @@ func (l *lockState) releaseWritersFromTxn(txn *enginepb.TxnMeta) {
 	}
 }
 
+// Returns the index of the transaction with the given id in the Shared lock
+// holders, or -1 if the transaction does not hold the lock with Shared
+// strength.
+// REQUIRES: l.mu is locked.
+func (l *lockState) findSharedHolder(id uuid.UUID) int {
+	for i := range l.holder.shared {
+		if l.holder.shared[i].txn.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the transaction that the request g, which accesses the key with
+// SpanReadWrite, conflicts with on a lock that is held with Shared strength,
+// along with whether that transaction holds the lock. Returns a nil
+// transaction if g is compatible with the lock and can proceed to acquire it.
+// See the comment about "Shared locks" in lockWaitQueue.
+// REQUIRES: l.mu is locked and the lock is held with Shared strength.
+func (l *lockState) sharedLockConflict(g *lockTableGuardImpl) (*enginepb.TxnMeta, bool) {
+	if g.txn == nil || g.str != lock.Shared {
+		// Incompatible with all Shared lock holders other than its own
+		// transaction. Note that non-transactional writers are incompatible
+		// with all of them.
+		for i := range l.holder.shared {
+			if txn := l.holder.shared[i].txn; !g.isSameTxn(txn) {
+				return txn, true
+			}
+		}
+		return nil, false
+	}
+	if l.findSharedHolder(g.txn.ID) >= 0 {
+		return nil, false
+	}
+	// Compatible with the holders, but must not jump ahead of a transactional
+	// request with a lower seqNum that is waiting to acquire an Exclusive lock.
+	for e := l.queuedWriters.Front(); e != nil; e = e.Next() {
+		qg := e.Value.(*queuedGuard)
+		w := qg.guard
+		if w.seqNum >= g.seqNum {
+			break
+		}
+		if w.txn != nil && w.str != lock.Shared && !g.isSameTxn(w.txn) {
+			return w.txn, false
+		}
+	}
+	return nil, false
+}
+
+// Removes the waiting writers that no longer conflict with a lock that is
+// held with Shared strength. Active waiters are told to look for the next lock
+// to wait at. If the distinguished waiter is removed, the caller is
+// responsible for finding a new one, typically by calling
+// informActiveWaiters.
+// REQUIRES: l.mu is locked and the lock is held with Shared strength.
+func (l *lockState) releaseCompatibleWaiters() {
+	for e := l.queuedWriters.Front(); e != nil; {
+		qg := e.Value.(*queuedGuard)
+		curr := e
+		e = e.Next()
+		g := qg.guard
+		if txn, _ := l.sharedLockConflict(g); txn != nil {
+			continue
+		}
+		l.queuedWriters.Remove(curr)
+		if qg.active {
+			if g == l.distinguishedWaiter {
+				l.distinguishedWaiter = nil
+			}
+			g.doneWaitingAtLock(false, l)
+		} else {
+			g.mu.Lock()
+			delete(g.mu.locks, l)
+			g.mu.Unlock()
+		}
+	}
+}
+
 // When the active waiters have shrunk and the distinguished waiter has gone,
 // try to make a new distinguished waiter if there is at least 1 active
 // waiter.
@@ -958,7 +1081,7 @@ func (l *lockState) tryMakeNewDistinguished() {
 // reservation.
 // REQUIRES: l.mu is locked.
 func (l *lockState) isEmptyLock() bool {
-	if !l.holder.locked && l.reservation == nil {
+	if !l.holder.locked && l.reservation == nil && len(l.holder.shared) == 0 {
 		for i := range l.holder.holder {
 			if !l.holder.holder[i].isEmpty() {
 				panic("lockState with !locked but non-zero lockHolderInfo")
@@ -972,8 +1095,8 @@ func (l *lockState) isEmptyLock() bool {
 	return false
 }
 
-// Returns true iff the lock is currently held by the transaction with the
-// given id.
+// Returns true iff the lock is currently held with Exclusive strength by the
+// transaction with the given id.
 // REQUIRES: l.mu is locked.
 func (l *lockState) isLockedBy(id uuid.UUID) bool {
 	if l.holder.locked {
@@ -988,8 +1111,8 @@ func (l *lockState) isLockedBy(id uuid.UUID) bool {
 	return false
 }
 
-// Returns information about the current lock holder if the lock is held, else
-// returns nil.
+// Returns information about the current lock holder if the lock is held with
+// Exclusive strength, else returns nil.
 // REQUIRES: l.mu is locked.
 func (l *lockState) getLockHolder() (*enginepb.TxnMeta, hlc.Timestamp) {
 	if !l.holder.locked {
@@ -1013,13 +1136,14 @@ func (l *lockState) getLockHolder() (*enginepb.TxnMeta, hlc.Timestamp) {
 	return l.holder.holder[index].txn, l.holder.holder[index].ts
 }
 
-// Removes the current lock holder from the lock.
+// Removes the current lock holder(s) from the lock.
 // REQUIRES: l.mu is locked.
 func (l *lockState) clearLockHolder() {
 	l.holder.locked = false
 	for i := range l.holder.holder {
 		l.holder.holder[i] = lockHolderInfo{}
 	}
+	l.holder.shared = nil
 }
 
 // Decides whether the request g with access sa should actively wait at this
@@ -1049,7 +1173,8 @@ func (l *lockState) tryActiveWait(g *lockTableGuardImpl, sa spanset.SpanAccess,
 
 	if sa == spanset.SpanReadOnly {
 		if lockHolderTxn == nil {
-			// Reads only care about locker, not a reservation.
+			// Reads only care about an Exclusive locker, not a reservation or
+			// Shared lockers.
 			return false
 		}
 		// Locked by some other txn.
@@ -1081,21 +1206,31 @@ func (l *lockState) tryActiveWait(g *lockTableGuardImpl, sa spanset.SpanAccess,
 	if lockHolderTxn != nil {
 		waitForState.txn = lockHolderTxn
 		waitForState.held = true
-	} else {
+	} else if l.reservation != nil {
 		if l.reservation == g {
 			// Already reserved by this request.
 			return false
 		}
 		// A non-transactional write request never makes or breaks reservations,
 		// and only waits for a reservation if the reservation has a lower
-		// seqNum. Note that `sa == spanset.SpanRead && lockHolderTxn == nil`
-		// was already checked above.
-		if g.txn == nil && l.reservation.seqNum > g.seqNum {
+		// seqNum. The same is true for a request that intends to acquire a
+		// Shared lock, except that it breaks the reservation if it goes on to
+		// acquire the lock. Note that `sa == spanset.SpanRead && lockHolderTxn
+		// == nil` was already checked above.
+		if (g.txn == nil || g.str == lock.Shared) && l.reservation.seqNum > g.seqNum {
 			// Reservation is held by a request with a higher seqNum and g is a
-			// non-transactional request. Ignore the reservation.
+			// non-transactional request or a Shared locker. Ignore the
+			// reservation.
 			return false
 		}
 		waitForState.txn = l.reservation.txn
+	} else {
+		// Held with Shared strength.
+		waitForState.txn, waitForState.held = l.sharedLockConflict(g)
+		if waitForState.txn == nil {
+			// Compatible with the lock.
+			return false
+		}
 	}
 
 	// Incompatible with whoever is holding lock or reservation.
@@ -1185,10 +1320,23 @@ func (l *lockState) tryActiveWait(g *lockTableGuardImpl, sa spanset.SpanAccess,
 // that is acquiring the lock.
 // Acquires l.mu.
 func (l *lockState) acquireLock(
-	_ lock.Strength, durability lock.Durability, txn *enginepb.TxnMeta, ts hlc.Timestamp,
+	str lock.Strength, durability lock.Durability, txn *enginepb.TxnMeta, ts hlc.Timestamp,
 ) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if str == lock.Shared {
+		return l.acquireSharedLock(txn, ts)
+	}
+	wasSharedLocked := len(l.holder.shared) > 0
+	if wasSharedLocked {
+		// The lock can only be converted from a Shared lock to an Exclusive
+		// lock if this transaction is its sole holder, since the request must
+		// otherwise have waited for the other holders.
+		if len(l.holder.shared) > 1 || l.holder.shared[0].txn.ID != txn.ID {
+			return errors.AssertionFailedf("existing shared lock cannot be acquired by different transaction")
+		}
+		l.holder.shared = nil
+	}
 	if l.holder.locked {
 		// Already held.
 		beforeTxn, beforeTs := l.getLockHolder()
@@ -1293,7 +1441,9 @@ func (l *lockState) acquireLock(
 			panic("lockTable bug")
 		}
 	} else {
-		if l.queuedWriters.Len() > 0 || l.waitingReaders.Len() > 0 {
+		// Only a lock that was held with Shared strength can have queued
+		// writers without a reservation.
+		if (!wasSharedLocked && l.queuedWriters.Len() > 0) || l.waitingReaders.Len() > 0 {
 			panic("lockTable bug")
 		}
 	}
@@ -1311,6 +1461,87 @@ func (l *lockState) acquireLock(
 	return nil
 }
 
+// Acquires this lock with Shared strength. Shared locks are always held with
+// Unreplicated durability.
+// REQUIRES: l.mu is locked.
+func (l *lockState) acquireSharedLock(txn *enginepb.TxnMeta, ts hlc.Timestamp) error {
+	if l.holder.locked {
+		if beforeTxn, _ := l.getLockHolder(); txn.ID != beforeTxn.ID {
+			return errors.AssertionFailedf("existing lock cannot be acquired by different transaction")
+		}
+		// Already held with Exclusive strength, which provides all the
+		// protection of a Shared lock.
+		return nil
+	}
+	if i := l.findSharedHolder(txn.ID); i >= 0 {
+		// Already held. See the comment in acquireLock about forwarding the
+		// lock's timestamp and tracking sequence numbers. Shared locks do not
+		// conflict with non-locking reads, so a change to the timestamp does
+		// not affect any waiters.
+		holder := &l.holder.shared[i]
+		if holder.txn.Epoch < txn.Epoch {
+			// Clear the sequences for the older epoch.
+			holder.seqs = holder.seqs[:0]
+		}
+		if len(holder.seqs) == 0 || holder.seqs[len(holder.seqs)-1] < txn.Sequence {
+			holder.txn = txn
+		}
+		holder.seqs = insertSeq(holder.seqs, txn.Sequence)
+		holder.ts.Forward(ts)
+		return nil
+	}
+	// Not already held by this transaction, so may be reserved by a request
+	// that intends to acquire an Exclusive lock. Like in acquireLock, the
+	// reservation may belong to a different transaction, in which case it is
+	// broken, since Shared lockers do not wait for reservations held by
+	// requests with a higher seqNum.
+	if l.reservation != nil {
+		if l.reservation.txn.ID != txn.ID {
+			qg := &queuedGuard{
+				guard:  l.reservation,
+				active: false,
+			}
+			l.queuedWriters.PushFront(qg)
+		} else {
+			l.reservation.mu.Lock()
+			delete(l.reservation.mu.locks, l)
+			l.reservation.mu.Unlock()
+		}
+		l.reservation = nil
+	}
+	if l.waitingReaders.Len() > 0 {
+		panic("lockTable bug")
+	}
+	l.holder.shared = append(l.holder.shared, lockHolderInfo{
+		txn:  txn,
+		seqs: append([]enginepb.TxnSeq(nil), txn.Sequence),
+		ts:   ts,
+	})
+
+	// Waiting requests from the same txn, and requests that only conflicted
+	// with a reservation, may no longer need to wait.
+	l.releaseCompatibleWaiters()
+
+	// Inform active waiters since lock has transitioned to held.
+	l.informActiveWaiters()
+	return nil
+}
+
+// Inserts seq into seqs, which is in increasing order, if it is not already
+// present.
+func insertSeq(seqs []enginepb.TxnSeq, seq enginepb.TxnSeq) []enginepb.TxnSeq {
+	i := sort.Search(len(seqs), func(i int) bool {
+		return seqs[i] >= seq
+	})
+	if i < len(seqs) && seqs[i] == seq {
+		return seqs
+	}
+	seqs = append(seqs, 0)
+	copy(seqs[i+1:], seqs[i:])
+	seqs[i] = seq
+	return seqs
+}
+
 // A replicated lock held by txn with timestamp ts was discovered by guard g
 // where g is trying to access this key with access sa.
 // Acquires l.mu.
@@ -1325,6 +1556,14 @@ func (l *lockState) discoveredLock(
 			return errors.AssertionFailedf("discovered lock by different transaction than existing lock")
 		}
 	} else {
+		if len(l.holder.shared) > 0 {
+			// The transaction must have converted its Shared lock into an
+			// Exclusive lock.
+			if len(l.holder.shared) > 1 || l.holder.shared[0].txn.ID != txn.ID {
+				return errors.AssertionFailedf("discovered lock by different transaction than existing shared lock")
+			}
+			l.holder.shared = nil
+		}
 		l.holder.locked = true
 	}
 	holder := &l.holder.holder[lock.Replicated]
@@ -1411,8 +1650,9 @@ func (l *lockState) tryClearLock(force bool) bool {
 		return false
 	}
 
-	// Remove unreplicated holder.
+	// Remove unreplicated holder(s). Shared locks are always unreplicated.
 	l.holder.holder[lock.Unreplicated] = lockHolderInfo{}
+	l.holder.shared = nil
 	var waitState waitingState
 	if replicatedHeld && !force {
 		lockHolderTxn, _ := l.getLockHolder()
@@ -1496,6 +1736,9 @@ func removeIgnored(
 func (l *lockState) tryUpdateLock(up *roachpb.LockUpdate) (gc bool, err error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if i := l.findSharedHolder(up.Txn.ID); i >= 0 {
+		return l.updateSharedLock(i, up), nil
+	}
 	if !l.isLockedBy(up.Txn.ID) {
 		return false, nil
 	}
@@ -1572,6 +1815,41 @@ func (l *lockState) tryUpdateLock(up *roachpb.LockUpdate) (gc bool, err error) {
 	return false, nil
 }
 
+// Updates the Shared lock held by the transaction at index i of the Shared
+// lock holders, mirroring the handling of unreplicated locks in tryUpdateLock.
+// Returns whether the lockState can be garbage collected.
+// REQUIRES: l.mu is locked.
+func (l *lockState) updateSharedLock(i int, up *roachpb.LockUpdate) (gc bool) {
+	holder := &l.holder.shared[i]
+	txn := &up.Txn
+	release := up.Status.IsFinalized() || txn.Epoch > holder.txn.Epoch
+	if !release && txn.Epoch == holder.txn.Epoch {
+		holder.seqs = removeIgnored(holder.seqs, up.IgnoredSeqNums)
+		release = len(holder.seqs) == 0
+	}
+	if !release {
+		// Shared locks do not conflict with non-locking reads, so advancing
+		// the timestamp of the lock does not affect any waiters.
+		if holder.ts.Less(txn.WriteTimestamp) {
+			holder.ts = txn.WriteTimestamp
+			if txn.Epoch == holder.txn.Epoch {
+				holder.txn = txn
+			}
+		}
+		return false
+	}
+
+	l.holder.shared = append(l.holder.shared[:i], l.holder.shared[i+1:]...)
+	if len(l.holder.shared) == 0 {
+		l.holder.shared = nil
+		return l.lockIsFree()
+	}
+	// Some of the waiters may no longer conflict with the remaining holders.
+	l.releaseCompatibleWaiters()
+	l.informActiveWaiters()
+	return false
+}
+
 // The lock holder timestamp has increased. Some of the waiters may no longer
 // need to wait.
 // REQUIRES: l.mu is locked.
@@ -1652,6 +1930,13 @@ func (l *lockState) requestDone(g *lockTableGuardImpl) (gc bool) {
 	if !doneRemoval {
 		panic("lockTable bug")
 	}
+	if len(l.holder.shared) > 0 {
+		// The request may have been a waiter that the requests behind it, which
+		// intend to acquire a Shared lock, were deferring to.
+		l.releaseCompatibleWaiters()
+		l.informActiveWaiters()
+		return false
+	}
 	if distinguishedRemoved {
 		l.tryMakeNewDistinguished()
 	}
@@ -1702,7 +1987,7 @@ func (l *lockState) tryFreeLockOnReplicatedAcquire() bool {
 // waiters, but there cannot be a reservation.
 // REQUIRES: l.mu is locked.
 func (l *lockState) lockIsFree() (gc bool) {
-	if l.holder.locked {
+	if l.holder.locked || len(l.holder.shared) > 0 {
 		panic("called lockIsFree on lock with holder")
 	}
 	if l.reservation != nil {
@@ -1721,8 +2006,9 @@ func (l *lockState) lockIsFree() (gc bool) {
 		g.doneWaitingAtLock(false, l)
 	}
 
-	// The prefix of the queue that is non-transactional writers is done
-	// waiting.
+	// The prefix of the queue that is non-transactional writers or requests
+	// that intend to acquire a Shared lock is done waiting. Neither can make a
+	// reservation.
 	for e := l.queuedWriters.Front(); e != nil; {
 		qg := e.Value.(*queuedGuard)
 		g := qg.guard
@@ -1734,6 +2020,20 @@ func (l *lockState) lockIsFree() (gc bool) {
 				l.distinguishedWaiter = nil
 			}
 			g.doneWaitingAtLock(false, l)
+		} else if g.str == lock.Shared {
+			curr := e
+			e = e.Next()
+			l.queuedWriters.Remove(curr)
+			if qg.active {
+				if g == l.distinguishedWaiter {
+					l.distinguishedWaiter = nil
+				}
+				g.doneWaitingAtLock(false, l)
+			} else {
+				g.mu.Lock()
+				delete(g.mu.locks, l)
+				g.mu.Unlock()
+			}
 		} else {
 			break
 		}
@@ -1743,7 +2043,8 @@ func (l *lockState) lockIsFree() (gc bool) {
 		return true
 	}
 
-	// First waiting writer (it must be transactional) gets the reservation.
+	// First waiting writer (it must be transactional and intend to acquire an
+	// Exclusive lock) gets the reservation.
 	e := l.queuedWriters.Front()
 	qg := e.Value.(*queuedGuard)
 	g := qg.guard
@@ -1783,6 +2084,7 @@ func (t *lockTableImpl) ScanAndEnqueue(req Request, guard lockTableGuard) lockTa
 		g.spans = req.LockSpans
 		g.readTS = req.readConflictTimestamp()
 		g.writeTS = req.writeConflictTimestamp()
+		g.str = req.writeLockStrength()
 		g.sa = spanset.NumSpanAccess - 1
 		g.index = -1
 	} else {
@@ -1901,8 +2203,14 @@ func (t *lockTableImpl) AcquireLock(
 		// If not enabled, don't track any locks.
 		return nil
 	}
-	if strength != lock.Exclusive {
-		return errors.AssertionFailedf("lock strength not Exclusive")
+	switch strength {
+	case lock.Exclusive:
+	case lock.Shared:
+		if durability != lock.Unreplicated {
+			return errors.AssertionFailedf("lock strength Shared requires Unreplicated durability")
+		}
+	default:
+		return errors.AssertionFailedf("unsupported lock strength %s", strength)
 	}
 	ss := spanset.SpanGlobal
 	if keys.IsLocal(key) {
@@ -52,10 +52,11 @@ new-txn txn=<name> ts=<int>[,<int>] epoch=<int> [seq=<int>]
 
  Creates a TxnMeta.
 
-new-request r=<name> txn=<name>|none ts=<int>[,<int>] spans=r|w@<start>[,<end>]+...
+new-request r=<name> txn=<name>|none ts=<int>[,<int>] spans=r|w@<start>[,<end>]+... [str=shared|exclusive] [wait-policy=block|error]
 ----
 
- Creates a Request.
+ Creates a Request. The strength determines the strength of the locks that the
+ request intends to acquire on its write spans, and defaults to exclusive.
 
 scan r=<name>
 ----
@@ -64,11 +65,12 @@ scan r=<name>
  Calls lockTable.ScanAndEnqueue. If the request has an existing guard, uses it.
  If a guard is returned, stores it for later use.
 
-acquire r=<name> k=<key> durability=r|u
+acquire r=<name> k=<key> durability=r|u [str=shared|exclusive]
 ----
 <error string>
 
- Acquires lock for the request, using the existing guard for that request.
+ Acquires lock for the request, using the existing guard for that request. The
+ strength defaults to exclusive.
 
 release txn=<name> span=<start>[,<end>]
 ----
@@ -201,9 +203,22 @@ func TestLockTableBasic(t *testing.T) {
 				spans := scanSpans(t, d, ts)
 				req := Request{
 					Timestamp:  ts,
+					WaitPolicy: scanWaitPolicy(t, d),
 					LatchSpans: spans,
 					LockSpans:  spans,
 				}
+				if scanLockStrength(t, d) == lock.Shared {
+					// Represent the request as a Shared locking scan over its write
+					// spans, which is what determines its lock strength.
+					for _, span := range spans.GetSpans(spanset.SpanReadWrite, spanset.SpanGlobal) {
+						var ru roachpb.RequestUnion
+						ru.MustSetInner(&roachpb.ScanRequest{
+							RequestHeader: roachpb.RequestHeaderFromSpan(span.Span),
+							KeyLocking:    lock.Shared,
+						})
+						req.Requests = append(req.Requests, ru)
+					}
+				}
 				if txnMeta != nil {
 					// Update the transaction's timestamp, if necessary. The transaction
 					// may have needed to move its timestamp for any number of reasons.
@@ -246,7 +261,7 @@ func TestLockTableBasic(t *testing.T) {
 				if s[0] == 'r' {
 					durability = lock.Replicated
 				}
-				if err := lt.AcquireLock(&req.Txn.TxnMeta, roachpb.Key(key), lock.Exclusive, durability); err != nil {
+				if err := lt.AcquireLock(&req.Txn.TxnMeta, roachpb.Key(key), scanLockStrength(t, d), durability); err != nil {
 					return err.Error()
 				}
 				return lt.(*lockTableImpl).String()
@@ -460,6 +475,40 @@ func scanTimestamp(t *testing.T, d *datadriven.TestData) hlc.Timestamp {
 	return ts
 }
 
+func scanLockStrength(t *testing.T, d *datadriven.TestData) lock.Strength {
+	if !d.HasArg("str") {
+		return lock.Exclusive
+	}
+	var strS string
+	d.ScanArgs(t, "str", &strS)
+	switch strS {
+	case "shared":
+		return lock.Shared
+	case "exclusive":
+		return lock.Exclusive
+	default:
+		d.Fatalf(t, "unknown lock strength: %s", strS)
+		return 0
+	}
+}
+
+func scanWaitPolicy(t *testing.T, d *datadriven.TestData) lock.WaitPolicy {
+	if !d.HasArg("wait-policy") {
+		return lock.WaitPolicy_Block
+	}
+	var policy string
+	d.ScanArgs(t, "wait-policy", &policy)
+	switch policy {
+	case "block":
+		return lock.WaitPolicy_Block
+	case "error":
+		return lock.WaitPolicy_Error
+	default:
+		d.Fatalf(t, "unknown wait policy: %s", policy)
+		return 0
+	}
+}
+
 func getSpan(t *testing.T, d *datadriven.TestData, str string) roachpb.Span {
 	parts := strings.Split(str, ",")
 	span := roachpb.Span{Key: roachpb.Key(parts[0])}
@@ -26,8 +26,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
+	"github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
 )
 
 // LockTableLivenessPushDelay sets the delay before pushing in order to detect
@@ -110,6 +113,9 @@ type lockTableWaiterImpl struct {
 	// When set, WriteIntentError are propagated instead of pushing
 	// conflicting transactions.
 	disableTxnPushing bool
+	// When set, called with every ContentionEvent once the request stops
+	// waiting on the corresponding conflict.
+	onContentionEvent func(*roachpb.ContentionEvent)
 }
 
 // IntentResolver is an interface used by lockTableWaiterImpl to push
@@ -152,11 +158,25 @@ func (w *lockTableWaiterImpl) WaitOn(
 	// re-discover the intent(s) during evaluation and resolve them themselves.
 	var deferredResolution []roachpb.LockUpdate
 	defer w.resolveDeferredIntents(ctx, &err, &deferredResolution)
+	// Used to record the time spent waiting on each conflicting transaction.
+	h := w.newContentionEventHelper(req)
+	defer h.emit(ctx)
 	for {
 		select {
 		case <-newStateC:
-			timerC = nil
 			state := guard.CurState()
+			h.emitAndInit(ctx, state)
+			if timerC != nil && isSameConflict(state, timerWaitingState) {
+				// The request is still waiting on the same transaction in the
+				// same way, so the update does not indicate that the conflicting
+				// transaction made any progress. This happens when the set of
+				// Shared holders of a lock changes while a request waits on one
+				// of them. Keep the pending push timer instead of restarting it,
+				// or a steady stream of such updates would indefinitely delay the
+				// request's liveness and deadlock detection pushes.
+				continue
+			}
+			timerC = nil
 			switch state.kind {
 			case waitFor, waitForDistinguished:
 				if req.WaitPolicy == lock.WaitPolicy_Error {
@@ -342,7 +362,7 @@ func (w *lockTableWaiterImpl) WaitOn(
 
 		case <-timerC:
 			// If the request was in the waitFor or waitForDistinguished states
-			// and did not observe any update to its state for the entire delay,
+			// and did not observe any change to its state for the entire delay,
 			// it should push. It may be the case that the transaction is part
 			// of a dependency cycle or that the lock holder's coordinator node
 			// has crashed.
@@ -406,13 +426,17 @@ func (w *lockTableWaiterImpl) WaitOnLock(
 	if err != nil {
 		return roachpb.NewError(err)
 	}
-	return w.pushLockTxn(ctx, req, waitingState{
+	ws := waitingState{
 		kind:        waitFor,
 		txn:         &intent.Txn,
 		key:         intent.Key,
 		held:        true,
 		guardAccess: sa,
-	})
+	}
+	h := w.newContentionEventHelper(req)
+	defer h.emit(ctx)
+	h.emitAndInit(ctx, ws)
+	return w.pushLockTxn(ctx, req, ws)
 }
 
 // ClearCaches implements the lockTableWaiter interface.
@@ -706,6 +730,85 @@ func (c *txnCache) insertFrontLocked(txn *roachpb.Transaction) {
 	c.txns[0] = txn
 }
 
+// contentionEventHelper tracks the transaction that a request is waiting on
+// and emits a ContentionEvent, into the trace and to the onEvent callback, once
+// the request stops waiting on it.
+type contentionEventHelper struct {
+	onEvent func(*roachpb.ContentionEvent) // may be nil
+	// The ID of the waiting request's transaction, or the zero UUID if the
+	// request is not transactional.
+	waitingTxnID uuid.UUID
+
+	// The open event, if any, and the time at which waiting on it began.
+	ev     *roachpb.ContentionEvent
+	tBegin time.Time
+}
+
+// newContentionEventHelper returns a contentionEventHelper that records the
+// contention events of the provided request.
+func (w *lockTableWaiterImpl) newContentionEventHelper(req Request) contentionEventHelper {
+	h := contentionEventHelper{onEvent: w.onContentionEvent}
+	if req.Txn != nil {
+		h.waitingTxnID = req.Txn.ID
+	}
+	return h
+}
+
+// emit finalizes and emits the open contention event, if any.
+func (h *contentionEventHelper) emit(ctx context.Context) {
+	if h.ev == nil {
+		return
+	}
+	h.ev.Duration = timeutil.Since(h.tBegin)
+	if sp := opentracing.SpanFromContext(ctx); sp != nil {
+		sp.LogFields(otlog.String(tracing.ContentionEventField, h.ev.String()))
+	}
+	if h.onEvent != nil {
+		h.onEvent(h.ev)
+	}
+	h.ev = nil
+}
+
+// emitAndInit compares the waitingState's conflicting transaction and key, if
+// any, against the open contention event, if any. If they match, the request
+// continues to wait on the same conflict and no action is taken. Otherwise, the
+// open event is emitted and a new one is opened if the request is still
+// waiting.
+func (h *contentionEventHelper) emitAndInit(ctx context.Context, s waitingState) {
+	switch s.kind {
+	case waitFor, waitForDistinguished, waitElsewhere:
+		if s.txn == nil {
+			// Not waiting on a transaction, so there is no contention to record.
+			h.emit(ctx)
+			return
+		}
+		if h.ev != nil && (h.ev.TxnMeta.ID != s.txn.ID || !h.ev.Key.Equal(s.key)) {
+			h.emit(ctx)
+		}
+		if h.ev == nil {
+			h.ev = &roachpb.ContentionEvent{
+				Key:          s.key,
+				TxnMeta:      *s.txn,
+				WaitingTxnID: h.waitingTxnID,
+			}
+			h.tBegin = timeutil.Now()
+		}
+	case waitSelf:
+		// The request is waiting on another request from its own transaction,
+		// which is not contention with another transaction.
+		h.emit(ctx)
+	case doneWaiting:
+		h.emit(ctx)
+	}
+}
+
+// isSameConflict returns whether the two waiting states wait on the same
+// transaction at the same key, in the same manner.
+func isSameConflict(a, b waitingState) bool {
+	return a.kind == b.kind && a.held == b.held && a.guardAccess == b.guardAccess &&
+		a.txn != nil && b.txn != nil && a.txn.ID == b.txn.ID && a.key.Equal(b.key)
+}
+
 func newWriteIntentErr(ws waitingState) *Error {
 	return roachpb.NewError(&roachpb.WriteIntentError{
 		Intents: []roachpb.Intent{roachpb.MakeIntent(ws.txn, ws.key)},
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/intentresolver"
@@ -28,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -468,6 +470,88 @@ func testErrorWaitPush(t *testing.T, k waitKind, makeReq func() Request, expPush
 	})
 }
 
+// TestLockTableWaiterUnchangedConflict tests that the lockTableWaiter does not
+// postpone its push when it is informed of a new waiting state that waits on
+// the same transaction in the same way, as happens when the set of Shared
+// holders of a lock changes while a writer waits on one of them.
+func TestLockTableWaiterUnchangedConflict(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testutils.DefaultSucceedsSoonDuration)
+	defer cancel()
+	w, ir, g := setupLockTableWaiterTest()
+	defer w.stopper.Stop(ctx)
+	const delay = 10 * time.Millisecond
+	LockTableLivenessPushDelay.Override(&w.st.SV, delay)
+	LockTableDeadlockDetectionPushDelay.Override(&w.st.SV, time.Hour)
+
+	txn := makeTxnProto("request")
+	req := Request{
+		Txn:       &txn,
+		Timestamp: txn.ReadTimestamp,
+	}
+	keyA := roachpb.Key("keyA")
+	pusheeTxn := makeTxnProto("pushee")
+	g.state = waitingState{
+		kind:        waitForDistinguished,
+		txn:         &pusheeTxn.TxnMeta,
+		key:         keyA,
+		held:        true,
+		guardAccess: spanset.SpanReadWrite,
+	}
+	g.notify()
+
+	// Keep informing the waiter of its unchanged state more often than the
+	// push delay until it pushes.
+	pushed := make(chan struct{})
+	notifierDone := make(chan struct{})
+	go func() {
+		defer close(notifierDone)
+		ticker := time.NewTicker(delay / 10)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case g.signal <- struct{}{}:
+				default:
+				}
+			case <-pushed:
+				return
+			}
+		}
+	}()
+
+	ir.pushTxn = func(
+		_ context.Context,
+		pusheeArg *enginepb.TxnMeta,
+		_ roachpb.Header,
+		pushType roachpb.PushTxnType,
+	) (*roachpb.Transaction, *Error) {
+		require.Equal(t, &pusheeTxn.TxnMeta, pusheeArg)
+		require.Equal(t, roachpb.PUSH_ABORT, pushType)
+		close(pushed)
+		<-notifierDone
+
+		resp := &roachpb.Transaction{TxnMeta: *pusheeArg, Status: roachpb.ABORTED}
+		ir.resolveIntent = func(_ context.Context, intent roachpb.LockUpdate) *Error {
+			require.Equal(t, keyA, intent.Key)
+			require.Equal(t, pusheeTxn.ID, intent.Txn.ID)
+			g.state = waitingState{kind: doneWaiting}
+			// The notifier may have left a notification behind.
+			select {
+			case g.signal <- struct{}{}:
+			default:
+			}
+			return nil
+		}
+		return resp, nil
+	}
+
+	err := w.WaitOn(ctx, req, g)
+	require.Nil(t, err)
+}
+
 // TestLockTableWaiterIntentResolverError tests that the lockTableWaiter
 // propagates errors from its intent resolver when it pushes transactions
 // or resolves their intents.
@@ -615,6 +699,57 @@ func TestTxnCache(t *testing.T) {
 	}
 }
 
+func TestContentionEventHelper(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	var events []*roachpb.ContentionEvent
+	w := &lockTableWaiterImpl{onContentionEvent: func(ev *roachpb.ContentionEvent) {
+		events = append(events, ev)
+	}}
+
+	txn1, txn2, waitingTxn := makeTxnProto("txn1"), makeTxnProto("txn2"), makeTxnProto("waiting")
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	h := w.newContentionEventHelper(Request{Txn: &waitingTxn})
+
+	// Waiting on the same conflict repeatedly opens a single event.
+	h.emitAndInit(ctx, waitingState{kind: waitFor, txn: &txn1.TxnMeta, key: keyA})
+	h.emitAndInit(ctx, waitingState{kind: waitForDistinguished, txn: &txn1.TxnMeta, key: keyA})
+	require.Len(t, events, 0)
+
+	// Waiting on a different key emits the open event.
+	h.emitAndInit(ctx, waitingState{kind: waitFor, txn: &txn1.TxnMeta, key: keyB})
+	require.Len(t, events, 1)
+	require.Equal(t, keyA, events[0].Key)
+	require.Equal(t, txn1.ID, events[0].TxnMeta.ID)
+	require.Equal(t, waitingTxn.ID, events[0].WaitingTxnID)
+
+	// Waiting on a different transaction emits the open event.
+	h.emitAndInit(ctx, waitingState{kind: waitElsewhere, txn: &txn2.TxnMeta, key: keyB})
+	require.Len(t, events, 2)
+	require.Equal(t, keyB, events[1].Key)
+	require.Equal(t, txn1.ID, events[1].TxnMeta.ID)
+	require.Equal(t, waitingTxn.ID, events[1].WaitingTxnID)
+
+	// Waiting on the request's own transaction is not contention.
+	h.emitAndInit(ctx, waitingState{kind: waitSelf})
+	require.Len(t, events, 3)
+	require.Equal(t, txn2.ID, events[2].TxnMeta.ID)
+	require.Equal(t, waitingTxn.ID, events[2].WaitingTxnID)
+	h.emitAndInit(ctx, waitingState{kind: doneWaiting})
+	h.emit(ctx)
+	require.Len(t, events, 3)
+
+	// Non-transactional requests are recorded with a zero waiting txn ID.
+	h = w.newContentionEventHelper(Request{})
+	h.emitAndInit(ctx, waitingState{kind: waitFor, txn: &txn1.TxnMeta, key: keyA})
+	h.emit(ctx)
+	require.Len(t, events, 4)
+	require.Equal(t, txn1.ID, events[3].TxnMeta.ID)
+	require.Equal(t, uuid.UUID{}, events[3].WaitingTxnID)
+}
+
 func BenchmarkTxnCache(b *testing.B) {
 	rng := rand.New(rand.NewSource(timeutil.Now().UnixNano()))
 	var c txnCache
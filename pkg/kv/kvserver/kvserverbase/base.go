@@ -30,6 +30,17 @@ var MergeQueueEnabled = settings.RegisterBoolSetting(
 	true,
 )
 
+// ReplicatedLockingReadsEnabled controls whether the exclusive locks acquired
+// by locking reads are replicated. Writers only look for replicated locks
+// held by other transactions while it is enabled, so that clusters which
+// never use replicated locks don't pay for it.
+var ReplicatedLockingReadsEnabled = settings.RegisterBoolSetting(
+	"kv.transaction.replicated_locking_reads_enabled",
+	"if enabled, exclusive locks acquired by locking reads (e.g. SELECT FOR UPDATE) "+
+		"are replicated through Raft so that they survive lease transfers and node restarts",
+	false,
+)
+
 // TxnCleanupThreshold is the threshold after which a transaction is
 // considered abandoned and fit for removal, as measured by the
 // maximum of its last heartbeat and timestamp. Abort spans for the
@@ -0,0 +1,197 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/stateloader"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// PrepareReplicaReport describes the update staged for a single replica.
+type PrepareReplicaReport struct {
+	// OldDescriptor is the replica's range descriptor before the update.
+	OldDescriptor roachpb.RangeDescriptor
+	// NewDescriptor is the replica's range descriptor after the update.
+	NewDescriptor roachpb.RangeDescriptor
+	// AbortedTransactionID is set if a pending transaction had to be aborted
+	// to remove its intent from the range descriptor.
+	AbortedTransactionID uuid.UUID
+}
+
+// PrepareStoreReport describes the updates staged by PrepareUpdateReplicas.
+type PrepareStoreReport struct {
+	// UpdatedReplicas are the replicas whose descriptors were rewritten.
+	UpdatedReplicas []PrepareReplicaReport
+	// SkippedReplicas are the replicas for which the update was already
+	// applied by a previous run.
+	SkippedReplicas []roachpb.RangeDescriptor
+}
+
+// PrepareUpdateReplicas stages the updates of the plan which target the given
+// stores into the corresponding readWriters. Updates targeting other stores
+// are ignored, which allows the same plan to be applied to every surviving
+// node in turn. Updates that were already applied are skipped, so preparing a
+// plan is idempotent. An error is returned if a replica's descriptor changed
+// since the replica info was collected.
+//
+// The caller is responsible for committing the staged changes.
+func PrepareUpdateReplicas(
+	ctx context.Context,
+	plan ReplicaUpdatePlan,
+	clock *hlc.Clock,
+	stores map[roachpb.StoreID]storage.ReadWriter,
+) (PrepareStoreReport, error) {
+	var report PrepareStoreReport
+	for _, update := range plan.Updates {
+		rw, ok := stores[update.NewReplica.StoreID]
+		if !ok {
+			continue
+		}
+
+		var desc roachpb.RangeDescriptor
+		found, err := storage.MVCCGetProto(ctx, rw, keys.RangeDescriptorKey(update.StartKey),
+			clock.Now(), &desc, storage.MVCCGetOptions{Inconsistent: true})
+		if err != nil {
+			return PrepareStoreReport{}, errors.Wrapf(err,
+				"loading descriptor of r%d on s%d", update.RangeID, update.NewReplica.StoreID)
+		}
+		if !found || desc.RangeID != update.RangeID {
+			return PrepareStoreReport{}, errors.Errorf(
+				"descriptor of r%d not found on s%d", update.RangeID, update.NewReplica.StoreID)
+		}
+
+		replicas := desc.Replicas().All()
+		if len(replicas) == 1 && replicas[0].StoreID == update.NewReplica.StoreID &&
+			replicas[0].ReplicaID == update.NewReplica.ReplicaID &&
+			desc.NextReplicaID == update.NextReplicaID {
+			report.SkippedReplicas = append(report.SkippedReplicas, desc)
+			continue
+		}
+		oldReplica, ok := desc.GetReplicaDescriptor(update.NewReplica.StoreID)
+		if !ok || oldReplica.ReplicaID != update.OldReplicaID ||
+			desc.NextReplicaID != update.NewReplica.ReplicaID {
+			return PrepareStoreReport{}, errors.Errorf(
+				"descriptor of r%d on s%d changed since replica info was collected: %s",
+				update.RangeID, update.NewReplica.StoreID, desc)
+		}
+
+		newDesc := desc
+		newDesc.SetReplicas(roachpb.MakeReplicaDescriptors([]roachpb.ReplicaDescriptor{update.NewReplica}))
+		newDesc.NextReplicaID = update.NextReplicaID
+		abortedTxn, err := RewriteRangeDescriptor(ctx, rw, clock.Now(), &newDesc)
+		if err != nil {
+			return PrepareStoreReport{}, errors.Wrapf(err,
+				"rewriting descriptor of r%d on s%d", update.RangeID, update.NewReplica.StoreID)
+		}
+		replicaReport := PrepareReplicaReport{
+			OldDescriptor: desc,
+			NewDescriptor: newDesc,
+		}
+		if abortedTxn != nil {
+			replicaReport.AbortedTransactionID = abortedTxn.ID
+		}
+		report.UpdatedReplicas = append(report.UpdatedReplicas, replicaReport)
+	}
+	return report, nil
+}
+
+// RewriteRangeDescriptor writes the given descriptor to the range-local
+// descriptor key of its range. If the key holds the intent of a pending
+// transaction, the transaction is aborted and returned.
+//
+// The meta copies of the descriptor are not updated. Instead, they are left
+// in a temporarily inconsistent state and will be overwritten when the
+// cluster recovers and up-replicates the range from its single copy to
+// multiple copies. We rely on the fact that all range descriptor updates
+// start with a CPut on the range-local copy followed by a blind Put to the
+// meta copy.
+//
+// For example, if we have replicas on s1-s4 but s3 and s4 are dead, we will
+// rewrite the replica on s2 to have s2 as its only member only. When the
+// cluster is restarted (and the dead nodes remain dead), the rewritten
+// replica will be the only one able to make progress. It will elect itself
+// leader and upreplicate.
+//
+// The old replica on s1 is untouched by this process. It will eventually
+// either be overwritten by a new replica when s2 upreplicates, or it will be
+// destroyed by the replica GC queue after upreplication has happened and s1
+// is no longer a member. (Note that in the latter case, consistency between
+// s1 and s2 no longer matters; the consistency checker will only run on nodes
+// that the new leader believes are members of the range).
+//
+// Note that this does not guarantee fully consistent results; the most recent
+// writes to the raft log may have been lost. In the most unfortunate cases,
+// this means that we would be "winding back" a split or a merge, which is
+// almost certainly to result in irrecoverable corruption (for example, not
+// only will individual values stored in the meta ranges diverge, but there
+// will be keys not represented by any ranges or vice versa).
+func RewriteRangeDescriptor(
+	ctx context.Context, rw storage.ReadWriter, now hlc.Timestamp, desc *roachpb.RangeDescriptor,
+) (abortedTxn *enginepb.TxnMeta, _ error) {
+	key := keys.RangeDescriptorKey(desc.StartKey)
+	sl := stateloader.Make(desc.RangeID)
+	ms, err := sl.LoadMVCCStats(ctx, rw)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading MVCCStats")
+	}
+	err = storage.MVCCPutProto(ctx, rw, &ms, key, now, nil /* txn */, desc)
+	if wiErr := (*roachpb.WriteIntentError)(nil); errors.As(err, &wiErr) {
+		if len(wiErr.Intents) != 1 {
+			return nil, errors.Errorf("expected 1 intent, found %d: %s", len(wiErr.Intents), wiErr)
+		}
+		intent := wiErr.Intents[0]
+		// We rely on the property that transactions involving the range
+		// descriptor always start on the range-local descriptor's key. This
+		// guarantees that when the transaction commits, the intent will be
+		// resolved synchronously. If we see an intent on this key, we know
+		// that the transaction did not commit and we can abort it.
+		//
+		// TODO(nvanbenschoten): This need updating for parallel commits. If
+		// the transaction record is in the STAGING state, we can't just delete
+		// it. Simplest solution to this is to avoid parallel commits for
+		// membership change transactions; if we can't do that I don't think
+		// we'll be able to recover them with an offline tool.
+		//
+		// A crude form of the intent resolution process: abort the
+		// transaction by deleting its record.
+		txnKey := keys.TransactionKey(intent.Txn.Key, intent.Txn.ID)
+		if err := storage.MVCCDelete(ctx, rw, &ms, txnKey, hlc.Timestamp{}, nil); err != nil {
+			return nil, err
+		}
+		update := roachpb.LockUpdate{
+			Span:   roachpb.Span{Key: intent.Key},
+			Txn:    intent.Txn,
+			Status: roachpb.ABORTED,
+		}
+		if _, err := storage.MVCCResolveWriteIntent(ctx, rw, &ms, update); err != nil {
+			return nil, err
+		}
+		// With the intent resolved, we can try again.
+		if err := storage.MVCCPutProto(ctx, rw, &ms, key, now, nil /* txn */, desc); err != nil {
+			return nil, err
+		}
+		abortedTxn = &intent.Txn
+	} else if err != nil {
+		return nil, err
+	}
+	if err := sl.SetMVCCStats(ctx, rw, &ms); err != nil {
+		return nil, errors.Wrap(err, "updating MVCCStats")
+	}
+	return abortedTxn, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package loqrecovery implements offline recovery of ranges that have lost
+// quorum. Recovery proceeds in three steps, each of which operates on
+// stopped nodes:
+//
+//  1. CollectReplicaInfo reads the replica descriptors and raft state of all
+//     replicas found on the stores of a surviving node.
+//  2. PlanReplicas combines the information collected from all surviving
+//     nodes, determines which ranges can no longer make progress and picks
+//     the most up-to-date surviving replica of each such range to become its
+//     sole voter.
+//  3. PrepareUpdateReplicas stages the resulting descriptor rewrites into
+//     per-store batches, which are then committed by the caller.
+//
+// The collected info and the plan are plain structs that are serialized as
+// JSON so that they can be moved between nodes by an operator.
+package loqrecovery
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/stateloader"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/errors"
+)
+
+// ReplicaInfo describes a single replica found on a store during collection.
+type ReplicaInfo struct {
+	NodeID  roachpb.NodeID          `json:"node_id"`
+	StoreID roachpb.StoreID         `json:"store_id"`
+	Desc    roachpb.RangeDescriptor `json:"desc"`
+	// RaftAppliedIndex is the index of the last raft log entry applied to the
+	// replica's state machine.
+	RaftAppliedIndex uint64 `json:"raft_applied_index"`
+	// RaftCommittedIndex is the highest raft log index known by the replica
+	// to be committed.
+	RaftCommittedIndex uint64 `json:"raft_committed_index"`
+}
+
+// NodeReplicaInfo is the set of replicas collected from the stores of a
+// single node.
+type NodeReplicaInfo struct {
+	Replicas []ReplicaInfo `json:"replicas"`
+}
+
+// CollectReplicaInfo reads the replica descriptors and raft state of all
+// replicas found on the given stores. The stores must not be in use by a
+// running node.
+func CollectReplicaInfo(ctx context.Context, stores []storage.Engine) (NodeReplicaInfo, error) {
+	if len(stores) == 0 {
+		return NodeReplicaInfo{}, errors.New("no stores were provided for info collection")
+	}
+
+	var replicas []ReplicaInfo
+	for _, reader := range stores {
+		storeIdent, err := kvserver.ReadStoreIdent(ctx, reader)
+		if err != nil {
+			return NodeReplicaInfo{}, err
+		}
+		err = kvserver.IterateRangeDescriptors(ctx, reader, func(desc roachpb.RangeDescriptor) (bool, error) {
+			rsl := stateloader.Make(desc.RangeID)
+			appliedIndex, _, err := rsl.LoadAppliedIndex(ctx, reader)
+			if err != nil {
+				return false, errors.Wrapf(err, "loading applied index of r%d", desc.RangeID)
+			}
+			hs, err := rsl.LoadHardState(ctx, reader)
+			if err != nil {
+				return false, errors.Wrapf(err, "loading hard state of r%d", desc.RangeID)
+			}
+			replicas = append(replicas, ReplicaInfo{
+				NodeID:             storeIdent.NodeID,
+				StoreID:            storeIdent.StoreID,
+				Desc:               desc,
+				RaftAppliedIndex:   appliedIndex,
+				RaftCommittedIndex: hs.Commit,
+			})
+			return false, nil
+		})
+		if err != nil {
+			return NodeReplicaInfo{}, err
+		}
+	}
+	return NodeReplicaInfo{Replicas: replicas}, nil
+}
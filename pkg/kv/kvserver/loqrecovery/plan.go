@@ -0,0 +1,214 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/errors"
+)
+
+// ReplicaUpdate describes the rewrite of a surviving replica's descriptor
+// that turns it into the sole voter of its range.
+type ReplicaUpdate struct {
+	RangeID  roachpb.RangeID `json:"range_id"`
+	StartKey roachpb.RKey    `json:"start_key"`
+	// OldReplicaID is the ID of the surviving replica before the update.
+	OldReplicaID roachpb.ReplicaID `json:"old_replica_id"`
+	// NewReplica is the descriptor of the surviving replica after the update.
+	// It carries a fresh replica ID so that other surviving members of the old
+	// incarnation of the range, which are not in sync with it, do not
+	// recognize it.
+	NewReplica    roachpb.ReplicaDescriptor `json:"new_replica"`
+	NextReplicaID roachpb.ReplicaID         `json:"next_replica_id"`
+}
+
+// ReplicaUpdatePlan is the set of replica updates which have to be applied to
+// the surviving stores to restore quorum to all ranges.
+type ReplicaUpdatePlan struct {
+	Updates []ReplicaUpdate `json:"updates"`
+}
+
+// PlanningReport describes the decisions made by PlanReplicas.
+type PlanningReport struct {
+	// TotalReplicas is the number of replicas found on the surviving stores.
+	TotalReplicas int
+	// DiscardedNonSurvivors is the number of replicas of ranges without
+	// quorum that were not chosen to be the survivor.
+	DiscardedNonSurvivors int
+	// PresentStores are the stores which provided replica info.
+	PresentStores []roachpb.StoreID
+	// MissingStores are the stores referenced by range descriptors which did
+	// not provide replica info. They are presumed dead.
+	MissingStores []roachpb.StoreID
+	// Problems lists inconsistencies in the key space that would remain after
+	// the plan is applied, such as overlapping ranges or key spans not
+	// covered by any surviving replica.
+	Problems []string
+}
+
+// PlanReplicas computes the replica updates needed to restore quorum to all
+// ranges that lost it, using the replica info collected from all surviving
+// nodes.
+//
+// Stores referenced by range descriptors that didn't provide any info are
+// considered dead. If deadStoreIDs is not empty, it must match that set
+// exactly; this guards against accidentally omitting the info of a surviving
+// node. For every range that can't make progress without the dead stores,
+// the surviving replica with the highest raft applied index is chosen to
+// become the sole voter of the range, with ties broken by the highest store
+// ID.
+func PlanReplicas(
+	ctx context.Context, nodes []NodeReplicaInfo, deadStoreIDs []roachpb.StoreID,
+) (ReplicaUpdatePlan, PlanningReport, error) {
+	var report PlanningReport
+
+	presentStores := make(map[roachpb.StoreID]struct{})
+	replicasByRange := make(map[roachpb.RangeID][]ReplicaInfo)
+	for _, node := range nodes {
+		for _, r := range node.Replicas {
+			for _, existing := range replicasByRange[r.Desc.RangeID] {
+				if existing.StoreID == r.StoreID {
+					return ReplicaUpdatePlan{}, PlanningReport{}, errors.Errorf(
+						"replica info for r%d on s%d was provided more than once", r.Desc.RangeID, r.StoreID)
+				}
+			}
+			presentStores[r.StoreID] = struct{}{}
+			replicasByRange[r.Desc.RangeID] = append(replicasByRange[r.Desc.RangeID], r)
+			report.TotalReplicas++
+		}
+	}
+
+	missingStores := make(map[roachpb.StoreID]struct{})
+	for _, replicas := range replicasByRange {
+		for _, r := range replicas {
+			for _, rd := range r.Desc.Replicas().All() {
+				if _, ok := presentStores[rd.StoreID]; !ok {
+					missingStores[rd.StoreID] = struct{}{}
+				}
+			}
+		}
+	}
+	report.PresentStores = sortedStoreIDs(presentStores)
+	report.MissingStores = sortedStoreIDs(missingStores)
+
+	if len(deadStoreIDs) > 0 {
+		deadStores := make(map[roachpb.StoreID]struct{})
+		for _, id := range deadStoreIDs {
+			if _, ok := presentStores[id]; ok {
+				return ReplicaUpdatePlan{}, PlanningReport{}, errors.Errorf(
+					"s%d was marked as dead but replica info was collected from it", id)
+			}
+			deadStores[id] = struct{}{}
+		}
+		for _, id := range report.MissingStores {
+			if _, ok := deadStores[id]; !ok {
+				return ReplicaUpdatePlan{}, PlanningReport{}, errors.Errorf(
+					"no replica info was collected from s%d but it was not marked as dead", id)
+			}
+		}
+	}
+
+	isLive := func(rd roachpb.ReplicaDescriptor) bool {
+		_, ok := presentStores[rd.StoreID]
+		return ok
+	}
+
+	var plan ReplicaUpdatePlan
+	survivors := make([]ReplicaInfo, 0, len(replicasByRange))
+	for _, replicas := range replicasByRange {
+		survivor := pickSurvivor(replicas)
+		survivors = append(survivors, survivor)
+		desc := survivor.Desc
+		if desc.Replicas().CanMakeProgress(isLive) {
+			continue
+		}
+		report.DiscardedNonSurvivors += len(replicas) - 1
+		oldReplica, ok := desc.GetReplicaDescriptor(survivor.StoreID)
+		if !ok {
+			return ReplicaUpdatePlan{}, PlanningReport{}, errors.AssertionFailedf(
+				"r%d on s%d is not present in its own descriptor %s", desc.RangeID, survivor.StoreID, desc)
+		}
+		plan.Updates = append(plan.Updates, ReplicaUpdate{
+			RangeID:      desc.RangeID,
+			StartKey:     desc.StartKey,
+			OldReplicaID: oldReplica.ReplicaID,
+			NewReplica: roachpb.ReplicaDescriptor{
+				NodeID:    survivor.NodeID,
+				StoreID:   survivor.StoreID,
+				ReplicaID: desc.NextReplicaID,
+			},
+			NextReplicaID: desc.NextReplicaID + 1,
+		})
+	}
+	sort.Slice(plan.Updates, func(i, j int) bool {
+		return plan.Updates[i].RangeID < plan.Updates[j].RangeID
+	})
+	report.Problems = checkKeySpaceCoverage(survivors)
+	return plan, report, nil
+}
+
+// pickSurvivor returns the most up-to-date replica out of the surviving
+// replicas of a range.
+func pickSurvivor(replicas []ReplicaInfo) ReplicaInfo {
+	best := replicas[0]
+	for _, r := range replicas[1:] {
+		if r.RaftAppliedIndex > best.RaftAppliedIndex ||
+			(r.RaftAppliedIndex == best.RaftAppliedIndex && r.StoreID > best.StoreID) {
+			best = r
+		}
+	}
+	return best
+}
+
+// checkKeySpaceCoverage verifies that the descriptors of the chosen replicas
+// cover the whole key space exactly once and returns a description of every
+// gap and overlap found.
+func checkKeySpaceCoverage(survivors []ReplicaInfo) []string {
+	sort.Slice(survivors, func(i, j int) bool {
+		return survivors[i].Desc.StartKey.Less(survivors[j].Desc.StartKey)
+	})
+	var problems []string
+	prevEnd := roachpb.RKeyMin
+	var prevDesc roachpb.RangeDescriptor
+	for _, s := range survivors {
+		desc := s.Desc
+		switch {
+		case prevEnd.Less(desc.StartKey):
+			problems = append(problems, fmt.Sprintf(
+				"key span %s-%s is not covered by any surviving replica", prevEnd, desc.StartKey))
+		case desc.StartKey.Less(prevEnd):
+			problems = append(problems, fmt.Sprintf(
+				"range %s overlaps with range %s", desc, prevDesc))
+		}
+		if prevEnd.Less(desc.EndKey) {
+			prevEnd = desc.EndKey
+			prevDesc = desc
+		}
+	}
+	if !prevEnd.Equal(roachpb.RKeyMax) {
+		problems = append(problems, fmt.Sprintf(
+			"key span %s-%s is not covered by any surviving replica", prevEnd, roachpb.RKeyMax))
+	}
+	return problems
+}
+
+func sortedStoreIDs(stores map[roachpb.StoreID]struct{}) []roachpb.StoreID {
+	ids := make([]roachpb.StoreID, 0, len(stores))
+	for id := range stores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+// makeDesc returns a descriptor for the given span with one voter on each of
+// the given stores. Node IDs are equal to store IDs.
+func makeDesc(
+	rangeID roachpb.RangeID, start, end roachpb.RKey, storeIDs ...roachpb.StoreID,
+) roachpb.RangeDescriptor {
+	desc := roachpb.RangeDescriptor{
+		RangeID:       rangeID,
+		StartKey:      start,
+		EndKey:        end,
+		NextReplicaID: roachpb.ReplicaID(len(storeIDs) + 1),
+	}
+	var replicas []roachpb.ReplicaDescriptor
+	for i, storeID := range storeIDs {
+		replicas = append(replicas, roachpb.ReplicaDescriptor{
+			NodeID:    roachpb.NodeID(storeID),
+			StoreID:   storeID,
+			ReplicaID: roachpb.ReplicaID(i + 1),
+		})
+	}
+	desc.SetReplicas(roachpb.MakeReplicaDescriptors(replicas))
+	return desc
+}
+
+func makeInfo(
+	storeID roachpb.StoreID, appliedIndex uint64, desc roachpb.RangeDescriptor,
+) loqrecovery.ReplicaInfo {
+	return loqrecovery.ReplicaInfo{
+		NodeID:             roachpb.NodeID(storeID),
+		StoreID:            storeID,
+		Desc:               desc,
+		RaftAppliedIndex:   appliedIndex,
+		RaftCommittedIndex: appliedIndex,
+	}
+}
+
+func TestPlanReplicas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	keyB := roachpb.RKey("b")
+
+	// r1 keeps quorum since two of its three replicas survive. r2 loses
+	// quorum with two of its five replicas surviving, the one on s2 being
+	// the most up to date.
+	r1 := makeDesc(1, roachpb.RKeyMin, keyB, 1, 2, 3)
+	r2 := makeDesc(2, keyB, roachpb.RKeyMax, 1, 2, 4, 5, 6)
+	nodes := []loqrecovery.NodeReplicaInfo{
+		{Replicas: []loqrecovery.ReplicaInfo{makeInfo(1, 10, r1), makeInfo(1, 20, r2)}},
+		{Replicas: []loqrecovery.ReplicaInfo{makeInfo(2, 12, r1), makeInfo(2, 25, r2)}},
+	}
+
+	t.Run("plan", func(t *testing.T) {
+		plan, report, err := loqrecovery.PlanReplicas(ctx, nodes, nil /* deadStoreIDs */)
+		require.NoError(t, err)
+		require.Equal(t, []loqrecovery.ReplicaUpdate{{
+			RangeID:       2,
+			StartKey:      keyB,
+			OldReplicaID:  2,
+			NewReplica:    roachpb.ReplicaDescriptor{NodeID: 2, StoreID: 2, ReplicaID: 6},
+			NextReplicaID: 7,
+		}}, plan.Updates)
+		require.Equal(t, 4, report.TotalReplicas)
+		require.Equal(t, 1, report.DiscardedNonSurvivors)
+		require.Equal(t, []roachpb.StoreID{1, 2}, report.PresentStores)
+		require.Equal(t, []roachpb.StoreID{3, 4, 5, 6}, report.MissingStores)
+		require.Empty(t, report.Problems)
+	})
+
+	t.Run("dead stores", func(t *testing.T) {
+		_, _, err := loqrecovery.PlanReplicas(ctx, nodes, []roachpb.StoreID{3, 4, 5, 6})
+		require.NoError(t, err)
+		_, _, err = loqrecovery.PlanReplicas(ctx, nodes, []roachpb.StoreID{3, 4, 5})
+		require.EqualError(t, err, "no replica info was collected from s6 but it was not marked as dead")
+		_, _, err = loqrecovery.PlanReplicas(ctx, nodes, []roachpb.StoreID{1, 3, 4, 5, 6})
+		require.EqualError(t, err, "s1 was marked as dead but replica info was collected from it")
+	})
+
+	t.Run("duplicate info", func(t *testing.T) {
+		_, _, err := loqrecovery.PlanReplicas(ctx, append(nodes, nodes[0]), nil /* deadStoreIDs */)
+		require.EqualError(t, err, "replica info for r1 on s1 was provided more than once")
+	})
+
+	t.Run("key space gap", func(t *testing.T) {
+		_, report, err := loqrecovery.PlanReplicas(ctx, []loqrecovery.NodeReplicaInfo{
+			{Replicas: []loqrecovery.ReplicaInfo{makeInfo(1, 20, r2)}},
+		}, nil /* deadStoreIDs */)
+		require.NoError(t, err)
+		require.Len(t, report.Problems, 1)
+		require.Contains(t, report.Problems[0], "is not covered by any surviving replica")
+	})
+
+	t.Run("key space overlap", func(t *testing.T) {
+		// A stale replica of r1 that hasn't applied the split which created r2.
+		stale := makeDesc(1, roachpb.RKeyMin, roachpb.RKeyMax, 1, 3, 4)
+		_, report, err := loqrecovery.PlanReplicas(ctx, []loqrecovery.NodeReplicaInfo{
+			{Replicas: []loqrecovery.ReplicaInfo{makeInfo(1, 20, r2)}},
+			{Replicas: []loqrecovery.ReplicaInfo{makeInfo(3, 5, stale)}},
+		}, nil /* deadStoreIDs */)
+		require.NoError(t, err)
+		require.Len(t, report.Problems, 1)
+		require.Contains(t, report.Problems[0], "overlaps with")
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package loqrecovery_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/loqrecovery"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecoverLossOfQuorum runs a three node cluster on sticky in-memory
+// stores, stops it and recovers a range replicated to all nodes using only
+// the store of the first node, which is then restarted on its own.
+func TestRecoverLossOfQuorum(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	defer server.CloseAllStickyInMemEngines()
+
+	ctx := context.Background()
+
+	const numNodes = 3
+	clusterArgs := base.TestClusterArgs{
+		ReplicationMode:   base.ReplicationManual,
+		ServerArgsPerNode: map[int]base.TestServerArgs{},
+	}
+	for i := 0; i < numNodes; i++ {
+		clusterArgs.ServerArgsPerNode[i] = base.TestServerArgs{
+			StoreSpecs: []base.StoreSpec{{
+				InMemory:               true,
+				StickyInMemoryEngineID: strconv.Itoa(i + 1),
+			}},
+		}
+	}
+
+	// Start the cluster, replicate a scratch range to all nodes, then stop
+	// it. The sticky engines survive the stop, so they can be inspected and
+	// repaired offline afterwards.
+	tc := testcluster.StartTestCluster(t, numNodes, clusterArgs)
+	scratchKey := tc.ScratchRange(t)
+	scratchDesc := tc.AddReplicasOrFatal(t, scratchKey, tc.Targets(1, 2)...)
+	require.NoError(t, tc.Server(0).DB().Put(ctx, scratchKey, "value"))
+	var engines []storage.Engine
+	for i := 0; i < numNodes; i++ {
+		engines = append(engines, tc.GetFirstStoreFromServer(t, i).Engine())
+	}
+	tc.Stopper().Stop(ctx)
+
+	// Only the first node survives. The scratch range lost quorum, while all
+	// other ranges only ever had a replica on the first node.
+	survivor := engines[0]
+	info, err := loqrecovery.CollectReplicaInfo(ctx, []storage.Engine{survivor})
+	require.NoError(t, err)
+
+	plan, report, err := loqrecovery.PlanReplicas(
+		ctx, []loqrecovery.NodeReplicaInfo{info}, nil /* deadStoreIDs */)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+	require.Equal(t, []roachpb.StoreID{1}, report.PresentStores)
+	require.Equal(t, []roachpb.StoreID{2, 3}, report.MissingStores)
+	require.Len(t, plan.Updates, 1)
+	update := plan.Updates[0]
+	require.Equal(t, scratchDesc.RangeID, update.RangeID)
+	require.Equal(t, roachpb.StoreID(1), update.NewReplica.StoreID)
+	require.Equal(t, scratchDesc.NextReplicaID, update.NewReplica.ReplicaID)
+
+	clock := hlc.NewClock(hlc.UnixNano, 0)
+	batch := survivor.NewBatch()
+	defer batch.Close()
+	prepReport, err := loqrecovery.PrepareUpdateReplicas(ctx, plan, clock,
+		map[roachpb.StoreID]storage.ReadWriter{1: batch})
+	require.NoError(t, err)
+	require.Len(t, prepReport.UpdatedReplicas, 1)
+	require.NoError(t, batch.Commit(true /* sync */))
+
+	// The scratch range now has the surviving replica as its only member.
+	info, err = loqrecovery.CollectReplicaInfo(ctx, []storage.Engine{survivor})
+	require.NoError(t, err)
+	var found bool
+	for _, r := range info.Replicas {
+		if r.Desc.RangeID != scratchDesc.RangeID {
+			continue
+		}
+		found = true
+		require.Equal(t, []roachpb.ReplicaDescriptor{update.NewReplica}, r.Desc.Replicas().All())
+		require.Equal(t, update.NextReplicaID, r.Desc.NextReplicaID)
+	}
+	require.True(t, found, "r%d not found on the surviving store", scratchDesc.RangeID)
+
+	// A second run of the same plan doesn't change anything.
+	_, report, err = loqrecovery.PlanReplicas(
+		ctx, []loqrecovery.NodeReplicaInfo{info}, nil /* deadStoreIDs */)
+	require.NoError(t, err)
+	require.Empty(t, report.MissingStores)
+	batch2 := survivor.NewBatch()
+	defer batch2.Close()
+	prepReport, err = loqrecovery.PrepareUpdateReplicas(ctx, plan, clock,
+		map[roachpb.StoreID]storage.ReadWriter{1: batch2})
+	require.NoError(t, err)
+	require.Empty(t, prepReport.UpdatedReplicas)
+	require.Len(t, prepReport.SkippedReplicas, 1)
+
+	// Restart the surviving node on its repaired store. The scratch range
+	// regains quorum with the surviving replica as its only voter, so it
+	// elects a leader and serves reads and writes again.
+	tc = testcluster.StartTestCluster(t, 1, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+		ServerArgs:      clusterArgs.ServerArgsPerNode[0],
+	})
+	defer tc.Stopper().Stop(ctx)
+	store := tc.GetFirstStoreFromServer(t, 0)
+	testutils.SucceedsSoon(t, func() error {
+		repl := store.LookupReplica(roachpb.RKey(scratchKey))
+		if repl == nil {
+			return errors.Errorf("r%d not found", scratchDesc.RangeID)
+		}
+		if status := repl.RaftStatus(); status == nil || status.Lead != uint64(update.NewReplica.ReplicaID) {
+			return errors.Errorf("r%d has not elected the surviving replica as leader", scratchDesc.RangeID)
+		}
+		return nil
+	})
+	db := tc.Server(0).DB()
+	kv, err := db.Get(ctx, scratchKey)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), kv.ValueBytes())
+	require.NoError(t, db.Put(ctx, scratchKey, "new value"))
+	kv, err = db.Get(ctx, scratchKey)
+	require.NoError(t, err)
+	require.Equal(t, []byte("new value"), kv.ValueBytes())
+}
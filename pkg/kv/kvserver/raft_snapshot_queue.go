@@ -113,8 +113,9 @@ func (rq *raftSnapshotQueue) processRaftSnapshot(
 	// A learner replica is either getting a snapshot of type LEARNER by the node
 	// that's adding it or it's been orphaned and it's about to be cleaned up by
 	// the replicate queue. Either way, no point in also sending it a snapshot of
-	// type RAFT.
-	if repDesc.GetType() == roachpb.LEARNER {
+	// type RAFT. Non-voters receive the same kind of snapshot when they are
+	// added.
+	if typ := repDesc.GetType(); typ == roachpb.LEARNER || typ == roachpb.NON_VOTER {
 		if fn := repl.store.cfg.TestingKnobs.ReplicaSkipLearnerSnapshot; fn != nil && fn() {
 			return nil
 		}
@@ -46,6 +46,7 @@ func MakeAllKeyRanges(d *roachpb.RangeDescriptor) []KeyRange {
 	return []KeyRange{
 		MakeRangeIDLocalKeyRange(d.RangeID, false /* replicatedOnly */),
 		MakeRangeLocalKeyRange(d),
+		MakeLockTableKeyRange(d),
 		MakeUserKeyRange(d),
 	}
 }
@@ -58,11 +59,13 @@ func MakeAllKeyRanges(d *roachpb.RangeDescriptor) []KeyRange {
 //
 // 1. Replicated range-id local key range
 // 2. Range-local key range
-// 3. User key range
+// 3. Lock table key range
+// 4. User key range
 func MakeReplicatedKeyRanges(d *roachpb.RangeDescriptor) []KeyRange {
 	return []KeyRange{
 		MakeRangeIDLocalKeyRange(d.RangeID, true /* replicatedOnly */),
 		MakeRangeLocalKeyRange(d),
+		MakeLockTableKeyRange(d),
 		MakeUserKeyRange(d),
 	}
 }
@@ -95,6 +98,17 @@ func MakeRangeLocalKeyRange(d *roachpb.RangeDescriptor) KeyRange {
 	}
 }
 
+// MakeLockTableKeyRange returns the lock table key range. Like range-local
+// keys, lock table keys are replicated keys that sort outside of the range
+// that they belong to: the replicated lock on a key belongs to the range
+// containing that key.
+func MakeLockTableKeyRange(d *roachpb.RangeDescriptor) KeyRange {
+	return KeyRange{
+		Start: storage.MakeMVCCMetadataKey(keys.LockTableSingleKey(d.StartKey.AsRawKey())),
+		End:   storage.MakeMVCCMetadataKey(keys.LockTableSingleKey(d.EndKey.AsRawKey())),
+	}
+}
+
 // MakeUserKeyRange returns the user key range.
 func MakeUserKeyRange(d *roachpb.RangeDescriptor) KeyRange {
 	// The first range in the keyspace starts at KeyMin, which includes the
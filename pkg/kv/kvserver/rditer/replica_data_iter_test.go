@@ -93,6 +93,8 @@ func createRangeData(
 		{keys.TransactionKey(roachpb.Key(desc.StartKey), uuid.MakeV4()), ts0},
 		{keys.TransactionKey(roachpb.Key(desc.StartKey.Next()), uuid.MakeV4()), ts0},
 		{keys.TransactionKey(fakePrevKey(desc.EndKey), uuid.MakeV4()), ts0},
+		{keys.LockTableSingleKey(roachpb.Key(desc.StartKey)), ts0},
+		{keys.LockTableSingleKey(fakePrevKey(desc.EndKey)), ts0},
 		// TODO(bdarnell): KeyMin.Next() results in a key in the reserved system-local space.
 		// Once we have resolved https://github.com/cockroachdb/cockroach/issues/437,
 		// replace this with something that reliably generates the first valid key in the range.
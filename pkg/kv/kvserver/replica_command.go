@@ -1002,6 +1002,23 @@ func (r *Replica) changeReplicasImpl(
 		return nil, err
 	}
 
+	if len(chgs.NonVoterAdditions())+len(chgs.NonVoterRemovals()) > 0 {
+		if !r.ClusterSettings().Version.IsActive(ctx, clusterversion.VersionNonVotingReplicas) {
+			return nil, errors.Errorf("non-voting replicas require cluster version %s",
+				clusterversion.VersionNonVotingReplicas)
+		}
+		desc, err = r.changeNonVoters(ctx, desc, priority, reason, details, chgs)
+		if err != nil {
+			return nil, err
+		}
+		if len(chgs.Additions())+len(chgs.Removals()) == 0 {
+			// There are no voter changes left to carry out. Note that calling
+			// atomicReplicationChange with no changes would instead attempt to
+			// leave a joint configuration.
+			return desc, nil
+		}
+	}
+
 	if adds := chgs.Additions(); len(adds) > 0 {
 		// Lock learner snapshots even before we run the ConfChange txn to add them
 		// to prevent a race with the raft snapshot queue trying to send it first.
@@ -1053,6 +1070,62 @@ func (r *Replica) changeReplicasImpl(
 	return desc, err
 }
 
+// changeNonVoters adds and removes the non-voting replicas specified in the
+// given replication changes. Each non-voter is added through its own simple
+// configuration change and caught up via a snapshot; removals are carried out
+// outright since non-voters don't count towards quorum. Voter changes in chgs
+// are ignored.
+func (r *Replica) changeNonVoters(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	priority SnapshotRequest_Priority,
+	reason kvserverpb.RangeLogEventReason,
+	details string,
+	chgs roachpb.ReplicationChanges,
+) (*roachpb.RangeDescriptor, error) {
+	if adds := chgs.NonVoterAdditions(); len(adds) > 0 {
+		// As with learners, prevent the raft snapshot queue from racing with the
+		// snapshots we're about to send.
+		releaseSnapshotLockFn := r.lockLearnerSnapshot(ctx, adds)
+		defer releaseSnapshotLockFn()
+
+		for _, target := range adds {
+			var err error
+			desc, err = execChangeReplicasTxn(
+				ctx, r.store, desc, reason, details,
+				[]internalReplicationChange{{target: target, typ: internalChangeTypeAddNonVoter}},
+			)
+			if err != nil {
+				return nil, err
+			}
+			rDesc, ok := desc.GetReplicaDescriptor(target.StoreID)
+			if !ok {
+				return nil, errors.Errorf("programming error: replica %v not found in %v", target, desc)
+			}
+			if fn := r.store.cfg.TestingKnobs.ReplicaSkipLearnerSnapshot; fn != nil && fn() {
+				continue
+			}
+			// If the snapshot fails, the non-voter is left in place. It doesn't
+			// affect quorum, and the raft snapshot queue will catch it up later.
+			if err := r.sendSnapshot(ctx, rDesc, SnapshotRequest_LEARNER, priority); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, target := range chgs.NonVoterRemovals() {
+		var err error
+		desc, err = execChangeReplicasTxn(
+			ctx, r.store, desc, reason, details,
+			[]internalReplicationChange{{target: target, typ: internalChangeTypeRemove}},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return desc, nil
+}
+
 // maybeLeaveAtomicChangeReplicas transitions out of the joint configuration if
 // the descriptor indicates one. This involves running a distributed transaction
 // updating said descriptor, the result of which will be returned. The
@@ -1138,7 +1211,7 @@ func validateReplicationChanges(
 					return fmt.Errorf("changes %+v refer to n%d twice for change %v",
 						chgs, chg.Target.NodeID, chg.ChangeType)
 				}
-				if prevChg.ChangeType != roachpb.ADD_REPLICA {
+				if !isReplicaAddition(prevChg.ChangeType) {
 					return fmt.Errorf("can only add-remove a replica within a node, but got %+v", chgs)
 				}
 			}
@@ -1163,7 +1236,7 @@ func validateReplicationChanges(
 			chg, k := byStoreID[rDesc.StoreID]
 			// We should be removing the replica from the existing store during a
 			// rebalance within the node.
-			if !k || chg.ChangeType != roachpb.REMOVE_REPLICA {
+			if !k || !isReplicaRemoval(chg.ChangeType) {
 				return errors.Errorf(
 					"Expected replica to be removed from %v during a lateral rebalance %v within the node.", rDesc, chgs)
 			}
@@ -1175,7 +1248,12 @@ func validateReplicationChanges(
 		// (2) add on the node, when we only have one replica.
 		// See https://github.com/cockroachdb/cockroach/issues/40333.
 		if ok {
-			if chg.ChangeType == roachpb.REMOVE_REPLICA {
+			if isReplicaRemoval(chg.ChangeType) {
+				// Non-voters must be removed as such, and voters must not be.
+				if isNonVoter := rDesc.GetType() == roachpb.NON_VOTER; isNonVoter != (chg.ChangeType == roachpb.REMOVE_NON_VOTER) {
+					return errors.Errorf("unable to %s %v which is a %s in %s",
+						chg.ChangeType, chg.Target, rDesc.GetType(), desc)
+				}
 				continue
 			}
 			// Looks like we found a replica with the same store and node id. If the
@@ -1187,6 +1265,10 @@ func validateReplicationChanges(
 				return errors.Errorf(
 					"unable to add replica %v which is already present as a learner in %s", chg.Target, desc)
 			}
+			if rDesc.GetType() == roachpb.NON_VOTER {
+				return errors.Errorf(
+					"unable to add replica %v which is already present as a non-voter in %s", chg.Target, desc)
+			}
 
 			// Otherwise, we already had a full voter replica. Can't add another to
 			// this store.
@@ -1196,7 +1278,7 @@ func validateReplicationChanges(
 		for _, c := range byStoreID {
 			// We're adding a replica that's already there. This isn't allowed, even
 			// when the newly added one would be on a different store.
-			if c.ChangeType == roachpb.ADD_REPLICA {
+			if isReplicaAddition(c.ChangeType) {
 				if len(desc.Replicas().All()) > 1 {
 					return errors.Errorf("unable to add replica %v; node already has a replica in %s", c.Target.StoreID, desc)
 				}
@@ -1209,7 +1291,7 @@ func validateReplicationChanges(
 	// Any removals left in the map now refer to nonexisting replicas, and we refuse them.
 	for _, byStoreID := range byNodeAndStoreID {
 		for _, chg := range byStoreID {
-			if chg.ChangeType != roachpb.REMOVE_REPLICA {
+			if !isReplicaRemoval(chg.ChangeType) {
 				continue
 			}
 			return errors.Errorf("removing %v which is not in %s", chg.Target, desc)
@@ -1218,6 +1300,18 @@ func validateReplicationChanges(
 	return nil
 }
 
+// isReplicaAddition returns whether the given change type adds a replica,
+// voting or not.
+func isReplicaAddition(typ roachpb.ReplicaChangeType) bool {
+	return typ == roachpb.ADD_REPLICA || typ == roachpb.ADD_NON_VOTER
+}
+
+// isReplicaRemoval returns whether the given change type removes a replica,
+// voting or not.
+func isReplicaRemoval(typ roachpb.ReplicaChangeType) bool {
+	return typ == roachpb.REMOVE_REPLICA || typ == roachpb.REMOVE_NON_VOTER
+}
+
 // addLearnerReplicas adds learners to the given replication targets.
 func addLearnerReplicas(
 	ctx context.Context,
@@ -1437,6 +1531,9 @@ const (
 	// voter with them), see:
 	// https://github.com/cockroachdb/cockroach/pull/40268
 	internalChangeTypeRemove
+	// internalChangeTypeAddNonVoter adds a non-voting replica. Unlike a learner,
+	// the non-voter is not promoted afterwards and remains part of the range.
+	internalChangeTypeAddNonVoter
 )
 
 // internalReplicationChange is a replication target together with an internal
@@ -1487,6 +1584,9 @@ func prepareChangeReplicasTrigger(
 			case internalChangeTypeAddLearner:
 				added = append(added,
 					updatedDesc.AddReplica(chg.target.NodeID, chg.target.StoreID, roachpb.LEARNER))
+			case internalChangeTypeAddNonVoter:
+				added = append(added,
+					updatedDesc.AddReplica(chg.target.NodeID, chg.target.StoreID, roachpb.NON_VOTER))
 			case internalChangeTypePromoteLearner:
 				typ := roachpb.VOTER_FULL
 				if useJoint {
@@ -1503,7 +1603,7 @@ func prepareChangeReplicasTrigger(
 					return nil, errors.Errorf("target %s not found", chg.target)
 				}
 				prevTyp := rDesc.GetType()
-				if !useJoint || prevTyp == roachpb.LEARNER {
+				if !useJoint || prevTyp == roachpb.LEARNER || prevTyp == roachpb.NON_VOTER {
 					rDesc, _ = updatedDesc.RemoveReplica(chg.target.NodeID, chg.target.StoreID)
 				} else if prevTyp != roachpb.VOTER_FULL {
 					// NB: prevTyp is already known to be VOTER_FULL because of
@@ -229,4 +229,34 @@ func TestValidateReplicationChanges(t *testing.T) {
 		{ChangeType: roachpb.ADD_REPLICA, Target: roachpb.ReplicationTarget{NodeID: 1, StoreID: 2}},
 	})
 	require.NoError(t, err)
+
+	// Test Case 18: Add and remove non-voters.
+	nonVoterType := roachpb.NON_VOTER
+	descNonVoter := &roachpb.RangeDescriptor{
+		InternalReplicas: []roachpb.ReplicaDescriptor{
+			{NodeID: 1, StoreID: 1},
+			{NodeID: 2, StoreID: 2, Type: &nonVoterType},
+		},
+	}
+	err = validateReplicationChanges(descNonVoter, roachpb.ReplicationChanges{
+		{ChangeType: roachpb.ADD_NON_VOTER, Target: roachpb.ReplicationTarget{NodeID: 3, StoreID: 3}},
+		{ChangeType: roachpb.REMOVE_NON_VOTER, Target: roachpb.ReplicationTarget{NodeID: 2, StoreID: 2}},
+	})
+	require.NoError(t, err)
+
+	// Test Case 19: Try to add where there is already a non-voter.
+	err = validateReplicationChanges(descNonVoter, roachpb.ReplicationChanges{
+		{ChangeType: roachpb.ADD_REPLICA, Target: roachpb.ReplicationTarget{NodeID: 2, StoreID: 2}},
+	})
+	require.Regexp(t, "already present as a non-voter", err)
+
+	// Test Case 20: Non-voters and voters must be removed as such.
+	err = validateReplicationChanges(descNonVoter, roachpb.ReplicationChanges{
+		{ChangeType: roachpb.REMOVE_REPLICA, Target: roachpb.ReplicationTarget{NodeID: 2, StoreID: 2}},
+	})
+	require.Regexp(t, "unable to REMOVE_REPLICA n2,s2 which is a NON_VOTER", err)
+	err = validateReplicationChanges(descNonVoter, roachpb.ReplicationChanges{
+		{ChangeType: roachpb.REMOVE_NON_VOTER, Target: roachpb.ReplicationTarget{NodeID: 1, StoreID: 1}},
+	})
+	require.Regexp(t, "unable to REMOVE_NON_VOTER n1,s1 which is a VOTER_FULL", err)
 }
@@ -462,7 +462,10 @@ func evaluateCommand(
 		}
 
 		if cmd.EvalRW != nil {
-			pd, err = cmd.EvalRW(ctx, readWriter, cArgs, reply)
+			err = batcheval.CheckIntentWriteForConflictingLocks(ctx, readWriter, cArgs)
+			if err == nil {
+				pd, err = cmd.EvalRW(ctx, readWriter, cArgs, reply)
+			}
 		} else {
 			pd, err = cmd.EvalRO(ctx, readWriter, cArgs, reply)
 		}
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/kvserverbase"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -572,6 +573,7 @@ func TestEvaluateBatch(t *testing.T) {
 				eng:   eng,
 			}
 			d.AbortSpan = abortspan.New(1)
+			d.ClusterSettings = cluster.MakeTestingClusterSettings()
 			d.ba.Header.Timestamp = ts
 
 			tc.setup(t, d)
@@ -80,6 +80,7 @@ func newUnloadedReplica(
 			IntentResolver:    store.intentResolver,
 			TxnWaitMetrics:    store.txnWaitMetrics,
 			SlowLatchGauge:    store.metrics.SlowLatchRequests,
+			OnContentionEvent: store.cfg.OnContentionEvent,
 			DisableTxnPushing: store.TestingKnobs().DontPushOnWriteIntentError,
 			TxnWaitKnobs:      store.TestingKnobs().TxnWaitKnobs,
 		}),
@@ -1015,9 +1015,10 @@ func (r *Replica) clearSubsumedReplicaDiskData(
 	subsumedRepls []*Replica,
 	subsumedNextReplicaID roachpb.ReplicaID,
 ) error {
-	getKeyRanges := func(desc *roachpb.RangeDescriptor) [2]rditer.KeyRange {
+	getKeyRanges := func(desc *roachpb.RangeDescriptor) [3]rditer.KeyRange {
 		return [...]rditer.KeyRange{
 			rditer.MakeRangeLocalKeyRange(desc),
+			rditer.MakeLockTableKeyRange(desc),
 			rditer.MakeUserKeyRange(desc),
 		}
 	}
@@ -1066,8 +1067,8 @@ func (r *Replica) clearSubsumedReplicaDiskData(
 		}
 	}
 
-	// We might have to create SSTs for the range local keys and user keys
-	// depending on if the subsumed replicas are not fully contained by the
+	// We might have to create SSTs for the range local keys, lock table keys
+	// and user keys depending on if the subsumed replicas are not fully contained by the
 	// replica in our snapshot. The following is an example to this case
 	// happening.
 	//
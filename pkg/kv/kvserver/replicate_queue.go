@@ -410,6 +410,17 @@ func (rq *replicateQueue) processOneChange(
 		return rq.removeLearner(ctx, repl, dryRun)
 	case AllocatorConsiderRebalance:
 		return rq.considerRebalance(ctx, repl, voterReplicas, canTransferLease, dryRun)
+	case AllocatorAddNonVoter:
+		return rq.addNonVoter(ctx, repl, dryRun)
+	case AllocatorRemoveNonVoter:
+		return rq.removeNonVoter(ctx, repl, dryRun)
+	case AllocatorRemoveDeadNonVoter:
+		_, deadNonVoters := rq.allocator.storePool.liveAndDeadReplicas(desc.Replicas().NonVoters())
+		return rq.removeUnhealthyNonVoter(ctx, repl, deadNonVoters, kvserverpb.ReasonStoreDead, dryRun)
+	case AllocatorRemoveDecommissioningNonVoter:
+		decommissioningNonVoters := rq.allocator.storePool.decommissioningReplicas(desc.Replicas().NonVoters())
+		return rq.removeUnhealthyNonVoter(
+			ctx, repl, decommissioningNonVoters, kvserverpb.ReasonStoreDecommissioning, dryRun)
 	case AllocatorFinalizeAtomicReplicationChange:
 		_, err := maybeLeaveAtomicChangeReplicasAndRemoveLearners(ctx, repl.store, repl.Desc())
 		// Requeue because either we failed to transition out of a joint state
@@ -476,10 +487,14 @@ func (rq *replicateQueue) addOrReplace(
 	// there is a reason we're removing it (i.e. dead or decommissioning). If we
 	// left the replica in the slice, the allocator would not be guaranteed to
 	// pick a replica that fills the gap removeRepl leaves once it's gone.
+	//
+	// Stores holding a non-voter are passed along as well so that the new voter
+	// isn't placed on one of them.
+	nonVoterReplicas := desc.Replicas().NonVoters()
 	newStore, details, err := rq.allocator.AllocateTarget(
 		ctx,
 		zone,
-		remainingLiveReplicas,
+		append(remainingLiveReplicas[:len(remainingLiveReplicas):len(remainingLiveReplicas)], nonVoterReplicas...),
 	)
 	if err != nil {
 		return false, err
@@ -493,7 +508,7 @@ func (rq *replicateQueue) addOrReplace(
 	}
 
 	clusterNodes := rq.allocator.storePool.ClusterNodeCount()
-	need := GetNeededReplicas(*zone.NumReplicas, clusterNodes)
+	need := GetNeededReplicas(zone.GetNumVoters(), clusterNodes)
 
 	// Only up-replicate if there are suitable allocation targets such that,
 	// either the replication goal is met, or it is possible to get to the next
@@ -512,6 +527,7 @@ func (rq *replicateQueue) addOrReplace(
 		// This means we are going to up-replicate to an even replica state.
 		// Check if it is possible to go to an odd replica state beyond it.
 		oldPlusNewReplicas := append([]roachpb.ReplicaDescriptor(nil), existingReplicas...)
+		oldPlusNewReplicas = append(oldPlusNewReplicas, nonVoterReplicas...)
 		oldPlusNewReplicas = append(oldPlusNewReplicas, roachpb.ReplicaDescriptor{
 			NodeID:  newStore.Node.NodeID,
 			StoreID: newStore.StoreID,
@@ -715,7 +731,7 @@ func (rq *replicateQueue) removeDecommissioning(
 	ctx context.Context, repl *Replica, dryRun bool,
 ) (requeue bool, _ error) {
 	desc, _ := repl.DescAndZone()
-	decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(desc.Replicas().All())
+	decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(desc.Replicas().Voters())
 	if len(decommissioningReplicas) == 0 {
 		log.VEventf(ctx, 1, "range of replica %s was identified as having decommissioning replicas, "+
 			"but no decommissioning replicas were found", repl)
@@ -819,6 +835,123 @@ func (rq *replicateQueue) removeLearner(
 	return true, nil
 }
 
+// addNonVoter adds a non-voting replica to the range on a store that doesn't
+// already hold a replica of it.
+func (rq *replicateQueue) addNonVoter(
+	ctx context.Context, repl *Replica, dryRun bool,
+) (requeue bool, _ error) {
+	desc, zone := repl.DescAndZone()
+	existingReplicas := desc.Replicas().All()
+	newStore, details, err := rq.allocator.AllocateTarget(ctx, zone, existingReplicas)
+	if err != nil {
+		return false, err
+	}
+	rq.metrics.AddReplicaCount.Inc(1)
+	target := roachpb.ReplicationTarget{
+		NodeID:  newStore.Node.NodeID,
+		StoreID: newStore.StoreID,
+	}
+	log.VEventf(ctx, 1, "adding non-voter %+v: %s",
+		target, rangeRaftProgress(repl.RaftStatus(), existingReplicas))
+	if err := rq.changeReplicas(
+		ctx,
+		repl,
+		roachpb.MakeReplicationChanges(roachpb.ADD_NON_VOTER, target),
+		desc,
+		SnapshotRequest_RECOVERY,
+		kvserverpb.ReasonRangeUnderReplicated,
+		details,
+		dryRun,
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeNonVoter removes a non-voting replica from a range that has more of
+// them than its zone config asks for.
+func (rq *replicateQueue) removeNonVoter(
+	ctx context.Context, repl *Replica, dryRun bool,
+) (requeue bool, _ error) {
+	desc, zone := repl.DescAndZone()
+	nonVoterReplicas := desc.Replicas().NonVoters()
+	if len(nonVoterReplicas) == 0 {
+		log.VEventf(ctx, 1, "range of replica %s was identified as having too many non-voters, "+
+			"but no non-voters were found", repl)
+		return true, nil
+	}
+	removeReplica, details, err := rq.allocator.RemoveTarget(
+		ctx, zone, nonVoterReplicas, desc.Replicas().All())
+	if err != nil {
+		return false, err
+	}
+	// NB: we don't check whether to transfer the lease away because non-voters
+	// can't hold the lease.
+	rq.metrics.RemoveReplicaCount.Inc(1)
+	log.VEventf(ctx, 1, "removing non-voter %+v due to over-replication: %s",
+		removeReplica, rangeRaftProgress(repl.RaftStatus(), desc.Replicas().All()))
+	target := roachpb.ReplicationTarget{
+		NodeID:  removeReplica.NodeID,
+		StoreID: removeReplica.StoreID,
+	}
+	if err := rq.changeReplicas(
+		ctx,
+		repl,
+		roachpb.MakeReplicationChanges(roachpb.REMOVE_NON_VOTER, target),
+		desc,
+		SnapshotRequest_UNKNOWN, // unused
+		kvserverpb.ReasonRangeOverReplicated,
+		details,
+		dryRun,
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeUnhealthyNonVoter removes the first of the given dead or
+// decommissioning non-voting replicas. Unlike for voters, there's no need to
+// add a replacement first since non-voters don't count towards quorum; the
+// replacement is added on a subsequent pass.
+func (rq *replicateQueue) removeUnhealthyNonVoter(
+	ctx context.Context,
+	repl *Replica,
+	unhealthyNonVoters []roachpb.ReplicaDescriptor,
+	reason kvserverpb.RangeLogEventReason,
+	dryRun bool,
+) (requeue bool, _ error) {
+	desc := repl.Desc()
+	if len(unhealthyNonVoters) == 0 {
+		log.VEventf(ctx, 1, "range of replica %s was identified as having unhealthy non-voters (%s), "+
+			"but none were found", repl, reason)
+		return true, nil
+	}
+	removeReplica := unhealthyNonVoters[0]
+	if reason == kvserverpb.ReasonStoreDead {
+		rq.metrics.RemoveDeadReplicaCount.Inc(1)
+	} else {
+		rq.metrics.RemoveReplicaCount.Inc(1)
+	}
+	log.VEventf(ctx, 1, "removing non-voter %+v from store (%s)", removeReplica, reason)
+	target := roachpb.ReplicationTarget{
+		NodeID:  removeReplica.NodeID,
+		StoreID: removeReplica.StoreID,
+	}
+	if err := rq.changeReplicas(
+		ctx,
+		repl,
+		roachpb.MakeReplicationChanges(roachpb.REMOVE_NON_VOTER, target),
+		desc,
+		SnapshotRequest_UNKNOWN, // unused
+		reason,
+		"",
+		dryRun,
+	); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (rq *replicateQueue) considerRebalance(
 	ctx context.Context,
 	repl *Replica,
@@ -836,6 +969,11 @@ func (rq *replicateQueue) considerRebalance(
 			storeFilterThrottled)
 		if !ok {
 			log.VEventf(ctx, 1, "no suitable rebalance target")
+		} else if rDesc, ok := desc.GetReplicaDescriptor(addTarget.StoreID); ok && rDesc.GetType() == roachpb.NON_VOTER {
+			// The rebalance target already holds a non-voting replica of this
+			// range. Rebalancing only considers voters, so we can't place a voter
+			// there.
+			log.VEventf(ctx, 1, "rebalance target s%d already holds a non-voter", addTarget.StoreID)
 		} else if done, err := rq.maybeTransferLeaseAway(ctx, repl, removeTarget.StoreID, dryRun); err != nil {
 			log.VEventf(ctx, 1, "want to remove self, but failed to transfer lease away: %s", err)
 		} else if done {
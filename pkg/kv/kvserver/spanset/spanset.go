@@ -11,6 +11,7 @@
 package spanset
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"runtime/debug"
@@ -295,6 +296,13 @@ func (s *SpanSet) CheckAllowedAt(
 func (s *SpanSet) checkAllowed(
 	access SpanAccess, span roachpb.Span, check func(SpanAccess, Span) bool,
 ) error {
+	// Commands do not declare the lock table keys that they access. Instead,
+	// accesses to the lock table are checked against the spans of the user keys
+	// whose locks are being accessed.
+	if userSpan, ok := lockTableSpanToUserSpan(span); ok {
+		span = userSpan
+	}
+
 	scope := SpanGlobal
 	if (span.Key != nil && keys.IsLocal(span.Key)) ||
 		(span.EndKey != nil && keys.IsLocal(span.EndKey)) {
@@ -312,6 +320,38 @@ func (s *SpanSet) checkAllowed(
 	return errors.Errorf("cannot %s undeclared span %s\ndeclared:\n%s\nstack:\n%s", access, span, s, debug.Stack())
 }
 
+// lockTableSpanToUserSpan translates a span of lock table keys into the span
+// of user keys whose locks it covers. Returns false if the span does not
+// address the lock table.
+func lockTableSpanToUserSpan(span roachpb.Span) (roachpb.Span, bool) {
+	decode := func(k roachpb.Key) (roachpb.Key, bool) {
+		if k == nil || !bytes.HasPrefix(k, keys.LocalRangeLockTablePrefix) {
+			return nil, false
+		}
+		lockedKey, err := keys.DecodeLockTableSingleKey(k)
+		if err != nil {
+			return nil, false
+		}
+		if lockedKey == nil {
+			lockedKey = roachpb.KeyMin
+		}
+		return lockedKey, true
+	}
+	var userSpan roachpb.Span
+	var ok bool
+	if span.Key != nil {
+		if userSpan.Key, ok = decode(span.Key); !ok {
+			return span, false
+		}
+	}
+	if span.EndKey != nil {
+		if userSpan.EndKey, ok = decode(span.EndKey); !ok {
+			return span, false
+		}
+	}
+	return userSpan, ok
+}
+
 // contains returns whether s1 contains s2. Unlike Span.Contains, this function
 // supports spans with a nil start key and a non-nil end key (e.g. "[nil, c)").
 // In this form, s2.Key (inclusive) is considered to be the previous key to
@@ -176,6 +176,48 @@ func TestSpanSetCheckAllowedBoundaries(t *testing.T) {
 	}
 }
 
+// Test that accesses to the lock table are checked against the spans of the
+// user keys whose locks are being accessed.
+func TestSpanSetCheckAllowedLockTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var ss SpanSet
+	ss.AddNonMVCC(SpanReadOnly, roachpb.Span{Key: roachpb.Key("b"), EndKey: roachpb.Key("d")})
+	ss.AddMVCC(SpanReadWrite, roachpb.Span{Key: roachpb.Key("g")}, hlc.Timestamp{WallTime: 2})
+
+	lockKey := keys.LockTableSingleKey
+	allowed := []struct {
+		access SpanAccess
+		span   roachpb.Span
+	}{
+		{SpanReadOnly, roachpb.Span{Key: lockKey(roachpb.Key("b")), EndKey: lockKey(roachpb.Key("d"))}},
+		{SpanReadOnly, roachpb.Span{Key: lockKey(roachpb.Key("c"))}},
+		{SpanReadOnly, roachpb.Span{EndKey: lockKey(roachpb.Key("d"))}},
+		{SpanReadOnly, roachpb.Span{Key: lockKey(roachpb.Key("g"))}},
+		{SpanReadWrite, roachpb.Span{Key: lockKey(roachpb.Key("g"))}},
+	}
+	for _, tc := range allowed {
+		if err := ss.CheckAllowed(tc.access, tc.span); err != nil {
+			t.Errorf("expected %s access to %s to be allowed, but got error: %+v", tc.access, tc.span, err)
+		}
+	}
+
+	disallowed := []struct {
+		access SpanAccess
+		span   roachpb.Span
+	}{
+		{SpanReadOnly, roachpb.Span{Key: lockKey(roachpb.Key("a"))}},
+		{SpanReadOnly, roachpb.Span{Key: lockKey(roachpb.Key("b")), EndKey: lockKey(roachpb.Key("e"))}},
+		{SpanReadWrite, roachpb.Span{Key: lockKey(roachpb.Key("c"))}},
+		{SpanReadOnly, roachpb.Span{Key: lockKey(roachpb.Key("h"))}},
+	}
+	for _, tc := range disallowed {
+		if err := ss.CheckAllowed(tc.access, tc.span); err == nil {
+			t.Errorf("expected %s access to %s to be disallowed", tc.access, tc.span)
+		}
+	}
+}
+
 // Test that CheckAllowedAt properly enforces timestamp control.
 func TestSpanSetCheckAllowedAtTimestamps(t *testing.T) {
 	defer leaktest.AfterTest(t)()
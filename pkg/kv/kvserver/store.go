@@ -732,6 +732,11 @@ type StoreConfig struct {
 	// subsystem. It is queried during the GC process and in the handling of
 	// AdminVerifyProtectedTimestampRequest.
 	ProtectedTimestampCache protectedts.Cache
+
+	// OnContentionEvent, if set, is invoked whenever a request finishes waiting
+	// on a lock held by another transaction. It is used to aggregate contention
+	// information on the node.
+	OnContentionEvent func(*roachpb.ContentionEvent)
 }
 
 // ConsistencyTestingKnobs is a BatchEvalTestingKnobs struct used to control the
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/concurrency/lock"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
 )
 
 // UserPriority is a custom type for transaction's user priority.
@@ -1227,21 +1228,48 @@ func (*ClearRangeRequest) flags() int { return isWrite | isRange | isAlone }
 func (*RevertRangeRequest) flags() int { return isWrite | isRange }
 
 func (sr *ScanRequest) flags() int {
-	maybeLocking := 0
-	if sr.KeyLocking != lock.None {
-		maybeLocking = isLocking
-	}
+	maybeLocking := flagsForKeyLocking(sr.KeyLocking, sr.KeyLockingReplicated)
 	return isRead | isRange | isTxn | maybeLocking | updatesTSCache | needsRefresh
 }
 
 func (rsr *ReverseScanRequest) flags() int {
-	maybeLocking := 0
-	if rsr.KeyLocking != lock.None {
-		maybeLocking = isLocking
-	}
+	maybeLocking := flagsForKeyLocking(rsr.KeyLocking, rsr.KeyLockingReplicated)
 	return isRead | isRange | isReverse | isTxn | maybeLocking | updatesTSCache | needsRefresh
 }
 
+// flagsForKeyLocking returns the flags of a read request that acquires locks
+// of the specified strength on the keys that it reads. Acquiring replicated
+// locks writes to the range's lock table keyspace, so such requests must be
+// evaluated as writes and go through consensus.
+func flagsForKeyLocking(str lock.Strength, replicated bool) int {
+	if str == lock.None {
+		return 0
+	}
+	if replicated {
+		return isLocking | isWrite
+	}
+	return isLocking
+}
+
+// KeyLockingDurability returns the durability of the locks acquired by the
+// scan, if any.
+func (sr *ScanRequest) KeyLockingDurability() lock.Durability {
+	return keyLockingDurability(sr.KeyLockingReplicated)
+}
+
+// KeyLockingDurability returns the durability of the locks acquired by the
+// reverse scan, if any.
+func (rsr *ReverseScanRequest) KeyLockingDurability() lock.Durability {
+	return keyLockingDurability(rsr.KeyLockingReplicated)
+}
+
+func keyLockingDurability(replicated bool) lock.Durability {
+	if replicated {
+		return lock.Replicated
+	}
+	return lock.Unreplicated
+}
+
 // EndTxn updates the timestamp cache to prevent replays.
 // Replays for the same transaction key and timestamp will have
 // Txn.WriteTooOld=true and must retry on EndTxn.
@@ -1428,6 +1456,18 @@ func (rc ReplicationChanges) Removals() []ReplicationTarget {
 	return rc.byType(REMOVE_REPLICA)
 }
 
+// NonVoterAdditions returns a slice of all contained replication changes that
+// add non-voting replicas.
+func (rc ReplicationChanges) NonVoterAdditions() []ReplicationTarget {
+	return rc.byType(ADD_NON_VOTER)
+}
+
+// NonVoterRemovals returns a slice of all contained replication changes that
+// remove non-voting replicas.
+func (rc ReplicationChanges) NonVoterRemovals() []ReplicationTarget {
+	return rc.byType(REMOVE_NON_VOTER)
+}
+
 // Changes returns the changes requested by this AdminChangeReplicasRequest, taking
 // the deprecated method of doing so into account.
 func (acrr *AdminChangeReplicasRequest) Changes() []ReplicationChange {
@@ -1466,3 +1506,14 @@ func (rirr *ResolveIntentRangeRequest) AsLockUpdate() LockUpdate {
 		IgnoredSeqNums: rirr.IgnoredSeqNums,
 	}
 }
+
+var _ redact.SafeFormatter = ContentionEvent{}
+
+func (e ContentionEvent) String() string {
+	return redact.StringWithoutMarkers(e)
+}
+
+// SafeFormat implements the redact.SafeFormatter interface.
+func (e ContentionEvent) SafeFormat(w redact.SafePrinter, _ rune) {
+	w.Printf("conflicted with %s on %s for %.3fs", e.TxnMeta.ID, e.Key, e.Duration.Seconds())
+}
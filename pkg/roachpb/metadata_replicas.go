@@ -52,6 +52,13 @@ func ReplicaTypeLearner() *ReplicaType {
 	return &t
 }
 
+// ReplicaTypeNonVoter returns a NON_VOTER pointer suitable for use in
+// a nullable proto field.
+func ReplicaTypeNonVoter() *ReplicaType {
+	t := NON_VOTER
+	return &t
+}
+
 // ReplicaDescriptors is a set of replicas, usually the nodes/stores on which
 // replicas of a range are stored.
 type ReplicaDescriptors struct {
@@ -108,6 +115,10 @@ func predLearner(rDesc ReplicaDescriptor) bool {
 	return rDesc.GetType() == LEARNER
 }
 
+func predNonVoter(rDesc ReplicaDescriptor) bool {
+	return rDesc.GetType() == NON_VOTER
+}
+
 // Voters returns the current and future voter replicas in the set. This means
 // that during an atomic replication change, only the replicas that will be
 // voters once the change completes will be returned; "outgoing" voters will not
@@ -215,6 +226,15 @@ func (d ReplicaDescriptors) Learners() []ReplicaDescriptor {
 	return d.Filter(predLearner)
 }
 
+// NonVoters returns the non-voting replicas in the set. Unlike learners,
+// non-voters are a persistent part of the range's configuration and are placed
+// by the allocator to serve follower reads. This may allocate, but it also may
+// return the underlying slice as a performance optimization, so it's not safe
+// to modify the returned value.
+func (d ReplicaDescriptors) NonVoters() []ReplicaDescriptor {
+	return d.Filter(predNonVoter)
+}
+
 // Filter returns only the replica descriptors for which the supplied method
 // returns true. The memory returned may be shared with the receiver.
 func (d ReplicaDescriptors) Filter(pred func(rDesc ReplicaDescriptor) bool) []ReplicaDescriptor {
@@ -287,7 +307,7 @@ func (d ReplicaDescriptors) InAtomicReplicationChange() bool {
 		switch rDesc.GetType() {
 		case VOTER_INCOMING, VOTER_OUTGOING, VOTER_DEMOTING:
 			return true
-		case VOTER_FULL, LEARNER:
+		case VOTER_FULL, LEARNER, NON_VOTER:
 		default:
 			panic(fmt.Sprintf("unknown replica type %d", rDesc.GetType()))
 		}
@@ -318,7 +338,7 @@ func (d ReplicaDescriptors) ConfState() raftpb.ConfState {
 		case VOTER_DEMOTING:
 			cs.VotersOutgoing = append(cs.VotersOutgoing, id)
 			cs.LearnersNext = append(cs.LearnersNext, id)
-		case LEARNER:
+		case LEARNER, NON_VOTER:
 			cs.Learners = append(cs.Learners, id)
 		default:
 			panic(fmt.Sprintf("unknown ReplicaType %d", typ))
@@ -43,6 +43,7 @@ var vi = ReplicaTypeVoterIncoming()
 var vo = ReplicaTypeVoterOutgoing()
 var vd = ReplicaTypeVoterDemoting()
 var l = ReplicaTypeLearner()
+var nv = ReplicaTypeNonVoter()
 
 func TestVotersLearnersAll(t *testing.T) {
 
@@ -58,6 +59,8 @@ func TestVotersLearnersAll(t *testing.T) {
 		{rd(vi, 1)},
 		{rd(vo, 1)},
 		{rd(l, 1), rd(vo, 2), rd(vi, 3), rd(vi, 4)},
+		{rd(nv, 1)},
+		{rd(v, 1), rd(nv, 2), rd(l, 3), rd(nv, 4)},
 	}
 	for _, test := range tests {
 		t.Run("", func(t *testing.T) {
@@ -76,10 +79,14 @@ func TestVotersLearnersAll(t *testing.T) {
 				seen[learner] = struct{}{}
 				assert.Equal(t, LEARNER, learner.GetType())
 			}
+			for _, nonVoter := range r.NonVoters() {
+				seen[nonVoter] = struct{}{}
+				assert.Equal(t, NON_VOTER, nonVoter.GetType())
+			}
 
 			all := r.All()
-			// Make sure that VOTER_OUTGOING is the only type that is skipped both
-			// by Learners() and Voters()
+			// Make sure that VOTER_OUTGOING is the only type that is skipped by
+			// Learners(), NonVoters() and Voters()
 			for _, rd := range all {
 				typ := rd.GetType()
 				if _, seen := seen[rd]; !seen {
@@ -198,6 +205,11 @@ func TestReplicaDescriptorsConfState(t *testing.T) {
 			[]ReplicaDescriptor{rd(vo, 1), rd(vd, 2), rd(vi, 3), rd(vi, 4), rd(l, 5)},
 			"Voters:[3 4] VotersOutgoing:[1 2] Learners:[5] LearnersNext:[2] AutoLeave:false",
 		},
+		// Non-voters are learners as far as raft is concerned.
+		{
+			[]ReplicaDescriptor{rd(v, 1), rd(nv, 2), rd(l, 3)},
+			"Voters:[1] VotersOutgoing:[] Learners:[2 3] LearnersNext:[] AutoLeave:false",
+		},
 	}
 
 	for _, test := range tests {
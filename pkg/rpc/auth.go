@@ -38,6 +38,13 @@ func authErrorf(format string, a ...interface{}) error {
 // contains a sufficiently privileged user.
 type kvAuth struct {
 	tenant tenantAuthorizer
+	// sqlPodTenantID is set when the RPC server is the one of a SQL pod of a
+	// secondary tenant, which only accepts the DistSQL RPCs of the other SQL
+	// pods of the same tenant.
+	//
+	// TODO(multitenant): the SQL pods currently need a node certificate to
+	// serve these RPCs.
+	sqlPodTenantID roachpb.TenantID
 }
 
 // kvAuth implements the auth interface.
@@ -53,7 +60,7 @@ func (a kvAuth) unaryInterceptor(
 	}
 	if tenID != (roachpb.TenantID{}) {
 		ctx = contextWithTenant(ctx, tenID)
-		if err := a.tenant.authorize(tenID, info.FullMethod, req); err != nil {
+		if err := a.authorizeTenant(tenID, info.FullMethod, req); err != nil {
 			return nil, err
 		}
 	}
@@ -79,13 +86,37 @@ func (a kvAuth) streamInterceptor(
 					return err
 				}
 				// 'm' is now populated and contains the request from the client.
-				return a.tenant.authorize(tenID, info.FullMethod, m)
+				return a.authorizeTenant(tenID, info.FullMethod, m)
 			},
 		}
 	}
 	return handler(srv, ss)
 }
 
+// authorizeTenant authorizes an RPC sent by the given tenant. KV nodes only
+// allow tenants to access their own resources, whereas the SQL pods of a
+// tenant only allow the other SQL pods of the same tenant to set up DistSQL
+// flows on them.
+func (a kvAuth) authorizeTenant(tenID roachpb.TenantID, fullMethod string, req interface{}) error {
+	if a.sqlPodTenantID != (roachpb.TenantID{}) {
+		if tenID != a.sqlPodTenantID {
+			return authErrorf("requested tenant %s does not match the SQL pod's tenant %s",
+				tenID, a.sqlPodTenantID)
+		}
+		switch fullMethod {
+		case "/cockroach.sql.distsqlrun.DistSQL/SetupFlow",
+			"/cockroach.sql.distsqlrun.DistSQL/FlowStream":
+			return nil
+		case "/cockroach.rpc.Heartbeat/Ping":
+			// The connections between SQL pods are heartbeated like any other.
+			return nil
+		default:
+			return authErrorf("unknown method %q", fullMethod)
+		}
+	}
+	return a.tenant.authorize(tenID, fullMethod, req)
+}
+
 func (a kvAuth) authenticate(ctx context.Context) (roachpb.TenantID, error) {
 	if grpcutil.IsLocalRequestContext(ctx) {
 		// This is an in-process request. Bypass authentication check.
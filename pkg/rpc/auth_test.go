@@ -379,3 +379,41 @@ func TestTenantAuthRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestSQLPodAuthRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	a := kvAuth{sqlPodTenantID: roachpb.MakeTenantID(10)}
+	const method = "/cockroach.sql.distsqlrun.DistSQL/SetupFlow"
+
+	// The SQL pods of a tenant accept the DistSQL RPCs from the same tenant.
+	for _, m := range []string{
+		"/cockroach.sql.distsqlrun.DistSQL/SetupFlow",
+		"/cockroach.sql.distsqlrun.DistSQL/FlowStream",
+		"/cockroach.rpc.Heartbeat/Ping",
+	} {
+		require.NoError(t, a.authorizeTenant(roachpb.MakeTenantID(10), m, nil /* req */))
+	}
+
+	// But no other RPC.
+	for _, m := range []string{
+		"/cockroach.roachpb.Internal/Batch",
+		"/cockroach.blobs.Blob/List",
+		"/cockroach.server.serverpb.Status/ListSessions",
+	} {
+		err := a.authorizeTenant(roachpb.MakeTenantID(10), m, nil /* req */)
+		require.Error(t, err)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.Regexp(t, `unknown method`, err)
+	}
+
+	// Nor from other tenants.
+	err := a.authorizeTenant(roachpb.MakeTenantID(11), method, nil /* req */)
+	require.Error(t, err)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Regexp(t, `requested tenant 11 does not match the SQL pod's tenant 10`, err)
+
+	// KV nodes don't accept the RPC from any tenant.
+	err = kvAuth{}.authorizeTenant(roachpb.MakeTenantID(10), method, nil /* req */)
+	require.Error(t, err)
+	require.Regexp(t, `unknown method`, err)
+}
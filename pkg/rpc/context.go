@@ -184,6 +184,10 @@ func NewServer(ctx *Context, opts ...ServerOption) *grpc.Server {
 
 	if !ctx.Config.Insecure {
 		a := kvAuth{}
+		if ctx.tenID != roachpb.SystemTenantID {
+			// This is the RPC server of a tenant's SQL pod.
+			a.sqlPodTenantID = ctx.tenID
+		}
 
 		unaryInterceptor = append(unaryInterceptor, a.AuthUnary())
 		streamInterceptor = append(streamInterceptor, a.AuthStream())
@@ -1003,8 +1003,16 @@ func TestAdminAPIEvents(t *testing.T) {
 				}
 
 				isSettingChange := e.EventType == string(sql.EventLogSetClusterSetting)
-
-				if e.TargetID == 0 && !isSettingChange {
+				// Some events do not target a specific descriptor or node.
+				isUntargeted := isSettingChange ||
+					e.EventType == string(sql.EventLogJobStateChange) ||
+					e.EventType == string(sql.EventLogCreateRole) ||
+					e.EventType == string(sql.EventLogAlterRole) ||
+					e.EventType == string(sql.EventLogDropRole) ||
+					e.EventType == string(sql.EventLogGrantRole) ||
+					e.EventType == string(sql.EventLogRevokeRole)
+
+				if e.TargetID == 0 && !isUntargeted {
 					t.Errorf("%d: missing/empty TargetID", i)
 				}
 				if e.ReportingID == 0 {
@@ -339,6 +339,14 @@ type SQLConfig struct {
 	//
 	// Only applies when the SQL server is deployed individually.
 	TenantIDCodecOverride roachpb.TenantID
+
+	// TenantServeSQLInstanceRPCs, if set, makes the SQL server accept the RPCs
+	// of the other SQL servers of its tenant on the RPC address of its
+	// BaseConfig, so that DistSQL flows can be distributed across them. It
+	// requires a node certificate in secure mode.
+	//
+	// Only applies when the SQL server is deployed individually.
+	TenantServeSQLInstanceRPCs bool
 }
 
 // MakeSQLConfig returns a SQLConfig with default values.
@@ -0,0 +1,86 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IndexUsageStatistics returns the index usage statistics of the requested
+// node, or of all nodes in the cluster merged into a single view. The
+// statistics are kept in memory, so the reads served by nodes that cannot be
+// reached (or that have restarted) are missing from the result.
+func (s *statusServer) IndexUsageStatistics(
+	ctx context.Context, req *serverpb.IndexUsageStatisticsRequest,
+) (*serverpb.IndexUsageStatisticsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireViewActivityPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	localReq := &serverpb.IndexUsageStatisticsRequest{
+		NodeID: "local",
+	}
+
+	if len(req.NodeID) > 0 {
+		requestedNodeID, local, err := s.parseNodeID(req.NodeID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		if local {
+			return indexUsageStatisticsLocal(s.admin.server.sqlServer.pgServer.SQLServer.GetLocalIndexStatistics()), nil
+		}
+		statusClient, err := s.dialNode(ctx, requestedNodeID)
+		if err != nil {
+			return nil, err
+		}
+		return statusClient.IndexUsageStatistics(ctx, localReq)
+	}
+
+	var response serverpb.IndexUsageStatisticsResponse
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		statusClient := client.(serverpb.StatusClient)
+		return statusClient.IndexUsageStatistics(ctx, localReq)
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		stats := nodeResp.(*serverpb.IndexUsageStatisticsResponse).Statistics
+		response.Statistics = idxusage.MergeSerializedStats(response.Statistics, stats)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		log.Warningf(ctx, "error fetching index usage statistics from n%d: %v", nodeID, err)
+	}
+
+	if err := s.iterateNodes(ctx, "index usage statistics", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// indexUsageStatisticsLocal returns the index usage statistics collected on
+// the local node or SQL pod.
+func indexUsageStatisticsLocal(
+	stats *idxusage.LocalIndexUsageStats,
+) *serverpb.IndexUsageStatisticsResponse {
+	return &serverpb.IndexUsageStatisticsResponse{Statistics: stats.Serialize()}
+}
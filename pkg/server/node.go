@@ -36,9 +36,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/bootstrap"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -163,6 +165,11 @@ type Node struct {
 	initialStart bool // True if this is the first time this node has started.
 	txnMetrics   kvcoord.TxnMetrics
 
+	// kvAdmissionQ admits the evaluation of batches received by the node.
+	kvAdmissionQ *admission.WorkQueue
+	// storeGrantCoords admit writes to the node's stores.
+	storeGrantCoords *admission.StoreGrantCoordinators
+
 	perReplicaServer kvserver.Server
 }
 
@@ -292,6 +299,8 @@ func NewNode(
 	txnMetrics kvcoord.TxnMetrics,
 	execCfg *sql.ExecutorConfig,
 	clusterID *base.ClusterIDContainer,
+	kvAdmissionQ *admission.WorkQueue,
+	storeGrantCoords *admission.StoreGrantCoordinators,
 ) *Node {
 	var eventLogger sql.EventLogger
 	if execCfg != nil {
@@ -306,6 +315,9 @@ func NewNode(
 		txnMetrics:  txnMetrics,
 		eventLogger: eventLogger,
 		clusterID:   clusterID,
+
+		kvAdmissionQ:     kvAdmissionQ,
+		storeGrantCoords: storeGrantCoords,
 	}
 	n.perReplicaServer = kvserver.MakeServer(&n.Descriptor, n.stores)
 	return n
@@ -810,12 +822,21 @@ func (n *Node) recordJoinEvent() {
 		return
 	}
 
-	logEventType := sql.EventLogNodeRestart
-	lastUp := n.lastUp
+	var event eventpb.EventPayload
+	var nodeDetails *eventpb.CommonNodeEventDetails
 	if n.initialStart {
-		logEventType = sql.EventLogNodeJoin
-		lastUp = n.startedAt
+		ev := &eventpb.NodeJoin{}
+		event = ev
+		nodeDetails = &ev.CommonNodeEventDetails
+		nodeDetails.LastUp = n.startedAt
+	} else {
+		ev := &eventpb.NodeRestart{}
+		event = ev
+		nodeDetails = &ev.CommonNodeEventDetails
+		nodeDetails.LastUp = n.lastUp
 	}
+	nodeDetails.StartedAt = n.startedAt
+	nodeDetails.NodeID = int32(n.Descriptor.NodeID)
 
 	n.stopper.RunWorker(context.Background(), func(bgCtx context.Context) {
 		ctx, span := n.AnnotateCtxWithSpan(bgCtx, "record-join-event")
@@ -824,21 +845,16 @@ func (n *Node) recordJoinEvent() {
 		retryOpts.Closer = n.stopper.ShouldStop()
 		for r := retry.Start(retryOpts); r.Next(); {
 			if err := n.storeCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+				nodeDetails.ClusterID = n.clusterID.Get().String()
 				return n.eventLogger.InsertEventRecord(
 					ctx,
 					txn,
-					logEventType,
 					int32(n.Descriptor.NodeID),
 					int32(n.Descriptor.NodeID),
-					struct {
-						Descriptor roachpb.NodeDescriptor
-						ClusterID  uuid.UUID
-						StartedAt  int64
-						LastUp     int64
-					}{n.Descriptor, n.clusterID.Get(), n.startedAt, lastUp},
+					event,
 				)
 			}); err != nil {
-				log.Warningf(ctx, "%s: unable to log %s event: %s", n, logEventType, err)
+				log.Warningf(ctx, "%s: unable to log %s event: %s", n, eventpb.GetEventTypeName(event), err)
 			} else {
 				return
 			}
@@ -879,6 +895,27 @@ func (n *Node) batchInternal(
 			log.Eventf(ctx, "node received request: %s", args.Summary())
 		}
 
+		// Wait for admission before evaluating the batch.
+		admissionInfo := makeAdmissionWorkInfo(args)
+		enabled, err := n.kvAdmissionQ.Admit(ctx, admissionInfo)
+		if err != nil {
+			return err
+		}
+		if enabled {
+			defer n.kvAdmissionQ.AdmittedWorkDone()
+		}
+		if args.IsWrite() {
+			if storeQ := n.storeGrantCoords.TryGetQueueForStore(args.Replica.StoreID); storeQ != nil {
+				enabled, err := storeQ.Admit(ctx, admissionInfo)
+				if err != nil {
+					return err
+				}
+				if enabled {
+					defer storeQ.AdmittedWorkDone()
+				}
+			}
+		}
+
 		tStart := timeutil.Now()
 		var pErr *roachpb.Error
 		br, pErr = n.stores.Send(ctx, *args)
@@ -898,6 +935,58 @@ func (n *Node) batchInternal(
 	return br, nil
 }
 
+var _ admission.StoreMetricsProvider = &Node{}
+
+// GetStoreMetrics implements the admission.StoreMetricsProvider interface.
+func (n *Node) GetStoreMetrics() []admission.StoreMetrics {
+	var metrics []admission.StoreMetrics
+	_ = n.stores.VisitStores(func(s *kvserver.Store) error {
+		stats, err := s.Engine().GetStats()
+		if err != nil {
+			log.Warningf(context.Background(), "failed to get stats of s%d: %v", s.StoreID(), err)
+			return nil
+		}
+		metrics = append(metrics, admission.StoreMetrics{
+			StoreID:         s.StoreID(),
+			L0FileCount:     stats.L0FileCount,
+			L0SublevelCount: stats.L0SublevelCount,
+		})
+		return nil
+	})
+	return metrics
+}
+
+// makeAdmissionWorkInfo returns the information used to admit the given
+// batch.
+func makeAdmissionWorkInfo(ba *roachpb.BatchRequest) admission.WorkInfo {
+	info := admission.WorkInfo{
+		Priority:   admission.WorkPriority(ba.AdmissionHeader.Priority),
+		CreateTime: ba.AdmissionHeader.CreateTime,
+	}
+	// Batches of transactions holding locks bypass admission control, since
+	// they may be blocking other admitted work which waits for these locks.
+	// Making them wait as well could deadlock.
+	if ba.Txn != nil && ba.Txn.IsLocking() {
+		info.BypassAdmission = true
+		return info
+	}
+	if ba.AdmissionHeader.Source == roachpb.AdmissionHeader_OTHER {
+		// Only bulk operations without an admission header are subject to
+		// admission control. Other such requests are typically internal
+		// operations which the node's health depends on.
+		info.BypassAdmission = true
+		for _, ru := range ba.Requests {
+			switch ru.GetInner().(type) {
+			case *roachpb.AddSSTableRequest, *roachpb.ExportRequest, *roachpb.RevertRangeRequest:
+				info.BypassAdmission = false
+				info.Priority = admission.BulkNormalPri
+				info.CreateTime = timeutil.Now().UnixNano()
+			}
+		}
+	}
+	return info
+}
+
 // Batch implements the roachpb.InternalServer interface.
 func (n *Node) Batch(
 	ctx context.Context, args *roachpb.BatchRequest,
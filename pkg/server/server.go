@@ -56,6 +56,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	_ "github.com/cockroachdb/cockroach/pkg/sql/gcjob" // register jobs declared outside of pkg/sql
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
@@ -66,10 +67,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/ui"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/netutil"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -135,6 +138,10 @@ type Server struct {
 	recorder     *status.MetricsRecorder
 	runtime      *status.RuntimeStatSampler
 
+	// grantCoordinator performs admission control for the KV and SQL flow
+	// work of the node.
+	grantCoordinator *admission.GrantCoordinator
+
 	admin          *adminServer
 	status         *statusServer
 	authentication *authenticationServer
@@ -442,6 +449,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	// ClosedTimestamp), but the Node needs a StoreConfig to be made.
 	var lateBoundNode *Node
 
+	contentionRegistry := contention.NewRegistry()
+
 	storeCfg := kvserver.StoreConfig{
 		DefaultZoneConfig:       &cfg.DefaultZoneConfig,
 		Settings:                st,
@@ -488,6 +497,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		ExternalStorage:         externalStorage,
 		ExternalStorageFromURI:  externalStorageFromURI,
 		ProtectedTimestampCache: protectedtsProvider,
+		OnContentionEvent: func(ev *roachpb.ContentionEvent) {
+			contentionRegistry.AddContentionEvent(*ev)
+		},
 	}
 	if storeTestingKnobs := cfg.TestingKnobs.Store; storeTestingKnobs != nil {
 		storeCfg.TestingKnobs = *storeTestingKnobs.(*kvserver.StoreTestingKnobs)
@@ -496,9 +508,19 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	recorder := status.NewMetricsRecorder(clock, nodeLiveness, rpcContext, g, st)
 	registry.AddMetricStruct(rpcContext.RemoteClocks.Metrics())
 
+	gcoord, gcoordMetrics := admission.NewGrantCoordinator(
+		st, admission.MakeDefaultOptions(cfg.HistogramWindowInterval()))
+	for _, m := range gcoordMetrics {
+		registry.AddMetricStruct(m)
+	}
+	storeGrantCoords, storeGrantMetrics := admission.NewStoreGrantCoordinators(
+		st, cfg.HistogramWindowInterval())
+	registry.AddMetricStruct(storeGrantMetrics)
+
 	node := NewNode(
 		storeCfg, recorder, registry, stopper,
-		txnMetrics, nil /* execCfg */, &rpcContext.ClusterID)
+		txnMetrics, nil /* execCfg */, &rpcContext.ClusterID,
+		gcoord.GetWorkQueue(admission.KVWork), storeGrantCoords)
 	lateBoundNode = node
 	roachpb.RegisterInternalServer(grpcServer.Server, node)
 	kvserver.RegisterPerReplicaServer(grpcServer.Server, node.perReplicaServer)
@@ -538,6 +560,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		stopper,
 		sessionRegistry,
 		internalExecutor,
+		contentionRegistry,
 	)
 	// TODO(tbg): don't pass all of Server into this to avoid this hack.
 	sAuth := newAuthenticationServer(lateBoundServer)
@@ -566,6 +589,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 			externalStorage:        externalStorage,
 			externalStorageFromURI: externalStorageFromURI,
 			isMeta1Leaseholder:     node.stores.IsMeta1Leaseholder,
+			sqlFlowAdmissionQ:      gcoord.GetWorkQueue(admission.SQLFlowWork),
 		},
 		SQLConfig:                &cfg.SQLConfig,
 		BaseConfig:               &cfg.BaseConfig,
@@ -612,6 +636,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		registry:               registry,
 		recorder:               recorder,
 		runtime:                runtimeSampler,
+		grantCoordinator:       gcoord,
 		admin:                  sAdmin,
 		status:                 sStatus,
 		authentication:         sAuth,
@@ -1485,6 +1510,9 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	log.Event(ctx, "started node")
+	// Admission control of writes to the stores can start now that the stores
+	// are known.
+	s.node.storeGrantCoords.SetStoreMetricsProvider(ctx, s.stopper, s.node)
 	if err := s.startPersistingHLCUpperBound(
 		ctx,
 		hlcUpperBound > 0,
@@ -1526,6 +1554,7 @@ func (s *Server) Start(ctx context.Context) error {
 		goroutineDumpDirName: s.cfg.GoroutineDumpDirName,
 		heapProfileDirName:   s.cfg.HeapProfileDirName,
 		runtime:              s.runtime,
+		grantCoordinator:     s.grantCoordinator,
 	}); err != nil {
 		return err
 	}
@@ -1622,6 +1651,21 @@ func (s *Server) Start(ctx context.Context) error {
 	// This mirrors the handling of /health above.
 	s.mux.Handle("/_admin/v1/health", gwMux)
 	s.mux.Handle(ts.URLPrefix, authHandler)
+	// The Prometheus-compatible query endpoint serves the same time series
+	// data as the ts endpoints, and is authenticated like them.
+	var promHandler http.Handler = s.tsServer.PrometheusAPIHandler(
+		ts.NewMetricNameResolver(func() []string {
+			data := s.recorder.GetTimeSeriesData()
+			names := make([]string, len(data))
+			for i := range data {
+				names[i] = data[i].Name
+			}
+			return names
+		}))
+	if s.cfg.RequireWebSession() {
+		promHandler = newAuthenticationMux(s.authentication, promHandler)
+	}
+	s.mux.Handle(ts.PromQueryRangePath, promHandler)
 	s.mux.Handle(statusPrefix, authHandler)
 	// The /login endpoint is, by definition, available pre-authentication.
 	s.mux.Handle(loginPath, gwMux)
@@ -1941,13 +1985,19 @@ func (s *Server) Decommission(
 	}
 
 	eventLogger := sql.MakeEventLogger(s.sqlServer.execCfg)
-	var eventType sql.EventLogType
+	var newEvent func(eventpb.CommonNodeDecommissionDetails) eventpb.EventPayload
 	if targetStatus.Decommissioning() {
-		eventType = sql.EventLogNodeDecommissioning
+		newEvent = func(d eventpb.CommonNodeDecommissionDetails) eventpb.EventPayload {
+			return &eventpb.NodeDecommissioning{CommonNodeDecommissionDetails: d}
+		}
 	} else if targetStatus.Decommissioned() {
-		eventType = sql.EventLogNodeDecommissioned
+		newEvent = func(d eventpb.CommonNodeDecommissionDetails) eventpb.EventPayload {
+			return &eventpb.NodeDecommissioned{CommonNodeDecommissionDetails: d}
+		}
 	} else if targetStatus.Active() {
-		eventType = sql.EventLogNodeRecommissioned
+		newEvent = func(d eventpb.CommonNodeDecommissionDetails) eventpb.EventPayload {
+			return &eventpb.NodeRecommissioned{CommonNodeDecommissionDetails: d}
+		}
 	} else {
 		panic("unexpected target membership status")
 	}
@@ -1964,12 +2014,17 @@ func (s *Server) Decommission(
 			// update, this would force a 2PC and potentially leave write intents in
 			// the node liveness range. Better to make the event logging best effort
 			// than to slow down future node liveness transactions.
+			event := newEvent(eventpb.CommonNodeDecommissionDetails{
+				RequestingNodeID: int32(s.NodeID()),
+				TargetNodeID:     int32(nodeID),
+			})
 			if err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 				return eventLogger.InsertEventRecord(
-					ctx, txn, eventType, int32(nodeID), int32(s.NodeID()), struct{}{},
+					ctx, txn, int32(nodeID), int32(s.NodeID()), event,
 				)
 			}); err != nil {
-				log.Errorf(ctx, "unable to record %s event for node %d: %s", eventType, nodeID, err)
+				log.Errorf(ctx, "unable to record %s event for node %d: %s",
+					eventpb.GetEventTypeName(event), nodeID, err)
 			}
 		}
 	}
@@ -1983,6 +2038,8 @@ type sampleEnvironmentCfg struct {
 	goroutineDumpDirName string
 	heapProfileDirName   string
 	runtime              *status.RuntimeStatSampler
+	// grantCoordinator, if set, is informed of the CPU load of the node.
+	grantCoordinator *admission.GrantCoordinator
 }
 
 // startSampleEnvironment starts a periodic loop that samples the environment and,
@@ -2096,6 +2153,9 @@ func startSampleEnvironment(ctx context.Context, cfg sampleEnvironmentCfg) error
 				curStats := goMemStats.Load().(*status.GoMemStats)
 				cgoStats := status.GetCGoMemStats(ctx)
 				cfg.runtime.SampleEnvironment(ctx, curStats, cgoStats)
+				if cfg.grantCoordinator != nil {
+					cfg.grantCoordinator.CPULoad(cfg.runtime.CPUCombinedPercentNorm.Value())
+				}
 				if goroutineDumper != nil {
 					goroutineDumper.MaybeDump(ctx, cfg.st, cfg.runtime.Goroutines.Value())
 				}
@@ -47,9 +47,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/planhints"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleaudit"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlinstance"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlinstance/instanceprovider"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness/slprovider"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
@@ -58,9 +62,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sqlmigrations"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/netutil"
@@ -90,6 +97,8 @@ type sqlServer struct {
 	// sqlMemMetrics are used to track memory usage of sql sessions.
 	sqlMemMetrics           sql.MemoryMetrics
 	stmtDiagnosticsRegistry *stmtdiagnostics.Registry
+	roleAuditRegistry       *roleaudit.Registry
+	planBaselineRegistry    *planhints.Registry
 	sqlLivenessProvider     sqlliveness.Provider
 	metricsRegistry         *metric.Registry
 }
@@ -119,12 +128,22 @@ type sqlServerOptionalKVArgs struct {
 	// Used by backup/restore.
 	externalStorage        cloud.ExternalStorageFactory
 	externalStorageFromURI cloud.ExternalStorageFromURIFactory
+
+	// Used by DistSQL to admit the execution of flows.
+	sqlFlowAdmissionQ *admission.WorkQueue
 }
 
 // sqlServerOptionalTenantArgs are the arguments supplied to newSQLServer which
 // are only available if the SQL server runs as part of a standalone SQL node.
 type sqlServerOptionalTenantArgs struct {
 	tenantConnect kvtenant.Connector
+	// servesSQLInstanceRPCs is set if the RPC server of the SQL pod accepts
+	// the RPCs of the other SQL pods of the tenant, which allows the DistSQL
+	// flows to be distributed across the SQL pods.
+	servesSQLInstanceRPCs bool
+	// sqlInstanceAddr is the advertised address of the RPC server of the SQL
+	// pod, if servesSQLInstanceRPCs is set.
+	sqlInstanceAddr string
 }
 
 type sqlServerArgs struct {
@@ -216,17 +235,48 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 
 	jobRegistry := cfg.circularJobRegistry
 
+	// If necessary, start the tenant proxy first, to ensure all other
+	// components can properly route to KV nodes. The SQL pods of secondary
+	// tenants use it below to register their SQL instance.
+	if cfg.tenantConnect != nil {
+		if err := cfg.tenantConnect.Start(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.sqlLivenessProvider = slprovider.New(
+		cfg.stopper, cfg.clock, cfg.db, codec, cfg.Settings,
+	)
+	cfg.registry.AddMetricStruct(cfg.sqlLivenessProvider.Metrics())
+
+	// The SQL pods of a secondary tenant register themselves in the
+	// sql_instances table. If the SQL pod serves RPCs, its DistSQL flows are
+	// distributed across the SQL pods of the tenant, which dial each other
+	// using the addresses they registered.
+	var sqlInstanceProvider sqlinstance.Provider
+	var sqlInstanceResolver sqlinstance.AddressResolver
+	distSQLNodeDialer := cfg.nodeDialer
+	if !codec.ForSystemTenant() {
+		sqlInstanceProvider = instanceprovider.New(
+			cfg.stopper, cfg.db, codec, cfg.sqlLivenessProvider, cfg.sqlInstanceAddr,
+		)
+		// The SQLInstanceID is allocated before the components which use it
+		// are created, so that it never changes once they observe it.
+		startSQLInstance(ctx, cfg, sqlInstanceProvider)
+		if cfg.servesSQLInstanceRPCs {
+			sqlInstanceResolver = sqlInstanceProvider
+			distSQLNodeDialer = nodedialer.New(
+				cfg.rpcContext, sqlInstanceAddressResolver(sqlInstanceProvider),
+			)
+		}
+	}
+
 	{
 		regLiveness := cfg.nodeLiveness
 		if testingLiveness := cfg.TestingKnobs.RegistryLiveness; testingLiveness != nil {
 			regLiveness = optionalnodeliveness.MakeContainer(testingLiveness.(*jobs.FakeNodeLiveness))
 		}
 
-		cfg.sqlLivenessProvider = slprovider.New(
-			cfg.stopper, cfg.clock, cfg.db, cfg.circularInternalExecutor, cfg.Settings,
-		)
-		cfg.registry.AddMetricStruct(cfg.sqlLivenessProvider.Metrics())
-
 		*jobRegistry = *jobs.MakeRegistry(
 			cfg.AmbientCtx,
 			cfg.stopper,
@@ -370,12 +420,14 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 		SQLLivenessReader: cfg.sqlLivenessProvider,
 		JobRegistry:       jobRegistry,
 		Gossip:            cfg.gossip,
-		NodeDialer:        cfg.nodeDialer,
+		NodeDialer:        distSQLNodeDialer,
 		LeaseManager:      leaseMgr,
 
 		ExternalStorage:        cfg.externalStorage,
 		ExternalStorageFromURI: cfg.externalStorageFromURI,
 
+		SQLFlowAdmissionQ: cfg.sqlFlowAdmissionQ,
+
 		RangeCache:     cfg.distSender.RangeDescriptorCache(),
 		HydratedTables: hydratedTablesCache,
 	}
@@ -416,8 +468,9 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 	if nl, ok := cfg.nodeLiveness.Optional(47900); ok {
 		isLive = nl.IsLive
 	} else {
-		// We're on a SQL tenant, so this is the only node DistSQL will ever
-		// schedule on - always returning true is fine.
+		// We're on a SQL tenant, so DistSQL only schedules on this SQL pod or
+		// on the SQL pods which the sqlinstance subsystem reports as live -
+		// always returning true is fine.
 		isLive = func(roachpb.NodeID) (bool, error) {
 			return true, nil
 		}
@@ -462,7 +515,8 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 			cfg.gossip,
 			cfg.stopper,
 			isLive,
-			cfg.nodeDialer,
+			distSQLNodeDialer,
+			sqlInstanceResolver,
 		),
 
 		TableStatsCache: stats.NewTableStatisticsCache(
@@ -511,6 +565,11 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 		SlowInternalQueryLogger: log.NewSecondaryLogger(loggerCtx, nil, "sql-slow-internal-only",
 			true /* enableGc */, false /* forceSyncWrites */, true /* enableMsgCount */),
 
+		StructuredEventLogger: log.NewSecondaryLogger(
+			loggerCtx, nil, "eventlog",
+			true /* enableGc */, false /* forceSyncWrites */, true, /* enableMsgCount */
+		),
+
 		QueryCache:                 querycache.New(cfg.QueryCacheSize),
 		ProtectedTimestampProvider: cfg.protectedtsProvider,
 		ExternalIODirConfig:        cfg.ExternalIODirConfig,
@@ -522,6 +581,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 	cfg.stopper.AddCloser(execCfg.SlowQueryLogger)
 	cfg.stopper.AddCloser(execCfg.SlowInternalQueryLogger)
 	cfg.stopper.AddCloser(execCfg.AuthLogger)
+	cfg.stopper.AddCloser(execCfg.StructuredEventLogger)
 
 	if sqlSchemaChangerTestingKnobs := cfg.TestingKnobs.SQLSchemaChanger; sqlSchemaChangerTestingKnobs != nil {
 		execCfg.SchemaChangerTestingKnobs = sqlSchemaChangerTestingKnobs.(*sql.SchemaChangerTestingKnobs)
@@ -595,6 +655,13 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 	}
 	distSQLServer.ServerConfig.SessionBoundInternalExecutorFactory = ieFactory
 	jobRegistry.SetSessionBoundInternalExecutorFactory(ieFactory)
+	jobRegistry.SetEventLogFn(func(
+		ctx context.Context, txn *kv.Txn, targetID int32, event eventpb.EventPayload,
+	) error {
+		return sql.MakeEventLogger(execCfg).InsertEventRecord(
+			ctx, txn, targetID, int32(execCfg.NodeID.SQLInstanceID()), event,
+		)
+	})
 
 	distSQLServer.ServerConfig.ProtectedTimestampProvider = execCfg.ProtectedTimestampProvider
 
@@ -612,6 +679,10 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 		cfg.Settings,
 	)
 	execCfg.StmtDiagnosticsRecorder = stmtDiagnosticsRegistry
+	roleAuditRegistry := roleaudit.NewRegistry(cfg.circularInternalExecutor, cfg.Settings)
+	execCfg.RoleAuditRegistry = roleAuditRegistry
+	planBaselineRegistry := planhints.NewRegistry(cfg.circularInternalExecutor, cfg.Settings)
+	execCfg.PlanBaselineRegistry = planBaselineRegistry
 
 	temporaryObjectCleaner := sql.NewTemporaryObjectCleaner(
 		cfg.Settings,
@@ -640,11 +711,50 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*sqlServer, error) {
 		adminMemMetrics:         adminMemMetrics,
 		sqlMemMetrics:           sqlMemMetrics,
 		stmtDiagnosticsRegistry: stmtDiagnosticsRegistry,
+		roleAuditRegistry:       roleAuditRegistry,
+		planBaselineRegistry:    planBaselineRegistry,
 		sqlLivenessProvider:     cfg.sqlLivenessProvider,
 		metricsRegistry:         cfg.registry,
 	}, nil
 }
 
+// startSQLInstance allocates a unique SQLInstanceID to this SQL pod of a
+// secondary tenant, which then becomes the gateway ID of its DistSQL flows. It
+// is called by newSQLServer before the SQLInstanceID is read by any component,
+// and so starts the sqlliveness subsystem early. If the sql_instances table is
+// not available yet, the SQL pod keeps its default ID and its flows are planned
+// locally.
+func startSQLInstance(
+	ctx context.Context, cfg sqlServerArgs, sqlInstanceProvider sqlinstance.Provider,
+) {
+	if !cfg.Settings.Version.IsActive(ctx, clusterversion.VersionSQLInstancesTable) {
+		log.Infof(ctx, "not registering SQL instance: the sql_instances table is not available")
+		return
+	}
+	cfg.sqlLivenessProvider.Start(ctx)
+	instanceID, err := sqlInstanceProvider.Start(ctx)
+	if err != nil {
+		log.Warningf(ctx, "could not register SQL instance: %v", err)
+		return
+	}
+	cfg.nodeIDContainer.SetSQLInstanceID(instanceID)
+	cfg.rpcContext.NodeID.Set(ctx, roachpb.NodeID(instanceID))
+	log.Infof(ctx, "registered SQL instance %d", instanceID)
+}
+
+// sqlInstanceAddressResolver returns a nodedialer.AddressResolver which
+// resolves the SQLInstanceIDs of the SQL pods of a tenant to the addresses of
+// their RPC servers.
+func sqlInstanceAddressResolver(r sqlinstance.AddressResolver) nodedialer.AddressResolver {
+	return func(nodeID roachpb.NodeID) (net.Addr, error) {
+		instance, err := r.GetInstance(context.Background(), base.SQLInstanceID(nodeID))
+		if err != nil {
+			return nil, err
+		}
+		return util.NewUnresolvedAddr("tcp", instance.InstanceAddr), nil
+	}
+}
+
 func (s *sqlServer) start(
 	ctx context.Context,
 	stopper *stop.Stopper,
@@ -654,13 +764,7 @@ func (s *sqlServer) start(
 	socketFile string,
 	orphanedLeasesTimeThresholdNanos int64,
 ) error {
-	// If necessary, start the tenant proxy first, to ensure all other
-	// components can properly route to KV nodes.
-	if s.tenantConnect != nil {
-		if err := s.tenantConnect.Start(ctx); err != nil {
-			return err
-		}
-	}
+	// NB: the tenant proxy, if any, has already been started by newSQLServer.
 	s.sqlLivenessProvider.Start(ctx)
 	s.temporaryObjectCleaner.Start(ctx, stopper)
 	s.distSQLServer.Start()
@@ -669,6 +773,8 @@ func (s *sqlServer) start(
 		return err
 	}
 	s.stmtDiagnosticsRegistry.Start(ctx, stopper)
+	s.roleAuditRegistry.Start(ctx, stopper)
+	s.planBaselineRegistry.Start(ctx, stopper)
 
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
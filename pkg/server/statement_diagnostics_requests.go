@@ -76,7 +76,13 @@ func (s *statusServer) CreateStatementDiagnosticsReport(
 		Report: &serverpb.StatementDiagnosticsReport{},
 	}
 
-	err := s.stmtDiagnosticsRequester.InsertRequest(ctx, req.StatementFingerprint)
+	err := s.stmtDiagnosticsRequester.InsertRequest(
+		ctx,
+		req.StatementFingerprint,
+		0, /* samplingProbability */
+		0, /* minExecutionLatency */
+		0, /* expiresAfter */
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -51,6 +51,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/contention"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
@@ -298,6 +299,7 @@ type statusServer struct {
 	si                       systemInfoOnce
 	stmtDiagnosticsRequester StmtDiagnosticsRequester
 	internalExecutor         *sql.InternalExecutor
+	contentionRegistry       *contention.Registry
 }
 
 // StmtDiagnosticsRequester is the interface into *stmtdiagnostics.Registry
@@ -308,7 +310,17 @@ type StmtDiagnosticsRequester interface {
 	// tracing a query with the given fingerprint. Once this returns, calling
 	// shouldCollectDiagnostics() on the current node will return true for the given
 	// fingerprint.
-	InsertRequest(ctx context.Context, fprint string) error
+	//
+	// The zero values of samplingProbability, minExecutionLatency and
+	// expiresAfter make an unconditional request that is satisfied by the next
+	// execution of the statement.
+	InsertRequest(
+		ctx context.Context,
+		fprint string,
+		samplingProbability float64,
+		minExecutionLatency time.Duration,
+		expiresAfter time.Duration,
+	) error
 }
 
 // newStatusServer allocates and returns a statusServer.
@@ -327,6 +339,7 @@ func newStatusServer(
 	stopper *stop.Stopper,
 	sessionRegistry *sql.SessionRegistry,
 	internalExecutor *sql.InternalExecutor,
+	contentionRegistry *contention.Registry,
 ) *statusServer {
 	ambient.AddLogTag("status", nil)
 	server := &statusServer{
@@ -336,17 +349,18 @@ func newStatusServer(
 			sessionRegistry:  sessionRegistry,
 			st:               st,
 		},
-		cfg:              cfg,
-		admin:            adminServer,
-		db:               db,
-		gossip:           gossip,
-		metricSource:     metricSource,
-		nodeLiveness:     nodeLiveness,
-		storePool:        storePool,
-		rpcCtx:           rpcCtx,
-		stores:           stores,
-		stopper:          stopper,
-		internalExecutor: internalExecutor,
+		cfg:                cfg,
+		admin:              adminServer,
+		db:                 db,
+		gossip:             gossip,
+		metricSource:       metricSource,
+		nodeLiveness:       nodeLiveness,
+		storePool:          storePool,
+		rpcCtx:             rpcCtx,
+		stores:             stores,
+		stopper:            stopper,
+		internalExecutor:   internalExecutor,
+		contentionRegistry: contentionRegistry,
 	}
 
 	return server
@@ -1920,6 +1934,61 @@ func (s *statusServer) ListSessions(
 	return response, nil
 }
 
+// ListLocalContentionEvents returns a list of contention events on this node.
+func (s *statusServer) ListLocalContentionEvents(
+	ctx context.Context, _ *serverpb.ListContentionEventsRequest,
+) (*serverpb.ListContentionEventsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireViewActivityPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	return &serverpb.ListContentionEventsResponse{
+		Events: s.contentionRegistry.Serialize(),
+	}, nil
+}
+
+// ListContentionEvents returns a list of contention events on all nodes in the
+// cluster, merged into a single view.
+func (s *statusServer) ListContentionEvents(
+	ctx context.Context, req *serverpb.ListContentionEventsRequest,
+) (*serverpb.ListContentionEventsResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	ctx = s.AnnotateCtx(ctx)
+
+	if _, err := s.privilegeChecker.requireViewActivityPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	var response serverpb.ListContentionEventsResponse
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.ListLocalContentionEvents(ctx, req)
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		if nodeResp == nil {
+			return
+		}
+		events := nodeResp.(*serverpb.ListContentionEventsResponse).Events
+		response.Events = contention.MergeSerializedRegistries(response.Events, events)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		errResponse := serverpb.ListContentionEventsError{NodeID: nodeID, Message: err.Error()}
+		response.Errors = append(response.Errors, errResponse)
+	}
+
+	if err := s.iterateNodes(ctx, "contention events list", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
 // CancelSession responds to a session cancellation request by canceling the
 // target session's associated context.
 func (s *statusServer) CancelSession(
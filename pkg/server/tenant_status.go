@@ -13,9 +13,12 @@ package server
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -27,6 +30,10 @@ import (
 // tenant.
 type tenantStatusServer struct {
 	baseStatusServer
+
+	// sqlServer is set once the tenant's SQL server has been created, after
+	// the tenantStatusServer.
+	sqlServer *sql.Server
 }
 
 func newTenantStatusServer(
@@ -62,6 +69,22 @@ func (t *tenantStatusServer) ListLocalSessions(
 	return &serverpb.ListSessionsResponse{Sessions: sessions}, nil
 }
 
+// ListContentionEvents is unsupported for tenants: contention events are
+// recorded by the KV layer, which is not part of a SQL pod.
+func (t *tenantStatusServer) ListContentionEvents(
+	ctx context.Context, request *serverpb.ListContentionEventsRequest,
+) (*serverpb.ListContentionEventsResponse, error) {
+	return t.ListLocalContentionEvents(ctx, request)
+}
+
+// ListLocalContentionEvents is unsupported for tenants: contention events are
+// recorded by the KV layer, which is not part of a SQL pod.
+func (t *tenantStatusServer) ListLocalContentionEvents(
+	ctx context.Context, _ *serverpb.ListContentionEventsRequest,
+) (*serverpb.ListContentionEventsResponse, error) {
+	return nil, errorutil.UnsupportedWithMultiTenancy()
+}
+
 func (t *tenantStatusServer) CancelQuery(
 	ctx context.Context, request *serverpb.CancelQueryRequest,
 ) (*serverpb.CancelQueryResponse, error) {
@@ -87,3 +110,54 @@ func (t *tenantStatusServer) CancelSession(
 	}
 	return t.sessionRegistry.CancelSession(request.SessionID)
 }
+
+// Statements returns the statement statistics of the local SQL pod, since
+// there can only be one pod per tenant.
+func (t *tenantStatusServer) Statements(
+	ctx context.Context, _ *serverpb.StatementsRequest,
+) (*serverpb.StatementsResponse, error) {
+	ctx = t.AnnotateCtx(ctx)
+	if _, err := t.privilegeChecker.requireViewActivityPermission(ctx); err != nil {
+		return nil, err
+	}
+
+	stmtStats := t.sqlServer.GetUnscrubbedStmtStats()
+	txnStats := t.sqlServer.GetUnscrubbedTxnStats()
+	instanceID := roachpb.NodeID(t.sqlServer.GetExecutorConfig().NodeID.SQLInstanceID())
+
+	resp := &serverpb.StatementsResponse{
+		Statements:            make([]serverpb.StatementsResponse_CollectedStatementStatistics, len(stmtStats)),
+		LastReset:             t.sqlServer.GetStmtStatsLastReset(),
+		InternalAppNamePrefix: catconstants.InternalAppNamePrefix,
+		Transactions:          make([]serverpb.StatementsResponse_ExtendedCollectedTransactionStatistics, len(txnStats)),
+	}
+	for i, txn := range txnStats {
+		resp.Transactions[i] = serverpb.StatementsResponse_ExtendedCollectedTransactionStatistics{
+			StatsData: txn,
+			NodeID:    instanceID,
+		}
+	}
+	for i, stmt := range stmtStats {
+		resp.Statements[i] = serverpb.StatementsResponse_CollectedStatementStatistics{
+			Key: serverpb.StatementsResponse_ExtendedStatementStatisticsKey{
+				KeyData: stmt.Key,
+				NodeID:  instanceID,
+			},
+			ID:    stmt.ID,
+			Stats: stmt.Stats,
+		}
+	}
+	return resp, nil
+}
+
+// IndexUsageStatistics returns the index usage statistics of the local SQL
+// pod, since there can only be one pod per tenant.
+func (t *tenantStatusServer) IndexUsageStatistics(
+	ctx context.Context, _ *serverpb.IndexUsageStatisticsRequest,
+) (*serverpb.IndexUsageStatisticsResponse, error) {
+	ctx = t.AnnotateCtx(ctx)
+	if _, err := t.privilegeChecker.requireViewActivityPermission(ctx); err != nil {
+		return nil, err
+	}
+	return indexUsageStatisticsLocal(t.sqlServer.GetLocalIndexStatistics()), nil
+}
@@ -13,6 +13,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -523,17 +524,26 @@ func makeSQLServerArgs(
 	runtime := status.NewRuntimeStatSampler(context.Background(), clock)
 	registry.AddMetricStruct(runtime)
 
-	// We don't need this for anything except some services that want a gRPC
-	// server to register against (but they'll never get RPCs at the time of
-	// writing): the blob service and DistSQL.
-	dummyRPCServer := grpc.NewServer()
+	// If configured to, the RPC server accepts the DistSQL flows set up by the
+	// other SQL pods of the tenant. Otherwise, the RPC server is a dummy one
+	// which only exists for the services that register against it (the blob
+	// service and DistSQL), and the flows are always planned locally.
+	//
+	// TODO(multitenant): issue dedicated server certificates to the SQL pods.
+	grpcServer := grpc.NewServer()
+	if sqlCfg.TenantServeSQLInstanceRPCs {
+		if _, err := rpcContext.GetServerTLSConfig(); err != nil {
+			return sqlServerArgs{}, errors.Wrap(err, "serving RPCs to other SQL pods")
+		}
+		grpcServer = rpc.NewServer(rpcContext)
+	}
 	sessionRegistry := sql.NewSessionRegistry()
 	return sqlServerArgs{
 		sqlServerOptionalKVArgs: sqlServerOptionalKVArgs{
 			nodesStatusServer: serverpb.MakeOptionalNodesStatusServer(nil),
 			nodeLiveness:      optionalnodeliveness.MakeContainer(nil),
 			gossip:            gossip.MakeOptionalGossip(nil),
-			grpcServer:        dummyRPCServer,
+			grpcServer:        grpcServer,
 			isMeta1Leaseholder: func(_ context.Context, timestamp hlc.Timestamp) (bool, error) {
 				return false, errors.New("isMeta1Leaseholder is not available to secondary tenants")
 			},
@@ -547,7 +557,8 @@ func makeSQLServerArgs(
 			},
 		},
 		sqlServerOptionalTenantArgs: sqlServerOptionalTenantArgs{
-			tenantConnect: tenantConnect,
+			tenantConnect:         tenantConnect,
+			servesSQLInstanceRPCs: sqlCfg.TenantServeSQLInstanceRPCs,
 		},
 		SQLConfig:                &sqlCfg,
 		BaseConfig:               &baseCfg,
@@ -591,6 +602,9 @@ func (ts *TestServer) StartTenant(
 	sqlCfg.TenantKVAddrs = []string{ts.ServingRPCAddr()}
 	sqlCfg.TenantIDCodecOverride = params.TenantIDCodecOverride
 	baseCfg := makeTestBaseConfig(st)
+	// Test tenants are secure and have a node certificate, so they can accept
+	// the DistSQL flows set up by each other.
+	sqlCfg.TenantServeSQLInstanceRPCs = !baseCfg.Insecure
 	if params.AllowSettingClusterSettings {
 		baseCfg.TestingKnobs.TenantTestingKnobs = &sql.TenantTestingKnobs{
 			ClusterSettingsUpdater: st.MakeUpdater(),
@@ -617,14 +631,44 @@ func StartTenant(
 	if err != nil {
 		return "", "", err
 	}
+
+	// The RPC listener is set up before the SQL server is created so that the
+	// SQL pod can register its advertised RPC address.
+	var rpcL net.Listener
+	if args.servesSQLInstanceRPCs {
+		rpcL, err = listen(ctx, &args.Config.Addr, &args.Config.AdvertiseAddr, "rpc")
+		if err != nil {
+			return "", "", err
+		}
+		args.sqlInstanceAddr = args.Config.AdvertiseAddr
+		args.stopper.RunWorker(ctx, func(ctx context.Context) {
+			<-args.stopper.ShouldQuiesce()
+			_ = rpcL.Close()
+			<-args.stopper.ShouldStop()
+			args.grpcServer.Stop()
+		})
+	}
+
 	s, err := newSQLServer(ctx, args)
 	if err != nil {
 		return "", "", err
 	}
 
+	// The services are registered against the RPC server by newSQLServer, so
+	// it can only start serving now.
+	if rpcL != nil {
+		args.stopper.RunWorker(ctx, func(ctx context.Context) {
+			netutil.FatalIfUnexpected(args.grpcServer.Serve(rpcL))
+		})
+	}
+	args.sqlStatusServer.(*tenantStatusServer).sqlServer = s.pgServer.SQLServer
+
 	// TODO(asubiotto): remove this. Right now it is needed to initialize the
-	// SpanResolver.
-	s.execCfg.DistSQLPlanner.SetNodeInfo(roachpb.NodeDescriptor{NodeID: 0})
+	// SpanResolver. The SQLInstanceID has been allocated by newSQLServer, so the
+	// DistSQL flows of this SQL pod are identified by it.
+	s.execCfg.DistSQLPlanner.SetNodeInfo(roachpb.NodeDescriptor{
+		NodeID: roachpb.NodeID(s.execCfg.NodeID.SQLInstanceID()),
+	})
 
 	connManager := netutil.MakeServer(
 		args.stopper,
@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/proto"
@@ -789,21 +790,14 @@ func (n *alterTableNode) startExec(params runParams) error {
 	// Record this table alteration in the event log. This is an auditable log
 	// event and is recorded in the same transaction as the table descriptor
 	// update.
-	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
-		params.ctx,
-		params.p.txn,
-		EventLogAlterTable,
-		int32(n.tableDesc.ID),
-		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
-		struct {
-			TableName           string
-			Statement           string
-			User                string
-			MutationID          uint32
-			CascadeDroppedViews []string
-		}{params.p.ResolvedName(n.n.Table).FQString(), n.n.String(),
-			params.SessionData().User, uint32(mutationID), droppedViews},
-	)
+	return params.p.logEvent(params.ctx,
+		n.tableDesc.ID,
+		&eventpb.AlterTable{
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{Statement: n.n.String()},
+			TableName:             params.p.ResolvedName(n.n.Table).FQString(),
+			MutationID:            uint32(mutationID),
+			CascadeDroppedViews:   droppedViews,
+		})
 }
 
 func (p *planner) setAuditMode(
@@ -1060,6 +1054,17 @@ func injectTableStats(
 		if s.Name != "" {
 			name = s.Name
 		}
+		var expression interface{}
+		if s.Expression != "" {
+			if !params.ExecCfg().Settings.Version.IsActive(
+				params.ctx, clusterversion.VersionStatisticsExpressions,
+			) {
+				return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					`injecting statistics on expressions requires all nodes to be upgraded to %s`,
+					clusterversion.VersionByKey(clusterversion.VersionStatisticsExpressions))
+			}
+			expression = s.Expression
+		}
 		if _ /* rows */, err := params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
 			params.ctx,
 			"insert-stats",
@@ -1072,8 +1077,9 @@ func injectTableStats(
 					"rowCount",
 					"distinctCount",
 					"nullCount",
-					histogram
-				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+					histogram,
+					expression
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
 			desc.GetID(),
 			name,
 			columnIDs,
@@ -1082,6 +1088,7 @@ func injectTableStats(
 			s.DistinctCount,
 			s.NullCount,
 			histogram,
+			expression,
 		); err != nil {
 			return errors.Wrapf(err, "failed to insert stats")
 		}
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/errors"
 )
 
@@ -90,18 +91,14 @@ func (n *alterTypeNode) startExec(params runParams) error {
 	}
 
 	// Write a log event.
-	return MakeEventLogger(params.p.ExecCfg()).InsertEventRecord(
-		params.ctx,
-		params.p.txn,
-		EventLogAlterType,
-		int32(n.desc.ID),
-		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
-		struct {
-			TypeName  string
-			Statement string
-			User      string
-		}{n.desc.Name, tree.AsStringWithFQNames(n.n, params.Ann()), params.p.User()},
-	)
+	return params.p.logEvent(params.ctx,
+		n.desc.ID,
+		&eventpb.AlterType{
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: tree.AsStringWithFQNames(n.n, params.Ann()),
+			},
+			TypeName: n.desc.Name,
+		})
 }
 
 func (p *planner) addEnumValue(
@@ -540,6 +540,31 @@ func (s *sqlStats) resetAndMaybeDumpStats(ctx context.Context, target *sqlStats)
 	}
 }
 
+// drain clears all the stored per-app statement and transaction statistics
+// and returns them in a new sqlStats container, which is not visible to any
+// session.
+func (s *sqlStats) drain(ctx context.Context) *sqlStats {
+	drained := &sqlStats{st: s.st, apps: make(map[string]*appStats)}
+
+	s.Lock()
+	defer s.Unlock()
+	// As in resetAndMaybeDumpStats, the per-app maps are cleared manually
+	// since open sessions have cached the pointers to their appStats.
+	for appName, a := range s.apps {
+		a.Lock()
+		if dumpStmtStatsToLogBeforeReset.Get(&a.st.SV) {
+			dumpStmtStats(ctx, appName, a.stmts)
+		}
+		drained.apps[appName] = &appStats{st: a.st, stmts: a.stmts, txns: a.txns}
+		a.stmts = make(map[stmtKey]*stmtStats, len(a.stmts)/2)
+		a.txns = make(map[txnKey]*txnStats, len(a.txns)/2)
+		a.Unlock()
+	}
+	s.lastReset = timeutil.Now()
+	drained.lastReset = s.lastReset
+	return drained
+}
+
 func (s *sqlStats) getLastReset() time.Time {
 	s.Lock()
 	defer s.Unlock()
@@ -625,7 +650,7 @@ func (s *sqlStats) getUnscrubbedTxnStats() []roachpb.CollectedTransactionStatist
 		if cap(ret) == 0 {
 			ret = make([]roachpb.CollectedTransactionStatistics, 0, len(a.txns)*len(s.apps))
 		}
-		for _, stats := range a.txns {
+		for key, stats := range a.txns {
 			stats.mu.Lock()
 			data := stats.mu.data
 			stats.mu.Unlock()
@@ -634,6 +659,7 @@ func (s *sqlStats) getUnscrubbedTxnStats() []roachpb.CollectedTransactionStatist
 				StatementIDs: stats.statementIDs,
 				App:          appName,
 				Stats:        data,
+				ID:           string(key),
 			})
 		}
 		a.Unlock()
@@ -0,0 +1,373 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// SQLStatsFlushEnabled controls whether the statement and transaction
+// statistics collected in memory are periodically flushed to the
+// system.statement_statistics and system.transaction_statistics tables.
+var SQLStatsFlushEnabled = settings.RegisterPublicBoolSetting(
+	"sql.stats.flush.enabled",
+	"if set, SQL execution statistics are periodically flushed to disk",
+	true,
+)
+
+// SQLStatsFlushInterval is the interval at which the statement and
+// transaction statistics are flushed to the system tables.
+var SQLStatsFlushInterval = func() *settings.DurationSetting {
+	s := settings.RegisterValidatedDurationSetting(
+		"sql.stats.flush.interval",
+		"the interval at which SQL execution statistics are flushed to disk",
+		10*time.Minute,
+		func(v time.Duration) error {
+			if v < time.Second {
+				return errors.Errorf("sql.stats.flush.interval must be at least 1s, got %s", v)
+			}
+			return nil
+		},
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
+// SQLStatsAggregationInterval is the width of the time buckets into which
+// the flushed statistics are aggregated. Statistics flushed by a node within
+// the same bucket are merged into a single row.
+var SQLStatsAggregationInterval = func() *settings.DurationSetting {
+	s := settings.RegisterValidatedDurationSetting(
+		"sql.stats.aggregation.interval",
+		"the interval at which SQL execution statistics are aggregated on disk",
+		time.Hour,
+		func(v time.Duration) error {
+			if v < time.Minute {
+				return errors.Errorf("sql.stats.aggregation.interval must be at least 1m, got %s", v)
+			}
+			return nil
+		},
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
+// SQLStatsTTL is how long the flushed statistics are retained before they
+// are deleted.
+var SQLStatsTTL = settings.RegisterPublicNonNegativeDurationSetting(
+	"sql.stats.persisted_rows.ttl",
+	"the amount of time for which SQL execution statistics are retained on disk "+
+		"(0 to retain them forever)",
+	7*24*time.Hour,
+)
+
+// sqlStatsKey identifies the statistics of a statement or transaction
+// fingerprint collected by a node during an aggregation interval. It is the
+// primary key of the system.statement_statistics and
+// system.transaction_statistics tables.
+type sqlStatsKey struct {
+	aggregatedTs  time.Time
+	fingerprintID string
+	appName       string
+	nodeID        int64
+}
+
+// less orders the keys by aggregation time first, so that the cluster-wide
+// views list older statistics first.
+func (k sqlStatsKey) less(o sqlStatsKey) bool {
+	if !k.aggregatedTs.Equal(o.aggregatedTs) {
+		return k.aggregatedTs.Before(o.aggregatedTs)
+	}
+	if k.fingerprintID != o.fingerprintID {
+		return k.fingerprintID < o.fingerprintID
+	}
+	if k.appName != o.appName {
+		return k.appName < o.appName
+	}
+	return k.nodeID < o.nodeID
+}
+
+func (k sqlStatsKey) datums() (tree.Datums, error) {
+	ts, err := tree.MakeDTimestampTZ(k.aggregatedTs, time.Microsecond)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Datums{
+		ts,
+		tree.NewDString(k.fingerprintID),
+		tree.NewDString(k.appName),
+		tree.NewDInt(tree.DInt(k.nodeID)),
+	}, nil
+}
+
+// sqlStatsDeleteBatchSize is the maximum number of expired rows deleted by a
+// single statement.
+const sqlStatsDeleteBatchSize = 1024
+
+// currentSQLStatsAggregation returns the start and the width of the
+// aggregation interval the current time falls into.
+func currentSQLStatsAggregation(cfg *ExecutorConfig) (time.Time, time.Duration) {
+	aggInterval := SQLStatsAggregationInterval.Get(&cfg.Settings.SV)
+	return cfg.Clock.PhysicalTime().Truncate(aggInterval), aggInterval
+}
+
+// startSQLStatsFlushLoop starts a worker which periodically flushes the
+// statistics collected in memory to the system tables.
+func (s *Server) startSQLStatsFlushLoop(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(SQLStatsFlushInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if SQLStatsFlushEnabled.Get(&s.cfg.Settings.SV) {
+				s.FlushSQLStats(ctx)
+			}
+		}
+	})
+}
+
+// FlushSQLStats writes the statement and transaction statistics collected in
+// memory since the last flush to the system tables, and clears them from
+// memory. As with ResetSQLStats, the statement statistics are handed over to
+// the reported stats. If the statistics can't be written, they are put back
+// into memory so that the next flush retries them.
+func (s *Server) FlushSQLStats(ctx context.Context) {
+	drained := s.sqlStats.drain(ctx)
+	if err := s.persistSQLStats(ctx, drained); err != nil {
+		log.Warningf(ctx, "failed to flush SQL statistics: %v", err)
+		for appName, a := range drained.apps {
+			s.sqlStats.getStatsForApplication(appName).Add(a)
+		}
+		return
+	}
+	for appName, a := range drained.apps {
+		// Add takes care of the locking, and only the statement statistics
+		// are reported.
+		s.reportedStats.getStatsForApplication(appName).Add(&appStats{st: a.st, stmts: a.stmts})
+	}
+	if err := s.deleteExpiredSQLStats(ctx); err != nil {
+		log.Warningf(ctx, "failed to delete expired SQL statistics: %v", err)
+	}
+}
+
+// persistSQLStats writes the given statistics to the system tables. All the
+// statistics are written in a single transaction, so that a failed flush
+// doesn't leave some of them persisted: they can then all be put back into
+// memory without being counted twice.
+func (s *Server) persistSQLStats(ctx context.Context, drained *sqlStats) error {
+	if !s.cfg.Settings.Version.IsActive(ctx, clusterversion.VersionSQLStatsTables) {
+		return nil
+	}
+	if fn := s.cfg.TestingKnobs.BeforeSQLStatsPersist; fn != nil {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+	}
+	aggregatedTs, aggInterval := currentSQLStatsAggregation(s.cfg)
+	nodeID := s.cfg.NodeID.SQLInstanceID()
+	stmts := drained.getUnscrubbedStmtStats(s.cfg.VirtualSchemas)
+	txns := drained.getUnscrubbedTxnStats()
+
+	return s.cfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		for _, stmt := range stmts {
+			if stmt.Stats.Count == 0 {
+				continue
+			}
+			key := sqlStatsKey{
+				aggregatedTs:  aggregatedTs,
+				fingerprintID: string(stmt.ID),
+				appName:       stmt.Key.App,
+				nodeID:        int64(nodeID),
+			}
+			if err := s.upsertStmtStats(ctx, txn, key, aggInterval, stmt); err != nil {
+				return err
+			}
+		}
+		for _, txnStats := range txns {
+			if txnStats.Stats.Count == 0 {
+				continue
+			}
+			key := sqlStatsKey{
+				aggregatedTs:  aggregatedTs,
+				fingerprintID: txnStats.ID,
+				appName:       txnStats.App,
+				nodeID:        int64(nodeID),
+			}
+			if err := s.upsertTxnStats(ctx, txn, key, aggInterval, txnStats); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// upsertStmtStats writes the statistics of a statement fingerprint, merging
+// them with the statistics already flushed by this node during the same
+// aggregation interval.
+func (s *Server) upsertStmtStats(
+	ctx context.Context,
+	txn *kv.Txn,
+	key sqlStatsKey,
+	aggInterval time.Duration,
+	stmt roachpb.CollectedStatementStatistics,
+) error {
+	pk, err := key.datums()
+	if err != nil {
+		return err
+	}
+	row, err := s.cfg.InternalExecutor.QueryRowEx(
+		ctx, "read-stmt-stats", txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUser},
+		`SELECT statistics FROM system.statement_statistics
+		  WHERE aggregated_ts = $1 AND fingerprint_id = $2 AND app_name = $3 AND node_id = $4`,
+		pk[0], pk[1], pk[2], pk[3],
+	)
+	if err != nil {
+		return err
+	}
+	stats := stmt.Stats
+	if row != nil {
+		var existing roachpb.StatementStatistics
+		if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(row[0])), &existing); err != nil {
+			return err
+		}
+		existing.Add(&stats)
+		stats = existing
+	}
+	metadata, err := protoutil.Marshal(&stmt.Key)
+	if err != nil {
+		return err
+	}
+	statistics, err := protoutil.Marshal(&stats)
+	if err != nil {
+		return err
+	}
+	_, err = s.cfg.InternalExecutor.ExecEx(
+		ctx, "upsert-stmt-stats", txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUser},
+		`UPSERT INTO system.statement_statistics
+		   (aggregated_ts, fingerprint_id, app_name, node_id, agg_interval, metadata, statistics)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		pk[0], pk[1], pk[2], pk[3], aggInterval,
+		tree.NewDBytes(tree.DBytes(metadata)), tree.NewDBytes(tree.DBytes(statistics)),
+	)
+	return err
+}
+
+// upsertTxnStats writes the statistics of a transaction fingerprint, merging
+// them with the statistics already flushed by this node during the same
+// aggregation interval.
+func (s *Server) upsertTxnStats(
+	ctx context.Context,
+	txn *kv.Txn,
+	key sqlStatsKey,
+	aggInterval time.Duration,
+	txnStats roachpb.CollectedTransactionStatistics,
+) error {
+	pk, err := key.datums()
+	if err != nil {
+		return err
+	}
+	stmtIDs := tree.NewDArray(types.String)
+	for _, id := range txnStats.StatementIDs {
+		if err := stmtIDs.Append(tree.NewDString(string(id))); err != nil {
+			return err
+		}
+	}
+	row, err := s.cfg.InternalExecutor.QueryRowEx(
+		ctx, "read-txn-stats", txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUser},
+		`SELECT statistics FROM system.transaction_statistics
+		  WHERE aggregated_ts = $1 AND fingerprint_id = $2 AND app_name = $3 AND node_id = $4`,
+		pk[0], pk[1], pk[2], pk[3],
+	)
+	if err != nil {
+		return err
+	}
+	stats := txnStats.Stats
+	if row != nil {
+		var existing roachpb.TransactionStatistics
+		if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(row[0])), &existing); err != nil {
+			return err
+		}
+		existing.Add(&stats)
+		stats = existing
+	}
+	statistics, err := protoutil.Marshal(&stats)
+	if err != nil {
+		return err
+	}
+	_, err = s.cfg.InternalExecutor.ExecEx(
+		ctx, "upsert-txn-stats", txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUser},
+		`UPSERT INTO system.transaction_statistics
+		   (aggregated_ts, fingerprint_id, app_name, node_id, agg_interval, statement_ids, statistics)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		pk[0], pk[1], pk[2], pk[3], aggInterval,
+		stmtIDs, tree.NewDBytes(tree.DBytes(statistics)),
+	)
+	return err
+}
+
+// deleteExpiredSQLStats deletes the statistics which are older than the
+// configured TTL, regardless of the node that flushed them, so that the
+// statistics of decommissioned nodes are cleaned up as well. The rows are
+// deleted in batches to keep the transactions small.
+func (s *Server) deleteExpiredSQLStats(ctx context.Context) error {
+	ttl := SQLStatsTTL.Get(&s.cfg.Settings.SV)
+	if ttl == 0 {
+		return nil
+	}
+	cutoff, err := tree.MakeDTimestampTZ(s.cfg.Clock.PhysicalTime().Add(-ttl), time.Microsecond)
+	if err != nil {
+		return err
+	}
+	for _, table := range []string{"system.statement_statistics", "system.transaction_statistics"} {
+		for {
+			deleted, err := s.cfg.InternalExecutor.ExecEx(
+				ctx, "delete-expired-sql-stats", nil, /* txn */
+				sessiondata.InternalExecutorOverride{User: security.NodeUser},
+				`DELETE FROM `+table+` WHERE aggregated_ts < $1 LIMIT $2`,
+				cutoff, sqlStatsDeleteBatchSize,
+			)
+			if err != nil {
+				return err
+			}
+			if deleted < sqlStatsDeleteBatchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
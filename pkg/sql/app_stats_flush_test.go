@@ -0,0 +1,156 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLStatsFlush(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	var failFlush int32
+	params, _ := tests.CreateTestServerParams()
+	params.Knobs.SQLExecutor = &sql.ExecutorTestingKnobs{
+		BeforeSQLStatsPersist: func(context.Context) error {
+			if atomic.LoadInt32(&failFlush) == 1 {
+				return errors.New("injected flush failure")
+			}
+			return nil
+		},
+	}
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+	sqlServer := s.SQLServer().(*sql.Server)
+
+	// Use a single aggregation interval for the whole test, so that all the
+	// flushes merge into the same rows.
+	sql.SQLStatsAggregationInterval.Override(&s.ClusterSettings().SV, 24*time.Hour)
+
+	conn, err := sqlDB.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	db := sqlutils.MakeSQLRunner(conn)
+	db.Exec(t, `SET application_name = 'flush_test'`)
+	runQuery := func(n int) {
+		for i := 0; i < n; i++ {
+			db.Exec(t, `SELECT 1`)
+		}
+	}
+
+	// persistedCount returns the number of executions of the test statement
+	// flushed to system.statement_statistics, and viewCount the number of
+	// executions reported by crdb_internal.statement_statistics, which also
+	// includes the statistics still held in memory.
+	adminDB := sqlutils.MakeSQLRunner(sqlDB)
+	persistedCount := func() int {
+		var rows, count int
+		adminDB.QueryRow(t, `
+SELECT count(*), COALESCE(sum((crdb_internal.pb_to_json('cockroach.sql.StatementStatistics', statistics)->>'count')::INT), 0)
+  FROM system.statement_statistics
+ WHERE app_name = 'flush_test'
+   AND crdb_internal.pb_to_json('cockroach.sql.StatementStatisticsKey', metadata)->>'query' = 'SELECT _'`,
+		).Scan(&rows, &count)
+		require.LessOrEqual(t, rows, 1, "statistics of a single interval should be merged")
+		return count
+	}
+	viewCount := func() int {
+		var count int
+		adminDB.QueryRow(t, `
+SELECT COALESCE(sum((statistics->>'count')::INT), 0)
+  FROM crdb_internal.statement_statistics
+ WHERE app_name = 'flush_test' AND metadata->>'query' = 'SELECT _'`,
+		).Scan(&count)
+		return count
+	}
+	txnViewCount := func() int {
+		var count int
+		adminDB.QueryRow(t, `
+SELECT COALESCE(sum((statistics->>'count')::INT), 0)
+  FROM crdb_internal.transaction_statistics
+ WHERE app_name = 'flush_test'`,
+		).Scan(&count)
+		return count
+	}
+
+	// Start from a clean slate.
+	sqlServer.FlushSQLStats(ctx)
+	baseTxnCount := txnViewCount()
+
+	t.Run("flush", func(t *testing.T) {
+		runQuery(3)
+		require.Equal(t, 0, persistedCount())
+		require.Equal(t, 3, viewCount())
+		sqlServer.FlushSQLStats(ctx)
+		require.Equal(t, 3, persistedCount())
+		require.Equal(t, 3, viewCount())
+		require.Equal(t, baseTxnCount+3, txnViewCount())
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		// The statistics collected since the last flush are merged with
+		// the persisted ones, both by the flush and by the virtual table.
+		runQuery(2)
+		require.Equal(t, 3, persistedCount())
+		require.Equal(t, 5, viewCount())
+		sqlServer.FlushSQLStats(ctx)
+		require.Equal(t, 5, persistedCount())
+		require.Equal(t, 5, viewCount())
+	})
+
+	t.Run("failed flush", func(t *testing.T) {
+		// The statistics of a failed flush are kept in memory and written by
+		// the next flush.
+		runQuery(2)
+		atomic.StoreInt32(&failFlush, 1)
+		sqlServer.FlushSQLStats(ctx)
+		atomic.StoreInt32(&failFlush, 0)
+		require.Equal(t, 5, persistedCount())
+		require.Equal(t, 7, viewCount())
+		sqlServer.FlushSQLStats(ctx)
+		require.Equal(t, 7, persistedCount())
+		require.Equal(t, 7, viewCount())
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		// Expired statistics are deleted by every node, including those
+		// flushed by other nodes.
+		for _, stmt := range []string{
+			`INSERT INTO system.statement_statistics VALUES
+			   (now() - '30 days'::INTERVAL, 'expired', 'flush_test', 99, '1h', ''::BYTES, ''::BYTES)`,
+			`INSERT INTO system.transaction_statistics VALUES
+			   (now() - '30 days'::INTERVAL, 'expired', 'flush_test', 99, '1h', ARRAY[]::STRING[], ''::BYTES)`,
+		} {
+			adminDB.Exec(t, stmt)
+		}
+		sqlServer.FlushSQLStats(ctx)
+		for _, table := range []string{"system.statement_statistics", "system.transaction_statistics"} {
+			adminDB.CheckQueryResults(t,
+				`SELECT count(*) FROM `+table+` WHERE fingerprint_id = 'expired'`, [][]string{{"0"}})
+		}
+		// The unexpired statistics are retained.
+		require.Equal(t, 7, persistedCount())
+	})
+}
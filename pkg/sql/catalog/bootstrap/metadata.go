@@ -342,6 +342,14 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 
 	target.AddDescriptor(keys.SystemDatabaseID, systemschema.ScheduledJobsTable)
 	target.AddDescriptor(keys.SystemDatabaseID, systemschema.SqllivenessTable)
+
+	// Tables introduced in 21.1.
+
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.StatementStatisticsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.TransactionStatisticsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.RoleAuditPoliciesTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.PlanBaselinesTable)
+	target.AddDescriptor(keys.SystemDatabaseID, systemschema.SQLInstancesTable)
 }
 
 // addSplitIDs adds a split point for each of the PseudoTableIDs to the supplied
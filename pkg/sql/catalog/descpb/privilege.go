@@ -415,6 +415,11 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.StatementDiagnosticsTableID:          privilege.ReadWriteData,
 	keys.ScheduledJobsTableID:                 privilege.ReadWriteData,
 	keys.SqllivenessID:                        privilege.ReadWriteData,
+	keys.StatementStatisticsTableID:           privilege.ReadWriteData,
+	keys.TransactionStatisticsTableID:         privilege.ReadWriteData,
+	keys.RoleAuditPoliciesTableID:             privilege.ReadWriteData,
+	keys.PlanBaselinesTableID:                 privilege.ReadWriteData,
+	keys.SQLInstancesTableID:                  privilege.ReadWriteData,
 }
 
 // SetOwner sets the owner of the privilege descriptor to the provided string.
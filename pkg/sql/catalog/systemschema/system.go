@@ -218,8 +218,9 @@ CREATE TABLE system.table_statistics (
 	"distinctCount" INT8       NOT NULL,
 	"nullCount"     INT8       NOT NULL,
 	histogram       BYTES,
+	expression      STRING,
 	PRIMARY KEY ("tableID", "statisticID"),
-	FAMILY ("tableID", "statisticID", name, "columnIDs", "createdAt", "rowCount", "distinctCount", "nullCount", histogram)
+	FAMILY "fam_0_tableID_statisticID_name_columnIDs_createdAt_rowCount_distinctCount_nullCount_histogram" ("tableID", "statisticID", name, "columnIDs", "createdAt", "rowCount", "distinctCount", "nullCount", histogram, expression)
 );`
 
 	// locations are used to map a locality specified by a node to geographic
@@ -296,9 +297,12 @@ CREATE TABLE system.statement_diagnostics_requests(
 	statement_fingerprint STRING NOT NULL,
 	statement_diagnostics_id INT8,
 	requested_at TIMESTAMPTZ NOT NULL,
-	INDEX completed_idx (completed, id) STORING (statement_fingerprint),
+	min_execution_latency INTERVAL NULL,
+	expires_at TIMESTAMPTZ NULL,
+	sampling_probability FLOAT NULL,
+	INDEX completed_idx_v2 (completed, id) STORING (statement_fingerprint, min_execution_latency, expires_at, sampling_probability),
 
-	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at)
+	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at, min_execution_latency, expires_at, sampling_probability)
 );`
 
 	StatementDiagnosticsTableSchema = `
@@ -342,6 +346,77 @@ CREATE TABLE system.sqlliveness (
     expiration       DECIMAL NOT NULL,
   	FAMILY fam0_session_id_expiration (session_id, expiration)
 )`
+
+	// StatementStatisticsTableSchema stores the statement statistics flushed
+	// by each node, aggregated over time buckets of agg_interval.
+	StatementStatisticsTableSchema = `
+CREATE TABLE system.statement_statistics (
+    aggregated_ts  TIMESTAMPTZ NOT NULL,
+    fingerprint_id STRING NOT NULL,
+    app_name       STRING NOT NULL,
+    node_id        INT8 NOT NULL,
+    agg_interval   INTERVAL NOT NULL,
+    metadata       BYTES NOT NULL,
+    statistics     BYTES NOT NULL,
+    PRIMARY KEY (aggregated_ts, fingerprint_id, app_name, node_id),
+    FAMILY "primary" (
+        aggregated_ts, fingerprint_id, app_name, node_id,
+        agg_interval, metadata, statistics
+    )
+)`
+
+	// TransactionStatisticsTableSchema stores the transaction statistics
+	// flushed by each node, aggregated over time buckets of agg_interval.
+	TransactionStatisticsTableSchema = `
+CREATE TABLE system.transaction_statistics (
+    aggregated_ts  TIMESTAMPTZ NOT NULL,
+    fingerprint_id STRING NOT NULL,
+    app_name       STRING NOT NULL,
+    node_id        INT8 NOT NULL,
+    agg_interval   INTERVAL NOT NULL,
+    statement_ids  STRING[] NOT NULL,
+    statistics     BYTES NOT NULL,
+    PRIMARY KEY (aggregated_ts, fingerprint_id, app_name, node_id),
+    FAMILY "primary" (
+        aggregated_ts, fingerprint_id, app_name, node_id,
+        agg_interval, statement_ids, statistics
+    )
+)`
+
+	// RoleAuditPoliciesTableSchema stores the role-based SQL audit logging
+	// policies. A policy applies to the roles matching role_pattern, which is
+	// either the name of a role or a prefix followed by '*'.
+	RoleAuditPoliciesTableSchema = `
+CREATE TABLE system.role_audit_policies (
+    role_pattern STRING NOT NULL,
+    statements   STRING NOT NULL,
+    created      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (role_pattern),
+    FAMILY "primary" (role_pattern, statements, created)
+)`
+
+	// PlanBaselinesTableSchema stores the plan baselines, which are the plan
+	// hints applied to the statements with a given fingerprint.
+	PlanBaselinesTableSchema = `
+CREATE TABLE system.plan_baselines (
+    fingerprint STRING NOT NULL,
+    hints       STRING NOT NULL,
+    created     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (fingerprint),
+    FAMILY "primary" (fingerprint, hints, created)
+)`
+
+	// SQLInstancesTableSchema stores the SQL instances (pods) running on behalf
+	// of a tenant, along with the address of their RPC server and the
+	// sqlliveness session which keeps their ID allocated.
+	SQLInstancesTableSchema = `
+CREATE TABLE system.sql_instances (
+    id         INT NOT NULL,
+    addr       STRING,
+    session_id BYTES,
+    PRIMARY KEY (id),
+    FAMILY "primary" (id, addr, session_id)
+)`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -982,8 +1057,9 @@ var (
 			{Name: "distinctCount", ID: 7, Type: types.Int},
 			{Name: "nullCount", ID: 8, Type: types.Int},
 			{Name: "histogram", ID: 9, Type: types.Bytes, Nullable: true},
+			{Name: "expression", ID: 10, Type: types.String, Nullable: true},
 		},
-		NextColumnID: 10,
+		NextColumnID: 11,
 		Families: []descpb.ColumnFamilyDescriptor{
 			{
 				Name: "fam_0_tableID_statisticID_name_columnIDs_createdAt_rowCount_distinctCount_nullCount_histogram",
@@ -998,8 +1074,9 @@ var (
 					"distinctCount",
 					"nullCount",
 					"histogram",
+					"expression",
 				},
-				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
 			},
 		},
 		NextFamilyID: 1,
@@ -1532,13 +1609,17 @@ var (
 			{Name: "statement_fingerprint", ID: 3, Type: types.String, Nullable: false},
 			{Name: "statement_diagnostics_id", ID: 4, Type: types.Int, Nullable: true},
 			{Name: "requested_at", ID: 5, Type: types.TimestampTZ, Nullable: false},
+			{Name: "min_execution_latency", ID: 6, Type: types.Interval, Nullable: true},
+			{Name: "expires_at", ID: 7, Type: types.TimestampTZ, Nullable: true},
+			{Name: "sampling_probability", ID: 8, Type: types.Float, Nullable: true},
 		},
-		NextColumnID: 6,
+		NextColumnID: 9,
 		Families: []descpb.ColumnFamilyDescriptor{
 			{
-				Name:        "primary",
-				ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at"},
-				ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5},
+				Name: "primary",
+				ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at",
+					"min_execution_latency", "expires_at", "sampling_probability"},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8},
 			},
 		},
 		NextFamilyID: 1,
@@ -1546,14 +1627,14 @@ var (
 		// Index for the polling query.
 		Indexes: []descpb.IndexDescriptor{
 			{
-				Name:             "completed_idx",
+				Name:             "completed_idx_v2",
 				ID:               2,
 				Unique:           false,
 				ColumnNames:      []string{"completed", "id"},
-				StoreColumnNames: []string{"statement_fingerprint"},
+				StoreColumnNames: []string{"statement_fingerprint", "min_execution_latency", "expires_at", "sampling_probability"},
 				ColumnIDs:        []descpb.ColumnID{2, 1},
 				ColumnDirections: []descpb.IndexDescriptor_Direction{descpb.IndexDescriptor_ASC, descpb.IndexDescriptor_ASC},
-				StoreColumnIDs:   []descpb.ColumnID{3},
+				StoreColumnIDs:   []descpb.ColumnID{3, 6, 7, 8},
 				Version:          descpb.SecondaryIndexFamilyFormatVersion,
 			},
 		},
@@ -1684,8 +1765,194 @@ var (
 		FormatVersion:  descpb.InterleavedFormatVersion,
 		NextMutationID: 1,
 	})
+
+	// StatementStatisticsTable is the descriptor for the statement statistics
+	// table.
+	StatementStatisticsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "statement_statistics",
+		ID:                      keys.StatementStatisticsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "aggregated_ts", ID: 1, Type: types.TimestampTZ, Nullable: false},
+			{Name: "fingerprint_id", ID: 2, Type: types.String, Nullable: false},
+			{Name: "app_name", ID: 3, Type: types.String, Nullable: false},
+			{Name: "node_id", ID: 4, Type: types.Int, Nullable: false},
+			{Name: "agg_interval", ID: 5, Type: types.Interval, Nullable: false},
+			{Name: "metadata", ID: 6, Type: types.Bytes, Nullable: false},
+			{Name: "statistics", ID: 7, Type: types.Bytes, Nullable: false},
+		},
+		NextColumnID: 8,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ID:   0,
+				ColumnNames: []string{
+					"aggregated_ts", "fingerprint_id", "app_name", "node_id",
+					"agg_interval", "metadata", "statistics",
+				},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: sqlStatsPK(),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.StatementStatisticsTableID], security.NodeUser),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// TransactionStatisticsTable is the descriptor for the transaction
+	// statistics table.
+	TransactionStatisticsTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "transaction_statistics",
+		ID:                      keys.TransactionStatisticsTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "aggregated_ts", ID: 1, Type: types.TimestampTZ, Nullable: false},
+			{Name: "fingerprint_id", ID: 2, Type: types.String, Nullable: false},
+			{Name: "app_name", ID: 3, Type: types.String, Nullable: false},
+			{Name: "node_id", ID: 4, Type: types.Int, Nullable: false},
+			{Name: "agg_interval", ID: 5, Type: types.Interval, Nullable: false},
+			{Name: "statement_ids", ID: 6, Type: types.StringArray, Nullable: false},
+			{Name: "statistics", ID: 7, Type: types.Bytes, Nullable: false},
+		},
+		NextColumnID: 8,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ID:   0,
+				ColumnNames: []string{
+					"aggregated_ts", "fingerprint_id", "app_name", "node_id",
+					"agg_interval", "statement_ids", "statistics",
+				},
+				ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: sqlStatsPK(),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.TransactionStatisticsTableID], security.NodeUser),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// RoleAuditPoliciesTable is the descriptor for the role-based audit
+	// policies table.
+	RoleAuditPoliciesTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "role_audit_policies",
+		ID:                      keys.RoleAuditPoliciesTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "role_pattern", ID: 1, Type: types.String, Nullable: false},
+			{Name: "statements", ID: 2, Type: types.String, Nullable: false},
+			{Name: "created", ID: 3, Type: types.TimestampTZ, Nullable: false, DefaultExpr: &nowTZString},
+		},
+		NextColumnID: 4,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"role_pattern", "statements", "created"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("role_pattern"),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.RoleAuditPoliciesTableID], security.NodeUser),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// PlanBaselinesTable is the descriptor for the plan baselines table.
+	PlanBaselinesTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "plan_baselines",
+		ID:                      keys.PlanBaselinesTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "fingerprint", ID: 1, Type: types.String, Nullable: false},
+			{Name: "hints", ID: 2, Type: types.String, Nullable: false},
+			{Name: "created", ID: 3, Type: types.TimestampTZ, Nullable: false, DefaultExpr: &nowTZString},
+		},
+		NextColumnID: 4,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"fingerprint", "hints", "created"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("fingerprint"),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.PlanBaselinesTableID], security.NodeUser),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
+
+	// SQLInstancesTable is the descriptor for the SQL instances table.
+	SQLInstancesTable = tabledesc.NewImmutable(descpb.TableDescriptor{
+		Name:                    "sql_instances",
+		ID:                      keys.SQLInstancesTableID,
+		ParentID:                keys.SystemDatabaseID,
+		UnexposedParentSchemaID: keys.PublicSchemaID,
+		Version:                 1,
+		Columns: []descpb.ColumnDescriptor{
+			{Name: "id", ID: 1, Type: types.Int, Nullable: false},
+			{Name: "addr", ID: 2, Type: types.String, Nullable: true},
+			{Name: "session_id", ID: 3, Type: types.Bytes, Nullable: true},
+		},
+		NextColumnID: 4,
+		Families: []descpb.ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"id", "addr", "session_id"},
+				ColumnIDs:   []descpb.ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("id"),
+		NextIndexID:  2,
+		Privileges: descpb.NewCustomSuperuserPrivilegeDescriptor(
+			descpb.SystemAllowedPrivileges[keys.SQLInstancesTableID], security.NodeUser),
+		FormatVersion:  descpb.InterleavedFormatVersion,
+		NextMutationID: 1,
+	})
 )
 
+// sqlStatsPK returns the primary index of the statement and transaction
+// statistics tables.
+func sqlStatsPK() descpb.IndexDescriptor {
+	return descpb.IndexDescriptor{
+		Name:   "primary",
+		ID:     1,
+		Unique: true,
+		ColumnNames: []string{
+			"aggregated_ts", "fingerprint_id", "app_name", "node_id",
+		},
+		ColumnDirections: []descpb.IndexDescriptor_Direction{
+			descpb.IndexDescriptor_ASC, descpb.IndexDescriptor_ASC,
+			descpb.IndexDescriptor_ASC, descpb.IndexDescriptor_ASC,
+		},
+		ColumnIDs: []descpb.ColumnID{1, 2, 3, 4},
+		Version:   descpb.SecondaryIndexFamilyFormatVersion,
+	}
+}
+
 // newCommentPrivilegeDescriptor returns a privilege descriptor for comment table
 func newCommentPrivilegeDescriptor(priv privilege.List, owner string) *descpb.PrivilegeDescriptor {
 	selectPriv := privilege.List{privilege.SELECT}
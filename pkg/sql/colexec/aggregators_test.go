@@ -99,7 +99,7 @@ var aggTypes = []aggType{
 			outputTypes []*types.T,
 			_ bool,
 		) (colexecbase.Operator, error) {
-			return NewHashAggregator(allocator, memAccount, input, inputTypes, spec, evalCtx, constructors, constArguments, outputTypes)
+			return NewHashAggregator(allocator, memAccount, input, inputTypes, spec, evalCtx, constructors, constArguments, outputTypes, nil /* newSpillingQueueArgs */)
 		},
 		name: "hash",
 	},
@@ -1173,118 +1173,121 @@ func BenchmarkDistinctAggregation(b *testing.B) {
 	}
 }
 
-func TestHashAggregator(t *testing.T) {
-	defer leaktest.AfterTest(t)()
-	defer log.Scope(t).Close(t)
-	tcs := []aggregatorTestCase{
-		{
-			// Test carry between output batches.
-			input: tuples{
-				{0, 1},
-				{1, 5},
-				{0, 4},
-				{0, 2},
-				{2, 6},
-				{0, 3},
-				{0, 7},
-			},
-			typs:      []*types.T{types.Int, types.Int},
-			groupCols: []uint32{0},
-			aggCols:   [][]uint32{{1}},
-
-			expected: tuples{
-				{5},
-				{6},
-				{17},
-			},
+// hashAggregatorTestCases are the test cases that exercise the behavior
+// specific to the hash aggregation.
+var hashAggregatorTestCases = []aggregatorTestCase{
+	{
+		// Test carry between output batches.
+		input: tuples{
+			{0, 1},
+			{1, 5},
+			{0, 4},
+			{0, 2},
+			{2, 6},
+			{0, 3},
+			{0, 7},
+		},
+		typs:      []*types.T{types.Int, types.Int},
+		groupCols: []uint32{0},
+		aggCols:   [][]uint32{{1}},
 
-			name: "carryBetweenBatches",
+		expected: tuples{
+			{5},
+			{6},
+			{17},
 		},
-		{
-			// Test a single row input source.
-			input: tuples{
-				{5},
-			},
-			typs:      []*types.T{types.Int},
-			groupCols: []uint32{0},
-			aggCols:   [][]uint32{{0}},
 
-			expected: tuples{
-				{5},
-			},
+		name: "carryBetweenBatches",
+	},
+	{
+		// Test a single row input source.
+		input: tuples{
+			{5},
+		},
+		typs:      []*types.T{types.Int},
+		groupCols: []uint32{0},
+		aggCols:   [][]uint32{{0}},
 
-			name: "singleRowInput",
+		expected: tuples{
+			{5},
 		},
-		{
-			// Test bucket collisions.
-			input: tuples{
-				{0, 3},
-				{0, 4},
-				{coldata.BatchSize(), 6},
-				{0, 5},
-				{coldata.BatchSize(), 7},
-			},
-			typs:      []*types.T{types.Int, types.Int},
-			groupCols: []uint32{0},
-			aggCols:   [][]uint32{{1}},
 
-			expected: tuples{
-				{12},
-				{13},
-			},
+		name: "singleRowInput",
+	},
+	{
+		// Test bucket collisions.
+		input: tuples{
+			{0, 3},
+			{0, 4},
+			{coldata.BatchSize(), 6},
+			{0, 5},
+			{coldata.BatchSize(), 7},
+		},
+		typs:      []*types.T{types.Int, types.Int},
+		groupCols: []uint32{0},
+		aggCols:   [][]uint32{{1}},
 
-			name: "bucketCollision",
+		expected: tuples{
+			{12},
+			{13},
 		},
-		{
-			input: tuples{
-				{0, 1, 1.3},
-				{0, 1, 1.6},
-				{0, 1, 0.5},
-				{1, 1, 1.2},
-			},
-			typs:          []*types.T{types.Int, types.Int, types.Decimal},
-			convToDecimal: true,
 
-			aggFns:    []execinfrapb.AggregatorSpec_Func{execinfrapb.AggregatorSpec_SUM, execinfrapb.AggregatorSpec_SUM_INT},
-			groupCols: []uint32{0, 1},
-			aggCols: [][]uint32{
-				{2}, {1},
-			},
+		name: "bucketCollision",
+	},
+	{
+		input: tuples{
+			{0, 1, 1.3},
+			{0, 1, 1.6},
+			{0, 1, 0.5},
+			{1, 1, 1.2},
+		},
+		typs:          []*types.T{types.Int, types.Int, types.Decimal},
+		convToDecimal: true,
 
-			expected: tuples{
-				{3.4, 3},
-				{1.2, 1},
-			},
+		aggFns:    []execinfrapb.AggregatorSpec_Func{execinfrapb.AggregatorSpec_SUM, execinfrapb.AggregatorSpec_SUM_INT},
+		groupCols: []uint32{0, 1},
+		aggCols: [][]uint32{
+			{2}, {1},
+		},
 
-			name: "decimalSums",
+		expected: tuples{
+			{3.4, 3},
+			{1.2, 1},
 		},
-		{
-			// Test unused input columns.
-			input: tuples{
-				{0, 1, 2, 3},
-				{0, 1, 4, 5},
-				{1, 1, 3, 7},
-				{1, 2, 4, 9},
-				{0, 1, 6, 11},
-				{1, 2, 6, 13},
-			},
-			typs:      []*types.T{types.Int, types.Int, types.Int, types.Int},
-			groupCols: []uint32{0, 1},
-			aggCols:   [][]uint32{{3}},
 
-			expected: tuples{
-				{7},
-				{19},
-				{22},
-			},
+		name: "decimalSums",
+	},
+	{
+		// Test unused input columns.
+		input: tuples{
+			{0, 1, 2, 3},
+			{0, 1, 4, 5},
+			{1, 1, 3, 7},
+			{1, 2, 4, 9},
+			{0, 1, 6, 11},
+			{1, 2, 6, 13},
+		},
+		typs:      []*types.T{types.Int, types.Int, types.Int, types.Int},
+		groupCols: []uint32{0, 1},
+		aggCols:   [][]uint32{{3}},
 
-			name: "unusedInputCol",
+		expected: tuples{
+			{7},
+			{19},
+			{22},
 		},
-	}
+
+		name: "unusedInputCol",
+	},
+}
+
+func TestHashAggregator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
 
 	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
 	defer evalCtx.Stop(context.Background())
-	for _, tc := range tcs {
+	for _, tc := range hashAggregatorTestCases {
 		if err := tc.init(); err != nil {
 			t.Fatal(err)
 		}
@@ -1295,7 +1298,7 @@ func TestHashAggregator(t *testing.T) {
 		runTests(t, []tuples{tc.input}, tc.expected, unorderedVerifier, func(sources []colexecbase.Operator) (colexecbase.Operator, error) {
 			return NewHashAggregator(
 				testAllocator, testMemAcc, sources[0], tc.typs, tc.spec,
-				&evalCtx, constructors, constArguments, outputTypes,
+				&evalCtx, constructors, constArguments, outputTypes, nil, /* newSpillingQueueArgs */
 			)
 		})
 	}
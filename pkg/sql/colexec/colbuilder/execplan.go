@@ -198,6 +198,21 @@ func isSupported(mode sessiondata.VectorizeExecMode, spec *execinfrapb.Processor
 	case core.Ordinality != nil:
 		return nil
 
+	case core.ProjectSet != nil:
+		return nil
+
+	case core.ZigzagJoiner != nil:
+		if len(core.ZigzagJoiner.Tables) != 2 {
+			return errors.Newf("zigzag joins of %d tables are not supported", len(core.ZigzagJoiner.Tables))
+		}
+		if core.ZigzagJoiner.Type != descpb.InnerJoin {
+			return errors.Newf("non-inner zigzag joins are not supported")
+		}
+		return nil
+
+	case core.InvertedJoiner != nil:
+		return nil
+
 	case core.HashJoiner != nil:
 		if !core.HashJoiner.OnExpr.Empty() && core.HashJoiner.Type != descpb.InnerJoin {
 			return errors.Newf("can't plan vectorized non-inner hash joins with ON expressions")
@@ -402,6 +417,39 @@ func (r opResult) createDiskBackedSort(
 	), nil
 }
 
+// makeDiskBackedSorterConstructor returns a function that creates a
+// disk-backed sorter to be used by the disk-backed operators that fall back to
+// sorting (like the external hash joiner). If FD acquisitions are not
+// delegated, the disk-backed operator acquires all FDs up front, so the sorter
+// must not acquire any.
+func (r opResult) makeDiskBackedSorterConstructor(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	args *colexec.NewColOperatorArgs,
+	processorID int32,
+	memMonitorNamePrefix string,
+	factory coldata.ColumnFactory,
+) func(colexecbase.Operator, []*types.T, []execinfrapb.Ordering_Column, int) (colexecbase.Operator, error) {
+	return func(
+		input colexecbase.Operator,
+		inputTypes []*types.T,
+		orderingCols []execinfrapb.Ordering_Column,
+		maxNumberPartitions int,
+	) (colexecbase.Operator, error) {
+		sortArgs := *args
+		if !args.TestingKnobs.DelegateFDAcquisitions {
+			// Set the FDSemaphore to nil. This indicates that no FDs should be
+			// acquired. The disk-backed operator will do this up front.
+			sortArgs.FDSemaphore = nil
+		}
+		return r.createDiskBackedSort(
+			ctx, flowCtx, &sortArgs, input, inputTypes,
+			execinfrapb.Ordering{Columns: orderingCols},
+			0 /* matchLen */, maxNumberPartitions, processorID,
+			&execinfrapb.PostProcessSpec{}, memMonitorNamePrefix, factory)
+	}
+}
+
 // createAndWrapRowSource takes a processor spec, creating the row source and
 // wrapping it using wrapRowSources. Note that the post process spec is included
 // in the processor creation, so make sure to clear it if it will be inspected
@@ -690,22 +738,113 @@ func NewColOperator(
 			}
 
 			if needHash {
-				hashAggregatorMemAccount := streamingMemAccount
-				if !useStreamingMemAccountForBuffering {
-					// Create an unlimited mem account explicitly even though there is no
-					// disk spilling because the memory usage of an aggregator is
-					// proportional to the number of groups, not the number of inputs.
-					// The row execution engine also gives an unlimited (that still
-					// needs to be approved by the upstream monitor, so not really
-					// "unlimited") amount of memory to the aggregator.
-					hashAggregatorMemAccount = result.createBufferingUnlimitedMemAccount(ctx, flowCtx, "hash-aggregator")
+				hashAggregatorMemMonitorName := fmt.Sprintf("hash-aggregator-%d", spec.ProcessorID)
+				// The fallback strategy of the external hash aggregator is the
+				// ordered aggregator which doesn't support FILTER clauses, so
+				// we refuse to spill to disk if there are any filtering
+				// aggregate functions. The memory usage of the in-memory hash
+				// aggregator is proportional to the number of groups, and the
+				// row execution engine doesn't spill to disk either.
+				hasFilteringAgg := false
+				for _, aggFn := range aggSpec.Aggregations {
+					if aggFn.FilterColIdx != nil {
+						hasFilteringAgg = true
+					}
+				}
+				if useStreamingMemAccountForBuffering || args.TestingKnobs.DiskSpillingDisabled || hasFilteringAgg {
+					hashAggregatorMemAccount := streamingMemAccount
+					if !useStreamingMemAccountForBuffering {
+						// We will not be creating a disk-backed hash aggregator
+						// because either we're running a test that explicitly
+						// asked for only in-memory hash aggregator or there are
+						// filtering aggregate functions, so we create an
+						// unlimited mem account.
+						hashAggregatorMemAccount = result.createBufferingUnlimitedMemAccount(
+							ctx, flowCtx, hashAggregatorMemMonitorName,
+						)
+					}
+					evalCtx.SingleDatumAggMemAccount = hashAggregatorMemAccount
+					result.Op, err = colexec.NewHashAggregator(
+						colmem.NewAllocator(ctx, hashAggregatorMemAccount, factory),
+						hashAggregatorMemAccount, inputs[0], inputTypes, aggSpec,
+						evalCtx, constructors, constArguments, result.ColumnTypes,
+						nil, /* newSpillingQueueArgs */
+					)
+				} else {
+					hashAggregatorMemAccount := result.createMemAccountForSpillStrategy(
+						ctx, flowCtx, hashAggregatorMemMonitorName,
+					)
+					evalCtx.SingleDatumAggMemAccount = hashAggregatorMemAccount
+					// The in-memory hash aggregator tracks all of the input tuples
+					// in a spilling queue in order to be able to export them once
+					// the memory limit is reached. Note that the name of the
+					// monitor must not contain hashAggregatorMemMonitorName so
+					// that the disk spiller doesn't mistake the errors of the
+					// spilling queue for the ones of the in-memory hash
+					// aggregator.
+					inputTrackingMonitorName := fmt.Sprintf("hash-aggregator-input-%d", spec.ProcessorID)
+					newSpillingQueueArgs := &colexec.NewSpillingQueueArgs{
+						UnlimitedAllocator: colmem.NewAllocator(
+							ctx, result.createBufferingUnlimitedMemAccount(ctx, flowCtx, inputTrackingMonitorName), factory,
+						),
+						MemoryLimit:  execinfra.GetWorkMemLimit(flowCtx.Cfg),
+						DiskQueueCfg: args.DiskQueueCfg,
+						FDSemaphore:  args.FDSemaphore,
+						DiskAcc:      result.createDiskAccount(ctx, flowCtx, inputTrackingMonitorName),
+					}
+					var inMemoryHashAggregator colexecbase.Operator
+					inMemoryHashAggregator, err = colexec.NewHashAggregator(
+						colmem.NewAllocator(ctx, hashAggregatorMemAccount, factory),
+						hashAggregatorMemAccount, inputs[0], inputTypes, aggSpec,
+						evalCtx, constructors, constArguments, result.ColumnTypes,
+						newSpillingQueueArgs,
+					)
+					if err != nil {
+						return r, err
+					}
+					// The disk spiller closes only the disk-backed operator, so
+					// we need to close the in-memory hash aggregator separately.
+					result.ToClose = append(result.ToClose, inMemoryHashAggregator.(colexec.Closer))
+					diskAccount := result.createDiskAccount(ctx, flowCtx, hashAggregatorMemMonitorName)
+					result.Op = colexec.NewOneInputDiskSpiller(
+						inputs[0], inMemoryHashAggregator.(colexecbase.BufferingInMemoryOperator),
+						hashAggregatorMemMonitorName,
+						func(input colexecbase.Operator) colexecbase.Operator {
+							monitorNamePrefix := "external-hash-aggregator"
+							unlimitedMemAccount := result.createBufferingUnlimitedMemAccount(
+								ctx, flowCtx, monitorNamePrefix,
+							)
+							// The external hash aggregator is responsible for
+							// staying within the memory limit, so the aggregate
+							// functions that use datums need an unlimited account
+							// as well.
+							ehaEvalCtx := flowCtx.NewEvalCtx()
+							ehaEvalCtx.SingleDatumAggMemAccount = unlimitedMemAccount
+							// Make a copy of the DiskQueueCfg and set defaults for
+							// the hash aggregator. The cache mode is chosen to
+							// automatically close the cache belonging to partitions
+							// at a parent level when repartitioning.
+							diskQueueCfg := args.DiskQueueCfg
+							diskQueueCfg.CacheMode = colcontainer.DiskQueueCacheModeClearAndReuseCache
+							diskQueueCfg.SetDefaultBufferSizeBytesForCacheMode()
+							return colexec.NewExternalHashAggregator(
+								colmem.NewAllocator(ctx, unlimitedMemAccount, factory),
+								unlimitedMemAccount, input, inputTypes, aggSpec, ehaEvalCtx,
+								constructors, constArguments, result.ColumnTypes,
+								execinfra.GetWorkMemLimit(flowCtx.Cfg),
+								diskQueueCfg,
+								args.FDSemaphore,
+								result.makeDiskBackedSorterConstructor(
+									ctx, flowCtx, args, spec.ProcessorID, monitorNamePrefix+"-", factory,
+								),
+								args.TestingKnobs.NumForcedRepartitions,
+								args.TestingKnobs.DelegateFDAcquisitions,
+								diskAccount,
+							)
+						},
+						args.TestingKnobs.SpillingCallbackFn,
+					)
 				}
-				evalCtx.SingleDatumAggMemAccount = hashAggregatorMemAccount
-				result.Op, err = colexec.NewHashAggregator(
-					colmem.NewAllocator(ctx, hashAggregatorMemAccount, factory),
-					hashAggregatorMemAccount, inputs[0], inputTypes, aggSpec,
-					evalCtx, constructors, constArguments, result.ColumnTypes,
-				)
 			} else {
 				evalCtx.SingleDatumAggMemAccount = streamingMemAccount
 				result.Op, err = colexec.NewOrderedAggregator(
@@ -726,25 +865,73 @@ func NewColOperator(
 				result.Op, err = colexec.NewOrderedDistinct(inputs[0], core.Distinct.OrderedColumns, result.ColumnTypes)
 				result.IsStreaming = true
 			} else {
-				distinctMemAccount := streamingMemAccount
-				if !useStreamingMemAccountForBuffering {
-					// Create an unlimited mem account explicitly even though there is no
-					// disk spilling because the memory usage of an unordered distinct
-					// operator is proportional to the number of distinct tuples, not the
-					// number of input tuples.
-					// The row execution engine also gives an unlimited amount (that still
-					// needs to be approved by the upstream monitor, so not really
-					// "unlimited") amount of memory to the unordered distinct operator.
-					distinctMemAccount = result.createBufferingUnlimitedMemAccount(ctx, flowCtx, "distinct")
+				distinctMemMonitorName := fmt.Sprintf("distinct-%d", spec.ProcessorID)
+				var distinctMemAccount *mon.BoundAccount
+				var distinctUnlimitedAllocator *colmem.Allocator
+				if useStreamingMemAccountForBuffering {
+					distinctMemAccount = streamingMemAccount
+					distinctUnlimitedAllocator = streamingAllocator
+				} else {
+					distinctMemAccount = result.createMemAccountForSpillStrategy(
+						ctx, flowCtx, distinctMemMonitorName,
+					)
+					distinctUnlimitedAllocator = colmem.NewAllocator(
+						ctx, result.createBufferingUnlimitedMemAccount(ctx, flowCtx, distinctMemMonitorName), factory,
+					)
 				}
 				// TODO(yuzefovich): we have an implementation of partially ordered
 				// distinct, and we should plan it when we have non-empty ordered
 				// columns and we think that the probability of distinct tuples in the
 				// input is about 0.01 or less.
-				result.Op = colexec.NewUnorderedDistinct(
-					colmem.NewAllocator(ctx, distinctMemAccount, factory), inputs[0],
-					core.Distinct.DistinctColumns, result.ColumnTypes,
-				)
+				if args.TestingKnobs.DiskSpillingDisabled {
+					// We will not be creating a disk-backed unordered distinct
+					// because we're running a test that explicitly asked for only
+					// in-memory unordered distinct.
+					result.Op = colexec.NewUnorderedDistinct(
+						distinctUnlimitedAllocator, distinctUnlimitedAllocator, inputs[0],
+						core.Distinct.DistinctColumns, result.ColumnTypes,
+					)
+				} else {
+					inMemoryUnorderedDistinct := colexec.NewUnorderedDistinct(
+						colmem.NewAllocator(ctx, distinctMemAccount, factory),
+						distinctUnlimitedAllocator, inputs[0],
+						core.Distinct.DistinctColumns, result.ColumnTypes,
+					)
+					diskAccount := result.createDiskAccount(ctx, flowCtx, distinctMemMonitorName)
+					result.Op = colexec.NewOneInputDiskSpiller(
+						inputs[0], inMemoryUnorderedDistinct.(colexecbase.BufferingInMemoryOperator),
+						distinctMemMonitorName,
+						func(input colexecbase.Operator) colexecbase.Operator {
+							monitorNamePrefix := "external-distinct"
+							unlimitedAllocator := colmem.NewAllocator(
+								ctx, result.createBufferingUnlimitedMemAccount(ctx, flowCtx, monitorNamePrefix), factory,
+							)
+							// Make a copy of the DiskQueueCfg and set defaults for
+							// the unordered distinct. The cache mode is chosen to
+							// automatically close the cache belonging to partitions
+							// at a parent level when repartitioning.
+							diskQueueCfg := args.DiskQueueCfg
+							diskQueueCfg.CacheMode = colcontainer.DiskQueueCacheModeClearAndReuseCache
+							diskQueueCfg.SetDefaultBufferSizeBytesForCacheMode()
+							ed := colexec.NewExternalDistinct(
+								unlimitedAllocator, input,
+								core.Distinct.DistinctColumns, result.ColumnTypes,
+								execinfra.GetWorkMemLimit(flowCtx.Cfg),
+								diskQueueCfg,
+								args.FDSemaphore,
+								result.makeDiskBackedSorterConstructor(
+									ctx, flowCtx, args, spec.ProcessorID, monitorNamePrefix+"-", factory,
+								),
+								args.TestingKnobs.NumForcedRepartitions,
+								args.TestingKnobs.DelegateFDAcquisitions,
+								diskAccount,
+							)
+							result.ToClose = append(result.ToClose, ed.(colexec.Closer))
+							return ed
+						},
+						args.TestingKnobs.SpillingCallbackFn,
+					)
+				}
 			}
 
 		case core.Ordinality != nil:
@@ -756,6 +943,99 @@ func NewColOperator(
 			result.IsStreaming = true
 			result.ColumnTypes = appendOneType(spec.Input[0].ColumnTypes, types.Int)
 
+		case core.ProjectSet != nil:
+			if err := checkNumIn(inputs, 1); err != nil {
+				return r, err
+			}
+			inputTypes := make([]*types.T, len(spec.Input[0].ColumnTypes))
+			copy(inputTypes, spec.Input[0].ColumnTypes)
+			if err = result.planProjectSetOp(
+				ctx, flowCtx, args, core.ProjectSet, inputs[0], inputTypes, streamingAllocator, factory,
+			); err != nil {
+				// Not all scalar expressions and arguments of the
+				// set-returning functions can be planned as vectorized
+				// projections, so we fall back to wrapping the row-by-row
+				// processor.
+				log.VEventf(ctx, 1, "planning a wrapped ProjectSet processor because %s", err.Error())
+				result.resetToState(ctx, resultPreSpecPlanningStateShallowCopy)
+				err = result.createAndWrapRowSource(
+					ctx, flowCtx, args, inputs, [][]*types.T{inputTypes}, spec, factory, err,
+				)
+				// The wrapped processor handles the post-processing spec itself.
+				post = &execinfrapb.PostProcessSpec{}
+			}
+
+		case core.ZigzagJoiner != nil:
+			if err := checkNumIn(inputs, 0); err != nil {
+				return r, err
+			}
+			monitorName := "zigzag-joiner"
+			// We are using an unlimited memory monitor here because the merge
+			// joiner that joins the sides of the zigzag join is responsible for
+			// making sure that we stay within the memory limit, and it will fall
+			// back to disk if necessary.
+			unlimitedAllocator := colmem.NewAllocator(
+				ctx, result.createBufferingUnlimitedMemAccount(ctx, flowCtx, monitorName), factory,
+			)
+			diskAccount := result.createDiskAccount(ctx, flowCtx, monitorName)
+			zigzagOp, err := colfetcher.NewColZigzagJoiner(
+				streamingAllocator, unlimitedAllocator, flowCtx, core.ZigzagJoiner, post,
+				execinfra.GetWorkMemLimit(flowCtx.Cfg), args.DiskQueueCfg, args.FDSemaphore,
+				diskAccount,
+			)
+			if err != nil {
+				return r, err
+			}
+			result.Op = zigzagOp
+			result.IOReader = zigzagOp
+			result.MetadataSources = append(result.MetadataSources, zigzagOp)
+			result.ToClose = append(result.ToClose, zigzagOp)
+			// Similarly to colBatchScan, the zigzag joiner is wrapped with a
+			// cancel checker below, so we need to log its creation separately.
+			log.VEventf(ctx, 1, "made op %T\n", result.Op)
+			result.Op = colexec.NewCancelChecker(result.Op)
+			result.ColumnTypes = zigzagOp.ResultTypes
+
+			if !core.ZigzagJoiner.OnExpr.Empty() {
+				if err = result.planAndMaybeWrapOnExprAsFilter(
+					ctx, flowCtx, args, core.ZigzagJoiner.OnExpr, factory,
+				); err != nil {
+					return r, err
+				}
+			}
+
+		case core.InvertedJoiner != nil:
+			if err := checkNumIn(inputs, 1); err != nil {
+				return r, err
+			}
+			inputTypes := make([]*types.T, len(spec.Input[0].ColumnTypes))
+			copy(inputTypes, spec.Input[0].ColumnTypes)
+			// The inverted joiner de-duplicates the primary keys retrieved from
+			// the inverted index using a row container that can spill to disk.
+			invertedJoinerMonitorName := fmt.Sprintf("inverted-joiner-%d", spec.ProcessorID)
+			memMonitor := execinfra.NewLimitedMonitor(
+				ctx, flowCtx.EvalCtx.Mon, flowCtx.Cfg, invertedJoinerMonitorName+"-limited",
+			)
+			diskMonitor := execinfra.NewMonitor(ctx, flowCtx.Cfg.DiskMonitor, invertedJoinerMonitorName)
+			result.OpMonitors = append(result.OpMonitors, memMonitor, diskMonitor)
+			invertedJoinerOp, err := colfetcher.NewColInvertedJoiner(
+				streamingAllocator, flowCtx, core.InvertedJoiner, post, inputs[0], inputTypes,
+				memMonitor, diskMonitor,
+			)
+			if err != nil {
+				return r, err
+			}
+			result.Op = invertedJoinerOp
+			result.IOReader = invertedJoinerOp
+			result.MetadataSources = append(result.MetadataSources, invertedJoinerOp)
+			result.ToClose = append(result.ToClose, invertedJoinerOp)
+			// The inverted joiner performs potentially long-running index scans
+			// for each batch of input rows, so we wrap it with a cancel checker
+			// too.
+			log.VEventf(ctx, 1, "made op %T\n", result.Op)
+			result.Op = colexec.NewCancelChecker(result.Op)
+			result.ColumnTypes = invertedJoinerOp.ResultTypes
+
 		case core.HashJoiner != nil:
 			if err := checkNumIn(inputs, 2); err != nil {
 				return r, err
@@ -827,20 +1107,9 @@ func NewColOperator(
 							execinfra.GetWorkMemLimit(flowCtx.Cfg),
 							diskQueueCfg,
 							args.FDSemaphore,
-							func(input colexecbase.Operator, inputTypes []*types.T, orderingCols []execinfrapb.Ordering_Column, maxNumberPartitions int) (colexecbase.Operator, error) {
-								sortArgs := *args
-								if !args.TestingKnobs.DelegateFDAcquisitions {
-									// Set the FDSemaphore to nil. This indicates that no FDs
-									// should be acquired. The external hash joiner will do this
-									// up front.
-									sortArgs.FDSemaphore = nil
-								}
-								return result.createDiskBackedSort(
-									ctx, flowCtx, &sortArgs, input, inputTypes,
-									execinfrapb.Ordering{Columns: orderingCols},
-									0 /* matchLen */, maxNumberPartitions, spec.ProcessorID,
-									&execinfrapb.PostProcessSpec{}, monitorNamePrefix+"-", factory)
-							},
+							result.makeDiskBackedSorterConstructor(
+								ctx, flowCtx, args, spec.ProcessorID, monitorNamePrefix+"-", factory,
+							),
 							args.TestingKnobs.NumForcedRepartitions,
 							args.TestingKnobs.DelegateFDAcquisitions,
 							diskAccount,
@@ -1116,6 +1385,81 @@ func NewColOperator(
 	return r, err
 }
 
+// planProjectSetOp plans the vectorized ProjectSet operator on top of input.
+// The scalar expressions and the arguments of the set-returning functions are
+// planned as projections. An error is returned if any of the expressions
+// can't be executed by the vectorized engine.
+func (r opResult) planProjectSetOp(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	args *colexec.NewColOperatorArgs,
+	spec *execinfrapb.ProjectSetSpec,
+	input colexecbase.Operator,
+	inputTypes []*types.T,
+	streamingAllocator *colmem.Allocator,
+	factory coldata.ColumnFactory,
+) error {
+	semaCtx := flowCtx.TypeResolverFactory.NewSemaContext(flowCtx.EvalCtx.Txn)
+	evalCtx := flowCtx.NewEvalCtx()
+	typs := inputTypes
+	exprs := make([]tree.TypedExpr, len(spec.Exprs))
+	exprCols := make([][]int, len(spec.Exprs))
+	for i := range spec.Exprs {
+		expr, err := args.ExprHelper.ProcessExpr(spec.Exprs[i], semaCtx, flowCtx.EvalCtx, inputTypes)
+		if err != nil {
+			return err
+		}
+		exprs[i] = expr
+		var internalMemUsed, resultIdx int
+		if funcExpr, ok := expr.(*tree.FuncExpr); ok && funcExpr.IsGeneratorApplication() {
+			// Only the specialized generators operate on the physical
+			// representation of the arguments directly.
+			specialized := funcExpr.ResolvedOverload().SpecializedVecBuiltin != 0
+			for _, arg := range funcExpr.Exprs {
+				input, resultIdx, typs, internalMemUsed, err = planProjectionOperators(
+					ctx, evalCtx, arg.(tree.TypedExpr), typs, input, args.StreamingMemAccount, factory,
+				)
+				if err != nil {
+					return err
+				}
+				r.InternalMemUsage += internalMemUsed
+				if argType := typs[resultIdx]; specialized && argType.Family() == types.IntFamily && argType.Width() != 64 {
+					// The vectorized generators operate on INT8 arguments only.
+					input, resultIdx, typs, err = planCastOperator(
+						ctx, args.StreamingMemAccount, typs, input, resultIdx, argType, types.Int, factory,
+					)
+					if err != nil {
+						return err
+					}
+				}
+				exprCols[i] = append(exprCols[i], resultIdx)
+			}
+		} else {
+			input, resultIdx, typs, internalMemUsed, err = planProjectionOperators(
+				ctx, evalCtx, expr, typs, input, args.StreamingMemAccount, factory,
+			)
+			if err != nil {
+				return err
+			}
+			r.InternalMemUsage += internalMemUsed
+			exprCols[i] = []int{resultIdx}
+		}
+	}
+	op, err := colexec.NewProjectSetOp(
+		streamingAllocator, input, typs, len(inputTypes), evalCtx, exprs, exprCols,
+		spec.GeneratedColumns, spec.NumColsPerGen,
+	)
+	if err != nil {
+		return err
+	}
+	r.Op, r.IsStreaming = op, true
+	r.ToClose = append(r.ToClose, op.(colexec.Closer))
+	r.ColumnTypes = make([]*types.T, 0, len(inputTypes)+len(spec.GeneratedColumns))
+	r.ColumnTypes = append(r.ColumnTypes, inputTypes...)
+	r.ColumnTypes = append(r.ColumnTypes, spec.GeneratedColumns...)
+	return nil
+}
+
 // planAndMaybeWrapOnExprAsFilter plans a joiner ON expression as a filter. If
 // the filter is unsupported, it is planned as a wrapped noop processor with
 // the filter as a post-processing stage.
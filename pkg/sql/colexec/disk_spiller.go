@@ -320,7 +320,7 @@ func (b *bufferExportingOperator) Next(ctx context.Context) coldata.Batch {
 	if b.firstSourceDone {
 		return b.secondSource.Next(ctx)
 	}
-	batch := b.firstSource.ExportBuffered(b.secondSource)
+	batch := b.firstSource.ExportBuffered(ctx, b.secondSource)
 	if batch.Length() == 0 {
 		b.firstSourceDone = true
 		return b.secondSource.Next(ctx)
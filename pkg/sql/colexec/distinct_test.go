@@ -25,171 +25,173 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 )
 
-func TestDistinct(t *testing.T) {
-	defer leaktest.AfterTest(t)()
-	defer log.Scope(t).Close(t)
-	rng, _ := randutil.NewPseudoRand()
-	tcs := []struct {
-		distinctCols            []uint32
-		typs                    []*types.T
-		tuples                  []tuple
-		expected                []tuple
-		isOrderedOnDistinctCols bool
-	}{
-		{
-			distinctCols: []uint32{0, 1, 2},
-			typs:         []*types.T{types.Float, types.Int, types.String, types.Int},
-			tuples: tuples{
-				{nil, nil, nil, nil},
-				{nil, nil, nil, nil},
-				{nil, nil, "30", nil},
-				{1.0, 2, "30", 4},
-				{1.0, 2, "30", 4},
-				{2.0, 2, "30", 4},
-				{2.0, 3, "30", 4},
-				{2.0, 3, "40", 4},
-				{2.0, 3, "40", 4},
-			},
-			expected: tuples{
-				{nil, nil, nil, nil},
-				{nil, nil, "30", nil},
-				{1.0, 2, "30", 4},
-				{2.0, 2, "30", 4},
-				{2.0, 3, "30", 4},
-				{2.0, 3, "40", 4},
-			},
-			isOrderedOnDistinctCols: true,
+type distinctTestCase struct {
+	distinctCols            []uint32
+	typs                    []*types.T
+	tuples                  []tuple
+	expected                []tuple
+	isOrderedOnDistinctCols bool
+}
+
+var distinctTestCases = []distinctTestCase{
+	{
+		distinctCols: []uint32{0, 1, 2},
+		typs:         []*types.T{types.Float, types.Int, types.String, types.Int},
+		tuples: tuples{
+			{nil, nil, nil, nil},
+			{nil, nil, nil, nil},
+			{nil, nil, "30", nil},
+			{1.0, 2, "30", 4},
+			{1.0, 2, "30", 4},
+			{2.0, 2, "30", 4},
+			{2.0, 3, "30", 4},
+			{2.0, 3, "40", 4},
+			{2.0, 3, "40", 4},
 		},
-		{
-			distinctCols: []uint32{1, 0, 2},
-			typs:         []*types.T{types.Float, types.Int, types.Bytes, types.Int},
-			tuples: tuples{
-				{nil, nil, nil, nil},
-				{nil, nil, nil, nil},
-				{nil, nil, "30", nil},
-				{1.0, 2, "30", 4},
-				{1.0, 2, "30", 4},
-				{2.0, 2, "30", 4},
-				{2.0, 3, "30", 4},
-				{2.0, 3, "40", 4},
-				{2.0, 3, "40", 4},
-			},
-			expected: tuples{
-				{nil, nil, nil, nil},
-				{nil, nil, "30", nil},
-				{1.0, 2, "30", 4},
-				{2.0, 2, "30", 4},
-				{2.0, 3, "30", 4},
-				{2.0, 3, "40", 4},
-			},
-			isOrderedOnDistinctCols: true,
+		expected: tuples{
+			{nil, nil, nil, nil},
+			{nil, nil, "30", nil},
+			{1.0, 2, "30", 4},
+			{2.0, 2, "30", 4},
+			{2.0, 3, "30", 4},
+			{2.0, 3, "40", 4},
 		},
-		{
-			distinctCols: []uint32{0, 1, 2},
-			typs:         []*types.T{types.Float, types.Int, types.String, types.Int},
-			tuples: tuples{
-				{1.0, 2, "30", 4},
-				{1.0, 2, "30", 4},
-				{nil, nil, nil, nil},
-				{nil, nil, nil, nil},
-				{2.0, 2, "30", 4},
-				{2.0, 3, "30", 4},
-				{nil, nil, "30", nil},
-				{2.0, 3, "40", 4},
-				{2.0, 3, "40", 4},
-			},
-			expected: tuples{
-				{1.0, 2, "30", 4},
-				{nil, nil, nil, nil},
-				{2.0, 2, "30", 4},
-				{2.0, 3, "30", 4},
-				{nil, nil, "30", nil},
-				{2.0, 3, "40", 4},
-			},
+		isOrderedOnDistinctCols: true,
+	},
+	{
+		distinctCols: []uint32{1, 0, 2},
+		typs:         []*types.T{types.Float, types.Int, types.Bytes, types.Int},
+		tuples: tuples{
+			{nil, nil, nil, nil},
+			{nil, nil, nil, nil},
+			{nil, nil, "30", nil},
+			{1.0, 2, "30", 4},
+			{1.0, 2, "30", 4},
+			{2.0, 2, "30", 4},
+			{2.0, 3, "30", 4},
+			{2.0, 3, "40", 4},
+			{2.0, 3, "40", 4},
 		},
-		{
-			distinctCols: []uint32{0},
-			typs:         []*types.T{types.Int, types.Bytes},
-			tuples: tuples{
-				{1, "a"},
-				{2, "b"},
-				{3, "c"},
-				{nil, "d"},
-				{5, "e"},
-				{6, "f"},
-				{1, "1"},
-				{2, "2"},
-				{3, "3"},
-			},
-			expected: tuples{
-				{1, "a"},
-				{2, "b"},
-				{3, "c"},
-				{nil, "d"},
-				{5, "e"},
-				{6, "f"},
-			},
+		expected: tuples{
+			{nil, nil, nil, nil},
+			{nil, nil, "30", nil},
+			{1.0, 2, "30", 4},
+			{2.0, 2, "30", 4},
+			{2.0, 3, "30", 4},
+			{2.0, 3, "40", 4},
 		},
-		{
-			// This is to test hashTable deduplication with various batch size
-			// boundaries and ensure it always emits the first tuple it encountered.
-			distinctCols: []uint32{0},
-			typs:         []*types.T{types.Int, types.String},
-			tuples: tuples{
-				{1, "1"},
-				{1, "2"},
-				{1, "3"},
-				{1, "4"},
-				{1, "5"},
-				{2, "6"},
-				{2, "7"},
-				{2, "8"},
-				{2, "9"},
-				{2, "10"},
-				{0, "11"},
-				{0, "12"},
-				{0, "13"},
-				{1, "14"},
-				{1, "15"},
-				{1, "16"},
-			},
-			expected: tuples{
-				{1, "1"},
-				{2, "6"},
-				{0, "11"},
-			},
+		isOrderedOnDistinctCols: true,
+	},
+	{
+		distinctCols: []uint32{0, 1, 2},
+		typs:         []*types.T{types.Float, types.Int, types.String, types.Int},
+		tuples: tuples{
+			{1.0, 2, "30", 4},
+			{1.0, 2, "30", 4},
+			{nil, nil, nil, nil},
+			{nil, nil, nil, nil},
+			{2.0, 2, "30", 4},
+			{2.0, 3, "30", 4},
+			{nil, nil, "30", nil},
+			{2.0, 3, "40", 4},
+			{2.0, 3, "40", 4},
 		},
-		{
-			distinctCols: []uint32{0},
-			typs:         []*types.T{types.Jsonb, types.String},
-			tuples: tuples{
-				{`'{"id": 1}'`, "a"},
-				{`'{"id": 2}'`, "b"},
-				{`'{"id": 3}'`, "c"},
-				{`'{"id": 1}'`, "1"},
-				{`'{"id": null}'`, "d"},
-				{`'{"id": 2}'`, "2"},
-				{`'{"id": 5}'`, "e"},
-				{`'{"id": 6}'`, "f"},
-				{`'{"id": 3}'`, "3"},
-			},
-			expected: tuples{
-				{`'{"id": 1}'`, "a"},
-				{`'{"id": 2}'`, "b"},
-				{`'{"id": 3}'`, "c"},
-				{`'{"id": null}'`, "d"},
-				{`'{"id": 5}'`, "e"},
-				{`'{"id": 6}'`, "f"},
-			},
+		expected: tuples{
+			{1.0, 2, "30", 4},
+			{nil, nil, nil, nil},
+			{2.0, 2, "30", 4},
+			{2.0, 3, "30", 4},
+			{nil, nil, "30", nil},
+			{2.0, 3, "40", 4},
 		},
-	}
+	},
+	{
+		distinctCols: []uint32{0},
+		typs:         []*types.T{types.Int, types.Bytes},
+		tuples: tuples{
+			{1, "a"},
+			{2, "b"},
+			{3, "c"},
+			{nil, "d"},
+			{5, "e"},
+			{6, "f"},
+			{1, "1"},
+			{2, "2"},
+			{3, "3"},
+		},
+		expected: tuples{
+			{1, "a"},
+			{2, "b"},
+			{3, "c"},
+			{nil, "d"},
+			{5, "e"},
+			{6, "f"},
+		},
+	},
+	{
+		// This is to test hashTable deduplication with various batch size
+		// boundaries and ensure it always emits the first tuple it encountered.
+		distinctCols: []uint32{0},
+		typs:         []*types.T{types.Int, types.String},
+		tuples: tuples{
+			{1, "1"},
+			{1, "2"},
+			{1, "3"},
+			{1, "4"},
+			{1, "5"},
+			{2, "6"},
+			{2, "7"},
+			{2, "8"},
+			{2, "9"},
+			{2, "10"},
+			{0, "11"},
+			{0, "12"},
+			{0, "13"},
+			{1, "14"},
+			{1, "15"},
+			{1, "16"},
+		},
+		expected: tuples{
+			{1, "1"},
+			{2, "6"},
+			{0, "11"},
+		},
+	},
+	{
+		distinctCols: []uint32{0},
+		typs:         []*types.T{types.Jsonb, types.String},
+		tuples: tuples{
+			{`'{"id": 1}'`, "a"},
+			{`'{"id": 2}'`, "b"},
+			{`'{"id": 3}'`, "c"},
+			{`'{"id": 1}'`, "1"},
+			{`'{"id": null}'`, "d"},
+			{`'{"id": 2}'`, "2"},
+			{`'{"id": 5}'`, "e"},
+			{`'{"id": 6}'`, "f"},
+			{`'{"id": 3}'`, "3"},
+		},
+		expected: tuples{
+			{`'{"id": 1}'`, "a"},
+			{`'{"id": 2}'`, "b"},
+			{`'{"id": 3}'`, "c"},
+			{`'{"id": null}'`, "d"},
+			{`'{"id": 5}'`, "e"},
+			{`'{"id": 6}'`, "f"},
+		},
+	},
+}
 
-	for _, tc := range tcs {
+func TestDistinct(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	rng, _ := randutil.NewPseudoRand()
+	for _, tc := range distinctTestCases {
 		log.Infof(context.Background(), "unordered")
 		runTestsWithTyps(t, []tuples{tc.tuples}, [][]*types.T{tc.typs}, tc.expected, orderedVerifier,
 			func(input []colexecbase.Operator) (colexecbase.Operator, error) {
 				return NewUnorderedDistinct(
-					testAllocator, input[0], tc.distinctCols, tc.typs,
+					testAllocator, testAllocator, input[0], tc.distinctCols, tc.typs,
 				), nil
 			})
 		if tc.isOrderedOnDistinctCols {
@@ -222,7 +224,7 @@ func BenchmarkDistinct(b *testing.B) {
 
 	distinctConstructors := []func(*colmem.Allocator, colexecbase.Operator, []uint32, int, []*types.T) (colexecbase.Operator, error){
 		func(allocator *colmem.Allocator, input colexecbase.Operator, distinctCols []uint32, numOrderedCols int, typs []*types.T) (colexecbase.Operator, error) {
-			return NewUnorderedDistinct(allocator, input, distinctCols, typs), nil
+			return NewUnorderedDistinct(allocator, allocator, input, distinctCols, typs), nil
 		},
 		func(allocator *colmem.Allocator, input colexecbase.Operator, distinctCols []uint32, numOrderedCols int, typs []*types.T) (colexecbase.Operator, error) {
 			return newPartiallyOrderedDistinct(allocator, input, distinctCols, distinctCols[:numOrderedCols], typs)
@@ -0,0 +1,89 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/marusama/semaphore"
+)
+
+// NewExternalDistinct returns a disk-backed unordered distinct operator. It
+// uses the in-memory unordered distinct as the "main" strategy for the
+// hash-based partitioner and the combination of the disk-backed sort and the
+// ordered distinct as the "fallback".
+// - unlimitedAllocator must have been created with a memory account derived
+// from an unlimited memory monitor. It will be used by several internal
+// components of the external distinct which is responsible for making sure
+// that the components stay within the memory limit.
+// - numForcedRepartitions is a number of times that the external distinct is
+// forced to recursively repartition (even if it is otherwise not needed).
+// This should be non-zero only in tests.
+// - delegateFDAcquisitions specifies whether the external distinct should let
+// the partitioned disk queue acquire file descriptors instead of acquiring
+// them up front in Next. Should be true only in tests.
+func NewExternalDistinct(
+	unlimitedAllocator *colmem.Allocator,
+	input colexecbase.Operator,
+	distinctCols []uint32,
+	typs []*types.T,
+	memoryLimit int64,
+	diskQueueCfg colcontainer.DiskQueueCfg,
+	fdSemaphore semaphore.Semaphore,
+	createDiskBackedSorter func(input colexecbase.Operator, inputTypes []*types.T, orderingCols []execinfrapb.Ordering_Column, maxNumberPartitions int) (colexecbase.Operator, error),
+	numForcedRepartitions int,
+	delegateFDAcquisitions bool,
+	diskAcc *mon.BoundAccount,
+) colexecbase.Operator {
+	inMemMainOpConstructor := func(partitionedInputs []*partitionerToOperator) ResettableOperator {
+		// Note that the hash-based partitioner will make sure that partitions
+		// to process using the in-memory unordered distinct fit under the
+		// limit, so we use the same unlimited allocator for both allocator
+		// arguments.
+		return NewUnorderedDistinct(
+			unlimitedAllocator, unlimitedAllocator, partitionedInputs[0], distinctCols, typs,
+		).(ResettableOperator)
+	}
+	diskBackedFallbackOpConstructor := func(
+		partitionedInputs []colexecbase.Operator, maxNumberPartitions int,
+	) (colexecbase.Operator, Closers) {
+		// The distinct operator must emit the first tuple from the input among
+		// all that are identical on distinctCols, so we need a stable sort.
+		sorter := newStableDiskBackedSorter(
+			unlimitedAllocator, partitionedInputs[0], typs, distinctCols, maxNumberPartitions, createDiskBackedSorter,
+		)
+		op, err := NewOrderedDistinct(sorter, distinctCols, typs)
+		if err != nil {
+			colexecerror.InternalError(err)
+		}
+		return op, Closers{sorter.(Closer)}
+	}
+	return newHashBasedPartitioner(
+		unlimitedAllocator,
+		"external distinct",
+		[]colexecbase.Operator{input},
+		[][]*types.T{typs},
+		[][]uint32{distinctCols},
+		inMemMainOpConstructor,
+		diskBackedFallbackOpConstructor,
+		memoryLimit,
+		diskQueueCfg,
+		fdSemaphore,
+		numForcedRepartitions,
+		delegateFDAcquisitions,
+		diskAcc,
+	)
+}
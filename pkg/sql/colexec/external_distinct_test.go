@@ -0,0 +1,205 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils/colcontainerutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/marusama/semaphore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalDistinct(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	flowCtx := &execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg: &execinfra.ServerConfig{
+			Settings:    st,
+			DiskMonitor: testDiskMonitor,
+		},
+	}
+
+	queueCfg, cleanup := colcontainerutils.NewTestingDiskQueueCfg(t, true /* inMem */)
+	defer cleanup()
+
+	var (
+		accounts []*mon.BoundAccount
+		monitors []*mon.BytesMonitor
+	)
+	rng, _ := randutil.NewPseudoRand()
+	numForcedRepartitions := rng.Intn(5)
+	// Test the case in which the default memory is used as well as the case in
+	// which the distinct spills to disk.
+	for _, spillForced := range []bool{false, true} {
+		flowCtx.Cfg.TestingKnobs.ForceDiskSpill = spillForced
+		for _, tc := range distinctTestCases {
+			delegateFDAcquisitions := rng.Float64() < 0.5
+			log.Infof(ctx, "spillForced=%t/numRepartitions=%d/delegateFDAcquisitions=%t",
+				spillForced, numForcedRepartitions, delegateFDAcquisitions)
+			var semsToCheck []semaphore.Semaphore
+			// The external distinct doesn't preserve the order of the input
+			// tuples, so we use the unordered verifier.
+			runTestsWithTyps(t, []tuples{tc.tuples}, [][]*types.T{tc.typs}, tc.expected, unorderedVerifier,
+				func(input []colexecbase.Operator) (colexecbase.Operator, error) {
+					sem := colexecbase.NewTestingSemaphore(hbpMinPartitions)
+					semsToCheck = append(semsToCheck, sem)
+					op, newAccounts, newMonitors, _, err := createExternalDistinct(
+						ctx, flowCtx, tc.typs, tc.distinctCols, input[0], func() {}, queueCfg,
+						numForcedRepartitions, delegateFDAcquisitions, sem,
+					)
+					accounts = append(accounts, newAccounts...)
+					monitors = append(monitors, newMonitors...)
+					return op, err
+				})
+			for i, sem := range semsToCheck {
+				require.Equal(t, 0, sem.GetCount(), "sem still reports open FDs at index %d", i)
+			}
+		}
+	}
+	for _, acc := range accounts {
+		acc.Close(ctx)
+	}
+	for _, mon := range monitors {
+		mon.Stop(ctx)
+	}
+}
+
+// TestExternalDistinctFallbackToSort tests that the external distinct falls
+// back to using sort + ordered distinct when repartitioning doesn't decrease
+// the size of the partition and that the first tuple among all that are
+// identical on the distinct columns is emitted. We instantiate a source that
+// contains the same distinct value many times.
+func TestExternalDistinctFallbackToSort(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	flowCtx := &execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg: &execinfra.ServerConfig{
+			Settings: st,
+			TestingKnobs: execinfra.TestingKnobs{
+				ForceDiskSpill:   true,
+				MemoryLimitBytes: 1,
+			},
+			DiskMonitor: testDiskMonitor,
+		},
+	}
+	sourceTypes := []*types.T{types.Int, types.Int}
+	batch := testAllocator.NewMemBatchWithMaxCapacity(sourceTypes)
+	// The distinct column contains only zeroes while the other column has
+	// decreasing values, so the first tuple is the only one with the value
+	// of coldata.BatchSize() in the second column.
+	otherCol := batch.ColVec(1).Int64()
+	for i := 0; i < coldata.BatchSize(); i++ {
+		otherCol[i] = int64(coldata.BatchSize() - i)
+	}
+	batch.SetLength(coldata.BatchSize())
+	// Make sure that the partition is larger than the memory limit of the
+	// in-memory unordered distinct so that it has to be processed using the
+	// fallback strategy.
+	nBatches := 2*hbpMinimalMaxPartitionSizeForMain/(8*len(sourceTypes)*coldata.BatchSize()) + 1
+	source := newFiniteBatchSource(batch, sourceTypes, nBatches)
+	var spilled bool
+	queueCfg, cleanup := colcontainerutils.NewTestingDiskQueueCfg(t, true /* inMem */)
+	defer cleanup()
+	sem := colexecbase.NewTestingSemaphore(hbpMinPartitions)
+	// Ignore closers since the sorter should close itself when it is drained
+	// of all tuples. We assert this by checking that the semaphore reports a
+	// count of 0.
+	op, accounts, monitors, _, err := createExternalDistinct(
+		ctx, flowCtx, sourceTypes, []uint32{0}, source, func() { spilled = true }, queueCfg,
+		0 /* numForcedRepartitions */, true /* delegateFDAcquisitions */, sem,
+	)
+	defer func() {
+		for _, acc := range accounts {
+			acc.Close(ctx)
+		}
+		for _, mon := range monitors {
+			mon.Stop(ctx)
+		}
+	}()
+	require.NoError(t, err)
+	op.Init()
+	var actual tuples
+	for b := op.Next(ctx); b.Length() > 0; b = op.Next(ctx) {
+		for i := 0; i < b.Length(); i++ {
+			actual = append(actual, getTupleFromBatch(b, i))
+		}
+	}
+	require.True(t, spilled)
+	require.NoError(t, assertTuplesOrderedEqual(tuples{{0, coldata.BatchSize()}}, actual, &evalCtx))
+	require.Equal(t, 0, sem.GetCount())
+}
+
+// createExternalDistinct is a helper function that instantiates a
+// disk-backed unordered distinct operator. The desired memory limit must have
+// been already set on flowCtx. It returns an operator and an error as well as
+// memory monitors and memory accounts that will need to be closed once the
+// caller is done with the operator.
+func createExternalDistinct(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	inputTypes []*types.T,
+	distinctCols []uint32,
+	input colexecbase.Operator,
+	spillingCallbackFn func(),
+	diskQueueCfg colcontainer.DiskQueueCfg,
+	numForcedRepartitions int,
+	delegateFDAcquisitions bool,
+	testingSemaphore semaphore.Semaphore,
+) (colexecbase.Operator, []*mon.BoundAccount, []*mon.BytesMonitor, []Closer, error) {
+	spec := &execinfrapb.ProcessorSpec{
+		Input: []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
+		Core: execinfrapb.ProcessorCoreUnion{
+			Distinct: &execinfrapb.DistinctSpec{
+				DistinctColumns: distinctCols,
+			},
+		},
+	}
+	args := &NewColOperatorArgs{
+		Spec:                spec,
+		Inputs:              []colexecbase.Operator{input},
+		StreamingMemAccount: testMemAcc,
+		DiskQueueCfg:        diskQueueCfg,
+		FDSemaphore:         testingSemaphore,
+	}
+	// We will not use streaming memory account for the external distinct so
+	// that the in-memory unordered distinct could hit the memory limit set on
+	// flowCtx.
+	args.TestingKnobs.SpillingCallbackFn = spillingCallbackFn
+	args.TestingKnobs.NumForcedRepartitions = numForcedRepartitions
+	args.TestingKnobs.DelegateFDAcquisitions = delegateFDAcquisitions
+	result, err := TestNewColOperator(ctx, flowCtx, args)
+	return result.Op, result.OpAccounts, result.OpMonitors, result.ToClose, err
+}
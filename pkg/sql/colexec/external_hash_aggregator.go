@@ -0,0 +1,112 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+	"github.com/marusama/semaphore"
+)
+
+// NewExternalHashAggregator returns a disk-backed hash aggregator. It uses the
+// in-memory hash aggregator as the "main" strategy for the hash-based
+// partitioner and the combination of the disk-backed sort and the ordered
+// aggregator as the "fallback". Since the ordered aggregator doesn't support
+// FILTER clauses, spec must not contain filtering aggregate functions.
+// - unlimitedAllocator must have been created with a memory account derived
+// from an unlimited memory monitor. It will be used by several internal
+// components of the external hash aggregator which is responsible for making
+// sure that the components stay within the memory limit. memAccount should be
+// the same as the one used by unlimitedAllocator.
+// - numForcedRepartitions is a number of times that the external hash
+// aggregator is forced to recursively repartition (even if it is otherwise
+// not needed). This should be non-zero only in tests.
+// - delegateFDAcquisitions specifies whether the external hash aggregator
+// should let the partitioned disk queue acquire file descriptors instead of
+// acquiring them up front in Next. Should be true only in tests.
+func NewExternalHashAggregator(
+	unlimitedAllocator *colmem.Allocator,
+	memAccount *mon.BoundAccount,
+	input colexecbase.Operator,
+	inputTypes []*types.T,
+	spec *execinfrapb.AggregatorSpec,
+	evalCtx *tree.EvalContext,
+	constructors []execinfrapb.AggregateConstructor,
+	constArguments []tree.Datums,
+	outputTypes []*types.T,
+	memoryLimit int64,
+	diskQueueCfg colcontainer.DiskQueueCfg,
+	fdSemaphore semaphore.Semaphore,
+	createDiskBackedSorter func(input colexecbase.Operator, inputTypes []*types.T, orderingCols []execinfrapb.Ordering_Column, maxNumberPartitions int) (colexecbase.Operator, error),
+	numForcedRepartitions int,
+	delegateFDAcquisitions bool,
+	diskAcc *mon.BoundAccount,
+) colexecbase.Operator {
+	for _, aggFn := range spec.Aggregations {
+		if aggFn.FilterColIdx != nil {
+			colexecerror.InternalError(errors.AssertionFailedf("filtering aggregation is not supported by the external hash aggregator"))
+		}
+	}
+	inMemMainOpConstructor := func(partitionedInputs []*partitionerToOperator) ResettableOperator {
+		// Note that the hash-based partitioner will make sure that partitions
+		// to process using the in-memory hash aggregator fit under the limit,
+		// so we use the unlimited allocator. The input tuples don't need to be
+		// tracked since the in-memory hash aggregator will not be spilling to
+		// disk.
+		op, err := NewHashAggregator(
+			unlimitedAllocator, memAccount, partitionedInputs[0], inputTypes, spec,
+			evalCtx, constructors, constArguments, outputTypes, nil, /* newSpillingQueueArgs */
+		)
+		if err != nil {
+			colexecerror.InternalError(err)
+		}
+		return op.(ResettableOperator)
+	}
+	diskBackedFallbackOpConstructor := func(
+		partitionedInputs []colexecbase.Operator, maxNumberPartitions int,
+	) (colexecbase.Operator, Closers) {
+		// Some aggregate functions (e.g. array_agg) depend on the order of the
+		// tuples within the groups, so we need a stable sort.
+		sorter := newStableDiskBackedSorter(
+			unlimitedAllocator, partitionedInputs[0], inputTypes, spec.GroupCols, maxNumberPartitions, createDiskBackedSorter,
+		)
+		op, err := NewOrderedAggregator(
+			unlimitedAllocator, memAccount, sorter, inputTypes, spec, evalCtx,
+			constructors, constArguments, outputTypes, false, /* isScalar */
+		)
+		if err != nil {
+			colexecerror.InternalError(err)
+		}
+		return op, Closers{sorter.(Closer)}
+	}
+	return newHashBasedPartitioner(
+		unlimitedAllocator,
+		"external hash aggregator",
+		[]colexecbase.Operator{input},
+		[][]*types.T{inputTypes},
+		[][]uint32{spec.GroupCols},
+		inMemMainOpConstructor,
+		diskBackedFallbackOpConstructor,
+		memoryLimit,
+		diskQueueCfg,
+		fdSemaphore,
+		numForcedRepartitions,
+		delegateFDAcquisitions,
+		diskAcc,
+	)
+}
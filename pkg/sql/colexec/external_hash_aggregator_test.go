@@ -0,0 +1,206 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils/colcontainerutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/marusama/semaphore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalHashAggregator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	flowCtx := &execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg: &execinfra.ServerConfig{
+			Settings:    st,
+			DiskMonitor: testDiskMonitor,
+		},
+	}
+
+	queueCfg, cleanup := colcontainerutils.NewTestingDiskQueueCfg(t, true /* inMem */)
+	defer cleanup()
+
+	var (
+		accounts []*mon.BoundAccount
+		monitors []*mon.BytesMonitor
+	)
+	rng, _ := randutil.NewPseudoRand()
+	numForcedRepartitions := rng.Intn(5)
+	// Test the case in which the default memory is used as well as the case in
+	// which the hash aggregator spills to disk.
+	for _, spillForced := range []bool{false, true} {
+		flowCtx.Cfg.TestingKnobs.ForceDiskSpill = spillForced
+		for _, tc := range hashAggregatorTestCases {
+			delegateFDAcquisitions := rng.Float64() < 0.5
+			log.Infof(ctx, "spillForced=%t/numRepartitions=%d/%s/delegateFDAcquisitions=%t",
+				spillForced, numForcedRepartitions, tc.name, delegateFDAcquisitions)
+			if err := tc.init(); err != nil {
+				t.Fatal(err)
+			}
+			var semsToCheck []semaphore.Semaphore
+			runTestsWithTyps(t, []tuples{tc.input}, [][]*types.T{tc.typs}, tc.expected, unorderedVerifier,
+				func(input []colexecbase.Operator) (colexecbase.Operator, error) {
+					sem := colexecbase.NewTestingSemaphore(hbpMinPartitions)
+					semsToCheck = append(semsToCheck, sem)
+					op, newAccounts, newMonitors, _, err := createExternalHashAggregator(
+						ctx, flowCtx, tc.typs, tc.spec, input[0], func() {}, queueCfg,
+						numForcedRepartitions, delegateFDAcquisitions, sem,
+					)
+					accounts = append(accounts, newAccounts...)
+					monitors = append(monitors, newMonitors...)
+					return op, err
+				})
+			for i, sem := range semsToCheck {
+				require.Equal(t, 0, sem.GetCount(), "sem still reports open FDs at index %d", i)
+			}
+		}
+	}
+	for _, acc := range accounts {
+		acc.Close(ctx)
+	}
+	for _, mon := range monitors {
+		mon.Stop(ctx)
+	}
+}
+
+// TestExternalHashAggregatorFallbackToSort tests that the external hash
+// aggregator falls back to using sort + ordered aggregator when
+// repartitioning doesn't decrease the size of the partition. We instantiate
+// a source that contains the same grouping value many times.
+func TestExternalHashAggregatorFallbackToSort(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	flowCtx := &execinfra.FlowCtx{
+		EvalCtx: &evalCtx,
+		Cfg: &execinfra.ServerConfig{
+			Settings: st,
+			TestingKnobs: execinfra.TestingKnobs{
+				ForceDiskSpill:   true,
+				MemoryLimitBytes: 1,
+			},
+			DiskMonitor: testDiskMonitor,
+		},
+	}
+	sourceTypes := []*types.T{types.Int, types.Int}
+	batch := testAllocator.NewMemBatchWithMaxCapacity(sourceTypes)
+	// The grouping column contains only zeroes, and we're summing up ones.
+	aggCol := batch.ColVec(1).Int64()
+	for i := 0; i < coldata.BatchSize(); i++ {
+		aggCol[i] = 1
+	}
+	batch.SetLength(coldata.BatchSize())
+	// Make sure that the partition is larger than the memory limit of the
+	// in-memory hash aggregator so that it has to be processed using the
+	// fallback strategy.
+	nBatches := 2*hbpMinimalMaxPartitionSizeForMain/(8*len(sourceTypes)*coldata.BatchSize()) + 1
+	source := newFiniteBatchSource(batch, sourceTypes, nBatches)
+	tc := aggregatorTestCase{
+		typs:      sourceTypes,
+		groupCols: []uint32{0},
+		aggCols:   [][]uint32{{1}},
+		aggFns:    []execinfrapb.AggregatorSpec_Func{execinfrapb.AggregatorSpec_SUM_INT},
+	}
+	require.NoError(t, tc.init())
+	var spilled bool
+	queueCfg, cleanup := colcontainerutils.NewTestingDiskQueueCfg(t, true /* inMem */)
+	defer cleanup()
+	sem := colexecbase.NewTestingSemaphore(hbpMinPartitions)
+	// Ignore closers since the sorter should close itself when it is drained
+	// of all tuples. We assert this by checking that the semaphore reports a
+	// count of 0.
+	op, accounts, monitors, _, err := createExternalHashAggregator(
+		ctx, flowCtx, sourceTypes, tc.spec, source, func() { spilled = true }, queueCfg,
+		0 /* numForcedRepartitions */, true /* delegateFDAcquisitions */, sem,
+	)
+	defer func() {
+		for _, acc := range accounts {
+			acc.Close(ctx)
+		}
+		for _, mon := range monitors {
+			mon.Stop(ctx)
+		}
+	}()
+	require.NoError(t, err)
+	op.Init()
+	var actual []int64
+	for b := op.Next(ctx); b.Length() > 0; b = op.Next(ctx) {
+		actual = append(actual, b.ColVec(0).Int64()[:b.Length()]...)
+	}
+	require.True(t, spilled)
+	require.Equal(t, []int64{int64(nBatches * coldata.BatchSize())}, actual)
+	require.Equal(t, 0, sem.GetCount())
+}
+
+// createExternalHashAggregator is a helper function that instantiates a
+// disk-backed hash aggregator. The desired memory limit must have been
+// already set on flowCtx. It returns an operator and an error as well as
+// memory monitors and memory accounts that will need to be closed once the
+// caller is done with the operator.
+func createExternalHashAggregator(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	inputTypes []*types.T,
+	aggSpec *execinfrapb.AggregatorSpec,
+	input colexecbase.Operator,
+	spillingCallbackFn func(),
+	diskQueueCfg colcontainer.DiskQueueCfg,
+	numForcedRepartitions int,
+	delegateFDAcquisitions bool,
+	testingSemaphore semaphore.Semaphore,
+) (colexecbase.Operator, []*mon.BoundAccount, []*mon.BytesMonitor, []Closer, error) {
+	spec := &execinfrapb.ProcessorSpec{
+		Input: []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
+		Core: execinfrapb.ProcessorCoreUnion{
+			Aggregator: aggSpec,
+		},
+	}
+	args := &NewColOperatorArgs{
+		Spec:                spec,
+		Inputs:              []colexecbase.Operator{input},
+		StreamingMemAccount: testMemAcc,
+		DiskQueueCfg:        diskQueueCfg,
+		FDSemaphore:         testingSemaphore,
+	}
+	// We will not use streaming memory account for the external hash
+	// aggregator so that the in-memory hash aggregator could hit the memory
+	// limit set on flowCtx.
+	args.TestingKnobs.SpillingCallbackFn = spillingCallbackFn
+	args.TestingKnobs.NumForcedRepartitions = numForcedRepartitions
+	args.TestingKnobs.DelegateFDAcquisitions = delegateFDAcquisitions
+	result, err := TestNewColOperator(ctx, flowCtx, args)
+	return result.Op, result.OpAccounts, result.OpMonitors, result.ToClose, err
+}
@@ -63,8 +63,9 @@ const (
 type hashAggregator struct {
 	OneInputNode
 
-	allocator *colmem.Allocator
-	spec      *execinfrapb.AggregatorSpec
+	allocator  *colmem.Allocator
+	memAccount *mon.BoundAccount
+	spec       *execinfrapb.AggregatorSpec
 
 	aggHelper          aggregatorHelper
 	inputTypes         []*types.T
@@ -97,6 +98,13 @@ type hashAggregator struct {
 	// state stores the current state of hashAggregator.
 	state hashAggregatorState
 
+	// inputTrackingState tracks all the input tuples which is needed in order
+	// to fall back to the external hash aggregator.
+	inputTrackingState struct {
+		tuples            *spillingQueue
+		zeroBatchEnqueued bool
+	}
+
 	scratch struct {
 		// eqChains stores the chains of tuples from the current batch that are
 		// equal on the grouping columns (meaning that all tuples from the
@@ -119,6 +127,7 @@ type hashAggregator struct {
 	toClose     Closers
 }
 
+var _ colexecbase.BufferingInMemoryOperator = &hashAggregator{}
 var _ closableOperator = &hashAggregator{}
 
 // hashAggregatorAllocSize determines the allocation size used by the hash
@@ -132,6 +141,10 @@ const hashAggregatorAllocSize = 128
 // NewOrderedAggregator function.
 // memAccount should be the same as the one used by allocator and will be used
 // by aggregatorHelper to handle DISTINCT clause.
+// If newSpillingQueueArgs is non-nil, the hash aggregator will track all the
+// input tuples in a spillingQueue so that they could be exported once the
+// memory limit is reached (in order to fall back to the external hash
+// aggregator).
 func NewHashAggregator(
 	allocator *colmem.Allocator,
 	memAccount *mon.BoundAccount,
@@ -142,6 +155,7 @@ func NewHashAggregator(
 	constructors []execinfrapb.AggregateConstructor,
 	constArguments []tree.Datums,
 	outputTypes []*types.T,
+	newSpillingQueueArgs *NewSpillingQueueArgs,
 ) (colexecbase.Operator, error) {
 	aggFnsAlloc, inputArgsConverter, toClose, err := newAggregateFuncsAlloc(
 		allocator, inputTypes, spec, evalCtx, constructors, constArguments,
@@ -160,6 +174,7 @@ func NewHashAggregator(
 	hashAgg := &hashAggregator{
 		OneInputNode:       NewOneInputNode(input),
 		allocator:          allocator,
+		memAccount:         memAccount,
 		spec:               spec,
 		state:              hashAggregatorBuffering,
 		inputTypes:         inputTypes,
@@ -171,19 +186,18 @@ func NewHashAggregator(
 		hashAlloc:          aggBucketAlloc{allocator: allocator},
 	}
 	hashAgg.bufferingState.tuples = newAppendOnlyBufferedBatch(allocator, inputTypes, nil /* colsToStore */)
+	if newSpillingQueueArgs != nil {
+		hashAgg.inputTrackingState.tuples = newSpillingQueue(
+			newSpillingQueueArgs.UnlimitedAllocator, inputTypes, newSpillingQueueArgs.MemoryLimit,
+			newSpillingQueueArgs.DiskQueueCfg, newSpillingQueueArgs.FDSemaphore, newSpillingQueueArgs.DiskAcc,
+		)
+	}
 	hashAgg.datumAlloc.AllocSize = hashAggregatorAllocSize
-	hashAgg.aggHelper = newAggregatorHelper(allocator, memAccount, inputTypes, spec, &hashAgg.datumAlloc, true /* isHashAgg */, hashAgg.maxBuffered)
 	return hashAgg, err
 }
 
 func (op *hashAggregator) Init() {
 	op.input.Init()
-	// Note that we use a batch with fixed capacity because aggregate functions
-	// hold onto the vectors passed in into their Init method, so we cannot
-	// simply reallocate the output batch.
-	// TODO(yuzefovich): consider changing aggregateFunc interface to allow for
-	// updating the output vector.
-	op.output = op.allocator.NewMemBatchWithFixedCapacity(op.outputTypes, coldata.BatchSize())
 	op.scratch.eqChains = make([][]int, op.maxBuffered)
 	op.scratch.intSlice = make([]int, op.maxBuffered)
 	op.scratch.anotherIntSlice = make([]int, op.maxBuffered)
@@ -209,6 +223,21 @@ func (op *hashAggregator) Init() {
 }
 
 func (op *hashAggregator) Next(ctx context.Context) coldata.Batch {
+	if op.output == nil {
+		// The memory is allocated lazily (rather than in Init) so that
+		// reaching the memory limit here could be handled by the disk
+		// spiller.
+		op.aggHelper = newAggregatorHelper(
+			op.allocator, op.memAccount, op.inputTypes, op.spec, &op.datumAlloc,
+			true /* isHashAgg */, op.maxBuffered,
+		)
+		// Note that we use a batch with fixed capacity because aggregate
+		// functions hold onto the vectors passed in into their Init method, so
+		// we cannot simply reallocate the output batch.
+		// TODO(yuzefovich): consider changing aggregateFunc interface to allow
+		// for updating the output vector.
+		op.output = op.allocator.NewMemBatchWithFixedCapacity(op.outputTypes, coldata.BatchSize())
+	}
 	for {
 		switch op.state {
 		case hashAggregatorBuffering:
@@ -219,6 +248,9 @@ func (op *hashAggregator) Next(ctx context.Context) coldata.Batch {
 			}
 			op.bufferingState.pendingBatch, op.bufferingState.unprocessedIdx = op.input.Next(ctx), 0
 			n := op.bufferingState.pendingBatch.Length()
+			if op.inputTrackingState.tuples != nil {
+				op.trackInputBatch(ctx, op.bufferingState.pendingBatch)
+			}
 			if n == 0 {
 				op.state = hashAggregatorAggregating
 				continue
@@ -242,6 +274,14 @@ func (op *hashAggregator) Next(ctx context.Context) coldata.Batch {
 			if op.bufferingState.pendingBatch.Length() == 0 {
 				// TODO(yuzefovich): we no longer need the hash table, so we
 				// could be releasing its memory here.
+				if op.inputTrackingState.tuples != nil {
+					// We have fully consumed the input, so we will not be
+					// exporting the input tuples and can release the disk
+					// resources of the spilling queue.
+					if err := op.inputTrackingState.tuples.close(ctx); err != nil {
+						colexecerror.InternalError(err)
+					}
+				}
 				op.state = hashAggregatorOutputting
 				continue
 			}
@@ -424,6 +464,74 @@ func (op *hashAggregator) onlineAgg(ctx context.Context, b coldata.Batch) {
 	}
 }
 
+// trackInputBatch copies the batch read from the input into the spilling
+// queue that tracks all the input tuples. A zero-length batch is enqueued as
+// well, per the contract of the spilling queue.
+func (op *hashAggregator) trackInputBatch(ctx context.Context, batch coldata.Batch) {
+	n := batch.Length()
+	if n == 0 {
+		if err := op.inputTrackingState.tuples.enqueue(ctx, coldata.ZeroBatch); err != nil {
+			colexecerror.InternalError(err)
+		}
+		op.inputTrackingState.zeroBatchEnqueued = true
+		return
+	}
+	// TODO(yuzefovich): do not instantiate a new batch here once
+	// spillingQueues actually copy the batches when those are kept in-memory.
+	unlimitedAllocator := op.inputTrackingState.tuples.unlimitedAllocator
+	tuples := unlimitedAllocator.NewMemBatchWithFixedCapacity(op.inputTypes, n)
+	unlimitedAllocator.PerformOperation(tuples.ColVecs(), func() {
+		for colIdx, vec := range tuples.ColVecs() {
+			vec.Copy(
+				coldata.CopySliceArgs{
+					SliceArgs: coldata.SliceArgs{
+						Src:       batch.ColVec(colIdx),
+						Sel:       batch.Selection(),
+						SrcEndIdx: n,
+					},
+				},
+			)
+		}
+		tuples.SetLength(n)
+	})
+	if err := op.inputTrackingState.tuples.enqueue(ctx, tuples); err != nil {
+		colexecerror.InternalError(err)
+	}
+}
+
+// ExportBuffered exports all the tuples that the hashAggregator has read from
+// the input. The partial aggregation results cannot be exported, so the
+// disk-backed operator will have to aggregate all of the input tuples from
+// scratch.
+func (op *hashAggregator) ExportBuffered(ctx context.Context, _ colexecbase.Operator) coldata.Batch {
+	if op.inputTrackingState.tuples == nil {
+		colexecerror.InternalError(errors.AssertionFailedf(
+			"hash aggregator without input tracking is asked to export buffered tuples",
+		))
+	}
+	if op.state == hashAggregatorOutputting || op.state == hashAggregatorDone {
+		// The input tracking spilling queue has already been closed, and some
+		// of the output might have been emitted, so we cannot fall back to the
+		// external hash aggregator at this point.
+		colexecerror.InternalError(errors.AssertionFailedf(
+			"hash aggregator is asked to export buffered tuples after it started emitting output",
+		))
+	}
+	if !op.inputTrackingState.zeroBatchEnqueued {
+		// Per the contract of the spilling queue, we need to append a
+		// zero-length batch before dequeueing.
+		if err := op.inputTrackingState.tuples.enqueue(ctx, coldata.ZeroBatch); err != nil {
+			colexecerror.InternalError(err)
+		}
+		op.inputTrackingState.zeroBatchEnqueued = true
+	}
+	batch, err := op.inputTrackingState.tuples.dequeue(ctx)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	return batch
+}
+
 // reset resets the hashAggregator for another run. Primarily used for
 // benchmarks.
 func (op *hashAggregator) reset(ctx context.Context) {
@@ -436,8 +544,19 @@ func (op *hashAggregator) reset(ctx context.Context) {
 	op.buckets = op.buckets[:0]
 	op.state = hashAggregatorBuffering
 	op.ht.reset(ctx)
+	if op.inputTrackingState.tuples != nil {
+		op.inputTrackingState.tuples.reset(ctx)
+		op.inputTrackingState.zeroBatchEnqueued = false
+	}
 }
 
 func (op *hashAggregator) Close(ctx context.Context) error {
-	return op.toClose.Close(ctx)
+	var retErr error
+	if op.inputTrackingState.tuples != nil {
+		retErr = op.inputTrackingState.tuples.close(ctx)
+	}
+	if err := op.toClose.Close(ctx); err != nil && retErr == nil {
+		retErr = err
+	}
+	return retErr
 }
@@ -0,0 +1,720 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"context"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+	"github.com/marusama/semaphore"
+)
+
+// hashBasedPartitionerState indicates the current state of the hash-based
+// partitioner.
+type hashBasedPartitionerState int
+
+const (
+	// hbpInitialPartitioning indicates that the operator is currently reading
+	// batches from all inputs and distributing tuples to different partitions
+	// based on the hash values. Once all inputs are exhausted, the hash-based
+	// partitioner transitions to hbpProcessNewPartitionUsingMain state.
+	hbpInitialPartitioning hashBasedPartitionerState = iota
+	// hbpRecursivePartitioning indicates that the operator is recursively
+	// partitioning one of the existing partitions (that is too big to process
+	// using the "main" strategy at once). It will do so using a different hash
+	// function and will spill newly created partitions to disk. We also keep
+	// track whether repartitioning reduces the size of the partitions in
+	// question - if we see that the newly created largest partition is about
+	// the same in size as the "parent" partition (the percentage difference is
+	// less than hbpRecursivePartitioningSizeDecreaseThreshold), it is likely
+	// that the partition consists of the tuples not distinct on the hash
+	// columns, so we fall back to using the "fallback" strategy to process such
+	// partition. After repartitioning, the operator transitions to
+	// hbpProcessNewPartitionUsingMain state.
+	hbpRecursivePartitioning
+	// hbpProcessNewPartitionUsingMain indicates that the operator should choose
+	// a partition index and process the corresponding partitions of all inputs
+	// using the in-memory "main" operator. We will only process the partitions
+	// if they fit into memory. If there are no partition indices that the
+	// operator can
+	// process, it transitions into hbpRecursivePartitioning state. If there are
+	// no partition indices to process using the "main" strategy, but there are
+	// indices to process using the "fallback" strategy, the operator
+	// transitions to hbpProcessingUsingFallback state. If there are no
+	// partition indices left at all to process, the operator transitions to
+	// hbpFinished state.
+	hbpProcessNewPartitionUsingMain
+	// hbpProcessingUsingMain indicates that the operator is currently
+	// processing tuples from the corresponding partitions using the in-memory
+	// "main" operator. Once the "main" operator returns a zero-length batch
+	// (indicating that full output for the current partitions has been
+	// emitted), the hash-based partitioner transitions to
+	// hbpProcessNewPartitionUsingMain state.
+	hbpProcessingUsingMain
+	// hbpProcessingUsingFallback indicates that the operator is currently
+	// processing tuples from all partitions that need to be processed using
+	// the disk-backed "fallback" operator. Once the "fallback" operator returns
+	// a zero-length batch, the hash-based partitioner transitions to
+	// hbpFinished state.
+	hbpProcessingUsingFallback
+	// hbpFinished indicates that the hash-based partitioner has emitted all
+	// tuples already and only zero-length batch will be emitted from now on.
+	hbpFinished
+)
+
+const (
+	// hbpRecursivePartitioningSizeDecreaseThreshold determines by how much the
+	// newly-created partitions in the recursive partitioning stage should be
+	// smaller than the "parent" partition in order to consider the
+	// repartitioning "successful". If this threshold is not met, then this
+	// newly created partition will be added to the "fallback" list (which, in a
+	// sense, serves as the base case for "recursion").
+	hbpRecursivePartitioningSizeDecreaseThreshold = 0.05
+	// hbpDiskQueuesMemFraction determines the fraction of the available RAM
+	// that is allocated for the in-memory cache of disk queues.
+	hbpDiskQueuesMemFraction = 0.5
+	// hbpMinPartitions is the minimum number of partitions per input necessary
+	// for the hash-based partitioner to make progress. When falling back to the
+	// disk-backed operator, one partition of each input is read at a time
+	// (which uses an FD) while the external sorter of the fallback operator
+	// uses up to externalSorterMinPartitions for each input.
+	hbpMinPartitions = externalSorterMinPartitions + 1
+	// hbpMinimalMaxPartitionSizeForMain determines the minimum value for the
+	// maxPartitionSizeToProcessUsingMain variable of the hash-based
+	// partitioner.
+	hbpMinimalMaxPartitionSizeForMain = 64 << 10 /* 64 KiB */
+)
+
+// hashBasedPartitioner is an operator that implements the logic of Grace hash
+// join for the operators that only need to see all tuples with the same
+// values on the hash columns at once (like the hash joiner, the hash
+// aggregator and the unordered distinct).
+//
+// In order to get different hash functions, we're using the same family of
+// hash functions that the in-memory operators use, but we will seed it with a
+// different initial hash value.
+//
+// The operator works in two phases.
+//
+// Phase 1: partitioning
+// In this phase, we iterate through all inputs, hashing every row using a
+// hash function A that produces n partitions. This will produce n partitions
+// for each input, which will be persisted to disk separately as memory fills
+// up.
+//
+// Phase 2: processing
+// Now, we retrieve the partitions with the same index of all inputs from disk
+// and process them using the in-memory "main" operator (which uses a
+// different hash function B). Since all tuples with the same values on the
+// hash columns end up in the partitions with the same index, the output of
+// the "main" operator on all partitions is the same as on the whole inputs.
+//
+// If one of the partitions itself runs out of memory, we recursively apply
+// this algorithm. The partition will be divided into sub-partitions by a new
+// hash function, spilled to disk, and so on. If repartitioning doesn't reduce
+// size of the partitions sufficiently, then such partitions will be handled
+// using the disk-backed "fallback" operator.
+type hashBasedPartitioner struct {
+	NonExplainable
+	closerHelper
+
+	state              hashBasedPartitionerState
+	unlimitedAllocator *colmem.Allocator
+	// name is the name of the operator used in the logs.
+	name         string
+	inputs       []colexecbase.Operator
+	hashCols     [][]uint32
+	diskQueueCfg colcontainer.DiskQueueCfg
+
+	// fdState is used to acquire file descriptors up front.
+	fdState struct {
+		fdSemaphore semaphore.Semaphore
+		acquiredFDs int
+	}
+
+	// Partitioning phase variables.
+	partitioners     []colcontainer.PartitionedQueue
+	tupleDistributor *tupleHashDistributor
+	// maxNumberActivePartitions determines the maximum number of active
+	// partitions that the operator is allowed to have. This number is computed
+	// semi-dynamically and will influence the choice of numBuckets value.
+	maxNumberActivePartitions int
+	// numBuckets is the number of buckets that a partition is divided into.
+	numBuckets int
+	// partitionsToProcessUsingMain is a map from partitionIdx to a utility
+	// struct. This map contains all partition indices that need to be
+	// processed using the in-memory "main" operator. If the partition is too
+	// big, it will be tried to be repartitioned; if during repartitioning the
+	// size doesn't decrease enough, it will be added to
+	// partitionsToProcessUsingFallback.
+	partitionsToProcessUsingMain map[int]*hbpPartitionInfo
+	// partitionsToProcessUsingFallback contains all partition indices that
+	// need to be processed using the "fallback" strategy. Partition indices
+	// will be added into this slice if recursive partitioning doesn't seem to
+	// make progress on partition' size reduction.
+	partitionsToProcessUsingFallback []int
+	// partitionIdxOffset stores the first "available" partition index to use.
+	// During the partitioning step, all tuples will go into one of the buckets
+	// in [partitionIdxOffset, partitionIdxOffset + numBuckets) range.
+	partitionIdxOffset int
+	// numRepartitions tracks the number of times the hash-based partitioner
+	// had to recursively repartition another partition because the latter was
+	// too big to process using the "main" operator.
+	numRepartitions int
+	// inputBatches contains the batches read from each of the inputs during
+	// the initial partitioning.
+	inputBatches []coldata.Batch
+	// scratch and recursiveScratch are helper batches, one for each input
+	// since the inputs can have different schemas.
+	scratch, recursiveScratch []coldata.Batch
+
+	// Processing phase variables.
+	inMemMainOpInputs []*partitionerToOperator
+	inMemMainOp       ResettableOperator
+	// diskBackedFallbackOpInputs read all partitions that need to be
+	// processed using the "fallback" strategy one after another.
+	diskBackedFallbackOpInputs []*hbpFallbackInput
+	diskBackedFallbackOp       colexecbase.Operator
+	// diskBackedFallbackOpClosers are the components of the "fallback"
+	// operator (like the disk-backed sorters) that need to be closed
+	// explicitly since the "fallback" operator doesn't close them.
+	diskBackedFallbackOpClosers Closers
+
+	// maxPartitionSizeToProcessUsingMain indicates the maximum memory size of
+	// a partition of the last input that we're ok with processing using the
+	// in-memory "main" operator without having to repartition it. We pay
+	// attention only to the last input because the "main" operator is
+	// expected to buffer only it while processing the other inputs in a
+	// streaming fashion (e.g. the in-memory hash joiner fully buffers the
+	// right input).
+	maxPartitionSizeToProcessUsingMain int64
+
+	testingKnobs struct {
+		// numForcedRepartitions is a number of times that the hash-based
+		// partitioner is forced to recursively repartition (even if it is
+		// otherwise not needed) before it proceeds to processing the partitions.
+		numForcedRepartitions int
+		// delegateFDAcquisitions, if true, means that a test wants to force the
+		// PartitionedDiskQueues to track the number of file descriptors the
+		// hash-based partitioner will open/close. This disables the default
+		// behavior of acquiring all file descriptors up front in Next.
+		delegateFDAcquisitions bool
+	}
+}
+
+var _ closableOperator = &hashBasedPartitioner{}
+
+// hbpPartitionInfo tracks the memory size of a partition of the last input.
+type hbpPartitionInfo struct {
+	memSize       int64
+	parentMemSize int64
+}
+
+// newHashBasedPartitioner returns a disk-backed operator that partitions its
+// inputs based on the hash values of the corresponding hashCols and processes
+// the partitions with the same index of all inputs using the in-memory
+// operator created by inMemMainOpConstructor. The partitions that cannot be
+// split by repartitioning are processed using the disk-backed operator
+// created by diskBackedFallbackOpConstructor. Note that the fallback operator
+// receives the tuples from all such partitions of an input in a single input
+// (which is correct since the partitions are disjoint on the hash columns).
+// The components of the fallback operator that it doesn't close itself must
+// be returned by diskBackedFallbackOpConstructor as well.
+// - unlimitedAllocator must have been created with a memory account derived
+// from an unlimited memory monitor. It will be used by several internal
+// components of the hash-based partitioner which is responsible for making
+// sure that the components stay within the memory limit.
+// - numForcedRepartitions is a number of times that the hash-based
+// partitioner is forced to recursively repartition (even if it is otherwise
+// not needed). This should be non-zero only in tests.
+// - delegateFDAcquisitions specifies whether the hash-based partitioner
+// should let the partitioned disk queue acquire file descriptors instead of
+// acquiring them up front in Next. Should be true only in tests.
+func newHashBasedPartitioner(
+	unlimitedAllocator *colmem.Allocator,
+	name string,
+	inputs []colexecbase.Operator,
+	inputTypes [][]*types.T,
+	hashCols [][]uint32,
+	inMemMainOpConstructor func(partitionedInputs []*partitionerToOperator) ResettableOperator,
+	diskBackedFallbackOpConstructor func(partitionedInputs []colexecbase.Operator, maxNumberPartitions int) (colexecbase.Operator, Closers),
+	memoryLimit int64,
+	diskQueueCfg colcontainer.DiskQueueCfg,
+	fdSemaphore semaphore.Semaphore,
+	numForcedRepartitions int,
+	delegateFDAcquisitions bool,
+	diskAcc *mon.BoundAccount,
+) *hashBasedPartitioner {
+	if diskQueueCfg.CacheMode != colcontainer.DiskQueueCacheModeClearAndReuseCache {
+		colexecerror.InternalError(errors.Errorf("%s instantiated with suboptimal disk queue cache mode: %d", name, diskQueueCfg.CacheMode))
+	}
+	partitionedDiskQueueSemaphore := fdSemaphore
+	if !delegateFDAcquisitions {
+		// To avoid deadlocks with other disk queues, we manually attempt to
+		// acquire the maximum number of descriptors all at once in Next.
+		// Passing in a nil semaphore indicates that the caller will do the
+		// acquiring.
+		partitionedDiskQueueSemaphore = nil
+	}
+	numInputs := len(inputs)
+	partitioners := make([]colcontainer.PartitionedQueue, numInputs)
+	inMemMainOpInputs := make([]*partitionerToOperator, numInputs)
+	diskBackedFallbackOpInputs := make([]*hbpFallbackInput, numInputs)
+	fallbackInputs := make([]colexecbase.Operator, numInputs)
+	for i := range inputs {
+		partitioners[i] = colcontainer.NewPartitionedDiskQueue(
+			inputTypes[i], diskQueueCfg, partitionedDiskQueueSemaphore, colcontainer.PartitionerStrategyDefault, diskAcc,
+		)
+		inMemMainOpInputs[i] = newPartitionerToOperator(
+			unlimitedAllocator, inputTypes[i], partitioners[i], 0, /* partitionIdx */
+		)
+		diskBackedFallbackOpInputs[i] = &hbpFallbackInput{
+			partitionerToOperator: newPartitionerToOperator(
+				unlimitedAllocator, inputTypes[i], partitioners[i], 0, /* partitionIdx */
+			),
+		}
+		fallbackInputs[i] = diskBackedFallbackOpInputs[i]
+	}
+	// With the default limit of 256 file descriptors, this results in 16
+	// partitions. This is a hard maximum of partitions that will be used by
+	// the hash-based partitioner. Below we check whether we have enough RAM to
+	// support the caches of this number of partitions.
+	maxNumberActivePartitions := fdSemaphore.GetLimit() / 16
+	if diskQueueCfg.BufferSizeBytes > 0 {
+		diskQueuesTotalMemLimit := int(float64(memoryLimit) * hbpDiskQueuesMemFraction)
+		numDiskQueuesThatFit := diskQueuesTotalMemLimit / diskQueueCfg.BufferSizeBytes
+		if numDiskQueuesThatFit < maxNumberActivePartitions {
+			maxNumberActivePartitions = numDiskQueuesThatFit
+		}
+	}
+	if maxNumberActivePartitions < numInputs*hbpMinPartitions {
+		maxNumberActivePartitions = numInputs * hbpMinPartitions
+	}
+	diskQueuesMemUsed := maxNumberActivePartitions * diskQueueCfg.BufferSizeBytes
+	// We need to allocate 1 FD for each input for reading the partitions that
+	// we need to process using the "fallback" strategy, and all others are
+	// divided between the external sorters of the inputs.
+	fallbackMaxNumberPartitions := (maxNumberActivePartitions - numInputs) / numInputs
+	if fallbackMaxNumberPartitions < externalSorterMinPartitions {
+		// This code gets a maximum number of partitions based on the semaphore
+		// limit. In tests, this limit is set artificially low to catch any
+		// violations of the limit, resulting in possibly computing a low
+		// number of partitions for the sorter, which we overwrite here.
+		fallbackMaxNumberPartitions = externalSorterMinPartitions
+	}
+	op := &hashBasedPartitioner{
+		unlimitedAllocator:        unlimitedAllocator,
+		name:                      name,
+		inputs:                    inputs,
+		hashCols:                  hashCols,
+		diskQueueCfg:              diskQueueCfg,
+		partitioners:              partitioners,
+		maxNumberActivePartitions: maxNumberActivePartitions,
+		// In the initial partitioning state we will divide all available
+		// partitions evenly between the inputs.
+		numBuckets:                   maxNumberActivePartitions / numInputs,
+		partitionsToProcessUsingMain: make(map[int]*hbpPartitionInfo),
+		inputBatches:                 make([]coldata.Batch, numInputs),
+		inMemMainOpInputs:            inMemMainOpInputs,
+		inMemMainOp:                  inMemMainOpConstructor(inMemMainOpInputs),
+		diskBackedFallbackOpInputs:   diskBackedFallbackOpInputs,
+	}
+	op.diskBackedFallbackOp, op.diskBackedFallbackOpClosers = diskBackedFallbackOpConstructor(
+		fallbackInputs, fallbackMaxNumberPartitions,
+	)
+	op.fdState.fdSemaphore = fdSemaphore
+	// To simplify the accounting, we will assume that the in-memory "main"
+	// operator's memory usage is equal to the size of the partition of the
+	// last input to be processed. This is an overestimate for the operators
+	// that buffer up only some of the tuples (e.g. the hash aggregator keeps a
+	// single tuple per aggregation group) and an underestimate for the
+	// operators that also read batches from the other inputs (e.g. the hash
+	// joiner reads a single batch from the left partition at a time), but that
+	// shouldn't matter in the grand scheme of things.
+	op.maxPartitionSizeToProcessUsingMain = memoryLimit - int64(diskQueuesMemUsed)
+	if op.maxPartitionSizeToProcessUsingMain < hbpMinimalMaxPartitionSizeForMain {
+		op.maxPartitionSizeToProcessUsingMain = hbpMinimalMaxPartitionSizeForMain
+	}
+	op.scratch = make([]coldata.Batch, numInputs)
+	op.recursiveScratch = make([]coldata.Batch, numInputs)
+	for i := range inputTypes {
+		op.scratch[i] = unlimitedAllocator.NewMemBatchWithFixedCapacity(inputTypes[i], coldata.BatchSize())
+		op.recursiveScratch[i] = unlimitedAllocator.NewMemBatchWithFixedCapacity(inputTypes[i], coldata.BatchSize())
+	}
+	op.testingKnobs.numForcedRepartitions = numForcedRepartitions
+	op.testingKnobs.delegateFDAcquisitions = delegateFDAcquisitions
+	return op
+}
+
+// ChildCount implements the execinfra.OpNode interface.
+func (op *hashBasedPartitioner) ChildCount(verbose bool) int {
+	return len(op.inputs)
+}
+
+// Child implements the execinfra.OpNode interface.
+func (op *hashBasedPartitioner) Child(nth int, verbose bool) execinfra.OpNode {
+	return op.inputs[nth]
+}
+
+func (op *hashBasedPartitioner) Init() {
+	for _, input := range op.inputs {
+		input.Init()
+	}
+	// In the processing phase, the in-memory operator will use the default
+	// init hash value, so in order to use a "different" hash function in the
+	// partitioning phase we use a different init hash value.
+	op.tupleDistributor = newTupleHashDistributor(
+		defaultInitHashValue+1, op.numBuckets,
+	)
+	op.state = hbpInitialPartitioning
+}
+
+// partitionBatch distributes the tuples of batch that came from the input with
+// index inputIdx to the partitions of that input. parentMemSize is the memory
+// size of the "parent" partition of the last input being repartitioned and is
+// ignored for other inputs.
+func (op *hashBasedPartitioner) partitionBatch(
+	ctx context.Context, batch coldata.Batch, inputIdx int, parentMemSize int64,
+) {
+	batchLen := batch.Length()
+	if batchLen == 0 {
+		return
+	}
+	scratchBatch := op.scratch[inputIdx]
+	selections := op.tupleDistributor.distribute(ctx, batch, op.hashCols[inputIdx])
+	for idx, sel := range selections {
+		partitionIdx := op.partitionIdxOffset + idx
+		if len(sel) > 0 {
+			scratchBatch.ResetInternalBatch()
+			// The partitioner expects the batches without a selection vector,
+			// so we need to copy the tuples according to the selection vector
+			// into a scratch batch.
+			colVecs := scratchBatch.ColVecs()
+			op.unlimitedAllocator.PerformOperation(colVecs, func() {
+				for i, colvec := range colVecs {
+					colvec.Copy(coldata.CopySliceArgs{
+						SliceArgs: coldata.SliceArgs{
+							Src:       batch.ColVec(i),
+							Sel:       sel,
+							SrcEndIdx: len(sel),
+						},
+					})
+				}
+				scratchBatch.SetLength(len(sel))
+			})
+			if err := op.partitioners[inputIdx].Enqueue(ctx, partitionIdx, scratchBatch); err != nil {
+				colexecerror.InternalError(err)
+			}
+			partitionInfo, ok := op.partitionsToProcessUsingMain[partitionIdx]
+			if !ok {
+				partitionInfo = &hbpPartitionInfo{}
+				op.partitionsToProcessUsingMain[partitionIdx] = partitionInfo
+			}
+			if inputIdx == len(op.inputs)-1 {
+				partitionInfo.parentMemSize = parentMemSize
+				// We cannot use allocator's methods directly because those
+				// look at the capacities of the vectors, and in our case only
+				// first len(sel) tuples belong to the "current" batch.
+				partitionInfo.memSize += colmem.GetProportionalBatchMemSize(scratchBatch, int64(len(sel)))
+			}
+		}
+	}
+}
+
+func (op *hashBasedPartitioner) Next(ctx context.Context) coldata.Batch {
+StateChanged:
+	for {
+		switch op.state {
+		case hbpInitialPartitioning:
+			allInputsExhausted := true
+			for i, input := range op.inputs {
+				op.inputBatches[i] = input.Next(ctx)
+				if op.inputBatches[i].Length() > 0 {
+					allInputsExhausted = false
+				}
+			}
+			if allInputsExhausted {
+				// All inputs have been partitioned and spilled, so we
+				// transition to the "processing" phase. Close all the open
+				// write file descriptors.
+				for _, partitioner := range op.partitioners {
+					if err := partitioner.CloseAllOpenWriteFileDescriptors(ctx); err != nil {
+						colexecerror.InternalError(err)
+					}
+				}
+				op.inMemMainOp.Init()
+				op.partitionIdxOffset += op.numBuckets
+				op.state = hbpProcessNewPartitionUsingMain
+				continue
+			}
+			if !op.testingKnobs.delegateFDAcquisitions && op.fdState.acquiredFDs == 0 {
+				toAcquire := op.maxNumberActivePartitions
+				if err := op.fdState.fdSemaphore.Acquire(ctx, toAcquire); err != nil {
+					colexecerror.InternalError(err)
+				}
+				op.fdState.acquiredFDs = toAcquire
+			}
+			for i, batch := range op.inputBatches {
+				op.partitionBatch(ctx, batch, i, math.MaxInt64)
+			}
+
+		case hbpRecursivePartitioning:
+			op.numRepartitions++
+			if log.V(2) && op.numRepartitions%10 == 0 {
+				log.Infof(ctx, "%s is performing %d'th repartition", op.name, op.numRepartitions)
+			}
+			// In order to use a different hash function when repartitioning, we
+			// need to increase the seed value of the tuple distributor.
+			op.tupleDistributor.initHashValue++
+			// We're actively will be using op.numBuckets + 1 partitions (because
+			// we're repartitioning one input at a time while reading from the
+			// "parent" partition), so we can set op.numBuckets higher than in
+			// the initial partitioning step.
+			op.numBuckets = op.maxNumberActivePartitions - 1
+			op.tupleDistributor.resetNumOutputs(op.numBuckets)
+			for parentPartitionIdx, parentPartitionInfo := range op.partitionsToProcessUsingMain {
+				for inputIdx, partitioner := range op.partitioners {
+					batch := op.recursiveScratch[inputIdx]
+					for {
+						if err := partitioner.Dequeue(ctx, parentPartitionIdx, batch); err != nil {
+							colexecerror.InternalError(err)
+						}
+						if batch.Length() == 0 {
+							break
+						}
+						op.partitionBatch(ctx, batch, inputIdx, parentPartitionInfo.memSize)
+					}
+					// We're done reading from this partition, and it will never
+					// be read from again, so we can close it.
+					if err := partitioner.CloseInactiveReadPartitions(ctx); err != nil {
+						colexecerror.InternalError(err)
+					}
+					// We're done writing to the newly created partitions.
+					if err := partitioner.CloseAllOpenWriteFileDescriptors(ctx); err != nil {
+						colexecerror.InternalError(err)
+					}
+				}
+				for idx := 0; idx < op.numBuckets; idx++ {
+					newPartitionIdx := op.partitionIdxOffset + idx
+					if partitionInfo, ok := op.partitionsToProcessUsingMain[newPartitionIdx]; ok {
+						before, after := partitionInfo.parentMemSize, partitionInfo.memSize
+						if before > 0 {
+							sizeDecrease := 1.0 - float64(after)/float64(before)
+							if sizeDecrease < hbpRecursivePartitioningSizeDecreaseThreshold {
+								// We will need to process this partition using the
+								// "fallback" strategy.
+								op.partitionsToProcessUsingFallback = append(op.partitionsToProcessUsingFallback, newPartitionIdx)
+								delete(op.partitionsToProcessUsingMain, newPartitionIdx)
+							}
+						}
+					}
+				}
+				// We have successfully repartitioned the partitions with index
+				// 'parentPartitionIdx' of all inputs, so we delete that index
+				// from the map and proceed on processing the newly created
+				// partitions.
+				delete(op.partitionsToProcessUsingMain, parentPartitionIdx)
+				op.partitionIdxOffset += op.numBuckets
+				op.state = hbpProcessNewPartitionUsingMain
+				continue StateChanged
+			}
+
+		case hbpProcessNewPartitionUsingMain:
+			if op.testingKnobs.numForcedRepartitions > 0 && len(op.partitionsToProcessUsingMain) > 0 {
+				op.testingKnobs.numForcedRepartitions--
+				op.state = hbpRecursivePartitioning
+				continue
+			}
+			// Find next partition that we can process without having to
+			// recursively repartition.
+			for partitionIdx, partitionInfo := range op.partitionsToProcessUsingMain {
+				if partitionInfo.memSize <= op.maxPartitionSizeToProcessUsingMain {
+					// Update the inputs to the in-memory "main" operator and
+					// reset the latter.
+					for _, input := range op.inMemMainOpInputs {
+						input.partitionIdx = partitionIdx
+					}
+					op.inMemMainOp.reset(ctx)
+					delete(op.partitionsToProcessUsingMain, partitionIdx)
+					op.state = hbpProcessingUsingMain
+					continue StateChanged
+				}
+			}
+			if len(op.partitionsToProcessUsingMain) == 0 {
+				// All partitions to process using the in-memory "main" operator
+				// have been processed.
+				if len(op.partitionsToProcessUsingFallback) > 0 {
+					// But there are still some partitions to process using the
+					// "fallback" strategy.
+					if log.V(2) {
+						log.Infof(ctx,
+							"%s will process %d partitions using the fallback strategy",
+							op.name, len(op.partitionsToProcessUsingFallback),
+						)
+					}
+					for _, input := range op.diskBackedFallbackOpInputs {
+						input.partitionIdxs = op.partitionsToProcessUsingFallback
+					}
+					op.diskBackedFallbackOp.Init()
+					op.state = hbpProcessingUsingFallback
+					continue
+				}
+				// All partitions have been processed, so we transition to
+				// finished state.
+				op.state = hbpFinished
+				continue
+			}
+			// We have partitions that we cannot process without recursively
+			// repartitioning first, so we transition to the corresponding state.
+			op.state = hbpRecursivePartitioning
+			continue
+
+		case hbpProcessingUsingMain:
+			b := op.inMemMainOp.Next(ctx)
+			if b.Length() == 0 {
+				// We're done processing these partitions, so we close them and
+				// transition to processing new ones.
+				for _, partitioner := range op.partitioners {
+					if err := partitioner.CloseInactiveReadPartitions(ctx); err != nil {
+						colexecerror.InternalError(err)
+					}
+				}
+				op.state = hbpProcessNewPartitionUsingMain
+				continue
+			}
+			return b
+
+		case hbpProcessingUsingFallback:
+			b := op.diskBackedFallbackOp.Next(ctx)
+			if b.Length() == 0 {
+				op.state = hbpFinished
+				continue
+			}
+			return b
+
+		case hbpFinished:
+			if err := op.Close(ctx); err != nil {
+				colexecerror.InternalError(err)
+			}
+			return coldata.ZeroBatch
+
+		default:
+			colexecerror.InternalError(errors.AssertionFailedf("unexpected hashBasedPartitionerState %d", op.state))
+		}
+	}
+}
+
+func (op *hashBasedPartitioner) Close(ctx context.Context) error {
+	if !op.close() {
+		return nil
+	}
+	var retErr error
+	for _, partitioner := range op.partitioners {
+		if err := partitioner.Close(ctx); err != nil && retErr == nil {
+			retErr = err
+		}
+	}
+	if c, ok := op.inMemMainOp.(Closer); ok {
+		if err := c.Close(ctx); err != nil && retErr == nil {
+			retErr = err
+		}
+	}
+	if c, ok := op.diskBackedFallbackOp.(Closer); ok {
+		if err := c.Close(ctx); err != nil && retErr == nil {
+			retErr = err
+		}
+	}
+	if err := op.diskBackedFallbackOpClosers.Close(ctx); err != nil && retErr == nil {
+		retErr = err
+	}
+	if !op.testingKnobs.delegateFDAcquisitions && op.fdState.acquiredFDs > 0 {
+		op.fdState.fdSemaphore.Release(op.fdState.acquiredFDs)
+		op.fdState.acquiredFDs = 0
+	}
+	return retErr
+}
+
+// hbpFallbackInput is an Operator that Dequeue's from the partitions of a
+// single input that need to be processed using the "fallback" strategy one
+// after another. The partitions are disjoint on the hash columns, so the
+// "fallback" operator can process all of them at once.
+type hbpFallbackInput struct {
+	*partitionerToOperator
+
+	partitionIdxs []int
+}
+
+var _ colexecbase.Operator = &hbpFallbackInput{}
+
+func (i *hbpFallbackInput) Next(ctx context.Context) coldata.Batch {
+	for len(i.partitionIdxs) > 0 {
+		i.partitionIdx = i.partitionIdxs[0]
+		if b := i.partitionerToOperator.Next(ctx); b.Length() > 0 {
+			return b
+		}
+		// We're done reading from this partition, and it will never be read
+		// from again, so we can close it.
+		if err := i.partitioner.CloseInactiveReadPartitions(ctx); err != nil {
+			colexecerror.InternalError(err)
+		}
+		i.partitionIdxs = i.partitionIdxs[1:]
+	}
+	return coldata.ZeroBatch
+}
+
+// newStableDiskBackedSorter plans a disk-backed sort of input on orderingCols
+// that preserves the relative order of the tuples that are equal on
+// orderingCols. The disk-backed sort is not stable, so an ordinality column
+// is appended to every tuple and is used as the last ordering column. The
+// temporary column is projected out of the output. The "fallback" strategies
+// of the hash-based partitioner use this in order to keep the behavior of
+// the in-memory operators (e.g. the unordered distinct emits the first tuple
+// among all that are identical on the distinct columns). The returned
+// operator is a Closer that closes the disk-backed sort.
+func newStableDiskBackedSorter(
+	allocator *colmem.Allocator,
+	input colexecbase.Operator,
+	inputTypes []*types.T,
+	orderingCols []uint32,
+	maxNumberPartitions int,
+	createDiskBackedSorter func(input colexecbase.Operator, inputTypes []*types.T, orderingCols []execinfrapb.Ordering_Column, maxNumberPartitions int) (colexecbase.Operator, error),
+) colexecbase.Operator {
+	ordinalityColIdx := len(inputTypes)
+	input = NewOrdinalityOp(allocator, input, ordinalityColIdx)
+	sortTypes := make([]*types.T, len(inputTypes), len(inputTypes)+1)
+	copy(sortTypes, inputTypes)
+	sortTypes = append(sortTypes, types.Int)
+	sortOrdering := make([]execinfrapb.Ordering_Column, len(orderingCols)+1)
+	for i := range orderingCols {
+		sortOrdering[i].ColIdx = orderingCols[i]
+	}
+	sortOrdering[len(orderingCols)].ColIdx = uint32(ordinalityColIdx)
+	sorter, err := createDiskBackedSorter(input, sortTypes, sortOrdering, maxNumberPartitions)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	projection := make([]uint32, len(inputTypes))
+	for i := range projection {
+		projection[i] = uint32(i)
+	}
+	return NewSimpleProjectOp(sorter, len(sortTypes), projection)
+}
@@ -348,16 +348,7 @@ func (ht *hashTable) build(ctx context.Context, input colexecbase.Operator) {
 			if batch.Length() == 0 {
 				break
 			}
-			ht.computeHashAndBuildChains(ctx, batch)
-			ht.removeDuplicates(batch, ht.keys, ht.probeScratch.first, ht.probeScratch.next, ht.checkProbeForDistinct)
-			// We only check duplicates when there is at least one buffered
-			// tuple.
-			if ht.vals.Length() > 0 {
-				ht.removeDuplicates(batch, ht.keys, ht.buildScratch.first, ht.buildScratch.next, ht.checkBuildForDistinct)
-			}
-			if batch.Length() > 0 {
-				ht.appendAllDistinct(ctx, batch)
-			}
+			ht.distinctBuild(ctx, batch)
 		}
 
 	default:
@@ -365,6 +356,23 @@ func (ht *hashTable) build(ctx context.Context, input colexecbase.Operator) {
 	}
 }
 
+// distinctBuild appends the tuples from batch that are distinct on the key
+// columns (both within batch and from the tuples already buffered) to the
+// hash table. It must only be used in hashTableDistinctBuildMode. Note that
+// the selection vector of batch is updated to include only such tuples.
+func (ht *hashTable) distinctBuild(ctx context.Context, batch coldata.Batch) {
+	ht.computeHashAndBuildChains(ctx, batch)
+	ht.removeDuplicates(batch, ht.keys, ht.probeScratch.first, ht.probeScratch.next, ht.checkProbeForDistinct)
+	// We only check duplicates when there is at least one buffered
+	// tuple.
+	if ht.vals.Length() > 0 {
+		ht.removeDuplicates(batch, ht.keys, ht.buildScratch.first, ht.buildScratch.next, ht.checkBuildForDistinct)
+	}
+	if batch.Length() > 0 {
+		ht.appendAllDistinct(ctx, batch)
+	}
+}
+
 // computeHashAndBuildChains computes the hash codes of the tuples in batch and
 // then builds 'next' chains between those tuples. The goal is to separate all
 // tuples in batch into singly linked lists containing only tuples with the
@@ -59,7 +59,7 @@ func newPartiallyOrderedDistinct(
 			distinctUnorderedCols = append(distinctUnorderedCols, distinctCol)
 		}
 	}
-	distinct := NewUnorderedDistinct(allocator, chunkerOperator, distinctUnorderedCols, typs)
+	distinct := NewUnorderedDistinct(allocator, allocator, chunkerOperator, distinctUnorderedCols, typs)
 	return &partiallyOrderedDistinct{
 		input:    chunkerOperator,
 		distinct: distinct.(ResettableOperator),
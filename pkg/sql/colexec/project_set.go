@@ -0,0 +1,491 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colexec
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/colconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/arith"
+	"github.com/cockroachdb/errors"
+)
+
+// projectSetOp is the vectorized implementation of the ProjectSet processor.
+// For every input tuple it evaluates the expressions specified in the ROWS
+// FROM syntax (set-returning functions as well as scalar expressions) and
+// emits as many output tuples as the longest of the generated sets, with the
+// input columns repeated in each of the output tuples. Exhausted generators
+// as well as scalar expressions (after the first output tuple) are padded
+// with NULLs.
+//
+// The scalar expressions and the arguments of the set-returning functions are
+// evaluated by the projection operators planned on top of the input, so
+// projectSetOp only needs to expand the input tuples. The input columns are
+// copied into the output in a single "gather" per column (using a selection
+// vector that repeats the index of every input tuple as many times as the
+// tuple is emitted), and the set-returning functions write the generated
+// values directly into the output vectors.
+type projectSetOp struct {
+	OneInputNode
+	closerHelper
+
+	allocator *colmem.Allocator
+	// numInputCols is the number of the original input columns that are
+	// passed through. The remaining columns of the input batches contain the
+	// projected scalar expressions and arguments of the set-returning
+	// functions.
+	numInputCols int
+	outputTypes  []*types.T
+
+	// scalarCols contains, for every scalar expression, the index of the
+	// input column with its values and the index of the output column to copy
+	// them into.
+	scalarCols []projectSetScalarCol
+	// gens contains the generators of the set-returning functions along with
+	// the indices of the corresponding output columns.
+	gens []projectSetGen
+	// genCounts is scratch space for the number of values produced by each of
+	// the generators in a single call to emitTuple.
+	genCounts []int
+
+	// batch is the current input batch and inputIdx is the position of the
+	// current tuple within that batch (before the selection vector is
+	// applied).
+	batch    coldata.Batch
+	inputIdx int
+	// tupleStarted is set when the generators have been started for the
+	// current input tuple, and tupleEmitted is the number of output tuples
+	// that have already been emitted for it.
+	tupleStarted bool
+	tupleEmitted int
+
+	// gatherSel contains, for every output tuple at positions
+	// [gatherStartIdx, gatherStartIdx+len(gatherSel)), the index of the input
+	// tuple in the current input batch that it was generated from. isFirst
+	// indicates, for every position in the output batch, whether that output
+	// tuple is the first one generated from its input tuple.
+	gatherSel      []int
+	gatherStartIdx int
+	isFirst        []bool
+
+	output coldata.Batch
+}
+
+var _ colexecbase.Operator = &projectSetOp{}
+var _ Closer = &projectSetOp{}
+
+type projectSetScalarCol struct {
+	inputIdx  int
+	outputIdx int
+}
+
+type projectSetGen struct {
+	gen setGenerator
+	// outputIdx is the index of the first output column of the generator and
+	// numCols is the number of columns it produces.
+	outputIdx int
+	numCols   int
+}
+
+// setGenerator is a vectorized generator of the values of a set-returning
+// function.
+type setGenerator interface {
+	// start prepares the generator for the input tuple at position rowIdx
+	// (with the selection vector already applied) of batch.
+	start(ctx context.Context, batch coldata.Batch, rowIdx int)
+	// next writes at most n next generated tuples into vecs (one vector per
+	// generated column) starting at position destIdx and returns the number
+	// of written tuples. Zero is returned once the generated set is
+	// exhausted.
+	next(ctx context.Context, vecs []coldata.Vec, destIdx int, n int) int
+	// close releases the resources held for the current input tuple, if any.
+	close()
+}
+
+// NewProjectSetOp returns a new ProjectSet operator. The first numInputCols
+// columns of the input are the original input columns whereas the remaining
+// ones contain the results of the projections for the expressions of the
+// ROWS FROM syntax: for a scalar expression exprs[i], exprCols[i] contains the
+// index of the column with its result, and for a set-returning function
+// exprCols[i] contains the indices of the columns with its arguments.
+// generatedColumns and numColsPerGen describe the output columns of the
+// expressions the same way as the ProjectSetSpec does.
+func NewProjectSetOp(
+	allocator *colmem.Allocator,
+	input colexecbase.Operator,
+	inputTypes []*types.T,
+	numInputCols int,
+	evalCtx *tree.EvalContext,
+	exprs []tree.TypedExpr,
+	exprCols [][]int,
+	generatedColumns []*types.T,
+	numColsPerGen []uint32,
+) (colexecbase.Operator, error) {
+	p := &projectSetOp{
+		OneInputNode: NewOneInputNode(input),
+		allocator:    allocator,
+		numInputCols: numInputCols,
+	}
+	p.outputTypes = make([]*types.T, numInputCols, numInputCols+len(generatedColumns))
+	copy(p.outputTypes, inputTypes[:numInputCols])
+	for i, expr := range exprs {
+		outputIdx := len(p.outputTypes)
+		if funcExpr, ok := expr.(*tree.FuncExpr); ok && funcExpr.IsGeneratorApplication() {
+			numCols := int(numColsPerGen[i])
+			genTypes := generatedColumns[outputIdx-numInputCols : outputIdx-numInputCols+numCols]
+			gen, err := newSetGenerator(evalCtx, funcExpr, inputTypes, exprCols[i], genTypes)
+			if err != nil {
+				return nil, err
+			}
+			p.gens = append(p.gens, projectSetGen{gen: gen, outputIdx: outputIdx, numCols: numCols})
+			p.outputTypes = append(p.outputTypes, genTypes...)
+		} else {
+			p.scalarCols = append(p.scalarCols, projectSetScalarCol{
+				inputIdx: exprCols[i][0], outputIdx: outputIdx,
+			})
+			p.outputTypes = append(p.outputTypes, inputTypes[exprCols[i][0]])
+		}
+	}
+	p.genCounts = make([]int, len(p.gens))
+	return p, nil
+}
+
+// newSetGenerator returns a vectorized generator for the set-returning
+// function funcExpr whose arguments are in the input columns argumentCols and
+// which produces columns of outputTypes. The functions without a specialized
+// implementation are executed by a generator that operates on datums.
+func newSetGenerator(
+	evalCtx *tree.EvalContext,
+	funcExpr *tree.FuncExpr,
+	inputTypes []*types.T,
+	argumentCols []int,
+	outputTypes []*types.T,
+) (setGenerator, error) {
+	switch funcExpr.ResolvedOverload().SpecializedVecBuiltin {
+	case tree.GenerateSeriesIntInt:
+		return &generateSeriesIntGenerator{
+			startIdx: argumentCols[0], stopIdx: argumentCols[1], stepIdx: -1,
+		}, nil
+	case tree.GenerateSeriesIntIntInt:
+		return &generateSeriesIntGenerator{
+			startIdx: argumentCols[0], stopIdx: argumentCols[1], stepIdx: argumentCols[2],
+		}, nil
+	default:
+		props, _ := builtins.GetBuiltinProperties(strings.ToLower(funcExpr.Func.String()))
+		if props == nil || props.Class != tree.GeneratorClass {
+			return nil, errors.AssertionFailedf("%s is not a set-returning function", funcExpr.Func)
+		}
+		g := &datumSetGenerator{
+			evalCtx:      evalCtx,
+			factory:      funcExpr.ResolvedOverload().Generator,
+			nullableArgs: props.NullableArgs,
+			argumentCols: argumentCols,
+			args:         make(tree.Datums, len(argumentCols)),
+			argSel:       make([]int, 1),
+			converters:   make([]func(tree.Datum) interface{}, len(outputTypes)),
+		}
+		for i, typ := range outputTypes {
+			g.converters[i] = GetDatumToPhysicalFn(typ)
+		}
+		return g, nil
+	}
+}
+
+func (p *projectSetOp) Init() {
+	p.input.Init()
+}
+
+// Close is part of the Closer interface.
+func (p *projectSetOp) Close(ctx context.Context) error {
+	if !p.close() {
+		return nil
+	}
+	for _, g := range p.gens {
+		g.gen.close()
+	}
+	if closer, ok := p.input.(Closer); ok {
+		return closer.Close(ctx)
+	}
+	return nil
+}
+
+func (p *projectSetOp) Next(ctx context.Context) coldata.Batch {
+	p.output, _ = p.allocator.ResetMaybeReallocate(p.outputTypes, p.output, coldata.BatchSize())
+	if cap(p.isFirst) < p.output.Capacity() {
+		p.isFirst = make([]bool, p.output.Capacity())
+	}
+	outputIdx := 0
+	p.gatherStartIdx = 0
+	p.allocator.PerformOperation(p.output.ColVecs(), func() {
+		for outputIdx < p.output.Capacity() {
+			if p.batch == nil || p.inputIdx == p.batch.Length() {
+				// Before moving onto the next input batch, we need to copy the
+				// input tuples that were already expanded.
+				p.gather(outputIdx)
+				p.batch = p.input.Next(ctx)
+				p.inputIdx = 0
+				if p.batch.Length() == 0 {
+					return
+				}
+			}
+			rowIdx := p.inputIdx
+			if sel := p.batch.Selection(); sel != nil {
+				rowIdx = sel[rowIdx]
+			}
+			if !p.tupleStarted {
+				for _, g := range p.gens {
+					g.gen.start(ctx, p.batch, rowIdx)
+				}
+				p.tupleStarted = true
+				p.tupleEmitted = 0
+			}
+			n := p.emitTuple(ctx, outputIdx, rowIdx)
+			if n == 0 {
+				// The current input tuple is exhausted.
+				p.tupleStarted = false
+				p.inputIdx++
+				continue
+			}
+			outputIdx += n
+		}
+	})
+	p.gather(outputIdx)
+	p.output.SetLength(outputIdx)
+	return p.output
+}
+
+// emitTuple emits the next output tuples generated from the input tuple at
+// position rowIdx of the current input batch into the output batch starting
+// at position outputIdx. It returns the number of emitted tuples.
+func (p *projectSetOp) emitTuple(ctx context.Context, outputIdx int, rowIdx int) int {
+	toEmit := p.output.Capacity() - outputIdx
+	n := 0
+	if len(p.scalarCols) > 0 && p.tupleEmitted == 0 {
+		// The scalar expressions always produce a single value, so every input
+		// tuple is emitted at least once.
+		n = 1
+	}
+	for i, g := range p.gens {
+		vecs := p.output.ColVecs()[g.outputIdx : g.outputIdx+g.numCols]
+		p.genCounts[i] = g.gen.next(ctx, vecs, outputIdx, toEmit)
+		if p.genCounts[i] > n {
+			n = p.genCounts[i]
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	// The generators that produced fewer values than the longest one are
+	// padded with NULLs.
+	for i, g := range p.gens {
+		if p.genCounts[i] < n {
+			for j := g.outputIdx; j < g.outputIdx+g.numCols; j++ {
+				p.output.ColVec(j).Nulls().SetNullRange(outputIdx+p.genCounts[i], outputIdx+n)
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		p.gatherSel = append(p.gatherSel, rowIdx)
+		p.isFirst[outputIdx+i] = p.tupleEmitted == 0 && i == 0
+	}
+	p.tupleEmitted += n
+	return n
+}
+
+// gather copies the input columns and the results of the scalar expressions
+// for all expanded input tuples from the current input batch into the output
+// batch. The output tuples up to outputIdx are expanded at this point.
+func (p *projectSetOp) gather(outputIdx int) {
+	if len(p.gatherSel) > 0 {
+		for i := 0; i < p.numInputCols; i++ {
+			p.gatherCol(i, i)
+		}
+		for _, c := range p.scalarCols {
+			p.gatherCol(c.inputIdx, c.outputIdx)
+			nulls := p.output.ColVec(c.outputIdx).Nulls()
+			for i := p.gatherStartIdx; i < outputIdx; i++ {
+				if !p.isFirst[i] {
+					nulls.SetNull(i)
+				}
+			}
+		}
+	}
+	p.gatherSel = p.gatherSel[:0]
+	p.gatherStartIdx = outputIdx
+}
+
+func (p *projectSetOp) gatherCol(inputIdx int, outputIdx int) {
+	p.output.ColVec(outputIdx).Copy(
+		coldata.CopySliceArgs{
+			SliceArgs: coldata.SliceArgs{
+				Src:         p.batch.ColVec(inputIdx),
+				Sel:         p.gatherSel,
+				DestIdx:     p.gatherStartIdx,
+				SrcStartIdx: 0,
+				SrcEndIdx:   len(p.gatherSel),
+			},
+		},
+	)
+}
+
+var errGenerateSeriesStepCannotBeZero = pgerror.New(pgcode.InvalidParameterValue, "step cannot be 0")
+
+// generateSeriesIntGenerator is the vectorized implementation of
+// generate_series with integer bounds.
+type generateSeriesIntGenerator struct {
+	// startIdx, stopIdx and stepIdx are the indices of the input columns with
+	// the arguments. stepIdx is -1 if the step is not specified.
+	startIdx, stopIdx, stepIdx int
+
+	value, stop, step int64
+	// ok is false once the set is exhausted.
+	ok bool
+	// generated is the number of values generated for the current input
+	// tuple.
+	generated int
+}
+
+var _ setGenerator = &generateSeriesIntGenerator{}
+
+func (g *generateSeriesIntGenerator) start(_ context.Context, batch coldata.Batch, rowIdx int) {
+	g.generated = 0
+	startVec, stopVec := batch.ColVec(g.startIdx), batch.ColVec(g.stopIdx)
+	if startVec.Nulls().NullAt(rowIdx) || stopVec.Nulls().NullAt(rowIdx) {
+		// generate_series produces an empty set if any of its arguments is
+		// NULL.
+		g.ok = false
+		return
+	}
+	g.value, g.stop, g.step = startVec.Int64()[rowIdx], stopVec.Int64()[rowIdx], 1
+	if g.stepIdx >= 0 {
+		stepVec := batch.ColVec(g.stepIdx)
+		if stepVec.Nulls().NullAt(rowIdx) {
+			g.ok = false
+			return
+		}
+		g.step = stepVec.Int64()[rowIdx]
+		if g.step == 0 {
+			colexecerror.ExpectedError(errGenerateSeriesStepCannotBeZero)
+		}
+	}
+	g.ok = true
+}
+
+func (g *generateSeriesIntGenerator) next(
+	_ context.Context, vecs []coldata.Vec, destIdx int, n int,
+) int {
+	col := vecs[0].Int64()
+	i := 0
+	for ; i < n && g.ok; i++ {
+		if (g.step > 0 && g.value > g.stop) || (g.step < 0 && g.value < g.stop) {
+			g.ok = false
+			break
+		}
+		col[destIdx+i] = g.value
+		g.value, g.ok = arith.AddWithOverflow(g.value, g.step)
+	}
+	return i
+}
+
+func (g *generateSeriesIntGenerator) close() {}
+
+// datumSetGenerator executes the set-returning functions that don't have a
+// specialized vectorized implementation. It converts the arguments of every
+// input tuple into datums, instantiates the tree.ValueGenerator of the
+// function and converts the generated datums into the physical
+// representation of the output vectors.
+type datumSetGenerator struct {
+	evalCtx      *tree.EvalContext
+	factory      tree.GeneratorFactory
+	nullableArgs bool
+	argumentCols []int
+	// args and argSel are scratch space for converting the arguments of a
+	// single input tuple.
+	args       tree.Datums
+	argSel     []int
+	da         rowenc.DatumAlloc
+	converters []func(tree.Datum) interface{}
+	// gen is the value generator for the current input tuple. It is nil if
+	// the generated set is empty or has been exhausted.
+	gen tree.ValueGenerator
+}
+
+var _ setGenerator = &datumSetGenerator{}
+
+func (g *datumSetGenerator) start(ctx context.Context, batch coldata.Batch, rowIdx int) {
+	g.close()
+	g.argSel[0] = rowIdx
+	for i, colIdx := range g.argumentCols {
+		colconv.ColVecToDatumAndDeselect(g.args[i:i+1], batch.ColVec(colIdx), 1 /* length */, g.argSel, &g.da)
+		if g.args[i] == tree.DNull && !g.nullableArgs {
+			// The generated set is empty if any of the arguments is NULL and
+			// the function can't handle NULLs.
+			return
+		}
+	}
+	gen, err := g.factory(g.evalCtx, g.args)
+	if err != nil {
+		colexecerror.ExpectedError(err)
+	}
+	if gen == nil {
+		return
+	}
+	if err := gen.Start(ctx, g.evalCtx.Txn); err != nil {
+		colexecerror.ExpectedError(err)
+	}
+	g.gen = gen
+}
+
+func (g *datumSetGenerator) next(
+	ctx context.Context, vecs []coldata.Vec, destIdx int, n int,
+) int {
+	i := 0
+	for ; i < n && g.gen != nil; i++ {
+		ok, err := g.gen.Next(ctx)
+		if err != nil {
+			colexecerror.ExpectedError(err)
+		}
+		if !ok {
+			g.close()
+			break
+		}
+		values, err := g.gen.Values()
+		if err != nil {
+			colexecerror.ExpectedError(err)
+		}
+		for j, v := range values {
+			if v == tree.DNull {
+				vecs[j].Nulls().SetNull(destIdx + i)
+			} else {
+				coldata.SetValueAt(vecs[j], g.converters[j](v), destIdx+i)
+			}
+		}
+	}
+	return i
+}
+
+func (g *datumSetGenerator) close() {
+	if g.gen != nil {
+		g.gen.Close()
+		g.gen = nil
+	}
+}
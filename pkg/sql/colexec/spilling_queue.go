@@ -63,6 +63,17 @@ type spillingQueue struct {
 	diskAcc *mon.BoundAccount
 }
 
+// NewSpillingQueueArgs contains the arguments of newSpillingQueue except for
+// the types of the enqueued batches. It is used by the operators that can
+// optionally use a spillingQueue to be passed the queue configuration.
+type NewSpillingQueueArgs struct {
+	UnlimitedAllocator *colmem.Allocator
+	MemoryLimit        int64
+	DiskQueueCfg       colcontainer.DiskQueueCfg
+	FDSemaphore        semaphore.Semaphore
+	DiskAcc            *mon.BoundAccount
+}
+
 // newSpillingQueue creates a new spillingQueue. An unlimited allocator must be
 // passed in. The spillingQueue will use this allocator to check whether memory
 // usage exceeds the given memory limit and use disk if so.
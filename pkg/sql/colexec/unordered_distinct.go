@@ -15,16 +15,26 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
 )
 
 // NewUnorderedDistinct creates an unordered distinct on the given distinct
 // columns.
-// numHashBuckets determines the number of buckets that the hash table is
-// created with.
+// - allocator is used to buffer the distinct tuples in the hash table.
+// - outputUnlimitedAllocator is used to allocate the output batch. It must
+// have been created with an unlimited memory account so that the memory
+// limit is not reached once the output has started being emitted (at which
+// point the unordered distinct can no longer fall back to the external
+// distinct).
 func NewUnorderedDistinct(
-	allocator *colmem.Allocator, input colexecbase.Operator, distinctCols []uint32, typs []*types.T,
+	allocator *colmem.Allocator,
+	outputUnlimitedAllocator *colmem.Allocator,
+	input colexecbase.Operator,
+	distinctCols []uint32,
+	typs []*types.T,
 ) colexecbase.Operator {
 	// This number was chosen after running the micro-benchmarks.
 	const hashTableLoadFactor = 2.0
@@ -42,10 +52,10 @@ func NewUnorderedDistinct(
 	)
 
 	return &unorderedDistinct{
-		OneInputNode: NewOneInputNode(input),
-		allocator:    allocator,
-		ht:           ht,
-		typs:         typs,
+		OneInputNode:             NewOneInputNode(input),
+		outputUnlimitedAllocator: outputUnlimitedAllocator,
+		ht:                       ht,
+		typs:                     typs,
 	}
 }
 
@@ -59,18 +69,30 @@ func NewUnorderedDistinct(
 type unorderedDistinct struct {
 	OneInputNode
 
-	allocator     *colmem.Allocator
-	ht            *hashTable
-	typs          []*types.T
-	buildFinished bool
+	outputUnlimitedAllocator *colmem.Allocator
+	ht                       *hashTable
+	typs                     []*types.T
+	buildFinished            bool
+	// lastInputBatch is the last batch read from the input while building the
+	// hash table. If the memory limit is reached while the distinct tuples
+	// from this batch are being appended to the hash table, the batch needs
+	// to be exported along with the buffered tuples.
+	lastInputBatch coldata.Batch
 
 	distinctCount int
 
 	output           coldata.Batch
 	outputBatchStart int
+
+	exportState struct {
+		numExported       int
+		lastBatchExported bool
+		windowedBatch     coldata.Batch
+	}
 }
 
-var _ colexecbase.Operator = &unorderedDistinct{}
+var _ colexecbase.BufferingInMemoryOperator = &unorderedDistinct{}
+var _ ResettableOperator = &unorderedDistinct{}
 
 func (op *unorderedDistinct) Init() {
 	op.input.Init()
@@ -80,8 +102,14 @@ func (op *unorderedDistinct) Next(ctx context.Context) coldata.Batch {
 	// First, build the hash table and populate the selection vector that
 	// includes only distinct tuples.
 	if !op.buildFinished {
+		for {
+			op.lastInputBatch = op.input.Next(ctx)
+			if op.lastInputBatch.Length() == 0 {
+				break
+			}
+			op.ht.distinctBuild(ctx, op.lastInputBatch)
+		}
 		op.buildFinished = true
-		op.ht.build(ctx, op.input)
 
 		// We're using the hashTable in distinct mode, so it buffers only distinct
 		// tuples, as a result, we will be simply returning all buffered tuples.
@@ -90,7 +118,7 @@ func (op *unorderedDistinct) Next(ctx context.Context) coldata.Batch {
 	if op.outputBatchStart == op.distinctCount {
 		return coldata.ZeroBatch
 	}
-	op.output, _ = op.allocator.ResetMaybeReallocate(op.typs, op.output, op.distinctCount-op.outputBatchStart)
+	op.output, _ = op.outputUnlimitedAllocator.ResetMaybeReallocate(op.typs, op.output, op.distinctCount-op.outputBatchStart)
 
 	// Create and return the next batch of input to a maximum size equal to the
 	// capacity of the output batch.
@@ -101,7 +129,7 @@ func (op *unorderedDistinct) Next(ctx context.Context) coldata.Batch {
 	}
 	nSelected = batchEnd - op.outputBatchStart
 
-	op.allocator.PerformOperation(op.output.ColVecs(), func() {
+	op.outputUnlimitedAllocator.PerformOperation(op.output.ColVecs(), func() {
 		for colIdx, fromCol := range op.ht.vals.ColVecs() {
 			toCol := op.output.ColVec(colIdx)
 			toCol.Copy(
@@ -121,6 +149,45 @@ func (op *unorderedDistinct) Next(ctx context.Context) coldata.Batch {
 	return op.output
 }
 
+// ExportBuffered exports all the distinct tuples buffered in the hash table
+// followed by the last batch read from the input. Note that the tuples of the
+// last batch might have been already appended to the hash table, so some
+// tuples might be exported twice, but that is ok since the disk-backed
+// operator will remove the duplicates.
+func (op *unorderedDistinct) ExportBuffered(context.Context, colexecbase.Operator) coldata.Batch {
+	if op.buildFinished {
+		// The memory limit cannot be reached once the hash table has been
+		// built since the output is allocated with the unlimited allocator.
+		colexecerror.InternalError(errors.AssertionFailedf(
+			"unordered distinct is asked to export buffered tuples after the build finished",
+		))
+	}
+	if op.exportState.numExported < op.ht.vals.Length() {
+		if op.exportState.windowedBatch == nil {
+			op.exportState.windowedBatch = op.outputUnlimitedAllocator.NewMemBatchWithFixedCapacity(op.typs, 0 /* size */)
+		}
+		newNumExported := op.exportState.numExported + coldata.BatchSize()
+		if newNumExported > op.ht.vals.Length() {
+			newNumExported = op.ht.vals.Length()
+		}
+		// We don't need to worry about selection vectors on op.ht.vals because
+		// the tuples have been already selected during building of the hash
+		// table.
+		for i := range op.typs {
+			window := op.ht.vals.ColVec(i).Window(op.exportState.numExported, newNumExported)
+			op.exportState.windowedBatch.ReplaceCol(window, i)
+		}
+		op.exportState.windowedBatch.SetLength(newNumExported - op.exportState.numExported)
+		op.exportState.numExported = newNumExported
+		return op.exportState.windowedBatch
+	}
+	if !op.exportState.lastBatchExported && op.lastInputBatch != nil {
+		op.exportState.lastBatchExported = true
+		return op.lastInputBatch
+	}
+	return coldata.ZeroBatch
+}
+
 // reset resets the unorderedDistinct.
 func (op *unorderedDistinct) reset(ctx context.Context) {
 	if r, ok := op.input.(resetter); ok {
@@ -130,6 +197,9 @@ func (op *unorderedDistinct) reset(ctx context.Context) {
 	op.ht.vals.SetLength(0)
 	op.buildFinished = false
 	op.ht.reset(ctx)
+	op.lastInputBatch = nil
 	op.distinctCount = 0
 	op.outputBatchStart = 0
+	op.exportState.numExported = 0
+	op.exportState.lastBatchExported = false
 }
@@ -1149,7 +1149,27 @@ func assertTuplesSetsEqual(expected tuples, actual tuples, evalCtx *tree.EvalCon
 	}
 	actual = actual.sort(evalCtx)
 	expected = expected.sort(evalCtx)
-	return assertTuplesOrderedEqual(expected, actual, evalCtx)
+	if err := assertTuplesOrderedEqual(expected, actual, evalCtx); err == nil {
+		return nil
+	}
+	// The expected and the actual tuples might be sorted differently if they
+	// use different representations of the same values (e.g. JSON values are
+	// specified as strings in the expected tuples but are datums in the actual
+	// ones), so we match each expected tuple against the actual ones.
+	matched := make([]bool, len(actual))
+	for _, e := range expected {
+		found := false
+		for j, a := range actual {
+			if !matched[j] && tupleEquals(e, a, evalCtx) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return makeError(expected, actual)
+		}
+	}
+	return nil
 }
 
 // assertTuplesOrderedEqual asserts that two permutations of tuples are equal
@@ -83,5 +83,5 @@ type BufferingInMemoryOperator interface {
 	//
 	// Calling ExportBuffered may invalidate the contents of the last batch
 	// returned by ExportBuffered.
-	ExportBuffered(input Operator) coldata.Batch
+	ExportBuffered(ctx context.Context, input Operator) coldata.Batch
 }
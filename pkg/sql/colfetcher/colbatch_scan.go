@@ -25,9 +25,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/errors"
 )
 
@@ -258,3 +260,48 @@ func initCRowFetcher(
 
 	return index, isSecondaryIndex, nil
 }
+
+// initRowFetcher initializes a row.Fetcher which is used by the vectorized
+// operators that need to fetch rows with finer control than the cFetcher
+// provides (like seeking or decoding of inverted columns).
+func initRowFetcher(
+	flowCtx *execinfra.FlowCtx,
+	fetcher *row.Fetcher,
+	desc *tabledesc.Immutable,
+	indexIdx int,
+	colIdxMap map[descpb.ColumnID]int,
+	valNeededForCol util.FastIntSet,
+	mon *mon.BytesMonitor,
+	alloc *rowenc.DatumAlloc,
+	lockStrength descpb.ScanLockingStrength,
+	lockWaitPolicy descpb.ScanLockingWaitPolicy,
+) (index *descpb.IndexDescriptor, isSecondaryIndex bool, err error) {
+	index, isSecondaryIndex, err = desc.FindIndexByIndexIdx(indexIdx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tableArgs := row.FetcherTableArgs{
+		Desc:             desc,
+		Index:            index,
+		ColIdxMap:        colIdxMap,
+		IsSecondaryIndex: isSecondaryIndex,
+		Cols:             desc.Columns,
+		ValNeededForCol:  valNeededForCol,
+	}
+	if err := fetcher.Init(
+		flowCtx.EvalCtx.Context,
+		flowCtx.Codec(),
+		false, /* reverse */
+		lockStrength,
+		lockWaitPolicy,
+		false, /* isCheck */
+		alloc,
+		mon,
+		tableArgs,
+	); err != nil {
+		return nil, false, err
+	}
+
+	return index, isSecondaryIndex, nil
+}
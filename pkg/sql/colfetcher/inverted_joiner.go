@@ -0,0 +1,747 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/colconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/invertedeval"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedidx"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/scrub"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/span"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+)
+
+// invertedJoinerBatchSize is the number of input rows for which the inverted
+// expressions are evaluated together. See the comment on the same constant in
+// the rowexec package for more details.
+const invertedJoinerBatchSize = 100
+
+// invertedJoinerState represents the state of the ColInvertedJoiner.
+type invertedJoinerState int
+
+const (
+	// ijReadingInput means that a batch of rows is being read from the input.
+	ijReadingInput invertedJoinerState = iota
+	// ijPerformingIndexScan means it is performing an inverted index scan
+	// for the current input row batch.
+	ijPerformingIndexScan
+	// ijEmittingRows means it is emitting the results of the inverted join.
+	ijEmittingRows
+	// ijDone means that the input has been fully consumed and all the results
+	// have been emitted.
+	ijDone
+)
+
+// ColInvertedJoiner is the exec.Operator implementation of InvertedJoiner.
+// It reads the input in batches of rows, evaluates the inverted expressions
+// of each batch against the inverted index of the table (de-duplicating the
+// primary keys retrieved from the index in a disk-backed row container), and
+// then evaluates the ON expression on the joined rows. Please refer to the
+// comment on rowexec.invertedJoiner for more details.
+//
+// The output consists of the input columns followed by the columns of the
+// table for INNER and LEFT OUTER joins, and of only the input columns for
+// LEFT SEMI and LEFT ANTI joins. The input tuples of each batch of rows are
+// buffered in the columnar format, and the input columns of the output are
+// copied from them a batch at a time.
+type ColInvertedJoiner struct {
+	colexec.OneInputNode
+
+	allocator *colmem.Allocator
+	flowCtx   *execinfra.FlowCtx
+	evalCtx   *tree.EvalContext
+	state     invertedJoinerState
+
+	desc tabledesc.Immutable
+	// The map from ColumnIDs in the table to the column position.
+	colIdxMap map[descpb.ColumnID]int
+	index     *descpb.IndexDescriptor
+	// The ColumnID of the inverted column. Confusingly, this is also the id of
+	// the table column that was indexed.
+	invertedColID descpb.ColumnID
+
+	onExprHelper execinfrapb.ExprHelper
+	combinedRow  rowenc.EncDatumRow
+
+	joinType descpb.JoinType
+
+	fetcher  row.Fetcher
+	alloc    rowenc.DatumAlloc
+	rowAlloc rowenc.EncDatumRowAlloc
+	rowsRead int64
+
+	// keyRow, keyTypes, tableRow and the maps between them are used to
+	// transform the rows retrieved from the index into the primary key rows
+	// that are stored in keyRows and back. See the comment on the same fields
+	// of rowexec.invertedJoiner for more details.
+	keyRow              rowenc.EncDatumRow
+	keyTypes            []*types.T
+	tableRowToKeyRowMap map[int]int
+	tableRow            rowenc.EncDatumRow
+	keyRowToTableRowMap []int
+
+	inputTypes           []*types.T
+	datumsToInvertedExpr invertedexpr.DatumsToInvertedExpr
+	canPreFilter         bool
+	// Batch size for fetches. Not a constant so we can lower for testing.
+	batchSize int
+
+	// toDatumConverter converts the columns of the current input batch into
+	// datums from which the input rows are constructed.
+	toDatumConverter *colconv.VecToDatumConverter
+	// inputBatch is the current input batch and inputIdx is the position of
+	// the next tuple to read within that batch.
+	inputBatch coldata.Batch
+	inputIdx   int
+	// inputDone is set once the input has been fully consumed.
+	inputDone bool
+
+	// State variables for each batch of input rows. inputTuples contains the
+	// same rows as inputRows in the columnar format.
+	inputTuples     coldata.Batch
+	inputRows       rowenc.EncDatumRows
+	batchedExprEval invertedeval.BatchedInvertedExprEvaluator
+	// The row indexes that are the result of the inverted expression evaluation
+	// of the join. These will be further filtered using the onExpr.
+	joinedRowIdx [][]invertedeval.KeyIndex
+
+	// The container for the primary key rows retrieved from the index which
+	// de-duplicates them.
+	keyRows *rowcontainer.DiskBackedNumberedRowContainer
+
+	// emitCursor contains information about where the next row to emit is within
+	// joinedRowIdx.
+	emitCursor struct {
+		// inputRowIdx corresponds to joinedRowIdx[inputRowIdx].
+		inputRowIdx int
+		// outputRowIdx corresponds to joinedRowIdx[inputRowIdx][outputRowIdx].
+		outputRowIdx int
+		// seenMatch is true if there was a match at the current inputRowIdx.
+		seenMatch bool
+	}
+
+	spanBuilder *span.Builder
+	// A row with one element, corresponding to an encoded inverted column
+	// value. Used to construct the span of the index for that value.
+	invertedColRow rowenc.EncDatumRow
+
+	// neededColumns contains the ordinals of the output columns that are used
+	// by the post-processing. Only these columns are populated in the output
+	// batch.
+	neededColumns []int
+	// outputInputIdx contains the index of the tuple in inputTuples for each
+	// row of the output batch.
+	outputInputIdx []int
+	// outputTableRows contains the table rows of the output batch before they
+	// are converted into the columnar format. It is only used if the output
+	// includes the table columns.
+	outputTableRows rowenc.EncDatumRows
+	// nullTableRow is the table row of the unmatched rows of LEFT OUTER joins.
+	nullTableRow rowenc.EncDatumRow
+	da           rowenc.DatumAlloc
+	output       coldata.Batch
+
+	// init is true after Init() has been called.
+	init bool
+
+	// ResultTypes is the slice of resulting column types from this operator.
+	ResultTypes []*types.T
+}
+
+var _ colexecbase.Operator = &ColInvertedJoiner{}
+var _ execinfra.IOReader = &ColInvertedJoiner{}
+var _ execinfrapb.MetadataSource = &ColInvertedJoiner{}
+var _ colexec.Closer = &ColInvertedJoiner{}
+
+// NewColInvertedJoiner creates a new ColInvertedJoiner operator. memMonitor
+// and diskMonitor are used by the row container that de-duplicates the
+// primary keys retrieved from the inverted index.
+func NewColInvertedJoiner(
+	allocator *colmem.Allocator,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.InvertedJoinerSpec,
+	post *execinfrapb.PostProcessSpec,
+	input colexecbase.Operator,
+	inputTypes []*types.T,
+	memMonitor *mon.BytesMonitor,
+	diskMonitor *mon.BytesMonitor,
+) (*ColInvertedJoiner, error) {
+	switch spec.Type {
+	case descpb.InnerJoin, descpb.LeftOuterJoin, descpb.LeftSemiJoin, descpb.LeftAntiJoin:
+	default:
+		return nil, errors.AssertionFailedf("inverted join of type %s is not supported", spec.Type)
+	}
+	ij := &ColInvertedJoiner{
+		OneInputNode: colexec.NewOneInputNode(input),
+		allocator:    allocator,
+		flowCtx:      flowCtx,
+		evalCtx:      flowCtx.NewEvalCtx(),
+		desc:         tabledesc.MakeImmutable(spec.Table),
+		inputTypes:   inputTypes,
+		joinType:     spec.Type,
+		batchSize:    invertedJoinerBatchSize,
+	}
+	ij.colIdxMap = ij.desc.ColumnIdxMap()
+
+	var err error
+	ij.index, _, err = ij.desc.FindIndexByIndexIdx(int(spec.IndexIdx))
+	if err != nil {
+		return nil, err
+	}
+	ij.invertedColID = ij.index.ColumnIDs[0]
+
+	indexColumnIDs, _ := ij.index.FullColumnIDs()
+	// Inverted joins are not used for mutations.
+	tableColumns := ij.desc.ColumnsWithMutations(false /* mutations */)
+	ij.keyRow = make(rowenc.EncDatumRow, len(indexColumnIDs)-1)
+	ij.keyTypes = make([]*types.T, len(ij.keyRow))
+	ij.tableRow = make(rowenc.EncDatumRow, len(tableColumns))
+	ij.tableRowToKeyRowMap = make(map[int]int)
+	ij.keyRowToTableRowMap = make([]int, len(indexColumnIDs)-1)
+	for i := 1; i < len(indexColumnIDs); i++ {
+		keyRowIdx := i - 1
+		tableRowIdx := ij.colIdxMap[indexColumnIDs[i]]
+		ij.tableRowToKeyRowMap[tableRowIdx] = keyRowIdx
+		ij.keyRowToTableRowMap[keyRowIdx] = tableRowIdx
+		ij.keyTypes[keyRowIdx] = ij.desc.Columns[tableRowIdx].Type
+	}
+
+	// Inverted joins are not used for mutations.
+	rightColTypes := ij.desc.ColumnTypesWithMutations(false /* mutations */)
+	onExprColTypes := make([]*types.T, 0, len(inputTypes)+len(rightColTypes))
+	onExprColTypes = append(onExprColTypes, inputTypes...)
+	onExprColTypes = append(onExprColTypes, rightColTypes...)
+	if ij.joinType == descpb.InnerJoin || ij.joinType == descpb.LeftOuterJoin {
+		ij.ResultTypes = onExprColTypes
+	} else {
+		ij.ResultTypes = inputTypes
+	}
+
+	semaCtx := tree.MakeSemaContext()
+	// Before we can safely use types from the table descriptor, we need to
+	// make sure they are hydrated.
+	resolver := flowCtx.TypeResolverFactory.NewTypeResolver(ij.evalCtx.Txn)
+	semaCtx.TypeResolver = resolver
+	if err := resolver.HydrateTypeSlice(ij.evalCtx.Context, rightColTypes); err != nil {
+		return nil, err
+	}
+	if err := ij.onExprHelper.Init(spec.OnExpr, onExprColTypes, &semaCtx, ij.evalCtx); err != nil {
+		return nil, err
+	}
+	ij.combinedRow = make(rowenc.EncDatumRow, 0, len(onExprColTypes))
+	ij.nullTableRow = make(rowenc.EncDatumRow, len(ij.tableRow))
+	for i := range ij.nullTableRow {
+		ij.nullTableRow[i] = rowenc.EncDatum{Datum: tree.DNull}
+	}
+
+	var invertedExprHelper execinfrapb.ExprHelper
+	if err := invertedExprHelper.Init(spec.InvertedExpr, onExprColTypes, &semaCtx, ij.evalCtx); err != nil {
+		return nil, err
+	}
+	ij.datumsToInvertedExpr, err = invertedidx.NewDatumsToInvertedExpr(
+		ij.evalCtx, onExprColTypes, invertedExprHelper.Expr, ij.index,
+	)
+	if err != nil {
+		return nil, err
+	}
+	ij.canPreFilter = ij.datumsToInvertedExpr.CanPreFilter()
+	if ij.canPreFilter {
+		ij.batchedExprEval.Filterer = ij.datumsToInvertedExpr
+	}
+
+	neededColumns, err := neededColumnsForJoin(
+		post, execinfrapb.Expression{} /* onExpr */, ij.ResultTypes, &semaCtx, ij.evalCtx,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i, ok := neededColumns.Next(0); ok; i, ok = neededColumns.Next(i + 1) {
+		ij.neededColumns = append(ij.neededColumns, i)
+	}
+
+	// In general we need all the columns in the index to compute the set
+	// expression.
+	allIndexCols := util.MakeFastIntSet()
+	for _, colID := range indexColumnIDs {
+		allIndexCols.Add(ij.colIdxMap[colID])
+	}
+	if _, _, err = initRowFetcher(
+		flowCtx, &ij.fetcher, &ij.desc, int(spec.IndexIdx), ij.colIdxMap,
+		allIndexCols, flowCtx.EvalCtx.Mon, &ij.alloc,
+		descpb.ScanLockingStrength_FOR_NONE, descpb.ScanLockingWaitPolicy_BLOCK,
+	); err != nil {
+		return nil, err
+	}
+
+	ij.spanBuilder = span.MakeBuilder(flowCtx.Codec(), &ij.desc, ij.index)
+	ij.spanBuilder.SetNeededColumns(allIndexCols)
+
+	inputColIdxs := make([]int, len(inputTypes))
+	for i := range inputColIdxs {
+		inputColIdxs[i] = i
+	}
+	ij.toDatumConverter = colconv.NewVecToDatumConverter(len(inputTypes), inputColIdxs)
+	ij.keyRows = rowcontainer.NewDiskBackedNumberedRowContainer(
+		true, /* deDup */
+		ij.keyTypes,
+		ij.evalCtx,
+		flowCtx.Cfg.TempStorage,
+		memMonitor,
+		diskMonitor,
+	)
+	return ij, nil
+}
+
+// SetBatchSize sets the desired batch size. It should only be used in tests.
+func (ij *ColInvertedJoiner) SetBatchSize(batchSize int) {
+	ij.batchSize = batchSize
+}
+
+// Init is part of the Operator interface.
+func (ij *ColInvertedJoiner) Init() {
+	ij.init = true
+	ij.inputTuples = ij.allocator.NewMemBatchWithFixedCapacity(ij.inputTypes, ij.batchSize)
+	ij.Input().Init()
+}
+
+// Next is part of the Operator interface.
+func (ij *ColInvertedJoiner) Next(ctx context.Context) coldata.Batch {
+	if ij.state == ijDone {
+		return coldata.ZeroBatch
+	}
+	var reallocated bool
+	ij.output, reallocated = ij.allocator.ResetMaybeReallocate(ij.ResultTypes, ij.output, 1 /* minCapacity */)
+	if reallocated {
+		ij.outputInputIdx = make([]int, ij.output.Capacity())
+		if len(ij.ResultTypes) > len(ij.inputTypes) {
+			oldRows := ij.outputTableRows
+			ij.outputTableRows = make(rowenc.EncDatumRows, ij.output.Capacity())
+			for i := range ij.outputTableRows {
+				if len(oldRows) > 0 {
+					ij.outputTableRows[i] = oldRows[0]
+					oldRows = oldRows[1:]
+				} else {
+					ij.outputTableRows[i] = make(rowenc.EncDatumRow, len(ij.tableRow))
+				}
+			}
+		}
+	}
+	nRows := 0
+	for nRows < ij.output.Capacity() && ij.state != ijDone {
+		if ij.state == ijReadingInput && nRows > 0 {
+			// The input columns of the output rows are copied from the tuples
+			// of the current batch of input rows, so the output has to be
+			// emitted before the next batch is read.
+			break
+		}
+		switch ij.state {
+		case ijReadingInput:
+			ij.state = ij.readInput(ctx)
+		case ijPerformingIndexScan:
+			ij.state = ij.performScan(ctx)
+		case ijEmittingRows:
+			var emitted bool
+			ij.state, emitted = ij.emitRow(ctx, nRows)
+			if emitted {
+				nRows++
+			}
+		default:
+			colexecerror.InternalError(errors.AssertionFailedf("unsupported state: %d", ij.state))
+		}
+	}
+	numInputCols := len(ij.inputTypes)
+	ij.allocator.PerformOperation(ij.output.ColVecs()[:numInputCols], func() {
+		for _, colIdx := range ij.neededColumns {
+			if colIdx >= numInputCols {
+				break
+			}
+			ij.output.ColVec(colIdx).Copy(coldata.CopySliceArgs{
+				SliceArgs: coldata.SliceArgs{
+					Src:       ij.inputTuples.ColVec(colIdx),
+					Sel:       ij.outputInputIdx[:nRows],
+					SrcEndIdx: nRows,
+				},
+			})
+		}
+	})
+	for _, colIdx := range ij.neededColumns {
+		if colIdx < numInputCols {
+			continue
+		}
+		if err := colexec.EncDatumRowsToColVec(
+			ij.allocator, ij.outputTableRows[:nRows], ij.output.ColVec(colIdx), colIdx-numInputCols,
+			ij.ResultTypes[colIdx], &ij.da,
+		); err != nil {
+			colexecerror.InternalError(err)
+		}
+	}
+	ij.output.SetLength(nRows)
+	return ij.output
+}
+
+// bufferOutputRow stores the index of the input tuple and the table row of
+// the output row at position idx. The needed columns of the table row are
+// decoded right away since the rows retrieved from the row container are only
+// valid until the next access.
+func (ij *ColInvertedJoiner) bufferOutputRow(idx int, inputRowIdx int, tableRow rowenc.EncDatumRow) {
+	ij.outputInputIdx[idx] = inputRowIdx
+	if ij.outputTableRows == nil {
+		return
+	}
+	numInputCols := len(ij.inputTypes)
+	copy(ij.outputTableRows[idx], tableRow)
+	for _, colIdx := range ij.neededColumns {
+		if colIdx < numInputCols {
+			continue
+		}
+		if err := ij.outputTableRows[idx][colIdx-numInputCols].EnsureDecoded(ij.ResultTypes[colIdx], &ij.da); err != nil {
+			colexecerror.InternalError(err)
+		}
+	}
+}
+
+// readInput reads the next batch of input rows and starts an index scan.
+func (ij *ColInvertedJoiner) readInput(ctx context.Context) invertedJoinerState {
+	// Read the next batch of input rows.
+	for len(ij.inputRows) < ij.batchSize && !ij.inputDone {
+		if ij.inputBatch == nil || ij.inputIdx >= ij.inputBatch.Length() {
+			ij.inputBatch = ij.Input().Next(ctx)
+			ij.inputIdx = 0
+			if ij.inputBatch.Length() == 0 {
+				ij.inputDone = true
+				break
+			}
+			ij.toDatumConverter.ConvertBatchAndDeselect(ij.inputBatch)
+		}
+		startIdx := ij.inputIdx
+		endIdx := startIdx + ij.batchSize - len(ij.inputRows)
+		if endIdx > ij.inputBatch.Length() {
+			endIdx = ij.inputBatch.Length()
+		}
+		destIdx := ij.inputTuples.Length()
+		ij.allocator.PerformOperation(ij.inputTuples.ColVecs(), func() {
+			for i := range ij.inputTypes {
+				ij.inputTuples.ColVec(i).Copy(coldata.CopySliceArgs{
+					SliceArgs: coldata.SliceArgs{
+						Src:         ij.inputBatch.ColVec(i),
+						Sel:         ij.inputBatch.Selection(),
+						DestIdx:     destIdx,
+						SrcStartIdx: startIdx,
+						SrcEndIdx:   endIdx,
+					},
+				})
+			}
+		})
+		ij.inputTuples.SetLength(destIdx + endIdx - startIdx)
+		for ; ij.inputIdx < endIdx; ij.inputIdx++ {
+			row := ij.rowAlloc.AllocRow(len(ij.inputTypes))
+			for i := range row {
+				// Note that we don't need to apply the selection vector to
+				// inputIdx because the converter returns "dense" datum columns.
+				row[i] = rowenc.DatumToEncDatum(
+					ij.inputTypes[i], ij.toDatumConverter.GetDatumColumn(i)[ij.inputIdx],
+				)
+			}
+			ij.addInputRow(ctx, row)
+		}
+	}
+
+	if len(ij.inputRows) == 0 {
+		log.VEventf(ctx, 1, "no more input rows")
+		return ijDone
+	}
+	log.VEventf(ctx, 1, "read %d input rows", len(ij.inputRows))
+
+	spans := ij.batchedExprEval.Init()
+	if len(spans) == 0 {
+		// Nothing to scan. For each input row, place a nil slice in the joined
+		// rows, for emitRow() to process.
+		ij.joinedRowIdx = ij.joinedRowIdx[:0]
+		for range ij.inputRows {
+			ij.joinedRowIdx = append(ij.joinedRowIdx, nil)
+		}
+		return ijEmittingRows
+	}
+	// NB: spans is already sorted, and that sorting is preserved when
+	// generating indexSpans.
+	indexSpans, err := ij.generateSpans(spans)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+
+	log.VEventf(ctx, 1, "scanning %d spans", len(indexSpans))
+	if err := ij.fetcher.StartScan(
+		ctx, ij.flowCtx.Txn, indexSpans, false /* limitBatches */, 0, /* limitHint */
+		ij.flowCtx.TraceKV,
+	); err != nil {
+		colexecerror.InternalError(colexecerror.NewStorageError(err))
+	}
+	return ijPerformingIndexScan
+}
+
+// addInputRow adds the given row to the current batch of input rows.
+func (ij *ColInvertedJoiner) addInputRow(ctx context.Context, row rowenc.EncDatumRow) {
+	expr, preFilterState, err := ij.datumsToInvertedExpr.Convert(ctx, row)
+	if err != nil {
+		colexecerror.ExpectedError(err)
+	}
+	if expr == nil &&
+		(ij.joinType != descpb.LeftOuterJoin && ij.joinType != descpb.LeftAntiJoin) {
+		// One of the input columns was NULL, resulting in a nil expression.
+		// The join type will emit no row since the evaluation result will be
+		// an empty set, so don't bother keeping the input row.
+		ij.inputRows = append(ij.inputRows, nil)
+	} else {
+		ij.inputRows = append(ij.inputRows, row)
+	}
+	// A nil expression serves as a marker that will result in an empty set
+	// as the evaluation result.
+	ij.batchedExprEval.Exprs = append(ij.batchedExprEval.Exprs, expr)
+	if ij.canPreFilter {
+		if expr == nil {
+			preFilterState = nil
+		}
+		ij.batchedExprEval.PreFilterState = append(ij.batchedExprEval.PreFilterState, preFilterState)
+	}
+}
+
+func (ij *ColInvertedJoiner) generateSpan(enc []byte) (roachpb.Span, error) {
+	// Pretend that the encoded inverted val is an EncDatum. See the comment in
+	// rowexec.invertedJoiner.generateSpan for why this is safe.
+	encDatum := rowenc.EncDatumFromEncoded(descpb.DatumEncoding_ASCENDING_KEY, enc)
+	ij.invertedColRow = append(ij.invertedColRow[:0], encDatum)
+	span, _, err := ij.spanBuilder.SpanFromEncDatums(ij.invertedColRow, 1 /* prefixLen */)
+	return span, err
+}
+
+func (ij *ColInvertedJoiner) generateSpans(
+	invertedSpans []invertedeval.InvertedSpan,
+) ([]roachpb.Span, error) {
+	spans := make([]roachpb.Span, len(invertedSpans))
+	for i, span := range invertedSpans {
+		startSpan, err := ij.generateSpan(span.Start)
+		if err != nil {
+			return nil, err
+		}
+
+		endSpan, err := ij.generateSpan(span.End)
+		if err != nil {
+			return nil, err
+		}
+		startSpan.EndKey = endSpan.Key
+		spans[i] = startSpan
+	}
+	return spans, nil
+}
+
+// performScan reads the entire set of rows that are part of the scan, adds
+// them to the row container and evaluates the inverted expressions.
+func (ij *ColInvertedJoiner) performScan(ctx context.Context) invertedJoinerState {
+	log.VEventf(ctx, 1, "joining rows")
+	for {
+		// Fetch the next row and copy it into the row container.
+		scannedRow, _, _, err := ij.fetcher.NextRow(ctx)
+		if err != nil {
+			colexecerror.InternalError(colexecerror.NewStorageError(scrub.UnwrapScrubError(err)))
+		}
+		if scannedRow == nil {
+			// Done with this input batch.
+			break
+		}
+		ij.rowsRead++
+		encInvertedVal := scannedRow[ij.colIdxMap[ij.invertedColID]].EncodedBytes()
+		shouldAdd, err := ij.batchedExprEval.PrepareAddIndexRow(encInvertedVal)
+		if err != nil {
+			colexecerror.InternalError(err)
+		}
+		if shouldAdd {
+			ij.transformToKeyRow(scannedRow)
+			rowIdx, err := ij.keyRows.AddRow(ctx, ij.keyRow)
+			if err != nil {
+				colexecerror.InternalError(err)
+			}
+			if err = ij.batchedExprEval.AddIndexRow(rowIdx); err != nil {
+				colexecerror.InternalError(err)
+			}
+		}
+	}
+	ij.joinedRowIdx = ij.batchedExprEval.Evaluate()
+	ij.keyRows.SetupForRead(ctx, ij.joinedRowIdx)
+	log.VEventf(ctx, 1, "done evaluating expressions")
+	return ijEmittingRows
+}
+
+// emitRow buffers the next output row from ij.emitCursor at position
+// outputIdx of the output batch, if present, and returns whether it did.
+// Otherwise it prepares for another input batch.
+func (ij *ColInvertedJoiner) emitRow(
+	ctx context.Context, outputIdx int,
+) (_ invertedJoinerState, emitted bool) {
+	// Finished processing the batch.
+	if ij.emitCursor.inputRowIdx >= len(ij.joinedRowIdx) {
+		log.VEventf(ctx, 1, "done emitting rows")
+		// Ready for another input batch. Reset state.
+		ij.inputRows = ij.inputRows[:0]
+		ij.inputTuples.ResetInternalBatch()
+		ij.batchedExprEval.Reset()
+		ij.joinedRowIdx = nil
+		ij.emitCursor.outputRowIdx = 0
+		ij.emitCursor.inputRowIdx = 0
+		ij.emitCursor.seenMatch = false
+		if err := ij.keyRows.UnsafeReset(ctx); err != nil {
+			colexecerror.InternalError(err)
+		}
+		return ijReadingInput, false
+	}
+
+	// Reached the end of the matches for an input row. May need to emit for
+	// LeftOuterJoin and LeftAntiJoin.
+	if ij.emitCursor.outputRowIdx >= len(ij.joinedRowIdx[ij.emitCursor.inputRowIdx]) {
+		inputRowIdx := ij.emitCursor.inputRowIdx
+		seenMatch := ij.emitCursor.seenMatch
+		ij.emitCursor.inputRowIdx++
+		ij.emitCursor.outputRowIdx = 0
+		ij.emitCursor.seenMatch = false
+
+		if !seenMatch {
+			switch ij.joinType {
+			case descpb.LeftOuterJoin:
+				ij.bufferOutputRow(outputIdx, inputRowIdx, ij.nullTableRow)
+				return ijEmittingRows, true
+			case descpb.LeftAntiJoin:
+				ij.bufferOutputRow(outputIdx, inputRowIdx, nil /* tableRow */)
+				return ijEmittingRows, true
+			}
+		}
+		return ijEmittingRows, false
+	}
+
+	inputRowIdx := ij.emitCursor.inputRowIdx
+	joinedRowIdx := ij.joinedRowIdx[inputRowIdx][ij.emitCursor.outputRowIdx]
+	indexedRow, err := ij.keyRows.GetRow(ctx, joinedRowIdx, false /* skip */)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	ij.emitCursor.outputRowIdx++
+	ij.transformToTableRow(indexedRow)
+	if !ij.evalOnExpr(ij.inputRows[inputRowIdx], ij.tableRow) {
+		return ijEmittingRows, false
+	}
+	ij.emitCursor.seenMatch = true
+	switch ij.joinType {
+	case descpb.InnerJoin, descpb.LeftOuterJoin:
+		ij.bufferOutputRow(outputIdx, inputRowIdx, ij.tableRow)
+		return ijEmittingRows, true
+	case descpb.LeftSemiJoin:
+		// Skip the rest of the joined rows.
+		ij.skipRemaining(ctx)
+		ij.bufferOutputRow(outputIdx, inputRowIdx, nil /* tableRow */)
+		return ijEmittingRows, true
+	case descpb.LeftAntiJoin:
+		// Skip the rest of the joined rows.
+		ij.skipRemaining(ctx)
+	}
+	return ijEmittingRows, false
+}
+
+// skipRemaining skips the remaining joined rows for the current input row.
+func (ij *ColInvertedJoiner) skipRemaining(ctx context.Context) {
+	joinedRowIdx := ij.joinedRowIdx[ij.emitCursor.inputRowIdx]
+	for ; ij.emitCursor.outputRowIdx < len(joinedRowIdx); ij.emitCursor.outputRowIdx++ {
+		if _, err := ij.keyRows.GetRow(ctx, joinedRowIdx[ij.emitCursor.outputRowIdx], true /* skip */); err != nil {
+			colexecerror.InternalError(err)
+		}
+	}
+}
+
+// evalOnExpr evaluates the ON condition on the row with columns from both
+// sides.
+func (ij *ColInvertedJoiner) evalOnExpr(lrow, rrow rowenc.EncDatumRow) bool {
+	if ij.onExprHelper.Expr == nil {
+		return true
+	}
+	ij.combinedRow = append(ij.combinedRow[:0], lrow...)
+	ij.combinedRow = append(ij.combinedRow, rrow...)
+	res, err := ij.onExprHelper.EvalFilter(ij.combinedRow)
+	if err != nil {
+		colexecerror.ExpectedError(err)
+	}
+	return res
+}
+
+func (ij *ColInvertedJoiner) transformToKeyRow(row rowenc.EncDatumRow) {
+	for i, rowIdx := range ij.keyRowToTableRowMap {
+		ij.keyRow[i] = row[rowIdx]
+	}
+}
+
+func (ij *ColInvertedJoiner) transformToTableRow(keyRow rowenc.EncDatumRow) {
+	for r, k := range ij.tableRowToKeyRowMap {
+		ij.tableRow[r] = keyRow[k]
+	}
+}
+
+// DrainMeta is part of the MetadataSource interface.
+func (ij *ColInvertedJoiner) DrainMeta(ctx context.Context) []execinfrapb.ProducerMetadata {
+	if !ij.init {
+		// In some pathological queries Init() and Next() may never get called,
+		// so there is no metadata to return.
+		return nil
+	}
+	var trailingMeta []execinfrapb.ProducerMetadata
+	if tfs := execinfra.GetLeafTxnFinalState(ctx, ij.flowCtx.Txn); tfs != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{LeafTxnFinalState: tfs})
+	}
+	meta := execinfrapb.GetProducerMeta()
+	meta.Metrics = execinfrapb.GetMetricsMeta()
+	meta.Metrics.BytesRead = ij.GetBytesRead()
+	meta.Metrics.RowsRead = ij.GetRowsRead()
+	trailingMeta = append(trailingMeta, *meta)
+	return trailingMeta
+}
+
+// GetBytesRead is part of the execinfra.IOReader interface.
+func (ij *ColInvertedJoiner) GetBytesRead() int64 {
+	return ij.fetcher.GetBytesRead()
+}
+
+// GetRowsRead is part of the execinfra.IOReader interface.
+func (ij *ColInvertedJoiner) GetRowsRead() int64 {
+	return ij.rowsRead
+}
+
+// Close is part of the colexec.Closer interface.
+func (ij *ColInvertedJoiner) Close(ctx context.Context) error {
+	ij.fetcher.Close(ctx)
+	ij.keyRows.Close(ctx)
+	return nil
+}
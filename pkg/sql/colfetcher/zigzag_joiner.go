@@ -0,0 +1,616 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/colconv"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecbase/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/span"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+	"github.com/marusama/semaphore"
+)
+
+// zigzagJoinerBatchSize is the limit hint used whenever a side of the zigzag
+// join (re)starts its scan. See the comment on the same constant in the
+// rowexec package for more details.
+const zigzagJoinerBatchSize = 5
+
+// ColZigzagJoiner is the exec.Operator implementation of ZigzagJoiner.
+//
+// Each side of the join is a cFetcher scan over the span of its index that
+// has the fixed values as the prefix. Within that span the rows are ordered on
+// the equality columns, so the sides are joined by the merge joiner. Like the
+// row-by-row zigzag joiner, the sides skip ahead in the index: before fetching
+// the next batch, a side seeks to the equality values of the first row of the
+// current batch on the other side if it hasn't reached them yet, since no rows
+// in between can have a match. Unlike the row-by-row zigzag joiner, this
+// happens once per batch rather than once per row. Please refer to the comment
+// on rowexec.zigzagJoiner for a detailed description of the algorithm.
+//
+// Only inner zigzag joins of two tables are supported. The ON expression is
+// not evaluated by the ColZigzagJoiner itself and should be planned as a
+// filter on top of it.
+type ColZigzagJoiner struct {
+	// Operator is the merge joiner that joins the sides.
+	colexecbase.Operator
+
+	flowCtx *execinfra.FlowCtx
+	sides   [2]*zigzagJoinerSide
+
+	// init is true after Init() has been called.
+	init bool
+
+	// ResultTypes is the slice of resulting column types from this operator,
+	// the columns of the left table followed by the columns of the right
+	// table.
+	ResultTypes []*types.T
+}
+
+var _ colexecbase.Operator = &ColZigzagJoiner{}
+var _ execinfra.IOReader = &ColZigzagJoiner{}
+var _ execinfrapb.MetadataSource = &ColZigzagJoiner{}
+var _ colexec.Closer = &ColZigzagJoiner{}
+
+// zigzagJoinerSide is a cFetcher scan over one side of the zigzag join that
+// seeks past the rows that can't have a match on the other side.
+type zigzagJoinerSide struct {
+	colexecbase.ZeroInputNode
+
+	flowCtx *execinfra.FlowCtx
+	evalCtx *tree.EvalContext
+	fetcher cFetcher
+	// other is the other side of the join.
+	other *zigzagJoinerSide
+
+	table      *tabledesc.Immutable
+	index      *descpb.IndexDescriptor
+	indexTypes []*types.T
+	indexDirs  []descpb.IndexDescriptor_Direction
+
+	// eqColumns is the ordinal positions of the equality columns.
+	eqColumns []uint32
+	// eqOrdering is the ordering of the equality columns.
+	eqOrdering colinfo.ColumnOrdering
+
+	// fixedValues is the prefix of the index key that has fixed values.
+	fixedValues rowenc.EncDatumRow
+
+	// prefix is the prefix of the key which includes the table and index IDs.
+	prefix []byte
+	// span is the span of the index that has the fixed values as the prefix.
+	span        roachpb.Span
+	spanBuilder *span.Builder
+	// seekDatums is the scratch space for the datums used to construct the key
+	// to seek to.
+	seekDatums rowenc.EncDatumRow
+
+	// firstEqDatums and lastEqDatums contain the values of the equality
+	// columns of the first and the last rows of the current batch, and
+	// prevLastEqDatums of the last row of the previous batch.
+	firstEqDatums    tree.Datums
+	lastEqDatums     tree.Datums
+	prevLastEqDatums tree.Datums
+	rowIdxSel        []int
+	da               rowenc.DatumAlloc
+
+	// started is true once the initial scan has been started.
+	started bool
+	// done is true once the scan has been exhausted.
+	done bool
+	// bytesRead contains the number of bytes read by the KV fetchers of the
+	// previous scans.
+	bytesRead int64
+	// rowsRead contains the number of rows fetched by this side.
+	rowsRead int64
+}
+
+var _ colexecbase.Operator = &zigzagJoinerSide{}
+
+// NewColZigzagJoiner creates a new ColZigzagJoiner operator.
+// - allocator is used by the cFetchers of the sides of the join.
+// - unlimitedAllocator, memoryLimit, diskQueueCfg, fdSemaphore and diskAcc
+// are used by the merge joiner. See NewMergeJoinOp for more details.
+func NewColZigzagJoiner(
+	allocator *colmem.Allocator,
+	unlimitedAllocator *colmem.Allocator,
+	flowCtx *execinfra.FlowCtx,
+	spec *execinfrapb.ZigzagJoinerSpec,
+	post *execinfrapb.PostProcessSpec,
+	memoryLimit int64,
+	diskQueueCfg colcontainer.DiskQueueCfg,
+	fdSemaphore semaphore.Semaphore,
+	diskAcc *mon.BoundAccount,
+) (*ColZigzagJoiner, error) {
+	if len(spec.Tables) != 2 {
+		return nil, errors.AssertionFailedf("zigzag join of %d tables is not supported", len(spec.Tables))
+	}
+	if spec.Type != descpb.InnerJoin {
+		return nil, errors.AssertionFailedf("zigzag join of type %s is not supported", spec.Type)
+	}
+	tables := make([]tabledesc.Immutable, len(spec.Tables))
+	sideTypes := make([][]*types.T, len(spec.Tables))
+	var typs []*types.T
+	for i := range spec.Tables {
+		tables[i] = tabledesc.MakeImmutable(spec.Tables[i])
+		sideTypes[i] = tables[i].ColumnTypes()
+		typs = append(typs, sideTypes[i]...)
+	}
+
+	semaCtx := tree.MakeSemaContext()
+	evalCtx := flowCtx.NewEvalCtx()
+	// Before we can safely use types from the table descriptors, we need to
+	// make sure they are hydrated.
+	resolver := flowCtx.TypeResolverFactory.NewTypeResolver(evalCtx.Txn)
+	semaCtx.TypeResolver = resolver
+	if err := resolver.HydrateTypeSlice(evalCtx.Context, typs); err != nil {
+		return nil, err
+	}
+	neededColumns, err := neededColumnsForJoin(post, spec.OnExpr, typs, &semaCtx, evalCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	z := &ColZigzagJoiner{
+		flowCtx:     flowCtx,
+		ResultTypes: typs,
+	}
+	colOffset := 0
+	for i := range z.sides {
+		side := &zigzagJoinerSide{
+			flowCtx: flowCtx,
+			evalCtx: evalCtx,
+		}
+		if i < len(spec.FixedValues) {
+			side.fixedValues, err = valuesSpecToEncDatum(spec.FixedValues[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := side.init(allocator, spec, i, colOffset, &tables[i], neededColumns); err != nil {
+			return nil, err
+		}
+		colOffset += len(side.table.Columns)
+		z.sides[i] = side
+	}
+	z.sides[0].other, z.sides[1].other = z.sides[1], z.sides[0]
+
+	orderings := make([][]execinfrapb.Ordering_Column, len(z.sides))
+	for i, side := range z.sides {
+		orderings[i] = make([]execinfrapb.Ordering_Column, len(side.eqColumns))
+		for j := range side.eqColumns {
+			orderings[i][j].ColIdx = side.eqColumns[j]
+			if side.eqOrdering[j].Direction == encoding.Descending {
+				orderings[i][j].Direction = execinfrapb.Ordering_Column_DESC
+			}
+		}
+	}
+	for j := range orderings[0] {
+		// The sides are merged according to the directions of the left side.
+		if orderings[0][j].Direction != orderings[1][j].Direction {
+			return nil, errors.Newf("zigzag join on equality columns with different directions is not supported")
+		}
+	}
+	z.Operator, err = colexec.NewMergeJoinOp(
+		unlimitedAllocator, memoryLimit, diskQueueCfg, fdSemaphore, spec.Type,
+		z.sides[0], z.sides[1], sideTypes[0], sideTypes[1], orderings[0], orderings[1],
+		diskAcc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// neededColumnsForJoin returns the set of columns of the joined row that are
+// used either by the post-processing spec or by the ON expression.
+func neededColumnsForJoin(
+	post *execinfrapb.PostProcessSpec,
+	onExpr execinfrapb.Expression,
+	typs []*types.T,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+) (util.FastIntSet, error) {
+	helper := execinfra.ProcOutputHelper{}
+	if err := helper.Init(post, typs, semaCtx, evalCtx, nil /* output */); err != nil {
+		return util.FastIntSet{}, err
+	}
+	neededColumns := helper.NeededColumns()
+	var onExprHelper execinfrapb.ExprHelper
+	if err := onExprHelper.Init(onExpr, typs, semaCtx, evalCtx); err != nil {
+		return util.FastIntSet{}, err
+	}
+	if onExprHelper.Expr != nil {
+		for i := range typs {
+			if onExprHelper.Vars.IndexedVarUsed(i) {
+				neededColumns.Add(i)
+			}
+		}
+	}
+	return neededColumns, nil
+}
+
+// valuesSpecToEncDatum converts a values spec containing one tuple into
+// EncDatums for each cell. Note that this function assumes that there is only
+// one tuple in the ValuesSpec (i.e. the way fixed values are encoded in the
+// ZigzagJoinerSpec).
+func valuesSpecToEncDatum(
+	valuesSpec *execinfrapb.ValuesCoreSpec,
+) (res []rowenc.EncDatum, err error) {
+	res = make([]rowenc.EncDatum, len(valuesSpec.Columns))
+	rem := valuesSpec.RawBytes[0]
+	for i, colInfo := range valuesSpec.Columns {
+		res[i], rem, err = rowenc.EncDatumFromBuffer(colInfo.Type, colInfo.Encoding, rem)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// init sets up the given side of the join. colOffset is the number of columns
+// in the tables of all previous sides of the join.
+func (s *zigzagJoinerSide) init(
+	allocator *colmem.Allocator,
+	spec *execinfrapb.ZigzagJoinerSpec,
+	side int,
+	colOffset int,
+	table *tabledesc.Immutable,
+	neededOutputColumns util.FastIntSet,
+) error {
+	s.table = table
+	s.eqColumns = spec.EqColumns[side].Columns
+	indexOrdinal := spec.IndexOrdinals[side]
+	if indexOrdinal == 0 {
+		s.index = &s.table.PrimaryIndex
+	} else {
+		s.index = &s.table.Indexes[indexOrdinal-1]
+	}
+
+	var columnIDs []descpb.ColumnID
+	columnIDs, s.indexDirs = s.index.FullColumnIDs()
+	s.indexTypes = make([]*types.T, len(columnIDs))
+	columnTypes := s.table.ColumnTypes()
+	colIdxMap := s.table.ColumnIdxMap()
+	for i, columnID := range columnIDs {
+		s.indexTypes[i] = columnTypes[colIdxMap[columnID]]
+	}
+	var err error
+	if s.eqOrdering, err = s.makeEqOrdering(); err != nil {
+		return err
+	}
+
+	// Add the outputted columns.
+	neededCols := util.MakeFastIntSet()
+	maxCol := colOffset + len(s.table.Columns)
+	for i, ok := neededOutputColumns.Next(colOffset); ok && i < maxCol; i, ok = neededOutputColumns.Next(i + 1) {
+		neededCols.Add(i - colOffset)
+	}
+	// Add the equality columns.
+	for _, col := range s.eqColumns {
+		neededCols.Add(int(col))
+	}
+	if s.index.Type == descpb.IndexDescriptor_INVERTED &&
+		neededCols.Contains(colIdxMap[s.index.ColumnIDs[0]]) {
+		// The cFetcher can't decode the inverted column.
+		return errors.AssertionFailedf("the inverted column of the zigzag join is unexpectedly needed")
+	}
+
+	// NB: zigzag joins are disabled when a row-level locking clause is
+	// supplied, so there is no locking strength on *ZigzagJoinerSpec.
+	if _, _, err := initCRowFetcher(
+		s.flowCtx.Codec(), allocator, &s.fetcher, s.table, int(indexOrdinal), colIdxMap,
+		false /* reverseScan */, neededCols, execinfra.ScanVisibilityPublic,
+		descpb.ScanLockingStrength_FOR_NONE, descpb.ScanLockingWaitPolicy_BLOCK,
+		nil, /* systemColumnDescs */
+	); err != nil {
+		return err
+	}
+
+	s.spanBuilder = span.MakeBuilder(s.flowCtx.Codec(), s.table, s.index)
+	s.prefix = rowenc.MakeIndexKeyPrefix(s.flowCtx.Codec(), s.table, s.index.ID)
+	s.span, err = s.produceSpan(nil /* eqDatums */)
+	if err != nil {
+		return err
+	}
+	s.firstEqDatums = make(tree.Datums, 0, len(s.eqColumns))
+	s.lastEqDatums = make(tree.Datums, 0, len(s.eqColumns))
+	s.prevLastEqDatums = make(tree.Datums, 0, len(s.eqColumns))
+	s.rowIdxSel = make([]int, 1)
+	return nil
+}
+
+// makeEqOrdering returns the ordering of the equality columns.
+func (s *zigzagJoinerSide) makeEqOrdering() (colinfo.ColumnOrdering, error) {
+	ordering := make(colinfo.ColumnOrdering, len(s.eqColumns))
+	for i := range s.eqColumns {
+		colID := s.table.Columns[s.eqColumns[i]].ID
+		// Search the index columns, then the primary keys to find an ordering
+		// for the current column, 'colID'.
+		var direction encoding.Direction
+		var err error
+		if idx := findColumnID(s.index.ColumnIDs, colID); idx != -1 {
+			direction, err = s.index.ColumnDirections[idx].ToEncodingDirection()
+			if err != nil {
+				return nil, err
+			}
+		} else if idx := findColumnID(s.table.PrimaryIndex.ColumnIDs, colID); idx != -1 {
+			direction, err = s.table.PrimaryIndex.ColumnDirections[idx].ToEncodingDirection()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, errors.New("ordering of equality column not found in index or primary key")
+		}
+		ordering[i] = colinfo.ColumnOrderInfo{ColIdx: i, Direction: direction}
+	}
+	return ordering, nil
+}
+
+func findColumnID(s []descpb.ColumnID, t descpb.ColumnID) int {
+	for i := range s {
+		if s[i] == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// produceInvertedIndexKey generates a key for an inverted index from the
+// passed datums.
+func (s *zigzagJoinerSide) produceInvertedIndexKey(datums rowenc.EncDatumRow) (roachpb.Span, error) {
+	// For inverted indexes, the JSON field (first column in the index) is
+	// encoded a little differently. We need to explicitly call
+	// EncodeInvertedIndexKeys to generate the prefix. The rest of the index
+	// key containing the remaining neededDatums can be generated and appended
+	// using EncodeColumns.
+	colMap := make(map[descpb.ColumnID]int)
+	decodedDatums := make([]tree.Datum, len(datums))
+
+	// Ensure all EncDatums have been decoded.
+	for i, encDatum := range datums {
+		err := encDatum.EnsureDecoded(s.indexTypes[i], &s.da)
+		if err != nil {
+			return roachpb.Span{}, err
+		}
+
+		decodedDatums[i] = encDatum.Datum
+		if i < len(s.index.ColumnIDs) {
+			colMap[s.index.ColumnIDs[i]] = i
+		} else {
+			// This column's value will be encoded in the second part (i.e.
+			// EncodeColumns).
+			colMap[s.index.ExtraColumnIDs[i-len(s.index.ColumnIDs)]] = i
+		}
+	}
+
+	keys, err := rowenc.EncodeInvertedIndexKeys(
+		s.index,
+		colMap,
+		decodedDatums,
+		s.prefix,
+	)
+	if err != nil {
+		return roachpb.Span{}, err
+	}
+	if len(keys) != 1 {
+		return roachpb.Span{}, errors.Errorf("%d fixed values passed in for inverted index", len(keys))
+	}
+
+	// Append remaining (non-JSON) datums to the key.
+	keyBytes, _, err := rowenc.EncodeColumns(
+		s.index.ExtraColumnIDs[:len(datums)-1],
+		s.indexDirs[1:],
+		colMap,
+		decodedDatums,
+		keys[0],
+	)
+	key := roachpb.Key(keyBytes)
+	return roachpb.Span{Key: key, EndKey: key.PrefixEnd()}, err
+}
+
+// produceSpan generates the span of the index which has the fixed values
+// followed by the given values of the equality columns as the prefix.
+func (s *zigzagJoinerSide) produceSpan(eqDatums tree.Datums) (roachpb.Span, error) {
+	s.seekDatums = append(s.seekDatums[:0], s.fixedValues...)
+	for i, d := range eqDatums {
+		s.seekDatums = append(s.seekDatums, rowenc.DatumToEncDatum(s.indexTypes[len(s.fixedValues)+i], d))
+	}
+	if s.index.Type == descpb.IndexDescriptor_INVERTED {
+		return s.produceInvertedIndexKey(s.seekDatums)
+	}
+	sp, _, err := s.spanBuilder.SpanFromEncDatums(s.seekDatums, len(s.seekDatums))
+	return sp, err
+}
+
+// startScan starts the scan of the span of this side from the given key.
+func (s *zigzagJoinerSide) startScan(ctx context.Context, key roachpb.Key) {
+	if s.fetcher.fetcher != nil {
+		s.bytesRead += s.fetcher.fetcher.GetBytesRead()
+	}
+	if err := s.fetcher.StartScan(
+		ctx,
+		s.flowCtx.Txn,
+		roachpb.Spans{roachpb.Span{Key: key, EndKey: s.span.EndKey}},
+		true, /* limitBatches */
+		zigzagJoinerBatchSize,
+		s.flowCtx.TraceKV,
+	); err != nil {
+		colexecerror.InternalError(err)
+	}
+}
+
+// maybeSeek restarts the scan at the key that has the values of the equality
+// columns of the first row from the current batch of the other side if this
+// side hasn't reached it yet. The rows of this side in between can't have a
+// match since all rows of the other side that haven't been joined yet are in
+// its current batch, except for possibly the last group of its previous batch
+// which has the same values as the last row of that batch. In the latter case
+// this side must not skip ahead if its last row has those values too.
+func (s *zigzagJoinerSide) maybeSeek(ctx context.Context) {
+	target := s.other.firstEqDatums
+	if len(target) == 0 {
+		return
+	}
+	if len(s.lastEqDatums) != 0 {
+		if len(s.other.prevLastEqDatums) != 0 &&
+			s.compareEqDatums(s.lastEqDatums, s.other.prevLastEqDatums) <= 0 {
+			return
+		}
+		if s.compareEqDatums(s.lastEqDatums, target) >= 0 {
+			return
+		}
+	}
+	for _, d := range target {
+		if d == tree.DNull {
+			return
+		}
+	}
+	seekSpan, err := s.produceSpan(target)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	s.startScan(ctx, seekSpan.Key)
+}
+
+// compareEqDatums compares the given values of the equality columns according
+// to the ordering of this side.
+func (s *zigzagJoinerSide) compareEqDatums(lhs, rhs tree.Datums) int {
+	for i, o := range s.eqOrdering {
+		cmp := lhs[i].Compare(s.evalCtx, rhs[i])
+		if cmp != 0 {
+			if o.Direction == encoding.Descending {
+				cmp = -cmp
+			}
+			return cmp
+		}
+	}
+	return 0
+}
+
+// Init is part of the Operator interface.
+func (s *zigzagJoinerSide) Init() {}
+
+// Next is part of the Operator interface.
+func (s *zigzagJoinerSide) Next(ctx context.Context) coldata.Batch {
+	if s.done {
+		return coldata.ZeroBatch
+	}
+	if !s.started {
+		s.started = true
+		s.startScan(ctx, s.span.Key)
+	}
+	s.maybeSeek(ctx)
+	batch, err := s.fetcher.NextBatch(ctx)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	n := batch.Length()
+	if n == 0 {
+		s.done = true
+		return batch
+	}
+	s.rowsRead += int64(n)
+	s.prevLastEqDatums, s.lastEqDatums = s.lastEqDatums, s.prevLastEqDatums
+	s.firstEqDatums = s.getEqDatums(batch, 0 /* rowIdx */, s.firstEqDatums)
+	s.lastEqDatums = s.getEqDatums(batch, n-1, s.lastEqDatums)
+	return batch
+}
+
+// getEqDatums converts the values of the equality columns of the given row of
+// the batch into datums which are stored in res.
+func (s *zigzagJoinerSide) getEqDatums(
+	batch coldata.Batch, rowIdx int, res tree.Datums,
+) tree.Datums {
+	res = res[:len(s.eqColumns)]
+	s.rowIdxSel[0] = rowIdx
+	for i, colIdx := range s.eqColumns {
+		colconv.ColVecToDatumAndDeselect(
+			res[i:i+1], batch.ColVec(int(colIdx)), 1 /* length */, s.rowIdxSel, &s.da,
+		)
+	}
+	return res
+}
+
+// getBytesRead returns the number of bytes read by this side.
+func (s *zigzagJoinerSide) getBytesRead() int64 {
+	bytesRead := s.bytesRead
+	if s.fetcher.fetcher != nil {
+		bytesRead += s.fetcher.fetcher.GetBytesRead()
+	}
+	return bytesRead
+}
+
+// Init is part of the Operator interface.
+func (z *ColZigzagJoiner) Init() {
+	z.init = true
+	z.Operator.Init()
+}
+
+// DrainMeta is part of the MetadataSource interface.
+func (z *ColZigzagJoiner) DrainMeta(ctx context.Context) []execinfrapb.ProducerMetadata {
+	if !z.init {
+		// In some pathological queries Init() and Next() may never get called,
+		// so there is no metadata to return.
+		return nil
+	}
+	var trailingMeta []execinfrapb.ProducerMetadata
+	if tfs := execinfra.GetLeafTxnFinalState(ctx, z.flowCtx.Txn); tfs != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{LeafTxnFinalState: tfs})
+	}
+	meta := execinfrapb.GetProducerMeta()
+	meta.Metrics = execinfrapb.GetMetricsMeta()
+	meta.Metrics.BytesRead = z.GetBytesRead()
+	meta.Metrics.RowsRead = z.GetRowsRead()
+	trailingMeta = append(trailingMeta, *meta)
+	return trailingMeta
+}
+
+// GetBytesRead is part of the execinfra.IOReader interface.
+func (z *ColZigzagJoiner) GetBytesRead() int64 {
+	var bytesRead int64
+	for _, side := range z.sides {
+		bytesRead += side.getBytesRead()
+	}
+	return bytesRead
+}
+
+// GetRowsRead is part of the execinfra.IOReader interface.
+func (z *ColZigzagJoiner) GetRowsRead() int64 {
+	var rowsRead int64
+	for _, side := range z.sides {
+		rowsRead += side.rowsRead
+	}
+	return rowsRead
+}
+
+// Close is part of the colexec.Closer interface.
+func (z *ColZigzagJoiner) Close(ctx context.Context) error {
+	return z.Operator.(colexec.Closer).Close(ctx)
+}
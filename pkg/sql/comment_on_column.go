@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type commentOnColumnNode struct {
@@ -80,25 +81,19 @@ func (n *commentOnColumnNode) startExec(params runParams) error {
 		}
 	}
 
-	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
-		params.ctx,
-		params.p.txn,
-		EventLogCommentOnColumn,
-		int32(n.tableDesc.ID),
-		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
-		struct {
-			TableName  string
-			ColumnName string
-			Statement  string
-			User       string
-			Comment    *string
-		}{
-			n.tableDesc.Name,
-			string(n.n.ColumnItem.ColumnName),
-			n.n.String(),
-			params.SessionData().User,
-			n.n.Comment},
-	)
+	comment := ""
+	if n.n.Comment != nil {
+		comment = *n.n.Comment
+	}
+	return params.p.logEvent(params.ctx,
+		n.tableDesc.ID,
+		&eventpb.CommentOnColumn{
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{Statement: n.n.String()},
+			TableName:             n.tableDesc.Name,
+			ColumnName:            string(n.n.ColumnItem.ColumnName),
+			Comment:               comment,
+			NullComment:           n.n.Comment == nil,
+		})
 }
 
 func (n *commentOnColumnNode) Next(runParams) (bool, error) { return false, nil }
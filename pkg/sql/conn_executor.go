@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/database"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descs"
+	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -250,6 +251,10 @@ type Server struct {
 	// into reported stats when sqlStats is cleared.
 	reportedStats sqlStats
 
+	// indexUsageStats tracks the read activity of the indexes used by the
+	// statements executed on this node.
+	indexUsageStats *idxusage.LocalIndexUsageStats
+
 	reCache *tree.RegexpCache
 
 	// pool is the parent monitor for all session monitors except "internal" ones.
@@ -297,6 +302,8 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 		sqlStats:      sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
 		reportedStats: sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
 		reCache:       tree.NewRegexpCache(512),
+
+		indexUsageStats: idxusage.NewLocalIndexUsageStats(cfg.Settings),
 	}
 }
 
@@ -357,10 +364,18 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 	s.PeriodicallyClearSQLStats(ctx, stopper, MaxSQLStatReset, &s.reportedStats, s.ResetReportedStats)
 	// Start a second loop to clear SQL stats at the requested interval.
 	s.PeriodicallyClearSQLStats(ctx, stopper, SQLStatReset, &s.sqlStats, s.ResetSQLStats)
+	// Start a loop to flush the SQL stats to the system tables.
+	s.startSQLStatsFlushLoop(ctx, stopper)
 }
 
 // ResetSQLStats resets the executor's collected sql statistics.
 func (s *Server) ResetSQLStats(ctx context.Context) {
+	if SQLStatsFlushEnabled.Get(&s.cfg.Settings.SV) {
+		// Flush the SQL stats rather than dropping what was collected since
+		// the last flush. Flushing also dumps them into the reported stats.
+		s.FlushSQLStats(ctx)
+		return
+	}
 	// Dump the SQL stats into the reported stats before clearing the SQL stats.
 	s.sqlStats.resetAndMaybeDumpStats(ctx, &s.reportedStats)
 }
@@ -411,6 +426,12 @@ func (s *Server) GetStmtStatsLastReset() time.Time {
 	return s.sqlStats.getLastReset()
 }
 
+// GetLocalIndexStatistics returns the index usage statistics collected on this
+// node.
+func (s *Server) GetLocalIndexStatistics() *idxusage.LocalIndexUsageStats {
+	return s.indexUsageStats
+}
+
 // GetExecutorConfig returns this server's executor config.
 func (s *Server) GetExecutorConfig() *ExecutorConfig {
 	return s.cfg
@@ -2077,24 +2098,27 @@ func (ex *connExecutor) initEvalCtx(ctx context.Context, evalCtx *extendedEvalCo
 
 	*evalCtx = extendedEvalContext{
 		EvalContext: tree.EvalContext{
-			Planner:            p,
-			PrivilegedAccessor: p,
-			SessionAccessor:    p,
-			ClientNoticeSender: p,
-			Sequence:           p,
-			Tenant:             p,
-			SessionData:        ex.sessionData,
-			Settings:           ex.server.cfg.Settings,
-			TestingKnobs:       ex.server.cfg.EvalContextTestingKnobs,
-			ClusterID:          ex.server.cfg.ClusterID(),
-			ClusterName:        ex.server.cfg.RPCContext.ClusterName(),
-			NodeID:             ex.server.cfg.NodeID,
-			Codec:              ex.server.cfg.Codec,
-			Locality:           ex.server.cfg.Locality,
-			ReCache:            ex.server.reCache,
-			InternalExecutor:   &ie,
-			DB:                 ex.server.cfg.DB,
-			SQLLivenessReader:  ex.server.cfg.SQLLivenessReader,
+			Planner:                        p,
+			PrivilegedAccessor:             p,
+			SessionAccessor:                p,
+			ClientNoticeSender:             p,
+			Sequence:                       p,
+			Tenant:                         p,
+			StmtDiagnosticsRequestInserter: ex.server.cfg.StmtDiagnosticsRecorder.InsertRequest,
+			RoleAuditPolicies:              ex.server.cfg.RoleAuditRegistry,
+			PlanBaselines:                  ex.server.cfg.PlanBaselineRegistry,
+			SessionData:                    ex.sessionData,
+			Settings:                       ex.server.cfg.Settings,
+			TestingKnobs:                   ex.server.cfg.EvalContextTestingKnobs,
+			ClusterID:                      ex.server.cfg.ClusterID(),
+			ClusterName:                    ex.server.cfg.RPCContext.ClusterName(),
+			NodeID:                         ex.server.cfg.NodeID,
+			Codec:                          ex.server.cfg.Codec,
+			Locality:                       ex.server.cfg.Locality,
+			ReCache:                        ex.server.reCache,
+			InternalExecutor:               &ie,
+			DB:                             ex.server.cfg.DB,
+			SQLLivenessReader:              ex.server.cfg.SQLLivenessReader,
 		},
 		SessionMutator:       ex.dataMutator,
 		VirtualSchemas:       ex.server.cfg.VirtualSchemas,
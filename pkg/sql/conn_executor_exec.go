@@ -304,6 +304,7 @@ func (ex *connExecutor) execStmtInOpenState(
 
 	var shouldCollectDiagnostics bool
 	var finishCollectionDiagnostics StmtDiagnosticsTraceFinishFunc
+	var diagnosticsMinExecutionLatency time.Duration
 
 	if explainBundle, ok := stmt.AST.(*tree.ExplainAnalyzeDebug); ok {
 		telemetry.Inc(sqltelemetry.ExplainAnalyzeDebugUseCounter)
@@ -325,8 +326,9 @@ func (ex *connExecutor) execStmtInOpenState(
 		// bundle.
 		p.discardRows = true
 	} else {
-		shouldCollectDiagnostics, finishCollectionDiagnostics = ex.stmtDiagnosticsRecorder.ShouldCollectDiagnostics(ctx, stmt.AST)
-		if shouldCollectDiagnostics {
+		shouldCollectDiagnostics, finishCollectionDiagnostics, diagnosticsMinExecutionLatency =
+			ex.stmtDiagnosticsRecorder.ShouldCollectDiagnostics(ctx, stmt.AST)
+		if shouldCollectDiagnostics && diagnosticsMinExecutionLatency == 0 {
 			telemetry.Inc(sqltelemetry.StatementDiagnosticsCollectedCounter)
 		}
 	}
@@ -347,6 +349,14 @@ func (ex *connExecutor) execStmtInOpenState(
 			ie := p.extendedEvalCtx.InternalExecutor.(*InternalExecutor)
 			placeholders := p.extendedEvalCtx.Placeholders
 			if finishCollectionDiagnostics != nil {
+				if diagnosticsMinExecutionLatency != 0 {
+					// The request is conditional on the execution being slow; don't
+					// bother building the bundle for a fast one.
+					if timeutil.Since(ex.phaseTimes[sessionQueryReceived]) < diagnosticsMinExecutionLatency {
+						return
+					}
+					telemetry.Inc(sqltelemetry.StatementDiagnosticsCollectedCounter)
+				}
 				bundle, collectionErr := buildStatementBundle(
 					origCtx, ex.server.cfg.DB, ie, &p.curPlan, trace, placeholders,
 				)
@@ -840,6 +850,12 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	// defer is a catch-all in case some other return path is taken.
 	defer planner.curPlan.close(ctx)
 
+	// The role-based audit policies are checked even if planning failed:
+	// failed attempts must be audited too.
+	if ex.executorType == executorTypeExec {
+		planner.maybeAuditRole(ctx)
+	}
+
 	if planner.autoCommit {
 		planner.curPlan.flags.Set(planFlagImplicitTxn)
 	}
@@ -933,6 +949,11 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	ex.sessionTracing.TraceExecEnd(ctx, res.Err(), res.RowsAffected())
 	ex.statsCollector.phaseTimes[plannerEndExecStmt] = timeutil.Now()
 
+	// Only the indexes read by successful statements are recorded.
+	if err == nil && res.Err() == nil {
+		ex.server.indexUsageStats.RecordReads(planner.curPlan.indexesUsed)
+	}
+
 	// Record the statement summary. This also closes the plan if the
 	// plan has not been closed earlier.
 	ex.recordStatementSummary(
@@ -283,6 +283,7 @@ func startConnExecutor(
 			stopper,
 			func(roachpb.NodeID) (bool, error) { return true, nil }, // everybody is live
 			nil, /* nodeDialer */
+			nil, /* sqlInstanceResolver */
 		),
 		QueryCache:              querycache.New(0),
 		TestingKnobs:            ExecutorTestingKnobs{},
@@ -0,0 +1,343 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package contention aggregates the contention events observed by the
+// concurrency manager on a single node.
+package contention
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// Registry is an object that keeps track of aggregated contention information.
+// It can be thought of as three maps:
+// 1. The top-level map is a mapping from a tableID/indexID which uniquely
+//    describes an index to that index's contention information.
+// 2. The contention information is itself a map from keys in that index
+//    (maintained in sorted order on serialization) to a list of transactions
+//    that caused contention events on the corresponding key.
+// 3. The transaction list is a map from pairs of blocking and waiting
+//    transaction IDs to the number of times the waiting transaction was
+//    observed waiting on the blocking transaction on the key.
+// All three maps have a limited size and evict their least recently used
+// entries once that size is exceeded.
+//
+// The Registry is safe for concurrent use.
+type Registry struct {
+	mu struct {
+		syncutil.Mutex
+		// indexMap maps indexMapKeys to *indexMapValues.
+		indexMap *cache.UnorderedCache
+	}
+}
+
+var (
+	// indexMapMaxSize specifies the maximum number of indexes a Registry should
+	// keep track of contention events for.
+	indexMapMaxSize = 50
+	// keyMapMaxSize specifies the maximum number of keys in a given index a
+	// Registry should keep track of contention events for.
+	keyMapMaxSize = 50
+	// txnsMapMaxSize specifies the maximum number of pairs of blocking and
+	// waiting transactions a Registry should keep track of for a given key.
+	txnsMapMaxSize = 10
+)
+
+// indexMapKey is used as the key in the Registry's indexMap.
+type indexMapKey struct {
+	tableID descpb.ID
+	indexID descpb.IndexID
+}
+
+// indexMapValue is the contention information of a single index.
+type indexMapValue struct {
+	// numContentionEvents is the number of contention events that have happened
+	// on the index.
+	numContentionEvents int64
+	// cumulativeContentionTime is the total duration that transactions touching
+	// this index have spent contended.
+	cumulativeContentionTime time.Duration
+	// keyMap maps contended keys (as strings) to *txnsMapValues.
+	keyMap *cache.UnorderedCache
+}
+
+// txnsMapKey is used as the key in a txnsMapValue.
+type txnsMapKey struct {
+	// txnID is the ID of the transaction that caused the contention event.
+	txnID uuid.UUID
+	// waitingTxnID is the ID of the transaction that waited on txnID.
+	waitingTxnID uuid.UUID
+}
+
+// txnsMapValue maps the txnsMapKeys of the contention events on a key to the
+// number of times each was encountered.
+type txnsMapValue struct {
+	txns *cache.UnorderedCache
+}
+
+func newLRUCache(maxSize int) *cache.UnorderedCache {
+	return cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(size int, _, _ interface{}) bool {
+			return size > maxSize
+		},
+	})
+}
+
+func newIndexMapValue(c roachpb.ContentionEvent) *indexMapValue {
+	v := &indexMapValue{keyMap: newLRUCache(keyMapMaxSize)}
+	v.addContentionEvent(c)
+	return v
+}
+
+func (v *indexMapValue) addContentionEvent(c roachpb.ContentionEvent) {
+	v.numContentionEvents++
+	v.cumulativeContentionTime += c.Duration
+	var txns txnsMapValue
+	if existing, ok := v.keyMap.Get(string(c.Key)); ok {
+		txns = existing.(txnsMapValue)
+	} else {
+		txns = txnsMapValue{txns: newLRUCache(txnsMapMaxSize)}
+		v.keyMap.Add(string(c.Key), txns)
+	}
+	txnKey := txnsMapKey{txnID: c.TxnMeta.ID, waitingTxnID: c.WaitingTxnID}
+	var count int64
+	if existing, ok := txns.txns.Get(txnKey); ok {
+		count = existing.(int64)
+	}
+	txns.txns.Add(txnKey, count+1)
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.mu.indexMap = newLRUCache(indexMapMaxSize)
+	return r
+}
+
+// AddContentionEvent adds a new ContentionEvent to the Registry. Events on
+// keys that do not belong to a system tenant SQL index are ignored.
+func (r *Registry) AddContentionEvent(c roachpb.ContentionEvent) {
+	_, tableID, indexID, err := keys.TODOSQLCodec.DecodeIndexPrefix(c.Key)
+	if err != nil {
+		// The key is not a SQL key; there is no index to attribute the
+		// contention event to.
+		return
+	}
+	key := indexMapKey{tableID: descpb.ID(tableID), indexID: descpb.IndexID(indexID)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.mu.indexMap.Get(key); ok {
+		v.(*indexMapValue).addContentionEvent(c)
+		return
+	}
+	r.mu.indexMap.Add(key, newIndexMapValue(c))
+}
+
+// Serialize returns the serialized representation of the registry. In this
+// representation the following orderings are maintained:
+// - on the highest level, all IndexContentionEvents objects are ordered
+//   according to their importance (achieved by an explicit sort).
+// - on the middle level, all SingleKeyContention objects are ordered by their
+//   keys.
+// - on the lowest level, all SingleTxnContention objects are ordered by the
+//   number of times the blocking transaction was observed to contend with the
+//   waiting transaction.
+func (r *Registry) Serialize() []serverpb.IndexContentionEvents {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resp := make([]serverpb.IndexContentionEvents, 0, r.mu.indexMap.Len())
+	r.mu.indexMap.Do(func(e *cache.Entry) {
+		key := e.Key.(indexMapKey)
+		v := e.Value.(*indexMapValue)
+		ice := serverpb.IndexContentionEvents{
+			TableID:                  key.tableID,
+			IndexID:                  key.indexID,
+			NumContentionEvents:      v.numContentionEvents,
+			CumulativeContentionTime: v.cumulativeContentionTime,
+			Events:                   make([]serverpb.SingleKeyContention, 0, v.keyMap.Len()),
+		}
+		v.keyMap.Do(func(e *cache.Entry) {
+			txns := e.Value.(txnsMapValue)
+			skc := serverpb.SingleKeyContention{
+				Key:  roachpb.Key(e.Key.(string)),
+				Txns: make([]serverpb.SingleTxnContention, 0, txns.txns.Len()),
+			}
+			txns.txns.Do(func(e *cache.Entry) {
+				txnKey := e.Key.(txnsMapKey)
+				skc.Txns = append(skc.Txns, serverpb.SingleTxnContention{
+					TxnID:        txnKey.txnID,
+					Count:        e.Value.(int64),
+					WaitingTxnID: txnKey.waitingTxnID,
+				})
+			})
+			sortSingleTxnContention(skc.Txns)
+			ice.Events = append(ice.Events, skc)
+		})
+		sortSingleKeyContention(ice.Events)
+		resp = append(resp, ice)
+	})
+	sortIndexContentionEvents(resp)
+	return resp
+}
+
+// sortIndexContentionEvents sorts all of the index contention events in-place
+// in decreasing order of importance, which is determined by the number of
+// contention events and the cumulative contention time. Ties are broken by
+// table and index ID so that the order is deterministic.
+func sortIndexContentionEvents(ice []serverpb.IndexContentionEvents) {
+	sort.Slice(ice, func(i, j int) bool {
+		if ice[i].NumContentionEvents != ice[j].NumContentionEvents {
+			return ice[i].NumContentionEvents > ice[j].NumContentionEvents
+		}
+		if ice[i].CumulativeContentionTime != ice[j].CumulativeContentionTime {
+			return ice[i].CumulativeContentionTime > ice[j].CumulativeContentionTime
+		}
+		if ice[i].TableID != ice[j].TableID {
+			return ice[i].TableID < ice[j].TableID
+		}
+		return ice[i].IndexID < ice[j].IndexID
+	})
+}
+
+// sortSingleKeyContention sorts the contended keys in-place in ascending order.
+func sortSingleKeyContention(skc []serverpb.SingleKeyContention) {
+	sort.Slice(skc, func(i, j int) bool {
+		return skc[i].Key.Compare(skc[j].Key) < 0
+	})
+}
+
+// sortSingleTxnContention sorts the transactions in-place in decreasing order
+// of the number of times each was encountered.
+func sortSingleTxnContention(txns []serverpb.SingleTxnContention) {
+	sort.Slice(txns, func(i, j int) bool {
+		if txns[i].Count != txns[j].Count {
+			return txns[i].Count > txns[j].Count
+		}
+		if c := bytes.Compare(txns[i].TxnID.GetBytes(), txns[j].TxnID.GetBytes()); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(txns[i].WaitingTxnID.GetBytes(), txns[j].WaitingTxnID.GetBytes()) < 0
+	})
+}
+
+// MergeSerializedRegistries merges the serialized representations of two
+// Registries into one. first is modified in-place, and the resulting merged
+// representation maintains the orderings described on Serialize. The size
+// limits of the Registry are applied to the result as well.
+func MergeSerializedRegistries(
+	first, second []serverpb.IndexContentionEvents,
+) []serverpb.IndexContentionEvents {
+	for _, s := range second {
+		found := false
+		for i := range first {
+			if first[i].TableID == s.TableID && first[i].IndexID == s.IndexID {
+				first[i] = mergeIndexContentionEvents(first[i], s)
+				found = true
+				break
+			}
+		}
+		if !found {
+			first = append(first, s)
+		}
+	}
+	sortIndexContentionEvents(first)
+	if len(first) > indexMapMaxSize {
+		first = first[:indexMapMaxSize]
+	}
+	return first
+}
+
+// mergeIndexContentionEvents merges two IndexContentionEvents objects that
+// describe the same index.
+func mergeIndexContentionEvents(
+	first, second serverpb.IndexContentionEvents,
+) serverpb.IndexContentionEvents {
+	first.NumContentionEvents += second.NumContentionEvents
+	first.CumulativeContentionTime += second.CumulativeContentionTime
+	for _, s := range second.Events {
+		found := false
+		for i := range first.Events {
+			if first.Events[i].Key.Equal(s.Key) {
+				first.Events[i].Txns = mergeSingleTxnContention(first.Events[i].Txns, s.Txns)
+				found = true
+				break
+			}
+		}
+		if !found {
+			first.Events = append(first.Events, s)
+		}
+	}
+	sortSingleKeyContention(first.Events)
+	if len(first.Events) > keyMapMaxSize {
+		first.Events = first.Events[:keyMapMaxSize]
+	}
+	return first
+}
+
+// mergeSingleTxnContention merges two lists of transactions that contended on
+// the same key.
+func mergeSingleTxnContention(
+	first, second []serverpb.SingleTxnContention,
+) []serverpb.SingleTxnContention {
+	for _, s := range second {
+		found := false
+		for i := range first {
+			if first[i].TxnID.Equal(s.TxnID) && first[i].WaitingTxnID.Equal(s.WaitingTxnID) {
+				first[i].Count += s.Count
+				found = true
+				break
+			}
+		}
+		if !found {
+			first = append(first, s)
+		}
+	}
+	sortSingleTxnContention(first)
+	if len(first) > txnsMapMaxSize {
+		first = first[:txnsMapMaxSize]
+	}
+	return first
+}
+
+// String returns a string representation of the Registry.
+func (r *Registry) String() string {
+	var b strings.Builder
+	for _, ice := range r.Serialize() {
+		fmt.Fprintf(&b, "tableID=%d indexID=%d\n", ice.TableID, ice.IndexID)
+		fmt.Fprintf(&b, "%snum contention events: %d\n", prefixString(1), ice.NumContentionEvents)
+		fmt.Fprintf(&b, "%scumulative contention time: %s\n", prefixString(1), ice.CumulativeContentionTime)
+		fmt.Fprintf(&b, "%skeys:\n", prefixString(1))
+		for _, skc := range ice.Events {
+			fmt.Fprintf(&b, "%s%s contending txns:\n", prefixString(2), skc.Key)
+			for _, stc := range skc.Txns {
+				fmt.Fprintf(&b, "%sid=%s waiting id=%s count=%d\n",
+					prefixString(3), stc.TxnID, stc.WaitingTxnID, stc.Count)
+			}
+		}
+	}
+	return b.String()
+}
+
+func prefixString(depth int) string {
+	return strings.Repeat("  ", depth)
+}
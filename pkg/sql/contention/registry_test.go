@@ -0,0 +1,128 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package contention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func makeEvent(
+	tableID descpb.ID,
+	indexID descpb.IndexID,
+	suffix string,
+	txnID, waitingTxnID uuid.UUID,
+	d time.Duration,
+) roachpb.ContentionEvent {
+	key := keys.TODOSQLCodec.IndexPrefix(uint32(tableID), uint32(indexID))
+	key = append(key, suffix...)
+	return roachpb.ContentionEvent{
+		Key:          key,
+		TxnMeta:      enginepb.TxnMeta{ID: txnID},
+		Duration:     d,
+		WaitingTxnID: waitingTxnID,
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	txn1, txn2, txn3 := uuid.MakeV4(), uuid.MakeV4(), uuid.MakeV4()
+	r := NewRegistry()
+	// Non-SQL keys are ignored.
+	r.AddContentionEvent(roachpb.ContentionEvent{
+		Key: keys.RangeDescriptorKey(roachpb.RKey("a")), Duration: time.Second,
+	})
+	require.Empty(t, r.Serialize())
+
+	r.AddContentionEvent(makeEvent(53, 1, "b", txn1, txn3, time.Second))
+	r.AddContentionEvent(makeEvent(53, 1, "a", txn1, txn3, time.Second))
+	r.AddContentionEvent(makeEvent(53, 1, "a", txn2, txn3, time.Second))
+	r.AddContentionEvent(makeEvent(53, 1, "a", txn2, txn3, time.Second))
+	r.AddContentionEvent(makeEvent(53, 1, "a", txn2, txn1, time.Second))
+	r.AddContentionEvent(makeEvent(54, 2, "a", txn1, txn3, time.Second))
+
+	events := r.Serialize()
+	require.Len(t, events, 2)
+	// The most contended index comes first.
+	require.Equal(t, descpb.ID(53), events[0].TableID)
+	require.Equal(t, descpb.IndexID(1), events[0].IndexID)
+	require.Equal(t, int64(5), events[0].NumContentionEvents)
+	require.Equal(t, 5*time.Second, events[0].CumulativeContentionTime)
+	// Keys are ordered.
+	require.Len(t, events[0].Events, 2)
+	require.True(t, events[0].Events[0].Key.Compare(events[0].Events[1].Key) < 0)
+	// Transactions are aggregated per pair of blocking and waiting transaction
+	// and ordered by count.
+	txns := events[0].Events[0].Txns
+	require.Len(t, txns, 3)
+	require.Equal(t, serverpb.SingleTxnContention{TxnID: txn2, Count: 2, WaitingTxnID: txn3}, txns[0])
+	require.ElementsMatch(t, []serverpb.SingleTxnContention{
+		{TxnID: txn1, Count: 1, WaitingTxnID: txn3},
+		{TxnID: txn2, Count: 1, WaitingTxnID: txn1},
+	}, txns[1:])
+	require.Equal(t, descpb.ID(54), events[1].TableID)
+	require.Equal(t, int64(1), events[1].NumContentionEvents)
+}
+
+func TestRegistryEviction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	r := NewRegistry()
+	for i := 0; i < indexMapMaxSize+10; i++ {
+		r.AddContentionEvent(makeEvent(descpb.ID(100+i), 1, "a", uuid.MakeV4(), uuid.MakeV4(), time.Second))
+	}
+	for i := 0; i < txnsMapMaxSize+5; i++ {
+		r.AddContentionEvent(makeEvent(53, 1, "a", uuid.MakeV4(), uuid.MakeV4(), time.Second))
+	}
+	events := r.Serialize()
+	require.Len(t, events, indexMapMaxSize)
+	require.Equal(t, descpb.ID(53), events[0].TableID)
+	require.Len(t, events[0].Events[0].Txns, txnsMapMaxSize)
+}
+
+func TestMergeSerializedRegistries(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	txn1, txn2, txn3 := uuid.MakeV4(), uuid.MakeV4(), uuid.MakeV4()
+	r1, r2 := NewRegistry(), NewRegistry()
+	r1.AddContentionEvent(makeEvent(53, 1, "a", txn1, txn3, time.Second))
+	r1.AddContentionEvent(makeEvent(54, 1, "a", txn1, txn3, time.Second))
+	r2.AddContentionEvent(makeEvent(53, 1, "a", txn1, txn3, 2*time.Second))
+	r2.AddContentionEvent(makeEvent(53, 1, "a", txn1, txn2, time.Second))
+	r2.AddContentionEvent(makeEvent(53, 1, "b", txn2, txn3, time.Second))
+
+	merged := MergeSerializedRegistries(r1.Serialize(), r2.Serialize())
+	require.Len(t, merged, 2)
+	require.Equal(t, descpb.ID(53), merged[0].TableID)
+	require.Equal(t, int64(4), merged[0].NumContentionEvents)
+	require.Equal(t, 5*time.Second, merged[0].CumulativeContentionTime)
+	require.Len(t, merged[0].Events, 2)
+	// Only the events with the same blocking and waiting transactions are
+	// merged.
+	require.Equal(t, []serverpb.SingleTxnContention{
+		{TxnID: txn1, Count: 2, WaitingTxnID: txn3},
+		{TxnID: txn1, Count: 1, WaitingTxnID: txn2},
+	}, merged[0].Events[0].Txns)
+	require.Equal(t, []serverpb.SingleTxnContention{
+		{TxnID: txn2, Count: 1, WaitingTxnID: txn3},
+	}, merged[0].Events[1].Txns)
+	require.Equal(t, descpb.ID(54), merged[1].TableID)
+}
@@ -44,8 +44,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/typedesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/protoreflect"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
@@ -53,6 +55,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -78,47 +81,53 @@ const CrdbInternalName = sessiondata.CRDBInternalSchemaName
 var crdbInternal = virtualSchema{
 	name: CrdbInternalName,
 	tableDefs: map[descpb.ID]virtualSchemaDef{
-		catconstants.CrdbInternalBackwardDependenciesTableID: crdbInternalBackwardDependenciesTable,
-		catconstants.CrdbInternalBuildInfoTableID:            crdbInternalBuildInfoTable,
-		catconstants.CrdbInternalBuiltinFunctionsTableID:     crdbInternalBuiltinFunctionsTable,
-		catconstants.CrdbInternalClusterQueriesTableID:       crdbInternalClusterQueriesTable,
-		catconstants.CrdbInternalClusterTransactionsTableID:  crdbInternalClusterTxnsTable,
-		catconstants.CrdbInternalClusterSessionsTableID:      crdbInternalClusterSessionsTable,
-		catconstants.CrdbInternalClusterSettingsTableID:      crdbInternalClusterSettingsTable,
-		catconstants.CrdbInternalCreateStmtsTableID:          crdbInternalCreateStmtsTable,
-		catconstants.CrdbInternalCreateTypeStmtsTableID:      crdbInternalCreateTypeStmtsTable,
-		catconstants.CrdbInternalDatabasesTableID:            crdbInternalDatabasesTable,
-		catconstants.CrdbInternalFeatureUsageID:              crdbInternalFeatureUsage,
-		catconstants.CrdbInternalForwardDependenciesTableID:  crdbInternalForwardDependenciesTable,
-		catconstants.CrdbInternalGossipNodesTableID:          crdbInternalGossipNodesTable,
-		catconstants.CrdbInternalGossipAlertsTableID:         crdbInternalGossipAlertsTable,
-		catconstants.CrdbInternalGossipLivenessTableID:       crdbInternalGossipLivenessTable,
-		catconstants.CrdbInternalGossipNetworkTableID:        crdbInternalGossipNetworkTable,
-		catconstants.CrdbInternalIndexColumnsTableID:         crdbInternalIndexColumnsTable,
-		catconstants.CrdbInternalJobsTableID:                 crdbInternalJobsTable,
-		catconstants.CrdbInternalKVNodeStatusTableID:         crdbInternalKVNodeStatusTable,
-		catconstants.CrdbInternalKVStoreStatusTableID:        crdbInternalKVStoreStatusTable,
-		catconstants.CrdbInternalLeasesTableID:               crdbInternalLeasesTable,
-		catconstants.CrdbInternalLocalQueriesTableID:         crdbInternalLocalQueriesTable,
-		catconstants.CrdbInternalLocalTransactionsTableID:    crdbInternalLocalTxnsTable,
-		catconstants.CrdbInternalLocalSessionsTableID:        crdbInternalLocalSessionsTable,
-		catconstants.CrdbInternalLocalMetricsTableID:         crdbInternalLocalMetricsTable,
-		catconstants.CrdbInternalPartitionsTableID:           crdbInternalPartitionsTable,
-		catconstants.CrdbInternalPredefinedCommentsTableID:   crdbInternalPredefinedCommentsTable,
-		catconstants.CrdbInternalRangesNoLeasesTableID:       crdbInternalRangesNoLeasesTable,
-		catconstants.CrdbInternalRangesViewID:                crdbInternalRangesView,
-		catconstants.CrdbInternalRuntimeInfoTableID:          crdbInternalRuntimeInfoTable,
-		catconstants.CrdbInternalSchemaChangesTableID:        crdbInternalSchemaChangesTable,
-		catconstants.CrdbInternalSessionTraceTableID:         crdbInternalSessionTraceTable,
-		catconstants.CrdbInternalSessionVariablesTableID:     crdbInternalSessionVariablesTable,
-		catconstants.CrdbInternalStmtStatsTableID:            crdbInternalStmtStatsTable,
-		catconstants.CrdbInternalTableColumnsTableID:         crdbInternalTableColumnsTable,
-		catconstants.CrdbInternalTableIndexesTableID:         crdbInternalTableIndexesTable,
-		catconstants.CrdbInternalTablesTableLastStatsID:      crdbInternalTablesTableLastStats,
-		catconstants.CrdbInternalTablesTableID:               crdbInternalTablesTable,
-		catconstants.CrdbInternalTransactionStatsTableID:     crdbInternalTransactionStatisticsTable,
-		catconstants.CrdbInternalTxnStatsTableID:             crdbInternalTxnStatsTable,
-		catconstants.CrdbInternalZonesTableID:                crdbInternalZonesTable,
+		catconstants.CrdbInternalBackwardDependenciesTableID:    crdbInternalBackwardDependenciesTable,
+		catconstants.CrdbInternalBuildInfoTableID:               crdbInternalBuildInfoTable,
+		catconstants.CrdbInternalBuiltinFunctionsTableID:        crdbInternalBuiltinFunctionsTable,
+		catconstants.CrdbInternalClusterContendedTablesViewID:   crdbInternalClusterContendedTablesView,
+		catconstants.CrdbInternalClusterContentionEventsTableID: crdbInternalClusterContentionEventsTable,
+		catconstants.CrdbInternalClusterQueriesTableID:          crdbInternalClusterQueriesTable,
+		catconstants.CrdbInternalClusterTransactionsTableID:     crdbInternalClusterTxnsTable,
+		catconstants.CrdbInternalClusterSessionsTableID:         crdbInternalClusterSessionsTable,
+		catconstants.CrdbInternalClusterSettingsTableID:         crdbInternalClusterSettingsTable,
+		catconstants.CrdbInternalCreateStmtsTableID:             crdbInternalCreateStmtsTable,
+		catconstants.CrdbInternalCreateTypeStmtsTableID:         crdbInternalCreateTypeStmtsTable,
+		catconstants.CrdbInternalDatabasesTableID:               crdbInternalDatabasesTable,
+		catconstants.CrdbInternalEventLogTableID:                crdbInternalEventLogTable,
+		catconstants.CrdbInternalFeatureUsageID:                 crdbInternalFeatureUsage,
+		catconstants.CrdbInternalForwardDependenciesTableID:     crdbInternalForwardDependenciesTable,
+		catconstants.CrdbInternalGossipNodesTableID:             crdbInternalGossipNodesTable,
+		catconstants.CrdbInternalGossipAlertsTableID:            crdbInternalGossipAlertsTable,
+		catconstants.CrdbInternalGossipLivenessTableID:          crdbInternalGossipLivenessTable,
+		catconstants.CrdbInternalGossipNetworkTableID:           crdbInternalGossipNetworkTable,
+		catconstants.CrdbInternalIndexColumnsTableID:            crdbInternalIndexColumnsTable,
+		catconstants.CrdbInternalIndexUsageStatisticsTableID:    crdbInternalIndexUsageStatistics,
+		catconstants.CrdbInternalJobsTableID:                    crdbInternalJobsTable,
+		catconstants.CrdbInternalKVNodeStatusTableID:            crdbInternalKVNodeStatusTable,
+		catconstants.CrdbInternalKVStoreStatusTableID:           crdbInternalKVStoreStatusTable,
+		catconstants.CrdbInternalLeasesTableID:                  crdbInternalLeasesTable,
+		catconstants.CrdbInternalLocalQueriesTableID:            crdbInternalLocalQueriesTable,
+		catconstants.CrdbInternalLocalTransactionsTableID:       crdbInternalLocalTxnsTable,
+		catconstants.CrdbInternalLocalSessionsTableID:           crdbInternalLocalSessionsTable,
+		catconstants.CrdbInternalLocalMetricsTableID:            crdbInternalLocalMetricsTable,
+		catconstants.CrdbInternalPartitionsTableID:              crdbInternalPartitionsTable,
+		catconstants.CrdbInternalPredefinedCommentsTableID:      crdbInternalPredefinedCommentsTable,
+		catconstants.CrdbInternalRangesNoLeasesTableID:          crdbInternalRangesNoLeasesTable,
+		catconstants.CrdbInternalRangesViewID:                   crdbInternalRangesView,
+		catconstants.CrdbInternalRuntimeInfoTableID:             crdbInternalRuntimeInfoTable,
+		catconstants.CrdbInternalSchemaChangesTableID:           crdbInternalSchemaChangesTable,
+		catconstants.CrdbInternalSessionTraceTableID:            crdbInternalSessionTraceTable,
+		catconstants.CrdbInternalSessionVariablesTableID:        crdbInternalSessionVariablesTable,
+		catconstants.CrdbInternalStatementStatisticsTableID:     crdbInternalClusterStmtStatsTable,
+		catconstants.CrdbInternalStmtStatsTableID:               crdbInternalStmtStatsTable,
+		catconstants.CrdbInternalTableColumnsTableID:            crdbInternalTableColumnsTable,
+		catconstants.CrdbInternalTableIndexesTableID:            crdbInternalTableIndexesTable,
+		catconstants.CrdbInternalTablesTableLastStatsID:         crdbInternalTablesTableLastStats,
+		catconstants.CrdbInternalTablesTableID:                  crdbInternalTablesTable,
+		catconstants.CrdbInternalTransactionStatisticsTableID:   crdbInternalClusterTxnStatsTable,
+		catconstants.CrdbInternalTransactionStatsTableID:        crdbInternalTransactionStatisticsTable,
+		catconstants.CrdbInternalTxnStatsTableID:                crdbInternalTxnStatsTable,
+		catconstants.CrdbInternalZonesTableID:                   crdbInternalZonesTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -711,6 +720,89 @@ CREATE TABLE crdb_internal.jobs (
 	},
 }
 
+// crdbInternalEventLogTable exposes the events recorded in system.eventlog,
+// with the details common to all the events triggered by SQL statements
+// decoded into separate columns.
+var crdbInternalEventLogTable = virtualSchemaTable{
+	comment: `decoded events from system.eventlog (KV scan)`,
+	schema: `
+CREATE TABLE crdb_internal.eventlog (
+  timestamp        TIMESTAMP NOT NULL,
+  event_type       STRING NOT NULL,
+  target_id        INT NOT NULL,
+  reporting_id     INT NOT NULL,
+  user_name        STRING,
+  statement        STRING,
+  application_name STRING,
+  descriptor_id    INT,
+  info             JSONB
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		if err := p.RequireAdminRole(ctx, "read crdb_internal.eventlog"); err != nil {
+			return err
+		}
+		rows, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryEx(
+			ctx, "crdb-internal-eventlog-table", p.txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUser},
+			`SELECT timestamp, "eventType", "targetID", "reportingID", info FROM system.eventlog`)
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			info := tree.DNull
+			userName, statement, appName, descID := tree.DNull, tree.DNull, tree.DNull, tree.DNull
+			// Events recorded by previous versions may not have a valid JSON
+			// payload; their details are then not decoded.
+			if s, ok := r[4].(*tree.DString); ok {
+				if d, err := tree.ParseDJSON(string(*s)); err == nil {
+					info = d
+					j := d.(*tree.DJSON).JSON
+					if userName, err = jsonTextField(j, "User"); err != nil {
+						return err
+					}
+					if statement, err = jsonTextField(j, "Statement"); err != nil {
+						return err
+					}
+					if appName, err = jsonTextField(j, "ApplicationName"); err != nil {
+						return err
+					}
+					id, err := jsonTextField(j, "DescriptorID")
+					if err != nil {
+						return err
+					}
+					if id != tree.DNull {
+						if descID, err = tree.ParseDInt(string(tree.MustBeDString(id))); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			if err := addRow(
+				r[0], r[1], r[2], r[3],
+				userName, statement, appName, descID,
+				info,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// jsonTextField returns the value of the given field of a JSON object as
+// a string, or NULL if the field is not present.
+func jsonTextField(j json.JSON, key string) (tree.Datum, error) {
+	v, err := j.FetchValKey(key)
+	if err != nil || v == nil {
+		return tree.DNull, err
+	}
+	s, err := v.AsText()
+	if err != nil || s == nil {
+		return tree.DNull, err
+	}
+	return tree.NewDString(*s), nil
+}
+
 type stmtList []stmtKey
 
 func (s stmtList) Len() int {
@@ -1039,6 +1131,238 @@ CREATE TABLE crdb_internal.node_txn_stats (
 	},
 }
 
+// crdbInternalClusterStmtStatsTable exposes the statement statistics of the
+// whole cluster, merging the statistics flushed to
+// system.statement_statistics with those still held in the memory of the
+// nodes. The latter are attributed to the current aggregation interval.
+var crdbInternalClusterStmtStatsTable = virtualSchemaTable{
+	comment: `cluster-wide statement statistics, aggregated over time intervals ` +
+		`(KV scan and cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.statement_statistics (
+  aggregated_ts  TIMESTAMPTZ NOT NULL,
+  fingerprint_id STRING NOT NULL,
+  app_name       STRING NOT NULL,
+  node_id        INT NOT NULL,
+  agg_interval   INTERVAL NOT NULL,
+  metadata       JSONB NOT NULL,
+  statistics     JSONB NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		hasViewActivity, err := p.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivity {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s privilege", p.User(), roleoption.VIEWACTIVITY)
+		}
+
+		type stmtStatsRow struct {
+			aggInterval time.Duration
+			metadata    roachpb.StatementStatisticsKey
+			statistics  roachpb.StatementStatistics
+		}
+		var keys []sqlStatsKey
+		rows := make(map[sqlStatsKey]*stmtStatsRow)
+
+		persisted, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryEx(
+			ctx, "crdb-internal-statement-statistics", p.txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUser},
+			`SELECT aggregated_ts, fingerprint_id, app_name, node_id, agg_interval, metadata, statistics
+			   FROM system.statement_statistics`)
+		if err != nil {
+			return err
+		}
+		for _, r := range persisted {
+			key := sqlStatsKey{
+				aggregatedTs:  tree.MustBeDTimestampTZ(r[0]).Time,
+				fingerprintID: string(tree.MustBeDString(r[1])),
+				appName:       string(tree.MustBeDString(r[2])),
+				nodeID:        int64(tree.MustBeDInt(r[3])),
+			}
+			row := &stmtStatsRow{aggInterval: time.Duration(tree.MustBeDInterval(r[4]).Nanos())}
+			if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(r[5])), &row.metadata); err != nil {
+				return err
+			}
+			if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(r[6])), &row.statistics); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			rows[key] = row
+		}
+
+		response, err := p.extendedEvalCtx.SQLStatusServer.Statements(ctx, &serverpb.StatementsRequest{})
+		if err != nil {
+			return err
+		}
+		aggregatedTs, aggInterval := currentSQLStatsAggregation(p.execCfg)
+		for _, stmt := range response.Statements {
+			if stmt.Stats.Count == 0 {
+				continue
+			}
+			key := sqlStatsKey{
+				aggregatedTs:  aggregatedTs,
+				fingerprintID: string(stmt.ID),
+				appName:       stmt.Key.KeyData.App,
+				nodeID:        int64(stmt.Key.NodeID),
+			}
+			if row, ok := rows[key]; ok {
+				row.statistics.Add(&stmt.Stats)
+				continue
+			}
+			keys = append(keys, key)
+			rows[key] = &stmtStatsRow{
+				aggInterval: aggInterval,
+				metadata:    stmt.Key.KeyData,
+				statistics:  stmt.Stats,
+			}
+		}
+
+		sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+		for _, key := range keys {
+			row := rows[key]
+			datums, err := key.datums()
+			if err != nil {
+				return err
+			}
+			metadata, err := protoreflect.MessageToJSON(&row.metadata)
+			if err != nil {
+				return err
+			}
+			statistics, err := protoreflect.MessageToJSON(&row.statistics)
+			if err != nil {
+				return err
+			}
+			if err := addRow(append(datums,
+				tree.NewDInterval(duration.MakeDuration(row.aggInterval.Nanoseconds(), 0, 0), types.DefaultIntervalTypeMetadata),
+				tree.NewDJSON(metadata),
+				tree.NewDJSON(statistics),
+			)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalClusterTxnStatsTable exposes the transaction statistics of the
+// whole cluster, merging the statistics flushed to
+// system.transaction_statistics with those still held in the memory of the
+// nodes. The latter are attributed to the current aggregation interval.
+var crdbInternalClusterTxnStatsTable = virtualSchemaTable{
+	comment: `cluster-wide transaction statistics, aggregated over time intervals ` +
+		`(KV scan and cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.transaction_statistics (
+  aggregated_ts  TIMESTAMPTZ NOT NULL,
+  fingerprint_id STRING NOT NULL,
+  app_name       STRING NOT NULL,
+  node_id        INT NOT NULL,
+  agg_interval   INTERVAL NOT NULL,
+  statement_ids  STRING[] NOT NULL,
+  statistics     JSONB NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		hasViewActivity, err := p.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivity {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s privilege", p.User(), roleoption.VIEWACTIVITY)
+		}
+
+		type txnStatsRow struct {
+			aggInterval  time.Duration
+			statementIDs tree.Datum
+			statistics   roachpb.TransactionStatistics
+		}
+		var keys []sqlStatsKey
+		rows := make(map[sqlStatsKey]*txnStatsRow)
+
+		persisted, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.QueryEx(
+			ctx, "crdb-internal-transaction-statistics", p.txn,
+			sessiondata.InternalExecutorOverride{User: security.RootUser},
+			`SELECT aggregated_ts, fingerprint_id, app_name, node_id, agg_interval, statement_ids, statistics
+			   FROM system.transaction_statistics`)
+		if err != nil {
+			return err
+		}
+		for _, r := range persisted {
+			key := sqlStatsKey{
+				aggregatedTs:  tree.MustBeDTimestampTZ(r[0]).Time,
+				fingerprintID: string(tree.MustBeDString(r[1])),
+				appName:       string(tree.MustBeDString(r[2])),
+				nodeID:        int64(tree.MustBeDInt(r[3])),
+			}
+			row := &txnStatsRow{
+				aggInterval:  time.Duration(tree.MustBeDInterval(r[4]).Nanos()),
+				statementIDs: r[5],
+			}
+			if err := protoutil.Unmarshal([]byte(tree.MustBeDBytes(r[6])), &row.statistics); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			rows[key] = row
+		}
+
+		response, err := p.extendedEvalCtx.SQLStatusServer.Statements(ctx, &serverpb.StatementsRequest{})
+		if err != nil {
+			return err
+		}
+		aggregatedTs, aggInterval := currentSQLStatsAggregation(p.execCfg)
+		for _, txn := range response.Transactions {
+			if txn.StatsData.Stats.Count == 0 {
+				continue
+			}
+			key := sqlStatsKey{
+				aggregatedTs:  aggregatedTs,
+				fingerprintID: txn.StatsData.ID,
+				appName:       txn.StatsData.App,
+				nodeID:        int64(txn.NodeID),
+			}
+			if row, ok := rows[key]; ok {
+				row.statistics.Add(&txn.StatsData.Stats)
+				continue
+			}
+			stmtIDs := tree.NewDArray(types.String)
+			for _, id := range txn.StatsData.StatementIDs {
+				if err := stmtIDs.Append(tree.NewDString(string(id))); err != nil {
+					return err
+				}
+			}
+			keys = append(keys, key)
+			rows[key] = &txnStatsRow{
+				aggInterval:  aggInterval,
+				statementIDs: stmtIDs,
+				statistics:   txn.StatsData.Stats,
+			}
+		}
+
+		sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+		for _, key := range keys {
+			row := rows[key]
+			datums, err := key.datums()
+			if err != nil {
+				return err
+			}
+			statistics, err := protoreflect.MessageToJSON(&row.statistics)
+			if err != nil {
+				return err
+			}
+			if err := addRow(append(datums,
+				tree.NewDInterval(duration.MakeDuration(row.aggInterval.Nanoseconds(), 0, 0), types.DefaultIntervalTypeMetadata),
+				row.statementIDs,
+				tree.NewDJSON(statistics),
+			)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalSessionTraceTable exposes the latest trace collected on this
 // session (via SET TRACING={ON/OFF})
 //
@@ -1202,6 +1526,159 @@ var crdbInternalClusterTxnsTable = virtualSchemaTable{
 	},
 }
 
+// crdbInternalClusterContentionEventsTable exposes the contention events
+// aggregated by the KV layer on every node. There is one row for every
+// (index, key, blocking transaction, waiting transaction) tuple.
+var crdbInternalClusterContentionEventsTable = virtualSchemaTable{
+	comment: `contention information (cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.cluster_contention_events (
+  table_id                   INT NOT NULL,
+  index_id                   INT NOT NULL,
+  num_contention_events      INT NOT NULL,
+  cumulative_contention_time INTERVAL NOT NULL,
+  key                        BYTES NOT NULL,
+  txn_id                     UUID NOT NULL,
+  count                      INT NOT NULL,
+  waiting_txn_id             UUID NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		hasViewActivity, err := p.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivity {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s privilege", p.User(), roleoption.VIEWACTIVITY)
+		}
+		response, err := p.extendedEvalCtx.SQLStatusServer.ListContentionEvents(
+			ctx, &serverpb.ListContentionEventsRequest{})
+		if err != nil {
+			return err
+		}
+		// The events of the nodes that could not be contacted are missing, but
+		// the partial results are still useful.
+		for _, rpcErr := range response.Errors {
+			log.Warningf(ctx, "error fetching contention events from node %d: %s",
+				rpcErr.NodeID, rpcErr.Message)
+		}
+		for _, ice := range response.Events {
+			tableID := tree.NewDInt(tree.DInt(ice.TableID))
+			indexID := tree.NewDInt(tree.DInt(ice.IndexID))
+			numContentionEvents := tree.NewDInt(tree.DInt(ice.NumContentionEvents))
+			cumulativeContentionTime := tree.NewDInterval(
+				duration.MakeDuration(ice.CumulativeContentionTime.Nanoseconds(), 0, 0),
+				types.DefaultIntervalTypeMetadata,
+			)
+			for _, skc := range ice.Events {
+				key := tree.NewDBytes(tree.DBytes(skc.Key))
+				for _, stc := range skc.Txns {
+					if err := addRow(
+						tableID,
+						indexID,
+						numContentionEvents,
+						cumulativeContentionTime,
+						key,
+						tree.NewDUuid(tree.DUuid{UUID: stc.TxnID}),
+						tree.NewDInt(tree.DInt(stc.Count)),
+						tree.NewDUuid(tree.DUuid{UUID: stc.WaitingTxnID}),
+					); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalClusterContendedTablesView exposes the contention events with
+// the table and index IDs decoded into names, aggregated per index.
+var crdbInternalClusterContendedTablesView = virtualSchemaView{
+	schema: `
+CREATE VIEW crdb_internal.cluster_contended_tables (
+  database_name,
+  schema_name,
+  table_name,
+  index_name,
+  num_contention_events
+) AS
+  SELECT
+    t.database_name, t.schema_name, t.name, i.index_name, c.num_contention_events
+  FROM
+    (
+      SELECT DISTINCT table_id, index_id, num_contention_events
+      FROM crdb_internal.cluster_contention_events
+    ) AS c
+    JOIN crdb_internal.tables AS t ON c.table_id = t.table_id
+    JOIN crdb_internal.table_indexes AS i
+      ON c.table_id = i.descriptor_id AND c.index_id = i.index_id
+  ORDER BY c.num_contention_events DESC
+`,
+	resultColumns: colinfo.ResultColumns{
+		{Name: "database_name", Typ: types.String},
+		{Name: "schema_name", Typ: types.String},
+		{Name: "table_name", Typ: types.String},
+		{Name: "index_name", Typ: types.String},
+		{Name: "num_contention_events", Typ: types.Int},
+	},
+}
+
+// crdbInternalIndexUsageStatistics exposes the read statistics of the indexes
+// in the current database, collected on all nodes in the cluster. Indexes that
+// were never read since the nodes started are reported with no reads and a
+// NULL last read time.
+var crdbInternalIndexUsageStatistics = virtualSchemaTable{
+	comment: `index read statistics (cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.index_usage_statistics (
+  table_id    INT NOT NULL,
+  index_id    INT NOT NULL,
+  total_reads INT NOT NULL,
+  last_read   TIMESTAMPTZ
+)`,
+	populate: func(ctx context.Context, p *planner, db *dbdesc.Immutable, addRow func(...tree.Datum) error) error {
+		hasViewActivity, err := p.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivity {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s privilege", p.User(), roleoption.VIEWACTIVITY)
+		}
+		response, err := p.extendedEvalCtx.SQLStatusServer.IndexUsageStatistics(
+			ctx, &serverpb.IndexUsageStatisticsRequest{})
+		if err != nil {
+			return err
+		}
+		stats := make(map[idxusage.IndexKey]serverpb.IndexUsageStatistics, len(response.Statistics))
+		for _, s := range response.Statistics {
+			stats[idxusage.IndexKey{TableID: s.TableID, IndexID: s.IndexID}] = s
+		}
+		return forEachTableDescAll(ctx, p, db, hideVirtual,
+			func(_ *dbdesc.Immutable, _ string, table catalog.TableDescriptor) error {
+				tableID := table.GetID()
+				return table.ForeachIndex(catalog.IndexOpts{}, func(idx *descpb.IndexDescriptor, _ bool) error {
+					s := stats[idxusage.IndexKey{TableID: tableID, IndexID: idx.ID}]
+					lastRead := tree.DNull
+					if !s.LastRead.IsZero() {
+						lastRead, err = tree.MakeDTimestampTZ(s.LastRead, time.Microsecond)
+						if err != nil {
+							return err
+						}
+					}
+					return addRow(
+						tree.NewDInt(tree.DInt(tableID)),
+						tree.NewDInt(tree.DInt(idx.ID)),
+						tree.NewDInt(tree.DInt(s.TotalReadCount)),
+						lastRead,
+					)
+				})
+			},
+		)
+	},
+}
+
 func populateTransactionsTable(
 	ctx context.Context, addRow func(...tree.Datum) error, response *serverpb.ListSessionsResponse,
 ) error {
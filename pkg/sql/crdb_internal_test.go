@@ -384,6 +384,46 @@ WHERE
 	}
 }
 
+// TestClusterContentionEventsTxnIDs verifies that the contention events in
+// crdb_internal.cluster_contention_events record both the transaction that held
+// the lock and the transaction that waited on it.
+func TestClusterContentionEventsTxnIDs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	// Contention events are only recorded on keys of SQL indexes.
+	key := append(keys.SystemSQLCodec.IndexPrefix(keys.MinUserDescID, 1), "a"...)
+	blocking := kvDB.NewTxn(ctx, "blocking")
+	require.NoError(t, blocking.Put(ctx, key, "blocking"))
+	blockingID := blocking.ID()
+
+	// The waiting transaction has the highest priority, so it pushes the
+	// blocking transaction out of its way right away instead of waiting for it
+	// to finish.
+	waiting := kvDB.NewTxn(ctx, "waiting")
+	require.NoError(t, waiting.SetUserPriority(roachpb.MaxUserPriority))
+	waitingID := waiting.ID()
+	require.NoError(t, waiting.Put(ctx, key, "waiting"))
+	require.NoError(t, waiting.Commit(ctx))
+	require.Error(t, blocking.Commit(ctx))
+
+	var count int
+	require.NoError(t, sqlDB.QueryRow(`
+SELECT
+	count
+FROM
+	crdb_internal.cluster_contention_events
+WHERE
+	txn_id = $1 AND waiting_txn_id = $2`,
+		blockingID.String(), waitingID.String(),
+	).Scan(&count))
+	require.Equal(t, 1, count)
+}
+
 // TestCrdbInternalJobsOOM verifies that the memory budget works correctly for
 // crdb_internal.jobs.
 func TestCrdbInternalJobsOOM(t *testing.T) {
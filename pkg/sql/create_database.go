@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type createDatabaseNode struct {
@@ -90,18 +91,12 @@ func (n *createDatabaseNode) startExec(params runParams) error {
 	if created {
 		// Log Create Database event. This is an auditable log event and is
 		// recorded in the same transaction as the table descriptor update.
-		if err := MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
-			params.ctx,
-			params.p.txn,
-			EventLogCreateDatabase,
-			int32(desc.GetID()),
-			int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
-			struct {
-				DatabaseName string
-				Statement    string
-				User         string
-			}{n.n.Name.String(), n.n.String(), params.SessionData().User},
-		); err != nil {
+		if err := params.p.logEvent(params.ctx,
+			desc.GetID(),
+			&eventpb.CreateDatabase{
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{Statement: n.n.String()},
+				DatabaseName:          n.n.Name.String(),
+			}); err != nil {
 			return err
 		}
 	}
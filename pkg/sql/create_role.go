@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/errors"
 )
 
@@ -229,7 +230,14 @@ func (n *CreateRoleNode) startExec(params runParams) error {
 		}
 	}
 
-	return nil
+	// Log the role creation. The statement is not recorded because it
+	// may contain a password.
+	return params.p.logEvent(params.ctx,
+		0, /* no target */
+		&eventpb.CreateRole{
+			RoleName: normalizedUsername,
+			Options:  n.roleOptions.Names(),
+		})
 }
 
 // Next implements the planNode interface.
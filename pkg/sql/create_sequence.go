@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type createSequenceNode struct {
@@ -148,18 +149,12 @@ func doCreateSequence(
 
 	// Log Create Sequence event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
-	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
-		params.ctx,
-		params.p.txn,
-		EventLogCreateSequence,
-		int32(desc.ID),
-		int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
-		struct {
-			SequenceName string
-			Statement    string
-			User         string
-		}{name.FQString(), context, params.SessionData().User},
-	)
+	return params.p.logEvent(params.ctx,
+		desc.ID,
+		&eventpb.CreateSequence{
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{Statement: context},
+			SequenceName:          name.FQString(),
+		})
 }
 
 func (*createSequenceNode) Next(runParams) (bool, error) { return false, nil }
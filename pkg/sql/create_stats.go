@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/kv"
@@ -37,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/errors"
 )
 
@@ -199,7 +201,11 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 
 	// Identify which columns we should create statistics for.
 	var colStats []jobspb.CreateStatsDetails_ColStat
-	if len(n.ColumnNames) == 0 {
+	if n.Expr != nil {
+		if colStats, err = n.makeExprColStats(ctx, tableDesc); err != nil {
+			return nil, err
+		}
+	} else if len(n.ColumnNames) == 0 {
 		multiColEnabled := stats.MultiColumnStatisticsClusterMode.Get(&n.p.ExecCfg().Settings.SV)
 		if colStats, err = createStatsDefaultColumns(tableDesc, multiColEnabled); err != nil {
 			return nil, err
@@ -276,6 +282,47 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 	}, nil
 }
 
+// makeExprColStats validates the expression of a CREATE STATISTICS ... ON
+// (expr) statement and returns the column statistic on that expression. The
+// expression must be immutable and must reference at least one column of the
+// table.
+func (n *createStatsNode) makeExprColStats(
+	ctx context.Context, tableDesc *tabledesc.Immutable,
+) ([]jobspb.CreateStatsDetails_ColStat, error) {
+	if !n.p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.VersionStatisticsExpressions) {
+		return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`creating statistics on expressions requires all nodes to be upgraded to %s`,
+			clusterversion.VersionByKey(clusterversion.VersionStatisticsExpressions))
+	}
+
+	tn := tree.MakeUnqualifiedTableName(tree.Name(tableDesc.Name))
+	expr, typ, colIDs, err := schemaexpr.DequalifyAndValidateExpr(
+		ctx,
+		tableDesc,
+		n.Expr,
+		types.Any,
+		"CREATE STATISTICS",
+		&n.p.semaCtx,
+		tree.VolatilityImmutable,
+		&tn,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if colIDs.Empty() {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"statistics expression %s must reference at least one column", tree.AsString(n.Expr))
+	}
+
+	return []jobspb.CreateStatsDetails_ColStat{{
+		ColumnIDs: colIDs.Ordered(),
+		// Histograms are not supported on inverted index column types.
+		HasHistogram:        !colinfo.ColumnTypeIsInvertedIndexable(typ),
+		HistogramMaxBuckets: defaultHistogramBuckets,
+		Expression:          expr,
+	}}, nil
+}
+
 // maxNonIndexCols is the maximum number of non-index columns that we will use
 // when choosing a default set of column statistics.
 const maxNonIndexCols = 100
@@ -567,13 +614,15 @@ func (r *createStatsResumer) Resume(
 		return MakeEventLogger(evalCtx.ExecCfg).InsertEventRecord(
 			ctx,
 			txn,
-			EventLogCreateStatistics,
 			int32(details.Table.ID),
 			int32(evalCtx.NodeID.SQLInstanceID()),
-			struct {
-				TableName string
-				Statement string
-			}{details.FQTableName, details.Statement},
+			&eventpb.CreateStatistics{
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					Statement:    details.Statement,
+					DescriptorID: uint32(details.Table.ID),
+				},
+				TableName: details.FQTableName,
+			},
 		)
 	})
 }
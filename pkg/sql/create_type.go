@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/errors"
 )
 
@@ -327,18 +328,14 @@ func (p *planner) createEnum(params runParams, n *tree.CreateType) error {
 	}
 
 	// Log the event.
-	return MakeEventLogger(p.ExecCfg()).InsertEventRecord(
-		params.ctx,
-		p.txn,
-		EventLogCreateType,
-		int32(typeDesc.GetID()),
-		int32(p.ExtendedEvalContext().NodeID.SQLInstanceID()),
-		struct {
-			TypeName  string
-			Statement string
-			User      string
-		}{typeName.FQString(), tree.AsStringWithFQNames(n, params.Ann()), p.User()},
-	)
+	return p.logEvent(params.ctx,
+		typeDesc.GetID(),
+		&eventpb.CreateType{
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: tree.AsStringWithFQNames(n, params.Ann()),
+			},
+			TypeName: typeName.FQString(),
+		})
 }
 
 func (n *createTypeNode) Next(params runParams) (bool, error) { return false, nil }
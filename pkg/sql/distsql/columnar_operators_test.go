@@ -18,15 +18,23 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/col/typeconv"
+	"github.com/cockroachdb/cockroach/pkg/geo"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 	"github.com/stretchr/testify/require"
@@ -1099,6 +1107,260 @@ func TestWindowFunctionsAgainstProcessor(t *testing.T) {
 	}
 }
 
+func TestProjectSetAgainstProcessor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng, seed := randutil.NewPseudoRand()
+	nRuns := 10
+	nRows := 10
+	maxNum := 3
+	intTypes := []*types.T{types.Int, types.Int}
+	for _, tc := range []struct {
+		// inputTypes, if set, are the types of the random input. By default,
+		// the input consists of two small integer columns.
+		inputTypes       []*types.T
+		exprs            []string
+		generatedColumns []*types.T
+		numColsPerGen    []uint32
+	}{
+		{
+			exprs:            []string{"generate_series(@1, @2)"},
+			generatedColumns: []*types.T{types.Int},
+			numColsPerGen:    []uint32{1},
+		},
+		{
+			exprs:            []string{"@1 + @2", "generate_series(@1, @2)"},
+			generatedColumns: []*types.T{types.Int, types.Int},
+			numColsPerGen:    []uint32{1, 1},
+		},
+		{
+			exprs:            []string{"generate_series(@2, @1, -1)", "generate_series(0, @2, 2)"},
+			generatedColumns: []*types.T{types.Int, types.Int},
+			numColsPerGen:    []uint32{1, 1},
+		},
+		{
+			exprs:            []string{"unnest(ARRAY[@1, @2])", "generate_series(0, @1)"},
+			generatedColumns: []*types.T{types.Int, types.Int},
+			numColsPerGen:    []uint32{1, 1},
+		},
+		{
+			exprs:            []string{"information_schema._pg_expandarray(ARRAY[@1, @2])", "@2"},
+			generatedColumns: []*types.T{types.Int, types.Int, types.Int},
+			numColsPerGen:    []uint32{2, 1},
+		},
+		{
+			inputTypes:       []*types.T{types.StringArray, types.String},
+			exprs:            []string{"unnest(@1)", "regexp_split_to_table(@2, 'a')"},
+			generatedColumns: []*types.T{types.String, types.String},
+			numColsPerGen:    []uint32{1, 1},
+		},
+		{
+			inputTypes:       []*types.T{types.String, types.Jsonb},
+			exprs:            []string{"json_array_elements(json_build_array(@2, @1))", "json_each(json_build_object('k', @2, 'v', @1))"},
+			generatedColumns: []*types.T{types.Jsonb, types.String, types.Jsonb},
+			numColsPerGen:    []uint32{1, 2},
+		},
+	} {
+		inputTypes := tc.inputTypes
+		if inputTypes == nil {
+			inputTypes = intTypes
+		}
+		for run := 0; run < nRuns; run++ {
+			var rows rowenc.EncDatumRows
+			if tc.inputTypes == nil {
+				rows = rowenc.MakeRandIntRowsInRange(rng, nRows, len(inputTypes), maxNum, nullProbability)
+			} else {
+				rows = rowenc.RandEncDatumRowsOfTypes(rng, nRows, inputTypes)
+			}
+			exprs := make([]execinfrapb.Expression, len(tc.exprs))
+			for i, expr := range tc.exprs {
+				exprs[i].Expr = expr
+			}
+			spec := &execinfrapb.ProjectSetSpec{
+				Exprs:            exprs,
+				GeneratedColumns: tc.generatedColumns,
+				NumColsPerGen:    tc.numColsPerGen,
+			}
+			pspec := &execinfrapb.ProcessorSpec{
+				Input: []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
+				Core:  execinfrapb.ProcessorCoreUnion{ProjectSet: spec},
+			}
+			outputTypes := make([]*types.T, 0, len(inputTypes)+len(tc.generatedColumns))
+			outputTypes = append(outputTypes, inputTypes...)
+			outputTypes = append(outputTypes, tc.generatedColumns...)
+			args := verifyColOperatorArgs{
+				anyOrder:    false,
+				inputTypes:  [][]*types.T{inputTypes},
+				inputs:      []rowenc.EncDatumRows{rows},
+				outputTypes: outputTypes,
+				pspec:       pspec,
+			}
+			if err := verifyColOperator(args); err != nil {
+				fmt.Printf("--- seed = %d run = %d exprs = %v ---\n", seed, run, tc.exprs)
+				prettyPrintInput(rows, inputTypes, "t" /* tableName */)
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestZigzagJoinerAgainstProcessor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	rng, seed := randutil.NewPseudoRand()
+	nRuns := 10
+	nRows := 100
+	maxNum := 3
+	randIntFn := func(int) tree.Datum {
+		return tree.NewDInt(tree.DInt(rng.Intn(maxNum)))
+	}
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"a INT PRIMARY KEY, b INT, c INT, INDEX b (b), INDEX c (c)",
+		nRows,
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, randIntFn, randIntFn),
+	)
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+	const bIndex, cIndex = 1, 2
+	fixedValuesSpec := func(value int) *execinfrapb.ValuesCoreSpec {
+		spec, err := execinfra.GenerateValuesSpec(
+			[]*types.T{types.Int},
+			rowenc.EncDatumRows{{rowenc.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(value)))}},
+			1, /* rowsPerChunk */
+		)
+		require.NoError(t, err)
+		return &spec
+	}
+
+	for run := 0; run < nRuns; run++ {
+		// Only the columns present in the indexes are fetched, so the ON
+		// expression and the output columns can only reference the columns
+		// a and b of the left side and a and c of the right side.
+		for _, onExpr := range []string{"", "@1 % 2 = 0", "@2 < @6"} {
+			bValue, cValue := rng.Intn(maxNum), rng.Intn(maxNum)
+			spec := &execinfrapb.ZigzagJoinerSpec{
+				Tables:        []descpb.TableDescriptor{*td.TableDesc(), *td.TableDesc()},
+				IndexOrdinals: []uint32{bIndex, cIndex},
+				EqColumns: []execinfrapb.Columns{
+					{Columns: []uint32{0}},
+					{Columns: []uint32{0}},
+				},
+				FixedValues: []*execinfrapb.ValuesCoreSpec{
+					fixedValuesSpec(bValue), fixedValuesSpec(cValue),
+				},
+				OnExpr: execinfrapb.Expression{Expr: onExpr},
+				Type:   descpb.InnerJoin,
+			}
+			pspec := &execinfrapb.ProcessorSpec{
+				Core: execinfrapb.ProcessorCoreUnion{ZigzagJoiner: spec},
+				Post: execinfrapb.PostProcessSpec{
+					Projection:    true,
+					OutputColumns: []uint32{0, 1, 5},
+				},
+			}
+			args := verifyColOperatorArgs{
+				anyOrder:    true,
+				outputTypes: []*types.T{types.Int, types.Int, types.Int},
+				pspec:       pspec,
+				rng:         rng,
+				txn:         kv.NewTxn(ctx, kvDB, s.NodeID()),
+			}
+			if err := verifyColOperator(args); err != nil {
+				fmt.Printf("--- seed = %d run = %d b = %d c = %d on expr = %q ---\n",
+					seed, run, bValue, cValue, onExpr)
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestInvertedJoinerAgainstProcessor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	rng, seed := randutil.NewPseudoRand()
+	nRuns := 5
+	nRows := 100
+	nInputRows := 20
+	maxCoord := 10
+	randPointFn := func(int) tree.Datum {
+		return tree.NewDGeometry(geo.MustParseGeometry(
+			fmt.Sprintf("POINT(%d %d)", rng.Intn(maxCoord), rng.Intn(maxCoord)),
+		))
+	}
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"k INT PRIMARY KEY, g GEOMETRY, INVERTED INDEX gi (g)",
+		nRows,
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, randPointFn),
+	)
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+	const giIndex = 1
+
+	inputTypes := []*types.T{types.Geometry}
+	for run := 0; run < nRuns; run++ {
+		rows := make(rowenc.EncDatumRows, nInputRows)
+		for i := range rows {
+			if rng.Float64() < nullProbability {
+				rows[i] = rowenc.EncDatumRow{rowenc.DatumToEncDatum(types.Geometry, tree.DNull)}
+				continue
+			}
+			x, y := rng.Intn(maxCoord), rng.Intn(maxCoord)
+			polygon := tree.NewDGeometry(geo.MustParseGeometry(fmt.Sprintf(
+				"POLYGON((%d %d, %d %d, %d %d, %d %d, %d %d))",
+				x, y, x+2, y, x+2, y+2, x, y+2, x, y,
+			)))
+			rows[i] = rowenc.EncDatumRow{rowenc.DatumToEncDatum(types.Geometry, polygon)}
+		}
+		for _, joinType := range []descpb.JoinType{
+			descpb.InnerJoin, descpb.LeftOuterJoin, descpb.LeftSemiJoin, descpb.LeftAntiJoin,
+		} {
+			// The inverted column of the table is not available to the ON
+			// expression or the output since only its encoded inverted key is
+			// retrieved from the index.
+			for _, onExpr := range []string{"", "@2 % 2 = 0"} {
+				spec := &execinfrapb.InvertedJoinerSpec{
+					Table:        *td.TableDesc(),
+					IndexIdx:     giIndex,
+					InvertedExpr: execinfrapb.Expression{Expr: "st_intersects(@1, @3)"},
+					OnExpr:       execinfrapb.Expression{Expr: onExpr},
+					Type:         joinType,
+				}
+				pspec := &execinfrapb.ProcessorSpec{
+					Input: []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
+					Core:  execinfrapb.ProcessorCoreUnion{InvertedJoiner: spec},
+				}
+				outputTypes := inputTypes
+				if joinType == descpb.InnerJoin || joinType == descpb.LeftOuterJoin {
+					pspec.Post = execinfrapb.PostProcessSpec{
+						Projection:    true,
+						OutputColumns: []uint32{0, 1},
+					}
+					outputTypes = []*types.T{types.Geometry, types.Int}
+				}
+				args := verifyColOperatorArgs{
+					anyOrder:    true,
+					inputTypes:  [][]*types.T{inputTypes},
+					inputs:      []rowenc.EncDatumRows{rows},
+					outputTypes: outputTypes,
+					pspec:       pspec,
+					rng:         rng,
+					txn:         kv.NewTxn(ctx, kvDB, s.NodeID()),
+				}
+				if err := verifyColOperator(args); err != nil {
+					fmt.Printf("--- seed = %d run = %d join type = %s on expr = %q ---\n",
+						seed, run, joinType, onExpr)
+					prettyPrintInput(rows, inputTypes, "t" /* tableName */)
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
 // generateRandomSupportedTypes generates nCols random types that are supported
 // by the vectorized engine.
 func generateRandomSupportedTypes(rng *rand.Rand, nCols int) []*types.T {
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/col/coldataext"
+	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/colcontainer"
 	"github.com/cockroachdb/cockroach/pkg/sql/colexec"
@@ -64,6 +65,9 @@ type verifyColOperatorArgs struct {
 	numForcedRepartitions int
 	// rng (if set) will be used to randomize batch size.
 	rng *rand.Rand
+	// txn (if set) will be used by the processors and the operators that read
+	// from the KV layer.
+	txn *kv.Txn
 }
 
 // verifyColOperator passes inputs through both the processor defined by pspec
@@ -102,6 +106,9 @@ func verifyColOperator(args verifyColOperatorArgs) error {
 		},
 	}
 	flowCtx.Cfg.TestingKnobs.ForceDiskSpill = args.forceDiskSpill
+	if args.txn != nil {
+		flowCtx.Txn = args.txn
+	}
 
 	inputsProc := make([]execinfra.RowSource, len(args.inputs))
 	inputsColOp := make([]execinfra.RowSource, len(args.inputs))
@@ -199,6 +206,11 @@ func verifyColOperator(args verifyColOperatorArgs) error {
 			procRows = append(procRows, printRowForChecking(rowProc))
 		}
 		if metaProc != nil {
+			if metaProc.Err == nil && metaProc.Metrics != nil {
+				// The processors that read from the KV layer emit metrics
+				// metadata which we don't compare.
+				continue
+			}
 			if metaProc.Err == nil {
 				return errors.Errorf("unexpectedly processor returned non-error "+
 					"meta\n%+v", metaProc)
@@ -210,6 +222,9 @@ func verifyColOperator(args verifyColOperatorArgs) error {
 			colOpRows = append(colOpRows, printRowForChecking(rowColOp))
 		}
 		if metaColOp != nil {
+			if metaColOp.Err == nil && metaColOp.Metrics != nil {
+				continue
+			}
 			if metaColOp.Err == nil {
 				return errors.Errorf("unexpectedly columnar operator returned "+
 					"non-error meta\n%+v", metaColOp)
@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -169,6 +170,28 @@ func FlowVerIsCompatible(
 	return flowVer >= minAcceptedVersion && flowVer <= serverVersion
 }
 
+// makeFlowAdmissionWorkInfo returns the information used to admit a flow.
+func makeFlowAdmissionWorkInfo(
+	req *execinfrapb.SetupFlowRequest, localState LocalState,
+) admission.WorkInfo {
+	info := admission.WorkInfo{
+		Priority: admission.NormalPri,
+		// Flows of older transactions are preferred.
+		CreateTime: req.EvalContext.TxnTimestampNanos,
+	}
+	if req.LeafTxnInputState == nil && localState.Txn == nil {
+		// Flows that don't run in a transaction are run by bulk operations.
+		info.Priority = admission.BulkNormalPri
+	}
+	if localState.EvalContext != nil && !localState.IsLocal {
+		// The gateway flow of a distributed plan is set up after its remote
+		// flows, which are already running and waiting for it, so it must not
+		// wait for admission.
+		info.BypassAdmission = true
+	}
+	return info
+}
+
 // setupFlow creates a Flow.
 //
 // Args:
@@ -184,7 +207,7 @@ func (ds *ServerImpl) setupFlow(
 	req *execinfrapb.SetupFlowRequest,
 	syncFlowConsumer execinfra.RowReceiver,
 	localState LocalState,
-) (context.Context, flowinfra.Flow, error) {
+) (_ context.Context, retFlow flowinfra.Flow, retErr error) {
 	if !FlowVerIsCompatible(req.Version, execinfra.MinAcceptedVersion, execinfra.Version) {
 		err := errors.Errorf(
 			"version mismatch in flow request: %d; this node accepts %d through %d",
@@ -194,6 +217,24 @@ func (ds *ServerImpl) setupFlow(
 		return ctx, nil, err
 	}
 
+	if admissionQ := ds.ServerConfig.SQLFlowAdmissionQ; admissionQ != nil {
+		enabled, err := admissionQ.Admit(ctx, makeFlowAdmissionWorkInfo(req, localState))
+		if err != nil {
+			return ctx, nil, err
+		}
+		if enabled {
+			// The grant is returned once the flow is cleaned up or, if the setup
+			// fails, right away.
+			defer func() {
+				if retErr != nil {
+					admissionQ.AdmittedWorkDone()
+				} else {
+					retFlow.AddOnCleanup(admissionQ.AdmittedWorkDone)
+				}
+			}()
+		}
+	}
+
 	const opName = "flow"
 	var sp opentracing.Span
 	if parentSpan == nil {
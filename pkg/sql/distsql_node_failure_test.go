@@ -0,0 +1,244 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// deadNodeClientStream is a grpc.ClientStream which fails to send messages
+// once its node is considered dead.
+type deadNodeClientStream struct {
+	grpc.ClientStream
+	dead *int32
+}
+
+func (s *deadNodeClientStream) SendMsg(m interface{}) error {
+	if atomic.LoadInt32(s.dead) == 1 {
+		return status.Error(codes.Unavailable, "node is dead")
+	}
+	return s.ClientStream.SendMsg(m)
+}
+
+// Test that an AS OF SYSTEM TIME query is re-planned and run again when one of
+// the remote nodes running its flows dies in the middle of the query. The
+// retry doesn't use the dead node, and only the spans which were read by the
+// dead node are moved to other replicas. The same query without AS OF SYSTEM
+// TIME returns the error.
+func TestDistSQLRetryOnNodeFailure(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const deadNode = 3
+	var injectSetupFailure int32
+	var mu struct {
+		syncutil.Mutex
+		// spans contains the spans read by the flows set up on each remote
+		// node.
+		spans map[roachpb.NodeID][]roachpb.Span
+	}
+	resetFlows := func() map[roachpb.NodeID][]roachpb.Span {
+		mu.Lock()
+		defer mu.Unlock()
+		spans := mu.spans
+		mu.spans = make(map[roachpb.NodeID][]roachpb.Span)
+		return spans
+	}
+	resetFlows()
+
+	// The first scan of the table on the dead node blocks until the node is
+	// stopped. Once the node is dead, its streams don't send anything, so that
+	// it looks to the other nodes as if it crashed rather than shut down
+	// gracefully.
+	var blockScans, dead int32
+	var tablePrefix roachpb.Key
+	var deadNodeStopper *stop.Stopper
+	scanBlocked := make(chan struct{})
+
+	serverArgs := make(map[int]base.TestServerArgs)
+	for i := 0; i < 3; i++ {
+		args := base.TestServerArgs{
+			UseDatabase: "test",
+			Knobs: base.TestingKnobs{
+				SQLExecutor: &sql.ExecutorTestingKnobs{
+					RemoteFlowSetupError: func(nodeID roachpb.NodeID, flow *execinfrapb.FlowSpec) error {
+						if nodeID == deadNode && atomic.LoadInt32(&injectSetupFailure) == 1 {
+							return status.Error(codes.Unavailable, "injected node failure")
+						}
+						mu.Lock()
+						defer mu.Unlock()
+						for _, proc := range flow.Processors {
+							if tr := proc.Core.TableReader; tr != nil {
+								for _, span := range tr.Spans {
+									mu.spans[nodeID] = append(mu.spans[nodeID], span.Span)
+								}
+							}
+						}
+						return nil
+					},
+				},
+			},
+		}
+		if i == deadNode-1 {
+			args.Knobs.Server = &server.TestingKnobs{
+				ContextTestingKnobs: rpc.ContextTestingKnobs{
+					StreamClientInterceptor: func(string, rpc.ConnectionClass) grpc.StreamClientInterceptor {
+						return func(
+							ctx context.Context,
+							desc *grpc.StreamDesc,
+							cc *grpc.ClientConn,
+							method string,
+							streamer grpc.Streamer,
+							opts ...grpc.CallOption,
+						) (grpc.ClientStream, error) {
+							cs, err := streamer(ctx, desc, cc, method, opts...)
+							if err != nil {
+								return nil, err
+							}
+							return &deadNodeClientStream{ClientStream: cs, dead: &dead}, nil
+						}
+					},
+				},
+			}
+			args.Knobs.Store = &kvserver.StoreTestingKnobs{
+				TestingRequestFilter: func(ctx context.Context, ba roachpb.BatchRequest) *roachpb.Error {
+					if atomic.LoadInt32(&blockScans) == 0 {
+						return nil
+					}
+					for _, ru := range ba.Requests {
+						scan, ok := ru.GetInner().(*roachpb.ScanRequest)
+						if !ok || !bytes.HasPrefix(scan.Key, tablePrefix) {
+							continue
+						}
+						if atomic.CompareAndSwapInt32(&blockScans, 1, 0) {
+							close(scanBlocked)
+							<-deadNodeStopper.ShouldQuiesce()
+							return roachpb.NewError(&roachpb.NodeUnavailableError{})
+						}
+					}
+					return nil
+				},
+			}
+		}
+		serverArgs[i] = args
+	}
+	tc := serverutils.StartNewTestCluster(t, 3, /* numNodes */
+		base.TestClusterArgs{
+			ReplicationMode:   base.ReplicationManual,
+			ServerArgsPerNode: serverArgs,
+		})
+	defer tc.Stopper().Stop(context.Background())
+	deadNodeStopper = tc.Server(deadNode - 1).Stopper()
+
+	db := tc.ServerConn(0)
+	sqlutils.CreateTable(t, db, "t",
+		"num INT PRIMARY KEY",
+		30, /* numRows */
+		sqlutils.ToRowFn(sqlutils.RowIdxFn))
+	r := sqlutils.MakeSQLRunner(db)
+	// Each range has a replica on every node, and its lease on a different
+	// node.
+	r.Exec(t, `ALTER TABLE t SPLIT AT VALUES (10), (20)`)
+	r.Exec(t, fmt.Sprintf(`
+	ALTER TABLE t EXPERIMENTAL_RELOCATE VALUES
+	  (ARRAY[%[1]d, %[2]d, %[3]d], 1), (ARRAY[%[2]d, %[1]d, %[3]d], 10), (ARRAY[%[3]d, %[1]d, %[2]d], 20)
+	`,
+		tc.Server(0).GetFirstStoreID(),
+		tc.Server(1).GetFirstStoreID(),
+		tc.Server(2).GetFirstStoreID()))
+	// Ensure that the range cache is populated.
+	r.CheckQueryResults(t,
+		`SELECT lease_holder, replicas FROM [SHOW RANGES FROM TABLE t] ORDER BY start_key`,
+		[][]string{{"1", "{1,2,3}"}, {"2", "{1,2,3}"}, {"3", "{1,2,3}"}},
+	)
+	r.Exec(t, `SET distsql = always`)
+
+	// A query without AS OF SYSTEM TIME is not retried.
+	atomic.StoreInt32(&injectSetupFailure, 1)
+	r.ExpectErr(t, "injected node failure", `SELECT count(*), sum(num) FROM t`)
+	atomic.StoreInt32(&injectSetupFailure, 0)
+
+	var ts string
+	r.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts)
+	tableDesc := catalogkv.TestingGetTableDescriptor(tc.Server(0).DB(), keys.SystemSQLCodec, "test", "t")
+	tablePrefix = keys.SystemSQLCodec.TablePrefix(uint32(tableDesc.ID))
+	resetFlows()
+	atomic.StoreInt32(&blockScans, 1)
+
+	type result struct {
+		count, sum int
+		err        error
+	}
+	resultC := make(chan result, 1)
+	go func() {
+		var res result
+		res.err = db.QueryRow(
+			fmt.Sprintf(`SELECT count(*), sum(num) FROM t AS OF SYSTEM TIME %s`, ts),
+		).Scan(&res.count, &res.sum)
+		resultC <- res
+	}()
+
+	// Stop the node while its flow is reading from the table.
+	<-scanBlocked
+	firstAttempt := resetFlows()
+	if len(firstAttempt[2]) == 0 || len(firstAttempt[deadNode]) == 0 {
+		t.Fatalf("expected flows on n2 and n%d, got %v", deadNode, firstAttempt)
+	}
+	atomic.StoreInt32(&dead, 1)
+	tc.StopServer(deadNode - 1)
+
+	res := <-resultC
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if res.count != 30 || res.sum != 465 {
+		t.Fatalf("expected count 30 and sum 465, got %d and %d", res.count, res.sum)
+	}
+
+	// The dead node is not used by the retry, and the spans which were
+	// planned on the other nodes stay there.
+	retry := resetFlows()
+	if spans, ok := retry[deadNode]; ok {
+		t.Fatalf("expected no flow on the dead node, got one reading %v", spans)
+	}
+	for _, span := range firstAttempt[2] {
+		found := false
+		for _, retrySpan := range retry[2] {
+			found = found || retrySpan.Contains(span)
+		}
+		if !found {
+			t.Errorf("expected span %s to be read by n2 again, got %v", span, retry[2])
+		}
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan/replicaoracle"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlinstance"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
@@ -103,6 +104,11 @@ type DistSQLPlanner struct {
 	nodeDescs kvcoord.NodeDescStore
 	// rpcCtx is used to construct the spanResolver upon SetNodeInfo.
 	rpcCtx *rpc.Context
+
+	// sqlInstanceResolver is set on the SQL pods of secondary tenants which
+	// distribute their flows across the SQL pods of the tenant. The node IDs
+	// in the plans of these pods are SQLInstanceIDs.
+	sqlInstanceResolver sqlinstance.AddressResolver
 }
 
 // ReplicaOraclePolicy controls which policy the physical planner uses to choose
@@ -135,6 +141,7 @@ func NewDistSQLPlanner(
 	stopper *stop.Stopper,
 	isLive func(roachpb.NodeID) (bool, error),
 	nodeDialer *nodedialer.Dialer,
+	sqlInstanceResolver sqlinstance.AddressResolver,
 ) *DistSQLPlanner {
 	dsp := &DistSQLPlanner{
 		planVersion:   planVersion,
@@ -153,6 +160,7 @@ func NewDistSQLPlanner(
 		nodeDescs:             nodeDescs,
 		rpcCtx:                rpcCtx,
 		metadataTestTolerance: execinfra.NoExplain,
+		sqlInstanceResolver:   sqlInstanceResolver,
 	}
 
 	dsp.initRunners(ctx)
@@ -611,6 +619,32 @@ type PlanningCtx struct {
 	// If set, the diagram passed to saveDiagram will show the types of each
 	// stream.
 	saveDiagramShowInputTypes bool
+
+	// failedNodes contains the nodes whose flows failed during a previous
+	// attempt to run the plan (see planAndRunWithNodeFailureRetries). Ranges
+	// whose chosen replica lives on one of these nodes are planned on another
+	// replica when possible.
+	failedNodes map[roachpb.NodeID]struct{}
+
+	// rangeNodes, if set, records the node that each range was planned on. It
+	// is set when the plan may be re-created after a node failure, so that
+	// only the ranges which were planned on the failed nodes are moved, and
+	// all the other ranges stay where they were.
+	rangeNodes map[roachpb.RangeID]roachpb.NodeID
+
+	// sqlInstances caches the IDs of the SQL pods on which the flows of a
+	// secondary tenant can be planned (see sqlInstancesForPlanning).
+	sqlInstances []roachpb.NodeID
+}
+
+// markNodeFailed records that a flow on the given node failed, so that the
+// node is avoided when the plan is re-created.
+func (p *PlanningCtx) markNodeFailed(nodeID roachpb.NodeID) {
+	if p.failedNodes == nil {
+		p.failedNodes = make(map[roachpb.NodeID]struct{})
+	}
+	p.failedNodes[nodeID] = struct{}{}
+	p.NodeStatuses[nodeID] = NodeUnhealthy
 }
 
 var _ physicalplan.ExprContext = &PlanningCtx{}
@@ -796,6 +830,14 @@ func (dsp *DistSQLPlanner) PartitionSpans(
 	}
 	// nodeMap maps a nodeID to an index inside the partitions array.
 	nodeMap := make(map[roachpb.NodeID]int)
+	// The SQL pods of a secondary tenant are not colocated with the ranges, so
+	// the ranges are assigned to them in a round-robin fashion, starting with
+	// the gateway.
+	var sqlInstances []roachpb.NodeID
+	var rangeIdx int
+	if dsp.sqlInstanceResolver != nil {
+		sqlInstances = dsp.sqlInstancesForPlanning(planCtx)
+	}
 	it := planCtx.spanIter
 	for _, span := range spans {
 		// rSpan is the span we are currently partitioning.
@@ -842,6 +884,12 @@ func (dsp *DistSQLPlanner) PartitionSpans(
 			}
 
 			nodeID := replDesc.NodeID
+			if sqlInstances != nil {
+				nodeID = sqlInstances[rangeIdx%len(sqlInstances)]
+				rangeIdx++
+			} else {
+				nodeID = dsp.nodeForRange(planCtx, &desc, nodeID)
+			}
 			partitionIdx, inNodeMap := nodeMap[nodeID]
 			if !inNodeMap {
 				// This is the first time we are seeing nodeID for these spans. Check
@@ -862,6 +910,9 @@ func (dsp *DistSQLPlanner) PartitionSpans(
 					nodeMap[nodeID] = partitionIdx
 				}
 			}
+			if planCtx.rangeNodes != nil {
+				planCtx.rangeNodes[desc.RangeID] = partitions[partitionIdx].Node
+			}
 			partition := &partitions[partitionIdx]
 
 			if lastNodeID == nodeID {
@@ -886,6 +937,50 @@ func (dsp *DistSQLPlanner) PartitionSpans(
 	return partitions, nil
 }
 
+// canDistributeAcrossSQLInstances returns whether the plans of this SQL pod of
+// a secondary tenant can be distributed, which requires other live SQL pods
+// which accept the flows of this one. It is called for every plan, and only
+// reads the list of SQL pods cached by the resolver, which is refreshed in the
+// background.
+func (dsp *DistSQLPlanner) canDistributeAcrossSQLInstances(ctx context.Context) bool {
+	if dsp.sqlInstanceResolver == nil {
+		return false
+	}
+	instances, err := dsp.sqlInstanceResolver.GetAllInstances(ctx)
+	if err != nil {
+		log.VEventf(ctx, 1, "not distributing across SQL instances: %v", err)
+		return false
+	}
+	return len(instances) > 1
+}
+
+// sqlInstancesForPlanning returns the IDs of the SQL pods of this secondary
+// tenant on which flows can be planned, starting with the gateway. The SQL
+// pods which failed during a previous attempt to run the plan are omitted.
+// The result is cached in the PlanningCtx.
+func (dsp *DistSQLPlanner) sqlInstancesForPlanning(planCtx *PlanningCtx) []roachpb.NodeID {
+	if planCtx.sqlInstances != nil {
+		return planCtx.sqlInstances
+	}
+	sqlInstances := []roachpb.NodeID{dsp.gatewayNodeID}
+	instances, err := dsp.sqlInstanceResolver.GetAllInstances(planCtx.ctx)
+	if err != nil {
+		log.VEventf(planCtx.ctx, 1, "planning on the gateway only: %v", err)
+	}
+	for _, instance := range instances {
+		nodeID := roachpb.NodeID(instance.InstanceID)
+		if nodeID == dsp.gatewayNodeID {
+			continue
+		}
+		if _, failed := planCtx.failedNodes[nodeID]; failed {
+			continue
+		}
+		sqlInstances = append(sqlInstances, nodeID)
+	}
+	planCtx.sqlInstances = sqlInstances
+	return sqlInstances
+}
+
 // nodeVersionIsCompatible decides whether a particular node's DistSQL version
 // is compatible with dsp.planVersion. It uses gossip to find out the node's
 // version range.
@@ -1052,6 +1147,10 @@ func (dsp *DistSQLPlanner) getNodeIDForScan(
 	if len(spans) == 0 {
 		panic("no spans")
 	}
+	if dsp.sqlInstanceResolver != nil {
+		// The SQL pods of a secondary tenant are not colocated with the ranges.
+		return dsp.gatewayNodeID, nil
+	}
 
 	// Determine the node ID for the first range to be scanned.
 	it := planCtx.spanIter
@@ -1068,15 +1167,59 @@ func (dsp *DistSQLPlanner) getNodeIDForScan(
 		return 0, err
 	}
 
-	nodeID := replDesc.NodeID
+	desc := it.Desc()
+	nodeID := dsp.nodeForRange(planCtx, &desc, replDesc.NodeID)
 	status := dsp.CheckNodeHealthAndVersion(planCtx, nodeID)
 	if status != NodeOK {
 		log.Eventf(planCtx.ctx, "not planning on node %d: %s", nodeID, status)
-		return dsp.gatewayNodeID, nil
+		nodeID = dsp.gatewayNodeID
+	}
+	if planCtx.rangeNodes != nil {
+		planCtx.rangeNodes[desc.RangeID] = nodeID
 	}
 	return nodeID, nil
 }
 
+// nodeForRange returns the node on which to plan the given range, whose
+// replica chosen by the span resolver lives on the given node. If the plan is
+// being re-created after a node failure, a range keeps the node it was planned
+// on during the previous attempt unless that node failed, in which case the
+// range is moved to another replica.
+func (dsp *DistSQLPlanner) nodeForRange(
+	planCtx *PlanningCtx, desc *roachpb.RangeDescriptor, nodeID roachpb.NodeID,
+) roachpb.NodeID {
+	if prevNodeID, ok := planCtx.rangeNodes[desc.RangeID]; ok {
+		if _, failed := planCtx.failedNodes[prevNodeID]; !failed {
+			return prevNodeID
+		}
+	}
+	if _, failed := planCtx.failedNodes[nodeID]; failed {
+		return dsp.replicaNodeAvoidingFailures(planCtx, desc, nodeID)
+	}
+	return nodeID
+}
+
+// replicaNodeAvoidingFailures returns the node of another voting replica of
+// the given range when the replica chosen by the span resolver lives on a node
+// which failed during a previous attempt to run the plan. If no other replica
+// is on a healthy and compatible node, the failed node is returned, which the
+// callers then replace with the gateway.
+func (dsp *DistSQLPlanner) replicaNodeAvoidingFailures(
+	planCtx *PlanningCtx, desc *roachpb.RangeDescriptor, failedNodeID roachpb.NodeID,
+) roachpb.NodeID {
+	for _, repl := range desc.Replicas().Voters() {
+		if _, failed := planCtx.failedNodes[repl.NodeID]; failed {
+			continue
+		}
+		if dsp.CheckNodeHealthAndVersion(planCtx, repl.NodeID) == NodeOK {
+			log.VEventf(planCtx.ctx, 1, "planning r%d on n%d instead of failed n%d",
+				desc.RangeID, repl.NodeID, failedNodeID)
+			return repl.NodeID
+		}
+	}
+	return failedNodeID
+}
+
 // CheckNodeHealthAndVersion returns a information about a node's health and
 // compatibility. The info is also recorded in planCtx.Nodes.
 func (dsp *DistSQLPlanner) CheckNodeHealthAndVersion(
@@ -1115,28 +1258,52 @@ func (dsp *DistSQLPlanner) createTableReaders(
 		return nil, err
 	}
 
+	info := &tableReaderPlanningInfo{
+		spec:                  spec,
+		post:                  post,
+		desc:                  n.desc,
+		spans:                 n.spans,
+		reverse:               n.reverse,
+		scanVisibility:        n.colCfg.visibility,
+		parallelize:           n.parallelize,
+		estimatedRowCount:     n.estimatedRowCount,
+		reqOrdering:           n.reqOrdering,
+		cols:                  n.cols,
+		colsToTableOrdinalMap: scanNodeToTableOrdinalMap,
+		containsSystemColumns: n.containsSystemColumns,
+	}
+	if n.skipScanPrefixLen > 0 {
+		info.skipSpec = makeIndexSkipTableReaderSpec(spec, n)
+	}
+
 	p := MakePhysicalPlan(dsp.gatewayNodeID)
-	err = dsp.planTableReaders(
-		planCtx,
-		&p,
-		&tableReaderPlanningInfo{
-			spec:                  spec,
-			post:                  post,
-			desc:                  n.desc,
-			spans:                 n.spans,
-			reverse:               n.reverse,
-			scanVisibility:        n.colCfg.visibility,
-			parallelize:           n.parallelize,
-			estimatedRowCount:     n.estimatedRowCount,
-			reqOrdering:           n.reqOrdering,
-			cols:                  n.cols,
-			colsToTableOrdinalMap: scanNodeToTableOrdinalMap,
-			containsSystemColumns: n.containsSystemColumns,
-		},
-	)
+	err = dsp.planTableReaders(planCtx, &p, info)
 	return &p, err
 }
 
+// makeIndexSkipTableReaderSpec creates the spec of the IndexSkipTableReader
+// that performs the loose index scan of the given scanNode, using the fields of
+// the TableReaderSpec that was created for it. The spans are filled in by
+// planTableReaders.
+func makeIndexSkipTableReaderSpec(
+	spec *execinfrapb.TableReaderSpec, n *scanNode,
+) *execinfrapb.IndexSkipTableReaderSpec {
+	s := &execinfrapb.IndexSkipTableReaderSpec{
+		Table:             spec.Table,
+		IndexIdx:          spec.IndexIdx,
+		Visibility:        spec.Visibility,
+		Reverse:           spec.Reverse,
+		LockingStrength:   spec.LockingStrength,
+		LockingWaitPolicy: spec.LockingWaitPolicy,
+		PrefixLen:         uint32(n.skipScanPrefixLen),
+		HasSystemColumns:  spec.HasSystemColumns,
+	}
+	for i := range n.skipScanSuffixSpans {
+		s.SuffixSpans = append(s.SuffixSpans, execinfrapb.TableReaderSpan{Span: n.skipScanSuffixSpans[i]})
+	}
+	return s
+}
+
 // tableReaderPlanningInfo is a utility struct that contains the information
 // needed to perform the physical planning of table readers once the specs have
 // been created. See scanNode to get more context on some of the fields.
@@ -1153,6 +1320,10 @@ type tableReaderPlanningInfo struct {
 	cols                  []*descpb.ColumnDescriptor
 	colsToTableOrdinalMap []int
 	containsSystemColumns bool
+
+	// skipSpec, if set, is the spec of the IndexSkipTableReader that performs
+	// a loose index scan in place of the table readers.
+	skipSpec *execinfrapb.IndexSkipTableReaderSpec
 }
 
 func (dsp *DistSQLPlanner) planTableReaders(
@@ -1162,7 +1333,9 @@ func (dsp *DistSQLPlanner) planTableReaders(
 		spanPartitions []SpanPartition
 		err            error
 	)
-	if planCtx.isLocal {
+	if planCtx.isLocal || info.skipSpec != nil {
+		// Loose index scans seek from one distinct prefix to the next, so they
+		// are always planned as a single processor on the gateway.
 		spanPartitions = []SpanPartition{{dsp.gatewayNodeID, info.spans}}
 	} else if info.post.Limit == 0 {
 		// No hard limit - plan all table readers where their data live. Note
@@ -1186,6 +1359,16 @@ func (dsp *DistSQLPlanner) planTableReaders(
 
 	corePlacement := make([]physicalplan.ProcessorCorePlacement, len(spanPartitions))
 	for i, sp := range spanPartitions {
+		if info.skipSpec != nil {
+			for j := range sp.Spans {
+				info.skipSpec.Spans = append(info.skipSpec.Spans, execinfrapb.TableReaderSpan{Span: sp.Spans[j]})
+			}
+			p.TotalEstimatedScannedRows += info.estimatedRowCount
+			p.MaxEstimatedRowCount = info.estimatedRowCount
+			corePlacement[i].NodeID = sp.Node
+			corePlacement[i].Core.IndexSkipTableReader = info.skipSpec
+			continue
+		}
 		var tr *execinfrapb.TableReaderSpec
 		if i == 0 {
 			// For the first span partition, we can just directly use the spec we made
@@ -3461,13 +3644,14 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 // NewPlanningCtx returns a new PlanningCtx. When distribute is false, a
 // lightweight version PlanningCtx is returned that can be used when the caller
 // knows plans will only be run on one node. It is coerced to false on SQL
-// SQL tenants (in which case only local planning is supported), regardless of
-// the passed-in value. planner argument can be left nil.
+// tenants which can't distribute their plans across several SQL pods (in which
+// case only local planning is supported), regardless of the passed-in value.
+// planner argument can be left nil.
 func (dsp *DistSQLPlanner) NewPlanningCtx(
 	ctx context.Context, evalCtx *extendedEvalContext, planner *planner, txn *kv.Txn, distribute bool,
 ) *PlanningCtx {
-	// Tenants can not distribute plans.
-	distribute = distribute && evalCtx.Codec.ForSystemTenant()
+	distribute = distribute &&
+		(evalCtx.Codec.ForSystemTenant() || dsp.canDistributeAcrossSQLInstances(ctx))
 	planCtx := &PlanningCtx{
 		ctx:             ctx,
 		ExtendedEvalCtx: evalCtx,
@@ -44,6 +44,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/skip"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -1264,3 +1265,54 @@ func TestCheckNodeHealth(t *testing.T) {
 		})
 	}
 }
+
+// TestDistSQLAcrossTenantSQLPods verifies that the SQL pods of a secondary
+// tenant register themselves and distribute their flows across each other.
+func TestDistSQLAcrossTenantSQLPods(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	tenantID := roachpb.MakeTenantID(10)
+	pod1 := sqlutils.MakeSQLRunner(serverutils.StartTenant(t, s, base.TestTenantArgs{TenantID: tenantID}))
+	pod2 := sqlutils.MakeSQLRunner(serverutils.StartTenant(t, s, base.TestTenantArgs{
+		TenantID: tenantID,
+		Existing: true,
+	}))
+
+	// Each SQL pod is allocated its own SQLInstanceID.
+	pod2.CheckQueryResults(t,
+		`SELECT id, addr IS NOT NULL FROM system.sql_instances ORDER BY id`,
+		[][]string{{"1", "true"}, {"2", "true"}},
+	)
+
+	pod1.Exec(t, `CREATE DATABASE test`)
+	pod1.Exec(t, `CREATE TABLE test.t (k INT PRIMARY KEY)`)
+	pod1.Exec(t, `INSERT INTO test.t SELECT generate_series(1, 100)`)
+	pod1.Exec(t, `SET distsql = always`)
+
+	// Tenants can't split their ranges, so split the table from the system
+	// tenant.
+	var tableID uint32
+	pod1.QueryRow(t, `SELECT 'test.t'::regclass::oid`).Scan(&tableID)
+	splitKey := encoding.EncodeVarintAscending(
+		keys.MakeSQLCodec(tenantID).IndexPrefix(tableID, 1), 50,
+	)
+	require.NoError(t, kvDB.AdminSplit(ctx, roachpb.Key(splitKey), hlc.MaxTimestamp))
+
+	// Once the gateway has learned about the split and the other SQL pod, the
+	// ranges of the table are read by both SQL pods.
+	const query = `SELECT count(*) FROM test.t`
+	testutils.SucceedsSoon(t, func() error {
+		pod1.CheckQueryResults(t, query, [][]string{{"100"}})
+		var json string
+		pod1.QueryRow(t, `SELECT json FROM [EXPLAIN (DISTSQL) `+query+`]`).Scan(&json)
+		if exp := `"nodeNames":["1","2"]`; !strings.Contains(json, exp) {
+			return errors.Errorf("expected json to contain %s, but json is: %s", exp, json)
+		}
+		return nil
+	})
+}
@@ -19,25 +19,36 @@ import (
 )
 
 // SetupAllNodesPlanning creates a planCtx and sets up the planCtx.NodeStatuses
-// map for all nodes.
+// map for all nodes. On the SQL pods of secondary tenants, the nodes are the
+// SQL pods of the tenant.
 func (dsp *DistSQLPlanner) SetupAllNodesPlanning(
 	ctx context.Context, evalCtx *extendedEvalContext, execCfg *ExecutorConfig,
 ) (*PlanningCtx, []roachpb.NodeID, error) {
 	planCtx := dsp.NewPlanningCtx(ctx, evalCtx, nil /* planner */, nil /* txn */, true /* distribute */)
 
-	ss, err := execCfg.NodesStatusServer.OptionalNodesStatusServer(47900)
-	if err != nil {
-		return nil, nil, err
-	}
-	resp, err := ss.Nodes(ctx, &serverpb.NodesRequest{})
-	if err != nil {
-		return nil, nil, err
-	}
-	// Because we're not going through the normal pathways, we have to set up the
-	// planCtx.NodeStatuses map ourselves. CheckNodeHealthAndVersion() will
-	// populate it.
-	for _, node := range resp.Nodes {
-		_ /* NodeStatus */ = dsp.CheckNodeHealthAndVersion(planCtx, node.Desc.NodeID)
+	if !execCfg.Codec.ForSystemTenant() && dsp.sqlInstanceResolver != nil {
+		if planCtx.isLocal {
+			// There are no other SQL pods to plan on.
+			return planCtx, []roachpb.NodeID{dsp.gatewayNodeID}, nil
+		}
+		for _, nodeID := range dsp.sqlInstancesForPlanning(planCtx) {
+			_ /* NodeStatus */ = dsp.CheckNodeHealthAndVersion(planCtx, nodeID)
+		}
+	} else {
+		ss, err := execCfg.NodesStatusServer.OptionalNodesStatusServer(47900)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, err := ss.Nodes(ctx, &serverpb.NodesRequest{})
+		if err != nil {
+			return nil, nil, err
+		}
+		// Because we're not going through the normal pathways, we have to set up
+		// the planCtx.NodeStatuses map ourselves. CheckNodeHealthAndVersion()
+		// will populate it.
+		for _, node := range resp.Nodes {
+			_ /* NodeStatus */ = dsp.CheckNodeHealthAndVersion(planCtx, node.Desc.NodeID)
+		}
 	}
 	nodes := make([]roachpb.NodeID, 0, len(planCtx.NodeStatuses))
 	for nodeID := range planCtx.NodeStatuses {
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/span"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -37,6 +38,9 @@ type requestedStat struct {
 	histogramMaxBuckets int
 	name                string
 	inverted            bool
+	// expression, if set, is the expression on which the statistic is
+	// collected; columns are the columns it references.
+	expression string
 }
 
 const histogramSamples = 10000
@@ -101,8 +105,16 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 		}
 	}
 
+	// Render the expressions of the statistics on expressions after the scanned
+	// columns. The sketches on expressions are collected on the rendered
+	// columns.
+	exprCols, err := dsp.renderStatsExprs(planCtx, p, desc, &scan, reqStats)
+	if err != nil {
+		return nil, err
+	}
+
 	var sketchSpecs, invSketchSpecs []execinfrapb.SketchSpec
-	sampledColumnIDs := make([]descpb.ColumnID, len(scan.cols))
+	sampledColumnIDs := make([]descpb.ColumnID, len(p.ResultTypes))
 	for _, s := range reqStats {
 		spec := execinfrapb.SketchSpec{
 			SketchType:          execinfrapb.SketchType_HLL_PLUS_PLUS_V1,
@@ -111,6 +123,13 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 			Columns:             make([]uint32, len(s.columns)),
 			StatName:            s.name,
 		}
+		if s.expression != "" {
+			spec.Columns = []uint32{uint32(p.PlanToStreamColMap[exprCols[s.expression]])}
+			spec.Expression = s.expression
+			spec.ExpressionColumnIDs = s.columns
+			sketchSpecs = append(sketchSpecs, spec)
+			continue
+		}
 		for i, colID := range s.columns {
 			colIdx, ok := scan.colIdxMap[colID]
 			if !ok {
@@ -230,6 +249,56 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	return p, nil
 }
 
+// renderStatsExprs adds a rendering of the scanned columns followed by the
+// distinct expressions of the requested statistics to the plan, if there are
+// any. It returns the plan column of each expression.
+func (dsp *DistSQLPlanner) renderStatsExprs(
+	planCtx *PlanningCtx,
+	p *PhysicalPlan,
+	desc *tabledesc.Immutable,
+	scan *scanNode,
+	reqStats []requestedStat,
+) (map[string]int, error) {
+	var exprCols map[string]int
+	var exprs []tree.TypedExpr
+	var outTypes []*types.T
+	for _, s := range reqStats {
+		if s.expression == "" {
+			continue
+		}
+		if _, ok := exprCols[s.expression]; ok {
+			continue
+		}
+		if exprCols == nil {
+			exprCols = make(map[string]int)
+			exprs = make([]tree.TypedExpr, len(scan.cols))
+			outTypes = make([]*types.T, len(scan.cols))
+			for i, col := range scan.cols {
+				exprs[i] = tree.NewTypedOrdinalReference(i, col.Type)
+				outTypes[i] = col.Type
+			}
+		}
+		semaCtx := tree.MakeSemaContext()
+		expr, err := schemaexpr.MakeExprOnColumns(
+			planCtx.ctx, s.expression, desc, scan.cols, planCtx.EvalContext(), &semaCtx,
+		)
+		if err != nil {
+			return nil, err
+		}
+		exprCols[s.expression] = len(exprs)
+		exprs = append(exprs, expr)
+		outTypes = append(outTypes, expr.ResolvedType())
+	}
+	if exprCols == nil {
+		return nil, nil
+	}
+	if err := p.AddRendering(exprs, planCtx, p.PlanToStreamColMap, outTypes); err != nil {
+		return nil, err
+	}
+	p.PlanToStreamColMap = identityMap(p.PlanToStreamColMap, len(exprs))
+	return exprCols, nil
+}
+
 func (dsp *DistSQLPlanner) createPlanForCreateStats(
 	planCtx *PlanningCtx, job *jobs.Job,
 ) (*PhysicalPlan, error) {
@@ -248,6 +317,7 @@ func (dsp *DistSQLPlanner) createPlanForCreateStats(
 			histogramMaxBuckets: histogramMaxBuckets,
 			name:                details.Name,
 			inverted:            details.ColumnStats[i].Inverted,
+			expression:          details.ColumnStats[i].Expression,
 		}
 	}
 
@@ -16,7 +16,9 @@ import (
 	"math"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	circuit "github.com/cockroachdb/circuitbreaker"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -39,11 +41,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
-	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
@@ -217,11 +220,13 @@ func (dsp *DistSQLPlanner) setupFlows(
 			// Skip this node.
 			continue
 		}
-		if !evalCtx.Codec.ForSystemTenant() {
-			// A tenant server should never find itself distributing flows.
-			// NB: we wouldn't hit this in practice but if we did the actual
-			// error would be opaque.
-			return nil, nil, errorutil.UnsupportedWithMultiTenancy(47900)
+		if evalCtx.ExecCfg != nil {
+			if fn := evalCtx.ExecCfg.TestingKnobs.RemoteFlowSetupError; fn != nil {
+				if err := fn(nodeID, flowSpec); err != nil {
+					resultChan <- runnerResult{nodeID: nodeID, err: err}
+					continue
+				}
+			}
 		}
 		req := setupReq
 		req.Flow = *flowSpec
@@ -248,8 +253,8 @@ func (dsp *DistSQLPlanner) setupFlows(
 	// are not waiting for the flows themselves to complete.
 	for i := 0; i < len(flows)-1; i++ {
 		res := <-resultChan
-		if firstErr == nil {
-			firstErr = res.err
+		if firstErr == nil && res.err != nil {
+			firstErr = &remoteFlowSetupError{nodeID: res.nodeID, cause: res.err}
 		}
 		// TODO(radu): accumulate the flows that we failed to set up and move them
 		// into the local flow.
@@ -800,6 +805,18 @@ func (r *DistSQLReceiver) ProducerDone() {
 	r.cleanup()
 }
 
+// resetForRetry prepares the receiver to receive the results of the plan
+// again after a failed attempt to run it.
+func (r *DistSQLReceiver) resetForRetry() {
+	r.status = execinfra.NeedMoreRows
+	r.stats = topLevelQueryStats{}
+	if r.closed {
+		// The tracing cleanup has already been run by ProducerDone.
+		r.closed = false
+		r.cleanup = func() {}
+	}
+}
+
 // Types is part of the RowReceiver interface.
 func (r *DistSQLReceiver) Types() []*types.T {
 	return r.outputTypes
@@ -981,6 +998,10 @@ func (dsp *DistSQLPlanner) PlanAndRun(
 ) (cleanup func()) {
 	log.VEventf(ctx, 1, "creating DistSQL plan with isLocal=%v", planCtx.isLocal)
 
+	if dsp.canRetryOnNodeFailure(planCtx, plan, recv) {
+		return dsp.planAndRunWithNodeFailureRetries(ctx, evalCtx, planCtx, txn, plan, recv)
+	}
+
 	physPlan, err := dsp.createPhysPlan(planCtx, plan)
 	if err != nil {
 		recv.SetError(err)
@@ -991,6 +1012,225 @@ func (dsp *DistSQLPlanner) PlanAndRun(
 	return dsp.Run(planCtx, txn, physPlan, recv, evalCtx, nil /* finishedSetupFn */)
 }
 
+// maxNodeFailureRetries is the maximum number of times a distributed plan is
+// re-planned and re-run after one of the nodes participating in it fails.
+const maxNodeFailureRetries = 3
+
+// canRetryOnNodeFailure returns whether the plan can be transparently
+// re-planned and re-run if one of the remote nodes running its flows fails.
+// This is only the case for idempotent reads of historical data (AS OF SYSTEM
+// TIME queries) whose results are streamed to the client, since running such
+// a query again produces the same results.
+func (dsp *DistSQLPlanner) canRetryOnNodeFailure(
+	planCtx *PlanningCtx, plan planMaybePhysical, recv *DistSQLReceiver,
+) bool {
+	if planCtx.isLocal || plan.isPhysicalPlan() || planCtx.planner == nil {
+		return false
+	}
+	if recv.stmtType != tree.Rows {
+		return false
+	}
+	if _, ok := recv.resultWriter.(MetadataResultWriter); ok {
+		return false
+	}
+	p := planCtx.planner
+	if p.semaCtx.AsOfTimestamp == nil || p.stmt == nil || p.stmt.AST == nil {
+		return false
+	}
+	return !tree.CanWriteData(p.stmt.AST) && !tree.CanModifySchema(p.stmt.AST)
+}
+
+// planAndRunWithNodeFailureRetries is like PlanAndRun, but if the plan fails
+// because one of the remote nodes running its flows went away before any
+// rows were sent to the client, the failed nodes are excluded and the plan is
+// re-created and run again. Only the spans that were assigned to the failed
+// nodes move to other replicas: the ranges on the other nodes keep their
+// assignments (see PlanningCtx.rangeNodes).
+func (dsp *DistSQLPlanner) planAndRunWithNodeFailureRetries(
+	ctx context.Context,
+	evalCtx *extendedEvalContext,
+	planCtx *PlanningCtx,
+	txn *kv.Txn,
+	plan planMaybePhysical,
+	recv *DistSQLReceiver,
+) (cleanup func()) {
+	// The plan must stay open across the attempts, so we take over closing it
+	// from Run.
+	ignoreClose := planCtx.ignoreClose
+	planCtx.ignoreClose = true
+	defer func() { planCtx.ignoreClose = ignoreClose }()
+
+	// Errors are held back from the real result writer until we know whether
+	// the plan will be retried.
+	resultWriter := recv.resultWriter
+	w := &nodeFailureResultWriter{rowResultWriter: resultWriter}
+	recv.resultWriter = w
+	defer func() { recv.resultWriter = resultWriter }()
+
+	// Setting up the gateway flow replaces the memory monitor of evalCtx with
+	// the monitor of the flow, which is stopped when the flow is cleaned up, so
+	// every attempt needs to start from the original monitor.
+	monitor := evalCtx.Mon
+	planCtx.rangeNodes = make(map[roachpb.RangeID]roachpb.NodeID)
+	flowCleanup := func() {}
+	for attempt := 0; ; attempt++ {
+		physPlan, err := dsp.createPhysPlan(planCtx, plan)
+		if err != nil {
+			w.SetError(err)
+			break
+		}
+		dsp.FinalizePlan(planCtx, physPlan)
+		recv.expectedRowsRead = int64(physPlan.TotalEstimatedScannedRows)
+		flowCleanup = dsp.Run(planCtx, txn, physPlan, recv, evalCtx, nil /* finishedSetupFn */)
+
+		if w.err == nil || w.rowsSent || recv.commErr != nil || attempt == maxNodeFailureRetries {
+			break
+		}
+		// Plans with local processors wrap planNodes which can't be run twice.
+		if len(physPlan.LocalProcessors) > 0 || ctx.Err() != nil || !isNodeFailureError(w.err) {
+			break
+		}
+		failedNodes := dsp.findFailedNodes(ctx, physPlan, w.err)
+		if len(failedNodes) == 0 {
+			break
+		}
+		log.VEventf(ctx, 1, "re-planning after failure of nodes %v: %v", failedNodes, w.err)
+		telemetry.Inc(sqltelemetry.DistSQLNodeFailureRetryCounter)
+		flowCleanup()
+		flowCleanup = func() {}
+		evalCtx.Mon = monitor
+		for _, nodeID := range failedNodes {
+			planCtx.markNodeFailed(nodeID)
+		}
+		w.err = nil
+		recv.resetForRetry()
+	}
+	if w.err != nil {
+		resultWriter.SetError(w.err)
+	}
+
+	if planCtx.planner != nil && !ignoreClose {
+		// See the comment at the end of Run.
+		curPlan := &planCtx.planner.curPlan
+		return func() {
+			curPlan.execErr = resultWriter.Err()
+			curPlan.close(ctx)
+			flowCleanup()
+		}
+	}
+	return flowCleanup
+}
+
+// nodeFailureResultWriter is a rowResultWriter used while a plan can still be
+// retried after a node failure. It keeps the errors to itself and tracks
+// whether any results have been passed on to the wrapped writer.
+type nodeFailureResultWriter struct {
+	rowResultWriter
+	err      error
+	rowsSent bool
+}
+
+var _ rowResultWriter = &nodeFailureResultWriter{}
+
+// AddRow is part of the rowResultWriter interface.
+func (w *nodeFailureResultWriter) AddRow(ctx context.Context, row tree.Datums) error {
+	w.rowsSent = true
+	return w.rowResultWriter.AddRow(ctx, row)
+}
+
+// IncrementRowsAffected is part of the rowResultWriter interface.
+func (w *nodeFailureResultWriter) IncrementRowsAffected(n int) {
+	w.rowsSent = true
+	w.rowResultWriter.IncrementRowsAffected(n)
+}
+
+// SetError is part of the rowResultWriter interface.
+func (w *nodeFailureResultWriter) SetError(err error) {
+	w.err = err
+}
+
+// Err is part of the rowResultWriter interface.
+func (w *nodeFailureResultWriter) Err() error {
+	return w.err
+}
+
+// remoteFlowSetupError is returned by setupFlows when a flow could not be set
+// up on a remote node.
+type remoteFlowSetupError struct {
+	nodeID roachpb.NodeID
+	cause  error
+}
+
+func (e *remoteFlowSetupError) Error() string {
+	return fmt.Sprintf("setting up flow on n%d: %v", e.nodeID, e.cause)
+}
+
+// Cause implements the causer interface.
+func (e *remoteFlowSetupError) Cause() error { return e.cause }
+
+// Unwrap implements the wrapper interface.
+func (e *remoteFlowSetupError) Unwrap() error { return e.cause }
+
+// isNodeFailureError returns whether the error indicates that a node running
+// one of the flows of a plan became unavailable. A node that goes away in the
+// middle of the query breaks the streams it was sending results on, which the
+// inbound stream handlers report as communication errors.
+func isNodeFailureError(err error) bool {
+	return grpcutil.IsClosedConnection(err) ||
+		errors.Is(err, circuit.ErrBreakerOpen) ||
+		flowinfra.IsFlowRetryableError(err) ||
+		flowinfra.IsNoInboundStreamConnectionError(err) ||
+		pgerror.GetPGCode(err) == pgcode.InternalConnectionFailure
+}
+
+// findFailedNodesRetryOptions bounds how long findFailedNodes waits for a node
+// that went away to be reported as unhealthy. The flows on the other nodes
+// usually notice that a node went away before its connections are known to
+// be unhealthy or its liveness record expires.
+var findFailedNodesRetryOptions = retry.Options{
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	MaxRetries:     10,
+}
+
+// findFailedNodes returns the nodes that caused the given error when running
+// the plan. If a remote flow couldn't be set up, that is the node the flow was
+// sent to; otherwise, the remote nodes participating in the plan are checked
+// for health until at least one of them is found to be unhealthy.
+func (dsp *DistSQLPlanner) findFailedNodes(
+	ctx context.Context, plan *PhysicalPlan, err error,
+) []roachpb.NodeID {
+	if setupErr := (*remoteFlowSetupError)(nil); errors.As(err, &setupErr) {
+		return []roachpb.NodeID{setupErr.nodeID}
+	}
+	var remoteNodes []roachpb.NodeID
+	seen := make(map[roachpb.NodeID]struct{})
+	for i := range plan.Processors {
+		nodeID := plan.Processors[i].Node
+		if _, ok := seen[nodeID]; ok || nodeID == dsp.gatewayNodeID {
+			continue
+		}
+		seen[nodeID] = struct{}{}
+		remoteNodes = append(remoteNodes, nodeID)
+	}
+	if len(remoteNodes) == 0 {
+		return nil
+	}
+	for r := retry.StartWithCtx(ctx, findFailedNodesRetryOptions); r.Next(); {
+		var failedNodes []roachpb.NodeID
+		for _, nodeID := range remoteNodes {
+			if dsp.nodeHealth.check(ctx, nodeID) != nil {
+				failedNodes = append(failedNodes, nodeID)
+			}
+		}
+		if len(failedNodes) > 0 {
+			return failedNodes
+		}
+	}
+	return nil
+}
+
 // PlanAndRunCascadesAndChecks runs any cascade and check queries.
 //
 // Because cascades can themselves generate more cascades or check queries, this
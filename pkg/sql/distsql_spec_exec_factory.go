@@ -43,7 +43,7 @@ type distSQLSpecExecFactory struct {
 		// localPlanCtx stores the local planning context of the gateway.
 		localPlanCtx *PlanningCtx
 	}
-	singleTenant  bool
+	canDistribute bool
 	planningMode  distSQLPlanningMode
 	gatewayNodeID roachpb.NodeID
 }
@@ -65,18 +65,21 @@ const (
 )
 
 func newDistSQLSpecExecFactory(p *planner, planningMode distSQLPlanningMode) exec.Factory {
+	dsp := p.extendedEvalCtx.DistSQLPlanner
+	canDistribute := p.execCfg.Codec.ForSystemTenant() ||
+		dsp.canDistributeAcrossSQLInstances(p.EvalContext().Context)
 	return &distSQLSpecExecFactory{
 		planner:       p,
-		dsp:           p.extendedEvalCtx.DistSQLPlanner,
-		singleTenant:  p.execCfg.Codec.ForSystemTenant(),
+		dsp:           dsp,
+		canDistribute: canDistribute,
 		planningMode:  planningMode,
-		gatewayNodeID: p.extendedEvalCtx.DistSQLPlanner.gatewayNodeID,
+		gatewayNodeID: dsp.gatewayNodeID,
 	}
 }
 
 func (e *distSQLSpecExecFactory) getPlanCtx(recommendation distRecommendation) *PlanningCtx {
 	distribute := false
-	if e.singleTenant && e.planningMode != distSQLLocalOnlyPlanning {
+	if e.canDistribute && e.planningMode != distSQLLocalOnlyPlanning {
 		distribute = shouldDistributeGivenRecAndMode(recommendation, e.planner.extendedEvalCtx.SessionData.DistSQLMode)
 	}
 	if distribute {
@@ -170,6 +173,10 @@ func (e *distSQLSpecExecFactory) ConstructScan(
 		)
 	}
 
+	if params.SkipScanPrefixLen > 0 {
+		return nil, unimplemented.NewWithIssue(47473, "experimental opt-driven distsql planning: skip scan")
+	}
+
 	p := MakePhysicalPlan(e.gatewayNodeID)
 	// Although we don't yet recommend distributing plans where soft limits
 	// propagate to scan nodes because we don't have infrastructure to only
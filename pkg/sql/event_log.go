@@ -15,13 +15,20 @@ import (
 	"encoding/json"
 
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
 
 // EventLogType represents an event type that can be recorded in the event log.
 type EventLogType string
 
+// The value of each constant is the name of the corresponding event
+// payload in the eventpb package, as returned by
+// eventpb.GetEventTypeName.
+//
 // NOTE: When you add a new event type here. Please manually add it to
 // pkg/ui/src/util/eventTypes.ts so that it will be recognized in the UI.
 const (
@@ -113,42 +120,62 @@ const (
 	// EventLogCreateStatistics is recorded when statistics are collected for a
 	// table.
 	EventLogCreateStatistics EventLogType = "create_statistics"
-)
 
-// EventLogSetClusterSettingDetail is the json details for a settings change.
-type EventLogSetClusterSettingDetail struct {
-	SettingName string
-	Value       string
-	User        string
-}
+	// EventLogCreateRole is recorded when a role is created.
+	EventLogCreateRole EventLogType = "create_role"
+	// EventLogDropRole is recorded when a role is dropped.
+	EventLogDropRole EventLogType = "drop_role"
+	// EventLogAlterRole is recorded when a role is altered.
+	EventLogAlterRole EventLogType = "alter_role"
+	// EventLogGrantRole is recorded when role memberships are granted.
+	EventLogGrantRole EventLogType = "grant_role"
+	// EventLogRevokeRole is recorded when role memberships are revoked.
+	EventLogRevokeRole EventLogType = "revoke_role"
+
+	// EventLogJobStateChange is recorded when a job changes status.
+	EventLogJobStateChange EventLogType = "job_state_change"
+)
 
 // An EventLogger exposes methods used to record events to the event table.
 type EventLogger struct {
 	*InternalExecutor
+
+	// channel, if set, is the logger on which the structured events are
+	// emitted once the transaction that recorded them commits.
+	channel *log.SecondaryLogger
 }
 
 // MakeEventLogger constructs a new EventLogger.
 func MakeEventLogger(execCfg *ExecutorConfig) EventLogger {
-	return EventLogger{InternalExecutor: execCfg.InternalExecutor}
+	return EventLogger{
+		InternalExecutor: execCfg.InternalExecutor,
+		channel:          execCfg.StructuredEventLogger,
+	}
 }
 
 // InsertEventRecord inserts a single event into the event log as part of the
-// provided transaction.
+// provided transaction. The event type and timestamp of the event are
+// populated from the type of the payload and the transaction's timestamp.
 func (ev EventLogger) InsertEventRecord(
-	ctx context.Context,
-	txn *kv.Txn,
-	eventType EventLogType,
-	targetID, reportingID int32,
-	info interface{},
+	ctx context.Context, txn *kv.Txn, targetID, reportingID int32, event eventpb.EventPayload,
 ) error {
-	// Record event record insertion in local log output.
+	common := event.CommonDetails()
+	common.EventType = eventpb.GetEventTypeName(event)
+	common.Timestamp = txn.ReadTimestamp().WallTime
+
+	infoBytes, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// Emit the event on the eventlog channel (or the main log if the
+	// channel is not configured) once the transaction commits.
 	txn.AddCommitTrigger(func(ctx context.Context) {
-		log.Infof(
-			ctx, "Event: %q, target: %d, info: %+v",
-			eventType,
-			targetID,
-			info,
-		)
+		if ev.channel != nil {
+			ev.channel.StructuredEvent(ctx, event)
+			return
+		}
+		log.Infof(ctx, "Event: %q, target: %d, info: %s", common.EventType, targetID, infoBytes)
 	})
 
 	const insertEventTableStmt = `
@@ -156,23 +183,16 @@ INSERT INTO system.eventlog (
   timestamp, "eventType", "targetID", "reportingID", info
 )
 VALUES(
-  now(), $1, $2, $3, $4
+  $1, $2, $3, $4, $5
 )
 `
-	args := []interface{}{
-		eventType,
+	rows, err := ev.Exec(ctx, "log-event", txn, insertEventTableStmt,
+		timeutil.Unix(0, common.Timestamp),
+		common.EventType,
 		targetID,
 		reportingID,
-		nil, // info
-	}
-	if info != nil {
-		infoBytes, err := json.Marshal(info)
-		if err != nil {
-			return err
-		}
-		args[3] = string(infoBytes)
-	}
-	rows, err := ev.Exec(ctx, "log-event", txn, insertEventTableStmt, args...)
+		string(infoBytes),
+	)
 	if err != nil {
 		return err
 	}
@@ -181,3 +201,20 @@ VALUES(
 	}
 	return nil
 }
+
+// logEvent records an event triggered by the current SQL statement into
+// the event log. The common SQL details of the event that are not yet
+// populated are filled in from the current session.
+func (p *planner) logEvent(
+	ctx context.Context, descID descpb.ID, event eventpb.EventWithCommonSQLPayload,
+) error {
+	sqlCommon := event.CommonSQLDetails()
+	if sqlCommon.User == "" {
+		sqlCommon.User = p.User()
+	}
+	sqlCommon.ApplicationName = p.SessionData().ApplicationName
+	sqlCommon.DescriptorID = uint32(descID)
+	return MakeEventLogger(p.ExecCfg()).InsertEventRecord(
+		ctx, p.txn, int32(descID), int32(p.extendedEvalCtx.NodeID.SQLInstanceID()), event,
+	)
+}
@@ -14,13 +14,17 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleaudit"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
@@ -80,6 +84,11 @@ import (
 //    message upon error). Needed for auditing and troubleshooting.
 //  - the number of times the statement was retried automatically
 //    by the server so far.
+//
+// The statements selected by a role-based audit policy (see package
+// roleaudit) are reported to the audit log as structured events
+// (eventpb.RoleBasedAuditEvent) instead, which additionally include the
+// session ID, the client address and the policy that selected them.
 
 // logStatementsExecuteEnabled causes the Executor to log executed
 // statements and, if any, resulting errors.
@@ -155,8 +164,9 @@ func (p *planner) maybeLogStatementInternal(
 	slowQueryLogEnabled := slowLogThreshold != 0
 	slowInternalQueryLogEnabled := slowInternalQueryLogEnabled.Get(&p.execCfg.Settings.SV)
 	auditEventsDetected := len(p.curPlan.auditEvents) != 0
+	roleAuditDetected := p.curPlan.roleAudit != nil
 
-	if !logV && !logExecuteEnabled && !auditEventsDetected && !slowQueryLogEnabled {
+	if !logV && !logExecuteEnabled && !auditEventsDetected && !roleAuditDetected && !slowQueryLogEnabled {
 		return
 	}
 
@@ -207,6 +217,9 @@ func (p *planner) maybeLogStatementInternal(
 		logger.Logf(ctx, "%s %q %s %q %s %.3f %d %s %d",
 			lbl, appName, logTrigger, stmtStr, plStr, age, rows, auditErrStr, numRetries)
 	}
+	if roleAuditDetected {
+		p.logRoleAuditEvent(ctx, lbl, stmtStr, plStr, age, rows, err, numRetries)
+	}
 	if slowQueryLogEnabled && (queryDuration > slowLogThreshold || slowLogFullTableScans) {
 		logReason, shouldLog := p.slowQueryLogReason(queryDuration, slowLogThreshold)
 
@@ -263,6 +276,81 @@ func (p *planner) maybeAudit(desc catalog.Descriptor, priv privilege.Kind) {
 	}
 }
 
+// maybeAuditRole marks the current plan for auditing if the user
+// executing it, or one of the roles it is a member of, is subject to a
+// role-based audit policy. This is later picked up by
+// maybeLogStatement() above.
+//
+// It must be called while the transaction is open, so that the role
+// memberships can be looked up.
+func (p *planner) maybeAuditRole(ctx context.Context) {
+	registry := p.execCfg.RoleAuditRegistry
+	if registry == nil || !registry.HasPolicies() {
+		return
+	}
+	user := p.User()
+	roles := []string{user}
+	memberOf, err := p.MemberOfWithAdminOption(ctx, user)
+	if err != nil {
+		// The policies are still matched against the user itself: we'd
+		// rather audit too little than fail the statement.
+		log.Warningf(ctx, "unable to look up the roles of %s for auditing: %v", user, err)
+	}
+	for role := range memberOf {
+		roles = append(roles, role)
+	}
+	// The user comes first, then its roles in a deterministic order.
+	sort.Strings(roles[1:])
+	if policy, role, ok := registry.Match(p.stmt.AST, roles); ok {
+		p.curPlan.roleAudit = &roleAuditMatch{policy: policy, role: role}
+	}
+}
+
+// logRoleAuditEvent reports the current statement to the audit log on
+// behalf of the role-based audit policy that matched it.
+func (p *planner) logRoleAuditEvent(
+	ctx context.Context,
+	lbl string,
+	stmtStr string,
+	plStr string,
+	age float64,
+	rows int,
+	err error,
+	numRetries int,
+) {
+	sd := p.SessionData()
+	ev := &eventpb.RoleBasedAuditEvent{
+		CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+			Statement:       stmtStr,
+			User:            sd.User,
+			ApplicationName: sd.ApplicationName,
+		},
+		CommonSQLExecDetails: eventpb.CommonSQLExecDetails{
+			ExecMode:   lbl,
+			NumRows:    uint64(rows),
+			Age:        float32(age),
+			NumRetries: uint32(numRetries),
+		},
+		Role:        p.curPlan.roleAudit.role,
+		RolePattern: p.curPlan.roleAudit.policy.RolePattern,
+		Statements:  p.curPlan.roleAudit.policy.Statements.String(),
+		SessionID:   p.extendedEvalCtx.SessionID.String(),
+	}
+	if plStr != "{}" {
+		ev.Placeholders = plStr
+	}
+	if err != nil {
+		ev.SQLSTATE = pgerror.GetPGCode(err).String()
+		ev.ErrorText = err.Error()
+	}
+	if sd.RemoteAddr != nil {
+		ev.ClientAddress = sd.RemoteAddr.String()
+	}
+	ev.Timestamp = timeutil.Now().UnixNano()
+	ev.EventType = eventpb.GetEventTypeName(ev)
+	p.execCfg.AuditLogger.StructuredEvent(ctx, ev)
+}
+
 func (p *planner) slowQueryLogReason(
 	queryDuration time.Duration, slowLogThreshold time.Duration,
 ) (reason string, shouldLog bool) {
@@ -291,3 +379,12 @@ type auditEvent struct {
 	// Whether the event was for INSERT/DELETE/UPDATE.
 	writing bool
 }
+
+// roleAuditMatch records the role-based audit policy which applies to
+// the current statement.
+type roleAuditMatch struct {
+	policy roleaudit.Policy
+	// The role matched by the policy: either the user executing the
+	// statement or one of the roles it is a member of.
+	role string
+}
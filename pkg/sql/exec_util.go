@@ -49,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/colexec"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsql"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
@@ -56,7 +57,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/planhints"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleaudit"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
@@ -189,6 +192,23 @@ var zigzagJoinClusterMode = settings.RegisterBoolSetting(
 	true,
 )
 
+var eagerAggregationClusterMode = settings.RegisterBoolSetting(
+	"sql.defaults.eager_aggregation.enabled",
+	"default value for enable_eager_aggregation session setting; allows the optimizer to push aggregations below joins by default",
+	false,
+)
+
+var planCacheClusterMode = settings.RegisterEnumSetting(
+	"sql.defaults.plan_cache_mode",
+	"default value for plan_cache_mode session setting; controls whether prepared statements use custom or generic plans by default",
+	"force_custom_plan",
+	map[int64]string{
+		int64(sessiondata.PlanCacheModeForceCustom):  "force_custom_plan",
+		int64(sessiondata.PlanCacheModeForceGeneric): "force_generic_plan",
+		int64(sessiondata.PlanCacheModeAuto):         "auto",
+	},
+)
+
 var optDrivenFKCascadesClusterLimit = settings.RegisterNonNegativeIntSetting(
 	"sql.defaults.foreign_key_cascades_limit",
 	"default value for foreign_key_cascades_limit session setting; limits the number of cascading operations that run as part of a single query",
@@ -667,8 +687,11 @@ type ExecutorConfig struct {
 	SlowQueryLogger         *log.SecondaryLogger
 	SlowInternalQueryLogger *log.SecondaryLogger
 	AuthLogger              *log.SecondaryLogger
-	InternalExecutor        *InternalExecutor
-	QueryCache              *querycache.C
+	// StructuredEventLogger is the logger on which the events recorded in
+	// system.eventlog are also emitted.
+	StructuredEventLogger *log.SecondaryLogger
+	InternalExecutor      *InternalExecutor
+	QueryCache            *querycache.C
 
 	TestingKnobs                  ExecutorTestingKnobs
 	PGWireTestingKnobs            *PGWireTestingKnobs
@@ -695,6 +718,12 @@ type ExecutorConfig struct {
 	// StmtDiagnosticsRecorder deals with recording statement diagnostics.
 	StmtDiagnosticsRecorder *stmtdiagnostics.Registry
 
+	// RoleAuditRegistry holds the role-based SQL audit logging policies.
+	RoleAuditRegistry *roleaudit.Registry
+
+	// PlanBaselineRegistry holds the plan baselines.
+	PlanBaselineRegistry *planhints.Registry
+
 	ExternalIODirConfig base.ExternalIODirConfig
 
 	// HydratedTables is a node-level cache of table descriptors which utilize
@@ -719,9 +748,14 @@ type StmtDiagnosticsRecorder interface {
 	// If data is to be collected, the returned finish() function must always be
 	// called once the data was collected. If collection fails, it can be called
 	// with a collectionErr.
+	//
+	// If minExecutionLatency is non-zero, the request is conditional: the data
+	// must only be collected, and finish() called, if the execution of the
+	// statement took at least minExecutionLatency.
 	ShouldCollectDiagnostics(ctx context.Context, ast tree.Statement) (
 		shouldCollect bool,
 		finish StmtDiagnosticsTraceFinishFunc,
+		minExecutionLatency time.Duration,
 	)
 
 	// InsertStatementDiagnostics inserts a trace into system.statement_diagnostics.
@@ -813,6 +847,16 @@ type ExecutorTestingKnobs struct {
 	// RunAfterSCJobsCacheLookup is called after the SchemaChangeJobCache is checked for
 	// a given table id.
 	RunAfterSCJobsCacheLookup func(*jobs.Job)
+
+	// RemoteFlowSetupError, if set, is called before a flow is set up on a
+	// remote node. If it returns an error, the flow is not set up and the error
+	// is reported as if the SetupFlow RPC to that node had failed.
+	RemoteFlowSetupError func(nodeID roachpb.NodeID, flow *execinfrapb.FlowSpec) error
+
+	// BeforeSQLStatsPersist, if set, is called before the SQL statistics
+	// drained from memory are written to the system tables. If it returns an
+	// error, the flush fails with that error.
+	BeforeSQLStatsPersist func(ctx context.Context) error
 }
 
 // PGWireTestingKnobs contains knobs for the pgwire module.
@@ -957,7 +1001,8 @@ func getPlanDistribution(
 		return physicalplan.LocalPlan
 	}
 
-	if _, singleTenant := nodeID.OptionalNodeID(); !singleTenant {
+	if _, singleTenant := nodeID.OptionalNodeID(); !singleTenant &&
+		!p.extendedEvalCtx.DistSQLPlanner.canDistributeAcrossSQLInstances(ctx) {
 		return physicalplan.LocalPlan
 	}
 	if distSQLMode == sessiondata.DistSQLOff {
@@ -2075,6 +2120,14 @@ func (m *sessionDataMutator) SetZigzagJoinEnabled(val bool) {
 	m.data.ZigzagJoinEnabled = val
 }
 
+func (m *sessionDataMutator) SetEagerAggregationEnabled(val bool) {
+	m.data.EagerAggregationEnabled = val
+}
+
+func (m *sessionDataMutator) SetPlanCacheMode(val sessiondata.PlanCacheMode) {
+	m.data.PlanCacheMode = val
+}
+
 func (m *sessionDataMutator) SetExperimentalDistSQLPlanning(
 	val sessiondata.ExperimentalDistSQLPlanningMode,
 ) {
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/storage/cloud"
 	"github.com/cockroachdb/cockroach/pkg/storage/fs"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -133,6 +134,10 @@ type ServerConfig struct {
 	// AdminVerifyProtectedTimestampRequest.
 	ProtectedTimestampProvider protectedts.Provider
 
+	// SQLFlowAdmissionQ, if set, admits the execution of flows set up on this
+	// node. It is not set for SQL tenant pods.
+	SQLFlowAdmissionQ *admission.WorkQueue
+
 	// RangeCache is used by processors that were supposed to have been planned on
 	// the leaseholders of the data ranges that they're consuming. These
 	// processors query the cache to see if they should communicate updates to the
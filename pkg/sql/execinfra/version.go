@@ -39,7 +39,7 @@ import "github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
 //
 // ATTENTION: When updating these fields, add a brief description of what
 // changed to the version history below.
-const Version execinfrapb.DistSQLVersion = 37
+const Version execinfrapb.DistSQLVersion = 38
 
 // MinAcceptedVersion is the oldest version that the server is compatible with.
 // A server will not accept flows with older versions.
@@ -51,6 +51,11 @@ const MinAcceptedVersion execinfrapb.DistSQLVersion = 37
 
 Please add new entries at the top.
 
+- Version: 38 (MinAcceptedVersion: 37)
+    - Added the IndexSkipTableReader processor core and the prefix_len,
+      suffix_spans and has_system_columns fields to its spec. The processor
+      is only planned on the gateway, so the change is backwards compatible.
+
 - Version: 37 (MinAcceptedVersion: 37)
   - An InterleavedReaderJoiner processor was removed, and the old processor
     spec would be unrecognized by a server running older versions, hence the
@@ -154,6 +154,18 @@ func (tr *TableReaderSpec) summary() (string, []string) {
 	return "TableReader", details
 }
 
+// summary implements the diagramCellType interface.
+func (s *IndexSkipTableReaderSpec) summary() (string, []string) {
+	details := []string{
+		indexDetail(&s.Table, s.IndexIdx),
+		fmt.Sprintf("Skip prefix: %d", s.PrefixLen),
+	}
+	if len(s.SuffixSpans) > 0 {
+		details = append(details, fmt.Sprintf("Suffix spans: %d", len(s.SuffixSpans)))
+	}
+	return "IndexSkipTableReader", details
+}
+
 // summary implements the diagramCellType interface.
 func (jr *JoinReaderSpec) summary() (string, []string) {
 	details := make([]string, 0, 4)
@@ -114,6 +114,9 @@ type Flow interface {
 	// mailboxes exited).
 	Cleanup(context.Context)
 
+	// AddOnCleanup adds a callback to be executed at the very end of Cleanup.
+	AddOnCleanup(fn func())
+
 	// ConcurrentTxnUse returns true if multiple processors/operators in the flow
 	// will execute concurrently (i.e. if not all of them have been fused) and
 	// more than one goroutine will be using a txn.
@@ -160,6 +163,9 @@ type FlowBase struct {
 
 	doneFn func()
 
+	// onCleanup are the callbacks executed at the end of Cleanup.
+	onCleanup []func()
+
 	status flowStatus
 
 	// Cancel function for ctx. Call this to cancel the flow (safe to be called
@@ -230,6 +236,11 @@ func (f *FlowBase) AddStartable(s Startable) {
 	f.startables = append(f.startables, s)
 }
 
+// AddOnCleanup is part of the Flow interface.
+func (f *FlowBase) AddOnCleanup(fn func()) {
+	f.onCleanup = append(f.onCleanup, fn)
+}
+
 // GetID is part of the Flow interface.
 func (f *FlowBase) GetID() execinfrapb.FlowID {
 	return f.ID
@@ -457,6 +468,9 @@ func (f *FlowBase) Cleanup(ctx context.Context) {
 	if sp != nil {
 		sp.Finish()
 	}
+	for _, fn := range f.onCleanup {
+		fn()
+	}
 }
 
 // cancel iterates through all unconnected streams of this flow and marks them canceled.
@@ -174,6 +174,13 @@ func IsFlowRetryableError(e error) bool {
 	return errors.HasType(e, (*flowRetryableError)(nil))
 }
 
+// IsNoInboundStreamConnectionError returns true if an error indicates that a
+// flow timed out waiting for one of its inbound streams to be connected, which
+// usually means that the node on the other end of the stream went away.
+func IsNoInboundStreamConnectionError(e error) bool {
+	return errors.Is(e, errNoInboundStreamConnection)
+}
+
 // RegisterFlow makes a flow accessible to ConnectInboundStream. Any concurrent
 // ConnectInboundStream calls that are waiting for this flow are woken up.
 //
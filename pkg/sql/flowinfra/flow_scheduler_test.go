@@ -96,6 +96,10 @@ func (m *mockFlow) GetID() execinfrapb.FlowID {
 
 func (m *mockFlow) Cleanup(_ context.Context) {}
 
+func (m *mockFlow) AddOnCleanup(_ func()) {
+	panic("not implemented")
+}
+
 func (m *mockFlow) ConcurrentTxnUse() bool {
 	return false
 }
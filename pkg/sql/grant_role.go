@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 )
@@ -193,7 +194,13 @@ func (n *GrantRoleNode) startExec(params runParams) error {
 
 	n.run.rowsAffected += rowsAffected
 
-	return nil
+	return params.p.logEvent(params.ctx,
+		0, /* no target */
+		&eventpb.GrantRole{
+			RoleNames:   n.roles.ToStrings(),
+			Members:     n.members.ToStrings(),
+			AdminOption: n.adminOption,
+		})
 }
 
 // Next implements the planNode interface.
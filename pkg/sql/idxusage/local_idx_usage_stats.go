@@ -0,0 +1,174 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package idxusage collects the read statistics of the indexes used by the
+// statements executed on a node.
+package idxusage
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Enable determines whether index usage statistics are collected.
+var Enable = settings.RegisterPublicBoolSetting(
+	"sql.metrics.index_usage_stats.enabled", "collect per-index read statistics", true,
+)
+
+// IndexKey uniquely identifies an index.
+type IndexKey struct {
+	TableID descpb.ID
+	IndexID descpb.IndexID
+}
+
+// indexStats is the read activity of a single index. Its fields are accessed
+// atomically.
+type indexStats struct {
+	totalReadCount uint64
+	// lastRead is the time of the last read in nanoseconds since the Unix
+	// epoch.
+	lastRead int64
+}
+
+// recordRead records a read of the index at the given time.
+func (is *indexStats) recordRead(now int64) {
+	atomic.AddUint64(&is.totalReadCount, 1)
+	for {
+		lastRead := atomic.LoadInt64(&is.lastRead)
+		if lastRead >= now || atomic.CompareAndSwapInt64(&is.lastRead, lastRead, now) {
+			return
+		}
+	}
+}
+
+// toProto returns the statistics of the index identified by key.
+func (is *indexStats) toProto(key IndexKey) serverpb.IndexUsageStatistics {
+	res := serverpb.IndexUsageStatistics{
+		TableID:        key.TableID,
+		IndexID:        key.IndexID,
+		TotalReadCount: atomic.LoadUint64(&is.totalReadCount),
+	}
+	if lastRead := atomic.LoadInt64(&is.lastRead); lastRead != 0 {
+		res.LastRead = timeutil.Unix(0, lastRead)
+	}
+	return res
+}
+
+// LocalIndexUsageStats maintains the read statistics of all indexes read by
+// statements executed on this node.
+//
+// LocalIndexUsageStats is safe for concurrent use. Recording a read of an
+// index that was read before only performs atomic operations on the
+// statistics of that index, so concurrent statements don't contend on a
+// node-wide lock.
+type LocalIndexUsageStats struct {
+	st *cluster.Settings
+
+	// stats maps an IndexKey to its *indexStats.
+	stats sync.Map
+}
+
+// NewLocalIndexUsageStats creates a new LocalIndexUsageStats.
+func NewLocalIndexUsageStats(st *cluster.Settings) *LocalIndexUsageStats {
+	return &LocalIndexUsageStats{st: st}
+}
+
+// RecordReads records one read of each of the given indexes. It is a no-op if
+// the collection of index usage statistics is disabled.
+func (s *LocalIndexUsageStats) RecordReads(keys []IndexKey) {
+	if len(keys) == 0 || !Enable.Get(&s.st.SV) {
+		return
+	}
+	now := timeutil.Now().UnixNano()
+	for _, key := range keys {
+		stats, ok := s.stats.Load(key)
+		if !ok {
+			stats, _ = s.stats.LoadOrStore(key, &indexStats{})
+		}
+		stats.(*indexStats).recordRead(now)
+	}
+}
+
+// Get returns the read statistics of the given index. The statistics of an
+// index that was never read are empty.
+func (s *LocalIndexUsageStats) Get(key IndexKey) serverpb.IndexUsageStatistics {
+	if stats, ok := s.stats.Load(key); ok {
+		return stats.(*indexStats).toProto(key)
+	}
+	return serverpb.IndexUsageStatistics{TableID: key.TableID, IndexID: key.IndexID}
+}
+
+// Serialize returns the read statistics of all indexes that were read, ordered
+// by table and index ID.
+func (s *LocalIndexUsageStats) Serialize() []serverpb.IndexUsageStatistics {
+	var res []serverpb.IndexUsageStatistics
+	s.stats.Range(func(key, stats interface{}) bool {
+		res = append(res, stats.(*indexStats).toProto(key.(IndexKey)))
+		return true
+	})
+	sortIndexUsageStatistics(res)
+	return res
+}
+
+// Reset clears all collected statistics.
+func (s *LocalIndexUsageStats) Reset() {
+	s.stats.Range(func(key, _ interface{}) bool {
+		s.stats.Delete(key)
+		return true
+	})
+}
+
+func sortIndexUsageStatistics(stats []serverpb.IndexUsageStatistics) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TableID != stats[j].TableID {
+			return stats[i].TableID < stats[j].TableID
+		}
+		return stats[i].IndexID < stats[j].IndexID
+	})
+}
+
+// MergeSerializedStats merges the serialized statistics of two nodes into one.
+// Both inputs must be ordered by table and index ID, as returned by Serialize;
+// the result is ordered the same way. Read counts are summed, and the most
+// recent read time is retained.
+func MergeSerializedStats(
+	first, second []serverpb.IndexUsageStatistics,
+) []serverpb.IndexUsageStatistics {
+	res := make([]serverpb.IndexUsageStatistics, 0, len(first)+len(second))
+	i, j := 0, 0
+	for i < len(first) && j < len(second) {
+		a, b := first[i], second[j]
+		switch {
+		case a.TableID < b.TableID || (a.TableID == b.TableID && a.IndexID < b.IndexID):
+			res = append(res, a)
+			i++
+		case a.TableID == b.TableID && a.IndexID == b.IndexID:
+			a.TotalReadCount += b.TotalReadCount
+			if b.LastRead.After(a.LastRead) {
+				a.LastRead = b.LastRead
+			}
+			res = append(res, a)
+			i++
+			j++
+		default:
+			res = append(res, b)
+			j++
+		}
+	}
+	res = append(res, first[i:]...)
+	return append(res, second[j:]...)
+}
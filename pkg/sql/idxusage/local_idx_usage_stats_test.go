@@ -0,0 +1,102 @@
+// Copyright 2021 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package idxusage
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalIndexUsageStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	s := NewLocalIndexUsageStats(st)
+
+	primary := IndexKey{TableID: 53, IndexID: 1}
+	secondary := IndexKey{TableID: 53, IndexID: 2}
+	other := IndexKey{TableID: 52, IndexID: 1}
+
+	s.RecordReads([]IndexKey{secondary, primary})
+	s.RecordReads([]IndexKey{secondary})
+	s.RecordReads([]IndexKey{other})
+
+	require.Equal(t, uint64(2), s.Get(secondary).TotalReadCount)
+	require.Equal(t, uint64(1), s.Get(primary).TotalReadCount)
+	require.False(t, s.Get(secondary).LastRead.IsZero())
+	require.True(t, s.Get(IndexKey{TableID: 53, IndexID: 3}).LastRead.IsZero())
+
+	stats := s.Serialize()
+	require.Len(t, stats, 3)
+	require.Equal(t, other.TableID, stats[0].TableID)
+	require.Equal(t, primary.IndexID, stats[1].IndexID)
+	require.Equal(t, secondary.IndexID, stats[2].IndexID)
+
+	// Disabling the collection leaves the existing statistics alone.
+	Enable.Override(&st.SV, false)
+	s.RecordReads([]IndexKey{secondary})
+	require.Equal(t, uint64(2), s.Get(secondary).TotalReadCount)
+
+	s.Reset()
+	require.Empty(t, s.Serialize())
+}
+
+func TestLocalIndexUsageStatsConcurrentReads(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s := NewLocalIndexUsageStats(cluster.MakeTestingClusterSettings())
+	keys := []IndexKey{{TableID: 53, IndexID: 1}, {TableID: 53, IndexID: 2}}
+
+	const numWorkers, numReads = 8, 1000
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numReads; j++ {
+				s.RecordReads(keys)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, key := range keys {
+		require.Equal(t, uint64(numWorkers*numReads), s.Get(key).TotalReadCount)
+	}
+}
+
+func TestMergeSerializedStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	t1 := time.Unix(100, 0)
+	t2 := time.Unix(200, 0)
+	first := []serverpb.IndexUsageStatistics{
+		{TableID: 52, IndexID: 1, TotalReadCount: 1, LastRead: t1},
+		{TableID: 53, IndexID: 2, TotalReadCount: 2, LastRead: t2},
+	}
+	second := []serverpb.IndexUsageStatistics{
+		{TableID: 53, IndexID: 1, TotalReadCount: 4, LastRead: t1},
+		{TableID: 53, IndexID: 2, TotalReadCount: 3, LastRead: t1},
+		{TableID: 54, IndexID: 1, TotalReadCount: 5, LastRead: t2},
+	}
+	require.Equal(t, []serverpb.IndexUsageStatistics{
+		{TableID: 52, IndexID: 1, TotalReadCount: 1, LastRead: t1},
+		{TableID: 53, IndexID: 1, TotalReadCount: 4, LastRead: t1},
+		{TableID: 53, IndexID: 2, TotalReadCount: 5, LastRead: t2},
+		{TableID: 54, IndexID: 1, TotalReadCount: 5, LastRead: t2},
+	}, MergeSerializedStats(first, second))
+}
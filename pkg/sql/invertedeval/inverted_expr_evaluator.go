@@ -8,7 +8,10 @@
 // by the Apache License, Version 2.0, included in the file
 // licenses/APL.txt.
 
-package rowexec
+// Package invertedeval contains the evaluation of (batches of) inverted
+// expressions that is shared by the row-by-row and the vectorized execution
+// engines.
+package invertedeval
 
 import (
 	"bytes"
@@ -23,7 +26,7 @@ import (
 // of an inverted index, which consists of an inverted column followed by the
 // primary key of the table. The set expressions involve union and
 // intersection over operands. The operands are sets of primary keys contained
-// in the corresponding span. Callers should use batchedInvertedExprEvaluator.
+// in the corresponding span. Callers should use BatchedInvertedExprEvaluator.
 // This evaluator does not do the actual scan -- it is fed the set elements as
 // the inverted index is scanned, and routes a set element to all the sets to
 // which it belongs (since spans can be overlapping). Once the scan is
@@ -107,19 +110,21 @@ type setExpression struct {
 	right         *setExpression
 }
 
-type invertedSpan = invertedexpr.SpanExpressionProto_Span
+// InvertedSpan is a span of the inverted index.
+type InvertedSpan = invertedexpr.SpanExpressionProto_Span
+
 type spanExpression = invertedexpr.SpanExpressionProto_Node
 
 // The spans in a SpanExpression.FactoredUnionSpans and the corresponding index
 // in invertedExprEvaluator.sets. Only populated when FactoredUnionsSpans is
 // non-empty.
 type spansAndSetIndex struct {
-	spans    []invertedSpan
+	spans    []InvertedSpan
 	setIndex int
 }
 
 // invertedExprEvaluator evaluates a single expression. It should not be directly
-// used -- see batchedInvertedExprEvaluator.
+// used -- see BatchedInvertedExprEvaluator.
 type invertedExprEvaluator struct {
 	setExpr *setExpression
 	// These are initially populated by calls to addIndexRow() as
@@ -213,9 +218,9 @@ func (ev *invertedExprEvaluator) evaluateSetExpr(sx *setExpression) setContainer
 
 // Supporting struct for invertedSpanRoutingInfo.
 type exprAndSetIndex struct {
-	// An index into batchedInvertedExprEvaluator.exprEvals.
+	// An index into BatchedInvertedExprEvaluator.exprEvals.
 	exprIndex int
-	// An index into batchedInvertedExprEvaluator.exprEvals[exprIndex].sets.
+	// An index into BatchedInvertedExprEvaluator.exprEvals[exprIndex].sets.
 	setIndex int
 }
 
@@ -233,7 +238,7 @@ func (esis exprAndSetIndexSorter) Less(i, j int) bool {
 // spans that are sorted and non-overlapping is used to route an added row to
 // all the expressions and sets that need that row.
 type invertedSpanRoutingInfo struct {
-	span invertedSpan
+	span InvertedSpan
 	// Sorted in increasing order of exprIndex.
 	exprAndSetIndexList []exprAndSetIndex
 	// A de-duped and sorted list of exprIndex values from exprAndSetIndexList.
@@ -255,39 +260,39 @@ func (s invertedSpanRoutingInfosByEndKey) Less(i, j int) bool {
 	return bytes.Compare(s[i].span.End, s[j].span.End) < 0
 }
 
-// preFilterer is the single method from DatumsToInvertedExpr that is relevant here.
-type preFilterer interface {
+// PreFilterer is the single method from DatumsToInvertedExpr that is relevant here.
+type PreFilterer interface {
 	PreFilter(enc invertedexpr.EncInvertedVal, preFilters []interface{}, result []bool) (bool, error)
 }
 
-// batchedInvertedExprEvaluator is for evaluating one or more expressions. The
-// batched evaluator can be reused by calling reset(). In the build phase,
-// append expressions directly to exprs. A nil expression is permitted, and is
-// just a placeholder that will result in a nil []KeyIndex in evaluate().
-// init() must be called before calls to {prepare}addIndexRow() -- it builds the
+// BatchedInvertedExprEvaluator is for evaluating one or more expressions. The
+// batched evaluator can be reused by calling Reset(). In the build phase,
+// append expressions directly to Exprs. A nil expression is permitted, and is
+// just a placeholder that will result in a nil []KeyIndex in Evaluate().
+// Init() must be called before calls to {Prepare}AddIndexRow() -- it builds the
 // fragmentedSpans used for routing the added rows.
-type batchedInvertedExprEvaluator struct {
-	filterer preFilterer
-	exprs    []*invertedexpr.SpanExpressionProto
+type BatchedInvertedExprEvaluator struct {
+	Filterer PreFilterer
+	Exprs    []*invertedexpr.SpanExpressionProto
 
 	// The pre-filtering state for each expression. When pre-filtering, this
-	// is the same length as exprs.
-	preFilterState []interface{}
+	// is the same length as Exprs.
+	PreFilterState []interface{}
 	// The parameters and result of pre-filtering for an inverted row are
 	// kept in this temporary state.
 	tempPreFilters      []interface{}
 	tempPreFilterResult []bool
 
-	// The evaluators for all the exprs.
+	// The evaluators for all the Exprs.
 	exprEvals []*invertedExprEvaluator
 	// Spans here are in sorted order and non-overlapping.
 	fragmentedSpans []invertedSpanRoutingInfo
-	// The routing index computed by prepareAddIndexRow
+	// The routing index computed by PrepareAddIndexRow
 	routingIndex int
 
 	// Temporary state used during initialization.
 	routingSpans       []invertedSpanRoutingInfo
-	coveringSpans      []invertedSpan
+	coveringSpans      []InvertedSpan
 	pendingSpansToSort invertedSpanRoutingInfosByEndKey
 }
 
@@ -322,7 +327,7 @@ type batchedInvertedExprEvaluator struct {
 //    c-e-f            f-g
 //    c-e-f            f-i
 //    c-e
-func (b *batchedInvertedExprEvaluator) fragmentPendingSpans(
+func (b *BatchedInvertedExprEvaluator) fragmentPendingSpans(
 	pendingSpans []invertedSpanRoutingInfo, fragmentUntil invertedexpr.EncInvertedVal,
 ) []invertedSpanRoutingInfo {
 	// The start keys are the same, so this only sorts in increasing order of
@@ -356,7 +361,7 @@ func (b *batchedInvertedExprEvaluator) fragmentPendingSpans(
 		}
 		// The next span to be added to fragmentedSpans.
 		nextSpan := invertedSpanRoutingInfo{
-			span: invertedSpan{
+			span: InvertedSpan{
 				Start: pendingSpans[0].span.Start,
 				End:   end,
 			},
@@ -392,7 +397,7 @@ func (b *batchedInvertedExprEvaluator) fragmentPendingSpans(
 	return pendingSpans
 }
 
-func (b *batchedInvertedExprEvaluator) pendingLenWithSameEnd(
+func (b *BatchedInvertedExprEvaluator) pendingLenWithSameEnd(
 	pendingSpans []invertedSpanRoutingInfo,
 ) int {
 	length := 1
@@ -405,17 +410,17 @@ func (b *batchedInvertedExprEvaluator) pendingLenWithSameEnd(
 	return length
 }
 
-// init fragments the spans for later routing of rows and returns spans
+// Init fragments the spans for later routing of rows and returns spans
 // representing a union of all the spans (for executing the scan). The
-// returned slice is only valid until the next call to reset.
-func (b *batchedInvertedExprEvaluator) init() []invertedSpan {
-	if cap(b.exprEvals) < len(b.exprs) {
-		b.exprEvals = make([]*invertedExprEvaluator, len(b.exprs))
+// returned slice is only valid until the next call to Reset.
+func (b *BatchedInvertedExprEvaluator) Init() []InvertedSpan {
+	if cap(b.exprEvals) < len(b.Exprs) {
+		b.exprEvals = make([]*invertedExprEvaluator, len(b.Exprs))
 	} else {
-		b.exprEvals = b.exprEvals[:len(b.exprs)]
+		b.exprEvals = b.exprEvals[:len(b.Exprs)]
 	}
 	// Initial spans fetched from all expressions.
-	for i, expr := range b.exprs {
+	for i, expr := range b.Exprs {
 		if expr == nil {
 			b.exprEvals[i] = nil
 			continue
@@ -479,12 +484,12 @@ func (b *batchedInvertedExprEvaluator) init() []invertedSpan {
 	return b.coveringSpans
 }
 
-// prepareAddIndexRow must be called prior to addIndexRow to do any
-// pre-filtering. The return value indicates whether addIndexRow should be
+// PrepareAddIndexRow must be called prior to AddIndexRow to do any
+// pre-filtering. The return value indicates whether AddIndexRow should be
 // called.
 // TODO(sumeer): if this will be called in non-decreasing order of enc,
 // use that to optimize the binary search.
-func (b *batchedInvertedExprEvaluator) prepareAddIndexRow(
+func (b *BatchedInvertedExprEvaluator) PrepareAddIndexRow(
 	enc invertedexpr.EncInvertedVal,
 ) (bool, error) {
 	i := sort.Search(len(b.fragmentedSpans), func(i int) bool {
@@ -492,7 +497,7 @@ func (b *batchedInvertedExprEvaluator) prepareAddIndexRow(
 	})
 	i--
 	b.routingIndex = i
-	if b.filterer != nil {
+	if b.Filterer != nil {
 		exprIndexList := b.fragmentedSpans[i].exprIndexList
 		if len(exprIndexList) > cap(b.tempPreFilters) {
 			b.tempPreFilters = make([]interface{}, len(exprIndexList))
@@ -502,17 +507,17 @@ func (b *batchedInvertedExprEvaluator) prepareAddIndexRow(
 			b.tempPreFilterResult = b.tempPreFilterResult[:len(exprIndexList)]
 		}
 		for j := range exprIndexList {
-			b.tempPreFilters[j] = b.preFilterState[exprIndexList[j]]
+			b.tempPreFilters[j] = b.PreFilterState[exprIndexList[j]]
 		}
-		return b.filterer.PreFilter(enc, b.tempPreFilters, b.tempPreFilterResult)
+		return b.Filterer.PreFilter(enc, b.tempPreFilters, b.tempPreFilterResult)
 	}
 	return true, nil
 }
 
-// addIndexRow must be called iff prepareAddIndexRow returned true.
-func (b *batchedInvertedExprEvaluator) addIndexRow(keyIndex KeyIndex) error {
+// AddIndexRow must be called iff PrepareAddIndexRow returned true.
+func (b *BatchedInvertedExprEvaluator) AddIndexRow(keyIndex KeyIndex) error {
 	i := b.routingIndex
-	if b.filterer != nil {
+	if b.Filterer != nil {
 		exprIndexes := b.fragmentedSpans[i].exprIndexList
 		exprSetIndexes := b.fragmentedSpans[i].exprAndSetIndexList
 		if len(exprIndexes) != len(b.tempPreFilterResult) {
@@ -540,8 +545,10 @@ func (b *batchedInvertedExprEvaluator) addIndexRow(keyIndex KeyIndex) error {
 	return nil
 }
 
-func (b *batchedInvertedExprEvaluator) evaluate() [][]KeyIndex {
-	result := make([][]KeyIndex, len(b.exprs))
+// Evaluate returns, for each of the Exprs, the set of key indexes that
+// satisfy that expression.
+func (b *BatchedInvertedExprEvaluator) Evaluate() [][]KeyIndex {
+	result := make([][]KeyIndex, len(b.Exprs))
 	for i := range b.exprEvals {
 		if b.exprEvals[i] == nil {
 			continue
@@ -551,9 +558,10 @@ func (b *batchedInvertedExprEvaluator) evaluate() [][]KeyIndex {
 	return result
 }
 
-func (b *batchedInvertedExprEvaluator) reset() {
-	b.exprs = b.exprs[:0]
-	b.preFilterState = b.preFilterState[:0]
+// Reset prepares the evaluator for the next batch of expressions.
+func (b *BatchedInvertedExprEvaluator) Reset() {
+	b.Exprs = b.Exprs[:0]
+	b.PreFilterState = b.PreFilterState[:0]
 	b.exprEvals = b.exprEvals[:0]
 	b.fragmentedSpans = b.fragmentedSpans[:0]
 	b.routingSpans = b.routingSpans[:0]
@@ -8,7 +8,7 @@
 // by the Apache License, Version 2.0, included in the file
 // licenses/APL.txt.
 
-package rowexec
+package invertedeval
 
 import (
 	"fmt"
@@ -33,11 +33,11 @@ func setToString(set []KeyIndex) string {
 	return b.String()
 }
 
-func writeSpan(b *strings.Builder, span invertedSpan) {
+func writeSpan(b *strings.Builder, span InvertedSpan) {
 	fmt.Fprintf(b, "[%s, %s) ", span.Start, span.End)
 }
 
-func spansToString(spans []invertedSpan) string {
+func spansToString(spans []InvertedSpan) string {
 	var b strings.Builder
 	for _, elem := range spans {
 		writeSpan(&b, elem)
@@ -136,35 +136,35 @@ type keyAndIndex struct {
 	index int
 }
 
-// Tests both invertedExprEvaluator and batchedInvertedExprEvaluator.
+// Tests both invertedExprEvaluator and BatchedInvertedExprEvaluator.
 func TestInvertedExpressionEvaluator(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	leaf1 := &spanExpression{
-		FactoredUnionSpans: []invertedSpan{{Start: []byte("a"), End: []byte("d")}},
+		FactoredUnionSpans: []InvertedSpan{{Start: []byte("a"), End: []byte("d")}},
 		Operator:           invertedexpr.None,
 	}
 	leaf2 := &spanExpression{
-		FactoredUnionSpans: []invertedSpan{{Start: []byte("e"), End: []byte("h")}},
+		FactoredUnionSpans: []InvertedSpan{{Start: []byte("e"), End: []byte("h")}},
 		Operator:           invertedexpr.None,
 	}
 	l1Andl2 := &spanExpression{
-		FactoredUnionSpans: []invertedSpan{
+		FactoredUnionSpans: []InvertedSpan{
 			{Start: []byte("i"), End: []byte("j")}, {Start: []byte("k"), End: []byte("n")}},
 		Operator: invertedexpr.SetIntersection,
 		Left:     leaf1,
 		Right:    leaf2,
 	}
 	leaf3 := &spanExpression{
-		FactoredUnionSpans: []invertedSpan{{Start: []byte("d"), End: []byte("f")}},
+		FactoredUnionSpans: []InvertedSpan{{Start: []byte("d"), End: []byte("f")}},
 		Operator:           invertedexpr.None,
 	}
 	leaf4 := &spanExpression{
-		FactoredUnionSpans: []invertedSpan{{Start: []byte("a"), End: []byte("c")}},
+		FactoredUnionSpans: []InvertedSpan{{Start: []byte("a"), End: []byte("c")}},
 		Operator:           invertedexpr.None,
 	}
 	l3Andl4 := &spanExpression{
-		FactoredUnionSpans: []invertedSpan{
+		FactoredUnionSpans: []InvertedSpan{
 			{Start: []byte("g"), End: []byte("m")}},
 		Operator: invertedexpr.SetIntersection,
 		Left:     leaf3,
@@ -191,7 +191,7 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 
 	// Test the getSpansAndSetIndex() method on the invertedExprEvaluator
 	// directly. The rest of the methods we will only exercise through
-	// batchedInvertedExprEvaluator.
+	// BatchedInvertedExprEvaluator.
 	evalUnion := newInvertedExprEvaluator(exprUnion)
 	// Indexes are being assigned using a pre-order traversal.
 	require.Equal(t, expectedSpansAndSetIndex,
@@ -201,15 +201,15 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 	require.Equal(t, expectedSpansAndSetIndex,
 		spansIndexToString(evalIntersection.getSpansAndSetIndex()))
 
-	// The batchedInvertedExprEvaluators will construct their own
+	// The BatchedInvertedExprEvaluators will construct their own
 	// invertedExprEvaluators.
 	protoUnion := invertedexpr.SpanExpressionProto{Node: *exprUnion}
-	batchEvalUnion := &batchedInvertedExprEvaluator{
-		exprs: []*invertedexpr.SpanExpressionProto{&protoUnion, nil},
+	batchEvalUnion := &BatchedInvertedExprEvaluator{
+		Exprs: []*invertedexpr.SpanExpressionProto{&protoUnion, nil},
 	}
 	protoIntersection := invertedexpr.SpanExpressionProto{Node: *exprIntersection}
-	batchEvalIntersection := &batchedInvertedExprEvaluator{
-		exprs: []*invertedexpr.SpanExpressionProto{&protoIntersection, nil},
+	batchEvalIntersection := &BatchedInvertedExprEvaluator{
+		Exprs: []*invertedexpr.SpanExpressionProto{&protoIntersection, nil},
 	}
 	expectedSpans := "[a, n) "
 	expectedFragmentedSpans :=
@@ -225,10 +225,10 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 			"span: [k, m)  indexes (expr, set): (0, 4) (0, 1) (expr): 0 \n" +
 			"span: [m, n)  indexes (expr, set): (0, 1) (expr): 0 \n"
 
-	require.Equal(t, expectedSpans, spansToString(batchEvalUnion.init()))
+	require.Equal(t, expectedSpans, spansToString(batchEvalUnion.Init()))
 	require.Equal(t, expectedFragmentedSpans,
 		fragmentedSpansToString(batchEvalUnion.fragmentedSpans))
-	require.Equal(t, expectedSpans, spansToString(batchEvalIntersection.init()))
+	require.Equal(t, expectedSpans, spansToString(batchEvalIntersection.Init()))
 	require.Equal(t, expectedFragmentedSpans,
 		fragmentedSpansToString(batchEvalIntersection.fragmentedSpans))
 
@@ -252,40 +252,40 @@ func TestInvertedExpressionEvaluator(t *testing.T) {
 		indexRows[i], indexRows[j] = indexRows[j], indexRows[i]
 	})
 	for _, elem := range indexRows {
-		add, err := batchEvalUnion.prepareAddIndexRow(invertedexpr.EncInvertedVal(elem.key))
+		add, err := batchEvalUnion.PrepareAddIndexRow(invertedexpr.EncInvertedVal(elem.key))
 		require.NoError(t, err)
 		require.Equal(t, true, add)
-		err = batchEvalUnion.addIndexRow(elem.index)
+		err = batchEvalUnion.AddIndexRow(elem.index)
 		require.NoError(t, err)
-		add, err = batchEvalIntersection.prepareAddIndexRow(invertedexpr.EncInvertedVal(elem.key))
+		add, err = batchEvalIntersection.PrepareAddIndexRow(invertedexpr.EncInvertedVal(elem.key))
 		require.NoError(t, err)
 		require.Equal(t, true, add)
-		err = batchEvalIntersection.addIndexRow(elem.index)
+		err = batchEvalIntersection.AddIndexRow(elem.index)
 		require.NoError(t, err)
 	}
-	require.Equal(t, expectedUnion, keyIndexesToString(batchEvalUnion.evaluate()))
-	require.Equal(t, expectedIntersection, keyIndexesToString(batchEvalIntersection.evaluate()))
+	require.Equal(t, expectedUnion, keyIndexesToString(batchEvalUnion.Evaluate()))
+	require.Equal(t, expectedIntersection, keyIndexesToString(batchEvalIntersection.Evaluate()))
 
 	// Now do both exprUnion and exprIntersection in a single batch.
 	batchBoth := batchEvalUnion
-	batchBoth.reset()
-	batchBoth.exprs = append(batchBoth.exprs, &protoUnion, &protoIntersection)
-	batchBoth.init()
+	batchBoth.Reset()
+	batchBoth.Exprs = append(batchBoth.Exprs, &protoUnion, &protoIntersection)
+	batchBoth.Init()
 	for _, elem := range indexRows {
-		add, err := batchBoth.prepareAddIndexRow(invertedexpr.EncInvertedVal(elem.key))
+		add, err := batchBoth.PrepareAddIndexRow(invertedexpr.EncInvertedVal(elem.key))
 		require.NoError(t, err)
 		require.Equal(t, true, add)
-		err = batchBoth.addIndexRow(elem.index)
+		err = batchBoth.AddIndexRow(elem.index)
 		require.NoError(t, err)
 	}
 	require.Equal(t, "0: 0 3 4 5 6 7 8 \n1: 0 4 6 8 \n",
-		keyIndexesToString(batchBoth.evaluate()))
+		keyIndexesToString(batchBoth.Evaluate()))
 
 	// Reset and evaluate nil expressions.
-	batchBoth.reset()
-	batchBoth.exprs = append(batchBoth.exprs, nil, nil)
-	require.Equal(t, 0, len(batchBoth.init()))
-	require.Equal(t, "0: \n1: \n", keyIndexesToString(batchBoth.evaluate()))
+	batchBoth.Reset()
+	batchBoth.Exprs = append(batchBoth.Exprs, nil, nil)
+	require.Equal(t, 0, len(batchBoth.Init()))
+	require.Equal(t, "0: \n1: \n", keyIndexesToString(batchBoth.Evaluate()))
 }
 
 // Test fragmentation for routing when multiple expressions in the batch have
@@ -295,28 +295,28 @@ func TestFragmentedSpans(t *testing.T) {
 
 	expr1 := invertedexpr.SpanExpressionProto{
 		Node: spanExpression{
-			FactoredUnionSpans: []invertedSpan{{Start: []byte("a"), End: []byte("g")}},
+			FactoredUnionSpans: []InvertedSpan{{Start: []byte("a"), End: []byte("g")}},
 			Operator:           invertedexpr.None,
 		},
 	}
 	expr2 := invertedexpr.SpanExpressionProto{
 		Node: spanExpression{
-			FactoredUnionSpans: []invertedSpan{{Start: []byte("d"), End: []byte("j")}},
+			FactoredUnionSpans: []InvertedSpan{{Start: []byte("d"), End: []byte("j")}},
 			Operator:           invertedexpr.None,
 		},
 	}
 	expr3 := invertedexpr.SpanExpressionProto{
 		Node: spanExpression{
-			FactoredUnionSpans: []invertedSpan{
+			FactoredUnionSpans: []InvertedSpan{
 				{Start: []byte("e"), End: []byte("f")}, {Start: []byte("i"), End: []byte("l")},
 				{Start: []byte("o"), End: []byte("p")}},
 			Operator: invertedexpr.None,
 		},
 	}
-	batchEval := &batchedInvertedExprEvaluator{
-		exprs: []*invertedexpr.SpanExpressionProto{&expr1, &expr2, &expr3},
+	batchEval := &BatchedInvertedExprEvaluator{
+		Exprs: []*invertedexpr.SpanExpressionProto{&expr1, &expr2, &expr3},
 	}
-	require.Equal(t, "[a, l) [o, p) ", spansToString(batchEval.init()))
+	require.Equal(t, "[a, l) [o, p) ", spansToString(batchEval.Init()))
 	require.Equal(t,
 		"span: [a, d)  indexes (expr, set): (0, 0) (expr): 0 \n"+
 			"span: [d, e)  indexes (expr, set): (0, 0) (1, 0) (expr): 0 1 \n"+
@@ -353,11 +353,11 @@ func TestInvertedExpressionEvaluatorPreFilter(t *testing.T) {
 	// Setup expressions such that the same expression appears multiple times
 	// in a span.
 	leaf1 := &spanExpression{
-		FactoredUnionSpans: []invertedSpan{{Start: []byte("a"), End: []byte("d")}},
+		FactoredUnionSpans: []InvertedSpan{{Start: []byte("a"), End: []byte("d")}},
 		Operator:           invertedexpr.None,
 	}
 	leaf2 := &spanExpression{
-		FactoredUnionSpans: []invertedSpan{{Start: []byte("e"), End: []byte("h")}},
+		FactoredUnionSpans: []InvertedSpan{{Start: []byte("e"), End: []byte("h")}},
 		Operator:           invertedexpr.None,
 	}
 	expr1 := &spanExpression{
@@ -381,22 +381,22 @@ func TestInvertedExpressionEvaluatorPreFilter(t *testing.T) {
 	}
 	expr2Proto := invertedexpr.SpanExpressionProto{Node: *expr2}
 	preFilters := []interface{}{"pf1", "pf2"}
-	batchEval := &batchedInvertedExprEvaluator{
-		exprs:          []*invertedexpr.SpanExpressionProto{&expr1Proto, &expr2Proto},
-		preFilterState: preFilters,
+	batchEval := &BatchedInvertedExprEvaluator{
+		Exprs:          []*invertedexpr.SpanExpressionProto{&expr1Proto, &expr2Proto},
+		PreFilterState: preFilters,
 	}
-	require.Equal(t, "[a, d) [e, h) ", spansToString(batchEval.init()))
+	require.Equal(t, "[a, d) [e, h) ", spansToString(batchEval.Init()))
 	require.Equal(t,
 		"span: [a, d)  indexes (expr, set): (0, 2) (0, 4) (1, 3) (expr): 0 1 \n"+
 			"span: [e, h)  indexes (expr, set): (0, 3) (1, 2) (1, 4) (expr): 0 1 \n",
 		fragmentedSpansToString(batchEval.fragmentedSpans))
 	feedIndexRows := func(indexRows []keyAndIndex, expectedAdd bool) {
 		for _, elem := range indexRows {
-			add, err := batchEval.prepareAddIndexRow(invertedexpr.EncInvertedVal(elem.key))
+			add, err := batchEval.PrepareAddIndexRow(invertedexpr.EncInvertedVal(elem.key))
 			require.NoError(t, err)
 			require.Equal(t, expectedAdd, add)
 			if add {
-				err = batchEval.addIndexRow(elem.index)
+				err = batchEval.AddIndexRow(elem.index)
 			}
 			require.NoError(t, err)
 		}
@@ -406,7 +406,7 @@ func TestInvertedExpressionEvaluatorPreFilter(t *testing.T) {
 		t:                  t,
 		expectedPreFilters: preFilters,
 	}
-	batchEval.filterer = &filterer
+	batchEval.Filterer = &filterer
 	// Neither row is pre-filtered, so 0 will appear in output.
 	filterer.result = []bool{true, true}
 	feedIndexRows([]keyAndIndex{{"a", 0}, {"e", 0}}, true)
@@ -431,7 +431,7 @@ func TestInvertedExpressionEvaluatorPreFilter(t *testing.T) {
 	filterer.result = []bool{false, false}
 	feedIndexRows([]keyAndIndex{{"a", 3}, {"e", 3}}, false)
 
-	require.Equal(t, "0: 0 1 \n1: 0 2 \n", keyIndexesToString(batchEval.evaluate()))
+	require.Equal(t, "0: 0 1 \n1: 0 2 \n", keyIndexesToString(batchEval.Evaluate()))
 }
 
 // TODO(sumeer): randomized inputs for union, intersection and expression evaluation.
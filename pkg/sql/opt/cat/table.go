@@ -167,6 +167,13 @@ type TableStatistic interface {
 	// and it represents the distribution of values for that column.
 	// See HistogramBucket for more details.
 	Histogram() []HistogramBucket
+
+	// Expression returns the expression on which the statistic was collected,
+	// or the empty string if it was collected on the columns themselves. For a
+	// statistic on an expression, the columns are the ones referenced by the
+	// expression, and the distinct count, null count and histogram describe the
+	// values of the expression.
+	Expression() string
 }
 
 // HistogramBucket contains the data for a single histogram bucket. Note
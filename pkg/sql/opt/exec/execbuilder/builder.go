@@ -90,6 +90,18 @@ type Builder struct {
 	// containsFullIndexScan is set to true if the statement contains a secondary
 	// index scan.
 	ContainsFullIndexScan bool
+
+	// IndexesUsed lists the indexes read by the statement, through scans,
+	// index joins, lookup joins, inverted joins and zigzag joins. Indexes of
+	// virtual tables are not included. An index appears once per operator that
+	// reads it.
+	IndexesUsed []IndexUsage
+}
+
+// IndexUsage identifies an index read by a statement.
+type IndexUsage struct {
+	TableID cat.StableID
+	IndexID cat.StableID
 }
 
 // New constructs an instance of the execution node builder using the
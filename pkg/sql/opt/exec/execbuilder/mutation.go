@@ -490,8 +490,9 @@ func (b *Builder) tryBuildDeleteRange(del *memo.DeleteExpr) (_ execPlan, ok bool
 	}
 
 	// Check for simple Scan input operator without a limit; anything else is not
-	// supported by a range delete.
-	if scan, ok := del.Input.(*memo.ScanExpr); !ok || scan.HardLimit != 0 {
+	// supported by a range delete. Skip scans are not supported either, since
+	// their constraint does not describe a contiguous range of keys.
+	if scan, ok := del.Input.(*memo.ScanExpr); !ok || scan.HardLimit != 0 || scan.IsSkipScan() {
 		return execPlan{}, false, nil
 	}
 
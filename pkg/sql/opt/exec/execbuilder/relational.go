@@ -448,6 +448,10 @@ func (b *Builder) indexConstraintMaxResults(scan *memo.ScanExpr) (maxRows uint64
 	if c == nil || c.IsContradiction() || c.IsUnconstrained() {
 		return 0, false
 	}
+	if scan.IsSkipScan() {
+		// The constraint of a skip scan is applied for each distinct prefix.
+		return 0, false
+	}
 
 	numCols := c.Columns.Count()
 	var indexCols opt.ColSet
@@ -533,6 +537,7 @@ func (b *Builder) scanParams(
 		NeededCols:         needed,
 		IndexConstraint:    scan.Constraint,
 		InvertedConstraint: scan.InvertedConstraint,
+		SkipScanPrefixLen:  scan.SkipScanPrefixLen,
 		HardLimit:          hardLimit,
 		SoftLimit:          softLimit,
 		// HardLimit.Reverse() is taken into account by ScanIsReverse.
@@ -563,18 +568,30 @@ func (b *Builder) buildScan(scan *memo.ScanExpr) (execPlan, error) {
 
 	// Save if we planned a full table/index scan on the builder so that the
 	// planner can be made aware later. We only do this for non-virtual tables.
-	if !tab.IsVirtualTable() && scan.Constraint == nil && scan.InvertedConstraint == nil {
+	if !tab.IsVirtualTable() && scan.Constraint == nil && scan.InvertedConstraint == nil &&
+		!scan.IsSkipScan() {
 		if scan.Index == cat.PrimaryIndex {
 			b.ContainsFullTableScan = true
 		} else {
 			b.ContainsFullIndexScan = true
 		}
 	}
+	b.recordIndexRead(tab, tab.Index(scan.Index))
 
 	res.root = root
 	return res, nil
 }
 
+// recordIndexRead records that the statement reads the given index, so that
+// the executor can maintain index usage statistics. Reads of virtual tables
+// are not recorded.
+func (b *Builder) recordIndexRead(tab cat.Table, idx cat.Index) {
+	if tab.IsVirtualTable() {
+		return
+	}
+	b.IndexesUsed = append(b.IndexesUsed, IndexUsage{TableID: tab.ID(), IndexID: idx.ID()})
+}
+
 func (b *Builder) buildSelect(sel *memo.SelectExpr) (execPlan, error) {
 	input, err := b.buildRelational(sel.Input)
 	if err != nil {
@@ -1370,6 +1387,7 @@ func (b *Builder) buildIndexJoin(join *memo.IndexJoinExpr) (execPlan, error) {
 	if err != nil {
 		return execPlan{}, err
 	}
+	b.recordIndexRead(tab, pri)
 
 	return res, nil
 }
@@ -1440,6 +1458,7 @@ func (b *Builder) buildLookupJoin(join *memo.LookupJoinExpr) (execPlan, error) {
 	if err != nil {
 		return execPlan{}, err
 	}
+	b.recordIndexRead(tab, idx)
 
 	// Apply a post-projection if Cols doesn't contain all input columns.
 	if !inputCols.SubsetOf(join.Cols) {
@@ -1516,6 +1535,7 @@ func (b *Builder) buildInvertedJoin(join *memo.InvertedJoinExpr) (execPlan, erro
 	if err != nil {
 		return execPlan{}, err
 	}
+	b.recordIndexRead(tab, idx)
 
 	// Apply a post-projection to remove the inverted column.
 	return b.applySimpleProject(res, join.Cols, join.ProvidedPhysical().Ordering)
@@ -1594,6 +1614,8 @@ func (b *Builder) buildZigzagJoin(join *memo.ZigzagJoinExpr) (execPlan, error) {
 	if err != nil {
 		return execPlan{}, err
 	}
+	b.recordIndexRead(leftTable, leftIndex)
+	b.recordIndexRead(rightTable, rightIndex)
 
 	return res, nil
 }
@@ -354,6 +354,10 @@ func (e *emitter) emitNodeAttributes(n *Node) error {
 			e.emitSpans("spans", a.Table, a.Index, a.Params)
 		}
 
+		if a.Params.SkipScanPrefixLen > 0 {
+			ob.Attr("skip prefix", a.Params.SkipScanPrefixLen)
+		}
+
 		if a.Params.HardLimit > 0 {
 			ob.Attr("limit", a.Params.HardLimit)
 		}
@@ -692,14 +696,19 @@ func (e *emitter) emitSpans(
 
 func (e *emitter) spansStr(table cat.Table, index cat.Index, scanParams exec.ScanParams) string {
 	if scanParams.InvertedConstraint == nil && scanParams.IndexConstraint == nil {
+		if scanParams.SkipScanPrefixLen > 0 {
+			return "SKIP SCAN"
+		}
 		if scanParams.HardLimit > 0 {
 			return "LIMITED SCAN"
 		}
 		return "FULL SCAN"
 	}
 
-	// In verbose mode show the physical spans.
-	if e.ob.flags.Verbose {
+	// In verbose mode show the physical spans. The physical spans of a skip
+	// scan depend on the distinct prefixes that are found during execution, so
+	// the logical spans are shown instead.
+	if e.ob.flags.Verbose && scanParams.SkipScanPrefixLen == 0 {
 		return e.spanFormatFn(table, index, scanParams)
 	}
 
@@ -42,6 +42,13 @@ type ScanParams struct {
 	IndexConstraint    *constraint.Constraint
 	InvertedConstraint invertedexpr.InvertedSpans
 
+	// If non-zero, the scan is a loose index scan that skips through the
+	// distinct values of this many leading index columns. In this case,
+	// IndexConstraint (if set) constrains the index columns that follow the
+	// prefix, and is applied for each distinct prefix. If IndexConstraint is
+	// nil, the scan returns one row for each distinct prefix.
+	SkipScanPrefixLen int
+
 	// If non-zero, the scan returns this many rows.
 	HardLimit int64
 
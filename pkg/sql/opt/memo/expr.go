@@ -397,6 +397,11 @@ const (
 	// PreferLookupJoinIntoRight reduces the cost of a lookup join where the
 	// lookup table is on the right side.
 	PreferLookupJoinIntoRight
+
+	// PreserveJoinOrder prevents the join from being reordered, without
+	// restricting its execution method. It is set on the joins built for a
+	// Leading plan hint (see package planhints).
+	PreserveJoinOrder
 )
 
 const (
@@ -427,6 +432,8 @@ var joinFlagStr = map[JoinFlags]string{
 
 	PreferLookupJoinIntoLeft:  "lookup join (into left side)",
 	PreferLookupJoinIntoRight: "lookup join (into right side)",
+
+	PreserveJoinOrder: "preserve join order",
 }
 
 // Empty returns true if this is the default value (where all join types are
@@ -446,7 +453,8 @@ func (jf JoinFlags) String() string {
 	}
 
 	prefer := jf & (PreferLookupJoinIntoLeft | PreferLookupJoinIntoRight)
-	disallow := jf ^ prefer
+	preserve := jf & PreserveJoinOrder
+	disallow := jf ^ prefer ^ preserve
 
 	// Special cases with prettier results for common cases.
 	var b strings.Builder
@@ -480,6 +488,13 @@ func (jf JoinFlags) String() string {
 		b.WriteString(joinFlagStr[flag])
 		prefer ^= flag
 	}
+
+	if preserve != 0 {
+		if b.Len() > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(joinFlagStr[PreserveJoinOrder])
+	}
 	return b.String()
 }
 
@@ -609,11 +624,13 @@ func (f *WindowFrame) String() string {
 }
 
 // IsCanonical returns true if the ScanPrivate indicates an original unaltered
-// primary index Scan operator (i.e. unconstrained and not limited).
+// primary index Scan operator (i.e. unconstrained, not limited and not a skip
+// scan).
 func (s *ScanPrivate) IsCanonical() bool {
 	return s.Index == cat.PrimaryIndex &&
 		s.Constraint == nil &&
-		s.HardLimit == 0
+		s.HardLimit == 0 &&
+		s.SkipScanPrefixLen == 0
 }
 
 // IsUnfiltered returns true if the ScanPrivate will produce all rows in the
@@ -622,9 +639,28 @@ func (s *ScanPrivate) IsUnfiltered(md *opt.Metadata) bool {
 	return (s.Constraint == nil || s.Constraint.IsUnconstrained()) &&
 		s.InvertedConstraint == nil &&
 		s.HardLimit == 0 &&
+		s.SkipScanPrefixLen == 0 &&
 		!s.UsesPartialIndex(md)
 }
 
+// IsSkipScan returns true if the ScanPrivate indicates a loose index scan that
+// skips through the distinct values of a prefix of the index columns. See
+// SkipScanPrefixLen.
+func (s *ScanPrivate) IsSkipScan() bool {
+	return s.SkipScanPrefixLen > 0
+}
+
+// SkipScanPrefixCols returns the set of columns of the prefix whose distinct
+// values are skipped through by a skip scan.
+func (s *ScanPrivate) SkipScanPrefixCols(md *opt.Metadata) opt.ColSet {
+	var cols opt.ColSet
+	idx := md.Table(s.Table).Index(s.Index)
+	for i := 0; i < s.SkipScanPrefixLen; i++ {
+		cols.Add(s.Table.ColumnID(idx.Column(i).Ordinal()))
+	}
+	return cols
+}
+
 // IsLocking returns true if the ScanPrivate is configured to use a row-level
 // locking mode. This can be the case either because the Scan is in the scope of
 // a SELECT .. FOR [KEY] UPDATE/SHARE clause or because the Scan was configured
@@ -388,6 +388,15 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 			n := tp.Childf("inverted constraint: %s", b.String())
 			ic.Format(n, "spans")
 		}
+		if t.IsSkipScan() {
+			idx := md.Table(t.Table).Index(t.Index)
+			var b strings.Builder
+			for i := 0; i < t.SkipScanPrefixLen; i++ {
+				b.WriteRune('/')
+				b.WriteString(fmt.Sprintf("%d", t.Table.ColumnID(idx.Column(i).Ordinal())))
+			}
+			tp.Childf("skip prefix: %s", b.String())
+		}
 		if t.HardLimit.IsSet() {
 			tp.Childf("limit: %s", t.HardLimit)
 		}
@@ -1254,6 +1263,9 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 		if t.UsesPartialIndex(f.Memo.Metadata()) {
 			f.Buffer.WriteString(",partial")
 		}
+		if t.IsSkipScan() {
+			f.Buffer.WriteString(",skip")
+		}
 
 	case *SequenceSelectPrivate:
 		seq := f.Memo.metadata.Sequence(t.Sequence)
@@ -140,6 +140,12 @@ func (b *logicalPropsBuilder) buildScanProps(scan *ScanExpr, rel *props.Relation
 				rel.FuncDeps.AddStrictKey(keyCols, allCols)
 			}
 		}
+		if scan.IsSkipScan() && scan.Constraint == nil {
+			// A skip scan without a constraint returns a single row for each
+			// distinct prefix, so the prefix columns form a key.
+			prefixCols := scan.SkipScanPrefixCols(md)
+			rel.FuncDeps.AddStrictKey(prefixCols, prefixCols.Union(rel.OutputCols))
+		}
 		rel.FuncDeps.MakeNotNull(rel.NotNullCols)
 		rel.FuncDeps.ProjectCols(rel.OutputCols)
 	}
@@ -157,7 +163,9 @@ func (b *logicalPropsBuilder) buildScanProps(scan *ScanExpr, rel *props.Relation
 		if hardLimit > 0 && hardLimit < math.MaxUint32 {
 			rel.Cardinality = rel.Cardinality.Limit(uint32(hardLimit))
 		}
-		if scan.Constraint != nil {
+		// The constraint of a skip scan is applied separately for each distinct
+		// prefix, so it does not bound the cardinality.
+		if scan.Constraint != nil && !scan.IsSkipScan() {
 			b.updateCardinalityFromConstraint(scan.Constraint, rel)
 		}
 		if isPartialIndexScan {
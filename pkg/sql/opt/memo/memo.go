@@ -133,6 +133,7 @@ type Memo struct {
 	// planning. We need to cross-check these before reusing a cached memo.
 	reorderJoinsLimit       int
 	zigzagJoinEnabled       bool
+	eagerAggEnabled         bool
 	useHistograms           bool
 	useMultiColStats        bool
 	safeUpdates             bool
@@ -166,6 +167,7 @@ func (m *Memo) Init(evalCtx *tree.EvalContext) {
 
 	m.reorderJoinsLimit = evalCtx.SessionData.ReorderJoinsLimit
 	m.zigzagJoinEnabled = evalCtx.SessionData.ZigzagJoinEnabled
+	m.eagerAggEnabled = evalCtx.SessionData.EagerAggregationEnabled
 	m.useHistograms = evalCtx.SessionData.OptimizerUseHistograms
 	m.useMultiColStats = evalCtx.SessionData.OptimizerUseMultiColStats
 	m.safeUpdates = evalCtx.SessionData.SafeUpdates
@@ -273,6 +275,7 @@ func (m *Memo) IsStale(
 	// changed.
 	if m.reorderJoinsLimit != evalCtx.SessionData.ReorderJoinsLimit ||
 		m.zigzagJoinEnabled != evalCtx.SessionData.ZigzagJoinEnabled ||
+		m.eagerAggEnabled != evalCtx.SessionData.EagerAggregationEnabled ||
 		m.useHistograms != evalCtx.SessionData.OptimizerUseHistograms ||
 		m.useMultiColStats != evalCtx.SessionData.OptimizerUseMultiColStats ||
 		m.safeUpdates != evalCtx.SessionData.SafeUpdates ||
@@ -366,6 +369,20 @@ func (m *Memo) RequestColStat(
 	return nil, false
 }
 
+// RequestTableColStat calculates and returns the column statistic of the given
+// columns of a base table, derived from the table statistics. It returns
+// ok=false if no table statistics are available.
+func (m *Memo) RequestTableColStat(
+	tabID opt.TableID, cols opt.ColSet,
+) (colStat *props.ColumnStatistic, ok bool) {
+	// When SetRoot is called, the statistics builder may have been cleared.
+	// If this happens, we can't serve the request anymore.
+	if sb := &m.logPropsBuilder.sb; sb.md != nil && sb.makeTableStatistics(tabID).Available {
+		return sb.colStatTable(tabID, cols), true
+	}
+	return nil, false
+}
+
 // RowsProcessed calculates and returns the number of rows processed by the
 // relational expression. It is currently only supported for joins.
 func (m *Memo) RowsProcessed(expr RelExpr) (_ float64, ok bool) {
@@ -147,6 +147,12 @@ func TestMemoIsStale(t *testing.T) {
 	evalCtx.SessionData.ZigzagJoinEnabled = false
 	notStale()
 
+	// Stale eager aggregation enable.
+	evalCtx.SessionData.EagerAggregationEnabled = true
+	stale()
+	evalCtx.SessionData.EagerAggregationEnabled = false
+	notStale()
+
 	// Stale optimizer histogram usage enable.
 	evalCtx.SessionData.OptimizerUseHistograms = true
 	stale()
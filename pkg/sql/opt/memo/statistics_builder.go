@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -300,7 +301,6 @@ func (sb *statisticsBuilder) colStatFromInput(
 					sb.makeTableStatistics(lookupJoin.Table)
 			}
 			if invertedJoin != nil {
-				// TODO(rytaft): use inverted index stats when available.
 				return sb.colStatTable(invertedJoin.Table, colSet),
 					sb.makeTableStatistics(invertedJoin.Table)
 			}
@@ -543,15 +543,28 @@ func (sb *statisticsBuilder) makeTableStatistics(tabID opt.TableID) *props.Stati
 
 		// Add all the column statistics, using the most recent statistic for each
 		// column set. Stats are ordered with most recent first.
+		tabMeta := sb.md.TableMeta(tabID)
 		for i := 0; i < tab.StatisticCount(); i++ {
 			stat := tab.Statistic(i)
-			if stat.ColumnCount() > 1 && !sb.evalCtx.SessionData.OptimizerUseMultiColStats {
+			isExprStat := stat.Expression() != ""
+			if stat.ColumnCount() > 1 && !isExprStat && !sb.evalCtx.SessionData.OptimizerUseMultiColStats {
 				continue
 			}
 
 			var cols opt.ColSet
-			for i := 0; i < stat.ColumnCount(); i++ {
-				cols.Add(tabID.ColumnID(stat.ColumnOrdinal(i)))
+			if isExprStat {
+				// The statistic describes the values of an expression over the
+				// columns; it becomes the statistic of the synthetic column which
+				// represents the expression.
+				exprStat, ok := tabMeta.ExprStatistics[stat.Expression()]
+				if !ok {
+					continue
+				}
+				cols.Add(exprStat.Col)
+			} else {
+				for i := 0; i < stat.ColumnCount(); i++ {
+					cols.Add(tabID.ColumnID(stat.ColumnOrdinal(i)))
+				}
 			}
 
 			if colStat, ok := stats.ColStats.Add(cols); ok {
@@ -564,7 +577,10 @@ func (sb *statisticsBuilder) makeTableStatistics(tabID opt.TableID) *props.Stati
 					// If this column is invertable, the histogram describes the inverted index
 					// entries, and we need to create a new stat for it, and not apply a histogram
 					// to the source column.
-					virtualColOrds := invIndexVirtualCols[stat.ColumnOrdinal(0)]
+					var virtualColOrds []int
+					if !isExprStat {
+						virtualColOrds = invIndexVirtualCols[stat.ColumnOrdinal(0)]
+					}
 					if len(virtualColOrds) == 0 {
 						colStat.Histogram = &props.Histogram{}
 						colStat.Histogram.Init(sb.evalCtx, col, stat.Histogram())
@@ -623,6 +639,15 @@ func (sb *statisticsBuilder) buildScan(scan *ScanExpr, relProps *props.Relationa
 	inputStats := sb.makeTableStatistics(scan.Table)
 	s.RowCount = inputStats.RowCount
 
+	// A skip scan without a constraint returns a single row for each distinct
+	// value of its prefix columns.
+	if scan.IsSkipScan() && scan.Constraint == nil {
+		prefixStat := sb.colStatTable(scan.Table, scan.SkipScanPrefixCols(sb.md))
+		s.ApplySelectivity(prefixStat.DistinctCount / s.RowCount)
+		sb.finalizeFromCardinality(relProps)
+		return
+	}
+
 	var pred FiltersExpr
 	if scan.UsesPartialIndex(sb.md) {
 		pred = scan.PartialIndexPredicate(sb.md)
@@ -1022,6 +1047,7 @@ func (sb *statisticsBuilder) buildInvertedFilter(
 	// -----------------------------------
 	inputStats := &invFilter.Input.Relational().Stats
 	s.RowCount = inputStats.RowCount
+	s.ApplySelectivity(sb.selectivityFromInvertedFilter(invFilter))
 	s.ApplySelectivity(sb.selectivityFromHistograms(histCols, invFilter, s))
 	s.ApplySelectivity(sb.selectivityFromMultiColDistinctCounts(constrainedCols, invFilter, s))
 	s.ApplySelectivity(sb.selectivityFromNullsRemoved(invFilter, relProps.NotNullCols, constrainedCols))
@@ -1032,6 +1058,69 @@ func (sb *statisticsBuilder) buildInvertedFilter(
 	sb.finalizeFromCardinality(relProps)
 }
 
+// selectivityFromInvertedFilter estimates the fraction of the input rows of the
+// given inverted filter which satisfy its inverted expression, using the
+// histogram of the inverted index keys. The input has a row for every key in
+// the spans to read, but only the primary keys which satisfy the set
+// operations of the expression are output. For example, the expression for
+// j @> '{"a": 1, "b": 2}' is the intersection of the keys for "a": 1 and
+// "b": 2, so the rows which only have one of the keys are filtered out.
+func (sb *statisticsBuilder) selectivityFromInvertedFilter(
+	invFilter *InvertedFilterExpr,
+) (selectivity float64) {
+	invCol := invFilter.InvertedColumn
+	tabID := sb.md.ColumnMeta(invCol).Table
+	if tabID == 0 || !sb.shouldUseHistogram(invFilter.Relational(), opt.MakeColSet(invCol)) {
+		return 1
+	}
+	hist := sb.colStatTable(tabID, opt.MakeColSet(invCol)).Histogram
+	if hist == nil {
+		return 1
+	}
+	spansToRead := invertedSpansRowCount(hist, invFilter.InvertedExpression.SpansToRead)
+	if spansToRead == 0 {
+		return 1
+	}
+	tableRowCount := sb.makeTableStatistics(tabID).RowCount
+	rowCount := invertedExprRowCount(hist, invFilter.InvertedExpression, tableRowCount)
+	return max(min(rowCount/spansToRead, 1), epsilon)
+}
+
+// invertedExprRowCount estimates the number of rows which satisfy the given
+// inverted expression, assuming that the keys of its subexpressions are
+// independent.
+func invertedExprRowCount(
+	hist *props.Histogram, expr invertedexpr.InvertedExpression, tableRowCount float64,
+) float64 {
+	spanExpr, ok := expr.(*invertedexpr.SpanExpression)
+	if !ok {
+		// The expression cannot be evaluated using the inverted index, so it
+		// doesn't filter any rows.
+		return tableRowCount
+	}
+	rowCount := invertedSpansRowCount(hist, spanExpr.FactoredUnionSpans)
+	switch spanExpr.Operator {
+	case invertedexpr.SetUnion:
+		rowCount += invertedExprRowCount(hist, spanExpr.Left, tableRowCount) +
+			invertedExprRowCount(hist, spanExpr.Right, tableRowCount)
+	case invertedexpr.SetIntersection:
+		if tableRowCount > 0 {
+			rowCount += invertedExprRowCount(hist, spanExpr.Left, tableRowCount) *
+				invertedExprRowCount(hist, spanExpr.Right, tableRowCount) / tableRowCount
+		}
+	}
+	return rowCount
+}
+
+// invertedSpansRowCount returns the number of inverted index entries in the
+// given spans, according to the histogram of the inverted index keys.
+func invertedSpansRowCount(hist *props.Histogram, spans invertedexpr.InvertedSpans) float64 {
+	if len(spans) == 0 {
+		return 0
+	}
+	return hist.InvertedFilter(spans).ValuesCount()
+}
+
 func (sb *statisticsBuilder) colStatInvertedFilter(
 	colSet opt.ColSet, invFilter *InvertedFilterExpr,
 ) *props.ColumnStatistic {
@@ -1186,7 +1275,7 @@ func (sb *statisticsBuilder) buildJoin(
 	}
 
 	if join.Op() == opt.InvertedJoinOp || hasGeoIndexJoinCond(h.filters) {
-		s.ApplySelectivity(sb.selectivityFromInvertedJoinCondition(join, s))
+		s.ApplySelectivity(sb.selectivityFromInvertedJoinCondition(join, h))
 	}
 	s.ApplySelectivity(sb.selectivityFromHistograms(histCols, join, s))
 	s.ApplySelectivity(sb.selectivityFromMultiColDistinctCounts(
@@ -2611,7 +2700,10 @@ func (sb *statisticsBuilder) shouldUseHistogram(relProps *props.Relational, cols
 			// Special case these since ColumnTypeIsInvertedIndexable returns true for
 			// them, but they are supported in histograms now.
 		default:
-			if colinfo.ColumnTypeIsInvertedIndexable(colTyp) {
+			// The histogram of an inverted index column describes the inverted
+			// index keys, which are collected by CREATE STATISTICS for all
+			// invertable types.
+			if colinfo.ColumnTypeIsInvertedIndexable(colTyp) && !sb.isInvertedIndexCol(col) {
 				allowHist = false
 			}
 		}
@@ -2619,6 +2711,16 @@ func (sb *statisticsBuilder) shouldUseHistogram(relProps *props.Relational, cols
 	return allowHist
 }
 
+// isInvertedIndexCol returns true if the given column is the virtual column of
+// an inverted index.
+func (sb *statisticsBuilder) isInvertedIndexCol(col opt.ColumnID) bool {
+	tabID := sb.md.ColumnMeta(col).Table
+	if tabID == 0 {
+		return false
+	}
+	return sb.md.Table(tabID).Column(tabID.ColumnOrdinal(col)).InvertedSourceColumnOrdinal() != -1
+}
+
 // rowsProcessed calculates and returns the number of rows processed by the
 // relational expression. It is currently only supported for joins.
 func (sb *statisticsBuilder) rowsProcessed(e RelExpr) float64 {
@@ -2819,8 +2921,8 @@ const (
 	// it worth adding the overhead of using a histogram.
 	minCardinalityForHistogram = 100
 
-	// This is the default selectivity estimated for inverted joins until we can
-	// get better statistics on inverted indexes.
+	// This is the default selectivity estimated for inverted joins when there
+	// is no histogram on the inverted index keys.
 	unknownInvertedJoinSelectivity = 1.0 / 100.0
 
 	// multiColWeight is the weight to assign the selectivity calculation using
@@ -2879,6 +2981,10 @@ func (sb *statisticsBuilder) filterRelExpr(
 	}
 	equivReps := equivFD.EquivReps()
 
+	// Calculate selectivity of conjuncts on expressions with statistics
+	// -----------------------------------------------------------------
+	filters, exprSelectivity := sb.applyExprStatistics(filters)
+
 	// Calculate distinct counts and histograms for constrained columns
 	// ----------------------------------------------------------------
 	numUnappliedConjuncts, constrainedCols, histCols := sb.applyFilter(filters, e, relProps)
@@ -2898,6 +3004,7 @@ func (sb *statisticsBuilder) filterRelExpr(
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &relProps.FuncDeps, e, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
 	s.ApplySelectivity(sb.selectivityFromNullsRemoved(e, notNullCols, constrainedCols))
+	s.ApplySelectivity(exprSelectivity)
 
 	// Adjust the selectivity so we don't double-count the histogram columns.
 	s.ApplySelectivity(1.0 / sb.selectivityFromSingleColDistinctCounts(histCols, e, s))
@@ -3705,6 +3812,93 @@ func (sb *statisticsBuilder) selectivityFromHistograms(
 	return max(selectivity, epsilon)
 }
 
+// applyExprStatistics calculates the selectivity of the conjuncts which
+// compare an expression on which a table statistic was collected with a
+// constant (see TableMeta.ExprStatistics), using the histogram of the
+// statistic. It returns the remaining conjuncts, which must be estimated as
+// usual, along with the selectivity of the conjuncts it applied.
+func (sb *statisticsBuilder) applyExprStatistics(
+	filters FiltersExpr,
+) (remaining FiltersExpr, selectivity float64) {
+	selectivity = 1.0
+	applied := false
+	for i := range filters {
+		conjunctSelectivity, ok := sb.selectivityFromExprStatistic(filters[i].Condition)
+		if !ok {
+			if applied {
+				remaining = append(remaining, filters[i])
+			}
+			continue
+		}
+		if !applied {
+			applied = true
+			remaining = make(FiltersExpr, i, len(filters)-1)
+			copy(remaining, filters[:i])
+		}
+		selectivity *= conjunctSelectivity
+	}
+	if !applied {
+		return filters, selectivity
+	}
+	return remaining, selectivity
+}
+
+// selectivityFromExprStatistic calculates the selectivity of a comparison
+// between an expression on which a table statistic with a histogram was
+// collected and a constant. It returns ok=false if the comparison doesn't have
+// this form.
+func (sb *statisticsBuilder) selectivityFromExprStatistic(
+	cond opt.ScalarExpr,
+) (selectivity float64, ok bool) {
+	switch cond.Op() {
+	case opt.EqOp, opt.LtOp, opt.GtOp, opt.LeOp, opt.GeOp, opt.NeOp:
+	default:
+		return 0, false
+	}
+	// Normalization rules move constants to the right side of comparisons.
+	left, right := cond.Child(0), cond.Child(1)
+	if !opt.IsConstValueOp(right) {
+		return 0, false
+	}
+
+	// Find the expression among the expressions of statistics. The scalar
+	// expressions are interned, so equal expressions are the same object.
+	var tabID opt.TableID
+	var exprStat opt.ExprStatistic
+	tables := sb.md.AllTables()
+	for i := 0; i < len(tables) && tabID == 0; i++ {
+		for _, s := range tables[i].ExprStatistics {
+			if s.Expr == left {
+				tabID, exprStat = tables[i].MetaID, s
+				break
+			}
+		}
+	}
+	if tabID == 0 {
+		return 0, false
+	}
+
+	tableStats := sb.makeTableStatistics(tabID)
+	colStat, ok := tableStats.ColStats.Lookup(opt.MakeColSet(exprStat.Col))
+	if !ok || colStat.Histogram == nil {
+		return 0, false
+	}
+	cb := constraintsBuilder{md: sb.md, evalCtx: sb.evalCtx}
+	cs, tight := cb.buildSingleColumnConstraintConst(exprStat.Col, cond.Op(), ExtractConstDatum(right))
+	if !tight || cs.Length() != 1 {
+		return 0, false
+	}
+	c := cs.Constraint(0)
+	if _, _, ok := colStat.Histogram.CanFilter(c); !ok {
+		return 0, false
+	}
+	hist := colStat.Histogram.Filter(c)
+
+	// Avoid setting selectivity to 0. The stats may be stale, and we
+	// can end up with weird and inefficient plans if we estimate 0 rows.
+	return max(fraction(hist.ValuesCount(), tableStats.RowCount), epsilon), true
+}
+
 // selectivityFromNullsRemoved calculates the selectivity from null-rejecting
 // filters that were not already accounted for in selectivityFromMultiColDistinctCounts
 // or selectivityFromHistograms. The columns for filters already accounted for
@@ -3848,12 +4042,72 @@ func (sb *statisticsBuilder) selectivityFromEquivalencySemiJoin(
 	return fraction(minDistinctCountRight, maxDistinctCountLeft)
 }
 
+// selectivityFromInvertedJoinCondition estimates the selectivity of the
+// condition of an inverted join. If there is a histogram of the inverted index
+// keys, each input row is assumed to match the average number of rows per key.
+// Joins which are not (yet) inverted joins use the inverted index of the
+// geospatial column on the right side of the join condition, if any, so that
+// all expressions in the same group have consistent estimates.
 func (sb *statisticsBuilder) selectivityFromInvertedJoinCondition(
-	e RelExpr, s *props.Statistics,
+	e RelExpr, h *joinPropsHelper,
 ) (selectivity float64) {
+	if join, ok := e.(*InvertedJoinExpr); ok {
+		idx := sb.md.Table(join.Table).Index(join.Index)
+		invCol := join.Table.ColumnID(idx.Column(0).Ordinal())
+		return sb.selectivityFromInvertedIndexKeys(e, join.Table, invCol)
+	}
+	for i := range h.filters {
+		cond := h.filters[i].Condition
+		if !isGeoIndexJoinCond(cond) {
+			continue
+		}
+		fn := cond.(*FunctionExpr)
+		for _, arg := range fn.Args[:2] {
+			col := arg.(*VariableExpr).Col
+			if !h.rightProps.OutputCols.Contains(col) {
+				continue
+			}
+			tabID := sb.md.ColumnMeta(col).Table
+			if tabID == 0 {
+				continue
+			}
+			tab := sb.md.Table(tabID)
+			for j, n := 0, tab.IndexCount(); j < n; j++ {
+				idx := tab.Index(j)
+				if idx.IsInverted() &&
+					tabID.ColumnID(idx.Column(0).InvertedSourceColumnOrdinal()) == col {
+					invCol := tabID.ColumnID(idx.Column(0).Ordinal())
+					return sb.selectivityFromInvertedIndexKeys(e, tabID, invCol)
+				}
+			}
+		}
+	}
 	return unknownInvertedJoinSelectivity
 }
 
+// selectivityFromInvertedIndexKeys returns the fraction of the rows of the
+// given table which match an average key of the given inverted index column,
+// according to its histogram.
+func (sb *statisticsBuilder) selectivityFromInvertedIndexKeys(
+	e RelExpr, tabID opt.TableID, invCol opt.ColumnID,
+) (selectivity float64) {
+	invCols := opt.MakeColSet(invCol)
+	if !sb.shouldUseHistogram(e.Relational(), invCols) {
+		return unknownInvertedJoinSelectivity
+	}
+	hist := sb.colStatTable(tabID, invCols).Histogram
+	if hist == nil {
+		return unknownInvertedJoinSelectivity
+	}
+	distinctCount := hist.DistinctValuesCount()
+	tableRowCount := sb.makeTableStatistics(tabID).RowCount
+	if distinctCount == 0 || tableRowCount == 0 {
+		return unknownInvertedJoinSelectivity
+	}
+	rowsPerKey := hist.ValuesCount() / distinctCount
+	return max(min(rowsPerKey/tableRowCount, 1), epsilon)
+}
+
 func (sb *statisticsBuilder) selectivityFromUnappliedConjuncts(
 	numUnappliedConjuncts float64,
 ) (selectivity float64) {
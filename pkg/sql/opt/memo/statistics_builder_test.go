@@ -15,7 +15,9 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils/testcat"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -231,6 +233,65 @@ func TestGetStatsFromConstraint(t *testing.T) {
 	)
 }
 
+// Test estimating the number of rows which satisfy an inverted expression
+// using the histogram of the inverted index keys.
+func TestInvertedExprRowCount(t *testing.T) {
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+
+	// There are 100 rows in the table, and 40, 30 and 50 of them have the keys
+	// a, b and c, respectively.
+	hist := &props.Histogram{}
+	hist.Init(&evalCtx, opt.ColumnID(1), []cat.HistogramBucket{
+		{NumEq: 40, UpperBound: tree.NewDBytes("a")},
+		{NumEq: 30, UpperBound: tree.NewDBytes("b")},
+		{NumEq: 50, UpperBound: tree.NewDBytes("c")},
+	})
+	const tableRowCount = 100
+
+	span := func(key string) invertedexpr.InvertedSpans {
+		return invertedexpr.InvertedSpans{
+			invertedexpr.MakeSingleInvertedValSpan(invertedexpr.EncInvertedVal(key)),
+		}
+	}
+	for _, tc := range []struct {
+		expr     invertedexpr.InvertedExpression
+		expected float64
+	}{
+		{
+			expr:     &invertedexpr.SpanExpression{FactoredUnionSpans: span("a")},
+			expected: 40,
+		},
+		{
+			expr: &invertedexpr.SpanExpression{
+				Operator: invertedexpr.SetUnion,
+				Left:     &invertedexpr.SpanExpression{FactoredUnionSpans: span("a")},
+				Right:    &invertedexpr.SpanExpression{FactoredUnionSpans: span("c")},
+			},
+			expected: 90,
+		},
+		{
+			expr: &invertedexpr.SpanExpression{
+				Operator: invertedexpr.SetIntersection,
+				Left:     &invertedexpr.SpanExpression{FactoredUnionSpans: span("a")},
+				Right:    &invertedexpr.SpanExpression{FactoredUnionSpans: span("b")},
+			},
+			expected: 12,
+		},
+		{
+			expr:     &invertedexpr.SpanExpression{FactoredUnionSpans: span("d")},
+			expected: 0,
+		},
+		{
+			expr:     invertedexpr.NonInvertedColExpression{},
+			expected: tableRowCount,
+		},
+	} {
+		if actual := invertedExprRowCount(hist, tc.expr, tableRowCount); actual != tc.expected {
+			t.Errorf("%v: expected %v, got %v", tc.expr, tc.expected, actual)
+		}
+	}
+}
+
 func testStats(t *testing.T, s *props.Statistics, expectedStats string) {
 	t.Helper()
 
@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 	"math/bits"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
@@ -455,6 +456,27 @@ func (md *Metadata) DuplicateTable(
 		}
 	}
 
+	// Create new synthetic columns for the expressions of statistics, and remap
+	// the column IDs in each ScalarExpr. The expressions are visited in sorted
+	// order so that the new column IDs are deterministic.
+	var exprStatistics map[string]ExprStatistic
+	if len(tabMeta.ExprStatistics) > 0 {
+		exprStrs := make([]string, 0, len(tabMeta.ExprStatistics))
+		for exprStr := range tabMeta.ExprStatistics {
+			exprStrs = append(exprStrs, exprStr)
+		}
+		sort.Strings(exprStrs)
+		exprStatistics = make(map[string]ExprStatistic, len(exprStrs))
+		for _, exprStr := range exprStrs {
+			exprStat := tabMeta.ExprStatistics[exprStr]
+			col := md.ColumnMeta(exprStat.Col)
+			exprStatistics[exprStr] = ExprStatistic{
+				Col:  md.AddColumn(col.Alias, col.Type),
+				Expr: remapColumnIDs(exprStat.Expr, colMap),
+			}
+		}
+	}
+
 	md.tables = append(md.tables, TableMeta{
 		MetaID:                 newTabID,
 		Table:                  tabMeta.Table,
@@ -463,6 +485,7 @@ func (md *Metadata) DuplicateTable(
 		Constraints:            constraints,
 		ComputedCols:           computedCols,
 		PartialIndexPredicates: partialIndexPredicates,
+		ExprStatistics:         exprStatistics,
 	})
 
 	return newTabID
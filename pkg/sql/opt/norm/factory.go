@@ -258,6 +258,31 @@ func (f *Factory) AssignPlaceholders(from *memo.Memo) (err error) {
 	return nil
 }
 
+// CopyWithoutAssigningPlaceholders makes a copy of the given memo in which the
+// placeholders are left in place. It is used to build the generic plan of a
+// prepared statement, which is optimized once and evaluates the placeholders
+// during each execution.
+func (f *Factory) CopyWithoutAssigningPlaceholders(from *memo.Memo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			// See AssignPlaceholders.
+			if ok, e := errorutil.ShouldCatch(r); ok {
+				err = e
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	var replaceFn ReplaceFunc
+	replaceFn = func(e opt.Expr) opt.Expr {
+		return f.CopyAndReplaceDefault(e, replaceFn)
+	}
+	f.CopyAndReplace(from.RootExpr().(memo.RelExpr), from.RootProps(), replaceFn)
+
+	return nil
+}
+
 // onConstructRelational is called as a final step by each factory method that
 // constructs a relational expression, so that any custom manual pattern
 // matching/replacement code can be run.
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils/testcat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 )
 
@@ -158,3 +159,48 @@ func TestCopyAndReplaceWithScan(t *testing.T) {
 		})
 	}
 }
+
+// Test CopyWithoutAssigningPlaceholders, which is used to build the generic
+// plan of a prepared statement. The placeholders must be left in place, so
+// that the optimizer can only use the index by generating a lookup join from
+// the placeholder values.
+func TestCopyWithoutAssigningPlaceholders(t *testing.T) {
+	cat := testcat.New()
+	if _, err := cat.ExecuteDDL("CREATE TABLE ab (a INT PRIMARY KEY, b INT)"); err != nil {
+		t.Fatal(err)
+	}
+
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	evalCtx.SessionData.PlanCacheMode = sessiondata.PlanCacheModeForceGeneric
+
+	var o xform.Optimizer
+	testutils.BuildQuery(t, &o, cat, &evalCtx, "SELECT b FROM ab WHERE a = $1")
+	m := o.Factory().DetachMemo()
+
+	o.Init(&evalCtx, cat)
+	if err := o.Factory().CopyWithoutAssigningPlaceholders(m); err != nil {
+		t.Fatal(err)
+	}
+	if !o.Memo().HasPlaceholders() {
+		t.Errorf("expected the copied memo to have placeholders")
+	}
+
+	e, err := o.Optimize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for e.Op() == opt.ProjectOp {
+		e = e.Child(0)
+	}
+	if e.Op() != opt.LookupJoinOp {
+		t.Errorf("expected optimizer to choose lookup-join, not %v", e.Op())
+	}
+
+	// The original memo must not be modified.
+	if m.IsOptimized() {
+		t.Errorf("expected the original memo to be unoptimized")
+	}
+	if !m.HasPlaceholders() {
+		t.Errorf("expected the original memo to have placeholders")
+	}
+}
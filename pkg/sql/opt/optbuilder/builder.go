@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/planhints"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/optgen/exprgen"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -66,6 +67,11 @@ type Builder struct {
 	// This is used when re-preparing invalidated queries.
 	KeepPlaceholders bool
 
+	// Hints is a control knob: if set, the plan hints are applied to the data
+	// sources and the joins they reference (see package planhints). The hints
+	// which could not be applied are returned by UnappliedHints.
+	Hints *planhints.Hints
+
 	// -- Results --
 	//
 	// These fields are set during the building process and can be used after
@@ -127,6 +133,9 @@ type Builder struct {
 	// isCorrelated is set to true if we already reported to telemetry that the
 	// query contains a correlated subquery.
 	isCorrelated bool
+
+	// applied records which of the Hints have been applied.
+	applied appliedHints
 }
 
 // New creates a new Builder structure initialized with the given
@@ -78,6 +78,9 @@ func (b *Builder) buildJoin(
 			pgcode.FeatureNotSupported, "join hint %s not supported", join.Hint,
 		))
 	}
+	if flags.Empty() {
+		flags = b.joinHintFlags(joinType, leftScope, rightScope)
+	}
 
 	switch cond := join.Cond.(type) {
 	case tree.NaturalJoinCond, *tree.UsingJoinCond:
@@ -0,0 +1,193 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/planhints"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// appliedHints records which of the plan hints given to the builder matched a
+// data source or a join of the statement.
+type appliedHints struct {
+	scans   []bool
+	joins   []bool
+	leading bool
+}
+
+// scanHintFlags returns the index flags implementing the scan hint for the
+// given table, which is referenced under the given alias. It returns nil if
+// there is no such hint, or if the hinted index does not exist.
+func (b *Builder) scanHintFlags(tab cat.Table, alias tree.Name) *tree.IndexFlags {
+	if b.Hints == nil {
+		return nil
+	}
+	hint, ord, ok := b.Hints.ScanHint(string(alias))
+	if !ok {
+		return nil
+	}
+	var flags tree.IndexFlags
+	switch hint.Method {
+	case planhints.SeqScan:
+		flags.IndexID = tree.IndexID(tab.Index(cat.PrimaryIndex).ID())
+
+	case planhints.IndexScan:
+		found := false
+		for i := 0; i < tab.IndexCount(); i++ {
+			if string(tab.Index(i).Name()) == hint.Index {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+		flags.Index = tree.UnrestrictedName(hint.Index)
+
+	case planhints.IndexOnlyScan:
+		flags.NoIndexJoin = true
+	}
+	if b.applied.scans == nil {
+		b.applied.scans = make([]bool, len(b.Hints.Scans))
+	}
+	b.applied.scans[ord] = true
+	return &flags
+}
+
+// joinHintFlags returns the join flags implementing the plan hints for the
+// join of the given scopes: the join method hint for the data sources of both
+// sides, and the Leading hint if the join is one of the joins it orders.
+func (b *Builder) joinHintFlags(joinType descpb.JoinType, left, right *scope) memo.JoinFlags {
+	if b.Hints == nil {
+		return 0
+	}
+	var flags memo.JoinFlags
+	leftAliases := scopeAliases(left)
+	rightAliases := scopeAliases(right)
+	if joinType == descpb.InnerJoin && len(rightAliases) == 1 &&
+		b.Hints.LeadingNext(leftAliases, rightAliases[0]) {
+		flags |= memo.PreserveJoinOrder
+		if len(leftAliases)+1 == len(b.Hints.Leading) {
+			b.applied.leading = true
+		}
+	}
+
+	aliases := append(leftAliases, rightAliases...)
+	for i := range b.Hints.Joins {
+		hint := &b.Hints.Joins[i]
+		if !hint.Matches(aliases) {
+			continue
+		}
+		switch hint.Method {
+		case planhints.HashJoin:
+			flags |= memo.AllowOnlyHashJoinStoreRight
+
+		case planhints.MergeJoin:
+			flags |= memo.AllowOnlyMergeJoin
+
+		case planhints.LookupJoin:
+			if joinType != descpb.InnerJoin && joinType != descpb.LeftOuterJoin {
+				return flags
+			}
+			flags |= memo.AllowOnlyLookupJoinIntoRight
+		}
+		if b.applied.joins == nil {
+			b.applied.joins = make([]bool, len(b.Hints.Joins))
+		}
+		b.applied.joins[i] = true
+		break
+	}
+	return flags
+}
+
+// leadingOrder returns the given FROM tables in the order of the Leading plan
+// hint, if it references exactly their aliases.
+func (b *Builder) leadingOrder(tables tree.TableExprs) (tree.TableExprs, bool) {
+	if b.Hints == nil || len(b.Hints.Leading) != len(tables) {
+		return nil, false
+	}
+	ordered := make(tree.TableExprs, len(tables))
+	for _, t := range tables {
+		source, ok := t.(*tree.AliasedTableExpr)
+		if !ok {
+			return nil, false
+		}
+		alias := source.As.Alias
+		if alias == "" {
+			tn, ok := source.Expr.(*tree.TableName)
+			if !ok {
+				return nil, false
+			}
+			alias = tn.ObjectName
+		}
+		pos := -1
+		for i, name := range b.Hints.Leading {
+			if name == string(alias) {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 || ordered[pos] != nil {
+			return nil, false
+		}
+		ordered[pos] = t
+	}
+	return ordered, true
+}
+
+// UnappliedHints returns the plan hints which did not match any data source or
+// join of the statement. It can only be called after Build.
+func (b *Builder) UnappliedHints() []string {
+	if b.Hints == nil {
+		return nil
+	}
+	var res []string
+	if len(b.Hints.Leading) != 0 && !b.applied.leading {
+		res = append(res, b.Hints.LeadingString())
+	}
+	for i := range b.Hints.Joins {
+		if b.applied.joins == nil || !b.applied.joins[i] {
+			res = append(res, b.Hints.Joins[i].String())
+		}
+	}
+	for i := range b.Hints.Scans {
+		if b.applied.scans == nil || !b.applied.scans[i] {
+			res = append(res, b.Hints.Scans[i].String())
+		}
+	}
+	return res
+}
+
+// scopeAliases returns the distinct names of the data sources of the columns
+// of the given scope, which are matched against the aliases of the join hints.
+func scopeAliases(s *scope) []string {
+	var aliases []string
+	for i := range s.cols {
+		name := string(s.cols[i].table.ObjectName)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, alias := range aliases {
+			if alias == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			aliases = append(aliases, name)
+		}
+	}
+	return aliases
+}
@@ -13,6 +13,7 @@ package optbuilder
 import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
@@ -24,7 +25,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 )
 
@@ -56,7 +59,16 @@ func (b *Builder) buildDataSource(
 			locking = locking.filter(source.As.Alias)
 		}
 
-		outScope = b.buildDataSource(source.Expr, indexFlags, locking, inScope)
+		if tn, ok := source.Expr.(*tree.TableName); ok && b.Hints != nil && indexFlags == nil {
+			// Scan hints reference the table by its alias, if it has one.
+			hintAlias := source.As.Alias
+			if hintAlias == "" {
+				hintAlias = tn.ObjectName
+			}
+			outScope = b.buildTableName(tn, nil /* indexFlags */, hintAlias, locking, inScope)
+		} else {
+			outScope = b.buildDataSource(source.Expr, indexFlags, locking, inScope)
+		}
 
 		if source.Ordinality {
 			outScope = b.buildWithOrdinality("ordinality", outScope)
@@ -71,65 +83,7 @@ func (b *Builder) buildDataSource(
 		return b.buildJoin(source, locking, inScope)
 
 	case *tree.TableName:
-		tn := source
-
-		// CTEs take precedence over other data sources.
-		if cte := inScope.resolveCTE(tn); cte != nil {
-			locking.ignoreLockingForCTE()
-			outScope = inScope.push()
-			inCols := make(opt.ColList, len(cte.cols))
-			outCols := make(opt.ColList, len(cte.cols))
-			outScope.cols = nil
-			for i, col := range cte.cols {
-				id := col.ID
-				c := b.factory.Metadata().ColumnMeta(id)
-				newCol := b.synthesizeColumn(outScope, col.Alias, c.Type, nil, nil)
-				newCol.table = *tn
-				inCols[i] = id
-				outCols[i] = newCol.id
-			}
-
-			outScope.expr = b.factory.ConstructWithScan(&memo.WithScanPrivate{
-				With:    cte.id,
-				Name:    string(cte.name.Alias),
-				InCols:  inCols,
-				OutCols: outCols,
-				ID:      b.factory.Metadata().NextUniqueID(),
-			})
-
-			return outScope
-		}
-
-		priv := privilege.SELECT
-		locking = locking.filter(tn.ObjectName)
-		if locking.isSet() {
-			// SELECT ... FOR [KEY] UPDATE/SHARE requires UPDATE privileges.
-			priv = privilege.UPDATE
-		}
-
-		ds, resName := b.resolveDataSource(tn, priv)
-		switch t := ds.(type) {
-		case cat.Table:
-			tabMeta := b.addTable(t, &resName)
-			return b.buildScan(
-				tabMeta,
-				tableOrdinals(t, columnKinds{
-					includeMutations: false,
-					includeSystem:    true,
-					includeVirtual:   false,
-				}),
-				indexFlags, locking, inScope,
-			)
-
-		case cat.Sequence:
-			return b.buildSequenceSelect(t, &resName, inScope)
-
-		case cat.View:
-			return b.buildView(t, &resName, locking, inScope)
-
-		default:
-			panic(errors.AssertionFailedf("unknown DataSource type %T", ds))
-		}
+		return b.buildTableName(source, indexFlags, "" /* hintAlias */, locking, inScope)
 
 	case *tree.ParenTableExpr:
 		return b.buildDataSource(source.Expr, indexFlags, locking, inScope)
@@ -239,6 +193,81 @@ func (b *Builder) buildDataSource(
 	}
 }
 
+// buildTableName builds a data source referenced by name. If hintAlias is set,
+// the scan hint for that alias is applied to the table it resolves to, if any;
+// indexFlags must be nil in that case.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildTableName(
+	tn *tree.TableName,
+	indexFlags *tree.IndexFlags,
+	hintAlias tree.Name,
+	locking lockingSpec,
+	inScope *scope,
+) (outScope *scope) {
+	// CTEs take precedence over other data sources.
+	if cte := inScope.resolveCTE(tn); cte != nil {
+		locking.ignoreLockingForCTE()
+		outScope = inScope.push()
+		inCols := make(opt.ColList, len(cte.cols))
+		outCols := make(opt.ColList, len(cte.cols))
+		outScope.cols = nil
+		for i, col := range cte.cols {
+			id := col.ID
+			c := b.factory.Metadata().ColumnMeta(id)
+			newCol := b.synthesizeColumn(outScope, col.Alias, c.Type, nil, nil)
+			newCol.table = *tn
+			inCols[i] = id
+			outCols[i] = newCol.id
+		}
+
+		outScope.expr = b.factory.ConstructWithScan(&memo.WithScanPrivate{
+			With:    cte.id,
+			Name:    string(cte.name.Alias),
+			InCols:  inCols,
+			OutCols: outCols,
+			ID:      b.factory.Metadata().NextUniqueID(),
+		})
+
+		return outScope
+	}
+
+	priv := privilege.SELECT
+	locking = locking.filter(tn.ObjectName)
+	if locking.isSet() {
+		// SELECT ... FOR [KEY] UPDATE/SHARE requires UPDATE privileges.
+		priv = privilege.UPDATE
+	}
+
+	ds, resName := b.resolveDataSource(tn, priv)
+	switch t := ds.(type) {
+	case cat.Table:
+		tabMeta := b.addTable(t, &resName)
+		if hintAlias != "" {
+			indexFlags = b.scanHintFlags(t, hintAlias)
+		}
+		return b.buildScan(
+			tabMeta,
+			tableOrdinals(t, columnKinds{
+				includeMutations: false,
+				includeSystem:    true,
+				includeVirtual:   false,
+			}),
+			indexFlags, locking, inScope,
+		)
+
+	case cat.Sequence:
+		return b.buildSequenceSelect(t, &resName, inScope)
+
+	case cat.View:
+		return b.buildView(t, &resName, locking, inScope)
+
+	default:
+		panic(errors.AssertionFailedf("unknown DataSource type %T", ds))
+	}
+}
+
 // buildView parses the view query text and builds it as a Select expression.
 func (b *Builder) buildView(
 	view cat.View, viewName *tree.TableName, locking lockingSpec, inScope *scope,
@@ -518,6 +547,7 @@ func (b *Builder) buildScan(
 		b.addCheckConstraintsForTable(tabMeta)
 		b.addComputedColsForTable(tabMeta)
 		b.addPartialIndexPredicatesForTable(tabMeta)
+		b.addExprStatisticsForTable(tabMeta)
 
 		outScope.expr = b.factory.ConstructScan(&private)
 
@@ -705,6 +735,86 @@ func (b *Builder) addPartialIndexPredicatesForTable(tabMeta *opt.TableMeta) {
 	}
 }
 
+// addExprStatisticsForTable finds all table statistics which were collected
+// on expressions and adds the expressions to the table metadata (see
+// TableMeta.ExprStatistics), along with a synthetic column for each one. The
+// expressions are converted from strings to ScalarExprs here.
+//
+// Statistics that are stale, because their expression no longer parses or
+// type-checks against the table (e.g. a column it references was dropped) or
+// because its type doesn't match the type of the histogram of the statistic
+// (which is possible if the type of a column was changed since the statistic
+// was calculated), are omitted.
+func (b *Builder) addExprStatisticsForTable(tabMeta *opt.TableMeta) {
+	var tableScope *scope
+	tab := tabMeta.Table
+	md := b.factory.Metadata()
+	for i, n := 0, tab.StatisticCount(); i < n; i++ {
+		stat := tab.Statistic(i)
+		exprStr := stat.Expression()
+		if exprStr == "" {
+			continue
+		}
+		if _, ok := tabMeta.ExprStatistics[exprStr]; ok {
+			continue
+		}
+		expr, err := parser.ParseExpr(exprStr)
+		if err != nil {
+			log.VEventf(b.ctx, 2, "skipping statistic on expression %q: %v", exprStr, err)
+			continue
+		}
+
+		if tableScope == nil {
+			tableScope = b.allocScope()
+			tableScope.appendOrdinaryColumnsFromTable(tabMeta, &tabMeta.Alias)
+		}
+
+		texpr, err := b.resolveExprStatistic(tableScope, expr)
+		if err != nil {
+			log.VEventf(b.ctx, 2, "skipping statistic on expression %q: %v", exprStr, err)
+			continue
+		}
+		typ := texpr.ResolvedType()
+		if hist := stat.Histogram(); len(hist) > 0 && !hist[0].UpperBound.ResolvedType().Equivalent(typ) {
+			continue
+		}
+		var scalar opt.ScalarExpr
+		b.factory.FoldingControl().TemporarilyDisallowStableFolds(func() {
+			scalar = b.buildScalar(texpr, tableScope, nil, nil, nil)
+		})
+		// Different expression strings can build the same scalar expression;
+		// only the most recent statistic is used for each expression.
+		duplicate := false
+		for _, exprStat := range tabMeta.ExprStatistics {
+			if exprStat.Expr == scalar {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			tabMeta.AddExprStatistic(exprStr, md.AddColumn(exprStr, typ), scalar)
+		}
+	}
+}
+
+// resolveExprStatistic resolves and type-checks the expression of a statistic
+// against the columns of its table. Errors are returned rather than raised, so
+// that a stale statistic doesn't prevent the query from being planned.
+func (b *Builder) resolveExprStatistic(
+	tableScope *scope, expr tree.Expr,
+) (texpr tree.TypedExpr, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if ok, e := errorutil.ShouldCatch(r); ok {
+				err = e
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	return tableScope.resolveAndRequireType(expr, types.Any), nil
+}
+
 func (b *Builder) buildSequenceSelect(
 	seq cat.Sequence, seqName *tree.TableName, inScope *scope,
 ) (outScope *scope) {
@@ -1173,9 +1283,55 @@ func (b *Builder) buildFromTables(
 			return b.buildFromWithLateral(tables, locking, inScope)
 		}
 	}
+	if ordered, ok := b.leadingOrder(tables); ok {
+		return b.buildFromTablesLeading(tables, ordered, locking, inScope)
+	}
 	return b.buildFromTablesRightDeep(tables, locking, inScope)
 }
 
+// buildFromTablesLeading builds a left-deep series of InnerJoin expressions
+// that join together the given FROM tables in the order of the Leading plan
+// hint (see leadingOrder). The joins are flagged so that the optimizer does
+// not reorder them. The output columns are still in the order of the FROM
+// list.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildFromTablesLeading(
+	tables, ordered tree.TableExprs, locking lockingSpec, inScope *scope,
+) (outScope *scope) {
+	tableScopes := make(map[tree.TableExpr]*scope, len(tables))
+	for _, t := range tables {
+		tableScopes[t] = b.buildDataSource(t, nil /* indexFlags */, locking, inScope)
+	}
+
+	joinScope := inScope.push()
+	joinScope.appendColumnsFromScope(tableScopes[ordered[0]])
+	joinScope.expr = tableScopes[ordered[0]].expr
+	for _, t := range ordered[1:] {
+		tableScope := tableScopes[t]
+
+		// Check that the same table name is not used multiple times.
+		b.validateJoinTableNames(joinScope, tableScope)
+
+		flags := b.joinHintFlags(descpb.InnerJoin, joinScope, tableScope)
+		joinScope.appendColumnsFromScope(tableScope)
+
+		left := joinScope.expr.(memo.RelExpr)
+		right := tableScope.expr.(memo.RelExpr)
+		joinScope.expr = b.factory.ConstructInnerJoin(
+			left, right, memo.TrueFilter, &memo.JoinPrivate{Flags: flags},
+		)
+	}
+
+	outScope = inScope.push()
+	for _, t := range tables {
+		outScope.appendColumnsFromScope(tableScopes[t])
+	}
+	outScope.expr = joinScope.expr
+	return outScope
+}
+
 // buildFromTablesRightDeep recursively builds a series of InnerJoin
 // expressions that join together the given FROM tables. The tables are joined
 // in the reverse order that they appear in the list, with the innermost join
@@ -1208,11 +1364,16 @@ func (b *Builder) buildFromTablesRightDeep(
 	// Check that the same table name is not used multiple times.
 	b.validateJoinTableNames(outScope, tableScope)
 
+	private := memo.EmptyJoinPrivate
+	if flags := b.joinHintFlags(descpb.InnerJoin, outScope, tableScope); !flags.Empty() {
+		private = &memo.JoinPrivate{Flags: flags}
+	}
+
 	outScope.appendColumnsFromScope(tableScope)
 
 	left := outScope.expr.(memo.RelExpr)
 	right := tableScope.expr.(memo.RelExpr)
-	outScope.expr = b.factory.ConstructInnerJoin(left, right, memo.TrueFilter, memo.EmptyJoinPrivate)
+	outScope.expr = b.factory.ConstructInnerJoin(left, right, memo.TrueFilter, private)
 	return outScope
 }
 
@@ -73,6 +73,9 @@ func ScanPrivateCanProvide(
 		if s.HardLimit.Reverse() {
 			direction = rev
 		}
+	} else if s.IsSkipScan() {
+		// Skip scans are only executed in the forward direction.
+		direction = fwd
 	} else if s.Flags.Direction != 0 {
 		direction = fwd
 		if s.Flags.Direction == tree.Descending {
@@ -162,6 +162,14 @@ type TableMeta struct {
 	// the map.
 	PartialIndexPredicates map[cat.IndexOrdinal]ScalarExpr
 
+	// ExprStatistics is a map from the expressions of the table statistics
+	// which were collected on expressions (see cat.TableStatistic.Expression)
+	// to the scalar expressions built from them. Each expression is associated
+	// with a synthetic column which represents its values, and which the
+	// statistic describes. These are used to estimate the selectivity of
+	// filters on the expressions.
+	ExprStatistics map[string]ExprStatistic
+
 	// anns annotates the table metadata with arbitrary data.
 	anns [maxTableAnnIDCount]interface{}
 }
@@ -243,6 +251,27 @@ func (tm *TableMeta) AddPartialIndexPredicate(ord cat.IndexOrdinal, pred ScalarE
 	tm.PartialIndexPredicates[ord] = pred
 }
 
+// AddExprStatistic adds the expression of a statistic collected on an
+// expression to the table's metadata, along with the synthetic column which
+// represents its values.
+func (tm *TableMeta) AddExprStatistic(exprStr string, col ColumnID, expr ScalarExpr) {
+	if tm.ExprStatistics == nil {
+		tm.ExprStatistics = make(map[string]ExprStatistic)
+	}
+	tm.ExprStatistics[exprStr] = ExprStatistic{Col: col, Expr: expr}
+}
+
+// ExprStatistic is an expression over the columns of a table on which a table
+// statistic was collected. See TableMeta.ExprStatistics.
+type ExprStatistic struct {
+	// Col is the synthetic column which represents the values of the
+	// expression.
+	Col ColumnID
+
+	// Expr is the scalar expression over the columns of the table.
+	Expr ScalarExpr
+}
+
 // TableAnnotation returns the given annotation that is associated with the
 // given table. If the table has no such annotation, TableAnnotation returns
 // nil.
@@ -43,6 +43,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -164,6 +165,13 @@ type Flags struct {
 	// SessionData.PreferLookupJoinsForFKs.
 	PreferLookupJoinsForFKs bool
 
+	// EagerAggregation is the default value for
+	// SessionData.EagerAggregationEnabled.
+	EagerAggregation bool
+
+	// PlanCacheMode is the default value for SessionData.PlanCacheMode.
+	PlanCacheMode sessiondata.PlanCacheMode
+
 	// Locality specifies the location of the planning node as a set of user-
 	// defined key/value pairs, ordered from most inclusive to least inclusive.
 	// If there are no tiers, then the node's location is not known. Examples:
@@ -355,6 +363,13 @@ func New(catalog cat.Catalog, sql string) *OptTester {
 //    expression in the query tree for the purpose of creating alternate query
 //    plans in the optimizer.
 //
+//  - eager-aggregation: enables eager aggregation, which allows the
+//    optimizer to push aggregations into the inputs of joins.
+//
+//  - plan-cache-mode: sets the plan_cache_mode session setting. With
+//    force_generic_plan or auto, the exploration rules that only apply to
+//    generic plans of prepared statements are enabled.
+//
 //  - locality: used to set the locality of the node that plans the query. This
 //    can affect costing when there are multiple possible indexes to choose
 //    from, each in different localities.
@@ -397,6 +412,8 @@ func (ot *OptTester) RunCommand(tb testing.TB, d *datadriven.TestData) string {
 
 	ot.evalCtx.SessionData.ReorderJoinsLimit = ot.Flags.JoinLimit
 	ot.evalCtx.SessionData.PreferLookupJoinsForFKs = ot.Flags.PreferLookupJoinsForFKs
+	ot.evalCtx.SessionData.EagerAggregationEnabled = ot.Flags.EagerAggregation
+	ot.evalCtx.SessionData.PlanCacheMode = ot.Flags.PlanCacheMode
 
 	ot.Flags.Verbose = datadriven.Verbose()
 	ot.evalCtx.TestingKnobs.OptimizerCostPerturbation = ot.Flags.PerturbCost
@@ -711,6 +728,19 @@ func (f *Flags) Set(arg datadriven.CmdArg) error {
 	case "prefer-lookup-joins-for-fks":
 		f.PreferLookupJoinsForFKs = true
 
+	case "eager-aggregation":
+		f.EagerAggregation = true
+
+	case "plan-cache-mode":
+		if len(arg.Vals) != 1 {
+			return fmt.Errorf("plan-cache-mode requires one argument")
+		}
+		mode, ok := sessiondata.PlanCacheModeFromString(arg.Vals[0])
+		if !ok {
+			return fmt.Errorf("invalid plan-cache-mode %s", arg.Vals[0])
+		}
+		f.PlanCacheMode = mode
+
 	case "rule":
 		if len(arg.Vals) != 1 {
 			return fmt.Errorf("rule requires one argument")
@@ -990,6 +990,11 @@ func (ts *TableStat) Histogram() []cat.HistogramBucket {
 	return histogram
 }
 
+// Expression is part of the cat.TableStatistic interface.
+func (ts *TableStat) Expression() string {
+	return ts.js.Expression
+}
+
 // TableStats is a slice of TableStat pointers.
 type TableStats []*TableStat
 
@@ -578,6 +578,20 @@ func (c *coster) computeScanCost(scan *memo.ScanExpr, required *physical.Require
 		numSpans = len(scan.InvertedConstraint)
 	}
 	baseCost := memo.Cost(numSpans * randIOCostFactor)
+	if scan.IsSkipScan() {
+		// A skip scan seeks once to find each distinct prefix, and then once more
+		// for each span of the constraint within that prefix.
+		numPrefixes := rowCount
+		prefixCols := scan.SkipScanPrefixCols(c.mem.Metadata())
+		if colStat, ok := c.mem.RequestTableColStat(scan.Table, prefixCols); ok {
+			numPrefixes = colStat.DistinctCount
+		}
+		seeksPerPrefix := 1
+		if scan.Constraint != nil {
+			seeksPerPrefix += numSpans
+		}
+		baseCost = memo.Cost(numPrefixes) * memo.Cost(seeksPerPrefix*randIOCostFactor)
+	}
 
 	// Add a small cost if the scan is unconstrained, so all else being equal, we
 	// will prefer a constrained scan. This is important if our row count
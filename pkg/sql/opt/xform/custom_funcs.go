@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props/physical"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/errors"
@@ -1204,6 +1205,247 @@ func (c *CustomFuncs) canMaybeConstrainNonInvertedIndex(
 	return false
 }
 
+// skipScanMinRowsPerPrefix is the minimum estimated number of rows per
+// distinct prefix value for a skip scan to be generated. With fewer rows per
+// prefix, the seeks performed by a skip scan cost more than reading the rows
+// they skip over.
+const skipScanMinRowsPerPrefix = 10
+
+// GenerateSkipScans generates a skip scan (also known as a loose index scan)
+// for each index that can be constrained by the filters once a prefix of its
+// columns is skipped. The prefix consists of the leading index columns that
+// are not referenced by the filters, and the constraint is built over the
+// index columns that follow it. For example, given an index on (a, b, c) and
+// the filter b > 1 AND b < 5, the skip scan has a prefix of one column (a) and
+// the constraint /2: [/2 - /4]. At execution time, the constraint is applied
+// within each distinct value of a.
+//
+// Skip scans are only generated when table statistics show that the prefix has
+// few distinct values relative to the number of rows in the table. As in
+// GenerateConstrainedScans, the skip scan is wrapped in a Select if there are
+// remaining filters, and in an IndexJoin if the index is not covering.
+func (c *CustomFuncs) GenerateSkipScans(
+	grp memo.RelExpr, scan memo.RelExpr, scanPrivate *memo.ScanPrivate, filters memo.FiltersExpr,
+) {
+	if !c.canSkipScan(scanPrivate) {
+		return
+	}
+
+	var sb indexScanBuilder
+	sb.init(c, scanPrivate.Table)
+
+	filterColumns := c.FilterOuterCols(filters)
+	iter := makeScanIndexIter(c.e.mem, scanPrivate, rejectInvertedIndexes|rejectPartialIndexes)
+	for iter.Next() {
+		index := iter.Index()
+		if !c.canSkipScanIndex(index) {
+			continue
+		}
+
+		// The prefix consists of the leading index columns that are not
+		// referenced by the filters.
+		prefixLen := 0
+		for prefixLen < index.LaxKeyColumnCount() &&
+			!filterColumns.Contains(scanPrivate.Table.IndexColumnID(index, prefixLen)) {
+			prefixLen++
+		}
+		if prefixLen == 0 || prefixLen == index.LaxKeyColumnCount() {
+			// Either the index can be constrained directly, or the filters don't
+			// reference any of its key columns.
+			continue
+		}
+
+		newScanPrivate := *scanPrivate
+		newScanPrivate.Index = iter.IndexOrdinal()
+		newScanPrivate.SkipScanPrefixLen = prefixLen
+		if !c.skipScanPrefixIsSelective(scan, &newScanPrivate) {
+			continue
+		}
+
+		constraint, remainingFilters, ok := c.tryConstrainIndexSuffix(
+			filters, scanPrivate.Table, iter.IndexOrdinal(), prefixLen,
+		)
+		if !ok {
+			continue
+		}
+		newScanPrivate.Constraint = constraint
+
+		// If the alternate index includes the set of needed columns, then construct
+		// a new Scan operator using that index.
+		if iter.IsCovering() {
+			sb.setScan(&newScanPrivate)
+			sb.addSelect(remainingFilters)
+			sb.build(grp)
+			continue
+		}
+
+		// Otherwise, construct an IndexJoin operator that provides the columns
+		// missing from the index.
+		if scanPrivate.Flags.NoIndexJoin {
+			continue
+		}
+
+		// Scan whatever columns we need which are available from the index, plus
+		// the PK columns.
+		newScanPrivate.Cols = iter.IndexColumns().Intersection(scanPrivate.Cols)
+		newScanPrivate.Cols.UnionWith(sb.primaryKeyCols())
+		sb.setScan(&newScanPrivate)
+
+		remainingFilters = sb.addSelectAfterSplit(remainingFilters, newScanPrivate.Cols)
+		sb.addIndexJoin(scanPrivate.Cols)
+		sb.addSelect(remainingFilters)
+
+		sb.build(grp)
+	}
+}
+
+// canSkipScan returns true if a skip scan can be used to read from the table
+// of the given ScanPrivate. Virtual tables do not support seeking, and locking
+// scans are not supported by the skip scan reader.
+func (c *CustomFuncs) canSkipScan(scanPrivate *memo.ScanPrivate) bool {
+	tab := c.e.mem.Metadata().Table(scanPrivate.Table)
+	return !tab.IsVirtualTable() && !scanPrivate.IsLocking()
+}
+
+// canSkipScanIndex returns true if a skip scan can be used to read from the
+// given index. Interleaved indexes are not supported, since the rows of other
+// tables can be found between the distinct prefixes.
+func (c *CustomFuncs) canSkipScanIndex(index cat.Index) bool {
+	return index.InterleaveAncestorCount() == 0 && index.InterleavedByCount() == 0
+}
+
+// skipScanPrefixIsSelective returns true if the table statistics show that the
+// skip scan prefix of the given ScanPrivate has few enough distinct values for
+// a skip scan to be worthwhile. The given scan is the original, unconstrained
+// scan of the table.
+func (c *CustomFuncs) skipScanPrefixIsSelective(
+	scan memo.RelExpr, scanPrivate *memo.ScanPrivate,
+) bool {
+	prefixCols := scanPrivate.SkipScanPrefixCols(c.e.mem.Metadata())
+	colStat, ok := c.e.mem.RequestTableColStat(scanPrivate.Table, prefixCols)
+	if !ok {
+		return false
+	}
+	return colStat.DistinctCount*skipScanMinRowsPerPrefix <= scan.Relational().Stats.RowCount
+}
+
+// tryConstrainIndexSuffix tries to derive a constraint from the given filters
+// for the key columns of the given index that follow the first prefixLen
+// columns. If a constraint is derived, it is returned along with any filter
+// remaining after extracting the constraint. If no constraint can be derived,
+// then tryConstrainIndexSuffix returns ok = false.
+func (c *CustomFuncs) tryConstrainIndexSuffix(
+	filters memo.FiltersExpr, tabID opt.TableID, indexOrd int, prefixLen int,
+) (constraint *constraint.Constraint, remainingFilters memo.FiltersExpr, ok bool) {
+	md := c.e.mem.Metadata()
+	index := md.Table(tabID).Index(indexOrd)
+	columns := make([]opt.OrderingColumn, index.LaxKeyColumnCount()-prefixLen)
+	var notNullCols opt.ColSet
+	for i := range columns {
+		col := index.Column(prefixLen + i)
+		colID := tabID.ColumnID(col.Ordinal())
+		columns[i] = opt.MakeOrderingColumn(colID, col.Descending)
+		if !col.IsNullable() {
+			notNullCols.Add(colID)
+		}
+	}
+
+	var ic idxconstraint.Instance
+	ic.Init(filters, nil /* optionalFilters */, columns, notNullCols, false /* isInverted */, c.e.evalCtx, c.e.f)
+	constraint = ic.Constraint()
+	if constraint.IsUnconstrained() || constraint.IsContradiction() {
+		return nil, nil, false
+	}
+
+	// Make copy of constraint so that idxconstraint instance is not referenced.
+	copy := *constraint
+	return &copy, ic.RemainingFilters(), true
+}
+
+// GenericRulesEnabled returns true if the optimizer may be building a generic
+// plan for a prepared statement, in which placeholders are not replaced by
+// their values before optimization. See the plan_cache_mode session setting.
+func (c *CustomFuncs) GenericRulesEnabled() bool {
+	return c.e.evalCtx.SessionData.PlanCacheMode != sessiondata.PlanCacheModeForceCustom
+}
+
+// FiltersHavePlaceholders returns true if any of the given filters contains a
+// placeholder.
+func (c *CustomFuncs) FiltersHavePlaceholders(filters memo.FiltersExpr) bool {
+	for i := range filters {
+		if filters[i].ScalarProps().HasPlaceholder {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateParameterizedJoin generates a lookup join into the scanned table
+// from a single-row Values expression holding the placeholders referenced by
+// the filters. The filters are rewritten to refer to the Values columns
+// instead of the placeholders, so that GenerateLookupJoins can use them as
+// lookup conditions. The join is wrapped in a Project that removes the Values
+// columns. See the GenerateParameterizedJoin rule.
+func (c *CustomFuncs) GenerateParameterizedJoin(
+	grp memo.RelExpr, scanPrivate *memo.ScanPrivate, filters memo.FiltersExpr,
+) {
+	md := c.e.mem.Metadata()
+	var placeholders memo.ScalarListExpr
+	var cols opt.ColList
+	placeholderCols := make(map[tree.PlaceholderIdx]opt.ColumnID)
+
+	var replace norm.ReplaceFunc
+	replace = func(e opt.Expr) opt.Expr {
+		switch t := e.(type) {
+		case *memo.PlaceholderExpr:
+			idx := t.Value.(*tree.Placeholder).Idx
+			// Multiple references to the same placeholder share a column.
+			col, ok := placeholderCols[idx]
+			if !ok {
+				col = md.AddColumn(fmt.Sprintf("$%d", idx+1), t.DataType())
+				placeholderCols[idx] = col
+				placeholders = append(placeholders, t)
+				cols = append(cols, col)
+			}
+			return c.e.f.ConstructVariable(col)
+
+		case memo.RelExpr:
+			// Placeholders within subqueries are left in place.
+			return e
+		}
+		return c.e.f.Replace(e, replace)
+	}
+
+	newFilters := make(memo.FiltersExpr, len(filters))
+	for i := range filters {
+		cond := replace(filters[i].Condition).(opt.ScalarExpr)
+		newFilters[i] = c.e.f.ConstructFiltersItem(cond)
+	}
+	if len(placeholders) == 0 {
+		return
+	}
+
+	typs := make([]*types.T, len(placeholders))
+	for i := range placeholders {
+		typs[i] = placeholders[i].DataType()
+	}
+	values := c.e.f.ConstructValues(
+		memo.ScalarListExpr{c.e.f.ConstructTuple(placeholders, types.MakeTuple(typs))},
+		&memo.ValuesPrivate{Cols: cols, ID: md.NextUniqueID()},
+	)
+	join := c.e.f.ConstructInnerJoin(
+		values,
+		c.e.f.ConstructScan(scanPrivate),
+		newFilters,
+		&memo.JoinPrivate{Flags: memo.AllowOnlyLookupJoinIntoRight},
+	)
+	c.e.mem.AddProjectToGroup(&memo.ProjectExpr{
+		Input:       join,
+		Projections: memo.EmptyProjectionsExpr,
+		Passthrough: grp.Relational().OutputCols,
+	}, grp)
+}
+
 // ----------------------------------------------------------------------
 //
 // Limit Rules
@@ -1244,6 +1486,11 @@ func (c *CustomFuncs) CanLimitFilteredScan(
 		return false
 	}
 
+	if scanPrivate.IsSkipScan() {
+		// Skip scans are never limited.
+		return false
+	}
+
 	if scanPrivate.Constraint == nil && !scanPrivate.UsesPartialIndex(c.e.mem.Metadata()) {
 		// This is not a constrained scan nor a partial index scan, so skip it.
 		// The GenerateLimitedScans rule is responsible for limited
@@ -1346,6 +1593,11 @@ func (c *CustomFuncs) ScanIsInverted(sp *memo.ScanPrivate) bool {
 	return idx.IsInverted()
 }
 
+// ScanIsSkipScan returns true if the given ScanPrivate is a skip scan.
+func (c *CustomFuncs) ScanIsSkipScan(sp *memo.ScanPrivate) bool {
+	return sp.IsSkipScan()
+}
+
 // SplitScanIntoUnionScans returns a Union of Scan operators with hard limits
 // that each scan over a single key from the original scan's constraints. This
 // is beneficial in cases where the original scan had to scan over many rows but
@@ -3055,6 +3307,154 @@ func (c *CustomFuncs) GenerateStreamingGroupBy(
 	}
 }
 
+// GenerateDistinctSkipScans generates a skip scan (also known as a loose index
+// scan) for each index whose leading key columns are the grouping columns of
+// the given DistinctOn. The DistinctOn must have no aggregations, and its
+// grouping columns must be the only columns produced by the scan. The skip
+// scan returns the first row for each distinct value of the grouping columns,
+// which is exactly the result of the DistinctOn. See the
+// GenerateDistinctSkipScans rule.
+func (c *CustomFuncs) GenerateDistinctSkipScans(
+	grp memo.RelExpr, scan memo.RelExpr, scanPrivate *memo.ScanPrivate, private *memo.GroupingPrivate,
+) {
+	if !c.canSkipScan(scanPrivate) || !private.GroupingCols.Equals(scanPrivate.Cols) {
+		return
+	}
+
+	prefixLen := private.GroupingCols.Len()
+	iter := makeScanIndexIter(c.e.mem, scanPrivate, rejectInvertedIndexes|rejectPartialIndexes)
+	for iter.Next() {
+		index := iter.Index()
+		if !c.canSkipScanIndex(index) || prefixLen >= index.LaxKeyColumnCount() {
+			// If the grouping columns form the entire key of the index, every row
+			// is distinct and there is nothing to skip.
+			continue
+		}
+
+		var prefixCols opt.ColSet
+		for i := 0; i < prefixLen; i++ {
+			prefixCols.Add(scanPrivate.Table.IndexColumnID(index, i))
+		}
+		if !prefixCols.Equals(private.GroupingCols) {
+			continue
+		}
+
+		newScanPrivate := *scanPrivate
+		newScanPrivate.Index = iter.IndexOrdinal()
+		newScanPrivate.SkipScanPrefixLen = prefixLen
+		if !c.skipScanPrefixIsSelective(scan, &newScanPrivate) {
+			continue
+		}
+		c.e.mem.AddScanToGroup(&memo.ScanExpr{ScanPrivate: newScanPrivate}, grp)
+	}
+}
+
+// CanPushGroupByIntoJoinLeft returns true if the given GroupBy aggregations
+// and grouping can be split into local aggregations over the given left input
+// of an inner join with the given ON condition, and final aggregations over the
+// join. See the PushGroupByIntoJoinLeft rule for more details.
+func (c *CustomFuncs) CanPushGroupByIntoJoinLeft(
+	left memo.RelExpr, on memo.FiltersExpr, aggs memo.AggregationsExpr, private *memo.GroupingPrivate,
+) bool {
+	if !c.e.evalCtx.SessionData.EagerAggregationEnabled {
+		return false
+	}
+
+	leftCols := left.Relational().OutputCols
+	for i := range aggs {
+		agg := aggs[i].Agg
+		if !opt.IsAggregateOp(agg) {
+			// Aggregate can't be an AggFilter or AggDistinct.
+			return false
+		}
+		if !opt.AggregatesCanMerge(agg.Op(), finalAggOp(agg.Op())) {
+			return false
+		}
+		if !c.ExtractAggInputColumns(agg).SubsetOf(leftCols) {
+			return false
+		}
+	}
+
+	localGroupingCols := c.localGroupingCols(leftCols, on, private)
+	if localGroupingCols.Empty() {
+		// A GroupBy with no grouping columns over the left input would behave
+		// differently from the original GroupBy when the join returns no rows.
+		return false
+	}
+
+	// Only push down the aggregation if it would reduce the number of rows of
+	// the left input.
+	return !left.Relational().FuncDeps.ColsAreStrictKey(localGroupingCols)
+}
+
+// PushGroupByIntoJoinLeft splits the given GroupBy aggregations into local
+// aggregations over the left input of the given inner join and final
+// aggregations over the join, and adds the resulting GroupBy to the given
+// group. CanPushGroupByIntoJoinLeft must be true. See the
+// PushGroupByIntoJoinLeft rule for more details.
+func (c *CustomFuncs) PushGroupByIntoJoinLeft(
+	grp memo.RelExpr,
+	left, right memo.RelExpr,
+	on memo.FiltersExpr,
+	joinPrivate *memo.JoinPrivate,
+	aggs memo.AggregationsExpr,
+	private *memo.GroupingPrivate,
+) {
+	md := c.e.mem.Metadata()
+	localAggs := make(memo.AggregationsExpr, len(aggs))
+	finalAggs := make(memo.AggregationsExpr, len(aggs))
+	for i := range aggs {
+		// The local aggregate is the original aggregate with a new output column,
+		// which is aggregated by the final aggregate into the original output
+		// column.
+		agg := aggs[i].Agg
+		localCol := md.AddColumn(md.ColumnMeta(aggs[i].Col).Alias, agg.DataType())
+		localAggs[i] = c.e.f.ConstructAggregationsItem(agg, localCol)
+		finalAgg := c.e.f.DynamicConstruct(
+			finalAggOp(agg.Op()), c.e.f.ConstructVariable(localCol),
+		).(opt.ScalarExpr)
+		finalAggs[i] = c.e.f.ConstructAggregationsItem(finalAgg, aggs[i].Col)
+	}
+
+	localPrivate := &memo.GroupingPrivate{
+		GroupingCols: c.localGroupingCols(left.Relational().OutputCols, on, private),
+	}
+	newJoin := c.e.f.ConstructInnerJoin(
+		c.e.f.ConstructGroupBy(left, localAggs, localPrivate),
+		right,
+		on,
+		joinPrivate,
+	)
+	c.e.mem.AddGroupByToGroup(&memo.GroupByExpr{
+		Input:           newJoin,
+		Aggregations:    finalAggs,
+		GroupingPrivate: *private,
+	}, grp)
+}
+
+// localGroupingCols returns the grouping columns of a GroupBy pushed into the
+// left input of a join with the given ON condition. These are the original
+// grouping columns from the left input, as well as the left columns referenced
+// by the ON condition.
+func (c *CustomFuncs) localGroupingCols(
+	leftCols opt.ColSet, on memo.FiltersExpr, private *memo.GroupingPrivate,
+) opt.ColSet {
+	cols := private.GroupingCols.Union(c.FilterOuterCols(on))
+	return cols.Intersection(leftCols)
+}
+
+// finalAggOp returns the aggregate operator that combines the results of the
+// given aggregate operator when it is computed in local and final phases.
+// Counts are combined by summing them; the other decomposable aggregates are
+// combined with the same aggregate.
+func finalAggOp(localOp opt.Operator) opt.Operator {
+	switch localOp {
+	case opt.CountOp, opt.CountRowsOp:
+		return opt.SumIntOp
+	}
+	return localOp
+}
+
 // OtherAggsAreConst returns true if all items in the given aggregate list
 // contain ConstAgg functions, except for the "except" item. The ConstAgg
 // functions will always return the same value, as long as there is at least
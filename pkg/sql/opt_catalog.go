@@ -29,11 +29,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -397,7 +399,12 @@ func (oc *optCatalog) dataSourceForTable(
 	var tableStats []*stats.TableStatistic
 	if !flags.NoTableStats {
 		var err error
-		tableStats, err = oc.planner.execCfg.TableStatsCache.GetTableStats(context.TODO(), desc.ID)
+		statsCache := oc.planner.execCfg.TableStatsCache
+		if stats.UseStatisticsForecasts.Get(&oc.planner.execCfg.Settings.SV) {
+			tableStats, err = statsCache.GetTableStatsWithForecasts(context.TODO(), desc.ID)
+		} else {
+			tableStats, err = statsCache.GetTableStats(context.TODO(), desc.ID)
+		}
 		if err != nil {
 			// Ignore any error. We still want to be able to run queries even if we lose
 			// access to the statistics table.
@@ -1304,13 +1311,33 @@ func (os *optTableStat) init(tab *optTable, stat *stats.TableStatistic) (ok bool
 		}
 	}
 
+	if stat.Expression != "" {
+		// The expression refers to the columns by name; skip the statistic if it
+		// no longer refers to the same columns (this is possible if a column was
+		// renamed since the statistic was calculated).
+		expr, err := parser.ParseExpr(stat.Expression)
+		if err != nil {
+			return false, nil
+		}
+		colIDs, err := schemaexpr.ExtractColumnIDs(tab.desc, expr)
+		if err != nil || colIDs.Len() != len(stat.ColumnIDs) {
+			return false, nil
+		}
+		for _, c := range stat.ColumnIDs {
+			if !colIDs.Contains(c) {
+				return false, nil
+			}
+		}
+	}
+
 	return true, nil
 }
 
 func (os *optTableStat) equals(other *optTableStat) bool {
 	// Two table statistics are considered equal if they have been created at the
-	// same time, on the same set of columns.
-	if os.CreatedAt() != other.CreatedAt() || len(os.columnOrdinals) != len(other.columnOrdinals) {
+	// same time, on the same set of columns and the same expression.
+	if os.CreatedAt() != other.CreatedAt() || len(os.columnOrdinals) != len(other.columnOrdinals) ||
+		os.Expression() != other.Expression() {
 		return false
 	}
 	for i, c := range os.columnOrdinals {
@@ -1356,6 +1383,11 @@ func (os *optTableStat) Histogram() []cat.HistogramBucket {
 	return os.stat.Histogram
 }
 
+// Expression is part of the cat.TableStatistic interface.
+func (os *optTableStat) Expression() string {
+	return os.stat.Expression
+}
+
 // optFamily is a wrapper around descpb.ColumnFamilyDescriptor that keeps a
 // reference to the table wrapper.
 type optFamily struct {
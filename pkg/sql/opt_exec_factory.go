@@ -107,14 +107,29 @@ func (ef *execFactory) ConstructScan(
 	scan.reverse = params.Reverse
 	scan.parallelize = params.Parallelize
 	var err error
-	scan.spans, err = generateScanSpans(ef.planner.ExecCfg().Codec, tabDesc, indexDesc, params)
-	if err != nil {
-		return nil, err
+	if params.SkipScanPrefixLen > 0 {
+		// A skip scan reads the whole index, seeking past each distinct prefix;
+		// the constraint applies to the columns following the prefix.
+		sb := span.MakeBuilder(ef.planner.ExecCfg().Codec, tabDesc, indexDesc)
+		if scan.spans, err = sb.UnconstrainedSpans(); err != nil {
+			return nil, err
+		}
+		scan.skipScanPrefixLen = params.SkipScanPrefixLen
+		scan.skipScanSuffixSpans, err = sb.SuffixSpansFromConstraint(
+			params.IndexConstraint, params.SkipScanPrefixLen,
+		)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		scan.spans, err = generateScanSpans(ef.planner.ExecCfg().Codec, tabDesc, indexDesc, params)
+		if err != nil {
+			return nil, err
+		}
+		scan.isFull = len(scan.spans) == 1 && scan.spans[0].EqualValue(
+			scan.desc.IndexSpan(ef.planner.ExecCfg().Codec, scan.index.ID),
+		)
 	}
-
-	scan.isFull = len(scan.spans) == 1 && scan.spans[0].EqualValue(
-		scan.desc.IndexSpan(ef.planner.ExecCfg().Codec, scan.index.ID),
-	)
 	if err = colCfg.assertValidReqOrdering(reqOrdering); err != nil {
 		return nil, err
 	}
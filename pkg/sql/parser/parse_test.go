@@ -360,6 +360,8 @@ func TestParse(t *testing.T) {
 		{`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS THROTTLING 0.9`},
 		{`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS AS OF SYSTEM TIME '2016-01-01'`},
 		{`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS THROTTLING 0.1 AS OF SYSTEM TIME '2016-01-01'`},
+		{`CREATE STATISTICS a ON (date_trunc('day', ts)) FROM t`},
+		{`CREATE STATISTICS a ON (j->'b') FROM t WITH OPTIONS AS OF SYSTEM TIME '2016-01-01'`},
 
 		{`ANALYZE t`},
 		{`ANALYZE db.sc.t`},
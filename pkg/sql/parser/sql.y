@@ -2985,7 +2985,7 @@ create_ddl_stmt:
 // %Category: Misc
 // %Text:
 // CREATE STATISTICS <statisticname>
-//   [ON <colname> [, ...]]
+//   [ON <colname> [, ...] | ON ( <expr> )]
 //   FROM <tablename> [AS OF SYSTEM TIME <expr>]
 create_stats_stmt:
   CREATE STATISTICS statistics_name opt_stats_columns FROM create_stats_target opt_create_stats_options
@@ -2997,6 +2997,15 @@ create_stats_stmt:
       Options: *$7.createStatsOptions(),
     }
   }
+| CREATE STATISTICS statistics_name ON '(' a_expr ')' FROM create_stats_target opt_create_stats_options
+  {
+    $$.val = &tree.CreateStats{
+      Name: tree.Name($3),
+      Expr: $6.expr(),
+      Table: $9.tblExpr(),
+      Options: *$10.createStatsOptions(),
+    }
+  }
 | CREATE STATISTICS error // SHOW HELP: CREATE STATISTICS
 
 opt_stats_columns:
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/explain"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
@@ -290,9 +291,17 @@ type planTop struct {
 	// current statement is causing an auditing event. See exec_log.go.
 	auditEvents []auditEvent
 
+	// roleAudit is set if the user executing the current statement is
+	// subject to a role-based audit policy. See exec_log.go.
+	roleAudit *roleAuditMatch
+
 	// flags is populated during planning and execution.
 	flags planFlags
 
+	// indexesUsed lists the indexes read by the statement. It is populated
+	// during planning and used to maintain index usage statistics.
+	indexesUsed []idxusage.IndexKey
+
 	// execErr retains the last execution error, if any.
 	execErr error
 
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/idxusage"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/execbuilder"
@@ -28,7 +29,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/physicalplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/planhints"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
@@ -40,6 +43,18 @@ var queryCacheEnabled = settings.RegisterBoolSetting(
 	"sql.query_cache.enabled", "enable the query cache", true,
 )
 
+// numCustomPlansBeforeGeneric is the number of executions of a prepared
+// statement which use custom plans in the auto plan_cache_mode before the
+// generic plan is considered. The value is the same as in Postgres.
+const numCustomPlansBeforeGeneric = 5
+
+// customPlanOptimizationCost is the estimated cost of optimizing a custom
+// plan, per table referenced by the statement, in the units of the cost model
+// of the optimizer. As in Postgres, it is added to the average cost of the
+// custom plans when comparing them to the generic plan, which saves the
+// optimization of each execution.
+const customPlanOptimizationCost = 2.5
+
 // prepareUsingOptimizer builds a memo for a prepared statement and populates
 // the following stmt.Prepared fields:
 //  - Columns
@@ -50,7 +65,7 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 	stmt := p.stmt
 
 	opc := &p.optPlanningCtx
-	opc.reset()
+	opc.reset(ctx)
 
 	stmt.Prepared.AnonymizedStr = anonymizeStmt(stmt.AST)
 
@@ -190,7 +205,7 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 // On success, it populates p.curPlan.
 func (p *planner) makeOptimizerPlan(ctx context.Context) error {
 	opc := &p.optPlanningCtx
-	opc.reset()
+	opc.reset(ctx)
 
 	execMemo, err := opc.buildExecMemo(ctx)
 	if err != nil {
@@ -291,6 +306,15 @@ type optPlanningCtx struct {
 	// allowMemoReuse is false.
 	useCache bool
 
+	// hints are the plan hints applied to the statement, either given in its
+	// hint comment or taken from the plan baseline of its fingerprint.
+	hints *planhints.Hints
+
+	// hintsFromComment is set if the hints were given in the hint comment of
+	// the statement, in which case the hints which cannot be applied are
+	// reported to the client.
+	hintsFromComment bool
+
 	flags planFlags
 }
 
@@ -302,7 +326,7 @@ func (opc *optPlanningCtx) init(p *planner) {
 }
 
 // reset initializes the planning context for the statement in the planner.
-func (opc *optPlanningCtx) reset() {
+func (opc *optPlanningCtx) reset(ctx context.Context) {
 	p := opc.p
 	opc.catalog.reset()
 	opc.optimizer.Init(p.EvalContext(), &opc.catalog)
@@ -334,6 +358,64 @@ func (opc *optPlanningCtx) reset() {
 		opc.allowMemoReuse = false
 		opc.useCache = false
 	}
+
+	opc.initHints(ctx)
+}
+
+// initHints determines the plan hints applied to the statement in the planner.
+// The hint comment of the statement takes precedence over the plan baseline of
+// its fingerprint. Invalid hint comments are reported to the client and
+// ignored.
+func (opc *optPlanningCtx) initHints(ctx context.Context) {
+	p := opc.p
+	opc.hints = nil
+	opc.hintsFromComment = false
+
+	if comment, ok := planhints.Extract(p.stmt.SQL); ok {
+		hints, err := planhints.Parse(comment)
+		if err != nil {
+			p.SendClientNotice(ctx, pgnotice.Newf("ignoring plan hints: %v", err))
+			return
+		}
+		opc.hints = hints
+		opc.hintsFromComment = true
+		return
+	}
+
+	registry := p.execCfg.PlanBaselineRegistry
+	if registry == nil || !registry.HasBaselines() {
+		return
+	}
+	fingerprint := p.stmt.AnonymizedStr
+	if fingerprint == "" {
+		fingerprint = anonymizeStmt(p.stmt.AST)
+	}
+	hints, ok := registry.Lookup(fingerprint)
+	if !ok {
+		// The baseline of an EXPLAINed statement also applies to the EXPLAIN, so
+		// that the pinned plan can be inspected.
+		if explain, isExplain := p.stmt.AST.(*tree.Explain); isExplain {
+			hints, ok = registry.Lookup(anonymizeStmt(explain.Statement))
+		}
+	}
+	if ok {
+		opc.hints = hints
+		// Cached memos are keyed by the statement and may have been built
+		// before the baseline was created, so they can't be used.
+		opc.allowMemoReuse = false
+		opc.useCache = false
+	}
+}
+
+// reportUnappliedHints notifies the client of the hints given in the hint
+// comment of the statement which did not match any data source or join.
+func (opc *optPlanningCtx) reportUnappliedHints(ctx context.Context, bld *optbuilder.Builder) {
+	if !opc.hintsFromComment {
+		return
+	}
+	for _, hint := range bld.UnappliedHints() {
+		opc.p.SendClientNotice(ctx, pgnotice.Newf("plan hint %s was not applied", hint))
+	}
 }
 
 func (opc *optPlanningCtx) log(ctx context.Context, msg string) {
@@ -381,9 +463,11 @@ func (opc *optPlanningCtx) buildReusableMemo(ctx context.Context) (_ *memo.Memo,
 	f := opc.optimizer.Factory()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
 	bld.KeepPlaceholders = true
+	bld.Hints = opc.hints
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
+	opc.reportUnappliedHints(ctx, bld)
 
 	if bld.DisableMemoReuse {
 		// The builder encountered a statement that prevents safe reuse of the memo.
@@ -446,6 +530,74 @@ func (opc *optPlanningCtx) reuseMemo(cachedMemo *memo.Memo) (*memo.Memo, error)
 	return f.Memo(), nil
 }
 
+// chooseGenericPlan returns the memo of the generic plan of the prepared
+// statement if it is to be used for the current execution according to the
+// plan_cache_mode session setting, or nil if a custom plan is to be optimized
+// for the placeholder values of the execution. The generic plan is built the
+// first time it is considered.
+func (opc *optPlanningCtx) chooseGenericPlan(
+	ctx context.Context, prepared *PreparedStatement,
+) (*memo.Memo, error) {
+	mode := opc.p.SessionData().PlanCacheMode
+	if mode == sessiondata.PlanCacheModeForceCustom || prepared.Memo.IsOptimized() {
+		// The memo of a statement without placeholders is fully optimized when
+		// it is prepared, so it is already reused by all executions.
+		return nil, nil
+	}
+	if mode == sessiondata.PlanCacheModeAuto && prepared.Costs.numCustom < numCustomPlansBeforeGeneric {
+		return nil, nil
+	}
+
+	if prepared.GenericMemo == nil {
+		genericMemo, err := opc.buildGenericMemo(ctx, prepared.Memo)
+		if err != nil {
+			return nil, err
+		}
+		if err := prepared.memAcc.Grow(ctx, genericMemo.MemoryEstimate()); err != nil {
+			return nil, err
+		}
+		prepared.GenericMemo = genericMemo
+		prepared.Costs.generic = genericMemo.RootExpr().(memo.RelExpr).Cost()
+	}
+
+	if mode == sessiondata.PlanCacheModeAuto {
+		numTables := len(prepared.Memo.Metadata().AllTables())
+		if !prepared.Costs.preferGeneric(numTables) {
+			opc.log(ctx, "generic plan is more expensive than custom plans")
+			return nil, nil
+		}
+	}
+	return prepared.GenericMemo, nil
+}
+
+// buildGenericMemo returns a fully optimized memo built from the given
+// prepared memo without replacing its placeholders, which are evaluated during
+// execution instead. The returned memo is detached from the planner and can be
+// reused by all executions of the prepared statement.
+func (opc *optPlanningCtx) buildGenericMemo(
+	ctx context.Context, preparedMemo *memo.Memo,
+) (*memo.Memo, error) {
+	opc.log(ctx, "building generic plan")
+	f := opc.optimizer.Factory()
+	if err := f.CopyWithoutAssigningPlaceholders(preparedMemo); err != nil {
+		return nil, err
+	}
+	if _, err := opc.optimizer.Optimize(); err != nil {
+		return nil, err
+	}
+	return opc.optimizer.DetachMemo(), nil
+}
+
+// discardGenericPlan discards the generic plan and the plan costs of the
+// prepared statement, after its memo was rebuilt.
+func (opc *optPlanningCtx) discardGenericPlan(ctx context.Context, prepared *PreparedStatement) {
+	if prepared.GenericMemo != nil {
+		prepared.memAcc.Shrink(ctx, prepared.GenericMemo.MemoryEstimate())
+		prepared.GenericMemo = nil
+	}
+	prepared.Costs = planCosts{}
+}
+
 // buildExecMemo creates a fully optimized memo, possibly reusing a previously
 // cached memo as a starting point.
 //
@@ -468,10 +620,20 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 			if err != nil {
 				return nil, err
 			}
+			opc.discardGenericPlan(ctx, prepared)
+		}
+		if genericMemo, err := opc.chooseGenericPlan(ctx, prepared); err != nil {
+			return nil, err
+		} else if genericMemo != nil {
+			opc.log(ctx, "reusing generic plan")
+			return genericMemo, nil
 		}
 		opc.log(ctx, "reusing cached memo")
-		memo, err := opc.reuseMemo(prepared.Memo)
-		return memo, err
+		customMemo, err := opc.reuseMemo(prepared.Memo)
+		if err == nil && !prepared.Memo.IsOptimized() {
+			prepared.Costs.addCustom(customMemo.RootExpr().(memo.RelExpr).Cost())
+		}
+		return customMemo, err
 	}
 
 	if opc.useCache {
@@ -509,9 +671,11 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 	f := opc.optimizer.Factory()
 	f.FoldingControl().AllowStableFolds()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
+	bld.Hints = opc.hints
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
+	opc.reportUnappliedHints(ctx, bld)
 	if _, isCanned := opc.p.stmt.AST.(*tree.CannedOptPlan); !isCanned {
 		if _, err := opc.optimizer.Optimize(); err != nil {
 			return nil, err
@@ -554,6 +718,7 @@ func (opc *optPlanningCtx) runExecBuilder(
 	var isDDL bool
 	var containsFullTableScan bool
 	var containsFullIndexScan bool
+	var indexesUsed []execbuilder.IndexUsage
 	if planTop.appStats != nil {
 		// We do not set this flag upfront when initializing planTop because the
 		// planning process could in principle modify the AST, resulting in a
@@ -574,6 +739,7 @@ func (opc *optPlanningCtx) runExecBuilder(
 		isDDL = bld.IsDDL
 		containsFullTableScan = bld.ContainsFullTableScan
 		containsFullIndexScan = bld.ContainsFullIndexScan
+		indexesUsed = bld.IndexesUsed
 	} else {
 		// Create an explain factory and record the explain.Plan.
 		explainFactory := explain.NewFactory(f)
@@ -587,6 +753,7 @@ func (opc *optPlanningCtx) runExecBuilder(
 		isDDL = bld.IsDDL
 		containsFullTableScan = bld.ContainsFullTableScan
 		containsFullIndexScan = bld.ContainsFullIndexScan
+		indexesUsed = bld.IndexesUsed
 	}
 
 	if stmt.ExpectedTypes != nil {
@@ -612,5 +779,14 @@ func (opc *optPlanningCtx) runExecBuilder(
 	if containsFullIndexScan {
 		planTop.flags.Set(planFlagContainsFullIndexScan)
 	}
+	if len(indexesUsed) > 0 {
+		planTop.indexesUsed = make([]idxusage.IndexKey, len(indexesUsed))
+		for i, u := range indexesUsed {
+			planTop.indexesUsed[i] = idxusage.IndexKey{
+				TableID: descpb.ID(u.TableID),
+				IndexID: descpb.IndexID(u.IndexID),
+			}
+		}
+	}
 	return nil
 }
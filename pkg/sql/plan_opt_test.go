@@ -543,6 +543,133 @@ SELECT cte.x, cte.y FROM cte LEFT JOIN cte as cte2 on cte.y = cte2.x`, j)
 	})
 }
 
+// TestGenericPlanCache tests that the generic plan of a prepared statement is
+// reused by its executions, and that it is discarded when the prepared memo is
+// invalidated by a schema change or by new table statistics.
+func TestGenericPlanCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	// Prepared statements and session traces belong to a session, so all
+	// statements must run on the same connection.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := sqlutils.MakeSQLRunner(conn)
+	r.Exec(t, "CREATE TABLE t (k INT PRIMARY KEY, v INT)")
+	r.Exec(t, "INSERT INTO t VALUES (1, 10), (2, 20), (3, 30)")
+
+	// execute runs the given prepared statement with the argument k, checks
+	// that it returns the value v, and returns the number of times that the
+	// generic plan was built and reused by the execution.
+	execute := func(t *testing.T, stmt string, k, v int) (built, reused int) {
+		t.Helper()
+		r.Exec(t, "SET tracing = on")
+		r.CheckQueryResults(t,
+			fmt.Sprintf("EXECUTE %s(%d)", stmt, k), [][]string{{fmt.Sprint(v)}},
+		)
+		r.Exec(t, "SET tracing = off")
+		r.QueryRow(t, `
+SELECT count(*) FILTER (WHERE message LIKE '%building generic plan%'),
+       count(*) FILTER (WHERE message LIKE '%reusing generic plan%')
+  FROM [SHOW TRACE FOR SESSION]`,
+		).Scan(&built, &reused)
+		return built, reused
+	}
+	expect := func(t *testing.T, built, reused, expBuilt, expReused int) {
+		t.Helper()
+		assert.Equal(t, expBuilt, built, "generic plans built")
+		assert.Equal(t, expReused, reused, "generic plans reused")
+	}
+
+	t.Run("reuse", func(t *testing.T) {
+		r.Exec(t, "SET plan_cache_mode = force_generic_plan")
+		r.Exec(t, "PREPARE p1 AS SELECT v FROM t WHERE k = $1")
+
+		// The generic plan is built by the first execution, and reused by the
+		// following executions with different placeholder values.
+		built, reused := execute(t, "p1", 1, 10)
+		expect(t, built, reused, 1, 1)
+		built, reused = execute(t, "p1", 2, 20)
+		expect(t, built, reused, 0, 1)
+		built, reused = execute(t, "p1", 3, 30)
+		expect(t, built, reused, 0, 1)
+
+		// Custom plans are used once the generic plan is no longer allowed.
+		r.Exec(t, "SET plan_cache_mode = force_custom_plan")
+		built, reused = execute(t, "p1", 1, 10)
+		expect(t, built, reused, 0, 0)
+	})
+
+	t.Run("schemachange", func(t *testing.T) {
+		r.Exec(t, "SET plan_cache_mode = force_generic_plan")
+		r.Exec(t, "PREPARE p2 AS SELECT v FROM t WHERE k = $1")
+		built, reused := execute(t, "p2", 1, 10)
+		expect(t, built, reused, 1, 1)
+
+		// The schema change invalidates the prepared memo, so the generic plan
+		// is rebuilt from the new memo.
+		r.Exec(t, "ALTER TABLE t ADD COLUMN w INT")
+		built, reused = execute(t, "p2", 2, 20)
+		expect(t, built, reused, 1, 1)
+		built, reused = execute(t, "p2", 3, 30)
+		expect(t, built, reused, 0, 1)
+	})
+
+	t.Run("statschange", func(t *testing.T) {
+		r.Exec(t, "SET plan_cache_mode = force_generic_plan")
+		r.Exec(t, "PREPARE p3 AS SELECT v FROM t WHERE k = $1")
+		built, reused := execute(t, "p3", 1, 10)
+		expect(t, built, reused, 1, 1)
+
+		// New statistics invalidate the prepared memo, so the generic plan is
+		// rebuilt from the new memo.
+		r.Exec(t, "CREATE STATISTICS s FROM t")
+		testutils.SucceedsSoon(t, func() error {
+			// The stats cache is updated asynchronously, so the generic plan may
+			// be reused before it is rebuilt.
+			if built, _ := execute(t, "p3", 2, 20); built != 1 {
+				return errors.Errorf("expected the generic plan to be rebuilt")
+			}
+			return nil
+		})
+		built, reused = execute(t, "p3", 3, 30)
+		expect(t, built, reused, 0, 1)
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		r.Exec(t, "SET plan_cache_mode = auto")
+		r.Exec(t, "PREPARE p4 AS SELECT v FROM t WHERE k = $1")
+
+		// Custom plans are used for the first executions, after which the
+		// generic plan, which is as cheap as the custom plans, is used.
+		for i := 0; i < numCustomPlansBeforeGeneric; i++ {
+			built, reused := execute(t, "p4", 1, 10)
+			expect(t, built, reused, 0, 0)
+		}
+		built, reused := execute(t, "p4", 2, 20)
+		expect(t, built, reused, 1, 1)
+		built, reused = execute(t, "p4", 3, 30)
+		expect(t, built, reused, 0, 1)
+
+		// The schema change discards the generic plan along with the costs of
+		// the custom plans, so custom plans are used again.
+		r.Exec(t, "ALTER TABLE t ADD COLUMN x INT")
+		for i := 0; i < numCustomPlansBeforeGeneric; i++ {
+			built, reused := execute(t, "p4", 1, 10)
+			expect(t, built, reused, 0, 0)
+		}
+		built, reused = execute(t, "p4", 2, 20)
+		expect(t, built, reused, 1, 1)
+	})
+}
+
 // BenchmarkQueryCache is a set of benchmarks that run queries against a server
 // with the query cache on and off, with varying number of parallel clients and
 // with workloads that are either cacheable or not.
@@ -0,0 +1,192 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package planhints
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var pollingInterval = settings.RegisterDurationSetting(
+	"sql.plan_baselines.poll_interval",
+	"rate at which each node reloads the plan baselines from "+
+		"system.plan_baselines, set to zero to disable",
+	10*time.Second)
+
+// Registry maintains an in-memory copy of system.plan_baselines, which is
+// reloaded periodically.
+type Registry struct {
+	st *cluster.Settings
+	ie sqlutil.InternalExecutor
+
+	mu struct {
+		syncutil.RWMutex
+		// baselines maps statement fingerprints to hints. It is replaced, never
+		// modified in place.
+		baselines map[string]*Hints
+	}
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(ie sqlutil.InternalExecutor, st *cluster.Settings) *Registry {
+	return &Registry{st: st, ie: ie}
+}
+
+// Start starts the loop that reloads the baselines periodically.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	// NB: The only error that should occur here would be if the server were
+	// shutting down so let's swallow it.
+	_ = stopper.RunAsyncTask(ctx, "plan-baselines-poll", r.poll)
+}
+
+func (r *Registry) poll(ctx context.Context) {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	intervalChanged := make(chan struct{}, 1)
+	pollingInterval.SetOnChange(&r.st.SV, func() {
+		select {
+		case intervalChanged <- struct{}{}:
+		default:
+		}
+	})
+	r.maybeRefresh(ctx)
+	for {
+		if interval := pollingInterval.Get(&r.st.SV); interval > 0 {
+			timer.Reset(interval)
+		} else {
+			timer.Stop()
+		}
+		select {
+		case <-intervalChanged:
+			continue
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return
+		}
+		r.maybeRefresh(ctx)
+	}
+}
+
+func (r *Registry) maybeRefresh(ctx context.Context) {
+	if err := r.refresh(ctx); err != nil && ctx.Err() == nil {
+		log.Warningf(ctx, "error loading plan baselines: %v", err)
+	}
+}
+
+// refresh reloads the baselines from system.plan_baselines. Rows with hints
+// that fail to parse, which can only be inserted by modifying the table
+// directly, are skipped.
+func (r *Registry) refresh(ctx context.Context) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.VersionPlanBaselines) {
+		return nil
+	}
+	rows, err := r.ie.QueryEx(ctx, "plan-baselines-poll", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		"SELECT fingerprint, hints FROM system.plan_baselines")
+	if err != nil {
+		return err
+	}
+	baselines := make(map[string]*Hints, len(rows))
+	for _, row := range rows {
+		fingerprint := string(tree.MustBeDString(row[0]))
+		hints, err := Parse(string(tree.MustBeDString(row[1])))
+		if err != nil {
+			log.Warningf(ctx, "skipping plan baseline for %q: %v", fingerprint, err)
+			continue
+		}
+		baselines[fingerprint] = hints
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.baselines = baselines
+	return nil
+}
+
+// Lookup returns the hints of the baseline for the given statement
+// fingerprint, if there is one. The returned hints must not be modified.
+func (r *Registry) Lookup(fingerprint string) (*Hints, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hints, ok := r.mu.baselines[fingerprint]
+	return hints, ok
+}
+
+// HasBaselines returns whether any baseline is defined. It allows callers to
+// skip computing the fingerprint of the statements when there is none.
+func (r *Registry) HasBaselines() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.mu.baselines) != 0
+}
+
+// SetBaseline implements the tree.PlanBaselineConfigurator interface. It
+// creates or replaces the baseline for the given statement fingerprint. The
+// change takes effect immediately on this node, and within the polling
+// interval on the other nodes.
+func (r *Registry) SetBaseline(ctx context.Context, fingerprint string, hints string) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.VersionPlanBaselines) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"plan baselines are only supported after the cluster version is upgraded")
+	}
+	if fingerprint == "" {
+		return pgerror.New(pgcode.InvalidParameterValue, "statement fingerprint cannot be empty")
+	}
+	parsed, err := Parse(hints)
+	if err != nil {
+		return err
+	}
+	if parsed.Empty() {
+		return pgerror.New(pgcode.InvalidParameterValue, "plan baseline requires at least one hint")
+	}
+	if _, err := r.ie.ExecEx(ctx, "plan-baselines-set", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		"UPSERT INTO system.plan_baselines (fingerprint, hints, created) VALUES ($1, $2, now())",
+		fingerprint, parsed.String(),
+	); err != nil {
+		return err
+	}
+	return r.refresh(ctx)
+}
+
+// ClearBaseline implements the tree.PlanBaselineConfigurator interface. It
+// removes the baseline for the given statement fingerprint, and returns
+// whether there was one.
+func (r *Registry) ClearBaseline(ctx context.Context, fingerprint string) (bool, error) {
+	if !r.st.Version.IsActive(ctx, clusterversion.VersionPlanBaselines) {
+		return false, pgerror.New(pgcode.FeatureNotSupported,
+			"plan baselines are only supported after the cluster version is upgraded")
+	}
+	n, err := r.ie.ExecEx(ctx, "plan-baselines-clear", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		"DELETE FROM system.plan_baselines WHERE fingerprint = $1",
+		fingerprint,
+	)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, r.refresh(ctx)
+}
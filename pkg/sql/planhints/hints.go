@@ -0,0 +1,426 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package planhints implements the plan hints, which constrain the join
+// order, the join methods and the scan methods chosen by the optimizer, and
+// the plan baselines stored in system.plan_baselines.
+//
+// Hints are given in a comment of the form /*+ ... */ which follows the
+// leading keyword(s) of a statement, in the style of pg_hint_plan:
+//
+//   SELECT /*+ Leading(a b) HashJoin(a b) IndexScan(b b_idx) */ ...
+//
+// A plan baseline associates hints with a statement fingerprint, so that the
+// plan of every execution of the statement is constrained by them, for
+// example to prevent plan changes after the table statistics are refreshed.
+// Hints in a comment take precedence over the baseline of the statement.
+package planhints
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// ScanMethod is the scan method requested by a scan hint.
+type ScanMethod int
+
+const (
+	// SeqScan requests a scan of the primary index.
+	SeqScan ScanMethod = iota
+	// IndexScan requests a scan of a given index.
+	IndexScan
+	// IndexOnlyScan requests a scan of an index which does not require an
+	// index join.
+	IndexOnlyScan
+)
+
+var scanMethodNames = [...]string{
+	SeqScan:       "SeqScan",
+	IndexScan:     "IndexScan",
+	IndexOnlyScan: "IndexOnlyScan",
+}
+
+func (m ScanMethod) String() string {
+	return scanMethodNames[m]
+}
+
+// JoinMethod is the join method requested by a join hint.
+type JoinMethod int
+
+const (
+	// HashJoin requests a hash join.
+	HashJoin JoinMethod = iota
+	// MergeJoin requests a merge join.
+	MergeJoin
+	// LookupJoin requests a lookup join into the right side of the join.
+	LookupJoin
+)
+
+var joinMethodNames = [...]string{
+	HashJoin:   "HashJoin",
+	MergeJoin:  "MergeJoin",
+	LookupJoin: "LookupJoin",
+}
+
+func (m JoinMethod) String() string {
+	return joinMethodNames[m]
+}
+
+// ScanHint selects the scan method of the data source with the given alias.
+type ScanHint struct {
+	Alias  string
+	Method ScanMethod
+	// Index is only set for IndexScan.
+	Index string
+}
+
+func (h *ScanHint) String() string {
+	var b strings.Builder
+	b.WriteString(h.Method.String())
+	b.WriteByte('(')
+	formatName(&b, h.Alias)
+	if h.Index != "" {
+		b.WriteByte(' ')
+		formatName(&b, h.Index)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// JoinHint selects the method of the join between exactly the data sources
+// with the given aliases (in any order).
+type JoinHint struct {
+	Aliases []string
+	Method  JoinMethod
+}
+
+func (h *JoinHint) String() string {
+	return formatHint(h.Method.String(), h.Aliases)
+}
+
+// Matches returns whether the hint applies to a join of the data sources
+// with the given aliases.
+func (h *JoinHint) Matches(aliases []string) bool {
+	return sameAliases(h.Aliases, aliases)
+}
+
+// Hints is a set of plan hints.
+type Hints struct {
+	Scans []ScanHint
+	Joins []JoinHint
+	// Leading, if set, is the order in which the data sources with the given
+	// aliases are joined.
+	Leading []string
+}
+
+// Empty returns true if there are no hints.
+func (h *Hints) Empty() bool {
+	return len(h.Scans) == 0 && len(h.Joins) == 0 && len(h.Leading) == 0
+}
+
+// ScanHint returns the scan hint for the given alias, along with its ordinal
+// in h.Scans.
+func (h *Hints) ScanHint(alias string) (*ScanHint, int, bool) {
+	for i := range h.Scans {
+		if h.Scans[i].Alias == alias {
+			return &h.Scans[i], i, true
+		}
+	}
+	return nil, 0, false
+}
+
+// LeadingNext returns whether the Leading hint orders a join which adds the
+// data source with the alias next to the join of the data sources with the
+// given aliases; they must be the ones which precede next in the hint.
+func (h *Hints) LeadingNext(aliases []string, next string) bool {
+	n := len(aliases)
+	return n < len(h.Leading) && h.Leading[n] == next && sameAliases(h.Leading[:n], aliases)
+}
+
+// LeadingString formats the Leading hint.
+func (h *Hints) LeadingString() string {
+	return formatHint("Leading", h.Leading)
+}
+
+// String formats the hints in a form accepted by Parse.
+func (h *Hints) String() string {
+	var parts []string
+	if len(h.Leading) != 0 {
+		parts = append(parts, h.LeadingString())
+	}
+	for i := range h.Joins {
+		parts = append(parts, h.Joins[i].String())
+	}
+	for i := range h.Scans {
+		parts = append(parts, h.Scans[i].String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Extract returns the contents of the hint comment of the given statement,
+// if it has one. The hint comment must only be preceded by keywords,
+// identifiers, parentheses, commas, whitespace and other comments; it is
+// usually placed right after the leading keyword of the statement, as in
+// SELECT /*+ ... */ or EXPLAIN (OPT) SELECT /*+ ... */.
+func Extract(sql string) (string, bool) {
+	for i := 0; i < len(sql); {
+		switch c := sql[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			i++
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'):
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return "", false
+			}
+			i += end + 1
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return "", false
+			}
+			if sql[i+2:i+2+end] != "" && sql[i+2] == '+' {
+				return sql[i+3 : i+2+end], true
+			}
+			i += end + 4
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// Parse parses the contents of a hint comment, which is a whitespace
+// separated list of hints of the form Name(arg ...). The hint names are
+// case-insensitive; the arguments are identifiers, which are folded to lower
+// case unless they are double-quoted.
+func Parse(s string) (*Hints, error) {
+	p := parser{s: s}
+	h := &Hints{}
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		name, ok := p.word()
+		if !ok {
+			return nil, p.errorAt("expected hint name")
+		}
+		args, err := p.args()
+		if err != nil {
+			return nil, err
+		}
+		if err := h.add(name, args); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *Hints) add(name string, args []string) error {
+	switch lower := strings.ToLower(name); lower {
+	case "seqscan", "indexscan", "indexonlyscan":
+		method := SeqScan
+		nArgs := 1
+		switch lower {
+		case "indexscan":
+			method, nArgs = IndexScan, 2
+		case "indexonlyscan":
+			method = IndexOnlyScan
+		}
+		if len(args) != nArgs {
+			return pgerror.Newf(pgcode.Syntax,
+				"%s hint requires %d argument(s), got %d", method, nArgs, len(args))
+		}
+		if _, _, ok := h.ScanHint(args[0]); ok {
+			return pgerror.Newf(pgcode.Syntax, "multiple scan hints for %q", args[0])
+		}
+		hint := ScanHint{Alias: args[0], Method: method}
+		if method == IndexScan {
+			hint.Index = args[1]
+		}
+		h.Scans = append(h.Scans, hint)
+
+	case "hashjoin", "mergejoin", "lookupjoin":
+		method := HashJoin
+		switch lower {
+		case "mergejoin":
+			method = MergeJoin
+		case "lookupjoin":
+			method = LookupJoin
+		}
+		if err := checkAliases(method.String(), args); err != nil {
+			return err
+		}
+		for i := range h.Joins {
+			if h.Joins[i].Matches(args) {
+				return pgerror.Newf(pgcode.Syntax, "multiple join hints for %s", strings.Join(args, " "))
+			}
+		}
+		h.Joins = append(h.Joins, JoinHint{Aliases: args, Method: method})
+
+	case "leading":
+		if len(h.Leading) != 0 {
+			return pgerror.New(pgcode.Syntax, "multiple Leading hints")
+		}
+		if err := checkAliases("Leading", args); err != nil {
+			return err
+		}
+		h.Leading = args
+
+	default:
+		return pgerror.Newf(pgcode.Syntax, "unknown hint %q", name)
+	}
+	return nil
+}
+
+// checkAliases checks that the arguments of a join hint are at least two
+// distinct aliases.
+func checkAliases(hint string, aliases []string) error {
+	if len(aliases) < 2 {
+		return pgerror.Newf(pgcode.Syntax, "%s hint requires at least 2 aliases", hint)
+	}
+	for i := range aliases {
+		for j := i + 1; j < len(aliases); j++ {
+			if aliases[i] == aliases[j] {
+				return pgerror.Newf(pgcode.Syntax, "%s hint references %q more than once", hint, aliases[i])
+			}
+		}
+	}
+	return nil
+}
+
+// sameAliases returns whether the two lists contain the same aliases. The
+// first list must not contain duplicates.
+func sameAliases(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sorted := append([]string(nil), b...)
+	sort.Strings(sorted)
+	for _, alias := range a {
+		if i := sort.SearchStrings(sorted, alias); i == len(sorted) || sorted[i] != alias {
+			return false
+		}
+	}
+	return true
+}
+
+func formatHint(name string, aliases []string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('(')
+	for i, alias := range aliases {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		formatName(&b, alias)
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// formatName writes an identifier, double-quoting it if it would not parse
+// back to the same identifier.
+func formatName(b *strings.Builder, name string) {
+	bare := name != ""
+	for _, c := range name {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')) {
+			bare = false
+			break
+		}
+	}
+	if bare {
+		b.WriteString(name)
+		return
+	}
+	b.WriteByte('"')
+	b.WriteString(name)
+	b.WriteByte('"')
+}
+
+// parser is a minimal scanner for the contents of hint comments.
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) errorAt(msg string) error {
+	return pgerror.Newf(pgcode.Syntax, "invalid plan hint at position %d: %s", p.pos+1, msg)
+}
+
+// word scans an unquoted identifier.
+func (p *parser) word() (string, bool) {
+	start := p.pos
+	for !p.done() {
+		c := p.s[p.pos]
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(p.pos > start && c >= '0' && c <= '9')) {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos], p.pos > start
+}
+
+// args scans a parenthesized list of identifiers.
+func (p *parser) args() ([]string, error) {
+	p.skipSpace()
+	if p.done() || p.s[p.pos] != '(' {
+		return nil, p.errorAt("expected (")
+	}
+	p.pos++
+	var args []string
+	for {
+		p.skipSpace()
+		if p.done() {
+			return nil, p.errorAt("expected )")
+		}
+		if p.s[p.pos] == ')' {
+			p.pos++
+			return args, nil
+		}
+		if p.s[p.pos] == '"' {
+			end := strings.IndexByte(p.s[p.pos+1:], '"')
+			if end < 0 {
+				return nil, p.errorAt("unterminated quoted identifier")
+			}
+			args = append(args, p.s[p.pos+1:p.pos+1+end])
+			p.pos += end + 2
+			continue
+		}
+		arg, ok := p.word()
+		if !ok {
+			return nil, p.errorAt("expected identifier")
+		}
+		args = append(args, strings.ToLower(arg))
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package planhints
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		sql      string
+		expected string
+		found    bool
+	}{
+		{"SELECT /*+ SeqScan(t) */ * FROM t", " SeqScan(t) ", true},
+		{"EXPLAIN SELECT /*+SeqScan(t)*/ * FROM t", "SeqScan(t)", true},
+		{"EXPLAIN (OPT, VERBOSE) SELECT /*+ SeqScan(t) */ * FROM t", " SeqScan(t) ", true},
+		{"SELECT /* comment */ /*+ SeqScan(t) */ * FROM t", " SeqScan(t) ", true},
+		{"SELECT -- comment\n/*+ SeqScan(t) */ * FROM t", " SeqScan(t) ", true},
+		{"SELECT /* SeqScan(t) */ * FROM t", "", false},
+		{"SELECT * FROM t /*+ SeqScan(t) */", "", false},
+		{"SELECT '/*+ SeqScan(t) */'", "", false},
+		{"SELECT /*+ SeqScan(t)", "", false},
+	} {
+		res, found := Extract(tc.sql)
+		require.Equal(t, tc.found, found, tc.sql)
+		require.Equal(t, tc.expected, res, tc.sql)
+	}
+}
+
+func TestParse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	h, err := Parse(`
+    seqscan(A) IndexScan(b b_idx) IndexOnlyScan("C")
+    HashJoin(a b) MergeJoin(b "C" a) LookupJoin(a "C")
+    Leading(a b "C")`)
+	require.NoError(t, err)
+	require.Equal(t, &Hints{
+		Scans: []ScanHint{
+			{Alias: "a", Method: SeqScan},
+			{Alias: "b", Method: IndexScan, Index: "b_idx"},
+			{Alias: "C", Method: IndexOnlyScan},
+		},
+		Joins: []JoinHint{
+			{Aliases: []string{"a", "b"}, Method: HashJoin},
+			{Aliases: []string{"b", "C", "a"}, Method: MergeJoin},
+			{Aliases: []string{"a", "C"}, Method: LookupJoin},
+		},
+		Leading: []string{"a", "b", "C"},
+	}, h)
+	const expected = `Leading(a b "C") HashJoin(a b) MergeJoin(b "C" a) LookupJoin(a "C") ` +
+		`SeqScan(a) IndexScan(b b_idx) IndexOnlyScan("C")`
+	require.Equal(t, expected, h.String())
+
+	// The formatted hints parse back to the same hints.
+	h2, err := Parse(h.String())
+	require.NoError(t, err)
+	require.Equal(t, h, h2)
+
+	require.True(t, h.Joins[0].Matches([]string{"b", "a"}))
+	require.False(t, h.Joins[0].Matches([]string{"a", "b", "C"}))
+	require.True(t, h.LeadingNext(nil, "a"))
+	require.True(t, h.LeadingNext([]string{"b", "a"}, "C"))
+	require.False(t, h.LeadingNext([]string{"a"}, "C"))
+
+	h, err = Parse("  ")
+	require.NoError(t, err)
+	require.True(t, h.Empty())
+
+	for _, tc := range []struct {
+		in  string
+		err string
+	}{
+		{"Foo(a)", `unknown hint "Foo"`},
+		{"SeqScan", `invalid plan hint at position 8: expected (`},
+		{"SeqScan(a", `invalid plan hint at position 10: expected )`},
+		{"SeqScan(a, b)", `invalid plan hint at position 10: expected identifier`},
+		{`SeqScan("a)`, `invalid plan hint at position 9: unterminated quoted identifier`},
+		{"SeqScan(a b)", `SeqScan hint requires 1 argument(s), got 2`},
+		{"IndexScan(a)", `IndexScan hint requires 2 argument(s), got 1`},
+		{"SeqScan(a) IndexOnlyScan(a)", `multiple scan hints for "a"`},
+		{"HashJoin(a)", `HashJoin hint requires at least 2 aliases`},
+		{"HashJoin(a b) MergeJoin(b a)", `multiple join hints for b a`},
+		{"Leading(a b a)", `Leading hint references "a" more than once`},
+		{"Leading(a b) Leading(b a)", `multiple Leading hints`},
+	} {
+		_, err := Parse(tc.in)
+		require.EqualError(t, err, tc.err, tc.in)
+	}
+}
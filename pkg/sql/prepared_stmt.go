@@ -50,6 +50,17 @@ type PreparedStatement struct {
 	// if it is used by the optimizer as a starting point.
 	Memo *memo.Memo
 
+	// GenericMemo is the fully optimized memo of the generic plan of the
+	// statement, in which placeholders are evaluated during execution rather
+	// than replaced by their values before optimization. It is built from Memo
+	// the first time it is needed (see the plan_cache_mode session setting).
+	GenericMemo *memo.Memo
+
+	// Costs records the estimated costs of the plans built for the statement,
+	// which determine whether the generic plan is used in the auto
+	// plan_cache_mode.
+	Costs planCosts
+
 	// refCount keeps track of the number of references to this PreparedStatement.
 	// New references are registered through incRef().
 	// Once refCount hits 0 (through calls to decRef()), the following memAcc is
@@ -77,9 +88,40 @@ func (p *PreparedStatement) MemoryEstimate() int64 {
 	if p.Memo != nil {
 		size += p.Memo.MemoryEstimate()
 	}
+	if p.GenericMemo != nil {
+		size += p.GenericMemo.MemoryEstimate()
+	}
 	return size
 }
 
+// planCosts records the estimated costs of the custom plans and of the generic
+// plan of a prepared statement.
+type planCosts struct {
+	// customSum is the sum of the costs of the numCustom custom plans built
+	// since the statement was prepared, or since its memo was last rebuilt.
+	customSum memo.Cost
+	numCustom int
+	// generic is the cost of the generic plan, if it has been built.
+	generic memo.Cost
+}
+
+func (c *planCosts) addCustom(cost memo.Cost) {
+	c.customSum += cost
+	c.numCustom++
+}
+
+// preferGeneric returns true if the cost of the generic plan is at most the
+// average cost of the custom plans, plus the estimated cost of optimizing a
+// custom plan for a statement referencing numTables tables.
+func (c *planCosts) preferGeneric(numTables int) bool {
+	if c.numCustom == 0 {
+		return false
+	}
+	avgCustom := c.customSum / memo.Cost(c.numCustom)
+	optimizationCost := customPlanOptimizationCost * memo.Cost(numTables+1)
+	return c.generic <= avgCustom+optimizationCost
+}
+
 func (p *PreparedStatement) decRef(ctx context.Context) {
 	if p.refCount <= 0 {
 		log.Fatal(ctx, "corrupt PreparedStatement refcount")
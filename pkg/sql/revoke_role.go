@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
@@ -146,7 +147,13 @@ func (n *RevokeRoleNode) startExec(params runParams) error {
 
 	n.run.rowsAffected += rowsAffected
 
-	return nil
+	return params.p.logEvent(params.ctx,
+		0, /* no target */
+		&eventpb.RevokeRole{
+			RoleNames:   n.roles.ToStrings(),
+			Members:     n.members.ToStrings(),
+			AdminOption: n.adminOption,
+		})
 }
 
 // Next implements the planNode interface.
@@ -0,0 +1,300 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package roleaudit implements the role-based SQL audit logging
+// policies stored in system.role_audit_policies.
+//
+// A policy selects the statements executed by the users matching a
+// role pattern, either directly or through their role memberships. The
+// statements selected by a policy are reported to the SQL audit log,
+// see (*planner).maybeLogStatement in package sql.
+package roleaudit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var pollingInterval = settings.RegisterDurationSetting(
+	"sql.log.role_audit.poll_interval",
+	"rate at which each node reloads the role-based audit policies from "+
+		"system.role_audit_policies, set to zero to disable",
+	10*time.Second)
+
+// StatementFilter selects the statements audited by a policy.
+type StatementFilter int
+
+const (
+	// AllStatements selects every statement.
+	AllStatements StatementFilter = iota
+	// DDLStatements selects the statements that can modify the schema.
+	DDLStatements
+	// WriteStatements selects the statements that can modify the schema
+	// or the data.
+	WriteStatements
+)
+
+var statementFilterNames = [...]string{
+	AllStatements:   "ALL",
+	DDLStatements:   "DDL",
+	WriteStatements: "WRITE",
+}
+
+func (f StatementFilter) String() string {
+	return statementFilterNames[f]
+}
+
+// ParseStatementFilter parses the name of a statement filter, as stored
+// in the statements column of system.role_audit_policies. The name is
+// case-insensitive.
+func ParseStatementFilter(s string) (StatementFilter, error) {
+	for i, name := range statementFilterNames {
+		if strings.EqualFold(s, name) {
+			return StatementFilter(i), nil
+		}
+	}
+	return 0, pgerror.Newf(pgcode.InvalidParameterValue,
+		"invalid audit statements %q: expected one of %s",
+		s, strings.Join(statementFilterNames[:], ", "))
+}
+
+// Selects returns whether the filter selects the given statement.
+func (f StatementFilter) Selects(stmt tree.Statement) bool {
+	switch f {
+	case DDLStatements:
+		return tree.CanModifySchema(stmt)
+	case WriteStatements:
+		return tree.CanModifySchema(stmt) || tree.CanWriteData(stmt)
+	default:
+		return true
+	}
+}
+
+// Policy is a role-based audit policy.
+type Policy struct {
+	// RolePattern is either the name of a role, or a prefix followed by
+	// '*', which matches all the roles starting with that prefix.
+	RolePattern string
+	// Statements selects the statements audited by the policy.
+	Statements StatementFilter
+}
+
+// ValidateRolePattern checks that a role pattern is well-formed: it
+// must be non-empty and can only contain '*' as its last character.
+func ValidateRolePattern(pattern string) error {
+	if pattern == "" {
+		return pgerror.New(pgcode.InvalidParameterValue, "role pattern cannot be empty")
+	}
+	if i := strings.IndexByte(pattern, '*'); i >= 0 && i != len(pattern)-1 {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"invalid role pattern %q: '*' is only allowed at the end", pattern)
+	}
+	return nil
+}
+
+// MatchesRole returns whether the policy applies to the given role.
+func (p Policy) MatchesRole(role string) bool {
+	if prefix := strings.TrimSuffix(p.RolePattern, "*"); len(prefix) != len(p.RolePattern) {
+		return strings.HasPrefix(role, prefix)
+	}
+	return p.RolePattern == role
+}
+
+// Registry maintains an in-memory copy of system.role_audit_policies,
+// which is reloaded periodically, and matches statements against it.
+type Registry struct {
+	st *cluster.Settings
+	ie sqlutil.InternalExecutor
+
+	mu struct {
+		syncutil.RWMutex
+		// policies is sorted by role pattern. It is replaced, never
+		// modified in place.
+		policies []Policy
+	}
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(ie sqlutil.InternalExecutor, st *cluster.Settings) *Registry {
+	return &Registry{st: st, ie: ie}
+}
+
+// Start starts the loop that reloads the policies periodically.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	// NB: The only error that should occur here would be if the server were
+	// shutting down so let's swallow it.
+	_ = stopper.RunAsyncTask(ctx, "role-audit-poll", r.poll)
+}
+
+func (r *Registry) poll(ctx context.Context) {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	intervalChanged := make(chan struct{}, 1)
+	pollingInterval.SetOnChange(&r.st.SV, func() {
+		select {
+		case intervalChanged <- struct{}{}:
+		default:
+		}
+	})
+	// The policies are loaded right away: statements executed before the
+	// first refresh would not be audited.
+	r.maybeRefresh(ctx)
+	for {
+		if interval := pollingInterval.Get(&r.st.SV); interval > 0 {
+			timer.Reset(interval)
+		} else {
+			timer.Stop()
+		}
+		select {
+		case <-intervalChanged:
+			continue
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return
+		}
+		r.maybeRefresh(ctx)
+	}
+}
+
+func (r *Registry) maybeRefresh(ctx context.Context) {
+	if err := r.refresh(ctx); err != nil && ctx.Err() == nil {
+		log.Warningf(ctx, "error loading role-based audit policies: %v", err)
+	}
+}
+
+// refresh reloads the policies from system.role_audit_policies. Rows
+// that fail to validate, which can only be inserted by modifying the
+// table directly, are skipped.
+func (r *Registry) refresh(ctx context.Context) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.VersionRoleAuditPolicies) {
+		return nil
+	}
+	rows, err := r.ie.QueryEx(ctx, "role-audit-poll", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		"SELECT role_pattern, statements FROM system.role_audit_policies ORDER BY role_pattern")
+	if err != nil {
+		return err
+	}
+	policies := make([]Policy, 0, len(rows))
+	for _, row := range rows {
+		pattern := string(tree.MustBeDString(row[0]))
+		filter, err := ParseStatementFilter(string(tree.MustBeDString(row[1])))
+		if err == nil {
+			err = ValidateRolePattern(pattern)
+		}
+		if err != nil {
+			log.Warningf(ctx, "skipping role-based audit policy for %q: %v", pattern, err)
+			continue
+		}
+		policies = append(policies, Policy{RolePattern: pattern, Statements: filter})
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.policies = policies
+	return nil
+}
+
+// HasPolicies returns whether any policy is defined. It allows callers
+// to skip looking up the role memberships of the user when auditing is
+// not configured.
+func (r *Registry) HasPolicies() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.mu.policies) != 0
+}
+
+// Policies returns the policies currently in effect on this node, sorted
+// by role pattern.
+func (r *Registry) Policies() []Policy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mu.policies
+}
+
+// Match returns the first policy, in role pattern order, which applies
+// to one of the given roles and selects the given statement. The roles
+// are those of the user executing the statement: the user itself first,
+// then the roles it is a member of. The matched role is returned along
+// with the policy.
+func (r *Registry) Match(stmt tree.Statement, roles []string) (Policy, string, bool) {
+	for _, p := range r.Policies() {
+		if !p.Statements.Selects(stmt) {
+			continue
+		}
+		for _, role := range roles {
+			if p.MatchesRole(role) {
+				return p, role, true
+			}
+		}
+	}
+	return Policy{}, "", false
+}
+
+// SetPolicy implements the tree.RoleAuditPolicyConfigurator interface. It
+// creates or replaces the policy for the given role pattern. The change
+// takes effect immediately on this node, and within the polling interval
+// on the other nodes.
+func (r *Registry) SetPolicy(ctx context.Context, rolePattern string, statements string) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.VersionRoleAuditPolicies) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"role-based audit policies are only supported after the cluster version is upgraded")
+	}
+	if err := ValidateRolePattern(rolePattern); err != nil {
+		return err
+	}
+	filter, err := ParseStatementFilter(statements)
+	if err != nil {
+		return err
+	}
+	if _, err := r.ie.ExecEx(ctx, "role-audit-set", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		"UPSERT INTO system.role_audit_policies (role_pattern, statements, created) VALUES ($1, $2, now())",
+		rolePattern, filter.String(),
+	); err != nil {
+		return err
+	}
+	return r.refresh(ctx)
+}
+
+// ClearPolicy implements the tree.RoleAuditPolicyConfigurator interface.
+// It removes the policy for the given role pattern, and returns whether
+// there was one.
+func (r *Registry) ClearPolicy(ctx context.Context, rolePattern string) (bool, error) {
+	if !r.st.Version.IsActive(ctx, clusterversion.VersionRoleAuditPolicies) {
+		return false, pgerror.New(pgcode.FeatureNotSupported,
+			"role-based audit policies are only supported after the cluster version is upgraded")
+	}
+	n, err := r.ie.ExecEx(ctx, "role-audit-clear", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUser},
+		"DELETE FROM system.role_audit_policies WHERE role_pattern = $1",
+		rolePattern,
+	)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, r.refresh(ctx)
+}
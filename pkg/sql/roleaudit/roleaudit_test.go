@@ -0,0 +1,119 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package roleaudit
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatementFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		in       string
+		expected StatementFilter
+	}{
+		{"ALL", AllStatements},
+		{"ddl", DDLStatements},
+		{"Write", WriteStatements},
+	} {
+		f, err := ParseStatementFilter(tc.in)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, f)
+	}
+	_, err := ParseStatementFilter("READ")
+	require.EqualError(t, err, `invalid audit statements "READ": expected one of ALL, DDL, WRITE`)
+}
+
+func TestPolicyMatchesRole(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		pattern string
+		role    string
+		matches bool
+	}{
+		{"dba", "dba", true},
+		{"dba", "dba_1", false},
+		{"dba_*", "dba_1", true},
+		{"dba_*", "dba_", true},
+		{"dba_*", "dba", false},
+		{"*", "root", true},
+	} {
+		require.NoError(t, ValidateRolePattern(tc.pattern))
+		p := Policy{RolePattern: tc.pattern}
+		require.Equal(t, tc.matches, p.MatchesRole(tc.role), "%s ~ %s", tc.pattern, tc.role)
+	}
+	require.Error(t, ValidateRolePattern(""))
+	require.Error(t, ValidateRolePattern("d*a"))
+	require.Error(t, ValidateRolePattern("**"))
+}
+
+func TestStatementFilterSelects(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		sql   string
+		all   bool
+		ddl   bool
+		write bool
+	}{
+		{"SELECT 1", true, false, false},
+		{"INSERT INTO t VALUES (1)", true, false, true},
+		{"CREATE TABLE t (x INT)", true, true, true},
+		{"GRANT SELECT ON t TO u", true, true, true},
+	} {
+		stmt, err := parser.ParseOne(tc.sql)
+		require.NoError(t, err)
+		require.Equal(t, tc.all, AllStatements.Selects(stmt.AST), tc.sql)
+		require.Equal(t, tc.ddl, DDLStatements.Selects(stmt.AST), tc.sql)
+		require.Equal(t, tc.write, WriteStatements.Selects(stmt.AST), tc.sql)
+	}
+}
+
+func TestRegistryMatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var r Registry
+	require.False(t, r.HasPolicies())
+	r.mu.policies = []Policy{
+		{RolePattern: "app", Statements: DDLStatements},
+		{RolePattern: "dba_*", Statements: AllStatements},
+	}
+	require.True(t, r.HasPolicies())
+
+	selectStmt, err := parser.ParseOne("SELECT 1")
+	require.NoError(t, err)
+	createStmt, err := parser.ParseOne("CREATE TABLE t (x INT)")
+	require.NoError(t, err)
+
+	// The DDL policy of app doesn't select SELECT statements.
+	_, _, ok := r.Match(selectStmt.AST, []string{"app"})
+	require.False(t, ok)
+
+	p, role, ok := r.Match(createStmt.AST, []string{"app"})
+	require.True(t, ok)
+	require.Equal(t, "app", p.RolePattern)
+	require.Equal(t, "app", role)
+
+	// Users are subject to the policies of the roles they are members of.
+	p, role, ok = r.Match(selectStmt.AST, []string{"alice", "dba_eu", "public"})
+	require.True(t, ok)
+	require.Equal(t, "dba_*", p.RolePattern)
+	require.Equal(t, "dba_eu", role)
+
+	_, _, ok = r.Match(createStmt.AST, []string{"bob", "public"})
+	require.False(t, ok)
+}
@@ -185,6 +185,16 @@ func (rol List) Contains(p Option) bool {
 	return false
 }
 
+// Names returns the names of the role options in the list, without
+// their values.
+func (rol List) Names() []string {
+	names := make([]string, len(rol))
+	for i, ro := range rol {
+		names[i] = ro.Option.String()
+	}
+	return names
+}
+
 // CheckRoleOptionConflicts returns an error if two or more options conflict with each other.
 func (rol List) CheckRoleOptionConflicts() error {
 	roleOptionBits, err := rol.ToBitField()
@@ -0,0 +1,346 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rowexec
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// indexSkipTableReader is a processor that performs a loose index scan: it
+// visits each distinct value of a prefix of the index columns in turn, and
+// seeks directly to the next distinct prefix instead of reading the rows in
+// between. When the index has few distinct prefixes, this is much cheaper than
+// reading the whole index.
+//
+// If the spec has no suffix spans, the reader returns the first row of each
+// distinct prefix. Otherwise, for each distinct prefix, it reads and returns
+// all rows in the suffix spans appended to that prefix.
+type indexSkipTableReader struct {
+	execinfra.ProcessorBase
+
+	// spans are the spans of the index that are skipped through. The start key
+	// (or the end key, for a reverse scan) of the current span is advanced past
+	// each distinct prefix as it is visited.
+	spans       roachpb.Spans
+	currentSpan int
+
+	// suffixSpans are the spans over the index columns following the prefix.
+	suffixSpans roachpb.Spans
+
+	// prefixLen is the number of leading index columns that are skipped
+	// through.
+	prefixLen int
+
+	// prefix is the encoded key of the prefix whose suffix spans are currently
+	// being read. It is only set when there are suffix spans.
+	prefix roachpb.Key
+
+	reverse bool
+
+	fetcher row.Fetcher
+	alloc   rowenc.DatumAlloc
+
+	// rowsRead is the number of rows read and is tracked unconditionally.
+	rowsRead int64
+}
+
+var _ execinfra.Processor = &indexSkipTableReader{}
+var _ execinfra.RowSource = &indexSkipTableReader{}
+var _ execinfra.Releasable = &indexSkipTableReader{}
+var _ execinfra.IOReader = &indexSkipTableReader{}
+
+const indexSkipTableReaderProcName = "index skip table reader"
+
+var istrPool = sync.Pool{
+	New: func() interface{} {
+		return &indexSkipTableReader{}
+	},
+}
+
+// newIndexSkipTableReader creates an indexSkipTableReader.
+func newIndexSkipTableReader(
+	flowCtx *execinfra.FlowCtx,
+	processorID int32,
+	spec *execinfrapb.IndexSkipTableReaderSpec,
+	post *execinfrapb.PostProcessSpec,
+	output execinfra.RowReceiver,
+) (*indexSkipTableReader, error) {
+	if spec.PrefixLen == 0 {
+		return nil, errors.AssertionFailedf("index skip table reader with an empty prefix")
+	}
+	if spec.Reverse && len(spec.SuffixSpans) > 0 {
+		return nil, errors.AssertionFailedf("reverse index skip table reader with suffix spans")
+	}
+
+	t := istrPool.Get().(*indexSkipTableReader)
+
+	tableDesc := tabledesc.NewImmutable(spec.Table)
+	returnMutations := spec.Visibility == execinfra.ScanVisibilityPublicAndNotPublic
+	resultTypes := tableDesc.ColumnTypesWithMutations(returnMutations)
+	columnIdxMap := tableDesc.ColumnIdxMapWithMutations(returnMutations)
+
+	// Add all requested system columns to the output.
+	var sysColDescs []descpb.ColumnDescriptor
+	if spec.HasSystemColumns {
+		sysColDescs = colinfo.AllSystemColumnDescs
+	}
+	for i := range sysColDescs {
+		resultTypes = append(resultTypes, sysColDescs[i].Type)
+		columnIdxMap[sysColDescs[i].ID] = len(columnIdxMap)
+	}
+
+	if err := t.Init(
+		t,
+		post,
+		resultTypes,
+		flowCtx,
+		processorID,
+		output,
+		nil, /* memMonitor */
+		execinfra.ProcStateOpts{
+			InputsToDrain:        nil,
+			TrailingMetaCallback: t.generateTrailingMeta,
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	neededColumns := t.Out.NeededColumns()
+	t.reverse = spec.Reverse
+	t.prefixLen = int(spec.PrefixLen)
+
+	index, _, err := initRowFetcher(
+		flowCtx,
+		&t.fetcher,
+		tableDesc,
+		int(spec.IndexIdx),
+		columnIdxMap,
+		spec.Reverse,
+		neededColumns,
+		false, /* isCheck */
+		flowCtx.EvalCtx.Mon,
+		&t.alloc,
+		spec.Visibility,
+		spec.LockingStrength,
+		spec.LockingWaitPolicy,
+		sysColDescs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if t.prefixLen > len(index.ColumnIDs) {
+		return nil, errors.AssertionFailedf(
+			"skip prefix of %d columns on index %q with %d columns",
+			t.prefixLen, index.Name, len(index.ColumnIDs),
+		)
+	}
+
+	t.spans = make(roachpb.Spans, len(spec.Spans))
+	for i, s := range spec.Spans {
+		t.spans[i] = s.Span
+	}
+	t.suffixSpans = make(roachpb.Spans, len(spec.SuffixSpans))
+	for i, s := range spec.SuffixSpans {
+		t.suffixSpans[i] = s.Span
+	}
+	if t.reverse {
+		t.currentSpan = len(t.spans) - 1
+	}
+
+	return t, nil
+}
+
+// Start is part of the RowSource interface.
+func (t *indexSkipTableReader) Start(ctx context.Context) context.Context {
+	if t.FlowCtx.Txn == nil {
+		log.Fatalf(ctx, "indexSkipTableReader outside of txn")
+	}
+	return t.StartInternal(ctx, indexSkipTableReaderProcName)
+}
+
+// Next is part of the RowSource interface.
+func (t *indexSkipTableReader) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetadata) {
+	for t.State == execinfra.StateRunning {
+		if t.prefix != nil {
+			// We are reading the suffix spans of the current prefix.
+			row, _, _, err := t.fetcher.NextRow(t.Ctx)
+			if err != nil {
+				t.MoveToDraining(err)
+				break
+			}
+			if row == nil {
+				// The suffix spans of this prefix are exhausted; skip past it.
+				t.spans[t.currentSpan].Key = t.prefix.PrefixEnd()
+				t.prefix = nil
+				continue
+			}
+			t.rowsRead++
+			if outRow := t.ProcessRowHelper(row); outRow != nil {
+				return outRow, nil
+			}
+			continue
+		}
+
+		if t.currentSpan < 0 || t.currentSpan >= len(t.spans) {
+			t.MoveToDraining(nil /* err */)
+			break
+		}
+
+		// Find the next distinct prefix by reading a single row from the
+		// remainder of the current span.
+		if err := t.fetcher.StartScan(
+			t.Ctx,
+			t.FlowCtx.Txn,
+			roachpb.Spans{t.spans[t.currentSpan]},
+			true, /* limitBatches */
+			1,    /* limitHint */
+			t.FlowCtx.TraceKV,
+		); err != nil {
+			t.MoveToDraining(err)
+			break
+		}
+		key, err := t.fetcher.PartialKey(t.prefixLen)
+		if err != nil {
+			t.MoveToDraining(err)
+			break
+		}
+		if key == nil {
+			// The current span is exhausted.
+			t.advanceSpan()
+			continue
+		}
+
+		if len(t.suffixSpans) > 0 {
+			// The key is owned by the fetcher, so it has to be copied before the
+			// next scan is started.
+			t.prefix = append(roachpb.Key(nil), key...)
+			if err := t.fetcher.StartScan(
+				t.Ctx,
+				t.FlowCtx.Txn,
+				t.makePrefixSpans(t.prefix),
+				true, /* limitBatches */
+				0,    /* limitHint */
+				t.FlowCtx.TraceKV,
+			); err != nil {
+				t.MoveToDraining(err)
+				break
+			}
+			continue
+		}
+
+		// Compute the key to skip to before the key is invalidated by
+		// NextRow.
+		if t.reverse {
+			t.spans[t.currentSpan].EndKey = append(roachpb.Key(nil), key...)
+		} else {
+			t.spans[t.currentSpan].Key = key.PrefixEnd()
+		}
+		row, _, _, err := t.fetcher.NextRow(t.Ctx)
+		if err != nil {
+			t.MoveToDraining(err)
+			break
+		}
+		if row == nil {
+			t.advanceSpan()
+			continue
+		}
+		t.rowsRead++
+		if outRow := t.ProcessRowHelper(row); outRow != nil {
+			return outRow, nil
+		}
+	}
+	return nil, t.DrainHelper()
+}
+
+// advanceSpan moves on to the next span in the scan direction.
+func (t *indexSkipTableReader) advanceSpan() {
+	if t.reverse {
+		t.currentSpan--
+	} else {
+		t.currentSpan++
+	}
+}
+
+// makePrefixSpans returns the spans to read for the given prefix, formed by
+// appending each suffix span to the prefix.
+func (t *indexSkipTableReader) makePrefixSpans(prefix roachpb.Key) roachpb.Spans {
+	spans := make(roachpb.Spans, len(t.suffixSpans))
+	for i, s := range t.suffixSpans {
+		spans[i].Key = append(prefix[:len(prefix):len(prefix)], s.Key...)
+		if len(s.EndKey) == 0 {
+			spans[i].EndKey = prefix.PrefixEnd()
+		} else {
+			spans[i].EndKey = append(prefix[:len(prefix):len(prefix)], s.EndKey...)
+		}
+	}
+	return spans
+}
+
+// Release releases this indexSkipTableReader back to the pool.
+func (t *indexSkipTableReader) Release() {
+	t.ProcessorBase.Reset()
+	t.fetcher.Reset()
+	*t = indexSkipTableReader{
+		ProcessorBase: t.ProcessorBase,
+		fetcher:       t.fetcher,
+		spans:         t.spans[:0],
+		suffixSpans:   t.suffixSpans[:0],
+	}
+	istrPool.Put(t)
+}
+
+func (t *indexSkipTableReader) close() {
+	if t.InternalClose() {
+		t.fetcher.Close(t.Ctx)
+	}
+}
+
+// ConsumerClosed is part of the RowSource interface.
+func (t *indexSkipTableReader) ConsumerClosed() {
+	t.close()
+}
+
+// GetBytesRead is part of the execinfra.IOReader interface.
+func (t *indexSkipTableReader) GetBytesRead() int64 {
+	return t.fetcher.GetBytesRead()
+}
+
+// GetRowsRead is part of the execinfra.IOReader interface.
+func (t *indexSkipTableReader) GetRowsRead() int64 {
+	return t.rowsRead
+}
+
+func (t *indexSkipTableReader) generateTrailingMeta(
+	ctx context.Context,
+) []execinfrapb.ProducerMetadata {
+	var trailingMeta []execinfrapb.ProducerMetadata
+	if tfs := execinfra.GetLeafTxnFinalState(ctx, t.FlowCtx.Txn); tfs != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{LeafTxnFinalState: tfs})
+	}
+	meta := execinfrapb.GetProducerMeta()
+	meta.Metrics = execinfrapb.GetMetricsMeta()
+	meta.Metrics.BytesRead, meta.Metrics.RowsRead = t.GetBytesRead(), t.GetRowsRead()
+	trailingMeta = append(trailingMeta, *meta)
+	t.close()
+	return trailingMeta
+}
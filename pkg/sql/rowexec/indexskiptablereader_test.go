@@ -0,0 +1,303 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rowexec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catalogkv"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/distsqlutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestIndexSkipTableReader(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	// Create a table where each row is:
+	//
+	//  |     a    |     b    |          s          |
+	//  |-------------------------------------------|
+	//  | rowId/10 | rowId%10 | IntToEnglish(rowId) |
+	//
+	// There are 10 distinct values of a (0 to 9), each with 10 values of b
+	// (0 to 9), except for a = 0, which has no b = 0 since row IDs start at 1.
+
+	aFn := func(row int) tree.Datum {
+		return tree.NewDInt(tree.DInt(row / 10))
+	}
+	bFn := func(row int) tree.Datum {
+		return tree.NewDInt(tree.DInt(row % 10))
+	}
+
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"a INT, b INT, s STRING, PRIMARY KEY (a, b)",
+		99,
+		sqlutils.ToRowFn(aFn, bFn, sqlutils.RowEnglishFn))
+
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+
+	makeIndexSpan := func(start, end int) execinfrapb.TableReaderSpan {
+		var span roachpb.Span
+		prefix := roachpb.Key(rowenc.MakeIndexKeyPrefix(keys.SystemSQLCodec, td, td.PrimaryIndex.ID))
+		span.Key = append(prefix, encoding.EncodeVarintAscending(nil, int64(start))...)
+		span.EndKey = append(span.EndKey, prefix...)
+		span.EndKey = append(span.EndKey, encoding.EncodeVarintAscending(nil, int64(end))...)
+		return execinfrapb.TableReaderSpan{Span: span}
+	}
+
+	// makeSuffixSpan returns a span over the values of b in [start, end). If
+	// end is negative, the span extends to the end of the prefix.
+	makeSuffixSpan := func(start, end int) execinfrapb.TableReaderSpan {
+		var span roachpb.Span
+		span.Key = encoding.EncodeVarintAscending(nil, int64(start))
+		if end >= 0 {
+			span.EndKey = encoding.EncodeVarintAscending(nil, int64(end))
+		}
+		return execinfrapb.TableReaderSpan{Span: span}
+	}
+
+	fullSpan := []execinfrapb.TableReaderSpan{{Span: td.PrimaryIndexSpan(keys.SystemSQLCodec)}}
+	outAB := execinfrapb.PostProcessSpec{
+		Projection:    true,
+		OutputColumns: []uint32{0, 1},
+	}
+
+	testCases := []struct {
+		desc     string
+		spec     execinfrapb.IndexSkipTableReaderSpec
+		post     execinfrapb.PostProcessSpec
+		expected string
+	}{
+		{
+			desc: "distinct prefixes",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:     fullSpan,
+				PrefixLen: 1,
+			},
+			post:     outAB,
+			expected: "[[0 1] [1 0] [2 0] [3 0] [4 0] [5 0] [6 0] [7 0] [8 0] [9 0]]",
+		},
+		{
+			desc: "distinct prefixes in reverse",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:     fullSpan,
+				PrefixLen: 1,
+				Reverse:   true,
+			},
+			post:     outAB,
+			expected: "[[9 9] [8 9] [7 9] [6 9] [5 9] [4 9] [3 9] [2 9] [1 9] [0 9]]",
+		},
+		{
+			desc: "distinct prefixes within spans",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:     []execinfrapb.TableReaderSpan{makeIndexSpan(1, 3), makeIndexSpan(7, 8)},
+				PrefixLen: 1,
+			},
+			post:     outAB,
+			expected: "[[1 0] [2 0] [7 0]]",
+		},
+		{
+			desc: "distinct prefixes with a filter",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:     fullSpan,
+				PrefixLen: 1,
+			},
+			post: execinfrapb.PostProcessSpec{
+				Filter:        execinfrapb.Expression{Expr: "@1 % 3 = 0"}, // a % 3 = 0
+				Projection:    true,
+				OutputColumns: []uint32{2}, // s
+			},
+			expected: "[['one'] ['three-zero'] ['six-zero'] ['nine-zero']]",
+		},
+		{
+			desc: "single suffix span",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:       fullSpan,
+				PrefixLen:   1,
+				SuffixSpans: []execinfrapb.TableReaderSpan{makeSuffixSpan(5, 6)},
+			},
+			post:     outAB,
+			expected: "[[0 5] [1 5] [2 5] [3 5] [4 5] [5 5] [6 5] [7 5] [8 5] [9 5]]",
+		},
+		{
+			desc: "multiple suffix spans",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:     []execinfrapb.TableReaderSpan{makeIndexSpan(0, 3)},
+				PrefixLen: 1,
+				SuffixSpans: []execinfrapb.TableReaderSpan{
+					makeSuffixSpan(0, 1), makeSuffixSpan(8, -1),
+				},
+			},
+			post:     outAB,
+			expected: "[[0 8] [0 9] [1 0] [1 8] [1 9] [2 0] [2 8] [2 9]]",
+		},
+		{
+			desc: "suffix span with a limit",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:       fullSpan,
+				PrefixLen:   1,
+				SuffixSpans: []execinfrapb.TableReaderSpan{makeSuffixSpan(3, 5)},
+			},
+			post: execinfrapb.PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1},
+				Limit:         5,
+			},
+			expected: "[[0 3] [0 4] [1 3] [1 4] [2 3]]",
+		},
+		{
+			desc: "empty suffix span",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:       fullSpan,
+				PrefixLen:   1,
+				SuffixSpans: []execinfrapb.TableReaderSpan{makeSuffixSpan(10, 20)},
+			},
+			post:     outAB,
+			expected: "[]",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			testutils.RunTrueAndFalse(t, "row-source", func(t *testing.T, rowSource bool) {
+				ts := c.spec
+				ts.Table = *td.TableDesc()
+
+				evalCtx := tree.MakeTestingEvalContext(s.ClusterSettings())
+				defer evalCtx.Stop(ctx)
+				flowCtx := execinfra.FlowCtx{
+					EvalCtx: &evalCtx,
+					Cfg:     &execinfra.ServerConfig{Settings: s.ClusterSettings()},
+					Txn:     kv.NewTxn(ctx, s.DB(), s.NodeID()),
+					NodeID:  evalCtx.NodeID,
+				}
+
+				var out execinfra.RowReceiver
+				var buf *distsqlutils.RowBuffer
+				if !rowSource {
+					buf = &distsqlutils.RowBuffer{}
+					out = buf
+				}
+				tr, err := newIndexSkipTableReader(&flowCtx, 0 /* processorID */, &ts, &c.post, out)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var results execinfra.RowSource
+				if rowSource {
+					tr.Start(ctx)
+					results = tr
+				} else {
+					tr.Run(ctx)
+					if !buf.ProducerClosed() {
+						t.Fatalf("output RowReceiver not closed")
+					}
+					buf.Start(ctx)
+					results = buf
+				}
+
+				var res rowenc.EncDatumRows
+				for {
+					row, meta := results.Next()
+					if meta != nil && meta.LeafTxnFinalState == nil && meta.Metrics == nil {
+						t.Fatalf("unexpected metadata: %+v", meta)
+					}
+					if row == nil {
+						break
+					}
+					res = append(res, row.Copy())
+				}
+				if result := res.String(tr.OutputTypes()); result != c.expected {
+					t.Errorf("invalid results: %s, expected %s'", result, c.expected)
+				}
+			})
+		})
+	}
+}
+
+// TestIndexSkipTableReaderInvalidSpec verifies that an indexSkipTableReader
+// cannot be created from an invalid spec.
+func TestIndexSkipTableReaderInvalidSpec(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"a INT, b INT, PRIMARY KEY (a, b)",
+		1,
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, sqlutils.RowIdxFn))
+
+	td := catalogkv.TestingGetTableDescriptor(kvDB, keys.SystemSQLCodec, "test", "t")
+	fullSpan := []execinfrapb.TableReaderSpan{{Span: td.PrimaryIndexSpan(keys.SystemSQLCodec)}}
+
+	testCases := []struct {
+		desc string
+		spec execinfrapb.IndexSkipTableReaderSpec
+	}{
+		{
+			desc: "empty prefix",
+			spec: execinfrapb.IndexSkipTableReaderSpec{Spans: fullSpan},
+		},
+		{
+			desc: "prefix longer than the index",
+			spec: execinfrapb.IndexSkipTableReaderSpec{Spans: fullSpan, PrefixLen: 3},
+		},
+		{
+			desc: "reverse with suffix spans",
+			spec: execinfrapb.IndexSkipTableReaderSpec{
+				Spans:       fullSpan,
+				PrefixLen:   1,
+				Reverse:     true,
+				SuffixSpans: fullSpan,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			ts := c.spec
+			ts.Table = *td.TableDesc()
+
+			evalCtx := tree.MakeTestingEvalContext(s.ClusterSettings())
+			defer evalCtx.Stop(ctx)
+			flowCtx := execinfra.FlowCtx{
+				EvalCtx: &evalCtx,
+				Cfg:     &execinfra.ServerConfig{Settings: s.ClusterSettings()},
+				Txn:     kv.NewTxn(ctx, s.DB(), s.NodeID()),
+				NodeID:  evalCtx.NodeID,
+			}
+			if _, err := newIndexSkipTableReader(
+				&flowCtx, 0 /* processorID */, &ts, &execinfrapb.PostProcessSpec{}, &distsqlutils.RowBuffer{},
+			); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/invertedeval"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
@@ -43,7 +44,7 @@ const (
 
 // TODO(sumeer): support pre-filtering, akin to the invertedJoiner, by passing
 // relationship info and parameters in the spec and using it to construct a
-// preFilterer.
+// invertedeval.PreFilterer.
 type invertedFilterer struct {
 	execinfra.ProcessorBase
 	runningState   invertedFiltererState
@@ -53,9 +54,9 @@ type invertedFilterer struct {
 	diskMonitor *mon.BytesMonitor
 	rc          *rowcontainer.DiskBackedNumberedRowContainer
 
-	invertedEval batchedInvertedExprEvaluator
+	invertedEval invertedeval.BatchedInvertedExprEvaluator
 	// The invertedEval result.
-	evalResult []KeyIndex
+	evalResult []invertedeval.KeyIndex
 	// The next result row, i.e., evalResult[resultIdx].
 	resultIdx int
 
@@ -83,8 +84,8 @@ func newInvertedFilterer(
 	ifr := &invertedFilterer{
 		input:          input,
 		invertedColIdx: spec.InvertedColIdx,
-		invertedEval: batchedInvertedExprEvaluator{
-			exprs: []*invertedexpr.SpanExpressionProto{&spec.InvertedExpr},
+		invertedEval: invertedeval.BatchedInvertedExprEvaluator{
+			Exprs: []*invertedexpr.SpanExpressionProto{&spec.InvertedExpr},
 		},
 	}
 
@@ -93,7 +94,7 @@ func newInvertedFilterer(
 	// de-duping. It will reduce the container memory/disk by 2x.
 
 	// Prepare inverted evaluator for later evaluation.
-	ifr.invertedEval.init()
+	ifr.invertedEval.Init()
 
 	// The RowContainer columns are the PK columns, that are the columns
 	// other than the inverted column. The output has the same types as
@@ -182,7 +183,7 @@ func (ifr *invertedFilterer) readInput() (invertedFiltererState, *execinfrapb.Pr
 	}
 	if row == nil {
 		log.VEventf(ifr.Ctx, 1, "no more input rows")
-		evalResult := ifr.invertedEval.evaluate()
+		evalResult := ifr.invertedEval.Evaluate()
 		ifr.rc.SetupForRead(ifr.Ctx, evalResult)
 		// invertedEval had a single expression in the batch, and the results
 		// for that expression are in evalResult[0].
@@ -208,11 +209,11 @@ func (ifr *invertedFilterer) readInput() (invertedFiltererState, *execinfrapb.Pr
 		return ifrStateUnknown, ifr.DrainHelper()
 	}
 	// Add to the evaluator.
-	if _, err = ifr.invertedEval.prepareAddIndexRow(row[ifr.invertedColIdx].EncodedBytes()); err != nil {
+	if _, err = ifr.invertedEval.PrepareAddIndexRow(row[ifr.invertedColIdx].EncodedBytes()); err != nil {
 		ifr.MoveToDraining(err)
 		return ifrStateUnknown, ifr.DrainHelper()
 	}
-	if err = ifr.invertedEval.addIndexRow(keyIndex); err != nil {
+	if err = ifr.invertedEval.AddIndexRow(keyIndex); err != nil {
 		ifr.MoveToDraining(err)
 		return ifrStateUnknown, ifr.DrainHelper()
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/invertedeval"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedidx"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
@@ -111,10 +112,10 @@ type invertedJoiner struct {
 
 	// State variables for each batch of input rows.
 	inputRows       rowenc.EncDatumRows
-	batchedExprEval batchedInvertedExprEvaluator
+	batchedExprEval invertedeval.BatchedInvertedExprEvaluator
 	// The row indexes that are the result of the inverted expression evaluation
 	// of the join. These will be further filtered using the onExpr.
-	joinedRowIdx [][]KeyIndex
+	joinedRowIdx [][]invertedeval.KeyIndex
 
 	// The container for the primary key rows retrieved from the index. For
 	// evaluating each inverted expression, which involved set unions and
@@ -243,7 +244,7 @@ func newInvertedJoiner(
 	}
 	ij.canPreFilter = ij.datumsToInvertedExpr.CanPreFilter()
 	if ij.canPreFilter {
-		ij.batchedExprEval.filterer = ij.datumsToInvertedExpr
+		ij.batchedExprEval.Filterer = ij.datumsToInvertedExpr
 	}
 
 	var fetcher row.Fetcher
@@ -317,7 +318,7 @@ func (ij *invertedJoiner) generateSpan(enc []byte) (roachpb.Span, error) {
 	return span, err
 }
 
-func (ij *invertedJoiner) generateSpans(invertedSpans []invertedSpan) ([]roachpb.Span, error) {
+func (ij *invertedJoiner) generateSpans(invertedSpans []invertedeval.InvertedSpan) ([]roachpb.Span, error) {
 	spans := make([]roachpb.Span, len(invertedSpans))
 	for i, span := range invertedSpans {
 		startSpan, err := ij.generateSpan(span.Start)
@@ -340,7 +341,7 @@ func (ij *invertedJoiner) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetad
 	// The join is implemented as follows:
 	// - Read the input rows in batches.
 	// - For each batch, map the rows to SpanExpressionProtos and initialize
-	//   a batchedInvertedExprEvaluator. Use that evaluator to generate spans
+	//   a BatchedInvertedExprEvaluator. Use that evaluator to generate spans
 	//   to read from the inverted index.
 	// - Retrieve the index rows and add the primary keys in these rows to the
 	//   row container, that de-duplicates, and pass the de-duplicated keys to
@@ -407,14 +408,14 @@ func (ij *invertedJoiner) readInput() (invertedJoinerState, *execinfrapb.Produce
 			// One of the input columns was NULL, resulting in a nil expression.
 			// The nil serves as a marker that will result in an empty set as the
 			// evaluation result.
-			ij.batchedExprEval.exprs = append(ij.batchedExprEval.exprs, nil)
+			ij.batchedExprEval.Exprs = append(ij.batchedExprEval.Exprs, nil)
 			if ij.canPreFilter {
-				ij.batchedExprEval.preFilterState = append(ij.batchedExprEval.preFilterState, nil)
+				ij.batchedExprEval.PreFilterState = append(ij.batchedExprEval.PreFilterState, nil)
 			}
 		} else {
-			ij.batchedExprEval.exprs = append(ij.batchedExprEval.exprs, expr)
+			ij.batchedExprEval.Exprs = append(ij.batchedExprEval.Exprs, expr)
 			if ij.canPreFilter {
-				ij.batchedExprEval.preFilterState = append(ij.batchedExprEval.preFilterState, preFilterState)
+				ij.batchedExprEval.PreFilterState = append(ij.batchedExprEval.PreFilterState, preFilterState)
 			}
 		}
 	}
@@ -427,7 +428,7 @@ func (ij *invertedJoiner) readInput() (invertedJoinerState, *execinfrapb.Produce
 	}
 	log.VEventf(ij.Ctx, 1, "read %d input rows", len(ij.inputRows))
 
-	spans := ij.batchedExprEval.init()
+	spans := ij.batchedExprEval.Init()
 	if len(spans) == 0 {
 		// Nothing to scan. For each input row, place a nil slice in the joined
 		// rows, for emitRow() to process.
@@ -471,7 +472,7 @@ func (ij *invertedJoiner) performScan() (invertedJoinerState, *execinfrapb.Produ
 			break
 		}
 		encInvertedVal := scannedRow[ij.colIdxMap[ij.invertedColID]].EncodedBytes()
-		shouldAdd, err := ij.batchedExprEval.prepareAddIndexRow(encInvertedVal)
+		shouldAdd, err := ij.batchedExprEval.PrepareAddIndexRow(encInvertedVal)
 		if err != nil {
 			ij.MoveToDraining(err)
 			return ijStateUnknown, ij.DrainHelper()
@@ -483,13 +484,13 @@ func (ij *invertedJoiner) performScan() (invertedJoinerState, *execinfrapb.Produ
 				ij.MoveToDraining(err)
 				return ijStateUnknown, ij.DrainHelper()
 			}
-			if err = ij.batchedExprEval.addIndexRow(rowIdx); err != nil {
+			if err = ij.batchedExprEval.AddIndexRow(rowIdx); err != nil {
 				ij.MoveToDraining(err)
 				return ijStateUnknown, ij.DrainHelper()
 			}
 		}
 	}
-	ij.joinedRowIdx = ij.batchedExprEval.evaluate()
+	ij.joinedRowIdx = ij.batchedExprEval.Evaluate()
 	ij.keyRows.SetupForRead(ij.Ctx, ij.joinedRowIdx)
 	log.VEventf(ij.Ctx, 1, "done evaluating expressions")
 
@@ -508,7 +509,7 @@ func (ij *invertedJoiner) emitRow() (
 		log.VEventf(ij.Ctx, 1, "done emitting rows")
 		// Ready for another input batch. Reset state.
 		ij.inputRows = ij.inputRows[:0]
-		ij.batchedExprEval.reset()
+		ij.batchedExprEval.Reset()
 		ij.joinedRowIdx = nil
 		ij.emitCursor.outputRowIdx = 0
 		ij.emitCursor.inputRowIdx = 0
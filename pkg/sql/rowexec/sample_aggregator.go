@@ -447,9 +447,16 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				histogram = &h
 			}
 
-			columnIDs := make([]descpb.ColumnID, len(si.spec.Columns))
-			for i, c := range si.spec.Columns {
-				columnIDs[i] = s.sampledCols[c]
+			var columnIDs []descpb.ColumnID
+			if si.spec.Expression != "" {
+				// The sketch column is the result of the expression, which is not a
+				// table column.
+				columnIDs = si.spec.ExpressionColumnIDs
+			} else {
+				columnIDs = make([]descpb.ColumnID, len(si.spec.Columns))
+				for i, c := range si.spec.Columns {
+					columnIDs[i] = s.sampledCols[c]
+				}
 			}
 
 			// Delete old stats that have been superseded.
@@ -459,6 +466,7 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				txn,
 				s.tableID,
 				columnIDs,
+				si.spec.Expression,
 			); err != nil {
 				return err
 			}
@@ -475,6 +483,7 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				distinctCount,
 				si.numNulls,
 				histogram,
+				si.spec.Expression,
 			); err != nil {
 				return err
 			}
@@ -69,6 +69,14 @@ type scanNode struct {
 	spans   []roachpb.Span
 	reverse bool
 
+	// If non-zero, the scan is a loose index scan that skips through the
+	// distinct values of this many leading index columns. skipScanSuffixSpans
+	// are the spans over the following index columns that are read for each
+	// distinct prefix (see execinfrapb.IndexSkipTableReaderSpec); if empty, a
+	// single row is read for each distinct prefix.
+	skipScanPrefixLen   int
+	skipScanSuffixSpans roachpb.Spans
+
 	reqOrdering ReqOrdering
 
 	// if non-zero, hardLimit indicates that the scanNode only needs to provide
@@ -49,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -1254,9 +1255,19 @@ func (sc *SchemaChanger) done(ctx context.Context) error {
 
 	descsToUpdate := append(tableIDsToUpdate, referencedTypeIDs...)
 	_, err = sc.leaseMgr.PublishMultiple(ctx, descsToUpdate, update, func(txn *kv.Txn) error {
-		schemaChangeEventType := EventLogFinishSchemaChange
+		var event eventpb.EventPayload
 		if isRollback {
-			schemaChangeEventType = EventLogFinishSchemaRollback
+			event = &eventpb.FinishSchemaChangeRollback{
+				CommonSchemaChangeEventDetails: eventpb.CommonSchemaChangeEventDetails{
+					MutationID: uint32(sc.mutationID),
+				},
+			}
+		} else {
+			event = &eventpb.FinishSchemaChange{
+				CommonSchemaChangeEventDetails: eventpb.CommonSchemaChangeEventDetails{
+					MutationID: uint32(sc.mutationID),
+				},
+			}
 		}
 
 		// Log "Finish Schema Change" or "Finish Schema Change Rollback"
@@ -1264,14 +1275,7 @@ func (sc *SchemaChanger) done(ctx context.Context) error {
 		// be correlated with the DDL statement that initiated the change
 		// using the mutation id.
 		return MakeEventLogger(sc.execCfg).InsertEventRecord(
-			ctx,
-			txn,
-			schemaChangeEventType,
-			int32(sc.descID),
-			int32(sc.sqlInstanceID),
-			struct {
-				MutationID uint32
-			}{uint32(sc.mutationID)},
+			ctx, txn, int32(sc.descID), int32(sc.sqlInstanceID), event,
 		)
 	})
 	if fn := sc.testingKnobs.RunBeforeChildJobs; fn != nil {
@@ -1530,13 +1534,14 @@ func (sc *SchemaChanger) maybeReverseMutations(ctx context.Context, causingError
 		return MakeEventLogger(sc.execCfg).InsertEventRecord(
 			ctx,
 			txn,
-			EventLogReverseSchemaChange,
 			int32(sc.descID),
 			int32(sc.sqlInstanceID),
-			struct {
-				Error      string
-				MutationID uint32
-			}{fmt.Sprintf("%+v", causingError), uint32(sc.mutationID)},
+			&eventpb.ReverseSchemaChange{
+				CommonSchemaChangeEventDetails: eventpb.CommonSchemaChangeEventDetails{
+					MutationID: uint32(sc.mutationID),
+				},
+				Error: fmt.Sprintf("%+v", causingError),
+			},
 		)
 	})
 	if err != nil {
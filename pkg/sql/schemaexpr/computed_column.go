@@ -116,7 +116,7 @@ func (v *ComputedColumnValidator) Validate(d *tree.ColumnTableDef) error {
 	// are no variable expressions (besides dummyColumnItems) and no impure
 	// functions. In order to safely serialize user defined types and their
 	// members, we need to serialize the typed expression here.
-	expr, _, err := DequalifyAndValidateExpr(
+	expr, _, _, err := DequalifyAndValidateExpr(
 		v.ctx,
 		v.desc,
 		d.Computed.Expr,
@@ -27,8 +27,8 @@ import (
 
 // DequalifyAndValidateExpr validates that an expression has the given type
 // and contains no functions with a volatility greater than maxVolatility. The
-// type-checked and constant-folded expression and the set of column IDs within
-// the expression are returned, if valid.
+// type-checked and constant-folded expression, its type, and the set of column
+// IDs within the expression are returned, if valid.
 //
 // The serialized expression is returned because returning the created
 // tree.TypedExpr would be dangerous. It contains dummyColumns which do not
@@ -43,7 +43,7 @@ func DequalifyAndValidateExpr(
 	semaCtx *tree.SemaContext,
 	maxVolatility tree.Volatility,
 	tn *tree.TableName,
-) (string, TableColSet, error) {
+) (string, *types.T, TableColSet, error) {
 	var colIDs TableColSet
 	sourceInfo := colinfo.NewSourceInfoForSingleTable(
 		*tn, colinfo.ResultColumnsFromColDescs(
@@ -53,14 +53,14 @@ func DequalifyAndValidateExpr(
 	)
 	expr, err := dequalifyColumnRefs(ctx, sourceInfo, expr)
 	if err != nil {
-		return "", colIDs, err
+		return "", nil, colIDs, err
 	}
 
 	// Replace the column variables with dummyColumns so that they can be
 	// type-checked.
 	replacedExpr, colIDs, err := replaceColumnVars(desc, expr)
 	if err != nil {
-		return "", colIDs, err
+		return "", nil, colIDs, err
 	}
 
 	typedExpr, err := SanitizeVarFreeExpr(
@@ -73,10 +73,10 @@ func DequalifyAndValidateExpr(
 	)
 
 	if err != nil {
-		return "", colIDs, err
+		return "", nil, colIDs, err
 	}
 
-	return tree.Serialize(typedExpr), colIDs, nil
+	return tree.Serialize(typedExpr), typedExpr.ResolvedType(), colIDs, nil
 }
 
 // ExtractColumnIDs returns the set of column IDs within the given expression.
@@ -111,6 +111,32 @@ func ExtractColumnIDs(desc catalog.TableDescriptor, rootExpr tree.Expr) (TableCo
 	return colIDs, err
 }
 
+// MakeExprOnColumns parses and type-checks a serialized expression over the
+// columns of the given table, such as the expression of a statistic. The
+// column references are replaced with IndexedVars which refer to the ordinals
+// of the given columns.
+func MakeExprOnColumns(
+	ctx context.Context,
+	exprStr string,
+	desc catalog.TableDescriptor,
+	cols []*descpb.ColumnDescriptor,
+	evalCtx *tree.EvalContext,
+	semaCtx *tree.SemaContext,
+) (tree.TypedExpr, error) {
+	expr, err := parser.ParseExpr(exprStr)
+	if err != nil {
+		return nil, err
+	}
+
+	tn := tree.MakeUnqualifiedTableName(tree.Name(desc.GetName()))
+	nr := newNameResolver(evalCtx, desc.GetID(), &tn, cols)
+	nr.addIVarContainerToSemaCtx(semaCtx)
+	if expr, err = nr.resolveNames(expr); err != nil {
+		return nil, err
+	}
+	return tree.TypeCheck(ctx, expr, semaCtx, types.Any)
+}
+
 // FormatExprForDisplay formats a schema expression string for display. It
 // accepts formatting flags to control things like showing type annotations or
 // type casts.
@@ -64,7 +64,7 @@ func MakeIndexPredicateValidator(
 //     functions.
 //
 func (v *IndexPredicateValidator) Validate(e tree.Expr) (string, error) {
-	expr, _, err := DequalifyAndValidateExpr(
+	expr, _, _, err := DequalifyAndValidateExpr(
 		v.ctx,
 		v.desc,
 		e,
@@ -4179,6 +4179,174 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	// Inserts a (possibly conditional) statement diagnostics request.
+	"crdb_internal.request_statement_bundle": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"fingerprint", types.String},
+				{"sampling_probability", types.Float},
+				{"min_execution_latency", types.Interval},
+				{"expires_after", types.Interval},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if evalCtx.SessionAccessor == nil || evalCtx.StmtDiagnosticsRequestInserter == nil {
+					return nil, errors.AssertionFailedf("statement diagnostics requests cannot be inserted from this context")
+				}
+				ctx := evalCtx.Ctx()
+				hasViewActivity, err := evalCtx.SessionAccessor.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+				if err != nil {
+					return nil, err
+				}
+				if !hasViewActivity {
+					return nil, pgerror.Newf(pgcode.InsufficientPrivilege,
+						"requesting statement bundles requires %s privilege", roleoption.VIEWACTIVITY)
+				}
+
+				stmtFingerprint := string(tree.MustBeDString(args[0]))
+				samplingProbability := float64(tree.MustBeDFloat(args[1]))
+				minExecutionLatency, err := intervalToDuration(tree.MustBeDInterval(args[2]))
+				if err != nil {
+					return nil, err
+				}
+				expiresAfter, err := intervalToDuration(tree.MustBeDInterval(args[3]))
+				if err != nil {
+					return nil, err
+				}
+				if err := evalCtx.StmtDiagnosticsRequestInserter(
+					ctx, stmtFingerprint, samplingProbability, minExecutionLatency, expiresAfter,
+				); err != nil {
+					return nil, err
+				}
+				return tree.DBoolTrue, nil
+			},
+			Info: "Requests a statement bundle for the statements matching the given fingerprint. " +
+				"Only an execution taking at least min_execution_latency (if non-zero) is collected, " +
+				"each execution is traced with sampling_probability (if non-zero), and the request " +
+				"expires after expires_after (if non-zero).",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	// Creates or replaces a role-based audit policy.
+	"crdb_internal.set_role_audit_policy": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"role_pattern", types.String},
+				{"statements", types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkRoleAuditPolicyAccess(evalCtx); err != nil {
+					return nil, err
+				}
+				rolePattern := string(tree.MustBeDString(args[0]))
+				statements := string(tree.MustBeDString(args[1]))
+				if err := evalCtx.RoleAuditPolicies.SetPolicy(evalCtx.Ctx(), rolePattern, statements); err != nil {
+					return nil, err
+				}
+				return tree.DBoolTrue, nil
+			},
+			Info: "Audits the statements executed by the users matching role_pattern, " +
+				"either directly or through their role memberships, in the SQL audit log. " +
+				"The role pattern is the name of a role, or a prefix followed by an asterisk to match " +
+				"all the roles with that prefix. The audited statements are ALL, DDL or WRITE " +
+				"(statements that can modify the schema or the data).",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	// Removes a role-based audit policy.
+	"crdb_internal.clear_role_audit_policy": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"role_pattern", types.String}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkRoleAuditPolicyAccess(evalCtx); err != nil {
+					return nil, err
+				}
+				rolePattern := string(tree.MustBeDString(args[0]))
+				found, err := evalCtx.RoleAuditPolicies.ClearPolicy(evalCtx.Ctx(), rolePattern)
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(tree.DBool(found)), nil
+			},
+			Info: "Removes the role-based audit policy for role_pattern. Returns false if " +
+				"there was no such policy.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	// Creates or replaces a plan baseline.
+	"crdb_internal.set_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"fingerprint", types.String},
+				{"hints", types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPlanBaselineAccess(evalCtx); err != nil {
+					return nil, err
+				}
+				fingerprint := string(tree.MustBeDString(args[0]))
+				hints := string(tree.MustBeDString(args[1]))
+				if err := evalCtx.PlanBaselines.SetBaseline(evalCtx.Ctx(), fingerprint, hints); err != nil {
+					return nil, err
+				}
+				return tree.DBoolTrue, nil
+			},
+			Info: "Pins the plan of the statements with the given fingerprint, as shown in " +
+				"crdb_internal.node_statement_statistics, by applying the given plan hints " +
+				"(for example 'Leading(a b) HashJoin(a b) IndexScan(b b_idx)') when planning them. " +
+				"Hints given in a comment of the statement take precedence over the baseline.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
+	// Removes a plan baseline.
+	"crdb_internal.clear_plan_baseline": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"fingerprint", types.String}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPlanBaselineAccess(evalCtx); err != nil {
+					return nil, err
+				}
+				fingerprint := string(tree.MustBeDString(args[0]))
+				found, err := evalCtx.PlanBaselines.ClearBaseline(evalCtx.Ctx(), fingerprint)
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(tree.DBool(found)), nil
+			},
+			Info: "Removes the plan baseline for the given statement fingerprint. Returns " +
+				"false if there was no such baseline.",
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
+
 	"num_nulls": makeBuiltin(
 		tree.FunctionProperties{
 			Category:     categoryComparison,
@@ -6278,3 +6446,47 @@ func followerReadTimestamp(ctx *tree.EvalContext, _ tree.Datums) (tree.Datum, er
 	}
 	return tree.MakeDTimestampTZ(ts, time.Microsecond)
 }
+
+// intervalToDuration converts an interval to a time.Duration, counting a day as
+// 24 hours and a month as 30 days.
+func intervalToDuration(interval *tree.DInterval) (time.Duration, error) {
+	nanos, _, _, err := interval.Duration.Encode()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(nanos), nil
+}
+
+// checkRoleAuditPolicyAccess checks that the current user can configure the
+// role-based audit policies, which is reserved to admins.
+func checkRoleAuditPolicyAccess(evalCtx *tree.EvalContext) error {
+	if evalCtx.SessionAccessor == nil || evalCtx.RoleAuditPolicies == nil {
+		return errors.AssertionFailedf("role-based audit policies cannot be configured from this context")
+	}
+	isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(evalCtx.Ctx())
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role are allowed to configure audit policies")
+	}
+	return nil
+}
+
+// checkPlanBaselineAccess checks that the current user can configure the plan
+// baselines, which is reserved to admins.
+func checkPlanBaselineAccess(evalCtx *tree.EvalContext) error {
+	if evalCtx.SessionAccessor == nil || evalCtx.PlanBaselines == nil {
+		return errors.AssertionFailedf("plan baselines cannot be configured from this context")
+	}
+	isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(evalCtx.Ctx())
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return pgerror.New(pgcode.InsufficientPrivilege,
+			"only users with the admin role are allowed to configure plan baselines")
+	}
+	return nil
+}
@@ -102,20 +102,20 @@ var generators = map[string]builtinDefinition{
 	),
 	"generate_series": makeBuiltin(genProps(),
 		// See https://www.postgresql.org/docs/current/static/functions-srf.html#FUNCTIONS-SRF-SERIES
-		makeGeneratorOverload(
+		withSpecializedVecBuiltin(makeGeneratorOverload(
 			tree.ArgTypes{{"start", types.Int}, {"end", types.Int}},
 			seriesValueGeneratorType,
 			makeSeriesGenerator,
 			"Produces a virtual table containing the integer values from `start` to `end`, inclusive.",
 			tree.VolatilityImmutable,
-		),
-		makeGeneratorOverload(
+		), tree.GenerateSeriesIntInt),
+		withSpecializedVecBuiltin(makeGeneratorOverload(
 			tree.ArgTypes{{"start", types.Int}, {"end", types.Int}, {"step", types.Int}},
 			seriesValueGeneratorType,
 			makeSeriesGenerator,
 			"Produces a virtual table containing the integer values from `start` to `end`, inclusive, by increment of `step`.",
 			tree.VolatilityImmutable,
-		),
+		), tree.GenerateSeriesIntIntInt),
 		makeGeneratorOverload(
 			tree.ArgTypes{{"start", types.Timestamp}, {"end", types.Timestamp}, {"step", types.Interval}},
 			seriesTSValueGeneratorType,
@@ -315,6 +315,15 @@ func makeGeneratorOverload(
 	return makeGeneratorOverloadWithReturnType(in, tree.FixedReturnType(ret), g, info, volatility)
 }
 
+// withSpecializedVecBuiltin marks the generator overload as having a native
+// implementation in the vectorized engine.
+func withSpecializedVecBuiltin(
+	o tree.Overload, vecBuiltin tree.SpecializedVectorizedBuiltin,
+) tree.Overload {
+	o.SpecializedVecBuiltin = vecBuiltin
+	return o
+}
+
 func newUnsuitableUseOfGeneratorError() error {
 	return errors.AssertionFailedf("generator functions cannot be evaluated as scalars")
 }
@@ -1631,8 +1631,11 @@ func (node *RefreshMaterializedView) Format(ctx *FmtCtx) {
 type CreateStats struct {
 	Name        Name
 	ColumnNames NameList
-	Table       TableExpr
-	Options     CreateStatsOptions
+	// Expr is the expression on which the statistic is created, if it is not
+	// created on a list of columns.
+	Expr    Expr
+	Table   TableExpr
+	Options CreateStatsOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -1643,6 +1646,10 @@ func (node *CreateStats) Format(ctx *FmtCtx) {
 	if len(node.ColumnNames) > 0 {
 		ctx.WriteString(" ON ")
 		ctx.FormatNode(&node.ColumnNames)
+	} else if node.Expr != nil {
+		ctx.WriteString(" ON (")
+		ctx.FormatNode(node.Expr)
+		ctx.WriteByte(')')
 	}
 
 	ctx.WriteString(" FROM ")
@@ -3114,6 +3114,40 @@ type TenantOperator interface {
 	DestroyTenant(ctx context.Context, tenantID uint64) error
 }
 
+// StmtDiagnosticsRequestInsertFunc inserts a statement diagnostics request for
+// the given statement fingerprint. It is used by builtins, which can't depend on
+// the stmtdiagnostics package directly.
+type StmtDiagnosticsRequestInsertFunc func(
+	ctx context.Context,
+	stmtFingerprint string,
+	samplingProbability float64,
+	minExecutionLatency time.Duration,
+	expiresAfter time.Duration,
+) error
+
+// RoleAuditPolicyConfigurator is used by builtins to configure the
+// role-based SQL audit logging policies. It is implemented by
+// *roleaudit.Registry, which builtins can't depend on directly.
+type RoleAuditPolicyConfigurator interface {
+	// SetPolicy creates or replaces the policy for the given role pattern.
+	SetPolicy(ctx context.Context, rolePattern string, statements string) error
+	// ClearPolicy removes the policy for the given role pattern, and returns
+	// whether there was one.
+	ClearPolicy(ctx context.Context, rolePattern string) (bool, error)
+}
+
+// PlanBaselineConfigurator is used by builtins to configure the plan
+// baselines. It is implemented by *planhints.Registry, which builtins can't
+// depend on directly.
+type PlanBaselineConfigurator interface {
+	// SetBaseline creates or replaces the baseline for the given statement
+	// fingerprint.
+	SetBaseline(ctx context.Context, fingerprint string, hints string) error
+	// ClearBaseline removes the baseline for the given statement fingerprint,
+	// and returns whether there was one.
+	ClearBaseline(ctx context.Context, fingerprint string) (bool, error)
+}
+
 // EvalContextTestingKnobs contains test knobs.
 type EvalContextTestingKnobs struct {
 	// AssertFuncExprReturnTypes indicates whether FuncExpr evaluations
@@ -3232,6 +3266,19 @@ type EvalContext struct {
 
 	Tenant TenantOperator
 
+	// StmtDiagnosticsRequestInserter is used by the
+	// crdb_internal.request_statement_bundle builtin to insert a statement
+	// diagnostics request.
+	StmtDiagnosticsRequestInserter StmtDiagnosticsRequestInsertFunc
+
+	// RoleAuditPolicies is used by the crdb_internal.set_role_audit_policy and
+	// crdb_internal.clear_role_audit_policy builtins.
+	RoleAuditPolicies RoleAuditPolicyConfigurator
+
+	// PlanBaselines is used by the crdb_internal.set_plan_baseline and
+	// crdb_internal.clear_plan_baseline builtins.
+	PlanBaselines PlanBaselineConfigurator
+
 	// The transaction in which the statement is executing.
 	Txn *kv.Txn
 	// A handle to the database.
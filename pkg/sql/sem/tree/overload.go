@@ -37,6 +37,8 @@ type SpecializedVectorizedBuiltin int
 // Keep this list alphabetized so that it is easy to manage.
 const (
 	_ SpecializedVectorizedBuiltin = iota
+	GenerateSeriesIntInt
+	GenerateSeriesIntIntInt
 	SubstringStringIntInt
 )
 
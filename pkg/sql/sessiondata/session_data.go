@@ -90,9 +90,16 @@ type SessionData struct {
 	// ZigzagJoinEnabled indicates whether the optimizer should try and plan a
 	// zigzag join.
 	ZigzagJoinEnabled bool
+	// EagerAggregationEnabled indicates whether the optimizer should try to
+	// push aggregations below joins.
+	EagerAggregationEnabled bool
 	// ReorderJoinsLimit indicates the number of joins at which the optimizer should
 	// stop attempting to reorder.
 	ReorderJoinsLimit int
+	// PlanCacheMode indicates whether prepared statements are executed with
+	// custom plans, which are optimized for the placeholder values of each
+	// execution, or with a generic plan, which is optimized once.
+	PlanCacheMode PlanCacheMode
 	// RequireExplicitPrimaryKeys indicates whether CREATE TABLE statements should
 	// error out if no primary key is provided.
 	RequireExplicitPrimaryKeys bool
@@ -250,6 +257,52 @@ func ExperimentalDistSQLPlanningModeFromString(val string) (ExperimentalDistSQLP
 	return m, true
 }
 
+// PlanCacheMode controls whether prepared statements are executed with custom
+// or generic plans. It is named after the equivalent Postgres setting.
+type PlanCacheMode int64
+
+const (
+	// PlanCacheModeForceCustom means that a custom plan is optimized for the
+	// placeholder values of each execution of a prepared statement.
+	PlanCacheModeForceCustom PlanCacheMode = iota
+	// PlanCacheModeForceGeneric means that a generic plan, in which the
+	// placeholders are evaluated during execution, is optimized once and used
+	// for all executions of a prepared statement.
+	PlanCacheModeForceGeneric
+	// PlanCacheModeAuto means that custom plans are used for the first
+	// executions of a prepared statement, after which the generic plan is used
+	// if its estimated cost is close to the average cost of the custom plans.
+	PlanCacheModeAuto
+)
+
+func (m PlanCacheMode) String() string {
+	switch m {
+	case PlanCacheModeForceCustom:
+		return "force_custom_plan"
+	case PlanCacheModeForceGeneric:
+		return "force_generic_plan"
+	case PlanCacheModeAuto:
+		return "auto"
+	default:
+		return fmt.Sprintf("invalid (%d)", m)
+	}
+}
+
+// PlanCacheModeFromString converts a string into a PlanCacheMode. False is
+// returned if the conversion was unsuccessful.
+func PlanCacheModeFromString(val string) (_ PlanCacheMode, ok bool) {
+	switch strings.ToLower(val) {
+	case "force_custom_plan":
+		return PlanCacheModeForceCustom, true
+	case "force_generic_plan":
+		return PlanCacheModeForceGeneric, true
+	case "auto":
+		return PlanCacheModeAuto, true
+	default:
+		return 0, false
+	}
+}
+
 // DistSQLExecMode controls if and when the Executor distributes queries.
 // Since 2.1, we run everything through the DistSQL infrastructure,
 // and these settings control whether to use a distributed plan, or use a plan
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/errors/hintdetail"
@@ -284,10 +285,16 @@ func (n *setClusterSettingNode) startExec(params runParams) error {
 		return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 			ctx,
 			txn,
-			EventLogSetClusterSetting,
 			0, /* no target */
 			int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
-			EventLogSetClusterSettingDetail{n.name, reportedValue, params.SessionData().User},
+			&eventpb.SetClusterSetting{
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					User:            params.SessionData().User,
+					ApplicationName: params.SessionData().ApplicationName,
+				},
+				SettingName: n.name,
+				Value:       reportedValue,
+			},
 		)
 	}); err != nil {
 		return err
@@ -16,6 +16,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/config/zonepb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -35,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/errors"
 	"github.com/gogo/protobuf/proto"
@@ -66,6 +68,7 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 	"range_min_bytes": {types.Int, func(c *zonepb.ZoneConfig, d tree.Datum) { c.RangeMinBytes = proto.Int64(int64(tree.MustBeDInt(d))) }},
 	"range_max_bytes": {types.Int, func(c *zonepb.ZoneConfig, d tree.Datum) { c.RangeMaxBytes = proto.Int64(int64(tree.MustBeDInt(d))) }},
 	"num_replicas":    {types.Int, func(c *zonepb.ZoneConfig, d tree.Datum) { c.NumReplicas = proto.Int32(int32(tree.MustBeDInt(d))) }},
+	"num_voters":      {types.Int, func(c *zonepb.ZoneConfig, d tree.Datum) { c.NumVoters = proto.Int32(int32(tree.MustBeDInt(d))) }},
 	"gc.ttlseconds": {types.Int, func(c *zonepb.ZoneConfig, d tree.Datum) {
 		c.GC = &zonepb.GCPolicy{TTLSeconds: int32(tree.MustBeDInt(d))}
 	}},
@@ -153,6 +156,12 @@ func (p *planner) SetZoneConfig(ctx context.Context, n *tree.SetZoneConfig) (pla
 				return nil, pgerror.Newf(pgcode.InvalidParameterValue,
 					"unsupported zone config parameter: %q", tree.ErrString(&opt.Key))
 			}
+			if opt.Key == "num_voters" &&
+				!p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.VersionNonVotingReplicas) {
+				return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					`setting num_voters requires all nodes to be upgraded to %s`,
+					clusterversion.VersionByKey(clusterversion.VersionNonVotingReplicas))
+			}
 			telemetry.Inc(
 				sqltelemetry.SchemaSetZoneConfigCounter(
 					n.ZoneSpecifier.TelemetryName(),
@@ -669,31 +678,19 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 		}
 
 		// Record that the change has occurred for auditing.
-		var eventLogType EventLogType
-		info := struct {
-			Target  string
-			Config  string `json:",omitempty"`
-			Options string `json:",omitempty"`
-			User    string
-		}{
-			Target:  tree.AsStringWithFQNames(&zs, params.Ann()),
-			Config:  strings.TrimSpace(yamlConfig),
-			Options: optionStr.String(),
-			User:    params.SessionData().User,
-		}
+		var event eventpb.EventWithCommonSQLPayload
 		if deleteZone {
-			eventLogType = EventLogRemoveZoneConfig
+			event = &eventpb.RemoveZoneConfig{
+				Target: tree.AsStringWithFQNames(&zs, params.Ann()),
+			}
 		} else {
-			eventLogType = EventLogSetZoneConfig
-		}
-		return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
-			params.ctx,
-			params.p.txn,
-			eventLogType,
-			int32(targetID),
-			int32(params.extendedEvalCtx.NodeID.SQLInstanceID()),
-			info,
-		)
+			event = &eventpb.SetZoneConfig{
+				Target:  tree.AsStringWithFQNames(&zs, params.Ann()),
+				Config:  strings.TrimSpace(yamlConfig),
+				Options: optionStr.String(),
+			}
+		}
+		return params.p.logEvent(params.ctx, targetID, event)
 	}
 	for _, zs := range specifiers {
 		// Note(solon): Currently the zone configurations are applied serially for
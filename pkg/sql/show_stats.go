@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/schemaexpr"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -32,6 +33,7 @@ var showTableStatsColumns = colinfo.ResultColumns{
 	{Name: "distinct_count", Typ: types.Int},
 	{Name: "null_count", Typ: types.Int},
 	{Name: "histogram_id", Typ: types.Int},
+	{Name: "expression", Typ: types.String},
 }
 
 var showTableStatsJSONColumns = colinfo.ResultColumns{
@@ -63,6 +65,8 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 			//  - convert column IDs to column names
 			//  - if the statistic has a histogram, we return the statistic ID as a
 			//    "handle" which can be used with SHOW HISTOGRAM.
+			//  - if the statistic is on an expression, we return the expression
+			//    formatted for display.
 			rows, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.Query(
 				ctx,
 				"read-table-stats",
@@ -74,7 +78,8 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 					      "rowCount",
 					      "distinctCount",
 					      "nullCount",
-					      histogram
+					      histogram,
+					      expression
 				 FROM system.table_statistics
 				 WHERE "tableID" = $1
 				 ORDER BY "createdAt"`,
@@ -93,6 +98,7 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 				distinctCountIdx
 				nullCountIdx
 				histogramIdx
+				expressionIdx
 				numCols
 			)
 
@@ -107,6 +113,9 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 					if r[nameIdx] != tree.DNull {
 						result[i].Name = string(*r[nameIdx].(*tree.DString))
 					}
+					if r[expressionIdx] != tree.DNull {
+						result[i].Expression = string(*r[expressionIdx].(*tree.DString))
+					}
 					colIDs := r[columnIDsIdx].(*tree.DArray).Array
 					result[i].Columns = make([]string, len(colIDs))
 					for j, d := range colIDs {
@@ -152,6 +161,13 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 					histogramID = r[statIDIdx]
 				}
 
+				expression := tree.DNull
+				if r[expressionIdx] != tree.DNull {
+					expression = tree.NewDString(statExpressionString(
+						ctx, p, desc, string(*r[expressionIdx].(*tree.DString)),
+					))
+				}
+
 				res := tree.Datums{
 					r[nameIdx],
 					colNames,
@@ -160,6 +176,7 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 					r[distinctCountIdx],
 					r[nullCountIdx],
 					histogramID,
+					expression,
 				}
 				if _, err := v.rows.AddRow(ctx, res); err != nil {
 					v.Close(ctx)
@@ -171,6 +188,18 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 	}, nil
 }
 
+// statExpressionString formats the expression of a statistic for display.
+func statExpressionString(
+	ctx context.Context, p *planner, desc *tabledesc.Immutable, expr string,
+) string {
+	res, err := schemaexpr.FormatExprForDisplay(ctx, desc, expr, &p.semaCtx, tree.FmtSimple)
+	if err != nil {
+		// This can happen if a column referenced by the expression was removed.
+		return expr
+	}
+	return res
+}
+
 func statColumnString(desc *tabledesc.Immutable, colID tree.Datum) string {
 	id := descpb.ColumnID(*colID.(*tree.DInt))
 	colDesc, err := desc.FindColumnByID(id)
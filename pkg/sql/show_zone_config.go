@@ -206,6 +206,11 @@ func zoneConfigToSQL(zs *tree.ZoneSpecifier, zone *zonepb.ZoneConfig) (string, e
 		f.Printf("\tnum_replicas = %d", *zone.NumReplicas)
 		useComma = true
 	}
+	if zone.NumVoters != nil {
+		writeComma(f, useComma)
+		f.Printf("\tnum_voters = %d", *zone.NumVoters)
+		useComma = true
+	}
 	if !zone.InheritedConstraints {
 		writeComma(f, useComma)
 		f.Printf("\tconstraints = %s", lex.EscapeSQLString(constraints))
@@ -223,6 +223,60 @@ func (s *Builder) SpansFromConstraint(
 	return spans, nil
 }
 
+// SuffixSpansFromConstraint generates spans from a constraint on the index
+// columns that follow the first prefixLen index columns. The resulting keys
+// contain neither the table and index prefix nor the prefix columns, so that
+// they can be appended to an encoded key prefix by a loose index scan. An empty
+// end key means that the span extends to the end of the key prefix. The index
+// must not be interleaved.
+func (s *Builder) SuffixSpansFromConstraint(
+	c *constraint.Constraint, prefixLen int,
+) (roachpb.Spans, error) {
+	if len(s.index.Interleave.Ancestors) > 0 {
+		return nil, errors.AssertionFailedf("suffix spans are not supported for interleaved indexes")
+	}
+	if c == nil || c.IsUnconstrained() {
+		return nil, nil
+	}
+	spans := make(roachpb.Spans, c.Spans.Count())
+	for i := range spans {
+		cs := c.Spans.Get(i)
+		startKey, err := s.encodeSuffixConstraintKey(cs.StartKey(), prefixLen)
+		if err != nil {
+			return nil, err
+		}
+		if cs.StartBoundary() == constraint.ExcludeBoundary {
+			startKey = startKey.PrefixEnd()
+		}
+		endKey, err := s.encodeSuffixConstraintKey(cs.EndKey(), prefixLen)
+		if err != nil {
+			return nil, err
+		}
+		if cs.EndBoundary() == constraint.IncludeBoundary && len(endKey) > 0 {
+			endKey = endKey.PrefixEnd()
+		}
+		spans[i] = roachpb.Span{Key: startKey, EndKey: endKey}
+	}
+	return spans, nil
+}
+
+// encodeSuffixConstraintKey encodes each logical part of a constraint.Key on
+// the index columns starting at the given offset, without any key prefix.
+func (s *Builder) encodeSuffixConstraintKey(ck constraint.Key, offset int) (roachpb.Key, error) {
+	var key roachpb.Key
+	for i := 0; i < ck.Length(); i++ {
+		dir, err := s.indexColDirs[offset+i].ToEncodingDirection()
+		if err != nil {
+			return nil, err
+		}
+		key, err = rowenc.EncodeTableKey(key, ck.Value(i), dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
 // UnconstrainedSpans returns the full span corresponding to the Builder's
 // table and index.
 func (s *Builder) UnconstrainedSpans() (roachpb.Spans, error) {
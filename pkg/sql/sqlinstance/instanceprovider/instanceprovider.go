@@ -0,0 +1,213 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package instanceprovider exposes an implementation of the
+// sqlinstance.Provider interface.
+package instanceprovider
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlinstance"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlinstance/instancestorage"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// CacheRefreshInterval is the interval at which the cached list of live SQL
+// instances is read again from system.sql_instances in the background.
+var CacheRefreshInterval = 3 * time.Second
+
+type provider struct {
+	stopper      *stop.Stopper
+	storage      *instancestorage.Storage
+	session      sqlliveness.Instance
+	slReader     sqlliveness.Reader
+	instanceAddr string
+
+	mu struct {
+		syncutil.Mutex
+		started    bool
+		instanceID base.SQLInstanceID
+		sessionID  sqlliveness.SessionID
+		// instances caches the live SQL instances. It is refreshed every
+		// CacheRefreshInterval by the refresh loop, so that reading it doesn't
+		// require any round trip.
+		instances []sqlinstance.InstanceInfo
+	}
+}
+
+var _ sqlinstance.Provider = &provider{}
+
+// New constructs a new Provider. The instance address is the address of the
+// RPC server of this SQL instance; it is empty if the instance doesn't accept
+// RPCs from other SQL instances.
+func New(
+	stopper *stop.Stopper,
+	db *kv.DB,
+	codec keys.SQLCodec,
+	slProvider sqlliveness.Provider,
+	instanceAddr string,
+) sqlinstance.Provider {
+	return &provider{
+		stopper:      stopper,
+		storage:      instancestorage.NewStorage(db, codec, slProvider),
+		session:      slProvider,
+		slReader:     slProvider,
+		instanceAddr: instanceAddr,
+	}
+}
+
+// Start implements the sqlinstance.Provider interface.
+//
+// The allocated ID is tied to the current sqlliveness session of this SQL
+// instance. It isn't released on shutdown: another SQL instance reuses it once
+// the session has expired. Start also reads the live SQL instances and starts
+// the loop which keeps them up to date.
+func (p *provider) Start(ctx context.Context) (base.SQLInstanceID, error) {
+	p.mu.Lock()
+	if p.mu.started {
+		defer p.mu.Unlock()
+		return p.mu.instanceID, nil
+	}
+	p.mu.Unlock()
+
+	session, err := p.session.Session(ctx)
+	if err != nil {
+		return 0, err
+	}
+	instanceID, err := p.storage.CreateInstance(ctx, session.ID(), p.instanceAddr)
+	if err != nil {
+		return 0, err
+	}
+
+	instances, err := p.readLiveInstances(ctx, instanceID, session.ID())
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mu.started = true
+	p.mu.instanceID = instanceID
+	p.mu.sessionID = session.ID()
+	p.mu.instances = instances
+	if err := p.stopper.RunAsyncTask(ctx, "sql-instance-refresh", p.refreshLoop); err != nil {
+		return 0, err
+	}
+	return instanceID, nil
+}
+
+// refreshLoop periodically refreshes the cached list of live SQL instances.
+func (p *provider) refreshLoop(ctx context.Context) {
+	ctx, cancel := p.stopper.WithCancelOnQuiesce(ctx)
+	defer cancel()
+	t := timeutil.NewTimer()
+	defer t.Stop()
+	for {
+		t.Reset(CacheRefreshInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			t.Read = true
+			p.mu.Lock()
+			instanceID, sessionID := p.mu.instanceID, p.mu.sessionID
+			p.mu.Unlock()
+			instances, err := p.readLiveInstances(ctx, instanceID, sessionID)
+			if err != nil {
+				// Keep the previous list until the next refresh succeeds.
+				log.Warningf(ctx, "failed to refresh the SQL instances: %v", err)
+				continue
+			}
+			p.mu.Lock()
+			p.mu.instances = instances
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Instance implements the sqlinstance.Provider interface.
+func (p *provider) Instance(ctx context.Context) (base.SQLInstanceID, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.mu.started {
+		return 0, errors.New("SQL instance has not been registered")
+	}
+	return p.mu.instanceID, nil
+}
+
+// GetInstance implements the sqlinstance.AddressResolver interface.
+func (p *provider) GetInstance(
+	ctx context.Context, instanceID base.SQLInstanceID,
+) (sqlinstance.InstanceInfo, error) {
+	instances, err := p.GetAllInstances(ctx)
+	if err != nil {
+		return sqlinstance.InstanceInfo{}, err
+	}
+	for _, instance := range instances {
+		if instance.InstanceID == instanceID {
+			return instance, nil
+		}
+	}
+	return sqlinstance.InstanceInfo{}, sqlinstance.NonExistentInstanceError
+}
+
+// GetAllInstances implements the sqlinstance.AddressResolver interface. The
+// instances which don't accept RPCs from other SQL instances are omitted,
+// except for this SQL instance. The returned slice must not be modified.
+//
+// The instances are served from a cache, refreshed in the background every
+// CacheRefreshInterval, so that planning queries doesn't wait on KV reads.
+func (p *provider) GetAllInstances(ctx context.Context) ([]sqlinstance.InstanceInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.mu.started {
+		return nil, errors.New("SQL instance has not been registered")
+	}
+	return p.mu.instances, nil
+}
+
+// readLiveInstances reads the SQL instances from system.sql_instances and
+// returns those which are alive and accept RPCs, as well as this SQL instance.
+func (p *provider) readLiveInstances(
+	ctx context.Context, instanceID base.SQLInstanceID, sessionID sqlliveness.SessionID,
+) ([]sqlinstance.InstanceInfo, error) {
+	instances, err := p.storage.GetAllInstancesData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live := make([]sqlinstance.InstanceInfo, 0, len(instances))
+	for _, instance := range instances {
+		if instance.InstanceID == instanceID && instance.SessionID == sessionID {
+			live = append(live, instance)
+			continue
+		}
+		if instance.InstanceAddr == "" {
+			continue
+		}
+		alive, err := p.slReader.IsAlive(ctx, instance.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if alive {
+			live = append(live, instance)
+		}
+	}
+	return live, nil
+}
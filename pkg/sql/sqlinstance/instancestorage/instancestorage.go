@@ -0,0 +1,230 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package instancestorage handles the storage of the SQL instances in the
+// system.sql_instances table.
+package instancestorage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlinstance"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// Storage implements the storage of the SQL instances.
+//
+// The instances are read and written with KV operations rather than SQL
+// statements, so that a SQL instance can allocate its SQLInstanceID before
+// its SQL server is set up.
+type Storage struct {
+	db       *kv.DB
+	codec    keys.SQLCodec
+	tableID  descpb.ID
+	slReader sqlliveness.Reader
+}
+
+// NewTestingStorage constructs a new storage with control for the table in
+// which the instances are stored. The table must have the schema of the
+// `sql_instances` table.
+func NewTestingStorage(
+	db *kv.DB, codec keys.SQLCodec, sqlInstancesTableID descpb.ID, slReader sqlliveness.Reader,
+) *Storage {
+	return &Storage{
+		db:       db,
+		codec:    codec,
+		tableID:  sqlInstancesTableID,
+		slReader: slReader,
+	}
+}
+
+// NewStorage creates a new storage struct.
+func NewStorage(db *kv.DB, codec keys.SQLCodec, slReader sqlliveness.Reader) *Storage {
+	return NewTestingStorage(db, codec, keys.SQLInstancesTableID, slReader)
+}
+
+// CreateInstance allocates a SQLInstanceID to a SQL instance with the given
+// session and address. The lowest ID which is either unused or held by an
+// instance whose session is no longer alive is allocated, so that the IDs
+// remain small and are reused as SQL instances come and go.
+func (s *Storage) CreateInstance(
+	ctx context.Context, sessionID sqlliveness.SessionID, addr string,
+) (instanceID base.SQLInstanceID, _ error) {
+	if err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		instances, err := s.getAllInstancesData(ctx, txn)
+		if err != nil {
+			return err
+		}
+		instanceID = 1
+		for _, instance := range instances {
+			if instance.InstanceID != instanceID {
+				// There is a gap in the allocated IDs.
+				break
+			}
+			alive, err := s.slReader.IsAlive(ctx, instance.SessionID)
+			if err != nil {
+				return err
+			}
+			if !alive {
+				// The instance holding this ID is gone, so it can be reused.
+				break
+			}
+			instanceID++
+		}
+		return txn.Put(ctx, s.makeInstanceKey(instanceID), encodeInstanceValue(addr, sessionID))
+	}); err != nil {
+		return 0, errors.Wrapf(err, "could not allocate SQL instance ID for session %s", sessionID)
+	}
+	log.Infof(ctx, "allocated SQL instance ID %d to session %s", instanceID, sessionID)
+	return instanceID, nil
+}
+
+// GetInstanceData returns the stored data of the given SQL instance, or
+// sqlinstance.NonExistentInstanceError if the ID isn't allocated. The
+// liveness of the instance's session is not checked.
+func (s *Storage) GetInstanceData(
+	ctx context.Context, instanceID base.SQLInstanceID,
+) (sqlinstance.InstanceInfo, error) {
+	row, err := s.db.Get(ctx, s.makeInstanceKey(instanceID))
+	if err != nil {
+		return sqlinstance.InstanceInfo{}, errors.Wrapf(err,
+			"could not query SQL instance %d", instanceID)
+	}
+	if row.Value == nil {
+		return sqlinstance.InstanceInfo{}, sqlinstance.NonExistentInstanceError
+	}
+	return s.decodeRow(row)
+}
+
+// GetAllInstancesData returns the stored data of all the SQL instances,
+// ordered by ID. The liveness of the instances' sessions is not checked.
+func (s *Storage) GetAllInstancesData(ctx context.Context) ([]sqlinstance.InstanceInfo, error) {
+	var instances []sqlinstance.InstanceInfo
+	if err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) (err error) {
+		instances, err = s.getAllInstancesData(ctx, txn)
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "could not query SQL instances")
+	}
+	return instances, nil
+}
+
+func (s *Storage) getAllInstancesData(
+	ctx context.Context, txn *kv.Txn,
+) ([]sqlinstance.InstanceInfo, error) {
+	start := s.makeIndexPrefix()
+	rows, err := txn.Scan(ctx, start, start.PrefixEnd(), 0 /* maxRows */)
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]sqlinstance.InstanceInfo, len(rows))
+	for i := range rows {
+		if instances[i], err = s.decodeRow(rows[i]); err != nil {
+			return nil, err
+		}
+	}
+	return instances, nil
+}
+
+// ReleaseInstanceID releases the given SQLInstanceID so that it can be
+// allocated to another SQL instance.
+func (s *Storage) ReleaseInstanceID(ctx context.Context, instanceID base.SQLInstanceID) error {
+	if err := s.db.Del(ctx, s.makeInstanceKey(instanceID)); err != nil {
+		return errors.Wrapf(err, "could not release SQL instance ID %d", instanceID)
+	}
+	return nil
+}
+
+// The columns of the sql_instances table. The instances are stored in the
+// single column family of the table's primary index, keyed by ID.
+const (
+	idColumnID        = 1
+	addrColumnID      = 2
+	sessionIDColumnID = 3
+)
+
+func (s *Storage) makeIndexPrefix() roachpb.Key {
+	return s.codec.IndexPrefix(uint32(s.tableID), 1 /* indexID */)
+}
+
+// makeInstanceKey returns the key of the row of the given SQL instance.
+func (s *Storage) makeInstanceKey(instanceID base.SQLInstanceID) roachpb.Key {
+	k := encoding.EncodeVarintAscending(s.makeIndexPrefix(), int64(instanceID))
+	return keys.MakeFamilyKey(k, 0 /* famID */)
+}
+
+// encodeInstanceValue encodes the non-key columns of the row of a SQL
+// instance. An empty address is stored as NULL.
+func encodeInstanceValue(addr string, sessionID sqlliveness.SessionID) *roachpb.Value {
+	var b []byte
+	lastColID := uint32(idColumnID)
+	if addr != "" {
+		b = encoding.EncodeBytesValue(b, addrColumnID-lastColID, []byte(addr))
+		lastColID = addrColumnID
+	}
+	b = encoding.EncodeBytesValue(b, sessionIDColumnID-lastColID, sessionID.UnsafeBytes())
+	var v roachpb.Value
+	v.SetTuple(b)
+	return &v
+}
+
+// decodeRow decodes a row of the sql_instances table.
+func (s *Storage) decodeRow(row kv.KeyValue) (sqlinstance.InstanceInfo, error) {
+	var info sqlinstance.InstanceInfo
+	prefix := s.makeIndexPrefix()
+	if !bytes.HasPrefix(row.Key, prefix) {
+		return info, errors.AssertionFailedf("key %s is not in the sql_instances table", row.Key)
+	}
+	_, id, err := encoding.DecodeVarintAscending(row.Key[len(prefix):])
+	if err != nil {
+		return info, errors.Wrapf(err, "failed to decode key %s", row.Key)
+	}
+	info.InstanceID = base.SQLInstanceID(id)
+
+	b, err := row.Value.GetTuple()
+	if err != nil {
+		return info, err
+	}
+	colID := uint32(idColumnID)
+	for len(b) > 0 {
+		_, _, colIDDiff, typ, err := encoding.DecodeValueTag(b)
+		if err != nil {
+			return info, err
+		}
+		colID += colIDDiff
+		if typ != encoding.Bytes {
+			return info, errors.AssertionFailedf(
+				"unexpected type %s of column %d in SQL instance %d", typ, colID, id)
+		}
+		var data []byte
+		if b, data, err = encoding.DecodeBytesValue(b); err != nil {
+			return info, err
+		}
+		switch colID {
+		case addrColumnID:
+			info.InstanceAddr = string(data)
+		case sessionIDColumnID:
+			info.SessionID = sqlliveness.SessionID(data)
+		default:
+			return info, errors.AssertionFailedf(
+				"unexpected column %d in SQL instance %d", colID, id)
+		}
+	}
+	return info, nil
+}
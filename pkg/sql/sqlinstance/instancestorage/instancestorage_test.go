@@ -0,0 +1,139 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package instancestorage_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlinstance"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlinstance/instancestorage"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSessions is a sqlliveness.Reader whose sessions are alive until they
+// are killed.
+type fakeSessions struct {
+	syncutil.Mutex
+	dead map[sqlliveness.SessionID]bool
+}
+
+func (f *fakeSessions) IsAlive(_ context.Context, sid sqlliveness.SessionID) (bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	return !f.dead[sid], nil
+}
+
+func (f *fakeSessions) kill(sid sqlliveness.SessionID) {
+	f.Lock()
+	defer f.Unlock()
+	f.dead[sid] = true
+}
+
+func TestStorage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	tDB := sqlutils.MakeSQLRunner(sqlDB)
+
+	setup := func(t *testing.T) (*fakeSessions, *instancestorage.Storage) {
+		dbName := t.Name()
+		tDB.Exec(t, `CREATE DATABASE "`+dbName+`"`)
+		schema := strings.Replace(systemschema.SQLInstancesTableSchema,
+			`CREATE TABLE system.sql_instances`,
+			`CREATE TABLE "`+dbName+`".sql_instances`, 1)
+		tDB.Exec(t, schema)
+		sessions := &fakeSessions{dead: make(map[sqlliveness.SessionID]bool)}
+		tableID := descpb.ID(sqlutils.QueryTableID(t, sqlDB, dbName, "public", "sql_instances"))
+		return sessions, instancestorage.NewTestingStorage(kvDB, keys.SystemSQLCodec, tableID, sessions)
+	}
+
+	t.Run("create-get-release", func(t *testing.T) {
+		_, storage := setup(t)
+
+		const addr1, addr2 = "localhost:1", "localhost:2"
+		id1, err := storage.CreateInstance(ctx, "session1", addr1)
+		require.NoError(t, err)
+		require.Equal(t, base.SQLInstanceID(1), id1)
+		id2, err := storage.CreateInstance(ctx, "session2", addr2)
+		require.NoError(t, err)
+		require.Equal(t, base.SQLInstanceID(2), id2)
+
+		instance, err := storage.GetInstanceData(ctx, id2)
+		require.NoError(t, err)
+		require.Equal(t, sqlinstance.InstanceInfo{
+			InstanceID: id2, InstanceAddr: addr2, SessionID: "session2",
+		}, instance)
+
+		instances, err := storage.GetAllInstancesData(ctx)
+		require.NoError(t, err)
+		require.Len(t, instances, 2)
+		require.Equal(t, id1, instances[0].InstanceID)
+		require.Equal(t, id2, instances[1].InstanceID)
+
+		// A released ID is reused by the next instance.
+		require.NoError(t, storage.ReleaseInstanceID(ctx, id1))
+		_, err = storage.GetInstanceData(ctx, id1)
+		require.Equal(t, sqlinstance.NonExistentInstanceError, err)
+		id3, err := storage.CreateInstance(ctx, "session3", "" /* addr */)
+		require.NoError(t, err)
+		require.Equal(t, id1, id3)
+
+		instance, err = storage.GetInstanceData(ctx, id3)
+		require.NoError(t, err)
+		require.Equal(t, sqlinstance.InstanceInfo{InstanceID: id3, SessionID: "session3"}, instance)
+
+		// The rows written by the storage can be read with SQL.
+		tDB.CheckQueryResults(t,
+			`SELECT id, COALESCE(addr, 'NULL'), encode(session_id, 'escape')
+			   FROM "`+t.Name()+`".sql_instances ORDER BY id`,
+			[][]string{{"1", "NULL", "session3"}, {"2", addr2, "session2"}},
+		)
+	})
+
+	t.Run("reuse-id-of-dead-session", func(t *testing.T) {
+		sessions, storage := setup(t)
+
+		for i, sid := range []sqlliveness.SessionID{"session1", "session2", "session3"} {
+			id, err := storage.CreateInstance(ctx, sid, "localhost:1")
+			require.NoError(t, err)
+			require.Equal(t, base.SQLInstanceID(i+1), id)
+		}
+
+		// The ID of an instance whose session has expired is allocated again.
+		sessions.kill("session2")
+		id, err := storage.CreateInstance(ctx, "session4", "localhost:4")
+		require.NoError(t, err)
+		require.Equal(t, base.SQLInstanceID(2), id)
+
+		instance, err := storage.GetInstanceData(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, sqlliveness.SessionID("session4"), instance.SessionID)
+
+		id, err = storage.CreateInstance(ctx, "session5", "localhost:5")
+		require.NoError(t, err)
+		require.Equal(t, base.SQLInstanceID(4), id)
+	})
+}
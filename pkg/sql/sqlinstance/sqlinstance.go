@@ -0,0 +1,60 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package sqlinstance provides interfaces to keep track of the SQL instances
+// (pods) running on behalf of a tenant. Each instance is allocated a unique
+// SQLInstanceID which is tied to its sqlliveness session, and advertises the
+// address of its RPC server so that other instances can set up DistSQL flows
+// on it.
+package sqlinstance
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
+	"github.com/cockroachdb/errors"
+)
+
+// InstanceInfo exposes information about a SQL instance, such as its ID and
+// the address of its RPC server.
+type InstanceInfo struct {
+	InstanceID   base.SQLInstanceID
+	InstanceAddr string
+	SessionID    sqlliveness.SessionID
+}
+
+// AddressResolver exposes the information about the live SQL instances. It
+// may lag behind the instances being started and stopped, and must not block
+// on round trips since it is consulted when planning queries.
+type AddressResolver interface {
+	// GetInstance returns the InstanceInfo of the given live SQL instance, or
+	// NonExistentInstanceError if there is no such instance.
+	GetInstance(context.Context, base.SQLInstanceID) (InstanceInfo, error)
+	// GetAllInstances returns the InstanceInfo of all the live SQL instances,
+	// ordered by ID.
+	GetAllInstances(context.Context) ([]InstanceInfo, error)
+}
+
+// Provider is a wrapper around the sqlinstance subsystem for external
+// consumption.
+type Provider interface {
+	AddressResolver
+	// Start allocates an SQLInstanceID to this SQL instance and registers its
+	// address. It must be called after the sqlliveness subsystem is started.
+	Start(context.Context) (base.SQLInstanceID, error)
+	// Instance returns the SQLInstanceID allocated to this SQL instance, or an
+	// error if Start has not succeeded.
+	Instance(context.Context) (base.SQLInstanceID, error)
+}
+
+// NonExistentInstanceError is returned when the requested SQL instance is
+// not registered or is no longer alive.
+var NonExistentInstanceError = errors.Errorf("non existent SQL instance")
@@ -15,15 +15,16 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
-	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
-	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -69,16 +70,21 @@ var CacheSize = settings.RegisterIntSetting(
 	1024)
 
 // Storage implements sqlliveness.Storage.
+//
+// The sessions are read and written with KV operations rather than SQL
+// statements, so that the storage can be used before the SQL server is set
+// up, e.g. by the SQL pods of secondary tenants to allocate their
+// SQLInstanceID on startup.
 type Storage struct {
 	settings   *cluster.Settings
 	stopper    *stop.Stopper
 	clock      *hlc.Clock
 	db         *kv.DB
-	ex         sqlutil.InternalExecutor
+	codec      keys.SQLCodec
+	tableID    descpb.ID
 	metrics    Metrics
 	gcInterval func() time.Duration
 	g          singleflight.Group
-	sd         sessiondata.InternalExecutorOverride
 	newTimer   func() timeutil.TimerI
 
 	mu struct {
@@ -95,15 +101,16 @@ type Storage struct {
 	}
 }
 
-// NewTestingStorage constructs a new storage with control for the database
-// in which the `sqlliveness` table should exist.
+// NewTestingStorage constructs a new storage with control for the table in
+// which the sessions are stored. The table must have the schema of the
+// `sqlliveness` table.
 func NewTestingStorage(
 	stopper *stop.Stopper,
 	clock *hlc.Clock,
 	db *kv.DB,
-	ie sqlutil.InternalExecutor,
+	codec keys.SQLCodec,
 	settings *cluster.Settings,
-	database string,
+	sqllivenessTableID descpb.ID,
 	newTimer func() timeutil.TimerI,
 ) *Storage {
 	s := &Storage{
@@ -111,11 +118,8 @@ func NewTestingStorage(
 		stopper:  stopper,
 		clock:    clock,
 		db:       db,
-		ex:       ie,
-		sd: sessiondata.InternalExecutorOverride{
-			User:     security.NodeUser,
-			Database: database,
-		},
+		codec:    codec,
+		tableID:  sqllivenessTableID,
 		newTimer: newTimer,
 		gcInterval: func() time.Duration {
 			baseInterval := GCInterval.Get(&settings.SV)
@@ -138,13 +142,9 @@ func NewTestingStorage(
 
 // NewStorage creates a new storage struct.
 func NewStorage(
-	stopper *stop.Stopper,
-	clock *hlc.Clock,
-	db *kv.DB,
-	ie sqlutil.InternalExecutor,
-	settings *cluster.Settings,
+	stopper *stop.Stopper, clock *hlc.Clock, db *kv.DB, codec keys.SQLCodec, settings *cluster.Settings,
 ) *Storage {
-	return NewTestingStorage(stopper, clock, db, ie, settings, "system",
+	return NewTestingStorage(stopper, clock, db, codec, settings, keys.SqllivenessID,
 		timeutil.DefaultTimeSource{}.NewTimer)
 }
 
@@ -231,22 +231,22 @@ func (s *Storage) IsAlive(ctx context.Context, sid sqlliveness.SessionID) (alive
 func (s *Storage) deleteOrFetchSession(
 	ctx context.Context, sid sqlliveness.SessionID, prevExpiration hlc.Timestamp,
 ) (alive bool, expiration hlc.Timestamp, err error) {
+	k := s.makeSessionKey(sid)
 	if err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
-
-		row, err := s.ex.QueryRowEx(ctx, "fetch-single-session", txn, s.sd, `
-SELECT expiration FROM sqlliveness WHERE session_id = $1
-`, sid.UnsafeBytes())
+		// Reset the results in case the transaction is retried.
+		alive, expiration = false, hlc.Timestamp{}
+		row, err := txn.Get(ctx, k)
 		if err != nil {
 			return err
 		}
 
 		// The session is not alive.
-		if row == nil {
+		if row.Value == nil {
 			return nil
 		}
 
 		// The session is alive if the read expiration differs from prevExpiration.
-		expiration, err = tree.DecimalToHLC(&row[0].(*tree.DDecimal).Decimal)
+		expiration, err = decodeExpiration(row.Value)
 		if err != nil {
 			return errors.Wrapf(err, "failed to parse expiration for session")
 		}
@@ -257,10 +257,7 @@ SELECT expiration FROM sqlliveness WHERE session_id = $1
 
 		// The session is expired and needs to be deleted.
 		expiration = hlc.Timestamp{}
-		_, err = s.ex.ExecEx(ctx, "delete-expired-session", txn, s.sd, `
-DELETE FROM sqlliveness WHERE session_id = $1
-`, sid.UnsafeBytes())
-		return err
+		return txn.Del(ctx, k)
 	}); err != nil {
 		return false, hlc.Timestamp{}, errors.Wrapf(err,
 			"could not query session id: %s", sid)
@@ -295,24 +292,32 @@ func (s *Storage) deleteSessionsLoop(ctx context.Context) {
 // matter. This would closer align with the behavior in node-liveness.
 func (s *Storage) deleteExpiredSessions(ctx context.Context) {
 	now := s.clock.Now()
-	row, err := s.ex.QueryRowEx(ctx, "delete-sessions", nil /* txn */, s.sd,
-		`
-  WITH deleted_sessions AS (
-                            DELETE FROM sqlliveness
-                                  WHERE expiration < $1
-                              RETURNING session_id
-                        )
-	SELECT count(*)
-  FROM deleted_sessions;`,
-		tree.TimestampToDecimalDatum(now),
-	)
-	if err != nil {
+	var deleted int64
+	if err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		deleted = 0
+		start := s.codec.IndexPrefix(uint32(s.tableID), 1 /* indexID */)
+		rows, err := txn.Scan(ctx, start, start.PrefixEnd(), 0 /* maxRows */)
+		if err != nil {
+			return err
+		}
+		b := txn.NewBatch()
+		for _, row := range rows {
+			expiration, err := decodeExpiration(row.Value)
+			if err != nil {
+				return err
+			}
+			if expiration.Less(now) {
+				b.Del(row.Key)
+				deleted++
+			}
+		}
+		return txn.CommitInBatch(ctx, b)
+	}); err != nil {
 		if ctx.Err() == nil {
 			log.Errorf(ctx, "could not delete expired sessions: %+v", err)
 		}
 		return
 	}
-	deleted := int64(*row[0].(*tree.DInt))
 
 	s.metrics.SessionDeletionsRuns.Inc(1)
 	s.metrics.SessionsDeleted.Inc(deleted)
@@ -328,14 +333,10 @@ func (s *Storage) deleteExpiredSessions(ctx context.Context) {
 func (s *Storage) Insert(
 	ctx context.Context, sid sqlliveness.SessionID, expiration hlc.Timestamp,
 ) (err error) {
-	if err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
-		_, err := s.ex.QueryRowEx(
-			ctx, "insert-session", txn, s.sd,
-			`INSERT INTO sqlliveness VALUES ($1, $2)`,
-			sid.UnsafeBytes(), tree.TimestampToDecimalDatum(expiration),
-		)
-		return err
-	}); err != nil {
+	// The session must not exist yet.
+	if err := s.db.CPut(
+		ctx, s.makeSessionKey(sid), encodeExpiration(expiration), nil, /* expValue */
+	); err != nil {
 		s.metrics.WriteFailures.Inc(1)
 		return errors.Wrapf(err, "could not insert session %s", sid)
 	}
@@ -349,17 +350,16 @@ func (s *Storage) Insert(
 func (s *Storage) Update(
 	ctx context.Context, sid sqlliveness.SessionID, expiration hlc.Timestamp,
 ) (sessionExists bool, err error) {
+	k := s.makeSessionKey(sid)
 	err = s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
-		data, err := s.ex.QueryRowEx(
-			ctx, "update-session", txn, s.sd, `
-UPDATE sqlliveness SET expiration = $1 WHERE session_id = $2 RETURNING session_id`,
-			tree.TimestampToDecimalDatum(expiration), sid.UnsafeBytes(),
-		)
+		row, err := txn.Get(ctx, k)
 		if err != nil {
 			return err
 		}
-		sessionExists = data != nil
-		return nil
+		if sessionExists = row.Value != nil; !sessionExists {
+			return nil
+		}
+		return txn.Put(ctx, k, encodeExpiration(expiration))
 	})
 	if err != nil || !sessionExists {
 		s.metrics.WriteFailures.Inc(1)
@@ -370,3 +370,47 @@ UPDATE sqlliveness SET expiration = $1 WHERE session_id = $2 RETURNING session_i
 	s.metrics.WriteSuccesses.Inc(1)
 	return sessionExists, nil
 }
+
+// makeSessionKey returns the key of the row of the given session. The
+// sessions are stored in the single column family of the table's primary
+// index, keyed by session ID.
+func (s *Storage) makeSessionKey(sid sqlliveness.SessionID) roachpb.Key {
+	k := s.codec.IndexPrefix(uint32(s.tableID), 1 /* indexID */)
+	k = encoding.EncodeBytesAscending(k, sid.UnsafeBytes())
+	return keys.MakeFamilyKey(k, 0 /* famID */)
+}
+
+// encodeExpiration encodes the expiration of a session as the value of its
+// row, in which the expiration column is the only non-key column.
+func encodeExpiration(expiration hlc.Timestamp) *roachpb.Value {
+	dec := tree.TimestampToDecimal(expiration)
+	var v roachpb.Value
+	v.SetTuple(encoding.EncodeDecimalValue(nil /* appendTo */, expirationColumnID, &dec))
+	return &v
+}
+
+// decodeExpiration decodes the expiration of a session from the value of its
+// row.
+func decodeExpiration(v *roachpb.Value) (hlc.Timestamp, error) {
+	b, err := v.GetTuple()
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	_, _, colID, typ, err := encoding.DecodeValueTag(b)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	if colID != expirationColumnID || typ != encoding.Decimal {
+		return hlc.Timestamp{}, errors.AssertionFailedf(
+			"unexpected column %d of type %s in session row", colID, typ)
+	}
+	_, dec, err := encoding.DecodeDecimalValue(b)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	return tree.DecimalToHLC(&dec)
+}
+
+// expirationColumnID is the ID of the expiration column of the sqlliveness
+// table.
+const expirationColumnID = 2
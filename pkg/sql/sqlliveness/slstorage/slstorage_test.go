@@ -19,11 +19,13 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlliveness/slstorage"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
@@ -46,7 +48,6 @@ func TestStorage(t *testing.T) {
 	ctx := context.Background()
 	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
 	defer s.Stopper().Stop(ctx)
-	ie := s.InternalExecutor().(sqlutil.InternalExecutor)
 	tDB := sqlutils.MakeSQLRunner(sqlDB)
 	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
 
@@ -66,8 +67,9 @@ func TestStorage(t *testing.T) {
 		}, base.DefaultMaxClockOffset)
 		settings := cluster.MakeTestingClusterSettings()
 		stopper := stop.NewStopper()
-		storage := slstorage.NewTestingStorage(stopper, clock, kvDB, ie, settings,
-			dbName, timeSource.NewTimer)
+		tableID := descpb.ID(sqlutils.QueryTableID(t, sqlDB, dbName, "public", "sqlliveness"))
+		storage := slstorage.NewTestingStorage(stopper, clock, kvDB, keys.SystemSQLCodec, settings,
+			tableID, timeSource.NewTimer)
 		return clock, timeSource, settings, stopper, storage
 	}
 
@@ -83,6 +85,15 @@ func TestStorage(t *testing.T) {
 			require.NoError(t, storage.Insert(ctx, id, exp))
 			require.Equal(t, int64(1), metrics.WriteSuccesses.Count())
 		}
+		{
+			// The session written by the storage can be read with SQL.
+			dec := tree.TimestampToDecimal(exp)
+			var matches bool
+			tDB.QueryRow(t, `
+SELECT expiration = $2::DECIMAL FROM "`+t.Name()+`".sqlliveness WHERE session_id = $1`,
+				[]byte(id), dec.String()).Scan(&matches)
+			require.True(t, matches)
+		}
 		{
 			isAlive, err := storage.IsAlive(ctx, id)
 			require.NoError(t, err)
@@ -301,7 +312,6 @@ func TestConcurrentAccessesAndEvictions(t *testing.T) {
 	ctx := context.Background()
 	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
 	defer s.Stopper().Stop(ctx)
-	ie := s.InternalExecutor().(sqlutil.InternalExecutor)
 	tDB := sqlutils.MakeSQLRunner(sqlDB)
 	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
 	dbName := t.Name()
@@ -318,8 +328,9 @@ func TestConcurrentAccessesAndEvictions(t *testing.T) {
 	settings := cluster.MakeTestingClusterSettings()
 	stopper := stop.NewStopper()
 	slstorage.CacheSize.Override(&settings.SV, 10)
-	storage := slstorage.NewTestingStorage(stopper, clock, kvDB, ie, settings,
-		dbName, timeSource.NewTimer)
+	tableID := descpb.ID(sqlutils.QueryTableID(t, sqlDB, dbName, "public", "sqlliveness"))
+	storage := slstorage.NewTestingStorage(stopper, clock, kvDB, keys.SystemSQLCodec, settings,
+		tableID, timeSource.NewTimer)
 
 	const (
 		runsPerWorker   = 100
@@ -20,6 +20,10 @@ import (
 // across multiple nodes.
 var DistSQLExecCounter = telemetry.GetCounterOnce("sql.exec.query.is-distributed")
 
+// DistSQLNodeFailureRetryCounter is to be incremented whenever a distributed
+// query is re-planned and re-run because one of its nodes failed.
+var DistSQLNodeFailureRetryCounter = telemetry.GetCounterOnce("sql.exec.query.node-failure-retry")
+
 // VecExecCounter is to be incremented whenever a query runs with the vectorized
 // execution engine.
 var VecExecCounter = telemetry.GetCounterOnce("sql.exec.query.is-vectorized")
@@ -518,7 +518,7 @@ func avgRefreshTime(tableStats []*TableStatistic) time.Duration {
 			reference = stat
 			continue
 		}
-		if !areEqual(stat.ColumnIDs, reference.ColumnIDs) {
+		if !areEqual(stat.ColumnIDs, reference.ColumnIDs) || stat.Expression != reference.Expression {
 			continue
 		}
 		// Stats are sorted with the most recent first.
@@ -32,13 +32,16 @@ const (
 // DeleteOldStatsForColumns deletes old statistics from the
 // system.table_statistics table. For the given tableID and columnIDs,
 // DeleteOldStatsForColumns keeps the most recent keepCount automatic
-// statistics and deletes all the others.
+// statistics and deletes all the others. If expression is set, only the
+// statistics on that expression are considered; otherwise, only the
+// statistics on the columns themselves are.
 func DeleteOldStatsForColumns(
 	ctx context.Context,
 	executor sqlutil.InternalExecutor,
 	txn *kv.Txn,
 	tableID descpb.ID,
 	columnIDs []descpb.ColumnID,
+	expression string,
 ) error {
 	columnIDsVal := tree.NewDArray(types.Int)
 	for _, c := range columnIDs {
@@ -55,11 +58,13 @@ func DeleteOldStatsForColumns(
 		`DELETE FROM system.table_statistics
                WHERE "tableID" = $1
                AND "columnIDs" = $3
+               AND COALESCE(expression, '') = $5
                AND "statisticID" NOT IN (
                    SELECT "statisticID" FROM system.table_statistics
                    WHERE "tableID" = $1
                    AND "name" = $2
                    AND "columnIDs" = $3
+                   AND COALESCE(expression, '') = $5
                    ORDER BY "createdAt" DESC
                    LIMIT $4
                )`,
@@ -67,6 +72,7 @@ func DeleteOldStatsForColumns(
 		AutoStatsName,
 		columnIDsVal,
 		keepCount,
+		expression,
 	)
 	return err
 }
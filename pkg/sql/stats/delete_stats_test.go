@@ -265,7 +265,7 @@ func TestDeleteOldStatsForColumns(t *testing.T) {
 		tableID descpb.ID, columnIDs []descpb.ColumnID, expectDeleted map[uint64]struct{},
 	) error {
 		if err := s.DB().Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
-			return DeleteOldStatsForColumns(ctx, ex, txn, tableID, columnIDs)
+			return DeleteOldStatsForColumns(ctx, ex, txn, tableID, columnIDs, "" /* expression */)
 		}); err != nil {
 			return err
 		}
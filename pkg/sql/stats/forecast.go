@@ -0,0 +1,402 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+)
+
+// UseStatisticsForecasts controls the cluster setting for using forecasted
+// table statistics in the optimizer.
+var UseStatisticsForecasts = settings.RegisterPublicBoolSetting(
+	"sql.stats.forecasts.enabled",
+	"when true, the optimizer uses table statistics forecasted from the history "+
+		"of the collected statistics",
+	true,
+)
+
+// ForecastStatsName is the name of the forecasted statistics. Forecasts are
+// never stored in system.table_statistics.
+const ForecastStatsName = "__forecast__"
+
+const (
+	// minObservationsForForecast is the minimum number of statistics collected
+	// at distinct times on a set of columns that are needed to forecast it.
+	minObservationsForForecast = 3
+
+	// minGoodnessOfFit is the minimum coefficient of determination (R²) of the
+	// linear regression of a quantity over time for the quantity to be
+	// forecasted.
+	minGoodnessOfFit = 0.95
+)
+
+// ForecastTableStatistics forecasts the statistics of a table at the time of
+// its next expected refresh from the history of its statistics, which must be
+// ordered by CreatedAt, newest first (as returned by GetTableStats).
+//
+// A statistic is forecasted for each set of columns (or expression) with at
+// least minObservationsForForecast statistics collected at distinct times.
+// Only the quantities which follow a linear trend are extrapolated: the row
+// count, the distinct count, the null count and the bounds of the histograms
+// of numeric and temporal columns. The histogram of the latest statistic is
+// stretched to the forecasted bounds, and its counts are scaled to the
+// forecasted number of non-NULL rows, so that the rows inserted since the last
+// refresh (e.g. the newest rows of a timestamp-ordered table) are no longer
+// beyond the histogram. Quantities without a trend keep their latest value;
+// if no quantity has a trend, no forecast is made.
+//
+// The returned list contains the observed statistics in their order, each
+// forecast being placed right before the latest statistic it was made from.
+// If no forecast is made, observed is returned as is.
+func ForecastTableStatistics(observed []*TableStatistic) []*TableStatistic {
+	keys := make([]string, len(observed))
+	history := make(map[string][]*TableStatistic)
+	for i, stat := range observed {
+		keys[i] = forecastKey(stat)
+		history[keys[i]] = append(history[keys[i]], stat)
+	}
+
+	var res []*TableStatistic
+	for i, stat := range observed {
+		if h := history[keys[i]]; h[0] == stat {
+			if forecast := forecastStatistic(h); forecast != nil {
+				if res == nil {
+					res = make([]*TableStatistic, 0, len(observed)+1)
+					res = append(res, observed[:i]...)
+				}
+				res = append(res, forecast)
+			}
+		}
+		if res != nil {
+			res = append(res, stat)
+		}
+	}
+	if res == nil {
+		return observed
+	}
+	return res
+}
+
+// forecastKey identifies the statistics which are forecasted together: the
+// statistics on the same columns or expression, with histograms of the same
+// type (the histograms of inverted index keys are of type BYTES).
+func forecastKey(stat *TableStatistic) string {
+	var histType string
+	if stat.HistogramData != nil && stat.HistogramData.ColumnType != nil {
+		histType = stat.HistogramData.ColumnType.SQLString()
+	}
+	return fmt.Sprintf("%v/%s/%s", stat.ColumnIDs, stat.Expression, histType)
+}
+
+// forecastStatistic forecasts a statistic from the given history of statistics
+// on the same columns, newest first. It returns nil if no forecast can be made.
+func forecastStatistic(history []*TableStatistic) *TableStatistic {
+	// Only consider the latest statistic collected at any given time.
+	obs := make([]*TableStatistic, 0, len(history))
+	for _, stat := range history {
+		if len(obs) == 0 || stat.CreatedAt.Before(obs[len(obs)-1].CreatedAt) {
+			obs = append(obs, stat)
+		}
+	}
+	if len(obs) < minObservationsForForecast {
+		return nil
+	}
+
+	// Forecast the statistic at the time of the next expected refresh, assuming
+	// the statistics are refreshed at regular intervals.
+	latest, oldest := obs[0], obs[len(obs)-1]
+	interval := latest.CreatedAt.Sub(oldest.CreatedAt) / time.Duration(len(obs)-1)
+	at := latest.CreatedAt.Add(interval)
+	x := func(t time.Time) float64 {
+		return t.Sub(oldest.CreatedAt).Seconds()
+	}
+	xs := make([]float64, len(obs))
+	for i := range obs {
+		xs[i] = x(obs[i].CreatedAt)
+	}
+	predict := func(y func(stat *TableStatistic) float64) (float64, bool) {
+		ys := make([]float64, len(obs))
+		for i := range obs {
+			ys[i] = y(obs[i])
+		}
+		return predictLinear(xs, ys, x(at))
+	}
+
+	rowCount, fitRows := predict(func(stat *TableStatistic) float64 {
+		return float64(stat.RowCount)
+	})
+	distinctCount, fitDistinct := predict(func(stat *TableStatistic) float64 {
+		return float64(stat.DistinctCount)
+	})
+	nullCount, fitNulls := predict(func(stat *TableStatistic) float64 {
+		return float64(stat.NullCount)
+	})
+	lo, hi, fitBounds := forecastHistogramBounds(obs, xs, x(at))
+	if !fitRows && !fitDistinct && !fitNulls && !fitBounds {
+		return nil
+	}
+	if !fitRows {
+		rowCount = float64(latest.RowCount)
+	}
+	if !fitDistinct {
+		distinctCount = float64(latest.DistinctCount)
+	}
+	if !fitNulls {
+		nullCount = float64(latest.NullCount)
+	}
+
+	// Make the forecasted counts consistent with each other.
+	rowCount = math.Max(math.Round(rowCount), 0)
+	nullCount = math.Min(math.Max(math.Round(nullCount), 0), rowCount)
+	distinctCount = math.Min(math.Max(math.Round(distinctCount), 0), rowCount)
+	if rowCount > nullCount {
+		distinctCount = math.Max(distinctCount, 1)
+	}
+
+	forecast := &TableStatistic{
+		TableStatisticProto: TableStatisticProto{
+			TableID:       latest.TableID,
+			Name:          ForecastStatsName,
+			ColumnIDs:     latest.ColumnIDs,
+			CreatedAt:     at,
+			RowCount:      uint64(rowCount),
+			DistinctCount: uint64(distinctCount),
+			NullCount:     uint64(nullCount),
+			Expression:    latest.Expression,
+		},
+	}
+	if latest.Histogram != nil {
+		// Only the decoded histogram is forecasted; the encoded buckets are not
+		// used by the optimizer.
+		forecast.HistogramData = &HistogramData{ColumnType: latest.HistogramData.ColumnType}
+		scale := 1.0
+		if latestNonNull := float64(latest.RowCount - latest.NullCount); latestNonNull > 0 {
+			scale = (rowCount - nullCount) / latestNonNull
+		}
+		forecast.Histogram = forecastHistogram(latest.Histogram, lo, hi, fitBounds, scale)
+	}
+	return forecast
+}
+
+// predictLinear fits a line to the given points with a least squares linear
+// regression, and returns its value at x. It returns false if the points do
+// not follow a linear trend closely enough, or do not change at all.
+func predictLinear(xs, ys []float64, x float64) (float64, bool) {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, false
+	}
+	if r2 := sxy * sxy / (sxx * syy); r2 < minGoodnessOfFit {
+		return 0, false
+	}
+	return meanY + sxy/sxx*(x-meanX), true
+}
+
+// forecastHistogramBounds forecasts the lowest and highest upper bounds of the
+// histograms of the given statistics, newest first, observed at the times xs.
+// It returns false if the histograms are not on a numeric or temporal type or
+// if neither bound follows a linear trend.
+func forecastHistogramBounds(obs []*TableStatistic, xs []float64, x float64) (lo, hi float64, ok bool) {
+	los := make([]float64, len(obs))
+	his := make([]float64, len(obs))
+	for i, stat := range obs {
+		h := stat.Histogram
+		if len(h) == 0 {
+			return 0, 0, false
+		}
+		var okLo, okHi bool
+		los[i], okLo = boundToFloat(h[0].UpperBound)
+		his[i], okHi = boundToFloat(h[len(h)-1].UpperBound)
+		if !okLo || !okHi {
+			return 0, 0, false
+		}
+	}
+	lo, fitLo := predictLinear(xs, los, x)
+	hi, fitHi := predictLinear(xs, his, x)
+	if !fitLo {
+		lo = los[0]
+	}
+	if !fitHi {
+		hi = his[0]
+	}
+	if (!fitLo && !fitHi) || hi < lo {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+// forecastHistogram returns a copy of the given histogram with its counts
+// scaled by the given factor. If fitBounds is true, its bounds are also mapped
+// linearly so that its lowest and highest bounds become lo and hi, as long as
+// the mapped bounds remain strictly increasing.
+func forecastHistogram(
+	h []cat.HistogramBucket, lo, hi float64, fitBounds bool, scale float64,
+) []cat.HistogramBucket {
+	res := make([]cat.HistogramBucket, len(h))
+	copy(res, h)
+	if fitBounds {
+		if bounds, ok := mapHistogramBounds(h, lo, hi); ok {
+			for i := range res {
+				res[i].UpperBound = bounds[i]
+			}
+		}
+	}
+	for i := range res {
+		b := &res[i]
+		b.NumEq *= scale
+		b.NumRange *= scale
+		b.DistinctRange *= scale
+		if i > 0 {
+			// The number of distinct values in the range of an integer bucket is
+			// limited by the width of the range.
+			if maxDistinct, ok := maxDistinctRange(res[i-1].UpperBound, b.UpperBound); ok {
+				b.DistinctRange = math.Min(b.DistinctRange, maxDistinct)
+			}
+		}
+		b.DistinctRange = math.Min(b.DistinctRange, b.NumRange)
+	}
+	return res
+}
+
+// mapHistogramBounds maps the upper bounds of the given histogram linearly so
+// that its lowest and highest bounds become lo and hi.
+func mapHistogramBounds(h []cat.HistogramBucket, lo, hi float64) ([]tree.Datum, bool) {
+	oldLo, _ := boundToFloat(h[0].UpperBound)
+	oldHi, _ := boundToFloat(h[len(h)-1].UpperBound)
+	bounds := make([]tree.Datum, len(h))
+	var prev float64
+	for i := range h {
+		v, _ := boundToFloat(h[i].UpperBound)
+		if oldHi > oldLo {
+			v = lo + (v-oldLo)*(hi-lo)/(oldHi-oldLo)
+		} else {
+			v += lo - oldLo
+		}
+		d, ok := floatToBound(v, h[i].UpperBound)
+		if !ok {
+			return nil, false
+		}
+		// Rounding may have collapsed neighboring bounds.
+		cur, _ := boundToFloat(d)
+		if i > 0 && cur <= prev {
+			return nil, false
+		}
+		bounds[i], prev = d, cur
+	}
+	return bounds, true
+}
+
+// maxDistinctRange returns the maximum number of distinct values strictly
+// between the given bounds, for integer and date bounds.
+func maxDistinctRange(lower, upper tree.Datum) (float64, bool) {
+	switch upper.(type) {
+	case *tree.DInt, *tree.DDate:
+		l, okL := boundToFloat(lower)
+		u, okU := boundToFloat(upper)
+		if !okL || !okU {
+			return 0, false
+		}
+		return math.Max(u-l-1, 0), true
+	}
+	return 0, false
+}
+
+// boundToFloat converts a histogram bound of a numeric or temporal type to a
+// float, so that it can be extrapolated. Dates are converted to days, and
+// timestamps to microseconds since the Unix epoch.
+func boundToFloat(d tree.Datum) (float64, bool) {
+	var f float64
+	switch t := d.(type) {
+	case *tree.DInt:
+		f = float64(*t)
+	case *tree.DFloat:
+		f = float64(*t)
+	case *tree.DDate:
+		if !t.IsFinite() {
+			return 0, false
+		}
+		f = float64(t.UnixEpochDays())
+	case *tree.DTimestamp:
+		f = timeToMicros(t.Time)
+	case *tree.DTimestampTZ:
+		f = timeToMicros(t.Time)
+	default:
+		return 0, false
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// floatToBound is the inverse of boundToFloat: it converts the float to a
+// datum of the same type as the given datum.
+func floatToBound(f float64, like tree.Datum) (tree.Datum, bool) {
+	switch like.(type) {
+	case *tree.DInt:
+		f = math.Round(f)
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, false
+		}
+		return tree.NewDInt(tree.DInt(f)), true
+	case *tree.DFloat:
+		return tree.NewDFloat(tree.DFloat(f)), true
+	case *tree.DDate:
+		d, err := pgdate.MakeDateFromUnixEpoch(int64(math.Round(f)))
+		if err != nil {
+			return nil, false
+		}
+		return tree.NewDDate(d), true
+	case *tree.DTimestamp:
+		d, err := tree.MakeDTimestamp(microsToTime(f), time.Microsecond)
+		if err != nil {
+			return nil, false
+		}
+		return d, true
+	case *tree.DTimestampTZ:
+		d, err := tree.MakeDTimestampTZ(microsToTime(f), time.Microsecond)
+		if err != nil {
+			return nil, false
+		}
+		return d, true
+	}
+	return nil, false
+}
+
+func timeToMicros(t time.Time) float64 {
+	return float64(t.Unix())*1e6 + float64(t.Nanosecond()/1000)
+}
+
+func microsToTime(f float64) time.Time {
+	micros := int64(math.Round(f))
+	return timeutil.Unix(micros/1e6, (micros%1e6)*1000)
+}
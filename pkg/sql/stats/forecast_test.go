@@ -0,0 +1,107 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictLinear(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	xs := []float64{0, 1, 2, 3}
+
+	y, ok := predictLinear(xs, []float64{10, 20, 30, 40}, 4)
+	require.True(t, ok)
+	require.InDelta(t, 50, y, 1e-9)
+
+	// A quantity which doesn't change is not forecasted.
+	_, ok = predictLinear(xs, []float64{10, 10, 10, 10}, 4)
+	require.False(t, ok)
+
+	// Neither is a quantity without a linear trend.
+	_, ok = predictLinear(xs, []float64{10, 40, 10, 40}, 4)
+	require.False(t, ok)
+}
+
+func TestForecastTableStatistics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	histType := types.Int
+	makeStat := func(
+		hours int, rowCount, distinctCount uint64, lo, hi int64, expr string,
+	) *TableStatistic {
+		nonNull := float64(rowCount)
+		return &TableStatistic{
+			TableStatisticProto: TableStatisticProto{
+				TableID:       100,
+				ColumnIDs:     []descpb.ColumnID{1},
+				CreatedAt:     start.Add(time.Duration(hours) * time.Hour),
+				RowCount:      rowCount,
+				DistinctCount: distinctCount,
+				HistogramData: &HistogramData{ColumnType: histType},
+				Expression:    expr,
+			},
+			Histogram: []cat.HistogramBucket{
+				{NumEq: 1, UpperBound: tree.NewDInt(tree.DInt(lo))},
+				{NumEq: 1, NumRange: nonNull - 2, DistinctRange: nonNull - 2, UpperBound: tree.NewDInt(tree.DInt(hi))},
+			},
+		}
+	}
+
+	// Too few observations.
+	observed := []*TableStatistic{
+		makeStat(1, 200, 200, 100, 299, ""),
+		makeStat(0, 100, 100, 0, 99, ""),
+	}
+	require.Equal(t, observed, ForecastTableStatistics(observed))
+
+	// The row count, distinct count and histogram bounds grow linearly.
+	observed = []*TableStatistic{
+		makeStat(2, 300, 300, 0, 299, ""),
+		makeStat(2, 300, 300, 0, 299, "a + 1"),
+		makeStat(1, 200, 200, 0, 199, ""),
+		makeStat(0, 100, 100, 0, 99, ""),
+	}
+	res := ForecastTableStatistics(observed)
+	require.Len(t, res, 5)
+	require.Equal(t, observed, append(res[:0:0], res[1:]...))
+
+	forecast := res[0]
+	require.Equal(t, ForecastStatsName, forecast.Name)
+	require.Equal(t, start.Add(3*time.Hour), forecast.CreatedAt)
+	require.Equal(t, uint64(400), forecast.RowCount)
+	require.Equal(t, uint64(400), forecast.DistinctCount)
+	require.Equal(t, uint64(0), forecast.NullCount)
+	require.Equal(t, "", forecast.Expression)
+	require.Len(t, forecast.Histogram, 2)
+	require.Equal(t, tree.NewDInt(0), forecast.Histogram[0].UpperBound)
+	require.Equal(t, tree.NewDInt(399), forecast.Histogram[1].UpperBound)
+	require.InDelta(t, 4.0/3, forecast.Histogram[1].NumEq, 1e-9)
+	require.InDelta(t, 298*4.0/3, forecast.Histogram[1].NumRange, 1e-9)
+
+	// Without a trend, no forecast is made.
+	observed = []*TableStatistic{
+		makeStat(2, 100, 100, 0, 99, ""),
+		makeStat(1, 100, 100, 0, 99, ""),
+		makeStat(0, 100, 100, 0, 99, ""),
+	}
+	require.Equal(t, observed, ForecastTableStatistics(observed))
+}
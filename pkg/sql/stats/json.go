@@ -31,6 +31,9 @@ type JSONStatistic struct {
 	RowCount      uint64   `json:"row_count"`
 	DistinctCount uint64   `json:"distinct_count"`
 	NullCount     uint64   `json:"null_count"`
+	// Expression is set if the statistic is on an expression rather than on
+	// the columns, which are then the columns referenced by the expression.
+	Expression string `json:"expression,omitempty"`
 	// HistogramColumnType is the string representation of the column type for the
 	// histogram (or unset if there is no histogram). Parsable with
 	// tree.ParseType.
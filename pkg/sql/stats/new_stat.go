@@ -43,6 +43,7 @@ func InsertNewStats(
 			int64(statistic.DistinctCount),
 			int64(statistic.NullCount),
 			statistic.HistogramData,
+			statistic.Expression,
 		)
 		if err != nil {
 			return err
@@ -51,7 +52,9 @@ func InsertNewStats(
 	return nil
 }
 
-// InsertNewStat inserts a new statistic in the system table.
+// InsertNewStat inserts a new statistic in the system table. If expression is
+// set, the statistic is on that expression rather than on the columns, which
+// are the ones it references.
 // The caller is responsible for calling GossipTableStatAdded to notify the stat
 // caches.
 func InsertNewStat(
@@ -63,12 +66,16 @@ func InsertNewStat(
 	columnIDs []descpb.ColumnID,
 	rowCount, distinctCount, nullCount int64,
 	h *HistogramData,
+	expression string,
 ) error {
 	// We must pass a nil interface{} if we want to insert a NULL.
-	var nameVal, histogramVal interface{}
+	var nameVal, histogramVal, expressionVal interface{}
 	if name != "" {
 		nameVal = name
 	}
+	if expression != "" {
+		expressionVal = expression
+	}
 	if h != nil {
 		var err error
 		histogramVal, err = protoutil.Marshal(h)
@@ -93,8 +100,9 @@ func InsertNewStat(
 					"rowCount",
 					"distinctCount",
 					"nullCount",
-					histogram
-				) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+					histogram,
+					expression
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
 		tableID,
 		nameVal,
 		columnIDsVal,
@@ -102,6 +110,7 @@ func InsertNewStat(
 		distinctCount,
 		nullCount,
 		histogramVal,
+		expressionVal,
 	)
 	return err
 }
@@ -93,6 +93,10 @@ type cacheEntry struct {
 
 	stats []*TableStatistic
 
+	// statsWithForecasts contains stats along with the statistics forecasted
+	// from them (see ForecastTableStatistics).
+	statsWithForecasts []*TableStatistic
+
 	// err is populated if the internal query to retrieve stats hit an error.
 	err error
 }
@@ -167,7 +171,30 @@ func (sc *TableStatisticsCache) GetTableStats(
 		return e.stats, e.err
 	}
 
-	return sc.addCacheEntryLocked(ctx, tableID)
+	stats, _, err := sc.addCacheEntryLocked(ctx, tableID)
+	return stats, err
+}
+
+// GetTableStatsWithForecasts is like GetTableStats, but the returned
+// statistics also include the statistics forecasted from the history of the
+// collected statistics, each forecast placed right before the latest
+// statistic it was made from (see ForecastTableStatistics).
+func (sc *TableStatisticsCache) GetTableStatsWithForecasts(
+	ctx context.Context, tableID descpb.ID,
+) ([]*TableStatistic, error) {
+	if descpb.IsReservedID(tableID) || descpb.IsVirtualTable(tableID) {
+		return nil, nil
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if found, e := sc.lookupStatsLocked(ctx, tableID, false /* stealthy */); found {
+		return e.statsWithForecasts, e.err
+	}
+
+	_, statsWithForecasts, err := sc.addCacheEntryLocked(ctx, tableID)
+	return statsWithForecasts, err
 }
 
 // lookupStatsLocked retrieves any existing stats for the given table.
@@ -221,7 +248,7 @@ func (sc *TableStatisticsCache) lookupStatsLocked(
 //
 func (sc *TableStatisticsCache) addCacheEntryLocked(
 	ctx context.Context, tableID descpb.ID,
-) (stats []*TableStatistic, err error) {
+) (stats, statsWithForecasts []*TableStatistic, err error) {
 	// Add a cache entry that other queries can find and wait on until we have the
 	// stats.
 	e := &cacheEntry{
@@ -238,10 +265,13 @@ func (sc *TableStatisticsCache) addCacheEntryLocked(
 		log.VEventf(ctx, 1, "reading statistics for table %d", tableID)
 		stats, err = sc.getTableStatsFromDB(ctx, tableID)
 		log.VEventf(ctx, 1, "finished reading statistics for table %d", tableID)
+		if err == nil {
+			statsWithForecasts = ForecastTableStatistics(stats)
+		}
 	}()
 
 	e.mustWait = false
-	e.stats, e.err = stats, err
+	e.stats, e.statsWithForecasts, e.err = stats, statsWithForecasts, err
 
 	// Wake up any other callers that are waiting on these stats.
 	e.waitCond.Broadcast()
@@ -251,7 +281,7 @@ func (sc *TableStatisticsCache) addCacheEntryLocked(
 		sc.mu.cache.Del(tableID)
 	}
 
-	return stats, err
+	return stats, statsWithForecasts, err
 }
 
 // refreshCacheEntry retrieves table statistics from the database and updates
@@ -284,7 +314,7 @@ func (sc *TableStatisticsCache) refreshCacheEntry(ctx context.Context, tableID d
 	}
 	e.refreshing = true
 
-	var stats []*TableStatistic
+	var stats, statsWithForecasts []*TableStatistic
 	var err error
 	for {
 		func() {
@@ -295,6 +325,9 @@ func (sc *TableStatisticsCache) refreshCacheEntry(ctx context.Context, tableID d
 			log.VEventf(ctx, 1, "refreshing statistics for table %d", tableID)
 			stats, err = sc.getTableStatsFromDB(ctx, tableID)
 			log.VEventf(ctx, 1, "done refreshing statistics for table %d", tableID)
+			if err == nil {
+				statsWithForecasts = ForecastTableStatistics(stats)
+			}
 		}()
 		if !e.mustRefreshAgain {
 			break
@@ -302,7 +335,7 @@ func (sc *TableStatisticsCache) refreshCacheEntry(ctx context.Context, tableID d
 		e.mustRefreshAgain = false
 	}
 
-	e.stats, e.err = stats, err
+	e.stats, e.statsWithForecasts, e.err = stats, statsWithForecasts, err
 	e.refreshing = false
 
 	if err != nil {
@@ -345,6 +378,7 @@ const (
 	distinctCountIndex
 	nullCountIndex
 	histogramIndex
+	expressionIndex
 	statsLen
 )
 
@@ -378,6 +412,7 @@ func (sc *TableStatisticsCache) parseStats(
 		{"distinctCount", distinctCountIndex, types.Int, false},
 		{"nullCount", nullCountIndex, types.Int, false},
 		{"histogram", histogramIndex, types.Bytes, true},
+		{"expression", expressionIndex, types.String, true},
 	}
 	for _, v := range expectedTypes {
 		if !datums[v.fieldIndex].ResolvedType().Equivalent(v.expectedType) &&
@@ -406,6 +441,9 @@ func (sc *TableStatisticsCache) parseStats(
 	if datums[nameIndex] != tree.DNull {
 		res.Name = string(*datums[nameIndex].(*tree.DString))
 	}
+	if datums[expressionIndex] != tree.DNull {
+		res.Expression = string(*datums[expressionIndex].(*tree.DString))
+	}
 	if datums[histogramIndex] != tree.DNull {
 		res.HistogramData = &HistogramData{}
 		if err := protoutil.Unmarshal(
@@ -476,7 +514,8 @@ SELECT
 	"rowCount",
 	"distinctCount",
 	"nullCount",
-	histogram
+	histogram,
+	expression
 FROM system.table_statistics
 WHERE "tableID" = $1
 ORDER BY "createdAt" DESC
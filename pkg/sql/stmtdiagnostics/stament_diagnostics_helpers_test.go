@@ -10,11 +10,20 @@
 
 package stmtdiagnostics
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // InsertRequestInternal exposes the form of insert which returns the request ID
 // as an int64 to tests in this package.
-func (r *Registry) InsertRequestInternal(ctx context.Context, fprint string) (int64, error) {
-	id, err := r.insertRequestInternal(ctx, fprint)
+func (r *Registry) InsertRequestInternal(
+	ctx context.Context,
+	fprint string,
+	samplingProbability float64,
+	minExecutionLatency time.Duration,
+	expiresAfter time.Duration,
+) (int64, error) {
+	id, err := r.insertRequestInternal(ctx, fprint, samplingProbability, minExecutionLatency, expiresAfter)
 	return int64(id), err
 }
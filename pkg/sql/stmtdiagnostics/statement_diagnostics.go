@@ -13,8 +13,10 @@ package stmtdiagnostics
 import (
 	"context"
 	"encoding/binary"
+	"math/rand"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -26,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
@@ -60,7 +63,7 @@ type Registry struct {
 		// internally; it'd deadlock.
 		syncutil.Mutex
 		// requests waiting for the right query to come along.
-		requestFingerprints map[requestID]string
+		requestFingerprints map[requestID]requestInfo
 		// ids of requests that this node is in the process of servicing.
 		ongoing map[requestID]struct{}
 
@@ -170,19 +173,42 @@ type requestID int
 // to the id column in statement_diagnostics.
 type stmtID int
 
+// requestInfo describes a diagnostics request: the fingerprint of the
+// statements it targets, and the conditions under which an execution of such a
+// statement satisfies it.
+type requestInfo struct {
+	fingerprint string
+	// minExecutionLatency, if non-zero, makes the request conditional: only an
+	// execution that takes at least this long satisfies it, and the request
+	// stays pending until such an execution comes along.
+	minExecutionLatency time.Duration
+	// samplingProbability, if non-zero, is the probability with which an
+	// execution of a matching statement is traced.
+	samplingProbability float64
+	// expiresAt, if non-zero, is the time after which the request is no longer
+	// satisfied.
+	expiresAt time.Time
+}
+
+func (info requestInfo) isExpired(now time.Time) bool {
+	return !info.expiresAt.IsZero() && !info.expiresAt.After(now)
+}
+
+func (info requestInfo) isConditional() bool {
+	return info.minExecutionLatency > 0
+}
+
 // addRequestInternalLocked adds a request to r.mu.requests. If the request is
 // already present, the call is a noop.
-func (r *Registry) addRequestInternalLocked(
-	ctx context.Context, id requestID, queryFingerprint string,
-) {
+func (r *Registry) addRequestInternalLocked(ctx context.Context, id requestID, info requestInfo) {
 	if r.findRequestLocked(id) {
 		// Request already exists.
 		return
 	}
 	if r.mu.requestFingerprints == nil {
-		r.mu.requestFingerprints = make(map[requestID]string)
+		r.mu.requestFingerprints = make(map[requestID]requestInfo)
 	}
-	r.mu.requestFingerprints[id] = queryFingerprint
+	r.mu.requestFingerprints[id] = info
 }
 
 func (r *Registry) findRequest(requestID requestID) bool {
@@ -201,27 +227,77 @@ func (r *Registry) findRequestLocked(requestID requestID) bool {
 }
 
 // InsertRequest is part of the StmtDiagnosticsRequester interface.
-func (r *Registry) InsertRequest(ctx context.Context, fprint string) error {
-	_, err := r.insertRequestInternal(ctx, fprint)
+//
+// If minExecutionLatency is non-zero, only an execution of the statement that
+// takes at least that long is collected. If samplingProbability is non-zero,
+// each execution of the statement is traced with that probability. If
+// expiresAfter is non-zero, the request is abandoned once that much time has
+// passed without it being satisfied.
+func (r *Registry) InsertRequest(
+	ctx context.Context,
+	fprint string,
+	samplingProbability float64,
+	minExecutionLatency time.Duration,
+	expiresAfter time.Duration,
+) error {
+	_, err := r.insertRequestInternal(ctx, fprint, samplingProbability, minExecutionLatency, expiresAfter)
 	return err
 }
 
-func (r *Registry) insertRequestInternal(ctx context.Context, fprint string) (requestID, error) {
+func (r *Registry) insertRequestInternal(
+	ctx context.Context,
+	fprint string,
+	samplingProbability float64,
+	minExecutionLatency time.Duration,
+	expiresAfter time.Duration,
+) (requestID, error) {
 	g, err := r.gossip.OptionalErr(48274)
 	if err != nil {
 		return 0, err
 	}
 
+	if samplingProbability < 0 || samplingProbability > 1 {
+		return 0, errors.Newf(
+			"expected sampling probability in range [0.0, 1.0], got %f", samplingProbability)
+	}
+	if minExecutionLatency < 0 {
+		return 0, errors.Newf(
+			"expected a non-negative minimum execution latency, got %s", minExecutionLatency)
+	}
+	if expiresAfter < 0 {
+		return 0, errors.Newf(
+			"expected a non-negative expiration, got %s", expiresAfter)
+	}
+	isConditional := samplingProbability != 0 || minExecutionLatency != 0 || expiresAfter != 0
+	if isConditional && !r.st.Version.IsActive(ctx, clusterversion.VersionAlterSystemStmtDiagReqs) {
+		return 0, errors.New(
+			"conditional statement diagnostics are only supported after the cluster version is upgraded")
+	}
+
+	now := timeutil.Now()
+	info := requestInfo{
+		fingerprint:         fprint,
+		minExecutionLatency: minExecutionLatency,
+		samplingProbability: samplingProbability,
+	}
+	if expiresAfter != 0 {
+		info.expiresAt = now.Add(expiresAfter)
+	}
+
 	var reqID requestID
 	err = r.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 		// Check if there's already a pending request for this fingerprint.
+		pendingQuery := "SELECT count(1) FROM system.statement_diagnostics_requests " +
+			"WHERE completed = false AND statement_fingerprint = $1"
+		if r.st.Version.IsActive(ctx, clusterversion.VersionAlterSystemStmtDiagReqs) {
+			// Expired requests don't count as pending.
+			pendingQuery += " AND (expires_at IS NULL OR expires_at > now())"
+		}
 		row, err := r.ie.QueryRowEx(ctx, "stmt-diag-check-pending", txn,
 			sessiondata.InternalExecutorOverride{
 				User: security.RootUser,
 			},
-			"SELECT count(1) FROM system.statement_diagnostics_requests "+
-				"WHERE completed = false AND statement_fingerprint = $1",
-			fprint)
+			pendingQuery, fprint)
 		if err != nil {
 			return err
 		}
@@ -230,13 +306,33 @@ func (r *Registry) insertRequestInternal(ctx context.Context, fprint string) (re
 			return errors.New("a pending request for the requested fingerprint already exists")
 		}
 
+		insertStmt := "INSERT INTO system.statement_diagnostics_requests (statement_fingerprint, requested_at) " +
+			"VALUES ($1, $2) RETURNING id"
+		qargs := []interface{}{fprint, now}
+		if isConditional {
+			insertStmt = "INSERT INTO system.statement_diagnostics_requests " +
+				"(statement_fingerprint, requested_at, sampling_probability, min_execution_latency, expires_at) " +
+				"VALUES ($1, $2, $3, $4, $5) RETURNING id"
+			samplingProbabilityVal, minExecutionLatencyVal, expiresAtVal := tree.DNull, tree.DNull, tree.DNull
+			if samplingProbability != 0 {
+				samplingProbabilityVal = tree.NewDFloat(tree.DFloat(samplingProbability))
+			}
+			if minExecutionLatency != 0 {
+				minExecutionLatencyVal = tree.NewDInterval(
+					duration.MakeDuration(minExecutionLatency.Nanoseconds(), 0 /* days */, 0 /* months */),
+					types.DefaultIntervalTypeMetadata,
+				)
+			}
+			if !info.expiresAt.IsZero() {
+				expiresAtVal = tree.MustMakeDTimestampTZ(info.expiresAt, time.Microsecond)
+			}
+			qargs = append(qargs, samplingProbabilityVal, minExecutionLatencyVal, expiresAtVal)
+		}
 		row, err = r.ie.QueryRowEx(ctx, "stmt-diag-insert-request", txn,
 			sessiondata.InternalExecutorOverride{
 				User: security.RootUser,
 			},
-			"INSERT INTO system.statement_diagnostics_requests (statement_fingerprint, requested_at) "+
-				"VALUES ($1, $2) RETURNING id",
-			fprint, timeutil.Now())
+			insertStmt, qargs...)
 		if err != nil {
 			return err
 		}
@@ -253,7 +349,7 @@ func (r *Registry) insertRequestInternal(ctx context.Context, fprint string) (re
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.mu.epoch++
-	r.addRequestInternalLocked(ctx, reqID, fprint)
+	r.addRequestInternalLocked(ctx, reqID, info)
 
 	// Notify all the other nodes that they have to poll.
 	buf := make([]byte, 8)
@@ -270,6 +366,10 @@ func (r *Registry) removeOngoing(requestID requestID) {
 	defer r.mu.Unlock()
 	// Remove the request from r.mu.ongoing.
 	delete(r.mu.ongoing, requestID)
+	// Conditional requests are left in r.mu.requestFingerprints while they are
+	// being serviced; they are now either satisfied or will be picked up again
+	// by the poller.
+	delete(r.mu.requestFingerprints, requestID)
 }
 
 // ShouldCollectDiagnostics checks whether any data should be collected for the
@@ -277,29 +377,61 @@ func (r *Registry) removeOngoing(requestID requestID) {
 // statement's fingerprint; in this case ShouldCollectDiagnostics will not
 // return true again on this note for the same diagnostics request.
 //
-// If data is to be collected, Finish() must always be called on the returned
-// stmtDiagnosticsHelper once the data was collected.
+// Conditional requests are the exception: since it isn't known yet whether
+// this execution will be slow enough to satisfy the request, the request stays
+// in the registry, and minExecutionLatency is returned. Once the statement has
+// executed, the caller must only collect the diagnostics and call Finish() if
+// the execution took at least minExecutionLatency.
+//
+// If data is to be collected for an unconditional request, Finish() must
+// always be called on the returned stmtDiagnosticsHelper once the data was
+// collected.
 func (r *Registry) ShouldCollectDiagnostics(
 	ctx context.Context, ast tree.Statement,
-) (bool, func(ctx context.Context, traceJSON tree.Datum, bundle []byte, collectionErr error)) {
+) (
+	shouldCollect bool,
+	finish func(ctx context.Context, traceJSON tree.Datum, bundle []byte, collectionErr error),
+	minExecutionLatency time.Duration,
+) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	// Return quickly if we have no requests to trace.
 	if len(r.mu.requestFingerprints) == 0 {
-		return false, nil
+		return false, nil, 0
 	}
 
 	fingerprint := tree.AsStringWithFlags(ast, tree.FmtHideConstants)
+	now := timeutil.Now()
 	var reqID requestID
+	var info requestInfo
 	for id, f := range r.mu.requestFingerprints {
-		if f == fingerprint {
+		if f.isExpired(now) {
+			// The request can't be satisfied anymore; the poller will not pick
+			// it up again either.
+			delete(r.mu.requestFingerprints, id)
+			continue
+		}
+		if f.fingerprint == fingerprint {
 			reqID = id
+			info = f
 			break
 		}
 	}
 	if reqID == 0 {
-		return false, nil
+		return false, nil, 0
+	}
+
+	if info.samplingProbability != 0 && rand.Float64() >= info.samplingProbability {
+		// This execution wasn't sampled.
+		return false, nil, 0
+	}
+
+	if info.isConditional() {
+		// Keep the request around until an execution satisfies it; the request
+		// is removed by the poller once it is marked as completed.
+		return true, makeStmtDiagnosticsHelper(r, fingerprint, tree.AsString(ast), reqID).Finish,
+			info.minExecutionLatency
 	}
 
 	// Remove the request.
@@ -309,7 +441,7 @@ func (r *Registry) ShouldCollectDiagnostics(
 	}
 
 	r.mu.ongoing[reqID] = struct{}{}
-	return true, makeStmtDiagnosticsHelper(r, fingerprint, tree.AsString(ast), reqID).Finish
+	return true, makeStmtDiagnosticsHelper(r, fingerprint, tree.AsString(ast), reqID).Finish, 0
 }
 
 type stmtDiagnosticsHelper struct {
@@ -484,6 +616,7 @@ func (r *Registry) insertStatementDiagnostics(
 // updates r.mu.requests accordingly.
 func (r *Registry) pollRequests(ctx context.Context) error {
 	var rows []tree.Datums
+	isConditionalSupported := r.st.Version.IsActive(ctx, clusterversion.VersionAlterSystemStmtDiagReqs)
 	// Loop until we run the query without straddling an epoch increment.
 	for {
 		r.mu.Lock()
@@ -491,12 +624,18 @@ func (r *Registry) pollRequests(ctx context.Context) error {
 		r.mu.Unlock()
 
 		var err error
+		pollQuery := "SELECT id, statement_fingerprint FROM system.statement_diagnostics_requests " +
+			"WHERE completed = false"
+		if isConditionalSupported {
+			pollQuery = "SELECT id, statement_fingerprint, min_execution_latency, expires_at, sampling_probability " +
+				"FROM system.statement_diagnostics_requests " +
+				"WHERE completed = false AND (expires_at IS NULL OR expires_at > now())"
+		}
 		rows, err = r.ie.QueryEx(ctx, "stmt-diag-poll", nil, /* txn */
 			sessiondata.InternalExecutorOverride{
 				User: security.RootUser,
 			},
-			"SELECT id, statement_fingerprint FROM system.statement_diagnostics_requests "+
-				"WHERE completed = false")
+			pollQuery)
 		if err != nil {
 			return err
 		}
@@ -516,10 +655,23 @@ func (r *Registry) pollRequests(ctx context.Context) error {
 	var ids util.FastIntSet
 	for _, row := range rows {
 		id := requestID(*row[0].(*tree.DInt))
-		fprint := string(*row[1].(*tree.DString))
+		info := requestInfo{fingerprint: string(*row[1].(*tree.DString))}
+		if isConditionalSupported {
+			if minLatency, ok := row[2].(*tree.DInterval); ok {
+				if nanos, _, _, err := minLatency.Duration.Encode(); err == nil {
+					info.minExecutionLatency = time.Duration(nanos)
+				}
+			}
+			if expiresAt, ok := row[3].(*tree.DTimestampTZ); ok {
+				info.expiresAt = expiresAt.Time
+			}
+			if prob, ok := row[4].(*tree.DFloat); ok {
+				info.samplingProbability = float64(*prob)
+			}
+		}
 
 		ids.Add(int(id))
-		r.addRequestInternalLocked(ctx, id, fprint)
+		r.addRequestInternalLocked(ctx, id, info)
 	}
 
 	// Remove all other requests.
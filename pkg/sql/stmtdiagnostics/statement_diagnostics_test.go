@@ -41,7 +41,7 @@ func TestDiagnosticsRequest(t *testing.T) {
 
 	// Ask to trace a particular query.
 	registry := s.ExecutorConfig().(sql.ExecutorConfig).StmtDiagnosticsRecorder
-	reqID, err := registry.InsertRequestInternal(ctx, "INSERT INTO test VALUES (_)")
+	reqID, err := registry.InsertRequestInternal(ctx, "INSERT INTO test VALUES (_)", 0, 0, 0)
 	require.NoError(t, err)
 	reqRow := db.QueryRow(
 		"SELECT completed, statement_diagnostics_id FROM system.statement_diagnostics_requests WHERE ID = $1", reqID)
@@ -74,11 +74,11 @@ func TestDiagnosticsRequest(t *testing.T) {
 	require.Contains(t, json, "statement execution committed the txn")
 
 	// Verify that we can handle multiple requests at the same time.
-	id1, err := registry.InsertRequestInternal(ctx, "INSERT INTO test VALUES (_)")
+	id1, err := registry.InsertRequestInternal(ctx, "INSERT INTO test VALUES (_)", 0, 0, 0)
 	require.NoError(t, err)
-	id2, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test")
+	id2, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test", 0, 0, 0)
 	require.NoError(t, err)
-	id3, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test WHERE x > _")
+	id3, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test WHERE x > _", 0, 0, 0)
 	require.NoError(t, err)
 
 	// Run the queries in a different order.
@@ -93,6 +93,40 @@ func TestDiagnosticsRequest(t *testing.T) {
 	_, err = db.Exec("INSERT INTO test VALUES (2)")
 	require.NoError(t, err)
 	checkCompleted(id1)
+
+	checkNotCompleted := func(reqID int64) {
+		traceRow := db.QueryRow(
+			"SELECT completed, statement_diagnostics_id FROM system.statement_diagnostics_requests WHERE ID = $1", reqID)
+		require.NoError(t, traceRow.Scan(&completed, &traceID))
+		require.False(t, completed)
+		require.False(t, traceID.Valid)
+	}
+
+	// A conditional request is only satisfied by a slow enough execution.
+	minLatency := 100 * time.Millisecond
+	condID, err := registry.InsertRequestInternal(ctx, "SELECT pg_sleep(_)", 0, minLatency, 0)
+	require.NoError(t, err)
+	_, err = db.Exec("SELECT pg_sleep(0)")
+	require.NoError(t, err)
+	checkNotCompleted(condID)
+	_, err = db.Exec(fmt.Sprintf("SELECT pg_sleep(%f)", minLatency.Seconds()))
+	require.NoError(t, err)
+	checkCompleted(condID)
+
+	// An expired request is not satisfied, and doesn't prevent a new request for
+	// the same fingerprint.
+	expiredID, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test", 0, 0, time.Nanosecond)
+	require.NoError(t, err)
+	_, err = db.Exec("SELECT x FROM test")
+	require.NoError(t, err)
+	checkNotCompleted(expiredID)
+	_, err = registry.InsertRequestInternal(ctx, "SELECT x FROM test", 0, 0, time.Hour)
+	require.NoError(t, err)
+
+	// Invalid sampling probabilities are rejected.
+	_, err = registry.InsertRequestInternal(ctx, "SELECT x FROM test WHERE x < _", 1.5, minLatency, 0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected sampling probability in range [0.0, 1.0]")
 }
 
 // Test that a different node can service a diagnostics request.
@@ -108,7 +142,7 @@ func TestDiagnosticsRequestDifferentNode(t *testing.T) {
 
 	// Ask to trace a particular query using node 0.
 	registry := tc.Server(0).ExecutorConfig().(sql.ExecutorConfig).StmtDiagnosticsRecorder
-	reqID, err := registry.InsertRequestInternal(ctx, "INSERT INTO test VALUES (_)")
+	reqID, err := registry.InsertRequestInternal(ctx, "INSERT INTO test VALUES (_)", 0, 0, 0)
 	require.NoError(t, err)
 	reqRow := db0.QueryRow(
 		`SELECT completed, statement_diagnostics_id FROM system.statement_diagnostics_requests
@@ -150,11 +184,11 @@ func TestDiagnosticsRequestDifferentNode(t *testing.T) {
 	runUntilTraced("INSERT INTO test VALUES (1)", reqID)
 
 	// Verify that we can handle multiple requests at the same time.
-	id1, err := registry.InsertRequestInternal(ctx, "INSERT INTO test VALUES (_)")
+	id1, err := registry.InsertRequestInternal(ctx, "INSERT INTO test VALUES (_)", 0, 0, 0)
 	require.NoError(t, err)
-	id2, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test")
+	id2, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test", 0, 0, 0)
 	require.NoError(t, err)
-	id3, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test WHERE x > _")
+	id3, err := registry.InsertRequestInternal(ctx, "SELECT x FROM test WHERE x > _", 0, 0, 0)
 	require.NoError(t, err)
 
 	// Run the queries in a different order.
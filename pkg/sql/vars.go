@@ -465,6 +465,44 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// CockroachDB extension.
+	`enable_eager_aggregation`: {
+		GetStringVal: makePostgresBoolGetStringValFn(`enable_eager_aggregation`),
+		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
+			b, err := parseBoolVar("enable_eager_aggregation", s)
+			if err != nil {
+				return err
+			}
+			m.SetEagerAggregationEnabled(b)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return formatBoolAsPostgresSetting(evalCtx.SessionData.EagerAggregationEnabled)
+		},
+		GlobalDefault: func(sv *settings.Values) string {
+			return formatBoolAsPostgresSetting(eagerAggregationClusterMode.Get(sv))
+		},
+	},
+
+	// See https://www.postgresql.org/docs/12/runtime-config-query.html#GUC-PLAN-CACHE-MODE
+	`plan_cache_mode`: {
+		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
+			mode, ok := sessiondata.PlanCacheModeFromString(s)
+			if !ok {
+				return newVarValueError(`plan_cache_mode`, s,
+					"force_custom_plan", "force_generic_plan", "auto")
+			}
+			m.SetPlanCacheMode(mode)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return evalCtx.SessionData.PlanCacheMode.String()
+		},
+		GlobalDefault: func(sv *settings.Values) string {
+			return sessiondata.PlanCacheMode(planCacheClusterMode.Get(sv)).String()
+		},
+	},
+
 	// CockroachDB extension.
 	`reorder_joins_limit`: {
 		GetStringVal: makeIntGetStringValFn(`reorder_joins_limit`),
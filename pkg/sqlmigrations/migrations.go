@@ -366,6 +366,47 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "add CREATELOGIN privilege to roles with CREATEROLE",
 		workFn: extendCreateRoleWithCreateLogin,
 	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.statement_statistics and system.transaction_statistics tables",
+		workFn:              createSQLStatsTables,
+		includedInBootstrap: clusterversion.VersionByKey(clusterversion.VersionSQLStatsTables),
+		newDescriptorIDs: staticIDs(
+			keys.StatementStatisticsTableID, keys.TransactionStatisticsTableID),
+	},
+	{
+		// Introduced in v21.1.
+		name:                "add conditional diagnostics columns to system.statement_diagnostics_requests",
+		workFn:              alterSystemStmtDiagReqs,
+		includedInBootstrap: clusterversion.VersionByKey(clusterversion.VersionAlterSystemStmtDiagReqs),
+	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.role_audit_policies table",
+		workFn:              createRoleAuditPoliciesTable,
+		includedInBootstrap: clusterversion.VersionByKey(clusterversion.VersionRoleAuditPolicies),
+		newDescriptorIDs:    staticIDs(keys.RoleAuditPoliciesTableID),
+	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.plan_baselines table",
+		workFn:              createPlanBaselinesTable,
+		includedInBootstrap: clusterversion.VersionByKey(clusterversion.VersionPlanBaselines),
+		newDescriptorIDs:    staticIDs(keys.PlanBaselinesTableID),
+	},
+	{
+		// Introduced in v21.1.
+		name:                "create system.sql_instances table",
+		workFn:              createSQLInstancesTable,
+		includedInBootstrap: clusterversion.VersionByKey(clusterversion.VersionSQLInstancesTable),
+		newDescriptorIDs:    staticIDs(keys.SQLInstancesTableID),
+	},
+	{
+		// Introduced in v21.1.
+		name:                "add expression column to system.table_statistics",
+		workFn:              alterSystemTableStatisticsAddExpression,
+		includedInBootstrap: clusterversion.VersionByKey(clusterversion.VersionStatisticsExpressions),
+	},
 }
 
 func staticIDs(
@@ -1361,6 +1402,66 @@ ADD COLUMN IF NOT EXISTS claim_instance_id INT8 FAMILY claim
 	return createSystemTable(ctx, r, systemschema.SqllivenessTable)
 }
 
+func createSQLStatsTables(ctx context.Context, r runner) error {
+	if err := createSystemTable(ctx, r, systemschema.StatementStatisticsTable); err != nil {
+		return err
+	}
+	return createSystemTable(ctx, r, systemschema.TransactionStatisticsTable)
+}
+
+func alterSystemStmtDiagReqs(ctx context.Context, r runner) error {
+	addColsStmt := `
+ALTER TABLE system.statement_diagnostics_requests
+ADD COLUMN IF NOT EXISTS min_execution_latency INTERVAL NULL FAMILY "primary",
+ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ NULL FAMILY "primary",
+ADD COLUMN IF NOT EXISTS sampling_probability FLOAT NULL FAMILY "primary"
+`
+	// The polling index is replaced by one that also stores the new columns,
+	// so that the registry can keep reading pending requests without an
+	// index join.
+	addIdxStmt := `
+CREATE INDEX IF NOT EXISTS completed_idx_v2
+ON system.statement_diagnostics_requests (completed, id)
+STORING (statement_fingerprint, min_execution_latency, expires_at, sampling_probability)
+`
+	dropIdxStmt := `DROP INDEX IF EXISTS system.statement_diagnostics_requests@completed_idx`
+	asNode := sessiondata.InternalExecutorOverride{User: security.NodeUser}
+
+	if _, err := r.sqlExecutor.ExecEx(
+		ctx, "add-stmt-diag-reqs-cols", nil, asNode, addColsStmt); err != nil {
+		return err
+	}
+	if _, err := r.sqlExecutor.ExecEx(
+		ctx, "add-stmt-diag-reqs-idx", nil, asNode, addIdxStmt); err != nil {
+		return err
+	}
+	_, err := r.sqlExecutor.ExecEx(ctx, "drop-stmt-diag-reqs-idx", nil, asNode, dropIdxStmt)
+	return err
+}
+
+func createRoleAuditPoliciesTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.RoleAuditPoliciesTable)
+}
+
+func createPlanBaselinesTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.PlanBaselinesTable)
+}
+
+func createSQLInstancesTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, systemschema.SQLInstancesTable)
+}
+
+func alterSystemTableStatisticsAddExpression(ctx context.Context, r runner) error {
+	addColStmt := `
+ALTER TABLE system.table_statistics
+ADD COLUMN IF NOT EXISTS expression STRING NULL
+FAMILY "fam_0_tableID_statisticID_name_columnIDs_createdAt_rowCount_distinctCount_nullCount_histogram"
+`
+	asNode := sessiondata.InternalExecutorOverride{User: security.NodeUser}
+	_, err := r.sqlExecutor.ExecEx(ctx, "add-table-statistics-expression-col", nil, asNode, addColStmt)
+	return err
+}
+
 func createTenantsTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, systemschema.TenantsTable)
 }
@@ -978,3 +978,66 @@ func TestVersionAlterSystemJobsAddSqllivenessColumnsAddNewSystemSqllivenessTable
 		mt.kvDB, keys.SystemSQLCodec, "system", "jobs")
 	require.Equal(t, newJobsTable, newJobsTableAgain)
 }
+
+func TestAlterSystemStmtDiagReqs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	// oldStmtDiagReqsTableSchema is the system.statement_diagnostics_requests
+	// definition prior to 21.1.
+	oldStmtDiagReqsTableSchema := `
+CREATE TABLE system.statement_diagnostics_requests(
+	id INT8 DEFAULT unique_rowid() PRIMARY KEY NOT NULL,
+	completed BOOL NOT NULL DEFAULT FALSE,
+	statement_fingerprint STRING NOT NULL,
+	statement_diagnostics_id INT8,
+	requested_at TIMESTAMPTZ NOT NULL,
+	INDEX completed_idx (completed, id) STORING (statement_fingerprint),
+
+	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at)
+);`
+	oldStmtDiagReqsTable, err := sql.CreateTestTableDescriptor(
+		context.Background(),
+		keys.SystemDatabaseID,
+		keys.StatementDiagnosticsRequestsTableID,
+		oldStmtDiagReqsTableSchema,
+		systemschema.StatementDiagnosticsRequestsTable.Privileges,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 5, len(oldStmtDiagReqsTable.Columns))
+
+	stmtDiagReqsTable := systemschema.StatementDiagnosticsRequestsTable
+	systemschema.StatementDiagnosticsRequestsTable = tabledesc.NewImmutable(*oldStmtDiagReqsTable.TableDesc())
+	defer func() {
+		systemschema.StatementDiagnosticsRequestsTable = stmtDiagReqsTable
+	}()
+
+	mt := makeMigrationTest(ctx, t)
+	defer mt.close(ctx)
+
+	migration := mt.pop(t, "add conditional diagnostics columns to system.statement_diagnostics_requests")
+	mt.start(t, base.TestServerArgs{})
+
+	// Run the migration and verify that the columns and the new polling index
+	// were added, and that the old index was dropped.
+	require.NoError(t, mt.runMigration(ctx, migration))
+
+	newTable := catalogkv.TestingGetTableDescriptor(
+		mt.kvDB, keys.SystemSQLCodec, "system", "statement_diagnostics_requests")
+	require.Equal(t, 8, len(newTable.Columns))
+	require.Equal(t, "min_execution_latency", newTable.Columns[5].Name)
+	require.Equal(t, "expires_at", newTable.Columns[6].Name)
+	require.Equal(t, "sampling_probability", newTable.Columns[7].Name)
+	require.Equal(t, 1, len(newTable.Families))
+	require.Equal(t, 1, len(newTable.Indexes))
+	require.Equal(t, "completed_idx_v2", newTable.Indexes[0].Name)
+	require.Equal(t,
+		[]string{"statement_fingerprint", "min_execution_latency", "expires_at", "sampling_probability"},
+		newTable.Indexes[0].StoreColumnNames)
+
+	// Run the migration again -- it should be a no-op.
+	require.NoError(t, mt.runMigration(ctx, migration))
+	newTableAgain := catalogkv.TestingGetTableDescriptor(
+		mt.kvDB, keys.SystemSQLCodec, "system", "statement_diagnostics_requests")
+	require.True(t, proto.Equal(newTable.TableDesc(), newTableAgain.TableDesc()))
+}
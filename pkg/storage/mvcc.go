@@ -2724,7 +2724,216 @@ func MVCCResolveWriteIntentUsingIter(
 	if len(intent.EndKey) > 0 {
 		return false, errors.Errorf("can't resolve range intent as point intent")
 	}
-	return mvccResolveWriteIntent(ctx, rw, iterAndBuf.iter, ms, intent, iterAndBuf.buf)
+	released, err := mvccReleaseLock(rw, iterAndBuf.iter, ms, intent, iterAndBuf.buf)
+	if err != nil {
+		return false, err
+	}
+	ok, err := mvccResolveWriteIntent(ctx, rw, iterAndBuf.iter, ms, intent, iterAndBuf.buf)
+	return ok || released, err
+}
+
+// MVCCAcquireLock acquires a replicated, exclusive lock on the key on behalf
+// of the transaction. Unlike an intent, the lock does not carry a provisional
+// value and is not stored in the key's MVCC history. Instead, it is stored in
+// the lock table keyspace, where it is invisible to non-locking readers but
+// causes writers and locking readers from other transactions to return a
+// WriteIntentError. The lock is released when the transaction's locks are
+// resolved, along with its intents.
+func MVCCAcquireLock(
+	ctx context.Context,
+	rw ReadWriter,
+	txn *roachpb.Transaction,
+	ms *enginepb.MVCCStats,
+	key roachpb.Key,
+) error {
+	if len(key) == 0 {
+		return emptyKeyError()
+	}
+	if txn == nil {
+		return errors.AssertionFailedf("cannot acquire a lock on %q outside of a transaction", key)
+	}
+	if keys.IsLocal(key) {
+		return errors.AssertionFailedf("cannot acquire a replicated lock on local key %q", key)
+	}
+
+	iter := rw.NewIterator(IterOptions{Prefix: true})
+	defer iter.Close()
+	buf := newPutBuffer()
+	defer buf.release()
+
+	lockKey := MakeMVCCMetadataKey(keys.LockTableSingleKey(key))
+	ok, origMetaKeySize, origMetaValSize, err := mvccGetMetadata(iter, lockKey, &buf.meta)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if buf.meta.Txn.ID != txn.ID {
+			return &roachpb.WriteIntentError{Intents: []roachpb.Intent{
+				roachpb.MakeIntent(buf.meta.Txn, key),
+			}}
+		}
+		if txn.Epoch < buf.meta.Txn.Epoch {
+			return errors.Errorf("lock acquisition with epoch %d came after lock acquisition with epoch %d in txn %s",
+				txn.Epoch, buf.meta.Txn.Epoch, txn.ID)
+		}
+		if txn.Epoch == buf.meta.Txn.Epoch && buf.meta.Txn.Sequence <= txn.Sequence {
+			// The transaction already holds the lock from an earlier sequence
+			// number in its current epoch, which is the stronger claim if the
+			// transaction later rolls back to a savepoint.
+			return nil
+		}
+	}
+
+	buf.newMeta = enginepb.MVCCMetadata{
+		Txn:       &txn.TxnMeta,
+		Timestamp: hlc.LegacyTimestamp(txn.WriteTimestamp),
+	}
+	metaKeySize, metaValSize, err := buf.putMeta(rw, lockKey, &buf.newMeta)
+	if err != nil {
+		return err
+	}
+	if ms != nil {
+		updateStatsForInline(ms, lockKey.Key, origMetaKeySize, origMetaValSize, metaKeySize, metaValSize)
+	}
+	return nil
+}
+
+// MVCCCheckForConflictingLocks scans the lock table for replicated locks on
+// keys in the span [key, endKey) that are held by transactions other than the
+// provided one. If any are found, a WriteIntentError containing all of them is
+// returned. Replicated locks are not visible to MVCC scans, so locking readers
+// must check for them explicitly.
+func MVCCCheckForConflictingLocks(
+	ctx context.Context, reader Reader, key, endKey roachpb.Key, txn *roachpb.Transaction,
+) error {
+	iter := reader.NewIterator(IterOptions{UpperBound: keys.LockTableSingleKey(endKey)})
+	defer iter.Close()
+
+	var meta enginepb.MVCCMetadata
+	var intents []roachpb.Intent
+	for iter.SeekGE(MakeMVCCMetadataKey(keys.LockTableSingleKey(key))); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		if err := iter.ValueProto(&meta); err != nil {
+			return err
+		}
+		if txn != nil && meta.Txn.ID == txn.ID {
+			continue
+		}
+		lockedKey, err := keys.DecodeLockTableSingleKey(iter.Key().Key)
+		if err != nil {
+			return err
+		}
+		intents = append(intents, roachpb.MakeIntent(meta.Txn, lockedKey))
+	}
+	if len(intents) > 0 {
+		return &roachpb.WriteIntentError{Intents: intents}
+	}
+	return nil
+}
+
+// MVCCCheckForConflictingLock returns a WriteIntentError if a replicated lock
+// is held on the key by a transaction other than the provided one. Replicated
+// locks are not stored in the key's MVCC history, so writers must check for
+// them explicitly.
+func MVCCCheckForConflictingLock(
+	ctx context.Context, reader Reader, key roachpb.Key, txn *roachpb.Transaction,
+) error {
+	iter := reader.NewIterator(IterOptions{Prefix: true})
+	defer iter.Close()
+
+	var meta enginepb.MVCCMetadata
+	lockKey := MakeMVCCMetadataKey(keys.LockTableSingleKey(key))
+	ok, _, _, err := mvccGetMetadata(iter, lockKey, &meta)
+	if err != nil || !ok {
+		return err
+	}
+	if txn != nil && meta.Txn.ID == txn.ID {
+		return nil
+	}
+	return &roachpb.WriteIntentError{Intents: []roachpb.Intent{
+		roachpb.MakeIntent(meta.Txn, key),
+	}}
+}
+
+// lockReleasedByUpdate returns whether the update releases the replicated
+// lock described by the provided metadata. The lock is released when its
+// transaction is finalized, when the transaction has moved on to a later
+// epoch, or when the sequence number that acquired it has been rolled back.
+// Otherwise, the lock is retained: unlike an intent, it has no timestamp that
+// needs to be moved forward.
+func lockReleasedByUpdate(meta *enginepb.MVCCMetadata, update roachpb.LockUpdate) bool {
+	return update.Status.IsFinalized() ||
+		meta.Txn.Epoch < update.Txn.Epoch ||
+		enginepb.TxnSeqIsIgnored(meta.Txn.Sequence, update.IgnoredSeqNums)
+}
+
+// mvccReleaseLock releases the replicated lock held on the update's key by
+// the update's transaction, if the update permits. Returns whether a lock was
+// released.
+func mvccReleaseLock(
+	rw ReadWriter, iter Iterator, ms *enginepb.MVCCStats, update roachpb.LockUpdate, buf *putBuffer,
+) (bool, error) {
+	lockKey := MakeMVCCMetadataKey(keys.LockTableSingleKey(update.Key))
+	ok, origMetaKeySize, origMetaValSize, err := mvccGetMetadata(iter, lockKey, &buf.meta)
+	if err != nil || !ok {
+		return false, err
+	}
+	if buf.meta.Txn.ID != update.Txn.ID || !lockReleasedByUpdate(&buf.meta, update) {
+		return false, nil
+	}
+	if err := rw.Clear(lockKey); err != nil {
+		return false, err
+	}
+	if ms != nil {
+		updateStatsForInline(ms, lockKey.Key, origMetaKeySize, origMetaValSize, 0, 0)
+	}
+	return true, nil
+}
+
+// mvccReleaseLocksInRange releases the replicated locks held on keys in the
+// update's span by the update's transaction, if the update permits. At most
+// max locks are released, unless max is zero. Returns the number of locks
+// released.
+//
+// The lock table is scanned using iter, which must not be bounded below the
+// end of the update's span of the lock table.
+func mvccReleaseLocksInRange(
+	rw ReadWriter, iter Iterator, ms *enginepb.MVCCStats, update roachpb.LockUpdate, max int64,
+) (int64, error) {
+	endKey := MakeMVCCMetadataKey(keys.LockTableSingleKey(update.EndKey))
+
+	var meta enginepb.MVCCMetadata
+	num := int64(0)
+	for iter.SeekGE(MakeMVCCMetadataKey(keys.LockTableSingleKey(update.Key))); ; iter.Next() {
+		if max > 0 && num == max {
+			break
+		}
+		if ok, err := iter.Valid(); err != nil {
+			return 0, err
+		} else if !ok || !iter.UnsafeKey().Less(endKey) {
+			break
+		}
+		if err := iter.ValueProto(&meta); err != nil {
+			return 0, err
+		}
+		if meta.Txn.ID != update.Txn.ID || !lockReleasedByUpdate(&meta, update) {
+			continue
+		}
+		lockKey := iter.Key()
+		if err := rw.Clear(lockKey); err != nil {
+			return 0, err
+		}
+		if ms != nil {
+			updateStatsForInline(ms, lockKey.Key, int64(lockKey.EncodedSize()),
+				int64(len(iter.UnsafeValue())), 0, 0)
+		}
+		num++
+	}
+	return num, nil
 }
 
 // unsafeNextVersion positions the iterator at the successor to latestKey. If this value
@@ -3127,12 +3336,24 @@ func MVCCResolveWriteIntentRangeUsingIter(
 		return 0, &resumeSpan, nil
 	}
 
+	// Replicated locks live in the lock table rather than in the intent span,
+	// so they are released separately, before the intents. They are only
+	// acquired on global keys, so their span of the lock table sorts before the
+	// intent span and is below the upper bound of iterAndBuf's iterator, which
+	// can't be used alongside a second iterator on a batch. They count against
+	// the max keys limit: if it is reached, the entire span is returned as the
+	// resume span, and the locks that were released are not found again when
+	// it is resumed.
+	num, err := mvccReleaseLocksInRange(rw, iterAndBuf.iter, ms, intent, max)
+	if err != nil {
+		return 0, nil, err
+	}
+
 	encKey := MakeMVCCMetadataKey(intent.Key)
 	encEndKey := MakeMVCCMetadataKey(intent.EndKey)
 	nextKey := encKey
 
 	var keyBuf []byte
-	num := int64(0)
 	intent.EndKey = nil
 
 	for {
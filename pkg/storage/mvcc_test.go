@@ -4249,6 +4249,160 @@ func TestMVCCResolveTxnRangeResume(t *testing.T) {
 	}
 }
 
+// TestMVCCReplicatedLocks verifies that replicated locks conflict with writers
+// and locking readers from other transactions, are invisible to non-locking
+// readers, and are released when the holder's locks are resolved.
+func TestMVCCReplicatedLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			var ms enginepb.MVCCStats
+			require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, &ms, testKey1))
+			require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, &ms, testKey2))
+			// Reacquiring a held lock is a no-op.
+			require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, &ms, testKey1))
+
+			// The lock is not visible to non-locking readers.
+			value, intent, err := MVCCGet(ctx, engine, testKey1, hlc.Timestamp{WallTime: 10}, MVCCGetOptions{})
+			require.NoError(t, err)
+			require.Nil(t, value)
+			require.Nil(t, intent)
+
+			// Other transactions cannot acquire the lock, write to the key, or
+			// perform locking reads over it.
+			var wiErr *roachpb.WriteIntentError
+			err = MVCCAcquireLock(ctx, engine, txn2, &ms, testKey1)
+			require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+			err = MVCCCheckForConflictingLock(ctx, engine, testKey1, txn2)
+			require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+			require.Len(t, wiErr.Intents, 1)
+			require.Equal(t, testKey1, wiErr.Intents[0].Key)
+			err = MVCCCheckForConflictingLocks(ctx, engine, testKey1, testKey3, txn2)
+			require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+			require.Len(t, wiErr.Intents, 2)
+			require.Equal(t, testKey1, wiErr.Intents[0].Key)
+			require.Equal(t, testKey2, wiErr.Intents[1].Key)
+
+			// The lock holder is not blocked by its own locks.
+			require.NoError(t, MVCCCheckForConflictingLocks(ctx, engine, testKey1, testKey3, txn1))
+			require.NoError(t, MVCCCheckForConflictingLock(ctx, engine, testKey1, txn1))
+			require.NoError(t, MVCCPut(ctx, engine, &ms, testKey1, txn1.ReadTimestamp, value3, txn1))
+
+			// Resolving the point intent releases the lock on that key.
+			ok, err := MVCCResolveWriteIntent(ctx, engine, &ms,
+				roachpb.MakeLockUpdate(txn1Commit, roachpb.Span{Key: testKey1}))
+			require.NoError(t, err)
+			require.True(t, ok)
+			err = MVCCCheckForConflictingLocks(ctx, engine, testKey1, testKey3, txn2)
+			require.True(t, errors.As(err, &wiErr), "unexpected error: %v", err)
+			require.Len(t, wiErr.Intents, 1)
+			require.Equal(t, testKey2, wiErr.Intents[0].Key)
+
+			// Resolving a span releases the remaining lock.
+			_, _, err = MVCCResolveWriteIntentRange(ctx, engine, &ms,
+				roachpb.MakeLockUpdate(txn1Commit, roachpb.Span{Key: testKey1, EndKey: testKey3}),
+				math.MaxInt64)
+			require.NoError(t, err)
+			require.NoError(t, MVCCCheckForConflictingLocks(ctx, engine, testKey1, testKey3, txn2))
+			require.NoError(t, MVCCAcquireLock(ctx, engine, txn2, &ms, testKey2))
+
+			// The lock table contributes to the range's stats.
+			iter := engine.NewIterator(IterOptions{UpperBound: roachpb.KeyMax})
+			defer iter.Close()
+			expMS, err := ComputeStatsGo(iter, roachpb.KeyMin, roachpb.KeyMax, ms.LastUpdateNanos)
+			require.NoError(t, err)
+			require.Equal(t, expMS, ms)
+		})
+	}
+}
+
+// TestMVCCReplicatedLockRelease verifies that replicated locks are retained
+// across pending lock updates and released when the holding transaction moves
+// to a later epoch.
+func TestMVCCReplicatedLockRelease(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, nil, testKey1))
+
+			// A pending update in the same epoch does not release the lock.
+			ok, err := MVCCResolveWriteIntent(ctx, engine, nil,
+				roachpb.MakeLockUpdate(txn1, roachpb.Span{Key: testKey1}))
+			require.NoError(t, err)
+			require.False(t, ok)
+			require.Error(t, MVCCCheckForConflictingLocks(ctx, engine, testKey1, testKey2, txn2))
+
+			// An update from a later epoch does.
+			ok, err = MVCCResolveWriteIntent(ctx, engine, nil,
+				roachpb.MakeLockUpdate(txn1e2, roachpb.Span{Key: testKey1}))
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.NoError(t, MVCCCheckForConflictingLocks(ctx, engine, testKey1, testKey2, txn2))
+		})
+	}
+}
+
+// TestMVCCReplicatedLocksResolveRangeResume verifies that the replicated locks
+// released by a ranged intent resolution count against its max keys limit.
+func TestMVCCReplicatedLocksResolveRangeResume(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	for _, engineImpl := range mvccEngineImpls {
+		t.Run(engineImpl.name, func(t *testing.T) {
+			engine := engineImpl.create()
+			defer engine.Close()
+
+			// Acquire 10 locks and write 5 intents from txn1.
+			for i := 0; i < 15; i++ {
+				key := roachpb.Key(fmt.Sprintf("%02d", i))
+				if i%3 == 2 {
+					require.NoError(t, MVCCPut(ctx, engine, nil, key, txn1.ReadTimestamp, value1, txn1))
+				} else {
+					require.NoError(t, MVCCAcquireLock(ctx, engine, txn1, nil, key))
+				}
+			}
+
+			// Resolve through a batch, which only hands out one iterator at a time.
+			batch := engine.NewBatch()
+			defer batch.Close()
+			span := roachpb.Span{Key: roachpb.Key("00"), EndKey: roachpb.Key("15")}
+			var resolved []int64
+			for resumeSpan := &span; resumeSpan != nil; {
+				var num int64
+				var err error
+				num, resumeSpan, err = MVCCResolveWriteIntentRange(ctx, batch, nil,
+					roachpb.MakeLockUpdate(txn1Commit, *resumeSpan), 4)
+				require.NoError(t, err)
+				resolved = append(resolved, num)
+			}
+			require.NoError(t, batch.Commit(false /* sync */))
+			// The locks are released first, 4 at a time, and the intents follow.
+			require.Equal(t, []int64{4, 4, 4, 3}, resolved)
+			require.NoError(t, MVCCCheckForConflictingLocks(ctx, engine, span.Key, span.EndKey, txn2))
+			for i := 2; i < 15; i += 3 {
+				key := roachpb.Key(fmt.Sprintf("%02d", i))
+				_, intent, err := MVCCGet(ctx, engine, key, hlc.Timestamp{WallTime: 10}, MVCCGetOptions{})
+				require.NoError(t, err)
+				require.Nil(t, intent)
+			}
+		})
+	}
+}
+
 func TestValidSplitKeys(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer log.Scope(t).Close(t)
@@ -30,6 +30,95 @@ package catalog
 //		(chartDefaultsPerMetricType).
 
 var charts = []sectionDescription{
+	{
+		Organization: [][]string{{Process, "Admission", "KV"}},
+		Charts: []chartDescription{
+			{
+				Title: "Requests",
+				Metrics: []string{
+					"admission.requested.kv",
+					"admission.admitted.kv",
+					"admission.errored.kv",
+				},
+			},
+			{
+				Title: "Wait Durations",
+				Metrics: []string{
+					"admission.wait_durations.kv",
+				},
+			},
+			{
+				Title: "Wait Queue Length",
+				Metrics: []string{
+					"admission.wait_queue_length.kv",
+				},
+			},
+			{
+				Title: "Slots",
+				Metrics: []string{
+					"admission.granter.total_slots.kv",
+					"admission.granter.used_slots.kv",
+				},
+			},
+		},
+	},
+	{
+		Organization: [][]string{{Process, "Admission", "SQL Flow"}},
+		Charts: []chartDescription{
+			{
+				Title: "Requests",
+				Metrics: []string{
+					"admission.requested.sql-flow",
+					"admission.admitted.sql-flow",
+					"admission.errored.sql-flow",
+				},
+			},
+			{
+				Title: "Wait Durations",
+				Metrics: []string{
+					"admission.wait_durations.sql-flow",
+				},
+			},
+			{
+				Title: "Wait Queue Length",
+				Metrics: []string{
+					"admission.wait_queue_length.sql-flow",
+				},
+			},
+			{
+				Title: "Slots",
+				Metrics: []string{
+					"admission.granter.total_slots.sql-flow",
+					"admission.granter.used_slots.sql-flow",
+				},
+			},
+		},
+	},
+	{
+		Organization: [][]string{{Process, "Admission", "KV Stores"}},
+		Charts: []chartDescription{
+			{
+				Title: "Requests",
+				Metrics: []string{
+					"admission.requested.kv-stores",
+					"admission.admitted.kv-stores",
+					"admission.errored.kv-stores",
+				},
+			},
+			{
+				Title: "Wait Durations",
+				Metrics: []string{
+					"admission.wait_durations.kv-stores",
+				},
+			},
+			{
+				Title: "Wait Queue Length",
+				Metrics: []string{
+					"admission.wait_queue_length.kv-stores",
+				},
+			},
+		},
+	},
 	{
 		Organization: [][]string{{Process, "Build Info"}},
 		Charts: []chartDescription{
@@ -0,0 +1,397 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ts
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// PromQueryRangePath is the path of the endpoint answering range queries
+// like the HTTP API of Prometheus, for consumption by tools like Grafana.
+const PromQueryRangePath = "/api/v1/query_range"
+
+// promMaxPoints is the maximum number of points per series returned by a
+// range query. This is the limit enforced by Prometheus.
+const promMaxPoints = 11000
+
+// The prefixes of the names of the time series recorded for node and store
+// metrics, and the labels identifying the source of the respective series.
+const (
+	promNodeSeriesPrefix  = "cr.node."
+	promStoreSeriesPrefix = "cr.store."
+	promNodeLabel         = "node_id"
+	promStoreLabel        = "store"
+)
+
+// MetricNameResolver maps the name of a metric in a PromQL query to the name
+// of the time series storing it. For example, "sql_select_count" resolves to
+// "cr.node.sql.select.count".
+type MetricNameResolver func(name string) (tsName string, ok bool)
+
+// NewMetricNameResolver creates a MetricNameResolver for the time series
+// names returned by the supplied function. The metric names are those used by
+// the Prometheus endpoint of the nodes, i.e. the series names without the
+// cr.node. or cr.store. prefix, with invalid characters replaced by
+// underscores.
+//
+// The names are cached, and refreshed at most once a minute when a metric is
+// not found.
+func NewMetricNameResolver(seriesNames func() []string) MetricNameResolver {
+	var mu struct {
+		syncutil.Mutex
+		names       map[string]string
+		lastRefresh time.Time
+	}
+	return func(name string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if tsName, ok := mu.names[name]; ok {
+			return tsName, true
+		}
+		if now := timeutil.Now(); mu.names == nil || now.Sub(mu.lastRefresh) > time.Minute {
+			mu.lastRefresh = now
+			mu.names = make(map[string]string)
+			series := seriesNames()
+			// Sort for determinism in the unlikely case of a collision between a
+			// node and a store metric; the node metric wins.
+			sort.Strings(series)
+			for _, s := range series {
+				var metricName string
+				switch {
+				case strings.HasPrefix(s, promNodeSeriesPrefix):
+					metricName = strings.TrimPrefix(s, promNodeSeriesPrefix)
+				case strings.HasPrefix(s, promStoreSeriesPrefix):
+					metricName = strings.TrimPrefix(s, promStoreSeriesPrefix)
+				default:
+					continue
+				}
+				if _, ok := mu.names[metric.ExportedName(metricName)]; !ok {
+					mu.names[metric.ExportedName(metricName)] = s
+				}
+			}
+		}
+		tsName, ok := mu.names[name]
+		return tsName, ok
+	}
+}
+
+// promSourceLabel returns the label identifying the source of the series of
+// the given time series.
+func promSourceLabel(tsName string) string {
+	if strings.HasPrefix(tsName, promStoreSeriesPrefix) {
+		return promStoreLabel
+	}
+	return promNodeLabel
+}
+
+// PrometheusAPIHandler returns an http.Handler serving PromQueryRangePath.
+// The handler evaluates a subset of PromQL over the time series data; see
+// parsePromQL for the supported expressions.
+func (s *Server) PrometheusAPIHandler(resolve MetricNameResolver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := s.AnnotateCtx(r.Context())
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			writePromError(ctx, w, http.StatusMethodNotAllowed, "bad_data",
+				errors.Newf("unsupported method %s", r.Method))
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			writePromError(ctx, w, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		q, err := parsePromQL(r.Form.Get("query"))
+		if err != nil {
+			writePromError(ctx, w, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		start, err := parsePromTime(r.Form.Get("start"))
+		if err != nil {
+			writePromError(ctx, w, http.StatusBadRequest, "bad_data", errors.Wrap(err, "invalid start"))
+			return
+		}
+		end, err := parsePromTime(r.Form.Get("end"))
+		if err != nil {
+			writePromError(ctx, w, http.StatusBadRequest, "bad_data", errors.Wrap(err, "invalid end"))
+			return
+		}
+		step, err := parsePromStep(r.Form.Get("step"))
+		if err != nil {
+			writePromError(ctx, w, http.StatusBadRequest, "bad_data", errors.Wrap(err, "invalid step"))
+			return
+		}
+		if end < start {
+			writePromError(ctx, w, http.StatusBadRequest, "bad_data",
+				errors.New("end timestamp must not be before start time"))
+			return
+		}
+		if (end-start)/step > promMaxPoints {
+			writePromError(ctx, w, http.StatusBadRequest, "bad_data", errors.Newf(
+				"exceeded maximum resolution of %d points per timeseries; try decreasing the query resolution (?step=XX)",
+				promMaxPoints))
+			return
+		}
+
+		result, err := s.promQueryRange(ctx, q, resolve, start, end, step)
+		if err != nil {
+			writePromError(ctx, w, http.StatusUnprocessableEntity, "execution", err)
+			return
+		}
+		writePromResponse(ctx, w, http.StatusOK, &promResponse{
+			Status: "success",
+			Data:   &promData{ResultType: "matrix", Result: result},
+		})
+	})
+}
+
+// promQueryRange evaluates a parsed PromQL expression over the given time
+// span, translating it onto time series queries.
+func (s *Server) promQueryRange(
+	ctx context.Context, q *promQuery, resolve MetricNameResolver, start, end, step int64,
+) ([]promSeries, error) {
+	result := []promSeries{}
+	// The internal names of the time series can be used directly.
+	tsName := q.metric
+	if !strings.HasPrefix(tsName, promNodeSeriesPrefix) && !strings.HasPrefix(tsName, promStoreSeriesPrefix) {
+		var ok bool
+		if tsName, ok = resolve(q.metric); !ok {
+			// Like Prometheus, return no data for unknown metrics.
+			return result, nil
+		}
+	}
+	sourceLabel := promSourceLabel(tsName)
+
+	newQuery := func(sources []string) tspb.Query {
+		query := tspb.Query{
+			Name:        tsName,
+			Downsampler: tspb.TimeSeriesQueryAggregator_AVG.Enum(),
+			Sources:     sources,
+		}
+		if q.aggregator != nil {
+			query.SourceAggregator = q.aggregator
+		}
+		if q.rate {
+			query.Derivative = tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE.Enum()
+		}
+		return query
+	}
+	run := func(queries []tspb.Query) (*tspb.TimeSeriesQueryResponse, error) {
+		return s.Query(ctx, &tspb.TimeSeriesQueryRequest{
+			StartNanos:  start,
+			EndNanos:    end,
+			Queries:     queries,
+			SampleNanos: step,
+		})
+	}
+
+	// Series are returned per source unless they are aggregated across
+	// sources. The sources only need to be listed when they are returned
+	// separately, or when the selector filters them.
+	perSource := q.aggregator == nil
+	for _, l := range q.grouping {
+		if l == sourceLabel {
+			perSource = true
+		}
+	}
+	var sources []string
+	if perSource || len(q.matchers) > 0 {
+		resp, err := run([]tspb.Query{newQuery(nil /* sources */)})
+		if err != nil {
+			return nil, err
+		}
+		for _, source := range resp.Results[0].Sources {
+			if promMatchSource(q.matchers, sourceLabel, source) {
+				sources = append(sources, source)
+			}
+		}
+		if len(sources) == 0 {
+			return result, nil
+		}
+		sort.Slice(sources, func(i, j int) bool { return promLessSource(sources[i], sources[j]) })
+	}
+
+	var queries []tspb.Query
+	if perSource {
+		for _, source := range sources {
+			queries = append(queries, newQuery([]string{source}))
+		}
+	} else {
+		queries = []tspb.Query{newQuery(sources)}
+	}
+	resp, err := run(queries)
+	if err != nil {
+		return nil, err
+	}
+	for i, res := range resp.Results {
+		if len(res.Datapoints) == 0 {
+			continue
+		}
+		// Like in Prometheus, the functions and aggregations drop the metric
+		// name.
+		labels := map[string]string{}
+		if !q.rate && q.aggregator == nil {
+			labels[promMetricNameLabel] = q.metric
+		}
+		if perSource {
+			labels[sourceLabel] = sources[i]
+		}
+		series := promSeries{Metric: labels, Values: make([]promSample, len(res.Datapoints))}
+		for j, dp := range res.Datapoints {
+			series.Values[j] = promSample{timestampNanos: dp.TimestampNanos, value: dp.Value}
+		}
+		result = append(result, series)
+	}
+	return result, nil
+}
+
+// promMatchSource returns whether the series of the given source is selected
+// by the matchers. The only label of a series besides its name is the label
+// identifying its source.
+func promMatchSource(matchers []promLabelMatcher, sourceLabel, source string) bool {
+	for i := range matchers {
+		var value string
+		if matchers[i].label == sourceLabel {
+			value = source
+		}
+		if !matchers[i].matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// promLessSource orders sources numerically when they are node or store IDs.
+func promLessSource(a, b string) bool {
+	ai, aErr := strconv.Atoi(a)
+	bi, bErr := strconv.Atoi(b)
+	if aErr == nil && bErr == nil {
+		return ai < bi
+	}
+	return a < b
+}
+
+// parsePromTime parses a timestamp, specified either as a Unix timestamp in
+// seconds or in the RFC 3339 format, into nanoseconds.
+func parsePromTime(s string) (int64, error) {
+	if s == "" {
+		return 0, errors.New("timestamp is required")
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return int64(math.Round(f * 1e9)), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, errors.Newf("cannot parse %q to a valid timestamp", s)
+	}
+	return t.UnixNano(), nil
+}
+
+// parsePromStep parses the resolution of a range query, specified either as a
+// number of seconds or as a duration, into nanoseconds. The step is rounded up
+// to a multiple of the resolution of the stored data.
+func parsePromStep(s string) (int64, error) {
+	if s == "" {
+		return 0, errors.New("step is required")
+	}
+	var step time.Duration
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		step = time.Duration(f * float64(time.Second))
+	} else if step, err = parsePromDuration(s); err != nil {
+		return 0, err
+	}
+	if step <= 0 {
+		return 0, errors.New("zero or negative query resolution step widths are not accepted")
+	}
+	resolution := Resolution10s.SampleDuration()
+	nanos := step.Nanoseconds()
+	if rem := nanos % resolution; rem != 0 {
+		nanos += resolution - rem
+	}
+	return nanos, nil
+}
+
+// promResponse is the envelope of the responses of the Prometheus HTTP API.
+type promResponse struct {
+	Status    string    `json:"status"`
+	Data      *promData `json:"data,omitempty"`
+	ErrorType string    `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type promData struct {
+	ResultType string       `json:"resultType"`
+	Result     []promSeries `json:"result"`
+}
+
+// promSeries is a series of a range query result.
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []promSample      `json:"values"`
+}
+
+// promSample is a point of a promSeries. It is encoded as a
+// [<unix seconds>, "<value>"] pair.
+type promSample struct {
+	timestampNanos int64
+	value          float64
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s promSample) MarshalJSON() ([]byte, error) {
+	var value string
+	switch {
+	case math.IsNaN(s.value):
+		value = "NaN"
+	case math.IsInf(s.value, 1):
+		value = "+Inf"
+	case math.IsInf(s.value, -1):
+		value = "-Inf"
+	default:
+		value = strconv.FormatFloat(s.value, 'f', -1, 64)
+	}
+	ts := strconv.FormatFloat(float64(s.timestampNanos)/1e9, 'f', -1, 64)
+	return []byte(`[` + ts + `,"` + value + `"]`), nil
+}
+
+func writePromError(ctx context.Context, w http.ResponseWriter, code int, typ string, err error) {
+	writePromResponse(ctx, w, code, &promResponse{
+		Status:    "error",
+		ErrorType: typ,
+		Error:     err.Error(),
+	})
+}
+
+func writePromResponse(ctx context.Context, w http.ResponseWriter, code int, resp *promResponse) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		log.Errorf(ctx, "encoding query response: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(b); err != nil {
+		log.Warningf(ctx, "writing query response: %v", err)
+	}
+}
@@ -0,0 +1,531 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ts
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/errors"
+)
+
+// This file implements a parser for the subset of PromQL that can be
+// translated onto time series queries:
+//
+//   <expr>       ::= <selector> | <rate> | <aggregation>
+//   <selector>   ::= [<metric name>] ['{' <matcher>, ... '}']
+//   <matcher>    ::= <label> ('=' | '!=' | '=~' | '!~') <string>
+//   <rate>       ::= ('rate' | 'irate') '(' <selector> '[' <duration> ']' ')'
+//   <aggregation>::= <aggregator> [<grouping>] '(' <selector> | <rate> ')' [<grouping>]
+//   <aggregator> ::= 'sum' | 'avg' | 'max' | 'min'
+//   <grouping>   ::= 'by' '(' <label>, ... ')'
+//
+// The range of rate() is accepted for compatibility but otherwise ignored:
+// rates are computed between consecutive samples, at the resolution of the
+// query step.
+
+// promMetricNameLabel is the label holding the metric name of a series.
+const promMetricNameLabel = "__name__"
+
+// promQuery is a parsed PromQL expression.
+type promQuery struct {
+	// metric is the name of the selected metric, as written in the query.
+	metric string
+	// matchers restrict the selected series. The metric name matcher is not
+	// included.
+	matchers []promLabelMatcher
+	// rate is set if the selected series are wrapped in rate() or irate().
+	rate bool
+	// aggregator is the aggregation applied across series, if any.
+	aggregator *tspb.TimeSeriesQueryAggregator
+	// grouping is the list of labels in the by clause of the aggregation.
+	grouping []string
+}
+
+type promMatchOp string
+
+const (
+	promMatchEqual     promMatchOp = "="
+	promMatchNotEqual  promMatchOp = "!="
+	promMatchRegexp    promMatchOp = "=~"
+	promMatchNotRegexp promMatchOp = "!~"
+)
+
+// promLabelMatcher is a label matcher of a selector.
+type promLabelMatcher struct {
+	label string
+	op    promMatchOp
+	value string
+	// re is the compiled (anchored) regular expression for the regexp
+	// operators.
+	re *regexp.Regexp
+}
+
+// matches returns whether the given label value, which is empty if the label
+// is not present, is selected by the matcher.
+func (m *promLabelMatcher) matches(value string) bool {
+	switch m.op {
+	case promMatchEqual:
+		return value == m.value
+	case promMatchNotEqual:
+		return value != m.value
+	case promMatchRegexp:
+		return m.re.MatchString(value)
+	case promMatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+	panic(errors.AssertionFailedf("unknown match operator %q", m.op))
+}
+
+var promAggregators = map[string]tspb.TimeSeriesQueryAggregator{
+	"sum": tspb.TimeSeriesQueryAggregator_SUM,
+	"avg": tspb.TimeSeriesQueryAggregator_AVG,
+	"max": tspb.TimeSeriesQueryAggregator_MAX,
+	"min": tspb.TimeSeriesQueryAggregator_MIN,
+}
+
+// parsePromQL parses a PromQL expression of the supported subset.
+func parsePromQL(input string) (*promQuery, error) {
+	p := promParser{lexer: promLexer{input: input}}
+	q, err := p.parse()
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %q", input)
+	}
+	return q, nil
+}
+
+type promParser struct {
+	lexer promLexer
+	// peeked holds the token returned by peek(), if any.
+	peeked *promToken
+}
+
+func (p *promParser) next() (promToken, error) {
+	if p.peeked != nil {
+		t := *p.peeked
+		p.peeked = nil
+		return t, nil
+	}
+	return p.lexer.next()
+}
+
+func (p *promParser) peek() (promToken, error) {
+	if p.peeked == nil {
+		t, err := p.lexer.next()
+		if err != nil {
+			return promToken{}, err
+		}
+		p.peeked = &t
+	}
+	return *p.peeked, nil
+}
+
+// expect consumes the next token, which must be the given punctuation.
+func (p *promParser) expect(punct string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != promTokPunct || t.val != punct {
+		return errors.Newf("expected %q at position %d, found %s", punct, t.pos, t)
+	}
+	return nil
+}
+
+func (p *promParser) parse() (*promQuery, error) {
+	q, err := p.parseExpr(true /* allowAggregation */)
+	if err != nil {
+		return nil, err
+	}
+	if t, err := p.next(); err != nil {
+		return nil, err
+	} else if t.kind != promTokEOF {
+		return nil, errors.Newf("unexpected %s at position %d", t, t.pos)
+	}
+	return q, nil
+}
+
+func (p *promParser) parseExpr(allowAggregation bool) (*promQuery, error) {
+	t, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind == promTokIdent {
+		if agg, ok := promAggregators[t.val]; ok {
+			if !allowAggregation {
+				return nil, errors.Newf("nested aggregations are not supported")
+			}
+			return p.parseAggregation(agg)
+		}
+		switch t.val {
+		case "rate", "irate":
+			return p.parseRate()
+		case "count", "stddev", "stdvar", "topk", "bottomk", "quantile", "count_values", "group",
+			"increase", "delta", "idelta", "deriv", "avg_over_time", "max_over_time",
+			"min_over_time", "sum_over_time", "histogram_quantile":
+			return nil, errors.WithHint(
+				errors.Newf("unsupported function: %s", t.val),
+				"Supported functions: rate, irate, sum, avg, max, min.")
+		}
+	}
+	return p.parseSelector()
+}
+
+func (p *promParser) parseAggregation(agg tspb.TimeSeriesQueryAggregator) (*promQuery, error) {
+	if _, err := p.next(); err != nil {
+		return nil, err
+	}
+	grouping, err := p.parseGrouping()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	q, err := p.parseExpr(false /* allowAggregation */)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if grouping == nil {
+		if grouping, err = p.parseGrouping(); err != nil {
+			return nil, err
+		}
+	}
+	q.aggregator = agg.Enum()
+	q.grouping = grouping
+	return q, nil
+}
+
+// parseGrouping parses an optional by clause. A nil slice is returned if there
+// is no by clause.
+func (p *promParser) parseGrouping() ([]string, error) {
+	t, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind != promTokIdent {
+		return nil, nil
+	}
+	switch t.val {
+	case "by":
+	case "without":
+		return nil, errors.New("aggregation without clauses are not supported")
+	default:
+		return nil, nil
+	}
+	if _, err := p.next(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	grouping := []string{}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == promTokPunct && t.val == ")" {
+			return grouping, nil
+		}
+		if len(grouping) > 0 {
+			if t.kind != promTokPunct || t.val != "," {
+				return nil, errors.Newf("expected \",\" or \")\" at position %d, found %s", t.pos, t)
+			}
+			if t, err = p.next(); err != nil {
+				return nil, err
+			}
+		}
+		if t.kind != promTokIdent {
+			return nil, errors.Newf("expected label name at position %d, found %s", t.pos, t)
+		}
+		grouping = append(grouping, t.val)
+	}
+}
+
+func (p *promParser) parseRate() (*promQuery, error) {
+	if _, err := p.next(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	q, err := p.parseSelector()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("["); err != nil {
+		return nil, errors.Wrap(err, "rate() requires a range vector")
+	}
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind != promTokDuration {
+		return nil, errors.Newf("expected duration at position %d, found %s", t.pos, t)
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	q.rate = true
+	return q, nil
+}
+
+func (p *promParser) parseSelector() (*promQuery, error) {
+	q := &promQuery{}
+	t, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind == promTokIdent {
+		q.metric = t.val
+		if _, err := p.next(); err != nil {
+			return nil, err
+		}
+		if t, err = p.peek(); err != nil {
+			return nil, err
+		}
+	}
+	if t.kind == promTokPunct && t.val == "{" {
+		if _, err := p.next(); err != nil {
+			return nil, err
+		}
+		if err := p.parseMatchers(q); err != nil {
+			return nil, err
+		}
+	}
+	if q.metric == "" {
+		return nil, errors.Newf("expected metric name at position %d", t.pos)
+	}
+	return q, nil
+}
+
+// parseMatchers parses the label matchers of a selector, after the opening
+// brace.
+func (p *promParser) parseMatchers(q *promQuery) error {
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind == promTokPunct && t.val == "}" {
+			return nil
+		}
+		if t.kind != promTokIdent {
+			return errors.Newf("expected label name at position %d, found %s", t.pos, t)
+		}
+		m := promLabelMatcher{label: t.val}
+		if t, err = p.next(); err != nil {
+			return err
+		}
+		switch op := promMatchOp(t.val); {
+		case t.kind == promTokPunct &&
+			(op == promMatchEqual || op == promMatchNotEqual || op == promMatchRegexp || op == promMatchNotRegexp):
+			m.op = op
+		default:
+			return errors.Newf("expected label matching operator at position %d, found %s", t.pos, t)
+		}
+		if t, err = p.next(); err != nil {
+			return err
+		}
+		if t.kind != promTokString {
+			return errors.Newf("expected string at position %d, found %s", t.pos, t)
+		}
+		m.value = t.val
+		if m.op == promMatchRegexp || m.op == promMatchNotRegexp {
+			if m.re, err = regexp.Compile("^(?:" + m.value + ")$"); err != nil {
+				return errors.Wrapf(err, "invalid regular expression for label %s", m.label)
+			}
+		}
+		if m.label == promMetricNameLabel {
+			if m.op != promMatchEqual {
+				return errors.New("only equality matchers are supported for the metric name")
+			}
+			if q.metric != "" && q.metric != m.value {
+				return errors.Newf("conflicting metric names %q and %q", q.metric, m.value)
+			}
+			q.metric = m.value
+		} else {
+			q.matchers = append(q.matchers, m)
+		}
+
+		if t, err = p.next(); err != nil {
+			return err
+		}
+		if t.kind == promTokPunct && t.val == "}" {
+			return nil
+		}
+		if t.kind != promTokPunct || t.val != "," {
+			return errors.Newf("expected \",\" or \"}\" at position %d, found %s", t.pos, t)
+		}
+	}
+}
+
+type promTokenKind int
+
+const (
+	promTokEOF promTokenKind = iota
+	promTokIdent
+	promTokString
+	promTokDuration
+	promTokPunct
+)
+
+type promToken struct {
+	kind promTokenKind
+	val  string
+	pos  int
+}
+
+func (t promToken) String() string {
+	if t.kind == promTokEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+// promLexer splits a PromQL expression into tokens.
+type promLexer struct {
+	input string
+	pos   int
+}
+
+func isPromIdentStart(r rune) bool {
+	return r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isPromIdentChar(r rune) bool {
+	return isPromIdentStart(r) || (r >= '0' && r <= '9')
+}
+
+func (l *promLexer) next() (promToken, error) {
+	for l.pos < len(l.input) && unicode.IsSpace(rune(l.input[l.pos])) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return promToken{kind: promTokEOF, pos: start}, nil
+	}
+	c := l.input[l.pos]
+	switch {
+	case isPromIdentStart(rune(c)):
+		for l.pos < len(l.input) && isPromIdentChar(rune(l.input[l.pos])) {
+			l.pos++
+		}
+		return promToken{kind: promTokIdent, val: l.input[start:l.pos], pos: start}, nil
+
+	case c >= '0' && c <= '9':
+		for l.pos < len(l.input) && isPromIdentChar(rune(l.input[l.pos])) {
+			l.pos++
+		}
+		val := l.input[start:l.pos]
+		if _, err := parsePromDuration(val); err != nil {
+			return promToken{}, errors.Wrapf(err, "at position %d", start)
+		}
+		return promToken{kind: promTokDuration, val: val, pos: start}, nil
+
+	case c == '"' || c == '\'' || c == '`':
+		return l.lexString(c)
+
+	case c == '=' || c == '!':
+		l.pos++
+		if l.pos < len(l.input) && (l.input[l.pos] == '=' || l.input[l.pos] == '~') {
+			l.pos++
+		}
+		val := l.input[start:l.pos]
+		if val == "!" || val == "==" {
+			return promToken{}, errors.Newf("unexpected %q at position %d", val, start)
+		}
+		return promToken{kind: promTokPunct, val: val, pos: start}, nil
+
+	case strings.IndexByte("(){}[],", c) >= 0:
+		l.pos++
+		return promToken{kind: promTokPunct, val: string(c), pos: start}, nil
+	}
+	return promToken{}, errors.Newf("unexpected character %q at position %d", c, start)
+}
+
+// lexString lexes a quoted string. Double- and single-quoted strings support
+// the Go escape sequences; backquoted strings are raw.
+func (l *promLexer) lexString(quote byte) (promToken, error) {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.input) && l.input[l.pos] != quote {
+		if l.input[l.pos] == '\\' && quote != '`' {
+			l.pos++
+		}
+		l.pos++
+	}
+	if l.pos >= len(l.input) {
+		return promToken{}, errors.Newf("unterminated string starting at position %d", start)
+	}
+	l.pos++
+	raw := l.input[start:l.pos]
+	var val string
+	switch quote {
+	case '`':
+		val = raw[1 : len(raw)-1]
+	case '\'':
+		// strconv.Unquote only accepts single characters between single quotes.
+		inner := strings.Replace(raw[1:len(raw)-1], `\'`, `'`, -1)
+		inner = strings.Replace(inner, `"`, `\"`, -1)
+		var err error
+		if val, err = strconv.Unquote(`"` + inner + `"`); err != nil {
+			return promToken{}, errors.Wrapf(err, "invalid string at position %d", start)
+		}
+	default:
+		var err error
+		if val, err = strconv.Unquote(raw); err != nil {
+			return promToken{}, errors.Wrapf(err, "invalid string at position %d", start)
+		}
+	}
+	return promToken{kind: promTokString, val: val, pos: start}, nil
+}
+
+var promDurationRE = regexp.MustCompile(`^(([0-9]+)(ms|s|m|h|d|w|y))+$`)
+var promDurationPartRE = regexp.MustCompile(`([0-9]+)(ms|s|m|h|d|w|y)`)
+
+var promDurationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// parsePromDuration parses a duration in the Prometheus format, e.g. "1h30m".
+func parsePromDuration(s string) (time.Duration, error) {
+	if !promDurationRE.MatchString(s) {
+		return 0, errors.Newf("invalid duration: %q", s)
+	}
+	var d time.Duration
+	for _, part := range promDurationPartRE.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.ParseInt(part[1], 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid duration: %q", s)
+		}
+		d += time.Duration(n) * promDurationUnits[part[2]]
+	}
+	return d, nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package ts
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePromQL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sum := tspb.TimeSeriesQueryAggregator_SUM.Enum()
+	max := tspb.TimeSeriesQueryAggregator_MAX.Enum()
+
+	testCases := []struct {
+		input    string
+		expected promQuery
+	}{
+		{`sql_select_count`, promQuery{metric: "sql_select_count"}},
+		{`{__name__="cr.node.sql.select.count"}`, promQuery{metric: "cr.node.sql.select.count"}},
+		{`capacity{store="1"}`, promQuery{
+			metric:   "capacity",
+			matchers: []promLabelMatcher{{label: "store", op: promMatchEqual, value: "1"}},
+		}},
+		{`rate(sql_select_count{node_id!='2',}[5m])`, promQuery{
+			metric:   "sql_select_count",
+			matchers: []promLabelMatcher{{label: "node_id", op: promMatchNotEqual, value: "2"}},
+			rate:     true,
+		}},
+		{`sum(rate(sql_select_count[1m30s]))`, promQuery{
+			metric: "sql_select_count", rate: true, aggregator: sum,
+		}},
+		{`sum by (node_id) (sql_select_count)`, promQuery{
+			metric: "sql_select_count", aggregator: sum, grouping: []string{"node_id"},
+		}},
+		{`max(capacity_used) by (store, node_id)`, promQuery{
+			metric: "capacity_used", aggregator: max, grouping: []string{"store", "node_id"},
+		}},
+		{`avg(sys_rss) by ()`, promQuery{
+			metric: "sys_rss", aggregator: tspb.TimeSeriesQueryAggregator_AVG.Enum(), grouping: []string{},
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := parsePromQL(tc.input)
+			require.NoError(t, err)
+			for i := range q.matchers {
+				q.matchers[i].re = nil
+			}
+			require.Equal(t, tc.expected, *q)
+		})
+	}
+
+	errorCases := []struct {
+		input  string
+		expErr string
+	}{
+		{``, `expected metric name`},
+		{`sum(max(a))`, `nested aggregations are not supported`},
+		{`rate(a)`, `rate() requires a range vector`},
+		{`rate(a[5x])`, `invalid duration`},
+		{`histogram_quantile(0.99, a)`, `unsupported function: histogram_quantile`},
+		{`sum without (store) (a)`, `without clauses are not supported`},
+		{`a{b="c"`, `expected "," or "}"`},
+		{`a{b=~"("}`, `invalid regular expression`},
+		{`a{__name__="b"}`, `conflicting metric names`},
+		{`a{b=="c"}`, `unexpected "=="`},
+		{`a b`, `unexpected "b"`},
+		{`a{b="c}`, `unterminated string`},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := parsePromQL(tc.input)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+func TestPromLabelMatchers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	q, err := parsePromQL(`a{store=~"1|3",store!="3",node_id=""}`)
+	require.NoError(t, err)
+	var selected []string
+	for _, source := range []string{"1", "2", "3", "13"} {
+		if promMatchSource(q.matchers, promStoreLabel, source) {
+			selected = append(selected, source)
+		}
+	}
+	require.Equal(t, []string{"1"}, selected)
+
+	// A matcher on a non-empty value of a missing label selects nothing.
+	q, err = parsePromQL(`a{node_id="1"}`)
+	require.NoError(t, err)
+	require.False(t, promMatchSource(q.matchers, promStoreLabel, "1"))
+}
+
+func TestParsePromParameters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts, err := parsePromTime("1600000000.5")
+	require.NoError(t, err)
+	require.Equal(t, int64(1600000000500000000), ts)
+	ts, err = parsePromTime("2020-09-13T12:26:40Z")
+	require.NoError(t, err)
+	require.Equal(t, int64(1600000000000000000), ts)
+	_, err = parsePromTime("yesterday")
+	require.Error(t, err)
+
+	for _, tc := range []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"10", 10 * time.Second},
+		{"15s", 20 * time.Second},
+		{"1m", time.Minute},
+		{"0.5", 10 * time.Second},
+		{"1h30m", 90 * time.Minute},
+	} {
+		step, err := parsePromStep(tc.input)
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.expected.Nanoseconds(), step, tc.input)
+	}
+	_, err = parsePromStep("-1")
+	require.Error(t, err)
+}
+
+func TestPromSampleJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	b, err := json.Marshal([]promSample{
+		{timestampNanos: 1600000000500000000, value: 1.25},
+		{timestampNanos: 1600000010000000000, value: math.NaN()},
+		{timestampNanos: 1600000020000000000, value: math.Inf(1)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `[[1600000000.5,"1.25"],[1600000010,"NaN"],[1600000020,"+Inf"]]`, string(b))
+}
+
+func TestMetricNameResolver(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	calls := 0
+	resolve := NewMetricNameResolver(func() []string {
+		calls++
+		return []string{
+			"cr.node.sql.select.count",
+			"cr.node.sql.exec.latency-p99",
+			"cr.store.capacity.used",
+			"other.metric",
+		}
+	})
+	for _, tc := range []struct {
+		name, expected string
+	}{
+		{"sql_select_count", "cr.node.sql.select.count"},
+		{"sql_exec_latency_p99", "cr.node.sql.exec.latency-p99"},
+		{"capacity_used", "cr.store.capacity.used"},
+		{"other_metric", ""},
+	} {
+		tsName, ok := resolve(tc.name)
+		require.Equal(t, tc.expected != "", ok, tc.name)
+		require.Equal(t, tc.expected, tsName, tc.name)
+	}
+	// The names are only refreshed after some time.
+	require.Equal(t, 1, calls)
+	require.Equal(t, promStoreLabel, promSourceLabel("cr.store.capacity.used"))
+	require.Equal(t, promNodeLabel, promSourceLabel("cr.node.sql.select.count"))
+}
@@ -12,8 +12,11 @@ package ts_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"reflect"
 	"sort"
 	"strings"
@@ -517,3 +520,117 @@ func populateSeries(seriesCount, sourceCount, valueCount int, tsdb *ts.DB) error
 	}
 	return nil
 }
+
+func TestServerPromQueryRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store: &kvserver.StoreTestingKnobs{
+				DisableTimeSeriesMaintenanceQueue: true,
+			},
+		},
+	})
+	defer s.Stopper().Stop(context.Background())
+	tsrv := s.(*server.TestServer)
+
+	// Populate data directly, under the name of real metrics so that the
+	// Prometheus names can be resolved.
+	datapoints := []tspb.TimeSeriesDatapoint{
+		{TimestampNanos: 400 * 1e9, Value: 100.0},
+		{TimestampNanos: 500 * 1e9, Value: 200.0},
+		{TimestampNanos: 520 * 1e9, Value: 300.0},
+	}
+	if err := tsrv.TsDB().StoreData(context.Background(), ts.Resolution10s, []tspb.TimeSeriesData{
+		{Name: "cr.node.sys.uptime", Source: "1", Datapoints: datapoints},
+		{Name: "cr.node.sys.uptime", Source: "2", Datapoints: datapoints},
+		{Name: "cr.store.capacity", Source: "3", Datapoints: datapoints},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := tsrv.GetAdminAuthenticatedHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	type series struct {
+		Metric map[string]string
+		Values [][2]interface{}
+	}
+	type response struct {
+		Status    string
+		ErrorType string
+		Error     string
+		Data      struct {
+			ResultType string
+			Result     []series
+		}
+	}
+	query := func(q string, expectedCode int) response {
+		t.Helper()
+		params := url.Values{}
+		params.Set("query", q)
+		params.Set("start", "500")
+		params.Set("end", "526")
+		params.Set("step", "10s")
+		resp, err := client.Get(s.AdminURL() + ts.PromQueryRangePath + "?" + params.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != expectedCode {
+			t.Fatalf("%s: expected status %d, got %s", q, expectedCode, resp.Status)
+		}
+		var r response
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	values := func(vals ...string) [][2]interface{} {
+		res := make([][2]interface{}, len(vals))
+		for i, v := range vals {
+			res[i] = [2]interface{}{float64(500 + 10*i), v}
+		}
+		return res
+	}
+
+	testCases := []struct {
+		query    string
+		expected []series
+	}{
+		{`sum(sys_uptime)`, []series{
+			{Metric: map[string]string{}, Values: values("400", "500", "600")},
+		}},
+		{`sys_uptime{node_id="2"}`, []series{
+			{Metric: map[string]string{"__name__": "sys_uptime", "node_id": "2"}, Values: values("200", "250", "300")},
+		}},
+		{`max by (node_id) (sys_uptime)`, []series{
+			{Metric: map[string]string{"node_id": "1"}, Values: values("200", "250", "300")},
+			{Metric: map[string]string{"node_id": "2"}, Values: values("200", "250", "300")},
+		}},
+		{`avg(sys_uptime{node_id=~"1|3"})`, []series{
+			{Metric: map[string]string{}, Values: values("200", "250", "300")},
+		}},
+		{`{__name__="cr.store.capacity"}`, []series{
+			{Metric: map[string]string{"__name__": "cr.store.capacity", "store": "3"}, Values: values("200", "250", "300")},
+		}},
+		{`sys_uptime{node_id="4"}`, []series{}},
+		{`unknown_metric`, []series{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			r := query(tc.query, http.StatusOK)
+			if r.Status != "success" || r.Data.ResultType != "matrix" {
+				t.Fatalf("unexpected response: %+v", r)
+			}
+			if !reflect.DeepEqual(tc.expected, r.Data.Result) {
+				t.Fatalf("expected:\n%+v\ngot:\n%+v", tc.expected, r.Data.Result)
+			}
+		})
+	}
+
+	r := query(`histogram_quantile(0.99, sys_uptime)`, http.StatusBadRequest)
+	if r.Status != "error" || r.ErrorType != "bad_data" || !strings.Contains(r.Error, "unsupported function") {
+		t.Fatalf("unexpected response: %+v", r)
+	}
+}
@@ -69,6 +69,18 @@ export const SET_ZONE_CONFIG = "set_zone_config";
 export const REMOVE_ZONE_CONFIG = "remove_zone_config";
 // Recorded when statistics are collected for a table.
 export const CREATE_STATISTICS = "create_statistics";
+// Recorded when a role is created.
+export const CREATE_ROLE = "create_role";
+// Recorded when a role is dropped.
+export const DROP_ROLE = "drop_role";
+// Recorded when a role is altered.
+export const ALTER_ROLE = "alter_role";
+// Recorded when role memberships are granted.
+export const GRANT_ROLE = "grant_role";
+// Recorded when role memberships are revoked.
+export const REVOKE_ROLE = "revoke_role";
+// Recorded when a job changes status.
+export const JOB_STATE_CHANGE = "job_state_change";
 
 // Node Event Types
 export const nodeEvents = [NODE_JOIN, NODE_RESTART, NODE_DECOMMISSIONING, NODE_DECOMMISSIONED, NODE_RECOMMISSIONED];
@@ -79,6 +79,18 @@ export function getEventDescription(e: Event$Properties): string {
       return `Zone Config Removed: User ${info.User} removed the zone config for ${info.Target}`;
     case eventTypes.CREATE_STATISTICS:
       return `Table statistics refreshed for ${info.TableName}`;
+    case eventTypes.CREATE_ROLE:
+      return `Role Created: User ${info.User} created role ${info.RoleName}`;
+    case eventTypes.DROP_ROLE:
+      return `Role Dropped: User ${info.User} dropped role ${info.RoleName}`;
+    case eventTypes.ALTER_ROLE:
+      return `Role Altered: User ${info.User} altered role ${info.RoleName}`;
+    case eventTypes.GRANT_ROLE:
+      return `Role Granted: User ${info.User} granted ${info.RoleNames} to ${info.Members}`;
+    case eventTypes.REVOKE_ROLE:
+      return `Role Revoked: User ${info.User} revoked ${info.RoleNames} from ${info.Members}`;
+    case eventTypes.JOB_STATE_CHANGE:
+      return `Job Status Changed: ${info.JobType} job ${info.JobID} changed from ${info.PreviousStatus} to ${info.Status}`;
     default:
       return `Unknown Event Type: ${e.event_type}, content: ${JSON.stringify(info, null, 2)}`;
   }
@@ -99,6 +111,13 @@ export interface EventInfo {
   Target?: string;
   Config?: string;
   Statement?: string;
+  RoleName?: string;
+  RoleNames?: string[];
+  Members?: string[];
+  JobID?: string;
+  JobType?: string;
+  Status?: string;
+  PreviousStatus?: string;
   // The following are three names for the same key (it was renamed twice).
   // All ar included for backwards compatibility.
   DroppedTables?: string[];
@@ -313,6 +313,26 @@ export default function Debug() {
           <DebugTableLink name="Local Sessions" url="/_status/local_sessions" />
           <DebugTableLink name="All Sessions" url="/_status/sessions" />
         </DebugTableRow>
+        <DebugTableRow title="Contention Events">
+          <DebugTableLink
+            name="Local Contention Events"
+            url="/_status/local_contention_events"
+          />
+          <DebugTableLink
+            name="All Contention Events"
+            url="/_status/contention_events"
+          />
+        </DebugTableRow>
+        <DebugTableRow title="Index Usage Statistics">
+          <DebugTableLink
+            name="Local Index Usage Statistics"
+            url="/_status/indexusagestatistics?node_id=local"
+          />
+          <DebugTableLink
+            name="All Index Usage Statistics"
+            url="/_status/indexusagestatistics"
+          />
+        </DebugTableRow>
         <DebugTableRow title="Cluster Wide">
           <DebugTableLink name="Raft" url="/_status/raft" />
           <DebugTableLink
@@ -0,0 +1,164 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package admission implements admission control for work performed by a
+// node, so that an overloaded node throttles incoming work instead of falling
+// over.
+//
+// Work of a certain WorkKind waits in a WorkQueue until it is granted
+// permission to run by a granter. Waiting work is ordered by WorkPriority and,
+// within a priority, by creation time. There are two kinds of granters:
+//
+//  - Slot granters bound the number of concurrently running pieces of work.
+//    They are used for work that is mostly CPU bound: KV batch evaluation and
+//    DistSQL flow execution. A GrantCoordinator owns the slot granters of a
+//    node and adjusts the number of slots based on the node's CPU load. KV
+//    work is always granted before SQL flow work, since it is closer to
+//    completion and holds resources that other work is waiting on.
+//  - Token granters bound the rate at which work is admitted. They are used
+//    for KV writes to a store, whose token budget is reduced when the store's
+//    LSM becomes unhealthy, as indicated by the number of files and
+//    sub-levels in L0. The StoreGrantCoordinators own the per-store token
+//    granters.
+package admission
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/errors"
+)
+
+// KVAdmissionControlEnabled controls whether KV work is subject to admission
+// control.
+var KVAdmissionControlEnabled = settings.RegisterBoolSetting(
+	"admission.kv.enabled",
+	"when true, work performed by the KV layer is subject to admission control",
+	true)
+
+// SQLFlowAdmissionControlEnabled controls whether DistSQL flows are subject
+// to admission control.
+var SQLFlowAdmissionControlEnabled = settings.RegisterBoolSetting(
+	"admission.sql_flow.enabled",
+	"when true, the execution of DistSQL flows is subject to admission control",
+	true)
+
+// KVSlotAdjusterOverloadThreshold is the normalized CPU utilization above
+// which the node is considered overloaded and the number of slots is reduced.
+var KVSlotAdjusterOverloadThreshold = settings.RegisterValidatedFloatSetting(
+	"admission.kv_slot_adjuster.overload_threshold",
+	"when the normalized CPU utilization of the node exceeds this fraction, "+
+		"the number of slots for KV and SQL flow work is reduced",
+	0.9,
+	func(v float64) error {
+		if v <= 0 || v > 1 {
+			return errors.Errorf("cannot set to %f, must be in (0, 1]", v)
+		}
+		return nil
+	})
+
+// L0FileCountOverloadThreshold is the number of files in L0 above which a
+// store is considered overloaded.
+var L0FileCountOverloadThreshold = settings.RegisterPositiveIntSetting(
+	"admission.l0_file_count_overload_threshold",
+	"when the number of files in L0 of a store exceeds this threshold, "+
+		"admission of KV writes to the store is throttled",
+	1000)
+
+// L0SubLevelCountOverloadThreshold is the number of sub-levels in L0 above
+// which a store is considered overloaded.
+var L0SubLevelCountOverloadThreshold = settings.RegisterPositiveIntSetting(
+	"admission.l0_sub_level_count_overload_threshold",
+	"when the number of sub-levels in L0 of a store exceeds this threshold, "+
+		"admission of KV writes to the store is throttled",
+	20)
+
+// WorkPriority represents the priority of work. Waiting work of a higher
+// priority is always admitted before waiting work of a lower priority.
+type WorkPriority int8
+
+const (
+	// LowPri is the lowest priority.
+	LowPri WorkPriority = math.MinInt8
+	// BulkNormalPri is the priority of bulk work, such as backfills, imports
+	// and restores.
+	BulkNormalPri WorkPriority = -30
+	// JobNormalPri is the priority of work performed on behalf of jobs and
+	// other internal background operations.
+	JobNormalPri WorkPriority = -10
+	// NormalPri is the priority of work performed on behalf of users.
+	NormalPri WorkPriority = 0
+	// HighPri is the highest priority.
+	HighPri WorkPriority = math.MaxInt8
+)
+
+var workPriorityNames = map[WorkPriority]string{
+	LowPri:        "low-pri",
+	BulkNormalPri: "bulk-normal-pri",
+	JobNormalPri:  "job-normal-pri",
+	NormalPri:     "normal-pri",
+	HighPri:       "high-pri",
+}
+
+func (w WorkPriority) String() string {
+	if name, ok := workPriorityNames[w]; ok {
+		return name
+	}
+	return fmt.Sprintf("pri(%d)", int8(w))
+}
+
+// WorkKind represents the kind of work, which determines the queue it waits
+// in and the granter which admits it.
+type WorkKind int8
+
+const (
+	// KVWork represents the evaluation of KV batch requests.
+	KVWork WorkKind = iota
+	// SQLFlowWork represents the execution of DistSQL flows.
+	SQLFlowWork
+	numWorkKinds
+)
+
+func (wk WorkKind) String() string {
+	switch wk {
+	case KVWork:
+		return "kv"
+	case SQLFlowWork:
+		return "sql-flow"
+	default:
+		panic(errors.AssertionFailedf("unknown WorkKind %d", int8(wk)))
+	}
+}
+
+// requester is the interface a granter uses to admit waiting work.
+type requester interface {
+	// hasWaitingRequests returns whether there is work waiting for admission.
+	hasWaitingRequests() bool
+	// granted admits the highest priority waiting work, if any, and returns
+	// whether work was admitted.
+	granted() bool
+}
+
+// granter is the interface a WorkQueue uses to acquire permission to admit
+// work.
+type granter interface {
+	// tryGet acquires permission to admit a piece of work without waiting.
+	tryGet() bool
+	// returnGrant is called when admitted work is done.
+	returnGrant()
+	// tookWithoutPermission is called for work which bypassed admission
+	// control, so that the granter can account for it.
+	tookWithoutPermission()
+	// tryGrant admits waiting work if permission is available. It is called
+	// after work was queued, since permission may have become available
+	// between a failed tryGet and the work being queued.
+	tryGrant()
+}
@@ -0,0 +1,509 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Options are the options of a GrantCoordinator.
+type Options struct {
+	// MinSlots and MaxSlots bound the number of slots of each slot granter.
+	MinSlots, MaxSlots int
+	// InitialSlots is the number of slots each slot granter starts with.
+	InitialSlots int
+	// HistogramWindowInterval is the window of the wait duration histograms.
+	HistogramWindowInterval time.Duration
+}
+
+// MakeDefaultOptions returns the default options, which scale the number of
+// slots with the number of CPUs usable by the process.
+func MakeDefaultOptions(histogramWindowInterval time.Duration) Options {
+	procs := runtime.GOMAXPROCS(0)
+	return Options{
+		MinSlots:                procs,
+		MaxSlots:                64 * procs,
+		InitialSlots:            8 * procs,
+		HistogramWindowInterval: histogramWindowInterval,
+	}
+}
+
+// GrantCoordinator coordinates the slot granters of a node. The number of
+// slots of each granter is adjusted based on the CPU utilization of the node,
+// which is reported by periodic calls to CPULoad.
+//
+// The granters form a chain ordered by WorkKind: waiting work of an earlier
+// WorkKind is always granted before waiting work of a later one. This is
+// because KV work is typically performed on behalf of SQL flows that were
+// already admitted, so granting it first completes work that holds resources
+// instead of starting new work.
+type GrantCoordinator struct {
+	settings           *cluster.Settings
+	minSlots, maxSlots int
+
+	// mu protects the state of the granters. The lock is held when calling
+	// into the requesters, which acquire their own locks, so the requesters
+	// must never call into a granter while holding their locks.
+	mu struct {
+		syncutil.Mutex
+		granters [numWorkKinds]*slotGranter
+	}
+	queues  [numWorkKinds]*WorkQueue
+	metrics GranterMetrics
+}
+
+// NewGrantCoordinator creates a GrantCoordinator and the WorkQueues of the
+// WorkKinds it coordinates. It returns the metrics of the coordinator and its
+// queues, which need to be registered by the caller.
+func NewGrantCoordinator(st *cluster.Settings, opts Options) (*GrantCoordinator, []metric.Struct) {
+	coord := &GrantCoordinator{
+		settings: st,
+		minSlots: opts.MinSlots,
+		maxSlots: opts.MaxSlots,
+		metrics:  makeGranterMetrics(),
+	}
+	metricStructs := []metric.Struct{coord.metrics}
+	enabledSettings := [numWorkKinds]*settings.BoolSetting{
+		KVWork:      KVAdmissionControlEnabled,
+		SQLFlowWork: SQLFlowAdmissionControlEnabled,
+	}
+	for kind := WorkKind(0); kind < numWorkKinds; kind++ {
+		sg := &slotGranter{
+			coord:      coord,
+			workKind:   kind,
+			totalSlots: opts.InitialSlots,
+		}
+		queueMetrics := makeWorkQueueMetrics(kind.String(), opts.HistogramWindowInterval)
+		q := makeWorkQueue(kind, sg, &st.SV, enabledSettings[kind], queueMetrics)
+		sg.requester = q
+		coord.mu.granters[kind] = sg
+		coord.queues[kind] = q
+		metricStructs = append(metricStructs, queueMetrics)
+	}
+	coord.updateMetricsLocked()
+	return coord, metricStructs
+}
+
+// GetWorkQueue returns the WorkQueue for the given WorkKind.
+func (coord *GrantCoordinator) GetWorkQueue(workKind WorkKind) *WorkQueue {
+	return coord.queues[workKind]
+}
+
+// CPULoad is called periodically with the CPU utilization of the node,
+// normalized to [0, 1] by the number of CPUs. When the utilization exceeds
+// admission.kv_slot_adjuster.overload_threshold the number of slots is
+// reduced; when it is below and work is waiting for admission, the number of
+// slots is increased.
+func (coord *GrantCoordinator) CPULoad(cpuUtilization float64) {
+	threshold := KVSlotAdjusterOverloadThreshold.Get(&coord.settings.SV)
+	coord.mu.Lock()
+	defer coord.mu.Unlock()
+	for _, sg := range coord.mu.granters {
+		if cpuUtilization > threshold {
+			sg.totalSlots -= (sg.totalSlots + 3) / 4
+			if sg.totalSlots < coord.minSlots {
+				sg.totalSlots = coord.minSlots
+			}
+		} else if sg.requester.hasWaitingRequests() {
+			sg.totalSlots += (sg.totalSlots + 3) / 4
+			if sg.totalSlots > coord.maxSlots {
+				sg.totalSlots = coord.maxSlots
+			}
+		}
+	}
+	coord.tryGrantLocked()
+	coord.updateMetricsLocked()
+}
+
+func (coord *GrantCoordinator) tryGet(workKind WorkKind) bool {
+	coord.mu.Lock()
+	defer coord.mu.Unlock()
+	// Work of an earlier WorkKind that is waiting takes precedence.
+	for kind := WorkKind(0); kind < workKind; kind++ {
+		if coord.mu.granters[kind].requester.hasWaitingRequests() {
+			return false
+		}
+	}
+	sg := coord.mu.granters[workKind]
+	if sg.usedSlots >= sg.totalSlots {
+		return false
+	}
+	sg.usedSlots++
+	coord.updateMetricsLocked()
+	return true
+}
+
+func (coord *GrantCoordinator) returnGrant(workKind WorkKind) {
+	coord.mu.Lock()
+	defer coord.mu.Unlock()
+	coord.mu.granters[workKind].usedSlots--
+	coord.tryGrantLocked()
+	coord.updateMetricsLocked()
+}
+
+func (coord *GrantCoordinator) tookWithoutPermission(workKind WorkKind) {
+	coord.mu.Lock()
+	defer coord.mu.Unlock()
+	coord.mu.granters[workKind].usedSlots++
+	coord.updateMetricsLocked()
+}
+
+func (coord *GrantCoordinator) tryGrant() {
+	coord.mu.Lock()
+	defer coord.mu.Unlock()
+	coord.tryGrantLocked()
+	coord.updateMetricsLocked()
+}
+
+// tryGrantLocked grants free slots to waiting work, in the order of the
+// grant chain. Work of a later WorkKind is only granted once no work of an
+// earlier WorkKind is waiting.
+func (coord *GrantCoordinator) tryGrantLocked() {
+	for _, sg := range coord.mu.granters {
+		for sg.usedSlots < sg.totalSlots && sg.requester.granted() {
+			sg.usedSlots++
+		}
+		if sg.requester.hasWaitingRequests() {
+			return
+		}
+	}
+}
+
+func (coord *GrantCoordinator) updateMetricsLocked() {
+	for kind, sg := range coord.mu.granters {
+		coord.metrics.TotalSlots[kind].Update(int64(sg.totalSlots))
+		coord.metrics.UsedSlots[kind].Update(int64(sg.usedSlots))
+	}
+}
+
+// slotGranter is the granter of a WorkKind coordinated by a
+// GrantCoordinator. Its fields are protected by the coordinator's mutex.
+type slotGranter struct {
+	coord      *GrantCoordinator
+	workKind   WorkKind
+	requester  requester
+	usedSlots  int
+	totalSlots int
+}
+
+var _ granter = &slotGranter{}
+
+func (sg *slotGranter) tryGet() bool           { return sg.coord.tryGet(sg.workKind) }
+func (sg *slotGranter) returnGrant()           { sg.coord.returnGrant(sg.workKind) }
+func (sg *slotGranter) tookWithoutPermission() { sg.coord.tookWithoutPermission(sg.workKind) }
+func (sg *slotGranter) tryGrant()              { sg.coord.tryGrant() }
+
+// GranterMetrics are the metrics of a GrantCoordinator.
+type GranterMetrics struct {
+	TotalSlots [numWorkKinds]*metric.Gauge
+	UsedSlots  [numWorkKinds]*metric.Gauge
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (GranterMetrics) MetricStruct() {}
+
+var _ metric.Struct = GranterMetrics{}
+
+func makeGranterMetrics() GranterMetrics {
+	var m GranterMetrics
+	for kind := WorkKind(0); kind < numWorkKinds; kind++ {
+		m.TotalSlots[kind] = metric.NewGauge(metric.Metadata{
+			Name:        "admission.granter.total_slots." + kind.String(),
+			Help:        "Total slots for " + kind.String() + " work",
+			Measurement: "Slots",
+			Unit:        metric.Unit_COUNT,
+		})
+		m.UsedSlots[kind] = metric.NewGauge(metric.Metadata{
+			Name:        "admission.granter.used_slots." + kind.String(),
+			Help:        "Used slots for " + kind.String() + " work",
+			Measurement: "Slots",
+			Unit:        metric.Unit_COUNT,
+		})
+	}
+	return m
+}
+
+// StoreMetrics are the metrics of a store used to assess the health of its
+// LSM.
+type StoreMetrics struct {
+	StoreID roachpb.StoreID
+	// L0FileCount is the number of files in L0.
+	L0FileCount int64
+	// L0SublevelCount is the number of sub-levels in L0. It is -1 for engines
+	// that don't maintain sub-levels.
+	L0SublevelCount int64
+}
+
+// StoreMetricsProvider provides the metrics of the stores of a node.
+type StoreMetricsProvider interface {
+	GetStoreMetrics() []StoreMetrics
+}
+
+const (
+	// adjustmentInterval is the interval at which the token budget of each
+	// store is recomputed from its metrics.
+	adjustmentInterval = 15 * time.Second
+	// ticksInAdjustmentInterval is the number of ticks over which the token
+	// budget is handed out, so that work is admitted smoothly over the
+	// interval instead of in a burst at its start.
+	ticksInAdjustmentInterval = 15
+	// unlimitedTokens is the token budget of stores that are not overloaded.
+	unlimitedTokens = math.MaxInt64
+)
+
+// StoreGrantCoordinators maintains a WorkQueue and token granter for each
+// store of a node. KV writes to a store are admitted by its queue, at a rate
+// that is throttled when the store's LSM is unhealthy.
+type StoreGrantCoordinators struct {
+	settings *cluster.Settings
+	metrics  WorkQueueMetrics
+
+	mu struct {
+		syncutil.Mutex
+		stores map[roachpb.StoreID]*storeGrantCoordinator
+	}
+}
+
+// NewStoreGrantCoordinators creates a StoreGrantCoordinators. Its queues are
+// created once a StoreMetricsProvider is set. The returned metrics are shared
+// by the queues of all stores and need to be registered by the caller.
+func NewStoreGrantCoordinators(
+	st *cluster.Settings, histogramWindowInterval time.Duration,
+) (*StoreGrantCoordinators, metric.Struct) {
+	sgc := &StoreGrantCoordinators{
+		settings: st,
+		metrics:  makeWorkQueueMetrics("kv-stores", histogramWindowInterval),
+	}
+	sgc.mu.stores = make(map[roachpb.StoreID]*storeGrantCoordinator)
+	return sgc, sgc.metrics
+}
+
+// SetStoreMetricsProvider sets the provider of the metrics of the node's
+// stores, and starts a worker which periodically adjusts the token budgets
+// of the stores based on these metrics.
+func (sgc *StoreGrantCoordinators) SetStoreMetricsProvider(
+	ctx context.Context, stopper *stop.Stopper, provider StoreMetricsProvider,
+) {
+	sgc.adjustTokens(ctx, provider.GetStoreMetrics())
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(adjustmentInterval / ticksInAdjustmentInterval)
+		defer ticker.Stop()
+		ticks := 0
+		for {
+			select {
+			case <-ticker.C:
+				ticks++
+				if ticks%ticksInAdjustmentInterval == 0 {
+					sgc.adjustTokens(ctx, provider.GetStoreMetrics())
+				} else {
+					sgc.tick()
+				}
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// TryGetQueueForStore returns the WorkQueue for the given store, or nil if
+// the store is not known yet.
+func (sgc *StoreGrantCoordinators) TryGetQueueForStore(storeID roachpb.StoreID) *WorkQueue {
+	sgc.mu.Lock()
+	defer sgc.mu.Unlock()
+	if s, ok := sgc.mu.stores[storeID]; ok {
+		return s.queue
+	}
+	return nil
+}
+
+func (sgc *StoreGrantCoordinators) adjustTokens(ctx context.Context, metrics []StoreMetrics) {
+	sgc.mu.Lock()
+	defer sgc.mu.Unlock()
+	for _, m := range metrics {
+		s, ok := sgc.mu.stores[m.StoreID]
+		if !ok {
+			s = sgc.newStoreGrantCoordinator()
+			sgc.mu.stores[m.StoreID] = s
+		}
+		s.adjustTokens(ctx, &sgc.settings.SV, m)
+	}
+}
+
+func (sgc *StoreGrantCoordinators) tick() {
+	sgc.mu.Lock()
+	defer sgc.mu.Unlock()
+	for _, s := range sgc.mu.stores {
+		s.granter.setAvailableTokens(s.tokensPerTick)
+	}
+}
+
+func (sgc *StoreGrantCoordinators) newStoreGrantCoordinator() *storeGrantCoordinator {
+	g := &tokenGranter{}
+	g.mu.availableTokens = unlimitedTokens
+	q := makeWorkQueue(KVWork, g, &sgc.settings.SV, KVAdmissionControlEnabled, sgc.metrics)
+	g.requester = q
+	return &storeGrantCoordinator{
+		granter:       g,
+		queue:         q,
+		tokensPerTick: unlimitedTokens,
+	}
+}
+
+// storeGrantCoordinator adjusts the token budget of a single store.
+type storeGrantCoordinator struct {
+	granter *tokenGranter
+	queue   *WorkQueue
+
+	// The fields below are protected by StoreGrantCoordinators.mu.
+
+	// tokensPerTick is the number of tokens handed out on each tick of the
+	// current adjustment interval.
+	tokensPerTick int64
+	// lastAdmittedCount is the admitted count of the granter at the start of
+	// the current adjustment interval.
+	lastAdmittedCount int64
+	// lastMetrics are the metrics at the start of the current adjustment
+	// interval.
+	lastMetrics StoreMetrics
+	overloaded  bool
+}
+
+// adjustTokens computes the token budget of the next adjustment interval. If
+// the store is not overloaded, the budget is unlimited. Otherwise, the budget
+// is half the number of requests admitted in the last interval, which gives
+// compactions the opportunity to catch up. While the store stays overloaded,
+// the budget keeps being halved unless the number of files in L0 is
+// decreasing, in which case it is maintained.
+func (s *storeGrantCoordinator) adjustTokens(
+	ctx context.Context, sv *settings.Values, m StoreMetrics,
+) {
+	admittedCount := s.granter.getAdmittedCount()
+	admitted := admittedCount - s.lastAdmittedCount
+	wasOverloaded := s.overloaded
+	improving := m.L0FileCount < s.lastMetrics.L0FileCount
+	s.lastAdmittedCount = admittedCount
+	s.lastMetrics = m
+
+	s.overloaded = m.L0FileCount > L0FileCountOverloadThreshold.Get(sv) ||
+		m.L0SublevelCount > L0SubLevelCountOverloadThreshold.Get(sv)
+	if !s.overloaded {
+		if wasOverloaded {
+			log.Infof(ctx, "s%d is no longer overloaded: %d files and %d sub-levels in L0",
+				m.StoreID, m.L0FileCount, m.L0SublevelCount)
+		}
+		s.tokensPerTick = unlimitedTokens
+	} else {
+		tokens := admitted / 2
+		if wasOverloaded && improving {
+			tokens = admitted
+		}
+		// Always admit at least one request per tick, so that the store's
+		// throughput can be measured during the next interval.
+		s.tokensPerTick = (tokens + ticksInAdjustmentInterval - 1) / ticksInAdjustmentInterval
+		if s.tokensPerTick < 1 {
+			s.tokensPerTick = 1
+		}
+		if !wasOverloaded {
+			log.Infof(ctx, "s%d is overloaded: %d files and %d sub-levels in L0, "+
+				"limiting admission to %d requests per %s",
+				m.StoreID, m.L0FileCount, m.L0SublevelCount,
+				s.tokensPerTick*ticksInAdjustmentInterval, adjustmentInterval)
+		}
+	}
+	s.granter.setAvailableTokens(s.tokensPerTick)
+}
+
+// tokenGranter is a granter which admits work while it has tokens available.
+// Admitted work consumes a token, which is not returned once the work is
+// done.
+type tokenGranter struct {
+	requester requester
+
+	// mu is held when calling into the requester.
+	mu struct {
+		syncutil.Mutex
+		// availableTokens is the number of tokens available. It can become
+		// negative due to work taken without permission, in which case the
+		// deficit is carried over to the next tick.
+		availableTokens int64
+		// admittedCount is the number of pieces of work admitted.
+		admittedCount int64
+	}
+}
+
+var _ granter = &tokenGranter{}
+
+func (tg *tokenGranter) tryGet() bool {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	return tg.tryTakeLocked()
+}
+
+func (tg *tokenGranter) tryTakeLocked() bool {
+	if tg.mu.availableTokens <= 0 {
+		return false
+	}
+	tg.mu.availableTokens--
+	tg.mu.admittedCount++
+	return true
+}
+
+func (tg *tokenGranter) returnGrant() {}
+
+func (tg *tokenGranter) tookWithoutPermission() {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.mu.availableTokens--
+	tg.mu.admittedCount++
+}
+
+func (tg *tokenGranter) tryGrant() {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.tryGrantLocked()
+}
+
+func (tg *tokenGranter) tryGrantLocked() {
+	for tg.mu.availableTokens > 0 && tg.requester.granted() {
+		tg.mu.availableTokens--
+		tg.mu.admittedCount++
+	}
+}
+
+// setAvailableTokens resets the available tokens at the start of a tick and
+// grants them to waiting work. Unused tokens of the previous tick are not
+// carried over, to avoid bursts of admissions, but a deficit is.
+func (tg *tokenGranter) setAvailableTokens(tokens int64) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	if tg.mu.availableTokens > 0 {
+		tg.mu.availableTokens = 0
+	}
+	tg.mu.availableTokens += tokens
+	tg.tryGrantLocked()
+}
+
+func (tg *tokenGranter) getAdmittedCount() int64 {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	return tg.mu.admittedCount
+}
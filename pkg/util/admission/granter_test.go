@@ -0,0 +1,175 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func numWaiting(q *WorkQueue) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.mu.waiting)
+}
+
+func waitForWaiting(t *testing.T, q *WorkQueue, expected int) {
+	testutils.SucceedsSoon(t, func() error {
+		if n := numWaiting(q); n != expected {
+			return errors.Errorf("expected %d waiting, found %d", expected, n)
+		}
+		return nil
+	})
+}
+
+func TestGrantCoordinator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	coord, _ := NewGrantCoordinator(st, Options{
+		MinSlots:                1,
+		MaxSlots:                4,
+		InitialSlots:            1,
+		HistogramWindowInterval: time.Second,
+	})
+	kvQ := coord.GetWorkQueue(KVWork)
+	sqlQ := coord.GetWorkQueue(SQLFlowWork)
+	ctx := context.Background()
+
+	slots := func(kind WorkKind) (used, total int) {
+		coord.mu.Lock()
+		defer coord.mu.Unlock()
+		sg := coord.mu.granters[kind]
+		return sg.usedSlots, sg.totalSlots
+	}
+
+	// The only KV slot is taken.
+	_, err := kvQ.Admit(ctx, WorkInfo{Priority: NormalPri})
+	require.NoError(t, err)
+
+	// The only SQL flow slot is taken, so further work of both kinds waits.
+	_, err = sqlQ.Admit(ctx, WorkInfo{Priority: NormalPri})
+	require.NoError(t, err)
+	admitted := make(chan WorkKind, 2)
+	go func() {
+		_, err := sqlQ.Admit(ctx, WorkInfo{Priority: NormalPri})
+		require.NoError(t, err)
+		admitted <- SQLFlowWork
+	}()
+	waitForWaiting(t, sqlQ, 1)
+	go func() {
+		_, err := kvQ.Admit(ctx, WorkInfo{Priority: NormalPri})
+		require.NoError(t, err)
+		admitted <- KVWork
+	}()
+	waitForWaiting(t, kvQ, 1)
+
+	// Returning a KV slot admits the waiting KV work.
+	kvQ.AdmittedWorkDone()
+	require.Equal(t, KVWork, <-admitted)
+
+	// Adding slots while the CPU is not overloaded admits the waiting SQL
+	// flow work.
+	coord.CPULoad(0.5)
+	require.Equal(t, SQLFlowWork, <-admitted)
+	used, total := slots(SQLFlowWork)
+	require.Equal(t, 2, used)
+	require.Equal(t, 2, total)
+
+	// Without waiting work, the slots are not increased further.
+	coord.CPULoad(0.5)
+	_, total = slots(SQLFlowWork)
+	require.Equal(t, 2, total)
+
+	// While waiting KV work exists, SQL flow work is not admitted even if it
+	// has free slots.
+	sqlQ.AdmittedWorkDone()
+	go func() {
+		_, err := kvQ.Admit(ctx, WorkInfo{Priority: NormalPri})
+		require.NoError(t, err)
+		admitted <- KVWork
+	}()
+	waitForWaiting(t, kvQ, 1)
+	require.False(t, coord.tryGet(SQLFlowWork))
+	kvQ.AdmittedWorkDone()
+	require.Equal(t, KVWork, <-admitted)
+	require.True(t, coord.tryGet(SQLFlowWork))
+
+	// Overload reduces the number of slots down to the minimum.
+	for i := 0; i < 5; i++ {
+		coord.CPULoad(1)
+	}
+	_, total = slots(KVWork)
+	require.Equal(t, 1, total)
+	_, total = slots(SQLFlowWork)
+	require.Equal(t, 1, total)
+
+	// Work bypassing admission control takes slots beyond the total.
+	_, err = kvQ.Admit(ctx, WorkInfo{Priority: NormalPri, BypassAdmission: true})
+	require.NoError(t, err)
+	used, _ = slots(KVWork)
+	require.Equal(t, 2, used)
+}
+
+func TestStoreGrantCoordinators(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	sgc, _ := NewStoreGrantCoordinators(st, time.Second)
+	ctx := context.Background()
+	require.Nil(t, sgc.TryGetQueueForStore(1))
+
+	// A healthy store admits work without limits.
+	sgc.adjustTokens(ctx, []StoreMetrics{{StoreID: 1, L0FileCount: 10, L0SublevelCount: 2}})
+	q := sgc.TryGetQueueForStore(1)
+	require.NotNil(t, q)
+	for i := 0; i < 100; i++ {
+		_, err := q.Admit(ctx, WorkInfo{Priority: NormalPri})
+		require.NoError(t, err)
+	}
+
+	// Once overloaded, the store admits half as much work during the next
+	// interval as during the last one, spread over its ticks.
+	sgc.adjustTokens(ctx, []StoreMetrics{{StoreID: 1, L0FileCount: 10, L0SublevelCount: 30}})
+	s := sgc.mu.stores[1]
+	require.Equal(t, int64(4), s.tokensPerTick)
+	for i := 0; i < 4; i++ {
+		require.True(t, s.granter.tryGet())
+	}
+	require.False(t, s.granter.tryGet())
+
+	admitted := make(chan struct{})
+	go func() {
+		_, err := q.Admit(ctx, WorkInfo{Priority: NormalPri})
+		require.NoError(t, err)
+		close(admitted)
+	}()
+	waitForWaiting(t, q, 1)
+	sgc.tick()
+	<-admitted
+
+	// While the store stays overloaded and doesn't improve, the budget keeps
+	// shrinking, but at least one request is admitted per tick.
+	sgc.adjustTokens(ctx, []StoreMetrics{{StoreID: 1, L0FileCount: 10, L0SublevelCount: 40}})
+	require.Equal(t, int64(1), s.tokensPerTick)
+
+	// A healthy store is no longer limited.
+	sgc.adjustTokens(ctx, []StoreMetrics{{StoreID: 1, L0FileCount: 10, L0SublevelCount: 5}})
+	require.Equal(t, int64(unlimitedTokens), s.tokensPerTick)
+	require.True(t, s.granter.tryGet())
+}
@@ -0,0 +1,261 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// WorkInfo describes a piece of work that is submitted for admission.
+type WorkInfo struct {
+	// Priority is the priority of the work.
+	Priority WorkPriority
+	// CreateTime is the time, in nanoseconds since the epoch, at which the
+	// work was created. Waiting work of the same priority is admitted in
+	// CreateTime order, so that pieces of work belonging to an older
+	// transaction or query are preferred.
+	CreateTime int64
+	// BypassAdmission is set for work that must not wait, for instance because
+	// other work may be waiting for it to finish. Such work is admitted
+	// immediately but is still accounted for by the granter.
+	BypassAdmission bool
+}
+
+// WorkQueue maintains the work of a WorkKind that is waiting for admission,
+// and admits it when its granter grants permission.
+type WorkQueue struct {
+	workKind WorkKind
+	granter  granter
+	sv       *settings.Values
+	enabled  *settings.BoolSetting
+	metrics  WorkQueueMetrics
+
+	mu struct {
+		syncutil.Mutex
+		waiting waitingWorkHeap
+	}
+}
+
+var _ requester = &WorkQueue{}
+
+func makeWorkQueue(
+	workKind WorkKind,
+	granter granter,
+	sv *settings.Values,
+	enabled *settings.BoolSetting,
+	metrics WorkQueueMetrics,
+) *WorkQueue {
+	return &WorkQueue{
+		workKind: workKind,
+		granter:  granter,
+		sv:       sv,
+		enabled:  enabled,
+		metrics:  metrics,
+	}
+}
+
+// Admit is called when requesting admission for a piece of work. It blocks
+// until the work is admitted or the context is canceled, in which case an
+// error is returned. If the returned bool is true, AdmittedWorkDone must be
+// called once the work is done. It is false when admission control is
+// disabled.
+func (q *WorkQueue) Admit(ctx context.Context, info WorkInfo) (enabled bool, _ error) {
+	if !q.enabled.Get(q.sv) {
+		return false, nil
+	}
+	q.metrics.Requested.Inc(1)
+	if info.BypassAdmission {
+		q.granter.tookWithoutPermission()
+		q.metrics.Admitted.Inc(1)
+		return true, nil
+	}
+
+	q.mu.Lock()
+	if len(q.mu.waiting) == 0 {
+		// Nothing is waiting, so the work may be admitted right away. The
+		// granter must not be called with q.mu held, since it calls into the
+		// requester while holding its own lock.
+		q.mu.Unlock()
+		if q.granter.tryGet() {
+			q.metrics.Admitted.Inc(1)
+			return true, nil
+		}
+		q.mu.Lock()
+	}
+	startTime := timeutil.Now()
+	work := &waitingWork{
+		priority:   info.Priority,
+		createTime: info.CreateTime,
+		grantCh:    make(chan struct{}, 1),
+	}
+	heap.Push(&q.mu.waiting, work)
+	q.mu.Unlock()
+	q.metrics.WaitQueueLength.Inc(1)
+	q.granter.tryGrant()
+
+	select {
+	case <-ctx.Done():
+		q.mu.Lock()
+		granted := work.heapIndex == -1
+		if !granted {
+			heap.Remove(&q.mu.waiting, work.heapIndex)
+		}
+		q.mu.Unlock()
+		if granted {
+			// The work was granted concurrently with the cancellation, so the
+			// grant needs to be returned.
+			q.granter.returnGrant()
+		} else {
+			q.metrics.WaitQueueLength.Dec(1)
+		}
+		q.metrics.Errored.Inc(1)
+		q.metrics.WaitDurations.RecordValue(timeutil.Since(startTime).Nanoseconds())
+		return false, errors.Wrapf(ctx.Err(),
+			"work %s deadline expired while waiting for admission", q.workKind)
+	case <-work.grantCh:
+		q.metrics.Admitted.Inc(1)
+		q.metrics.WaitDurations.RecordValue(timeutil.Since(startTime).Nanoseconds())
+		return true, nil
+	}
+}
+
+// AdmittedWorkDone is called when admitted work is done. It must be called
+// exactly once for every call to Admit that returned true.
+func (q *WorkQueue) AdmittedWorkDone() {
+	q.granter.returnGrant()
+}
+
+// hasWaitingRequests implements the requester interface.
+func (q *WorkQueue) hasWaitingRequests() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.mu.waiting) > 0
+}
+
+// granted implements the requester interface.
+func (q *WorkQueue) granted() bool {
+	q.mu.Lock()
+	if len(q.mu.waiting) == 0 {
+		q.mu.Unlock()
+		return false
+	}
+	work := heap.Pop(&q.mu.waiting).(*waitingWork)
+	q.mu.Unlock()
+	q.metrics.WaitQueueLength.Dec(1)
+	work.grantCh <- struct{}{}
+	return true
+}
+
+// waitingWork is a piece of work waiting in a WorkQueue.
+type waitingWork struct {
+	priority   WorkPriority
+	createTime int64
+	// grantCh is signaled when the work is admitted.
+	grantCh chan struct{}
+	// heapIndex is the index of the work in the waitingWorkHeap, or -1 once
+	// the work was removed from it.
+	heapIndex int
+}
+
+// waitingWorkHeap implements heap.Interface. The work at the top of the heap
+// has the highest priority and, within that priority, the earliest creation
+// time.
+type waitingWorkHeap []*waitingWork
+
+var _ heap.Interface = (*waitingWorkHeap)(nil)
+
+func (h waitingWorkHeap) Len() int { return len(h) }
+
+func (h waitingWorkHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].createTime < h[j].createTime
+}
+
+func (h waitingWorkHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *waitingWorkHeap) Push(x interface{}) {
+	work := x.(*waitingWork)
+	work.heapIndex = len(*h)
+	*h = append(*h, work)
+}
+
+func (h *waitingWorkHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	work := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	work.heapIndex = -1
+	return work
+}
+
+// WorkQueueMetrics are the metrics of a WorkQueue.
+type WorkQueueMetrics struct {
+	Requested       *metric.Counter
+	Admitted        *metric.Counter
+	Errored         *metric.Counter
+	WaitDurations   *metric.Histogram
+	WaitQueueLength *metric.Gauge
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (WorkQueueMetrics) MetricStruct() {}
+
+var _ metric.Struct = WorkQueueMetrics{}
+
+func makeWorkQueueMetrics(name string, histogramWindow time.Duration) WorkQueueMetrics {
+	return WorkQueueMetrics{
+		Requested: metric.NewCounter(metric.Metadata{
+			Name:        "admission.requested." + name,
+			Help:        "Number of requests for admission of " + name + " work",
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		Admitted: metric.NewCounter(metric.Metadata{
+			Name:        "admission.admitted." + name,
+			Help:        "Number of requests for admission of " + name + " work that were admitted",
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		Errored: metric.NewCounter(metric.Metadata{
+			Name:        "admission.errored." + name,
+			Help:        "Number of requests for admission of " + name + " work that failed while waiting",
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+		WaitDurations: metric.NewLatency(metric.Metadata{
+			Name:        "admission.wait_durations." + name,
+			Help:        "Wait time durations of " + name + " work that waited for admission",
+			Measurement: "Wait time Duration",
+			Unit:        metric.Unit_NANOSECONDS,
+		}, histogramWindow),
+		WaitQueueLength: metric.NewGauge(metric.Metadata{
+			Name:        "admission.wait_queue_length." + name,
+			Help:        "Number of pieces of " + name + " work waiting for admission",
+			Measurement: "Requests",
+			Unit:        metric.Unit_COUNT,
+		}),
+	}
+}
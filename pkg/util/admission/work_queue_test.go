@@ -0,0 +1,137 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// testGranter is a granter whose grants are controlled by the test.
+type testGranter struct {
+	mu struct {
+		syncutil.Mutex
+		returnValueFromTryGet bool
+		returned              int
+		tookWithoutPermission int
+	}
+	requester requester
+}
+
+var _ granter = &testGranter{}
+
+func (tg *testGranter) tryGet() bool {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	return tg.mu.returnValueFromTryGet
+}
+
+func (tg *testGranter) returnGrant() {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.mu.returned++
+}
+
+func (tg *testGranter) tookWithoutPermission() {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.mu.tookWithoutPermission++
+}
+
+func (tg *testGranter) tryGrant() {}
+
+func (tg *testGranter) setReturnValueFromTryGet(v bool) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+	tg.mu.returnValueFromTryGet = v
+}
+
+type admitResult struct {
+	id  int
+	err error
+}
+
+func TestWorkQueueBasic(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	tg := &testGranter{}
+	q := makeWorkQueue(KVWork, tg, &st.SV, KVAdmissionControlEnabled,
+		makeWorkQueueMetrics("test", time.Second))
+	tg.requester = q
+	ctx := context.Background()
+
+	// Work is admitted right away while the granter has permission.
+	tg.setReturnValueFromTryGet(true)
+	enabled, err := q.Admit(ctx, WorkInfo{Priority: NormalPri})
+	require.NoError(t, err)
+	require.True(t, enabled)
+	q.AdmittedWorkDone()
+	require.Equal(t, 1, tg.mu.returned)
+
+	// Work bypassing admission is admitted without asking for permission.
+	tg.setReturnValueFromTryGet(false)
+	enabled, err = q.Admit(ctx, WorkInfo{Priority: NormalPri, BypassAdmission: true})
+	require.NoError(t, err)
+	require.True(t, enabled)
+	require.Equal(t, 1, tg.mu.tookWithoutPermission)
+
+	// Waiting work is admitted in priority order, then in creation time
+	// order.
+	works := []WorkInfo{
+		{Priority: NormalPri, CreateTime: 2},
+		{Priority: BulkNormalPri, CreateTime: 1},
+		{Priority: NormalPri, CreateTime: 1},
+		{Priority: HighPri, CreateTime: 3},
+	}
+	results := make(chan admitResult, len(works))
+	for i, info := range works {
+		go func(id int, info WorkInfo) {
+			_, err := q.Admit(ctx, info)
+			results <- admitResult{id: id, err: err}
+		}(i, info)
+		waitForWaiting(t, q, i+1)
+	}
+	for _, expected := range []int{3, 2, 0, 1} {
+		require.True(t, q.granted())
+		res := <-results
+		require.NoError(t, res.err)
+		require.Equal(t, expected, res.id)
+	}
+	require.False(t, q.granted())
+	require.False(t, q.hasWaitingRequests())
+
+	// Canceled work stops waiting and is removed from the queue.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		_, err := q.Admit(cancelCtx, WorkInfo{Priority: NormalPri})
+		results <- admitResult{err: err}
+	}()
+	waitForWaiting(t, q, 1)
+	cancel()
+	res := <-results
+	require.True(t, errors.Is(res.err, context.Canceled))
+	require.False(t, q.hasWaitingRequests())
+	require.Equal(t, int64(1), q.metrics.Errored.Count())
+
+	// Nothing is admitted by the queue when admission control is disabled.
+	KVAdmissionControlEnabled.Override(&st.SV, false)
+	enabled, err = q.Admit(ctx, WorkInfo{Priority: NormalPri})
+	require.NoError(t, err)
+	require.False(t, enabled)
+}
@@ -364,6 +364,14 @@ func (mc *UnorderedCache) length() int {
 	return len(mc.hmap)
 }
 
+// Do invokes f on all of the entries in the cache. The order of iteration is
+// unspecified. f must not modify the cache.
+func (mc *UnorderedCache) Do(f func(e *Entry)) {
+	for _, e := range mc.hmap {
+		f(e.(*Entry))
+	}
+}
+
 // OrderedCache is a cache which supports binary searches using Ceil
 // and Floor methods. It is backed by a left-leaning red black tree.
 // See comments in UnorderedCache for more details on cache functionality.
@@ -95,6 +95,20 @@ func TestCacheDel(t *testing.T) {
 	}
 }
 
+func TestCacheDo(t *testing.T) {
+	mc := NewUnorderedCache(Config{Policy: CacheLRU, ShouldEvict: noEviction})
+	mc.Add(testKey("a"), 1)
+	mc.Add(testKey("b"), 2)
+	mc.Add(testKey("c"), 3)
+	sum := 0
+	mc.Do(func(e *Entry) {
+		sum += e.Value.(int)
+	})
+	if sum != 6 {
+		t.Fatalf("expected sum of values to be 6, got %d", sum)
+	}
+}
+
 func TestCacheAddDelEntry(t *testing.T) {
 	mc := NewUnorderedCache(Config{Policy: CacheLRU, ShouldEvict: noEviction})
 	e := &Entry{Key: testKey("myKey"), Value: 1234}
@@ -22,6 +22,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/logtags"
 )
@@ -77,6 +78,11 @@ type loggingT struct {
 }
 
 type loggerT struct {
+	// channel is the name of the logging channel served by this
+	// logger. It determines which configured sinks receive the
+	// entries, and is reported by the structured log formats.
+	channel string
+
 	// Directory prefix where to store this logger's files.
 	logDir DirName
 
@@ -94,6 +100,10 @@ type loggerT struct {
 	// stderr sink.
 	stderrThreshold Severity
 
+	// fileFormat is the format used for the logger's output file.
+	// If nil, the crdb-v1 format is used.
+	fileFormat logFormatter
+
 	// whether or not to include redaction markers.
 	// This is atomic because tests using TestLogScope might
 	// override this asynchronously with log calls.
@@ -160,6 +170,7 @@ func init() {
 	logging.bufPool.New = newBuffer
 	logging.mu.fatalCh = make(chan struct{})
 	mainLog.prefix = program
+	mainLog.channel = logconfig.DefaultChannel
 	// Default stderrThreshold and fileThreshold to log everything
 	// both to the output file and to the process' external stderr
 	// (OrigStderr).
@@ -331,6 +342,13 @@ func (l *loggerT) outputLogEntry(entry Entry) {
 
 		putBuffer(buf)
 	}
+	if err := l.outputToSinksLocked(entry, stacks); err != nil {
+		// A critical sink is unavailable. As above, we do not like to
+		// lose log entries, so we stop here.
+		l.exitLocked(err)
+		l.mu.Unlock() // unreachable except in tests
+		return        // unreachable except in tests
+	}
 	// Flush and exit on fatal logging.
 	if entry.Severity == Severity_FATAL {
 		l.flushAndSyncLocked(true /*doSync*/)
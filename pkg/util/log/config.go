@@ -0,0 +1,219 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/errors"
+)
+
+// ApplyConfig sets up the logging sinks described by the given
+// configuration, and routes the logging channels to them. The
+// configuration must have been validated with
+// (*logconfig.Config).Validate() beforehand.
+//
+// ApplyConfig should be called once, after
+// SetupRedactionAndStderrRedirects() and before the secondary loggers
+// are created. The channels routed to a file group stop writing to
+// their default log file; when a stderr sink is configured, it
+// supersedes the stderr threshold of all the loggers.
+//
+// The returned cleanup fn removes the sinks and restores the previous
+// routing. Like for SetupRedactionAndStderrRedirects(), this is only
+// useful in tests.
+func ApplyConfig(config *logconfig.Config) (cleanupForTestingOnly func(), err error) {
+	if func() bool {
+		sinkRegistry.mu.RLock()
+		defer sinkRegistry.mu.RUnlock()
+		return sinkRegistry.mu.active
+	}() {
+		return nil, errors.New("logging configuration already applied")
+	}
+
+	// Our own cancellable context to stop the GC daemons of the file
+	// groups. See the discussion in SetupRedactionAndStderrRedirects()
+	// about why we don't take a context from the caller.
+	ctx, cancel := context.WithCancel(context.Background())
+	var allSinks []*sinkInfo
+	closeAll := func() {
+		for _, s := range allSinks {
+			s.sink.close()
+		}
+		cancel()
+	}
+	defer func() {
+		if err != nil {
+			closeAll()
+		}
+	}()
+
+	channels := make(map[string][]*sinkInfo)
+	claimedFiles := make(map[string]bool)
+	attach := func(s *sinkInfo, chs logconfig.ChannelList) {
+		allSinks = append(allSinks, s)
+		for _, ch := range chs.Channels {
+			channels[ch] = append(channels[ch], s)
+		}
+	}
+
+	for _, name := range config.FileGroupNames() {
+		fc := config.Sinks.FileGroups[name]
+		s, err := newSinkInfo(fc.CommonSinkConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "file group %q", name)
+		}
+		if err := os.MkdirAll(*fc.Dir, 0755); err != nil {
+			return nil, errors.Wrapf(err, "file group %q", name)
+		}
+		secL := NewSecondaryLogger(ctx, &DirName{name: *fc.Dir}, name,
+			true /* enableGc */, *fc.SyncWrites, false /* enableMsgCount */)
+		// The file group logger does not serve a channel by itself: it
+		// only receives entries formatted by the sink.
+		secL.logger.channel = ""
+		secL.logger.fileFormat = s.formatter
+		secL.logger.redactableLogs.Set(*fc.Redactable)
+		s.sink = &fileSink{logger: secL}
+		attach(s, fc.Channels)
+		for _, ch := range fc.Channels.Channels {
+			claimedFiles[ch] = true
+		}
+	}
+
+	for _, name := range config.FluentServerNames() {
+		fc := config.Sinks.FluentServers[name]
+		s, err := newSinkInfo(fc.CommonSinkConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fluent server %q", name)
+		}
+		s.sink = newFluentSink(fc.Net, fc.Address)
+		attach(s, fc.Channels)
+	}
+
+	for _, name := range config.HTTPServerNames() {
+		hc := config.Sinks.HTTPServers[name]
+		s, err := newSinkInfo(hc.CommonSinkConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "http server %q", name)
+		}
+		s.sink = newHTTPSink(hc.Address, time.Duration(hc.Timeout), hc.UnsafeTLS, s.formatter.contentType())
+		attach(s, hc.Channels)
+	}
+
+	if sc := config.Sinks.Stderr; sc != nil {
+		s, err := newSinkInfo(sc.CommonSinkConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "stderr")
+		}
+		if *sc.Redactable && stderrLog == &mainLog {
+			// See the discussion in SetupRedactionAndStderrRedirects():
+			// redaction markers are only safe on stderr if the direct
+			// writes to the stderr file descriptor are captured.
+			return nil, errors.WithHint(
+				errors.New("cannot enable redactable stderr output without capturing stderr writes to a file"),
+				"Configure a logging directory, or set 'redactable: false' on the stderr sink.")
+		}
+		if _, ok := s.formatter.(formatCrdbV1TTY); ok && sc.NoColor {
+			s.formatter = formatCrdbV1{}
+		}
+		s.sink = stderrSink{}
+		attach(s, sc.Channels)
+	}
+
+	// Install the routing.
+	stderrOverridden := config.Sinks.Stderr != nil
+	func() {
+		sinkRegistry.mu.Lock()
+		defer sinkRegistry.mu.Unlock()
+		sinkRegistry.mu.active = true
+		sinkRegistry.mu.channels = channels
+		sinkRegistry.mu.claimedFiles = claimedFiles
+		sinkRegistry.mu.stderrOverridden = stderrOverridden
+	}()
+
+	// Disable the default outputs superseded by the configuration on
+	// the loggers that exist already. Loggers created afterwards
+	// consult the registry directly.
+	prevMainFileThreshold := mainLog.fileThreshold.get()
+	prevStderrThreshold := mainLog.stderrThreshold.get()
+	if claimedFiles[mainLog.channel] {
+		mainLog.fileThreshold.set(Severity_NONE)
+	}
+	if stderrOverridden {
+		mainLog.stderrThreshold.set(Severity_NONE)
+	}
+	func() {
+		secondaryLogRegistry.mu.Lock()
+		defer secondaryLogRegistry.mu.Unlock()
+		for _, secL := range secondaryLogRegistry.mu.loggers {
+			if claimedFiles[secL.logger.channel] {
+				secL.logger.fileThreshold.set(Severity_NONE)
+			}
+			if stderrOverridden {
+				secL.logger.stderrThreshold.set(Severity_NONE)
+			}
+		}
+	}()
+
+	cleanup := func() {
+		func() {
+			sinkRegistry.mu.Lock()
+			defer sinkRegistry.mu.Unlock()
+			sinkRegistry.mu.active = false
+			sinkRegistry.mu.channels = nil
+			sinkRegistry.mu.claimedFiles = nil
+			sinkRegistry.mu.stderrOverridden = false
+		}()
+
+		mainLog.fileThreshold.set(prevMainFileThreshold)
+		mainLog.stderrThreshold.set(prevStderrThreshold)
+		func() {
+			secondaryLogRegistry.mu.Lock()
+			defer secondaryLogRegistry.mu.Unlock()
+			for _, secL := range secondaryLogRegistry.mu.loggers {
+				// Secondary loggers write all their entries to their file,
+				// and inherit the stderr threshold from the main logger.
+				// See also (*TestLogScope).restoreStderrThreshold().
+				if claimedFiles[secL.logger.channel] {
+					secL.logger.fileThreshold.set(Severity_INFO)
+				}
+				if stderrOverridden {
+					secL.logger.stderrThreshold.set(prevStderrThreshold)
+				}
+			}
+		}()
+
+		closeAll()
+	}
+	return cleanup, nil
+}
+
+// newSinkInfo creates a sinkInfo from the common part of a sink
+// configuration. The caller is responsible for populating the sink.
+func newSinkInfo(c logconfig.CommonSinkConfig) (*sinkInfo, error) {
+	threshold, ok := SeverityByName(c.Filter)
+	if !ok {
+		return nil, errors.Newf("unknown severity: %q", c.Filter)
+	}
+	formatter, ok := formatters[*c.Format]
+	if !ok {
+		return nil, errors.Newf("unknown format: %q", *c.Format)
+	}
+	return &sinkInfo{
+		threshold:   threshold,
+		formatter:   formatter,
+		editor:      getEditor(SelectEditMode(*c.Redact, *c.Redactable)),
+		criticality: *c.ExitOnError,
+	}, nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// applyTestConfig parses, validates and applies a logging
+// configuration. The caller is responsible for calling the returned
+// cleanup function.
+func applyTestConfig(t *testing.T, input string) (cleanup func()) {
+	t.Helper()
+	cfg, err := logconfig.Parse(input)
+	require.NoError(t, err)
+	logDir := mainLog.logDir.String()
+	require.NoError(t, cfg.Validate(&logDir))
+	cleanup, err = ApplyConfig(cfg)
+	require.NoError(t, err)
+	return cleanup
+}
+
+func TestApplyConfigFileGroup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer TestingSetRedactable(true)()
+
+	cleanup := applyTestConfig(t, `
+sinks:
+  file-groups:
+    everything:
+      channels: [default, sql-audit]
+      format: json
+      filter: WARNING
+`)
+	defer func() { cleanup() }()
+
+	ctx := context.Background()
+	Infof(ctx, "not included")
+	Warningf(ctx, "warning on default channel")
+
+	// A secondary logger serving a routed channel does not write to its
+	// default file any more.
+	secCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	audit := NewSecondaryLogger(secCtx, nil, "sql-audit", true, false, true)
+	defer audit.Close()
+	audit.LogSev(ctx, Severity_ERROR, "audit event")
+	Flush()
+
+	require.Equal(t, Severity_NONE, mainLog.fileThreshold.get())
+	require.Equal(t, Severity_NONE, audit.logger.fileThreshold.get())
+
+	sinks := sinksForChannel(logconfig.DefaultChannel)
+	require.Len(t, sinks, 1)
+	fileName := sinks[0].sink.(*fileSink).logger.logger.mu.file.(*syncBuffer).file.Name()
+	require.Contains(t, fileName, "-everything.")
+	contents, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+
+	var channels, messages []string
+	for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+		var decoded struct {
+			Channel string
+			Message string
+			Tags    string
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &decoded), line)
+		if decoded.Tags == "config" {
+			// File header.
+			continue
+		}
+		channels = append(channels, decoded.Channel)
+		messages = append(messages, decoded.Message)
+	}
+	require.Equal(t, []string{"default", "sql-audit"}, channels)
+	// The argument to LogSev is considered unsafe.
+	require.Equal(t, []string{"warning on default channel", "‹audit event›"}, messages)
+
+	// The default outputs are restored by the cleanup.
+	cleanup()
+	cleanup = func() {}
+	require.Equal(t, Severity_INFO, mainLog.fileThreshold.get())
+	require.Equal(t, Severity_INFO, audit.logger.fileThreshold.get())
+}
+
+func TestApplyConfigNetworkSinks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+	defer TestingSetRedactable(true)()
+
+	// A fluent-compatible TCP server.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	fluentLines := make(chan string, 10)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			fluentLines <- scanner.Text()
+		}
+	}()
+
+	// An HTTP server.
+	var httpMu struct {
+		syncutil.Mutex
+		contentTypes []string
+		bodies       []string
+	}
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		httpMu.Lock()
+		defer httpMu.Unlock()
+		httpMu.contentTypes = append(httpMu.contentTypes, r.Header.Get("Content-Type"))
+		httpMu.bodies = append(httpMu.bodies, string(body))
+	}))
+	defer hs.Close()
+
+	cleanup := applyTestConfig(t, fmt.Sprintf(`
+sinks:
+  fluent-servers:
+    local:
+      channels: [default]
+      address: %s
+      filter: WARNING
+  http-servers:
+    collector:
+      channels: [default]
+      address: %s
+      redact: true
+`, l.Addr(), hs.URL))
+	defer cleanup()
+
+	ctx := context.Background()
+	Infof(ctx, "info %s", "hello")
+	Warningf(ctx, "warning %s", "confidential")
+
+	select {
+	case line := <-fluentLines:
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &decoded), line)
+		require.Equal(t, "cockroach.default", decoded["tag"])
+		require.Equal(t, "WARNING", decoded["severity"])
+		require.Equal(t, "warning ‹confidential›", decoded["message"])
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the fluent sink")
+	}
+
+	// The HTTP sink is synchronous.
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	require.Equal(t, []string{"application/json", "application/json"}, httpMu.contentTypes)
+	require.Len(t, httpMu.bodies, 2)
+	require.Contains(t, httpMu.bodies[0], `"severity":"INFO"`)
+	require.Contains(t, httpMu.bodies[0], `"message":"info ‹×›"`)
+	require.Contains(t, httpMu.bodies[1], `"message":"warning ‹×›"`)
+	require.NotContains(t, httpMu.bodies[1], "confidential")
+}
+
+func TestApplyConfigNetworkSinkErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	// Find an address with no server listening.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	// By default, network sink errors do not terminate the process.
+	cleanup := applyTestConfig(t, fmt.Sprintf(`
+sinks:
+  fluent-servers:
+    unavailable:
+      channels: all
+      address: %s
+`, addr))
+	Infof(context.Background(), "entry dropped by the fluent sink")
+	cleanup()
+
+	// Unless exit-on-error is requested.
+	var exitErr error
+	setExitErrFunc(true /* hideStack */, func(_ int, err error) { exitErr = err })
+	defer ResetExitFunc()
+	cleanup = applyTestConfig(t, fmt.Sprintf(`
+sinks:
+  fluent-servers:
+    unavailable:
+      channels: all
+      address: %s
+      exit-on-error: true
+`, addr))
+	defer cleanup()
+	Infof(context.Background(), "entry dropped by the fluent sink")
+	require.Error(t, exitErr)
+	require.Contains(t, exitErr.Error(), "connecting to fluent server")
+}
+
+func TestApplyConfigRejectsRedactableStderr(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	cfg, err := logconfig.Parse(`
+sinks:
+  stderr:
+    redactable: true
+`)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate(nil /* defaultLogDir */))
+	_, err = ApplyConfig(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "cannot enable redactable stderr output")
+
+	// A failed configuration does not prevent a subsequent one.
+	cfg.Sinks.Stderr.Redactable = new(bool)
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+	require.Equal(t, Severity_NONE, mainLog.stderrThreshold.get())
+
+	_, err = ApplyConfig(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already applied")
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package eventpb defines the structured events recorded in the
+// system.eventlog table and emitted on the eventlog logging channel.
+package eventpb
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// EventPayload is implemented by every event type. The common details
+// are populated when the event is recorded.
+type EventPayload interface {
+	CommonDetails() *CommonEventDetails
+}
+
+// EventWithCommonSQLPayload is implemented by the events triggered by a
+// SQL statement.
+type EventWithCommonSQLPayload interface {
+	EventPayload
+	CommonSQLDetails() *CommonSQLEventDetails
+}
+
+// CommonDetails implements the EventPayload interface for all the
+// events that embed CommonEventDetails.
+func (m *CommonEventDetails) CommonDetails() *CommonEventDetails { return m }
+
+// CommonSQLDetails implements the EventWithCommonSQLPayload interface
+// for all the events that embed CommonSQLEventDetails.
+func (m *CommonSQLEventDetails) CommonSQLDetails() *CommonSQLEventDetails { return m }
+
+// GetEventTypeName returns the name of the type of the event, derived
+// from the name of its message: for example "create_table" for
+// CreateTable. This is the value stored in the "eventType" column of
+// system.eventlog.
+func GetEventTypeName(event EventPayload) string {
+	name := reflect.TypeOf(event).Elem().Name()
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package eventpb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEventTypeName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		event    EventPayload
+		expected string
+	}{
+		{&CreateTable{}, "create_table"},
+		{&SetClusterSetting{}, "set_cluster_setting"},
+		{&FinishSchemaChangeRollback{}, "finish_schema_change_rollback"},
+		{&NodeJoin{}, "node_join"},
+		{&JobStateChange{}, "job_state_change"},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, GetEventTypeName(tc.event))
+	}
+}
+
+// TestEventJSONEncoding checks that the fields of the events, including
+// the fields of the embedded common details, are encoded using their Go
+// names as keys, as the events recorded in system.eventlog before they
+// were typed.
+func TestEventJSONEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		event    EventPayload
+		expected string
+	}{
+		{
+			&CreateTable{
+				CommonEventDetails: CommonEventDetails{Timestamp: 123, EventType: "create_table"},
+				CommonSQLEventDetails: CommonSQLEventDetails{
+					Statement:    "CREATE TABLE t (x INT)",
+					User:         "root",
+					DescriptorID: 53,
+				},
+				TableName: "defaultdb.public.t",
+			},
+			`{"Timestamp":123,"EventType":"create_table","Statement":"CREATE TABLE t (x INT)",` +
+				`"User":"root","DescriptorID":53,"TableName":"defaultdb.public.t"}`,
+		},
+		{
+			&CommentOnTable{TableName: "t", NullComment: true},
+			`{"TableName":"t","NullComment":true}`,
+		},
+		{
+			&NodeDecommissioned{
+				CommonNodeDecommissionDetails: CommonNodeDecommissionDetails{
+					RequestingNodeID: 1,
+					TargetNodeID:     2,
+				},
+			},
+			`{"RequestingNodeID":1,"TargetNodeID":2}`,
+		},
+		{
+			&JobStateChange{
+				CommonJobEventDetails: CommonJobEventDetails{
+					JobID:         42,
+					JobType:       "SCHEMA CHANGE",
+					DescriptorIDs: []uint32{53},
+					Status:        "succeeded",
+				},
+				PreviousStatus: "running",
+			},
+			`{"JobID":42,"JobType":"SCHEMA CHANGE","DescriptorIDs":[53],"Status":"succeeded",` +
+				`"PreviousStatus":"running"}`,
+		},
+	}
+	for _, tc := range testCases {
+		b, err := json.Marshal(tc.event)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, string(b))
+	}
+}
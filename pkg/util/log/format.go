@@ -0,0 +1,67 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import "github.com/cockroachdb/ttycolor"
+
+// logFormatter describes a format for log entries.
+type logFormatter interface {
+	// formatterName is the name of the format, as used in the logging
+	// configuration.
+	formatterName() string
+
+	// contentType is the MIME type of the formatted entries, used by
+	// the HTTP sink.
+	contentType() string
+
+	// formatEntry formats an Entry emitted on the given channel into a
+	// newly allocated *buffer. The output must end with a newline. The
+	// caller is responsible for calling putBuffer() afterwards.
+	formatEntry(channel string, entry Entry, stacks []byte) *buffer
+}
+
+// formatters is the registry of the supported log formats, keyed by
+// name. The names must be kept in sync with logconfig.Formats.
+var formatters = func() map[string]logFormatter {
+	m := make(map[string]logFormatter)
+	r := func(f logFormatter) {
+		m[f.formatterName()] = f
+	}
+	r(formatCrdbV1{})
+	r(formatCrdbV1TTY{})
+	r(formatJSON{})
+	r(formatJSON{fluentTag: true})
+	return m
+}()
+
+// formatCrdbV1 is the pre-existing CockroachDB log format, also
+// understood by EntryDecoder.
+type formatCrdbV1 struct{}
+
+func (formatCrdbV1) formatterName() string { return "crdb-v1" }
+
+func (formatCrdbV1) contentType() string { return "text/plain" }
+
+func (formatCrdbV1) formatEntry(_ string, entry Entry, stacks []byte) *buffer {
+	return logging.formatLogEntry(entry, stacks, nil)
+}
+
+// formatCrdbV1TTY is like formatCrdbV1, with VT color codes when the
+// standard error stream is a terminal.
+type formatCrdbV1TTY struct{}
+
+func (formatCrdbV1TTY) formatterName() string { return "crdb-v1-tty" }
+
+func (formatCrdbV1TTY) contentType() string { return "text/plain" }
+
+func (formatCrdbV1TTY) formatEntry(_ string, entry Entry, stacks []byte) *buffer {
+	return logging.formatLogEntry(entry, stacks, ttycolor.StderrProfile)
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// formatJSON renders log entries as JSON objects, one per line.
+//
+// The field names are part of the public interface and must remain
+// stable:
+//
+//   tag         The fluentd tag "cockroach.<channel>" (json-fluent only).
+//   channel     The logging channel the entry was emitted on.
+//   timestamp   The time of the event, as a decimal number of seconds
+//               since the Unix epoch with nanosecond precision, in a string.
+//   severity    The severity name, e.g. "INFO".
+//   goroutine   The goroutine ID (omitted if zero).
+//   file        The source file name.
+//   line        The source line number.
+//   counter     The entry counter (omitted if zero).
+//   redactable  1 if the tags and message contain redaction markers, 0 otherwise.
+//   tags        The context tags, comma-separated (omitted if empty).
+//   message     The log message.
+//   stacks      The goroutine stack traces (omitted if empty).
+type formatJSON struct {
+	// fluentTag, when set, includes the "tag" field used by fluentd
+	// to route events.
+	fluentTag bool
+}
+
+func (f formatJSON) formatterName() string {
+	if f.fluentTag {
+		return "json-fluent"
+	}
+	return "json"
+}
+
+func (formatJSON) contentType() string { return "application/json" }
+
+func (f formatJSON) formatEntry(channel string, entry Entry, stacks []byte) *buffer {
+	if entry.Severity > Severity_FATAL || entry.Severity <= Severity_UNKNOWN {
+		entry.Severity = Severity_INFO // for safety.
+	}
+
+	buf := getBuffer()
+	buf.WriteByte('{')
+	if f.fluentTag {
+		buf.WriteString(`"tag":"cockroach.`)
+		escapeJSONString(buf, channel)
+		buf.WriteString(`",`)
+	}
+	buf.WriteString(`"channel":"`)
+	escapeJSONString(buf, channel)
+	buf.WriteString(`","timestamp":"`)
+	n := buf.someDigits(0, int(entry.Time/1e9))
+	buf.tmp[n] = '.'
+	n++
+	n += buf.nDigits(9, n, int(entry.Time%1e9), '0')
+	buf.Write(buf.tmp[:n])
+	buf.WriteString(`","severity":"`)
+	buf.WriteString(entry.Severity.String())
+	buf.WriteByte('"')
+	if entry.Goroutine > 0 {
+		buf.WriteString(`,"goroutine":`)
+		buf.WriteString(strconv.FormatInt(entry.Goroutine, 10))
+	}
+	buf.WriteString(`,"file":"`)
+	escapeJSONString(buf, entry.File)
+	buf.WriteString(`","line":`)
+	buf.WriteString(strconv.FormatInt(entry.Line, 10))
+	if entry.Counter > 0 {
+		buf.WriteString(`,"counter":`)
+		buf.WriteString(strconv.FormatUint(entry.Counter, 10))
+	}
+	if entry.Redactable {
+		buf.WriteString(`,"redactable":1`)
+	} else {
+		buf.WriteString(`,"redactable":0`)
+	}
+	if entry.Tags != "" {
+		buf.WriteString(`,"tags":"`)
+		escapeJSONString(buf, entry.Tags)
+		buf.WriteByte('"')
+	}
+	buf.WriteString(`,"message":"`)
+	escapeJSONString(buf, entry.Message)
+	buf.WriteByte('"')
+	if len(stacks) > 0 {
+		buf.WriteString(`,"stacks":"`)
+		escapeJSONString(buf, string(stacks))
+		buf.WriteByte('"')
+	}
+	buf.WriteString("}\n")
+	return buf
+}
+
+const hexDigits = "0123456789abcdef"
+
+// escapeJSONString writes s to buf, escaped for inclusion inside a
+// JSON string literal. Invalid UTF-8 sequences are replaced by the
+// Unicode replacement character.
+func escapeJSONString(buf *buffer, s string) {
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`�`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatJSON(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	entry := Entry{
+		Severity:   Severity_WARNING,
+		Time:       1136214245654321000,
+		Goroutine:  11,
+		File:       "util/log/format_json_test.go",
+		Line:       123,
+		Counter:    7,
+		Redactable: true,
+		Tags:       "n1,client=‹127.0.0.1›",
+		Message:    "hello \"world\"\n\ttab\\ \x01 \xff ‹secret›",
+	}
+
+	testData := []struct {
+		formatter logFormatter
+		expected  string
+	}{
+		{formatJSON{},
+			`{"channel":"sql-audit","timestamp":"1136214245.654321000","severity":"WARNING","goroutine":11,` +
+				`"file":"util/log/format_json_test.go","line":123,"counter":7,"redactable":1,` +
+				`"tags":"n1,client=‹127.0.0.1›","message":"hello \"world\"\n\ttab\\ \u0001 � ‹secret›"}` + "\n"},
+		{formatJSON{fluentTag: true},
+			`{"tag":"cockroach.sql-audit","channel":"sql-audit","timestamp":"1136214245.654321000","severity":"WARNING","goroutine":11,` +
+				`"file":"util/log/format_json_test.go","line":123,"counter":7,"redactable":1,` +
+				`"tags":"n1,client=‹127.0.0.1›","message":"hello \"world\"\n\ttab\\ \u0001 � ‹secret›"}` + "\n"},
+	}
+
+	for _, tc := range testData {
+		t.Run(tc.formatter.formatterName(), func(t *testing.T) {
+			buf := tc.formatter.formatEntry("sql-audit", entry, nil)
+			defer putBuffer(buf)
+			require.Equal(t, tc.expected, buf.String())
+
+			// The output must be valid JSON.
+			var decoded map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+			require.Equal(t, "hello \"world\"\n\ttab\\ \x01 � ‹secret›", decoded["message"])
+		})
+	}
+}
+
+func TestFormatJSONOmitsEmptyFields(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	entry := Entry{
+		Severity: Severity_INFO,
+		Time:     5,
+		File:     "a.go",
+		Line:     1,
+		Message:  "hi",
+	}
+	buf := formatJSON{}.formatEntry("default", entry, []byte("goroutine 1 [running]:\n"))
+	defer putBuffer(buf)
+	require.Equal(t,
+		`{"channel":"default","timestamp":"0.000000005","severity":"INFO","file":"a.go","line":1,`+
+			`"redactable":0,"message":"hi","stacks":"goroutine 1 [running]:\n"}`+"\n",
+		buf.String())
+}
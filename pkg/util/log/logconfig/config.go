@@ -0,0 +1,346 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package logconfig defines the declarative (YAML) configuration of
+// the logging sinks. It is kept separate from package log so that the
+// configuration can be parsed and validated by the CLI without
+// activating the logging machinery.
+package logconfig
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultChannel is the name of the channel served by the main
+// logger.
+const DefaultChannel = "default"
+
+// Channels is the list of logging channels that can be routed to
+// sinks. Each channel other than DefaultChannel corresponds to one of
+// the secondary loggers, and has the name of the file prefix that
+// logger writes to when it is not routed elsewhere.
+var Channels = []string{
+	DefaultChannel,
+	"auth",
+	"eventlog",
+	"pebble",
+	"rocksdb",
+	"sql-audit",
+	"sql-exec",
+	"sql-slow",
+	"sql-slow-internal-only",
+}
+
+// Formats is the list of log entry formats supported by the sinks.
+var Formats = []string{
+	"crdb-v1",
+	"crdb-v1-tty",
+	"json",
+	"json-fluent",
+}
+
+// Severities is the list of severity names accepted as sink filters,
+// in increasing order of severity.
+var Severities = []string{"INFO", "WARNING", "ERROR", "FATAL", "NONE"}
+
+// Config represents the top-level logging configuration.
+//
+// An example configuration:
+//
+//   file-defaults:
+//     dir: /var/log/cockroach
+//   sinks:
+//     file-groups:
+//       security:
+//         channels: [auth, sql-audit]
+//         sync-writes: true
+//     fluent-servers:
+//       local:
+//         channels: all
+//         address: 127.0.0.1:5170
+//         filter: WARNING
+//     stderr:
+//       filter: ERROR
+//
+type Config struct {
+	// FileDefaults represents the default configuration for file sinks,
+	// inherited when a specific file sink config does not provide a
+	// configuration value.
+	FileDefaults FileDefaults `yaml:"file-defaults,omitempty"`
+
+	// Sinks represents the sink configurations.
+	Sinks SinkConfig `yaml:",omitempty"`
+}
+
+// SinkConfig represents the sink configurations.
+type SinkConfig struct {
+	// FileGroups represents the list of configured file sinks, keyed by
+	// the name of the file group. The name is used as file name suffix
+	// for the log files.
+	FileGroups map[string]*FileConfig `yaml:"file-groups,omitempty"`
+	// FluentServers represents the list of configured fluent sinks.
+	FluentServers map[string]*FluentConfig `yaml:"fluent-servers,omitempty"`
+	// HTTPServers represents the list of configured HTTP sinks.
+	HTTPServers map[string]*HTTPConfig `yaml:"http-servers,omitempty"`
+	// Stderr represents the configuration for the stderr sink. When
+	// specified, it supersedes the --logtostderr flag.
+	Stderr *StderrConfig `yaml:",omitempty"`
+}
+
+// CommonSinkConfig represents the common configuration shared across
+// all sinks.
+type CommonSinkConfig struct {
+	// Filter specifies the default minimum severity for log events to
+	// be emitted to this sink.
+	Filter string `yaml:",omitempty"`
+
+	// Format indicates the entry format to use.
+	Format *string `yaml:",omitempty"`
+
+	// Redact indicates whether to strip sensitive information before
+	// log events are emitted to this sink.
+	Redact *bool `yaml:",omitempty"`
+
+	// Redactable indicates whether to keep redaction markers in the
+	// sink's output. The presence of redaction markers makes it possible
+	// to strip sensitive data reliably.
+	Redactable *bool `yaml:",omitempty"`
+
+	// ExitOnError indicates whether the process should terminate when
+	// a log entry cannot be written to this sink. When false, the entry
+	// is silently dropped.
+	ExitOnError *bool `yaml:"exit-on-error,omitempty"`
+}
+
+// FileDefaults represent configuration defaults for file sinks.
+type FileDefaults struct {
+	// Dir stores the default output directory for file sinks.
+	Dir *string `yaml:",omitempty"`
+
+	// SyncWrites specifies whether to sync each write to disk. This
+	// makes the sink resistant to data loss upon a crash, at the
+	// expense of write latency.
+	SyncWrites *bool `yaml:"sync-writes,omitempty"`
+
+	// CommonSinkConfig represents the common sink configuration.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// FileConfig represents the configuration for one file sink.
+type FileConfig struct {
+	// Channels is the list of logging channels that are routed to this
+	// sink. The channels routed to a file group stop writing to their
+	// own default log files.
+	Channels ChannelList `yaml:",omitempty"`
+
+	// FileDefaults holds the configuration inherited from the
+	// file-defaults section when not specified.
+	FileDefaults `yaml:",inline"`
+}
+
+// FluentConfig represents the configuration for one fluentd-compatible
+// network sink. Log entries are sent as newline-delimited payloads over
+// a plain network connection.
+type FluentConfig struct {
+	// Channels is the list of logging channels that are routed to this
+	// sink.
+	Channels ChannelList `yaml:",omitempty"`
+
+	// Net is the protocol for the network connection, one of "tcp",
+	// "tcp4", "tcp6", "udp" or "unix". Defaults to "tcp".
+	Net string `yaml:",omitempty"`
+
+	// Address is the network address of the fluent server, in the
+	// format expected by net.Dial for the selected protocol.
+	Address string `yaml:",omitempty"`
+
+	// CommonSinkConfig represents the common sink configuration.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// HTTPConfig represents the configuration for one HTTP sink. Each log
+// entry is sent as the body of a separate POST request.
+type HTTPConfig struct {
+	// Channels is the list of logging channels that are routed to this
+	// sink.
+	Channels ChannelList `yaml:",omitempty"`
+
+	// Address is the URL of the HTTP server to send log entries to.
+	Address string `yaml:",omitempty"`
+
+	// Timeout is the maximum duration of a single HTTP request.
+	// Defaults to 2 seconds. Note that logging calls on the routed
+	// channels wait for the request to complete.
+	Timeout Duration `yaml:",omitempty"`
+
+	// UnsafeTLS disables the verification of the server's TLS
+	// certificate.
+	UnsafeTLS bool `yaml:"unsafe-tls,omitempty"`
+
+	// CommonSinkConfig represents the common sink configuration.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// StderrConfig represents the configuration for the stderr sink.
+type StderrConfig struct {
+	// Channels is the list of logging channels that are routed to
+	// stderr. Defaults to all channels.
+	Channels ChannelList `yaml:",omitempty"`
+
+	// NoColor forces the omission of VT color codes in the output even
+	// when stderr is a terminal.
+	NoColor bool `yaml:"no-color,omitempty"`
+
+	// CommonSinkConfig represents the common sink configuration.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// ChannelList is a list of logging channels. In YAML, it can be
+// specified either as a list of channel names, as a single string of
+// comma-separated channel names, or as the special string "all".
+type ChannelList struct {
+	Channels []string
+}
+
+var _ yaml.Marshaler = ChannelList{}
+var _ yaml.Unmarshaler = &ChannelList{}
+
+// MarshalYAML implements yaml.Marshaler.
+func (c ChannelList) MarshalYAML() (interface{}, error) {
+	return c.Channels, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *ChannelList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var names []string
+	var s string
+	if err := unmarshal(&s); err == nil {
+		if strings.TrimSpace(strings.ToLower(s)) == "all" {
+			c.Channels = append([]string(nil), Channels...)
+			return nil
+		}
+		names = strings.Split(s, ",")
+	} else if err := unmarshal(&names); err != nil {
+		return err
+	}
+	c.Channels = nil
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		c.Channels = append(c.Channels, name)
+	}
+	return nil
+}
+
+// Contains returns true iff the channel list includes the given
+// channel.
+func (c ChannelList) Contains(ch string) bool {
+	for _, name := range c.Channels {
+		if name == ch {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements the fmt.Stringer interface.
+func (c ChannelList) String() string {
+	return strings.Join(c.Channels, ",")
+}
+
+// Duration is a time.Duration that can be specified in YAML using the
+// syntax accepted by time.ParseDuration.
+type Duration time.Duration
+
+var _ yaml.Marshaler = Duration(0)
+var _ yaml.Unmarshaler = (*Duration)(nil)
+
+// MarshalYAML implements yaml.Marshaler.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Parse parses a YAML logging configuration. Unknown fields are
+// rejected. The resulting configuration is not validated yet; see
+// Validate().
+func Parse(input string) (*Config, error) {
+	c := &Config{}
+	if err := yaml.UnmarshalStrict([]byte(input), c); err != nil {
+		return nil, errors.Wrap(err, "parsing logging configuration")
+	}
+	return c, nil
+}
+
+// String renders the configuration as YAML.
+func (c *Config) String() string {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return "<error: " + err.Error() + ">"
+	}
+	return string(b)
+}
+
+// FileGroupNames returns the names of the configured file groups,
+// in sorted order.
+func (c *Config) FileGroupNames() []string {
+	return sortedKeys(len(c.Sinks.FileGroups), func(f func(string)) {
+		for name := range c.Sinks.FileGroups {
+			f(name)
+		}
+	})
+}
+
+// FluentServerNames returns the names of the configured fluent
+// servers, in sorted order.
+func (c *Config) FluentServerNames() []string {
+	return sortedKeys(len(c.Sinks.FluentServers), func(f func(string)) {
+		for name := range c.Sinks.FluentServers {
+			f(name)
+		}
+	})
+}
+
+// HTTPServerNames returns the names of the configured HTTP servers, in
+// sorted order.
+func (c *Config) HTTPServerNames() []string {
+	return sortedKeys(len(c.Sinks.HTTPServers), func(f func(string)) {
+		for name := range c.Sinks.HTTPServers {
+			f(name)
+		}
+	})
+}
+
+func sortedKeys(n int, visit func(func(string))) []string {
+	res := make([]string, 0, n)
+	visit(func(name string) { res = append(res, name) })
+	sort.Strings(res)
+	return res
+}
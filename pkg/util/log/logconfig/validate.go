@@ -0,0 +1,268 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package logconfig
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// DefaultHTTPTimeout is the default timeout for requests to HTTP
+// sinks.
+const DefaultHTTPTimeout = 2 * time.Second
+
+// Validate checks the configuration and populates the fields left
+// unspecified with their defaults. The defaultLogDir, if non-nil, is
+// used as output directory for file sinks when file-defaults does not
+// specify one.
+func (c *Config) Validate(defaultLogDir *string) error {
+	fd := &c.FileDefaults
+	if fd.Dir == nil {
+		fd.Dir = defaultLogDir
+	}
+	if fd.Dir != nil && *fd.Dir != "" {
+		absDir, err := filepath.Abs(*fd.Dir)
+		if err != nil {
+			return errors.Wrap(err, "file-defaults")
+		}
+		fd.Dir = &absDir
+	}
+	propagateDefaults(&fd.CommonSinkConfig, CommonSinkConfig{
+		Filter:      "INFO",
+		Format:      strPtr("crdb-v1"),
+		Redact:      boolPtr(false),
+		Redactable:  boolPtr(true),
+		ExitOnError: boolPtr(true),
+	})
+	if fd.SyncWrites == nil {
+		fd.SyncWrites = boolPtr(false)
+	}
+	if err := validateCommon(&fd.CommonSinkConfig); err != nil {
+		return errors.Wrap(err, "file-defaults")
+	}
+
+	// claimedBy remembers which file group a channel is routed to, to
+	// reject configurations where a channel's output file is ambiguous.
+	claimedBy := map[string]string{}
+	for _, name := range c.FileGroupNames() {
+		fc := c.Sinks.FileGroups[name]
+		if err := c.validateFileConfig(name, fc, claimedBy); err != nil {
+			return errors.Wrapf(err, "file group %q", name)
+		}
+	}
+	for _, name := range c.FluentServerNames() {
+		if err := validateFluentConfig(c.Sinks.FluentServers[name]); err != nil {
+			return errors.Wrapf(err, "fluent server %q", name)
+		}
+	}
+	for _, name := range c.HTTPServerNames() {
+		if err := validateHTTPConfig(c.Sinks.HTTPServers[name]); err != nil {
+			return errors.Wrapf(err, "http server %q", name)
+		}
+	}
+	if c.Sinks.Stderr != nil {
+		if err := validateStderrConfig(c.Sinks.Stderr); err != nil {
+			return errors.Wrap(err, "stderr")
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateFileConfig(
+	name string, fc *FileConfig, claimedBy map[string]string,
+) error {
+	if fc == nil {
+		return errors.New("empty configuration")
+	}
+	if name == "" || strings.ContainsAny(name, "./\\") {
+		return errors.New("file group name must be non-empty and cannot contain path separators or periods")
+	}
+	if err := validateChannels(fc.Channels, false /* defaultAll */); err != nil {
+		return err
+	}
+	if name == "stderr" || (contains(Channels, name) && !fc.Channels.Contains(name)) {
+		// The files of the group would be confused with the default
+		// files of another logger.
+		return errors.New("file group name conflicts with the default log files of another channel")
+	}
+	for _, ch := range fc.Channels.Channels {
+		if other, ok := claimedBy[ch]; ok {
+			return errors.Newf("channel %q is already routed to file group %q", ch, other)
+		}
+		claimedBy[ch] = name
+	}
+
+	fd := &c.FileDefaults
+	if fc.Dir == nil {
+		fc.Dir = fd.Dir
+	}
+	if fc.Dir == nil || *fc.Dir == "" {
+		return errors.WithHint(errors.New("no output directory configured"),
+			"Specify a directory using 'dir' in the file group or in file-defaults.")
+	}
+	absDir, err := filepath.Abs(*fc.Dir)
+	if err != nil {
+		return err
+	}
+	fc.Dir = &absDir
+	if fc.SyncWrites == nil {
+		fc.SyncWrites = fd.SyncWrites
+	}
+	propagateDefaults(&fc.CommonSinkConfig, fd.CommonSinkConfig)
+	return validateCommon(&fc.CommonSinkConfig)
+}
+
+func validateFluentConfig(fc *FluentConfig) error {
+	if fc == nil {
+		return errors.New("empty configuration")
+	}
+	if err := validateChannels(fc.Channels, false /* defaultAll */); err != nil {
+		return err
+	}
+	switch fc.Net {
+	case "":
+		fc.Net = "tcp"
+	case "tcp", "tcp4", "tcp6", "udp", "unix":
+	default:
+		return errors.Newf("unsupported network protocol: %q", fc.Net)
+	}
+	if fc.Address == "" {
+		return errors.New("address must be specified")
+	}
+	propagateDefaults(&fc.CommonSinkConfig, CommonSinkConfig{
+		Filter:      "INFO",
+		Format:      strPtr("json-fluent"),
+		Redact:      boolPtr(false),
+		Redactable:  boolPtr(true),
+		ExitOnError: boolPtr(false),
+	})
+	return validateCommon(&fc.CommonSinkConfig)
+}
+
+func validateHTTPConfig(hc *HTTPConfig) error {
+	if hc == nil {
+		return errors.New("empty configuration")
+	}
+	if err := validateChannels(hc.Channels, false /* defaultAll */); err != nil {
+		return err
+	}
+	if hc.Address == "" {
+		return errors.New("address must be specified")
+	}
+	u, err := url.Parse(hc.Address)
+	if err != nil {
+		return errors.Wrap(err, "invalid address")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Newf("unsupported URL scheme: %q", u.Scheme)
+	}
+	if hc.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	if hc.Timeout == 0 {
+		hc.Timeout = Duration(DefaultHTTPTimeout)
+	}
+	propagateDefaults(&hc.CommonSinkConfig, CommonSinkConfig{
+		Filter:      "INFO",
+		Format:      strPtr("json"),
+		Redact:      boolPtr(false),
+		Redactable:  boolPtr(true),
+		ExitOnError: boolPtr(false),
+	})
+	return validateCommon(&hc.CommonSinkConfig)
+}
+
+func validateStderrConfig(sc *StderrConfig) error {
+	if err := validateChannels(sc.Channels, true /* defaultAll */); err != nil {
+		return err
+	}
+	if len(sc.Channels.Channels) == 0 {
+		sc.Channels.Channels = append([]string(nil), Channels...)
+	}
+	propagateDefaults(&sc.CommonSinkConfig, CommonSinkConfig{
+		Filter: "INFO",
+		Format: strPtr("crdb-v1-tty"),
+		Redact: boolPtr(false),
+		// Redaction markers are not emitted on stderr by default: they
+		// are not safe unless direct writes to the stderr file
+		// descriptor are captured separately.
+		Redactable:  boolPtr(false),
+		ExitOnError: boolPtr(true),
+	})
+	return validateCommon(&sc.CommonSinkConfig)
+}
+
+func validateChannels(c ChannelList, defaultAll bool) error {
+	if len(c.Channels) == 0 {
+		if defaultAll {
+			return nil
+		}
+		return errors.WithHintf(errors.New("no channels specified"),
+			"Valid channels: %s, or \"all\".", strings.Join(Channels, ", "))
+	}
+	for _, ch := range c.Channels {
+		if !contains(Channels, ch) {
+			return errors.WithHintf(errors.Newf("unknown channel: %q", ch),
+				"Valid channels: %s.", strings.Join(Channels, ", "))
+		}
+	}
+	return nil
+}
+
+func validateCommon(c *CommonSinkConfig) error {
+	c.Filter = strings.ToUpper(c.Filter)
+	if !contains(Severities, c.Filter) {
+		return errors.WithHintf(errors.Newf("unknown severity: %q", c.Filter),
+			"Valid severities: %s.", strings.Join(Severities, ", "))
+	}
+	if !contains(Formats, *c.Format) {
+		return errors.WithHintf(errors.Newf("unknown format: %q", *c.Format),
+			"Valid formats: %s.", strings.Join(Formats, ", "))
+	}
+	return nil
+}
+
+// propagateDefaults populates the unspecified fields in c using the
+// values in defaults.
+func propagateDefaults(c *CommonSinkConfig, defaults CommonSinkConfig) {
+	if c.Filter == "" {
+		c.Filter = defaults.Filter
+	}
+	if c.Format == nil {
+		c.Format = defaults.Format
+	}
+	if c.Redact == nil {
+		c.Redact = defaults.Redact
+	}
+	if c.Redactable == nil {
+		c.Redactable = defaults.Redactable
+	}
+	if c.ExitOnError == nil {
+		c.ExitOnError = defaults.ExitOnError
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func strPtr(s string) *string { return &s }
+
+func boolPtr(b bool) *bool { return &b }
@@ -0,0 +1,230 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package logconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDefaults(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c, err := Parse(`
+sinks:
+  file-groups:
+    security:
+      channels: auth, sql-audit
+      sync-writes: true
+  fluent-servers:
+    local:
+      channels: all
+      address: 127.0.0.1:5170
+      filter: warning
+  http-servers:
+    collector:
+      channels: [sql-exec]
+      address: https://example.com/logs
+      redact: true
+  stderr:
+    filter: ERROR
+`)
+	require.NoError(t, err)
+	defaultDir := "/tmp/logs"
+	require.NoError(t, c.Validate(&defaultDir))
+
+	fg := c.Sinks.FileGroups["security"]
+	require.Equal(t, []string{"auth", "sql-audit"}, fg.Channels.Channels)
+	require.Equal(t, "/tmp/logs", *fg.Dir)
+	require.True(t, *fg.SyncWrites)
+	require.Equal(t, "INFO", fg.Filter)
+	require.Equal(t, "crdb-v1", *fg.Format)
+	require.True(t, *fg.Redactable)
+	require.True(t, *fg.ExitOnError)
+
+	fs := c.Sinks.FluentServers["local"]
+	require.Equal(t, Channels, fs.Channels.Channels)
+	require.Equal(t, "tcp", fs.Net)
+	require.Equal(t, "WARNING", fs.Filter)
+	require.Equal(t, "json-fluent", *fs.Format)
+	require.False(t, *fs.ExitOnError)
+
+	hs := c.Sinks.HTTPServers["collector"]
+	require.Equal(t, "json", *hs.Format)
+	require.Equal(t, Duration(DefaultHTTPTimeout), hs.Timeout)
+	require.True(t, *hs.Redact)
+
+	se := c.Sinks.Stderr
+	require.Equal(t, Channels, se.Channels.Channels)
+	require.Equal(t, "ERROR", se.Filter)
+	require.Equal(t, "crdb-v1-tty", *se.Format)
+	require.False(t, *se.Redactable)
+}
+
+func TestValidateFileDefaultsInheritance(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c, err := Parse(`
+file-defaults:
+  dir: /var/log/crdb
+  format: json
+  filter: WARNING
+sinks:
+  file-groups:
+    sql:
+      channels: [sql-exec, sql-slow]
+    storage:
+      channels: [pebble]
+      dir: /mnt/logs
+      format: crdb-v1
+  http-servers:
+    collector:
+      channels: [default]
+      address: http://localhost:8080
+      timeout: 500ms
+`)
+	require.NoError(t, err)
+	require.NoError(t, c.Validate(nil /* defaultLogDir */))
+
+	sql := c.Sinks.FileGroups["sql"]
+	require.Equal(t, "/var/log/crdb", *sql.Dir)
+	require.Equal(t, "json", *sql.Format)
+	require.Equal(t, "WARNING", sql.Filter)
+
+	storage := c.Sinks.FileGroups["storage"]
+	require.Equal(t, "/mnt/logs", *storage.Dir)
+	require.Equal(t, "crdb-v1", *storage.Format)
+	require.Equal(t, "WARNING", storage.Filter)
+
+	require.Equal(t, Duration(500*time.Millisecond), c.Sinks.HTTPServers["collector"].Timeout)
+}
+
+func TestValidateErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		input  string
+		expErr string
+	}{
+		{`unknown: 123`, `field unknown not found`},
+		{`
+sinks:
+  file-groups:
+    a:
+      channels: [default]`, `no output directory configured`},
+		{`
+file-defaults: {dir: /tmp}
+sinks:
+  file-groups:
+    a:
+      channels: []`, `no channels specified`},
+		{`
+file-defaults: {dir: /tmp}
+sinks:
+  file-groups:
+    a:
+      channels: [woo]`, `unknown channel: "woo"`},
+		{`
+file-defaults: {dir: /tmp}
+sinks:
+  file-groups:
+    a:
+      channels: [auth]
+    b:
+      channels: [auth, default]`, `channel "auth" is already routed to file group "a"`},
+		{`
+file-defaults: {dir: /tmp}
+sinks:
+  file-groups:
+    a.b:
+      channels: [auth]`, `cannot contain path separators`},
+		{`
+file-defaults: {dir: /tmp}
+sinks:
+  file-groups:
+    auth:
+      channels: [sql-audit]`, `conflicts with the default log files of another channel`},
+		{`
+file-defaults: {dir: /tmp}
+sinks:
+  file-groups:
+    stderr:
+      channels: [default]`, `conflicts with the default log files of another channel`},
+		{`
+sinks:
+  fluent-servers:
+    a:
+      channels: all`, `address must be specified`},
+		{`
+sinks:
+  fluent-servers:
+    a:
+      channels: all
+      net: sctp
+      address: localhost:123`, `unsupported network protocol: "sctp"`},
+		{`
+sinks:
+  http-servers:
+    a:
+      channels: all
+      address: ftp://localhost`, `unsupported URL scheme: "ftp"`},
+		{`
+sinks:
+  http-servers:
+    a:
+      channels: all
+      address: http://localhost
+      timeout: abc`, `invalid duration`},
+		{`
+sinks:
+  stderr:
+    filter: LOUD`, `unknown severity: "LOUD"`},
+		{`
+sinks:
+  stderr:
+    format: xml`, `unknown format: "xml"`},
+	}
+
+	for _, tc := range testData {
+		t.Run("", func(t *testing.T) {
+			c, err := Parse(tc.input)
+			if err == nil {
+				err = c.Validate(nil /* defaultLogDir */)
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expErr)
+		})
+	}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c, err := Parse(`
+sinks:
+  stderr:
+    channels: [default, auth]
+    no-color: true
+`)
+	require.NoError(t, err)
+	require.NoError(t, c.Validate(nil /* defaultLogDir */))
+
+	c2, err := Parse(c.String())
+	require.NoError(t, err)
+	require.NoError(t, c2.Validate(nil /* defaultLogDir */))
+	require.Equal(t, c.String(), c2.String())
+	require.True(t, c2.Sinks.Stderr.NoColor)
+	require.True(t, c2.Sinks.Stderr.Channels.Contains("auth"))
+	require.False(t, c2.Sinks.Stderr.Channels.Contains("pebble"))
+}
@@ -12,7 +12,9 @@ package log
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 )
 
@@ -66,6 +68,7 @@ func NewSecondaryLogger(
 	}
 	l := &SecondaryLogger{
 		logger: loggerT{
+			channel:         fileNamePrefix,
 			logDir:          DirName{name: dir},
 			prefix:          program + "-" + fileNamePrefix,
 			fileThreshold:   Severity_INFO,
@@ -76,6 +79,11 @@ func NewSecondaryLogger(
 		forceSyncWrites: forceSyncWrites,
 	}
 	l.logger.redactableLogs.Set(mainLog.redactableLogs.Get())
+	if channelFileClaimed(fileNamePrefix) {
+		// The channel was routed to a file group by the logging
+		// configuration; its default file is not used.
+		l.logger.fileThreshold = Severity_NONE
+	}
 	l.logger.mu.syncWrites = forceSyncWrites || mainLog.mu.syncWrites
 
 	// Ensure the registry knows about this logger.
@@ -130,5 +138,18 @@ func (l *SecondaryLogger) LogfDepth(
 
 // LogSev logs an event at the specified severity on a secondary logger.
 func (l *SecondaryLogger) LogSev(ctx context.Context, sev Severity, args ...interface{}) {
-	l.output(ctx, 1, Severity_INFO, "", args...)
+	l.output(ctx, 1, sev, "", args...)
+}
+
+// StructuredEvent emits a structured event on a secondary logger. The
+// event is encoded in JSON, using the same encoding as the info column
+// of system.eventlog.
+func (l *SecondaryLogger) StructuredEvent(ctx context.Context, event eventpb.EventPayload) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		l.output(ctx, 1, Severity_WARNING, "unable to encode event %s: %v",
+			Safe(eventpb.GetEventTypeName(event)), err)
+		return
+	}
+	l.output(ctx, 1, Severity_INFO, "Structured event: %s", string(b))
 }
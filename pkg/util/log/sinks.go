@@ -0,0 +1,167 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// logSink abstracts the destination of formatted log entries
+// configured via ApplyConfig().
+type logSink interface {
+	// output emits some formatted bytes to this sink. The data
+	// includes the final newline.
+	output(b []byte) error
+
+	// close releases the resources held by the sink.
+	close()
+}
+
+// sinkInfo represents a configured log sink, together with the
+// filtering, redaction and formatting parameters that apply to it.
+type sinkInfo struct {
+	sink logSink
+
+	// threshold is the minimum severity of the entries emitted to
+	// the sink.
+	threshold Severity
+
+	// formatter renders the entries for this sink.
+	formatter logFormatter
+
+	// editor applies the redaction policy of the sink to the tags and
+	// message of each entry.
+	editor redactEditor
+
+	// criticality indicates whether a failure to output an entry to
+	// this sink should terminate the process.
+	criticality bool
+}
+
+// format applies the redaction policy of the sink to the entry, then
+// formats it. The caller is responsible for calling putBuffer()
+// afterwards.
+func (s *sinkInfo) format(channel string, entry Entry, stacks []byte) *buffer {
+	msg := s.editor(redactablePackage{msg: []byte(entry.Message), redactable: entry.Redactable})
+	if entry.Tags != "" {
+		tags := s.editor(redactablePackage{msg: []byte(entry.Tags), redactable: entry.Redactable})
+		entry.Tags = string(tags.msg)
+	}
+	entry.Message = string(msg.msg)
+	entry.Redactable = msg.redactable
+	return s.formatter.formatEntry(channel, entry, stacks)
+}
+
+// sinkRegistry holds the routing of logging channels to the sinks
+// configured via ApplyConfig().
+var sinkRegistry struct {
+	mu struct {
+		syncutil.RWMutex
+
+		// active is set while a configuration is applied.
+		active bool
+
+		// channels maps each channel name to the sinks that receive its
+		// entries. The slices are never modified in place, so they can
+		// be used after the mutex is released.
+		channels map[string][]*sinkInfo
+
+		// claimedFiles is the set of channels that were routed to a file
+		// group, and thus do not write to their default log file any
+		// more.
+		claimedFiles map[string]bool
+
+		// stderrOverridden is set when a stderr sink was configured; in
+		// that case, the stderr thresholds of the loggers are disabled
+		// and the stderr output is handled by the sink instead.
+		stderrOverridden bool
+	}
+}
+
+// sinksForChannel returns the sinks configured for the given channel.
+func sinksForChannel(channel string) []*sinkInfo {
+	sinkRegistry.mu.RLock()
+	defer sinkRegistry.mu.RUnlock()
+	return sinkRegistry.mu.channels[channel]
+}
+
+// channelFileClaimed returns true if the given channel was routed to
+// a file group, in which case the logger serving the channel must not
+// write to its default file.
+func channelFileClaimed(channel string) bool {
+	sinkRegistry.mu.RLock()
+	defer sinkRegistry.mu.RUnlock()
+	return sinkRegistry.mu.claimedFiles[channel]
+}
+
+// outputToSinksLocked emits the entry to the sinks configured for
+// the logger's channel. An error is returned only if a critical sink
+// failed.
+//
+// l.mu is held.
+func (l *loggerT) outputToSinksLocked(entry Entry, stacks []byte) error {
+	for _, s := range sinksForChannel(l.channel) {
+		if entry.Severity < s.threshold {
+			continue
+		}
+		buf := s.format(l.channel, entry, stacks)
+		err := s.sink.output(buf.Bytes())
+		putBuffer(buf)
+		if err != nil && s.criticality {
+			return err
+		}
+	}
+	return nil
+}
+
+// stderrSink writes log entries to the process' external stderr
+// stream (OrigStderr).
+type stderrSink struct{}
+
+var _ logSink = stderrSink{}
+
+func (stderrSink) output(b []byte) error {
+	_, err := OrigStderr.Write(b)
+	return err
+}
+
+func (stderrSink) close() {}
+
+// fileSink writes log entries to the files of a file group. It relies
+// on a dedicated secondary logger to manage the files, which ensures
+// the files get flushed, rotated and garbage collected like those of
+// other loggers.
+type fileSink struct {
+	logger *SecondaryLogger
+}
+
+var _ logSink = (*fileSink)(nil)
+
+func (f *fileSink) output(b []byte) error {
+	l := &f.logger.logger
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.ensureFileLocked(); err != nil {
+		return err
+	}
+	return l.writeToFileLocked(b)
+}
+
+func (f *fileSink) close() {
+	f.logger.Close()
+	l := &f.logger.logger
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushAndSyncLocked(true /* doSync */)
+	// We are abandoning the logger; there is nothing left to do if the
+	// file cannot be closed cleanly.
+	_ = l.closeFileLocked()
+}
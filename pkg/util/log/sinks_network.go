@@ -0,0 +1,133 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// fluentDialTimeout is the maximum amount of time spent establishing
+// a connection to a fluent server.
+const fluentDialTimeout = 2 * time.Second
+
+// fluentWriteTimeout is the maximum amount of time spent sending a
+// single log entry to a fluent server.
+const fluentWriteTimeout = 2 * time.Second
+
+// fluentSink sends log entries over a network connection to a fluentd
+// (or compatible) server, one entry per line. The connection is
+// established lazily and re-established after errors.
+//
+// Note: the sink must not use the logging package itself, since it
+// runs with the mutex of the calling logger held.
+type fluentSink struct {
+	network string
+	addr    string
+
+	mu struct {
+		syncutil.Mutex
+		conn net.Conn
+	}
+}
+
+var _ logSink = (*fluentSink)(nil)
+
+func newFluentSink(network, addr string) *fluentSink {
+	return &fluentSink{network: network, addr: addr}
+}
+
+func (f *fluentSink) output(b []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.mu.conn == nil {
+		conn, err := net.DialTimeout(f.network, f.addr, fluentDialTimeout)
+		if err != nil {
+			return errors.Wrapf(err, "connecting to fluent server %s", f.addr)
+		}
+		f.mu.conn = conn
+	}
+	_ = f.mu.conn.SetWriteDeadline(timeutil.Now().Add(fluentWriteTimeout))
+	if _, err := f.mu.conn.Write(b); err != nil {
+		// Drop the connection; the next entry will reconnect.
+		f.closeLocked()
+		return errors.Wrapf(err, "writing to fluent server %s", f.addr)
+	}
+	return nil
+}
+
+func (f *fluentSink) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closeLocked()
+}
+
+func (f *fluentSink) closeLocked() {
+	if f.mu.conn != nil {
+		_ = f.mu.conn.Close()
+		f.mu.conn = nil
+	}
+}
+
+// httpSink sends each log entry as the body of a POST request to an
+// HTTP server.
+//
+// Note: the sink must not use the logging package itself, since it
+// runs with the mutex of the calling logger held.
+type httpSink struct {
+	client      *http.Client
+	address     string
+	contentType string
+}
+
+var _ logSink = (*httpSink)(nil)
+
+func newHTTPSink(address string, timeout time.Duration, unsafeTLS bool, contentType string) *httpSink {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if unsafeTLS {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &httpSink{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+		address:     address,
+		contentType: contentType,
+	}
+}
+
+func (h *httpSink) output(b []byte) error {
+	resp, err := h.client.Post(h.address, h.contentType, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	// Drain the response so that the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Newf("log sink %s: unexpected HTTP status %s", h.address, resp.Status)
+	}
+	return nil
+}
+
+func (h *httpSink) close() {
+	h.client.CloseIdleConnections()
+}
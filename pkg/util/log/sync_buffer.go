@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/errors"
 )
@@ -258,13 +259,25 @@ func (l *loggerT) initializeNewOutputFile(
 	}
 	logging.mu.Unlock()
 
-	// Including a non-ascii character in the first 1024 bytes of the log helps
-	// viewers that attempt to guess the character encoding.
-	messages = append(messages,
-		l.makeStartLine("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg utf8=\u2713"))
+	formatter := l.fileFormat
+	if formatter == nil {
+		formatter = formatCrdbV1{}
+	}
+	if _, ok := formatter.(formatCrdbV1); ok {
+		// Including a non-ascii character in the first 1024 bytes of the log helps
+		// viewers that attempt to guess the character encoding.
+		messages = append(messages,
+			l.makeStartLine("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg utf8=\u2713"))
+	}
 
+	// The file headers of file groups are not emitted on any particular
+	// channel; we report them as coming from the default channel.
+	channel := l.channel
+	if channel == "" {
+		channel = logconfig.DefaultChannel
+	}
 	for _, entry := range messages {
-		buf := logging.formatLogEntry(entry, nil, nil)
+		buf := formatter.formatEntry(channel, entry, nil)
 		var n int
 		n, err = file.Write(buf.Bytes())
 		putBuffer(buf)
@@ -44,7 +44,7 @@ func MakePrometheusExporter() PrometheusExporter {
 func (pm *PrometheusExporter) findOrCreateFamily(
 	prom PrometheusExportable,
 ) *prometheusgo.MetricFamily {
-	familyName := exportedName(prom.GetName())
+	familyName := ExportedName(prom.GetName())
 	if family, ok := pm.families[familyName]; ok {
 		return family
 	}
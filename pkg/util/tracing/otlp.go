@@ -0,0 +1,297 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	proto "github.com/gogo/protobuf/proto"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// This file implements the encoding of the OpenTelemetry protocol (OTLP)
+// trace export requests. Only the subset of the messages that we produce is
+// supported; the field numbers are those of the opentelemetry-proto
+// definitions:
+//
+//   ExportTraceServiceRequest  { repeated ResourceSpans resource_spans = 1; }
+//   ResourceSpans              { Resource resource = 1;
+//                                repeated InstrumentationLibrarySpans spans = 2; }
+//   Resource                   { repeated KeyValue attributes = 1; }
+//   InstrumentationLibrarySpans{ InstrumentationLibrary library = 1;
+//                                repeated Span spans = 2; }
+//   InstrumentationLibrary     { string name = 1; string version = 2; }
+//   Span                       { bytes trace_id = 1; bytes span_id = 2;
+//                                bytes parent_span_id = 4; string name = 5;
+//                                SpanKind kind = 6;
+//                                fixed64 start_time_unix_nano = 7;
+//                                fixed64 end_time_unix_nano = 8;
+//                                repeated KeyValue attributes = 9;
+//                                repeated Event events = 11;
+//                                Status status = 15; }
+//   Span.Event                 { fixed64 time_unix_nano = 1; string name = 2;
+//                                repeated KeyValue attributes = 3; }
+//   Status                     { string message = 2; StatusCode code = 3; }
+//   KeyValue                   { string key = 1; AnyValue value = 2; }
+//   AnyValue                   { string string_value = 1; }
+//
+// Hand-encoding the messages saves us from depending on the OpenTelemetry
+// SDK and its generated protos for what amounts to a couple hundred bytes per
+// span.
+
+// otlpTraceExportMethod is the gRPC method of the OTLP trace service.
+const otlpTraceExportMethod = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+
+// otlpInstrumentationLibrary is the name of the instrumentation library
+// reported with the exported spans.
+const otlpInstrumentationLibrary = "github.com/cockroachdb/cockroach/pkg/util/tracing"
+
+// Values of the OTLP SpanKind enum.
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+	otlpSpanKindClient   = 3
+	otlpSpanKindProducer = 4
+	otlpSpanKindConsumer = 5
+)
+
+// otlpStatusCodeError is the value of the OTLP StatusCode enum for failed
+// operations.
+const otlpStatusCodeError = 2
+
+// otlpEventName is the name given to the OTLP events created from log records
+// that don't have a LogMessageField.
+const otlpEventName = "log"
+
+// otlpExportRequest is an OTLP ExportTraceServiceRequest, built from recorded
+// spans.
+//
+// It implements the interfaces required by the gRPC proto codec. Only
+// marshaling is implemented: we never receive these requests.
+type otlpExportRequest struct {
+	// resource contains the attributes describing the process producing the
+	// spans (e.g. service.name).
+	resource map[string]string
+	spans    []RecordedSpan
+}
+
+// Reset is part of the proto.Message interface.
+func (r *otlpExportRequest) Reset() { *r = otlpExportRequest{} }
+
+// String is part of the proto.Message interface.
+func (r *otlpExportRequest) String() string {
+	return fmt.Sprintf("OTLP export request (%d spans)", len(r.spans))
+}
+
+// ProtoMessage is part of the proto.Message interface.
+func (*otlpExportRequest) ProtoMessage() {}
+
+// Marshal encodes the request in the protobuf wire format.
+func (r *otlpExportRequest) Marshal() ([]byte, error) {
+	var e otlpEncoder
+	e.message(1 /* resource_spans */, func(e *otlpEncoder) {
+		e.message(1 /* resource */, func(e *otlpEncoder) {
+			e.attributes(1 /* attributes */, r.resource, nil /* skip */)
+		})
+		e.message(2 /* instrumentation_library_spans */, func(e *otlpEncoder) {
+			e.message(1 /* instrumentation_library */, func(e *otlpEncoder) {
+				e.string(1 /* name */, otlpInstrumentationLibrary)
+			})
+			for i := range r.spans {
+				e.message(2 /* spans */, func(e *otlpEncoder) {
+					e.span(&r.spans[i])
+				})
+			}
+		})
+	})
+	return e.Bytes(), nil
+}
+
+// otlpExportResponse is an OTLP ExportTraceServiceResponse. Its contents are
+// ignored.
+type otlpExportResponse struct{}
+
+// Reset is part of the proto.Message interface.
+func (r *otlpExportResponse) Reset() {}
+
+// String is part of the proto.Message interface.
+func (r *otlpExportResponse) String() string { return "OTLP export response" }
+
+// ProtoMessage is part of the proto.Message interface.
+func (*otlpExportResponse) ProtoMessage() {}
+
+// Unmarshal is used by the gRPC codec.
+func (*otlpExportResponse) Unmarshal([]byte) error { return nil }
+
+// otlpEncoder extends proto.Buffer with helpers for the field types used by
+// OTLP. The errors returned by proto.Buffer are ignored: encoding into a
+// memory buffer cannot fail.
+type otlpEncoder struct {
+	proto.Buffer
+}
+
+func (e *otlpEncoder) key(field int, wireType int) {
+	_ = e.EncodeVarint(uint64(field)<<3 | uint64(wireType))
+}
+
+// bytes encodes a bytes field. Empty values are omitted, like proto3 does.
+func (e *otlpEncoder) bytes(field int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.key(field, proto.WireBytes)
+	_ = e.EncodeRawBytes(b)
+}
+
+// string encodes a string field. Empty values are omitted, like proto3 does.
+func (e *otlpEncoder) string(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.key(field, proto.WireBytes)
+	_ = e.EncodeStringBytes(s)
+}
+
+// varint encodes an integer or enum field. Zero values are omitted, like
+// proto3 does.
+func (e *otlpEncoder) varint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.key(field, proto.WireVarint)
+	_ = e.EncodeVarint(v)
+}
+
+// fixed64 encodes a fixed64 field. Zero values are omitted, like proto3 does.
+func (e *otlpEncoder) fixed64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.key(field, proto.WireFixed64)
+	_ = e.EncodeFixed64(v)
+}
+
+// message encodes an embedded message field whose contents are produced by
+// fn. The message is encoded even if it is empty.
+func (e *otlpEncoder) message(field int, fn func(e *otlpEncoder)) {
+	var sub otlpEncoder
+	fn(&sub)
+	e.key(field, proto.WireBytes)
+	_ = e.EncodeRawBytes(sub.Bytes())
+}
+
+// attributes encodes a repeated KeyValue field with string values. The
+// attributes are sorted by key, so that the encoding is deterministic. Keys
+// for which skip returns true are omitted.
+func (e *otlpEncoder) attributes(field int, attrs map[string]string, skip func(string) bool) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		if skip != nil && skip(k) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e.attribute(field, k, attrs[k])
+	}
+}
+
+// attribute encodes a KeyValue message with a string value.
+func (e *otlpEncoder) attribute(field int, key, value string) {
+	e.message(field, func(e *otlpEncoder) {
+		e.string(1 /* key */, key)
+		e.message(2 /* value */, func(e *otlpEncoder) {
+			e.string(1 /* string_value */, value)
+		})
+	})
+}
+
+// span encodes the fields of an OTLP Span message.
+//
+// The span's tags become string attributes, except for the span.kind and
+// error tags which are translated to the respective OTLP fields. The log
+// records become events; the LogMessageField of a record, if any, is used as
+// the name of the event.
+func (e *otlpEncoder) span(rs *RecordedSpan) {
+	e.bytes(1 /* trace_id */, otlpTraceID(rs.TraceID))
+	e.bytes(2 /* span_id */, otlpSpanID(rs.SpanID))
+	if rs.ParentSpanID != 0 {
+		e.bytes(4 /* parent_span_id */, otlpSpanID(rs.ParentSpanID))
+	}
+	e.string(5 /* name */, rs.Operation)
+	e.varint(6 /* kind */, otlpSpanKind(rs.Tags[string(ext.SpanKind)]))
+	start := rs.StartTime.UnixNano()
+	e.fixed64(7 /* start_time_unix_nano */, uint64(start))
+	e.fixed64(8 /* end_time_unix_nano */, uint64(start+rs.Duration.Nanoseconds()))
+	e.attributes(9 /* attributes */, rs.Tags, func(k string) bool {
+		return k == string(ext.SpanKind) || k == string(ext.Error)
+	})
+	for i := range rs.Logs {
+		l := &rs.Logs[i]
+		e.message(11 /* events */, func(e *otlpEncoder) {
+			e.fixed64(1 /* time_unix_nano */, uint64(l.Time.UnixNano()))
+			name := otlpEventName
+			for _, f := range l.Fields {
+				if f.Key == LogMessageField {
+					name = f.Value
+					break
+				}
+			}
+			e.string(2 /* name */, name)
+			for _, f := range l.Fields {
+				if f.Key != LogMessageField {
+					e.attribute(3 /* attributes */, f.Key, f.Value)
+				}
+			}
+		})
+	}
+	if rs.Tags[string(ext.Error)] == "true" {
+		e.message(15 /* status */, func(e *otlpEncoder) {
+			e.varint(3 /* code */, otlpStatusCodeError)
+		})
+	}
+}
+
+// otlpTraceID converts one of our 64-bit trace IDs to a 128-bit OTLP trace
+// ID. The high bits are zero, like in the OpenTracing bridges of the
+// OpenTelemetry SDKs.
+func otlpTraceID(id uint64) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[8:], id)
+	return b
+}
+
+// otlpSpanID converts one of our span IDs to an OTLP span ID.
+func otlpSpanID(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
+
+// otlpSpanKind converts the value of an OpenTracing span.kind tag to an OTLP
+// SpanKind.
+func otlpSpanKind(kind string) uint64 {
+	switch ext.SpanKindEnum(kind) {
+	case ext.SpanKindRPCServerEnum:
+		return otlpSpanKindServer
+	case ext.SpanKindRPCClientEnum:
+		return otlpSpanKindClient
+	case ext.SpanKindProducerEnum:
+		return otlpSpanKindProducer
+	case ext.SpanKindConsumerEnum:
+		return otlpSpanKindConsumer
+	default:
+		return otlpSpanKindInternal
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"google.golang.org/grpc"
+)
+
+// otlpExporterOptions configures an otlpExporter.
+type otlpExporterOptions struct {
+	// maxQueuedSpans bounds the number of finished spans waiting to be exported.
+	// Spans finished while the queue is full are dropped.
+	maxQueuedSpans int
+	// maxBatchSize is the maximum number of spans sent in a single export
+	// request. A request is sent as soon as this many spans are queued.
+	maxBatchSize int
+	// flushInterval is the maximum amount of time a finished span waits before
+	// being exported.
+	flushInterval time.Duration
+	// exportTimeout bounds the duration of an export request.
+	exportTimeout time.Duration
+}
+
+// Defaults for otlpExporterOptions, matching the defaults of the batch span
+// processors of the OpenTelemetry SDKs.
+const (
+	defaultOTLPMaxBatchSize  = 512
+	defaultOTLPFlushInterval = 5 * time.Second
+	defaultOTLPExportTimeout = 10 * time.Second
+)
+
+// otlpClient sends export requests to an OTLP collector.
+type otlpClient interface {
+	export(ctx context.Context, req *otlpExportRequest) error
+	close()
+}
+
+// otlpExporter batches finished spans and exports them asynchronously to an
+// OTLP collector.
+//
+// The memory used by the exporter is bounded: at most maxQueuedSpans are
+// buffered, and recordings are themselves bounded by maxLogsPerSpan. When the
+// collector cannot keep up, spans are dropped rather than slowing down the
+// operations being traced.
+type otlpExporter struct {
+	client   otlpClient
+	opts     otlpExporterOptions
+	resource map[string]string
+
+	mu struct {
+		syncutil.Mutex
+		queue []RecordedSpan
+		// dropped counts the spans dropped since the last report.
+		dropped int
+	}
+
+	// flushC is signaled when a full batch is queued.
+	flushC chan struct{}
+	// stopC is closed by close() to stop the export loop; doneC is closed by
+	// the export loop when it has exported the remaining spans.
+	stopC chan struct{}
+	doneC chan struct{}
+}
+
+// newOTLPExporter creates an exporter and starts its export loop. The
+// exporter needs to be closed.
+func newOTLPExporter(client otlpClient, opts otlpExporterOptions) *otlpExporter {
+	e := &otlpExporter{
+		client:   client,
+		opts:     opts,
+		resource: map[string]string{"service.name": "cockroach"},
+		flushC:   make(chan struct{}, 1),
+		stopC:    make(chan struct{}),
+		doneC:    make(chan struct{}),
+	}
+	go e.exportLoop()
+	return e
+}
+
+// enqueue schedules a finished span for export.
+func (e *otlpExporter) enqueue(rs RecordedSpan) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.mu.queue) >= e.opts.maxQueuedSpans {
+		e.mu.dropped++
+		return
+	}
+	e.mu.queue = append(e.mu.queue, rs)
+	if len(e.mu.queue) >= e.opts.maxBatchSize {
+		select {
+		case e.flushC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *otlpExporter) exportLoop() {
+	defer close(e.doneC)
+	ticker := time.NewTicker(e.opts.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.flushC:
+		case <-e.stopC:
+			e.flush()
+			return
+		}
+		e.flush()
+	}
+}
+
+// flush exports all the queued spans, in batches of at most maxBatchSize.
+func (e *otlpExporter) flush() {
+	for {
+		e.mu.Lock()
+		n := len(e.mu.queue)
+		if n > e.opts.maxBatchSize {
+			n = e.opts.maxBatchSize
+		}
+		batch := e.mu.queue[:n:n]
+		e.mu.queue = e.mu.queue[n:]
+		if len(e.mu.queue) == 0 {
+			// Release the memory of the spans that have been exported.
+			e.mu.queue = nil
+		}
+		dropped := e.mu.dropped
+		e.mu.dropped = 0
+		e.mu.Unlock()
+
+		if dropped > 0 {
+			otlpErrorf("dropped %d spans: export queue full", dropped)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), e.opts.exportTimeout)
+		err := e.client.export(ctx, &otlpExportRequest{resource: e.resource, spans: batch})
+		cancel()
+		if err != nil {
+			otlpErrorf("error exporting %d spans: %v", len(batch), err)
+		}
+	}
+}
+
+// close exports the queued spans and releases the exporter's resources. Spans
+// enqueued afterwards are not exported.
+func (e *otlpExporter) close() {
+	close(e.stopC)
+	<-e.doneC
+	e.client.close()
+}
+
+var otlpLogEveryN = util.Every(5 * time.Second)
+
+// otlpErrorf reports export errors. We can't use `log` from this package so
+// the errors are printed to stderr, with rate limiting.
+func otlpErrorf(format string, args ...interface{}) {
+	if otlpLogEveryN.ShouldProcess(timeutil.Now()) {
+		fmt.Fprintf(os.Stderr, "OpenTelemetry exporter: "+format+"\n", args...)
+	}
+}
+
+// newOTLPClient creates a client for the collector at the given address. An
+// http:// or https:// URL selects OTLP/HTTP; anything else is taken to be the
+// host:port address of a collector accepting OTLP/gRPC.
+func newOTLPClient(addr string, timeout time.Duration) (otlpClient, error) {
+	if isOTLPHTTPAddress(addr) {
+		if _, err := url.Parse(addr); err != nil {
+			return nil, err
+		}
+		return &otlpHTTPClient{
+			url:    addr,
+			client: httputil.NewClientWithTimeout(timeout),
+		}, nil
+	}
+	// The connection is established lazily, and re-established as needed, by
+	// grpc.
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	return &otlpGRPCClient{conn: conn}, nil
+}
+
+func isOTLPHTTPAddress(addr string) bool {
+	return strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://")
+}
+
+// otlpGRPCClient exports spans using OTLP/gRPC.
+type otlpGRPCClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *otlpGRPCClient) export(ctx context.Context, req *otlpExportRequest) error {
+	return c.conn.Invoke(ctx, otlpTraceExportMethod, req, &otlpExportResponse{})
+}
+
+func (c *otlpGRPCClient) close() {
+	_ = c.conn.Close()
+}
+
+// otlpHTTPClient exports spans using OTLP/HTTP with protobuf-encoded
+// payloads.
+type otlpHTTPClient struct {
+	url    string
+	client *httputil.Client
+}
+
+func (c *otlpHTTPClient) export(ctx context.Context, req *otlpExportRequest) error {
+	body, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Post(ctx, c.url, "application/x-protobuf", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Newf("collector responded with %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+func (c *otlpHTTPClient) close() {
+	c.client.CloseIdleConnections()
+}
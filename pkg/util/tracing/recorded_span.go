@@ -20,6 +20,11 @@ import (
 // for a log message.
 const LogMessageField = "event"
 
+// ContentionEventField is the field name used for the
+// opentracing.Span.LogFields() for a roachpb.ContentionEvent, which is recorded
+// whenever a request waits on a transaction that conflicts with it.
+const ContentionEventField = "contention_event"
+
 func (s *RecordedSpan) String() string {
 	sb := strings.Builder{}
 	sb.WriteString(fmt.Sprintf("=== %s (id: %d parent: %d)\n", s.Operation, s.SpanID, s.ParentSpanID))
@@ -29,12 +34,12 @@ func (s *RecordedSpan) String() string {
 	return sb.String()
 }
 
-// Msg extracts the message of the LogRecord, which is either in an "event" or
-// "error" field.
+// Msg extracts the message of the LogRecord, which is either in an "event",
+// "contention_event" or "error" field.
 func (l LogRecord) Msg() string {
 	for _, f := range l.Fields {
 		key := f.Key
-		if key == LogMessageField {
+		if key == LogMessageField || key == ContentionEventField {
 			return f.Value
 		}
 		if key == "error" {
@@ -0,0 +1,288 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// The OpenTelemetry shadow tracer records the spans in RecordedSpans and
+// hands them to an otlpExporter when they finish. Its span contexts are
+// propagated using the W3C Trace Context traceparent header format.
+
+// otelTraceParentField is the name of the field carrying the span context.
+const otelTraceParentField = "traceparent"
+
+type otelManager struct {
+	exporter *otlpExporter
+}
+
+func (*otelManager) Name() string {
+	return "opentelemetry"
+}
+
+func (m *otelManager) Close(tr opentracing.Tracer) {
+	m.exporter.close()
+}
+
+// otelTracer is the opentracing.Tracer of the OpenTelemetry shadow tracer.
+type otelTracer struct {
+	exporter *otlpExporter
+}
+
+var _ opentracing.Tracer = &otelTracer{}
+
+// createOTelTracer creates a shadow tracer exporting spans to the OTLP
+// collector at the given address.
+func createOTelTracer(
+	collectorAddr string, opts otlpExporterOptions,
+) (shadowTracerManager, opentracing.Tracer, error) {
+	client, err := newOTLPClient(collectorAddr, opts.exportTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	exporter := newOTLPExporter(client, opts)
+	return &otelManager{exporter: exporter}, &otelTracer{exporter: exporter}, nil
+}
+
+// StartSpan is part of the opentracing.Tracer interface.
+func (t *otelTracer) StartSpan(
+	operationName string, opts ...opentracing.StartSpanOption,
+) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+	s := &otelSpan{tracer: t}
+	s.mu.rs = RecordedSpan{
+		SpanID:    uint64(rand.Int63()),
+		Operation: operationName,
+		StartTime: sso.StartTime,
+	}
+	if s.mu.rs.StartTime.IsZero() {
+		s.mu.rs.StartTime = timeutil.Now()
+	}
+	for _, r := range sso.References {
+		if parent, ok := r.ReferencedContext.(*otelSpanContext); ok {
+			s.mu.rs.TraceID = parent.traceID
+			s.mu.rs.ParentSpanID = parent.spanID
+			break
+		}
+	}
+	if s.mu.rs.TraceID == 0 {
+		s.mu.rs.TraceID = uint64(rand.Int63())
+	}
+	for k, v := range sso.Tags {
+		s.setTagLocked(k, v)
+	}
+	return s
+}
+
+// Inject is part of the opentracing.Tracer interface.
+func (t *otelTracer) Inject(
+	osc opentracing.SpanContext, format interface{}, carrier interface{},
+) error {
+	sc, ok := osc.(*otelSpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	mapWriter, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	// version-traceid-spanid-flags, with the flags indicating that the trace is
+	// sampled.
+	mapWriter.Set(otelTraceParentField, fmt.Sprintf("00-%032x-%016x-01", sc.traceID, sc.spanID))
+	return nil
+}
+
+// Extract is part of the opentracing.Tracer interface.
+func (t *otelTracer) Extract(
+	format interface{}, carrier interface{},
+) (opentracing.SpanContext, error) {
+	mapReader, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+	var sc *otelSpanContext
+	err := mapReader.ForeachKey(func(k, v string) error {
+		if strings.ToLower(k) != otelTraceParentField {
+			return nil
+		}
+		parts := strings.Split(v, "-")
+		if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+			return opentracing.ErrSpanContextCorrupted
+		}
+		// We only generate 64-bit trace IDs; the high bits are ignored.
+		traceID, err := strconv.ParseUint(parts[1][16:], 16, 64)
+		if err != nil {
+			return opentracing.ErrSpanContextCorrupted
+		}
+		spanID, err := strconv.ParseUint(parts[2], 16, 64)
+		if err != nil {
+			return opentracing.ErrSpanContextCorrupted
+		}
+		sc = &otelSpanContext{traceID: traceID, spanID: spanID}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sc == nil {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	return sc, nil
+}
+
+type otelSpanContext struct {
+	traceID uint64
+	spanID  uint64
+}
+
+var _ opentracing.SpanContext = &otelSpanContext{}
+
+// ForeachBaggageItem is part of the opentracing.SpanContext interface.
+//
+// Baggage is propagated by our own tracer; the shadow spans only record the
+// baggage items as tags.
+func (*otelSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {}
+
+// otelSpan is the span of the OpenTelemetry shadow tracer.
+type otelSpan struct {
+	tracer *otelTracer
+
+	mu struct {
+		syncutil.Mutex
+		rs       RecordedSpan
+		finished bool
+	}
+}
+
+var _ opentracing.Span = &otelSpan{}
+
+// Finish is part of the opentracing.Span interface.
+func (s *otelSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// FinishWithOptions is part of the opentracing.Span interface.
+func (s *otelSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	finishTime := opts.FinishTime
+	if finishTime.IsZero() {
+		finishTime = timeutil.Now()
+	}
+	s.mu.Lock()
+	if s.mu.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.finished = true
+	s.mu.rs.Duration = finishTime.Sub(s.mu.rs.StartTime)
+	rs := s.mu.rs
+	s.mu.Unlock()
+	s.tracer.exporter.enqueue(rs)
+}
+
+// Context is part of the opentracing.Span interface.
+func (s *otelSpan) Context() opentracing.SpanContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &otelSpanContext{traceID: s.mu.rs.TraceID, spanID: s.mu.rs.SpanID}
+}
+
+// SetOperationName is part of the opentracing.Span interface.
+func (s *otelSpan) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.rs.Operation = operationName
+	return s
+}
+
+// SetTag is part of the opentracing.Span interface.
+func (s *otelSpan) SetTag(key string, value interface{}) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setTagLocked(key, value)
+	return s
+}
+
+func (s *otelSpan) setTagLocked(key string, value interface{}) {
+	if s.mu.rs.Tags == nil {
+		s.mu.rs.Tags = make(map[string]string)
+	}
+	s.mu.rs.Tags[key] = fmt.Sprint(value)
+}
+
+// LogFields is part of the opentracing.Span interface.
+func (s *otelSpan) LogFields(fields ...otlog.Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.finished || len(s.mu.rs.Logs) >= maxLogsPerSpan {
+		return
+	}
+	lr := LogRecord{
+		Time:   timeutil.Now(),
+		Fields: make([]LogRecord_Field, len(fields)),
+	}
+	for i, f := range fields {
+		lr.Fields[i] = LogRecord_Field{Key: f.Key(), Value: fmt.Sprint(f.Value())}
+	}
+	s.mu.rs.Logs = append(s.mu.rs.Logs, lr)
+}
+
+// LogKV is part of the opentracing.Span interface.
+func (s *otelSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := otlog.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(otlog.Error(err), otlog.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// SetBaggageItem is part of the opentracing.Span interface.
+func (s *otelSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	// Our tracer already sets a tag for every baggage item.
+	return s
+}
+
+// BaggageItem is part of the opentracing.Span interface.
+func (s *otelSpan) BaggageItem(restrictedKey string) string {
+	return ""
+}
+
+// Tracer is part of the opentracing.Span interface.
+func (s *otelSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// LogEvent is part of the opentracing.Span interface. Deprecated.
+func (s *otelSpan) LogEvent(event string) {
+	s.LogFields(otlog.String(LogMessageField, event))
+}
+
+// LogEventWithPayload is part of the opentracing.Span interface. Deprecated.
+func (s *otelSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(otlog.String(LogMessageField, event), otlog.Object("payload", payload))
+}
+
+// Log is part of the opentracing.Span interface. Deprecated.
+func (s *otelSpan) Log(data opentracing.LogData) {
+	panic("unimplemented")
+}
@@ -0,0 +1,352 @@
+// Copyright 2020 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	proto "github.com/gogo/protobuf/proto"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// testOTLPSpan is an OTLP span decoded by the collector stub.
+type testOTLPSpan struct {
+	traceID, spanID, parentSpanID uint64
+	name                          string
+	kind                          uint64
+	start, end                    uint64
+	attrs                         map[string]string
+	events                        []testOTLPEvent
+	statusCode                    uint64
+}
+
+type testOTLPEvent struct {
+	name  string
+	attrs map[string]string
+}
+
+// testOTLPCollector is an in-process OTLP collector, accepting spans over
+// gRPC and HTTP.
+type testOTLPCollector struct {
+	t  *testing.T
+	mu struct {
+		syncutil.Mutex
+		resource map[string]string
+		spans    []testOTLPSpan
+	}
+}
+
+// testExportRequest decodes an ExportTraceServiceRequest for the gRPC codec.
+type testExportRequest struct {
+	c *testOTLPCollector
+}
+
+func (*testExportRequest) Reset()         {}
+func (*testExportRequest) String() string { return "test export request" }
+func (*testExportRequest) ProtoMessage()  {}
+func (r *testExportRequest) Unmarshal(b []byte) error {
+	r.c.decodeRequest(b)
+	return nil
+}
+
+type testExportResponse struct{}
+
+func (*testExportResponse) Reset()                   {}
+func (*testExportResponse) String() string           { return "test export response" }
+func (*testExportResponse) ProtoMessage()            {}
+func (*testExportResponse) Marshal() ([]byte, error) { return nil, nil }
+
+// startGRPC starts a gRPC server implementing the OTLP trace service and
+// returns its address.
+func (c *testOTLPCollector) startGRPC() (addr string, stop func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(c.t, err)
+	s := grpc.NewServer()
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "opentelemetry.proto.collector.trace.v1.TraceService",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Export",
+			Handler: func(
+				_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor,
+			) (interface{}, error) {
+				if err := dec(&testExportRequest{c: c}); err != nil {
+					return nil, err
+				}
+				return &testExportResponse{}, nil
+			},
+		}},
+	}, c)
+	go func() { _ = s.Serve(ln) }()
+	return ln.Addr().String(), s.Stop
+}
+
+// startHTTP starts an HTTP server accepting OTLP/HTTP requests and returns
+// its URL.
+func (c *testOTLPCollector) startHTTP() (addr string, stop func()) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.decodeRequest(b)
+	}))
+	return s.URL + "/v1/traces", s.Close
+}
+
+// SucceedsSoon is testutils.SucceedsSoon. testutils depends on this package,
+// so it is injected by the external tracing_test package.
+var SucceedsSoon func(t testing.TB, fn func() error)
+
+func (c *testOTLPCollector) waitForSpans(n int) []testOTLPSpan {
+	var spans []testOTLPSpan
+	SucceedsSoon(c.t, func() error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if len(c.mu.spans) < n {
+			return errors.Newf("expected %d spans, got %d", n, len(c.mu.spans))
+		}
+		spans = append([]testOTLPSpan(nil), c.mu.spans...)
+		return nil
+	})
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return spans
+}
+
+// decodeFields decodes a protobuf message into a map from field number to
+// values. Length-delimited fields are returned as []byte; varint and fixed64
+// fields as uint64.
+func decodeFields(t *testing.T, b []byte) map[uint64][]interface{} {
+	res := make(map[uint64][]interface{})
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		require.True(t, n > 0)
+		b = b[n:]
+		var v interface{}
+		switch key & 7 {
+		case proto.WireVarint:
+			x, n := binary.Uvarint(b)
+			require.True(t, n > 0)
+			v, b = x, b[n:]
+		case proto.WireFixed64:
+			require.True(t, len(b) >= 8)
+			v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case proto.WireBytes:
+			l, n := binary.Uvarint(b)
+			require.True(t, n > 0 && len(b) >= n+int(l))
+			v, b = b[n:n+int(l)], b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type in key %d", key)
+		}
+		res[key>>3] = append(res[key>>3], v)
+	}
+	return res
+}
+
+func (c *testOTLPCollector) decodeAttributes(kvs []interface{}) map[string]string {
+	attrs := make(map[string]string)
+	for _, kv := range kvs {
+		f := decodeFields(c.t, kv.([]byte))
+		value := decodeFields(c.t, f[2][0].([]byte))
+		attrs[string(f[1][0].([]byte))] = string(value[1][0].([]byte))
+	}
+	return attrs
+}
+
+func (c *testOTLPCollector) decodeRequest(b []byte) {
+	req := decodeFields(c.t, b)
+	for _, rsb := range req[1] {
+		rs := decodeFields(c.t, rsb.([]byte))
+		resource := decodeFields(c.t, rs[1][0].([]byte))
+		var spans []testOTLPSpan
+		for _, ilsb := range rs[2] {
+			ils := decodeFields(c.t, ilsb.([]byte))
+			for _, sb := range ils[2] {
+				f := decodeFields(c.t, sb.([]byte))
+				s := testOTLPSpan{
+					traceID: binary.BigEndian.Uint64(f[1][0].([]byte)[8:]),
+					spanID:  binary.BigEndian.Uint64(f[2][0].([]byte)),
+					name:    string(f[5][0].([]byte)),
+					kind:    f[6][0].(uint64),
+					start:   f[7][0].(uint64),
+					end:     f[8][0].(uint64),
+					attrs:   c.decodeAttributes(f[9]),
+				}
+				if len(f[4]) > 0 {
+					s.parentSpanID = binary.BigEndian.Uint64(f[4][0].([]byte))
+				}
+				for _, eb := range f[11] {
+					ef := decodeFields(c.t, eb.([]byte))
+					s.events = append(s.events, testOTLPEvent{
+						name:  string(ef[2][0].([]byte)),
+						attrs: c.decodeAttributes(ef[3]),
+					})
+				}
+				if len(f[15]) > 0 {
+					status := decodeFields(c.t, f[15][0].([]byte))
+					s.statusCode = status[3][0].(uint64)
+				}
+				spans = append(spans, s)
+			}
+		}
+		c.mu.Lock()
+		c.mu.resource = c.decodeAttributes(resource[1])
+		c.mu.spans = append(c.mu.spans, spans...)
+		c.mu.Unlock()
+	}
+}
+
+func TestOTLPExport(t *testing.T) {
+	for _, protocol := range []string{"grpc", "http"} {
+		t.Run(protocol, func(t *testing.T) {
+			c := &testOTLPCollector{t: t}
+			var addr string
+			var stop func()
+			if protocol == "grpc" {
+				addr, stop = c.startGRPC()
+			} else {
+				addr, stop = c.startHTTP()
+			}
+			defer stop()
+
+			tr := NewTracer()
+			manager, otelTr, err := createOTelTracer(addr, otlpExporterOptions{
+				maxQueuedSpans: 100,
+				maxBatchSize:   10,
+				flushInterval:  10 * time.Millisecond,
+				exportTimeout:  5 * time.Second,
+			})
+			require.NoError(t, err)
+			tr.setShadowTracer(manager, otelTr)
+			defer tr.Close()
+			require.True(t, tr.AlwaysTrace())
+
+			root := tr.StartSpan("root", opentracing.Tags{"x": 1})
+			ext.SpanKindRPCClient.Set(root)
+			child := StartChildSpan("child", root, nil /* logTags */, false /* separateRecording */)
+			child.LogKV(LogMessageField, "hello", "k", "v")
+			child.LogKV("k", "v")
+			ext.Error.Set(child, true)
+			child.Finish()
+
+			// The shadow context is propagated to remote spans.
+			carrier := make(opentracing.HTTPHeadersCarrier)
+			require.NoError(t, tr.Inject(root.Context(), opentracing.HTTPHeaders, carrier))
+			wireContext, err := tr.Extract(opentracing.HTTPHeaders, carrier)
+			require.NoError(t, err)
+			remote := tr.StartSpan("remote", opentracing.ChildOf(wireContext))
+			remote.Finish()
+			root.Finish()
+
+			spans := c.waitForSpans(3)
+			rootSpan, childSpan, remoteSpan := spans[0], spans[1], spans[2]
+
+			require.Equal(t, "root", rootSpan.name)
+			require.Equal(t, uint64(0), rootSpan.parentSpanID)
+			require.Equal(t, uint64(otlpSpanKindClient), rootSpan.kind)
+			require.Equal(t, map[string]string{"x": "1"}, rootSpan.attrs)
+			require.True(t, rootSpan.end >= rootSpan.start)
+
+			require.Equal(t, "child", childSpan.name)
+			require.Equal(t, rootSpan.traceID, childSpan.traceID)
+			require.Equal(t, rootSpan.spanID, childSpan.parentSpanID)
+			require.Equal(t, uint64(otlpSpanKindInternal), childSpan.kind)
+			require.Equal(t, uint64(otlpStatusCodeError), childSpan.statusCode)
+			require.Equal(t, []testOTLPEvent{
+				{name: "hello", attrs: map[string]string{"k": "v"}},
+				{name: otlpEventName, attrs: map[string]string{"k": "v"}},
+			}, childSpan.events)
+
+			require.Equal(t, "remote", remoteSpan.name)
+			require.Equal(t, rootSpan.traceID, remoteSpan.traceID)
+			require.Equal(t, rootSpan.spanID, remoteSpan.parentSpanID)
+
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			require.Equal(t, map[string]string{"service.name": "cockroach"}, c.mu.resource)
+		})
+	}
+}
+
+// blockingOTLPClient is an otlpClient whose exports block until unblocked.
+type blockingOTLPClient struct {
+	unblock  chan struct{}
+	exported chan int
+}
+
+func (c *blockingOTLPClient) export(ctx context.Context, req *otlpExportRequest) error {
+	<-c.unblock
+	c.exported <- len(req.spans)
+	return nil
+}
+
+func (c *blockingOTLPClient) close() {}
+
+func TestOTLPExporterBoundedQueue(t *testing.T) {
+	client := &blockingOTLPClient{
+		unblock:  make(chan struct{}),
+		exported: make(chan int, 100),
+	}
+	e := newOTLPExporter(client, otlpExporterOptions{
+		maxQueuedSpans: 4,
+		maxBatchSize:   2,
+		flushInterval:  time.Hour,
+		exportTimeout:  time.Second,
+	})
+
+	// The first batch is handed to the (blocked) client, then the queue fills
+	// up and the following spans are dropped.
+	e.enqueue(RecordedSpan{SpanID: 1})
+	e.enqueue(RecordedSpan{SpanID: 2})
+	SucceedsSoon(t, func() error {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if n := len(e.mu.queue); n != 0 {
+			return errors.Newf("the first batch was not picked up, %d spans queued", n)
+		}
+		return nil
+	})
+	for i := 3; i <= 10; i++ {
+		e.enqueue(RecordedSpan{SpanID: uint64(i)})
+	}
+	e.mu.Lock()
+	require.Len(t, e.mu.queue, 4)
+	require.Equal(t, 4, e.mu.dropped)
+	e.mu.Unlock()
+
+	// Closing the exporter exports the queued spans.
+	close(client.unblock)
+	e.close()
+	close(client.exported)
+	var exported []int
+	for n := range client.exported {
+		exported = append(exported, n)
+	}
+	require.Equal(t, []int{2, 2, 2}, exported)
+}
@@ -14,6 +14,9 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net"
+	"net/url"
+	"os"
 	"regexp"
 	"sort"
 	"strconv"
@@ -70,6 +73,43 @@ var zipkinCollector = settings.RegisterPublicStringSetting(
 	envutil.EnvOrDefaultString("COCKROACH_TEST_ZIPKIN_COLLECTOR", ""),
 )
 
+var otelCollector = func() *settings.StringSetting {
+	s := settings.RegisterValidatedStringSetting(
+		"trace.opentelemetry.collector",
+		"if set, traces are exported to the given OpenTelemetry collector using OTLP; "+
+			"a host:port address uses gRPC (example: '127.0.0.1:4317'), an http(s) URL uses HTTP "+
+			"(example: 'http://127.0.0.1:4318/v1/traces'); "+
+			"ignored if trace.lightstep.token or trace.zipkin.collector is set",
+		envutil.EnvOrDefaultString("COCKROACH_TEST_OTEL_COLLECTOR", ""),
+		validateOTelCollector,
+	)
+	s.SetVisibility(settings.Public)
+	return s
+}()
+
+var otelMaxQueuedSpans = settings.RegisterPositiveIntSetting(
+	"trace.opentelemetry.max_queued_spans",
+	"maximum number of finished spans buffered for export to the OpenTelemetry collector; "+
+		"spans are dropped when the buffer is full",
+	2048,
+)
+
+func validateOTelCollector(_ *settings.Values, addr string) error {
+	if addr == "" {
+		return nil
+	}
+	if isOTLPHTTPAddress(addr) {
+		if _, err := url.Parse(addr); err != nil {
+			return errors.Wrap(err, "invalid collector URL")
+		}
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return errors.Wrap(err, "invalid collector address")
+	}
+	return nil
+}
+
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
 //  - forwarding events to x/net/trace instances
@@ -79,7 +119,8 @@ var zipkinCollector = settings.RegisterPublicStringSetting(
 //    events can be retrieved at any time.
 //
 //  - lightstep traces. This is implemented by maintaining a "shadow" lightstep
-//    span inside each of our spans.
+//    span inside each of our spans. Zipkin and OpenTelemetry (OTLP) exports
+//    are implemented similarly.
 //
 // Even when tracing is disabled, we still use this Tracer (with x/net/trace and
 // lightstep disabled) because of its recording capability (snowball
@@ -125,6 +166,18 @@ func (t *Tracer) Configure(sv *settings.Values) {
 			t.setShadowTracer(createLightStepTracer(lsToken))
 		} else if zipkinAddr := zipkinCollector.Get(sv); zipkinAddr != "" {
 			t.setShadowTracer(createZipkinTracer(zipkinAddr))
+		} else if otelAddr := otelCollector.Get(sv); otelAddr != "" {
+			manager, tr, err := createOTelTracer(otelAddr, otlpExporterOptions{
+				maxQueuedSpans: int(otelMaxQueuedSpans.Get(sv)),
+				maxBatchSize:   defaultOTLPMaxBatchSize,
+				flushInterval:  defaultOTLPFlushInterval,
+				exportTimeout:  defaultOTLPExportTimeout,
+			})
+			if err != nil {
+				// We can't use `log` from this package.
+				fmt.Fprintf(os.Stderr, "unable to set up the OpenTelemetry exporter: %v\n", err)
+			}
+			t.setShadowTracer(manager, tr)
 		} else {
 			t.setShadowTracer(nil, nil)
 		}
@@ -140,6 +193,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 	enableNetTrace.SetOnChange(sv, reconfigure)
 	lightstepToken.SetOnChange(sv, reconfigure)
 	zipkinCollector.SetOnChange(sv, reconfigure)
+	otelCollector.SetOnChange(sv, reconfigure)
+	otelMaxQueuedSpans.SetOnChange(sv, reconfigure)
 }
 
 func (t *Tracer) useNetTrace() bool {